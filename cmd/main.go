@@ -56,6 +56,7 @@ func main() {
 	kpiRepo := repositories.NewKPIRepository(database)
 	taskRepo := repositories.NewTaskRepository(database)
 	workFlowRepo := repositories.NewWorkFlowRepository(database)
+	workFlowVersionRepo := repositories.NewWorkFlowVersionRepository(database)
 	signJobRepo := repositories.NewSignJobRepository(database)
 	projectRepo := repositories.NewProjectRepository(database)
 	departmentRepo := repositories.NewDepartmentRepository(database)
@@ -71,20 +72,43 @@ func main() {
 	receivableRepo := repositories.NewReceivableRepository(database)
 	receiptRepo := repositories.NewReceiptRepository(database)
 	auditLogRepo := repositories.NewAuditLogRepository(database)
+	jobCostRepo := repositories.NewJobCostRepository(database)
+	signTypeWorkflowRepo := repositories.NewSignTypeWorkflowRepository(database)
+	capacityRepo := repositories.NewCapacityRepository(database)
+	attachmentRepo := repositories.NewAttachmentRepository(database)
+	timeEntryRepo := repositories.NewTimeEntryRepository(database)
+	slaRepo := repositories.NewSLARepository(database)
+	commentRepo := repositories.NewCommentRepository(database)
+	activityRepo := repositories.NewActivityRepository(database)
+	boardRepo := repositories.NewBoardRepository(database)
+	calendarFeedRepo := repositories.NewCalendarFeedRepository(database)
+	recurringTaskRepo := repositories.NewRecurringTaskRepository(database)
+	reviewCycleRepo := repositories.NewReviewCycleRepository(database)
+	leaveRepo := repositories.NewLeaveRepository(database)
+	attendanceRepo := repositories.NewAttendanceRepository(database)
+	payrollRepo := repositories.NewPayrollRepository(database)
+	employmentHistoryRepo := repositories.NewEmploymentHistoryRepository(database)
+	employeeChecklistRepo := repositories.NewEmployeeChecklistRepository(database)
 
-	userSvc := services.NewUserService(*cfg, userRepo, dropDownRepo, cloudflareStorage, taskRepo)
+	employmentHistorySvc := services.NewEmploymentHistoryService(*cfg, employmentHistoryRepo, userRepo, departmentRepo, positionRepo)
+	taskSvc := services.NewTaskService(*cfg, taskRepo, userRepo, workFlowRepo, departmentRepo, kpiEvaluationRepo, kpiRepo, signJobRepo, activityRepo)
+	employeeChecklistSvc := services.NewEmployeeChecklistService(*cfg, employeeChecklistRepo, userRepo, departmentRepo, positionRepo, taskRepo, taskSvc, calendarFeedRepo)
+
+	// token ของพนักงานที่ offboarding แล้วใช้ไม่ได้ทันทีโดยไม่ต้องรอหมดอายุ
+	authCookieMiddleware.SetAccessCheck(employeeChecklistSvc.IsAccessRevoked)
+
+	userSvc := services.NewUserService(*cfg, userRepo, dropDownRepo, cloudflareStorage, taskRepo, employmentHistorySvc, employeeChecklistSvc)
 	upLoadSvc := services.NewUpLoadService(*cfg, authRepo, upLoadRepo, userRepo, cloudflareStorage)
-	adminSvc := services.NewAdminService(*cfg, adminRepo, authRepo, userRepo)
+	adminSvc := services.NewAdminService(*cfg, adminRepo, authRepo, userRepo, employmentHistorySvc, employeeChecklistSvc)
 	dropDownSvc := services.NewDropDownService(*cfg, dropDownRepo)
 	kpiSvc := services.NewKPIService(*cfg, kpiRepo, userRepo)
-	taskSvc := services.NewTaskService(*cfg, taskRepo, userRepo, workFlowRepo, departmentRepo, kpiEvaluationRepo, kpiRepo, signJobRepo)
 	authSvc := services.NewAuthService(*cfg, authRepo, userRepo)
-	workFlowSvc := services.NewWorkflowService(*cfg, workFlowRepo)
-	signJobSvc := services.NewSignJobService(*cfg, signJobRepo, dropDownRepo, taskRepo, inComeRepo, receivableRepo)
+	workFlowSvc := services.NewWorkflowService(*cfg, workFlowRepo, workFlowVersionRepo, taskRepo, userRepo, departmentRepo, activityRepo)
+	signJobSvc := services.NewSignJobService(*cfg, signJobRepo, dropDownRepo, taskRepo, inComeRepo, receivableRepo, payableRepo, workFlowRepo, userRepo, signTypeWorkflowRepo, capacityRepo)
 	projectSvc := services.NewProjectService(*cfg, projectRepo, userRepo, signJobRepo, taskRepo)
 	departmentSvc := services.NewDepartmentService(*cfg, departmentRepo, userRepo)
 	positionSvc := services.NewPositionService(*cfg, positionRepo, departmentRepo, userRepo)
-	kpiEvaluationSvc := services.NewKPIEvaluationService(*cfg, kpiRepo, userRepo, kpiEvaluationRepo, taskRepo, departmentRepo, projectRepo, signJobRepo)
+	kpiEvaluationSvc := services.NewKPIEvaluationService(*cfg, kpiRepo, userRepo, kpiEvaluationRepo, taskRepo, departmentRepo, projectRepo, signJobRepo, slaRepo)
 	categorySvc := services.NewCategoryService(*cfg, categoryRepo)
 	signTypeSvc := services.NewSignTypeService(*cfg, signTypeRepo, userRepo)
 	bankAccountsSvc := services.NewBankAccountService(*cfg, bankAccountsRepo)
@@ -95,6 +119,21 @@ func main() {
 	receivableSvc := services.NewReceivableService(*cfg, receivableRepo, bankAccountsRepo, signJobRepo, inComeRepo)
 	receiptSvc := services.NewReceiptService(*cfg, receiptRepo, bankAccountsRepo)
 	auditLogSvc := services.NewAuditLogService(*cfg, auditLogRepo)
+	jobCostSvc := services.NewJobCostService(*cfg, jobCostRepo, signJobRepo, taskRepo, userRepo, positionRepo, expenseRepo, payableRepo, dropDownRepo)
+	signTypeWorkflowSvc := services.NewSignTypeWorkflowService(*cfg, signTypeWorkflowRepo, workFlowRepo, dropDownRepo)
+	capacitySvc := services.NewCapacityService(*cfg, capacityRepo, taskRepo, userRepo, workFlowRepo, signTypeWorkflowRepo, dropDownRepo, leaveRepo)
+	attachmentSvc := services.NewAttachmentService(*cfg, attachmentRepo, signJobRepo, taskRepo, departmentRepo, receiptRepo, payableRepo, expenseRepo, cloudflareStorage)
+	timeEntrySvc := services.NewTimeEntryService(*cfg, timeEntryRepo, taskRepo, userRepo, departmentRepo)
+	slaSvc := services.NewSLAService(*cfg, slaRepo, taskRepo, userRepo, departmentRepo, workFlowRepo)
+	commentSvc := services.NewCommentService(*cfg, commentRepo, activityRepo, attachmentRepo, taskRepo, signJobRepo, userRepo, departmentRepo)
+	boardSvc := services.NewBoardService(*cfg, boardRepo, taskRepo, taskSvc, departmentRepo, capacityRepo)
+	calendarFeedSvc := services.NewCalendarFeedService(*cfg, calendarFeedRepo, taskRepo, signJobRepo, userRepo, departmentRepo)
+	recurringTaskSvc := services.NewRecurringTaskService(*cfg, recurringTaskRepo, taskRepo, workFlowRepo, userRepo, departmentRepo)
+	reviewCycleSvc := services.NewReviewCycleService(*cfg, reviewCycleRepo, kpiEvaluationRepo, userRepo, departmentRepo)
+	kpiAnalyticsSvc := services.NewKPIAnalyticsService(*cfg, kpiEvaluationRepo, taskRepo, userRepo, departmentRepo)
+	leaveSvc := services.NewLeaveService(*cfg, leaveRepo, userRepo, departmentRepo, positionRepo, capacityRepo)
+	attendanceSvc := services.NewAttendanceService(*cfg, attendanceRepo, userRepo, departmentRepo, signJobRepo, capacityRepo, leaveRepo)
+	payrollSvc := services.NewPayrollService(*cfg, payrollRepo, userRepo, bankAccountsRepo, expenseRepo, attendanceSvc)
 
 	// เริ่มต้น Cronjob สำหรับตรวจสอบสถานะ Payable และ Receivable
 	statusChecker := cron.NewStatusChecker(payableRepo, receivableRepo)
@@ -104,6 +143,48 @@ func main() {
 		log.Println("🚀🚀🚀 Cronjob เริ่มทำงานแล้ว - ระบบจะตรวจสอบสถานะ Payable/Receivable ทุกวันเวลา 00:00 น.")
 	}
 
+	// เริ่มต้น Cronjob สำหรับตรวจสอบ SLA ของงานและ step
+	slaChecker := cron.NewSLAChecker(slaSvc)
+	if err := slaChecker.Start(); err != nil {
+		log.Printf("เริ่ม SLA cronjob ไม่สำเร็จ: %v", err)
+	}
+
+	// เริ่มต้น Cronjob สำหรับสร้างงานประจำที่ถึงรอบ
+	recurringTaskRunner := cron.NewRecurringTaskRunner(recurringTaskSvc)
+	if err := recurringTaskRunner.Start(); err != nil {
+		log.Printf("เริ่ม Recurring Task cronjob ไม่สำเร็จ: %v", err)
+	}
+
+	// เริ่มต้น Cronjob สำหรับตรวจ/แก้สถิติงานของผู้ใช้ (user_task_stats)
+	taskStatsChecker := cron.NewTaskStatsChecker(taskSvc)
+	if err := taskStatsChecker.Start(); err != nil {
+		log.Printf("เริ่ม Task Stats cronjob ไม่สำเร็จ: %v", err)
+	}
+
+	// เริ่มต้น Cronjob สำหรับเปิด/ปิดรอบประเมินผลงานและส่งเตือน
+	reviewCycleRunner := cron.NewReviewCycleRunner(reviewCycleSvc)
+	if err := reviewCycleRunner.Start(); err != nil {
+		log.Printf("เริ่ม Review Cycle cronjob ไม่สำเร็จ: %v", err)
+	}
+
+	// เริ่มต้น Cronjob สำหรับติดตามแบบประเมิน KPI ที่ค้าง (มอบหมาย/เตือน/ปิดอัตโนมัติ)
+	kpiEvaluationRunner := cron.NewKPIEvaluationRunner(kpiEvaluationSvc)
+	if err := kpiEvaluationRunner.Start(); err != nil {
+		log.Printf("เริ่ม KPI Evaluation cronjob ไม่สำเร็จ: %v", err)
+	}
+
+	// เริ่มต้น Cronjob สำหรับปรับตำแหน่ง/แผนกที่ตั้งวันที่มีผลล่วงหน้าไว้
+	employmentHistoryRunner := cron.NewEmploymentHistoryRunner(employmentHistorySvc)
+	if err := employmentHistoryRunner.Start(); err != nil {
+		log.Printf("เริ่ม Employment History cronjob ไม่สำเร็จ: %v", err)
+	}
+
+	// เริ่มต้น Cronjob สำหรับยกเลิกสิทธิ์พนักงานที่พ้นวันทำงานวันสุดท้าย
+	employeeChecklistRunner := cron.NewEmployeeChecklistRunner(employeeChecklistSvc)
+	if err := employeeChecklistRunner.Start(); err != nil {
+		log.Printf("เริ่ม Employee Checklist cronjob ไม่สำเร็จ: %v", err)
+	}
+
 	userHdl := handlers.NewUserHandler(userSvc, upLoadSvc, authCookieMiddleware)
 	upLoadHdl := handlers.NewUpLoadHandler(upLoadSvc, authCookieMiddleware)
 	adminHdl := handlers.NewAdminHandler(adminSvc, authCookieMiddleware)
@@ -126,8 +207,25 @@ func main() {
 	payableHdl := handlers.NewPayableHandler(payableSvc, authCookieMiddleware)
 	receivableHdl := handlers.NewReceivableHandler(receivableSvc, authCookieMiddleware)
 	receiptHdl := handlers.NewReceiptHandler(receiptSvc, authCookieMiddleware)
-	cronHdl := handlers.NewCronHandler(statusChecker, authCookieMiddleware)
+	cronHdl := handlers.NewCronHandler(statusChecker, slaChecker, recurringTaskRunner, taskStatsChecker, reviewCycleRunner, kpiEvaluationRunner, employmentHistoryRunner, employeeChecklistRunner, authCookieMiddleware)
 	auditLogHdl := handlers.NewAuditLogHandler(auditLogSvc, authCookieMiddleware)
+	jobCostHdl := handlers.NewJobCostHandler(jobCostSvc, authCookieMiddleware)
+	signTypeWorkflowHdl := handlers.NewSignTypeWorkflowHandler(signTypeWorkflowSvc, authCookieMiddleware)
+	capacityHdl := handlers.NewCapacityHandler(capacitySvc, authCookieMiddleware)
+	attachmentHdl := handlers.NewAttachmentHandler(attachmentSvc, authCookieMiddleware)
+	timeEntryHdl := handlers.NewTimeEntryHandler(timeEntrySvc, authCookieMiddleware)
+	slaHdl := handlers.NewSLAHandler(slaSvc, authCookieMiddleware)
+	commentHdl := handlers.NewCommentHandler(commentSvc, authCookieMiddleware)
+	boardHdl := handlers.NewBoardHandler(boardSvc, authCookieMiddleware)
+	calendarFeedHdl := handlers.NewCalendarFeedHandler(calendarFeedSvc, authCookieMiddleware)
+	recurringTaskHdl := handlers.NewRecurringTaskHandler(recurringTaskSvc, authCookieMiddleware)
+	reviewCycleHdl := handlers.NewReviewCycleHandler(reviewCycleSvc, authCookieMiddleware)
+	kpiAnalyticsHdl := handlers.NewKPIAnalyticsHandler(kpiAnalyticsSvc, authCookieMiddleware)
+	leaveHdl := handlers.NewLeaveHandler(leaveSvc, authCookieMiddleware)
+	attendanceHdl := handlers.NewAttendanceHandler(attendanceSvc, authCookieMiddleware)
+	payrollHdl := handlers.NewPayrollHandler(payrollSvc, authCookieMiddleware)
+	employmentHistoryHdl := handlers.NewEmploymentHistoryHandler(employmentHistorySvc, authCookieMiddleware)
+	employeeChecklistHdl := handlers.NewEmployeeChecklistHandler(employeeChecklistSvc, authCookieMiddleware)
 
 	app := fiber.New()
 
@@ -167,6 +265,23 @@ func main() {
 	receiptHdl.ReceiptRoutes(apiGroup)
 	cronHdl.CronRoutes(apiGroup)
 	auditLogHdl.AuditLogRoutes(apiGroup)
+	jobCostHdl.JobCostRoutes(apiGroup)
+	signTypeWorkflowHdl.SignTypeWorkflowRoutes(apiGroup)
+	capacityHdl.CapacityRoutes(apiGroup)
+	attachmentHdl.AttachmentRoutes(apiGroup)
+	timeEntryHdl.TimeEntryRoutes(apiGroup)
+	slaHdl.SLARoutes(apiGroup)
+	commentHdl.CommentRoutes(apiGroup)
+	boardHdl.BoardRoutes(apiGroup)
+	calendarFeedHdl.CalendarFeedRoutes(apiGroup)
+	recurringTaskHdl.RecurringTaskRoutes(apiGroup)
+	reviewCycleHdl.ReviewCycleRoutes(apiGroup)
+	kpiAnalyticsHdl.KPIAnalyticsRoutes(apiGroup)
+	leaveHdl.LeaveRoutes(apiGroup)
+	attendanceHdl.AttendanceRoutes(apiGroup)
+	payrollHdl.PayrollRoutes(apiGroup)
+	employmentHistoryHdl.EmploymentHistoryRoutes(apiGroup)
+	employeeChecklistHdl.EmployeeChecklistRoutes(apiGroup)
 
 	app.Use("/swagger", basicauth.New(basicauth.Config{
 		Users: map[string]string{
@@ -191,6 +306,13 @@ func main() {
 
 	// หยุด cronjob
 	statusChecker.Stop()
+	slaChecker.Stop()
+	recurringTaskRunner.Stop()
+	taskStatsChecker.Stop()
+	reviewCycleRunner.Stop()
+	kpiEvaluationRunner.Stop()
+	employmentHistoryRunner.Stop()
+	employeeChecklistRunner.Stop()
 	log.Println("Cronjob stopped")
 
 	// ปิด Fiber app
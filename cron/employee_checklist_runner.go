@@ -0,0 +1,93 @@
+package cron
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/robfig/cron/v3"
+)
+
+// EmployeeChecklistRunner ยกเลิกสิทธิ์เข้าระบบของพนักงานที่ offboarding และพ้นวันทำงานวันสุดท้ายแล้ว
+type EmployeeChecklistRunner struct {
+	employeeChecklistSvc ports.EmployeeChecklistService
+	cron                 *cron.Cron
+	lastRunSummary       *dto.ChecklistRunResult // เก็บผลลัพธ์การรันล่าสุด
+}
+
+// NewEmployeeChecklistRunner สร้าง EmployeeChecklistRunner ใหม่
+func NewEmployeeChecklistRunner(employeeChecklistSvc ports.EmployeeChecklistService) *EmployeeChecklistRunner {
+	// ใช้ timezone ไทย (Asia/Bangkok, GMT+7)
+	loc := helpers.BangkokLocation()
+
+	return &EmployeeChecklistRunner{
+		employeeChecklistSvc: employeeChecklistSvc,
+		cron:                 cron.New(cron.WithLocation(loc)),
+	}
+}
+
+// Start เริ่มต้น cronjob
+// รันทุกวันเวลา 00:10 น. หลังสิ้นวันทำงานวันสุดท้าย
+func (er *EmployeeChecklistRunner) Start() error {
+	_, err := er.cron.AddFunc("10 0 * * *", func() {
+		if _, err := er.run("[CRON]"); err != nil {
+			log.Printf("[CRON ERROR] ยกเลิกสิทธิ์พนักงานที่ offboarding ไม่สำเร็จ: %v", err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	er.cron.Start()
+	log.Println("[CRON] Employee Checklist Runner เริ่มทำงานแล้ว (รันทุกวัน 00:10 น. ตามเวลาไทย)")
+
+	return nil
+}
+
+// Stop หยุด cronjob
+func (er *EmployeeChecklistRunner) Stop() {
+	log.Println("[CRON] หยุด Employee Checklist Runner...")
+	er.cron.Stop()
+}
+
+// GetLastRunSummary คืนค่าผลสรุปการรันล่าสุด
+func (er *EmployeeChecklistRunner) GetLastRunSummary() *dto.ChecklistRunResult {
+	return er.lastRunSummary
+}
+
+// RunNow ยกเลิกสิทธิ์พนักงานที่ถึงกำหนดทันที
+func (er *EmployeeChecklistRunner) RunNow() (*dto.ChecklistRunResult, error) {
+	log.Println("[MANUAL] เริ่มยกเลิกสิทธิ์พนักงานที่ offboarding...")
+
+	summary, err := er.run("[MANUAL]")
+	if err != nil {
+		log.Printf("[MANUAL ERROR] ยกเลิกสิทธิ์พนักงานที่ offboarding ไม่สำเร็จ: %v", err)
+		return nil, err
+	}
+
+	log.Println("[MANUAL] ยกเลิกสิทธิ์พนักงานที่ offboarding เสร็จสิ้น")
+	return summary, nil
+}
+
+func (er *EmployeeChecklistRunner) run(prefix string) (*dto.ChecklistRunResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	summary, err := er.employeeChecklistSvc.RunDueOffboarding(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	er.lastRunSummary = summary
+
+	if summary.Due+len(summary.Errors) > 0 {
+		log.Printf("%s Offboarding: ถึงกำหนด %d คน, ยกเลิกสิทธิ์ %d คน, โอนงานเพิ่ม %d งาน, ผิดพลาด %d",
+			prefix, summary.Due, summary.Revoked, summary.ReassignedTasks, len(summary.Errors))
+	}
+	for _, e := range summary.Errors {
+		log.Printf("%s Offboarding error: %s", prefix, e)
+	}
+	return summary, nil
+}
@@ -0,0 +1,93 @@
+package cron
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/robfig/cron/v3"
+)
+
+// EmploymentHistoryRunner ปรับตำแหน่ง/แผนก/ประเภทการจ้างของพนักงานตามประวัติการจ้างงานที่ตั้งวันที่มีผลล่วงหน้าไว้
+type EmploymentHistoryRunner struct {
+	employmentHistorySvc ports.EmploymentHistoryService
+	cron                 *cron.Cron
+	lastRunSummary       *dto.EmploymentHistoryRunResult // เก็บผลลัพธ์การรันล่าสุด
+}
+
+// NewEmploymentHistoryRunner สร้าง EmploymentHistoryRunner ใหม่
+func NewEmploymentHistoryRunner(employmentHistorySvc ports.EmploymentHistoryService) *EmploymentHistoryRunner {
+	// ใช้ timezone ไทย (Asia/Bangkok, GMT+7)
+	loc := helpers.BangkokLocation()
+
+	return &EmploymentHistoryRunner{
+		employmentHistorySvc: employmentHistorySvc,
+		cron:                 cron.New(cron.WithLocation(loc)),
+	}
+}
+
+// Start เริ่มต้น cronjob
+// รันทุกวันเวลา 00:05 น. ให้การเปลี่ยนแปลงมีผลตั้งแต่ต้นวัน
+func (er *EmploymentHistoryRunner) Start() error {
+	_, err := er.cron.AddFunc("5 0 * * *", func() {
+		if _, err := er.run("[CRON]"); err != nil {
+			log.Printf("[CRON ERROR] ปรับประวัติการจ้างงานที่ถึงวันมีผลไม่สำเร็จ: %v", err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	er.cron.Start()
+	log.Println("[CRON] Employment History Runner เริ่มทำงานแล้ว (รันทุกวัน 00:05 น. ตามเวลาไทย)")
+
+	return nil
+}
+
+// Stop หยุด cronjob
+func (er *EmploymentHistoryRunner) Stop() {
+	log.Println("[CRON] หยุด Employment History Runner...")
+	er.cron.Stop()
+}
+
+// GetLastRunSummary คืนค่าผลสรุปการรันล่าสุด
+func (er *EmploymentHistoryRunner) GetLastRunSummary() *dto.EmploymentHistoryRunResult {
+	return er.lastRunSummary
+}
+
+// RunNow ปรับการเปลี่ยนแปลงที่ถึงวันมีผลทันที
+func (er *EmploymentHistoryRunner) RunNow() (*dto.EmploymentHistoryRunResult, error) {
+	log.Println("[MANUAL] เริ่มปรับประวัติการจ้างงานที่ถึงวันมีผล...")
+
+	summary, err := er.run("[MANUAL]")
+	if err != nil {
+		log.Printf("[MANUAL ERROR] ปรับประวัติการจ้างงานที่ถึงวันมีผลไม่สำเร็จ: %v", err)
+		return nil, err
+	}
+
+	log.Println("[MANUAL] ปรับประวัติการจ้างงานเสร็จสิ้น")
+	return summary, nil
+}
+
+func (er *EmploymentHistoryRunner) run(prefix string) (*dto.EmploymentHistoryRunResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	summary, err := er.employmentHistorySvc.ApplyDueEmploymentChanges(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	er.lastRunSummary = summary
+
+	if summary.Due+len(summary.Errors) > 0 {
+		log.Printf("%s Employment history: ถึงวันมีผล %d รายการ, ปรับพนักงาน %d คน, ผิดพลาด %d",
+			prefix, summary.Due, summary.Applied, len(summary.Errors))
+	}
+	for _, e := range summary.Errors {
+		log.Printf("%s Employment history error: %s", prefix, e)
+	}
+	return summary, nil
+}
@@ -0,0 +1,94 @@
+package cron
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/robfig/cron/v3"
+)
+
+// KPIEvaluationRunner ติดตามแบบประเมิน KPI ที่ค้าง: มอบหมายผู้ประเมิน ส่งอีเมลเตือน และปิดแบบประเมินที่ค้างเกินกำหนด
+type KPIEvaluationRunner struct {
+	kpiEvaluationSvc ports.KPIEvaluationService
+	cron             *cron.Cron
+	lastRunSummary   *dto.KPIEvaluationRunResult // เก็บผลลัพธ์การรันล่าสุด
+}
+
+// NewKPIEvaluationRunner สร้าง KPIEvaluationRunner ใหม่
+func NewKPIEvaluationRunner(kpiEvaluationSvc ports.KPIEvaluationService) *KPIEvaluationRunner {
+	// ใช้ timezone ไทย (Asia/Bangkok, GMT+7)
+	loc := helpers.BangkokLocation()
+
+	return &KPIEvaluationRunner{
+		kpiEvaluationSvc: kpiEvaluationSvc,
+		cron:             cron.New(cron.WithLocation(loc)),
+	}
+}
+
+// Start เริ่มต้น cronjob
+// รันทุกชั่วโมง (นาทีที่ 30) อีเมลเตือนส่งหลัง 09:00 น. ตามรอบวันที่ตั้งไว้ใน KPI template
+func (kr *KPIEvaluationRunner) Start() error {
+	_, err := kr.cron.AddFunc("30 * * * *", func() {
+		if _, err := kr.run("[CRON]"); err != nil {
+			log.Printf("[CRON ERROR] ตรวจแบบประเมิน KPI ที่ค้างไม่สำเร็จ: %v", err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	kr.cron.Start()
+	log.Println("[CRON] KPI Evaluation Runner เริ่มทำงานแล้ว (รันทุกชั่วโมง ตามเวลาไทย)")
+
+	return nil
+}
+
+// Stop หยุด cronjob
+func (kr *KPIEvaluationRunner) Stop() {
+	log.Println("[CRON] หยุด KPI Evaluation Runner...")
+	kr.cron.Stop()
+}
+
+// GetLastRunSummary คืนค่าผลสรุปการรันล่าสุด
+func (kr *KPIEvaluationRunner) GetLastRunSummary() *dto.KPIEvaluationRunResult {
+	return kr.lastRunSummary
+}
+
+// RunNow ตรวจแบบประเมินที่ค้างทันที
+func (kr *KPIEvaluationRunner) RunNow() (*dto.KPIEvaluationRunResult, error) {
+	log.Println("[MANUAL] เริ่มตรวจแบบประเมิน KPI ที่ค้าง...")
+
+	summary, err := kr.run("[MANUAL]")
+	if err != nil {
+		log.Printf("[MANUAL ERROR] ตรวจแบบประเมิน KPI ที่ค้างไม่สำเร็จ: %v", err)
+		return nil, err
+	}
+
+	log.Println("[MANUAL] ตรวจแบบประเมิน KPI ที่ค้างเสร็จสิ้น")
+	return summary, nil
+}
+
+func (kr *KPIEvaluationRunner) run(prefix string) (*dto.KPIEvaluationRunResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	summary, err := kr.kpiEvaluationSvc.RunKPIEvaluationDue(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	kr.lastRunSummary = summary
+
+	// log เฉพาะรอบที่มีการเปลี่ยนแปลง เพราะรันทุกชั่วโมง
+	if summary.Assigned+summary.Emails+summary.Failed+summary.Closed+len(summary.Errors) > 0 {
+		log.Printf("%s KPI evaluation: ค้าง %d, มอบหมาย %d, เตือน %d รายการ (%d อีเมล, ล้มเหลว %d), ปิด %d, ผิดพลาด %d",
+			prefix, summary.Open, summary.Assigned, summary.Reminded, summary.Emails, summary.Failed, summary.Closed, len(summary.Errors))
+	}
+	for _, e := range summary.Errors {
+		log.Printf("%s KPI evaluation error: %s", prefix, e)
+	}
+	return summary, nil
+}
@@ -0,0 +1,91 @@
+package cron
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/robfig/cron/v3"
+)
+
+// RecurringTaskRunner สร้างงานจากรายการงานประจำ (recurring task) ที่ถึงรอบ
+type RecurringTaskRunner struct {
+	recurringSvc   ports.RecurringTaskService
+	cron           *cron.Cron
+	lastRunSummary *dto.RecurringRunResult // เก็บผลลัพธ์การรันล่าสุด
+}
+
+// NewRecurringTaskRunner สร้าง RecurringTaskRunner ใหม่
+func NewRecurringTaskRunner(recurringSvc ports.RecurringTaskService) *RecurringTaskRunner {
+	// ใช้ timezone ไทย (Asia/Bangkok, GMT+7)
+	loc := helpers.BangkokLocation()
+
+	return &RecurringTaskRunner{
+		recurringSvc: recurringSvc,
+		cron:         cron.New(cron.WithLocation(loc)),
+	}
+}
+
+// Start เริ่มต้น cronjob
+// รันทุก 5 นาที งานที่ตั้งเวลาไว้จะถูกสร้างช้าสุดไม่เกิน 5 นาที
+func (rr *RecurringTaskRunner) Start() error {
+	_, err := rr.cron.AddFunc("*/5 * * * *", func() {
+		if _, err := rr.run("[CRON]"); err != nil {
+			log.Printf("[CRON ERROR] สร้างงานประจำไม่สำเร็จ: %v", err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	rr.cron.Start()
+	log.Println("[CRON] Recurring Task Runner เริ่มทำงานแล้ว (รันทุก 5 นาที ตามเวลาไทย)")
+
+	return nil
+}
+
+// Stop หยุด cronjob
+func (rr *RecurringTaskRunner) Stop() {
+	log.Println("[CRON] หยุด Recurring Task Runner...")
+	rr.cron.Stop()
+}
+
+// GetLastRunSummary คืนค่าผลสรุปการรันล่าสุด
+func (rr *RecurringTaskRunner) GetLastRunSummary() *dto.RecurringRunResult {
+	return rr.lastRunSummary
+}
+
+// RunNow สร้างงานที่ถึงรอบทันที
+func (rr *RecurringTaskRunner) RunNow() (*dto.RecurringRunResult, error) {
+	log.Println("[MANUAL] เริ่มสร้างงานประจำที่ถึงรอบ...")
+
+	summary, err := rr.run("[MANUAL]")
+	if err != nil {
+		log.Printf("[MANUAL ERROR] สร้างงานประจำไม่สำเร็จ: %v", err)
+		return nil, err
+	}
+
+	log.Println("[MANUAL] สร้างงานประจำเสร็จสิ้น")
+	return summary, nil
+}
+
+func (rr *RecurringTaskRunner) run(prefix string) (*dto.RecurringRunResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	summary, err := rr.recurringSvc.RunDue(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	rr.lastRunSummary = summary
+
+	// log เฉพาะรอบที่มีงาน เพราะรันถี่
+	if summary.Checked > 0 {
+		log.Printf("%s Recurring: ตรวจ %d รายการ, สร้างงาน %d, ข้ามรอบที่ตกหล่น %d, ผิดพลาด %d, ครบกำหนด %d",
+			prefix, summary.Checked, summary.Spawned, summary.Missed, summary.Failed, summary.Finished)
+	}
+	return summary, nil
+}
@@ -0,0 +1,94 @@
+package cron
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/robfig/cron/v3"
+)
+
+// ReviewCycleRunner เปิด/ปิดรอบประเมินผลงานตามวันที่ และส่งอีเมลเตือนก่อนปิดรอบ
+type ReviewCycleRunner struct {
+	reviewSvc      ports.ReviewCycleService
+	cron           *cron.Cron
+	lastRunSummary *dto.ReviewCycleRunResult // เก็บผลลัพธ์การรันล่าสุด
+}
+
+// NewReviewCycleRunner สร้าง ReviewCycleRunner ใหม่
+func NewReviewCycleRunner(reviewSvc ports.ReviewCycleService) *ReviewCycleRunner {
+	// ใช้ timezone ไทย (Asia/Bangkok, GMT+7)
+	loc := helpers.BangkokLocation()
+
+	return &ReviewCycleRunner{
+		reviewSvc: reviewSvc,
+		cron:      cron.New(cron.WithLocation(loc)),
+	}
+}
+
+// Start เริ่มต้น cronjob
+// รันทุกชั่วโมง (นาทีที่ 0) เปิด/ปิดรอบช้าสุดไม่เกิน 1 ชั่วโมง อีเมลเตือนส่งวันละครั้งหลัง 09:00 น.
+func (rc *ReviewCycleRunner) Start() error {
+	_, err := rc.cron.AddFunc("0 * * * *", func() {
+		if _, err := rc.run("[CRON]"); err != nil {
+			log.Printf("[CRON ERROR] ตรวจรอบประเมินผลงานไม่สำเร็จ: %v", err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	rc.cron.Start()
+	log.Println("[CRON] Review Cycle Runner เริ่มทำงานแล้ว (รันทุกชั่วโมง ตามเวลาไทย)")
+
+	return nil
+}
+
+// Stop หยุด cronjob
+func (rc *ReviewCycleRunner) Stop() {
+	log.Println("[CRON] หยุด Review Cycle Runner...")
+	rc.cron.Stop()
+}
+
+// GetLastRunSummary คืนค่าผลสรุปการรันล่าสุด
+func (rc *ReviewCycleRunner) GetLastRunSummary() *dto.ReviewCycleRunResult {
+	return rc.lastRunSummary
+}
+
+// RunNow ตรวจรอบประเมินทันที
+func (rc *ReviewCycleRunner) RunNow() (*dto.ReviewCycleRunResult, error) {
+	log.Println("[MANUAL] เริ่มตรวจรอบประเมินผลงาน...")
+
+	summary, err := rc.run("[MANUAL]")
+	if err != nil {
+		log.Printf("[MANUAL ERROR] ตรวจรอบประเมินผลงานไม่สำเร็จ: %v", err)
+		return nil, err
+	}
+
+	log.Println("[MANUAL] ตรวจรอบประเมินผลงานเสร็จสิ้น")
+	return summary, nil
+}
+
+func (rc *ReviewCycleRunner) run(prefix string) (*dto.ReviewCycleRunResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	summary, err := rc.reviewSvc.RunDue(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	rc.lastRunSummary = summary
+
+	// log เฉพาะรอบที่มีการเปลี่ยนแปลง เพราะรันทุกชั่วโมง
+	if len(summary.Opened)+len(summary.Closed)+len(summary.Reminders)+len(summary.Errors) > 0 {
+		log.Printf("%s Review cycle: เปิด %d รอบ, ปิด %d รอบ, ส่งเตือน %d รอบ, ผิดพลาด %d",
+			prefix, len(summary.Opened), len(summary.Closed), len(summary.Reminders), len(summary.Errors))
+	}
+	for _, e := range summary.Errors {
+		log.Printf("%s Review cycle error: %s", prefix, e)
+	}
+	return summary, nil
+}
@@ -0,0 +1,93 @@
+package cron
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/robfig/cron/v3"
+)
+
+// SLAChecker ตรวจงาน/step ที่ใกล้หรือเกินกำหนดตาม SLA และแจ้งผู้จัดการแผนก
+type SLAChecker struct {
+	slaSvc         ports.SLAService
+	cron           *cron.Cron
+	lastRunSummary *dto.SLACheckResult // เก็บผลลัพธ์การรันล่าสุด
+}
+
+// NewSLAChecker สร้าง SLAChecker ใหม่
+func NewSLAChecker(slaSvc ports.SLAService) *SLAChecker {
+	// ใช้ timezone ไทย (Asia/Bangkok, GMT+7)
+	loc := helpers.BangkokLocation()
+
+	return &SLAChecker{
+		slaSvc: slaSvc,
+		cron:   cron.New(cron.WithLocation(loc)),
+	}
+}
+
+// Start เริ่มต้น cronjob
+// รันทุกชั่วโมง (นาทีที่ 0) เพราะ SLA ของ step นับเป็นชั่วโมง
+func (sc *SLAChecker) Start() error {
+	_, err := sc.cron.AddFunc("0 * * * *", func() {
+		log.Println("[CRON] เริ่มตรวจสอบ SLA...")
+
+		if _, err := sc.run("[CRON]"); err != nil {
+			log.Printf("[CRON ERROR] ตรวจสอบ SLA ไม่สำเร็จ: %v", err)
+			return
+		}
+
+		log.Println("[CRON] ตรวจสอบ SLA เสร็จสิ้น")
+	})
+	if err != nil {
+		return err
+	}
+
+	sc.cron.Start()
+	log.Println("[CRON] SLA Checker เริ่มทำงานแล้ว (รันทุกชั่วโมง ตามเวลาไทย)")
+
+	return nil
+}
+
+// Stop หยุด cronjob
+func (sc *SLAChecker) Stop() {
+	log.Println("[CRON] หยุด SLA Checker...")
+	sc.cron.Stop()
+}
+
+// GetLastRunSummary คืนค่าผลสรุปการรันล่าสุด
+func (sc *SLAChecker) GetLastRunSummary() *dto.SLACheckResult {
+	return sc.lastRunSummary
+}
+
+// RunNow รันการตรวจสอบ SLA ทันที
+func (sc *SLAChecker) RunNow() (*dto.SLACheckResult, error) {
+	log.Println("[MANUAL] เริ่มตรวจสอบ SLA...")
+
+	summary, err := sc.run("[MANUAL]")
+	if err != nil {
+		log.Printf("[MANUAL ERROR] ตรวจสอบ SLA ไม่สำเร็จ: %v", err)
+		return nil, err
+	}
+
+	log.Println("[MANUAL] ตรวจสอบ SLA เสร็จสิ้น")
+	return summary, nil
+}
+
+func (sc *SLAChecker) run(prefix string) (*dto.SLACheckResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	summary, err := sc.slaSvc.RunCheck(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sc.lastRunSummary = summary
+
+	log.Printf("%s SLA: ตรวจ %d งาน, เตือนใหม่ %d, เกินกำหนดใหม่ %d, แจ้งผู้จัดการ %d, ปิด %d",
+		prefix, summary.CheckedTasks, summary.Warnings, summary.Overdue, summary.Escalated, summary.Resolved)
+	return summary, nil
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
 	"github.com/Be2Bag/erp-demo/pkg/util"
 	"github.com/Be2Bag/erp-demo/ports"
 	"github.com/robfig/cron/v3"
@@ -48,11 +49,7 @@ func NewStatusChecker(
 	receivableRepo ports.ReceivableRepository,
 ) *StatusChecker {
 	// ใช้ timezone ไทย (Asia/Bangkok, GMT+7)
-	loc, err := time.LoadLocation("Asia/Bangkok")
-	if err != nil {
-		// fallback ถ้าโหลด timezone ไม่ได้
-		loc = time.FixedZone("Asia/Bangkok", 7*60*60)
-	}
+	loc := helpers.BangkokLocation()
 
 	return &StatusChecker{
 		payableRepo:    payableRepo,
@@ -262,8 +259,7 @@ func (sc *StatusChecker) checkReceivableStatus(ctx context.Context) ([]StatusUpd
 // runStatusCheck รันการตรวจสอบสถานะและส่งคืนผลสรุป
 func (sc *StatusChecker) runStatusCheck() (*CronRunSummary, error) {
 	ctx := context.Background()
-	loc, _ := time.LoadLocation("Asia/Bangkok")
-	now := time.Now().In(loc)
+	now := time.Now().In(helpers.BangkokLocation())
 
 	summary := &CronRunSummary{
 		RunAt:        now.Format("02/01/2006 15:04:05"),
@@ -0,0 +1,96 @@
+package cron
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/robfig/cron/v3"
+)
+
+// TaskStatsChecker ตรวจ user_task_stats เทียบกับงานจริง และเขียนค่าใหม่ให้ผู้ใช้ที่ตัวเลขคลาด
+type TaskStatsChecker struct {
+	taskSvc        ports.TaskService
+	cron           *cron.Cron
+	lastRunSummary *dto.TaskStatsCheckResult // เก็บผลลัพธ์การรันล่าสุด
+}
+
+// NewTaskStatsChecker สร้าง TaskStatsChecker ใหม่
+func NewTaskStatsChecker(taskSvc ports.TaskService) *TaskStatsChecker {
+	// ใช้ timezone ไทย (Asia/Bangkok, GMT+7)
+	loc := helpers.BangkokLocation()
+
+	return &TaskStatsChecker{
+		taskSvc: taskSvc,
+		cron:    cron.New(cron.WithLocation(loc)),
+	}
+}
+
+// Start เริ่มต้น cronjob
+// รันทุกวันเวลา 02:00 น. (ช่วงที่ไม่มีคนแก้งาน)
+func (tc *TaskStatsChecker) Start() error {
+	_, err := tc.cron.AddFunc("0 2 * * *", func() {
+		log.Println("[CRON] เริ่มตรวจสอบสถิติงาน...")
+
+		if _, err := tc.run("[CRON]"); err != nil {
+			log.Printf("[CRON ERROR] ตรวจสอบสถิติงานไม่สำเร็จ: %v", err)
+			return
+		}
+
+		log.Println("[CRON] ตรวจสอบสถิติงานเสร็จสิ้น")
+	})
+	if err != nil {
+		return err
+	}
+
+	tc.cron.Start()
+	log.Println("[CRON] Task Stats Checker เริ่มทำงานแล้ว (รันทุกวันเวลา 02:00 น. ตามเวลาไทย)")
+
+	return nil
+}
+
+// Stop หยุด cronjob
+func (tc *TaskStatsChecker) Stop() {
+	log.Println("[CRON] หยุด Task Stats Checker...")
+	tc.cron.Stop()
+}
+
+// GetLastRunSummary คืนค่าผลสรุปการรันล่าสุด
+func (tc *TaskStatsChecker) GetLastRunSummary() *dto.TaskStatsCheckResult {
+	return tc.lastRunSummary
+}
+
+// RunNow ตรวจและแก้สถิติงานทันที
+func (tc *TaskStatsChecker) RunNow() (*dto.TaskStatsCheckResult, error) {
+	log.Println("[MANUAL] เริ่มตรวจสอบสถิติงาน...")
+
+	summary, err := tc.run("[MANUAL]")
+	if err != nil {
+		log.Printf("[MANUAL ERROR] ตรวจสอบสถิติงานไม่สำเร็จ: %v", err)
+		return nil, err
+	}
+
+	log.Println("[MANUAL] ตรวจสอบสถิติงานเสร็จสิ้น")
+	return summary, nil
+}
+
+func (tc *TaskStatsChecker) run(prefix string) (*dto.TaskStatsCheckResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	summary, err := tc.taskSvc.RebuildTaskStats(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	tc.lastRunSummary = summary
+
+	log.Printf("%s Task stats: ตรวจ %d งาน, %d ผู้ใช้, ไม่ตรง %d, แก้แล้ว %d",
+		prefix, summary.Tasks, summary.Users, summary.Drifted, summary.Repaired)
+	for _, d := range summary.Drift {
+		log.Printf("%s Task stats drift: user=%s stored=%+v actual=%+v", prefix, d.UserID, d.Stored, d.Actual)
+	}
+	return summary, nil
+}
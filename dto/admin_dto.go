@@ -6,9 +6,10 @@ type RequestUpdateUserStatus struct {
 }
 
 type RequestUpdateUserPosition struct {
-	UserID       string `json:"user_id" validate:"required" example:"50f7a957-8c2c-4a76-88ed-7c247471f28f"`
-	Role         string `json:"role" validate:"required,oneof=admin user" example:"admin"`
-	PositionID   string `json:"position_id" validate:"required" example:"50f7a957-8c2c-4a76-88ed-7c247471f28f"`
-	DepartmentID string `json:"department_id" validate:"required" example:"50f7a957-8c2c-4a76-88ed-7c247471f28f"`
-	Note         string `json:"note"  example:"ทดสอบปรับตำแหน่ง"`
+	UserID        string `json:"user_id" validate:"required" example:"50f7a957-8c2c-4a76-88ed-7c247471f28f"`
+	Role          string `json:"role" validate:"required,oneof=admin user" example:"admin"`
+	PositionID    string `json:"position_id" validate:"required" example:"50f7a957-8c2c-4a76-88ed-7c247471f28f"`
+	DepartmentID  string `json:"department_id" validate:"required" example:"50f7a957-8c2c-4a76-88ed-7c247471f28f"`
+	Note          string `json:"note"  example:"ทดสอบปรับตำแหน่ง"`
+	EffectiveDate string `json:"effective_date" example:"2025-10-01"` // วันที่มีผล YYYY-MM-DD (ว่าง = วันนี้)
 }
@@ -0,0 +1,49 @@
+package dto
+
+import "time"
+
+// ---------- Request DTO ----------
+
+type CreateAttachmentDTO struct {
+	EntityType string `form:"entity_type"` // sign_job|task|step|receipt|payable|expense (จำเป็น)
+	EntityID   string `form:"entity_id"`   // รหัสเอกสาร (จำเป็น)
+	TaskID     string `form:"task_id"`     // รหัส task (จำเป็นเมื่อ entity_type = step)
+	Category   string `form:"category"`    // photo|design|proof|document|invoice|other (ว่าง = other)
+	Caption    string `form:"caption"`     // คำอธิบาย
+}
+
+// AttachmentFile ไฟล์ที่ handler บันทึกไว้ชั่วคราวก่อนส่งให้ service อัปโหลด
+type AttachmentFile struct {
+	Path        string // ที่อยู่ไฟล์ชั่วคราว
+	Name        string // ชื่อไฟล์ต้นฉบับ
+	ContentType string // MIME type จาก multipart header
+	Size        int64  // ขนาดไฟล์ (bytes)
+}
+
+type RequestListAttachments struct {
+	EntityType      string `query:"entity_type"`      // ประเภทเอกสาร (จำเป็น)
+	EntityID        string `query:"entity_id"`        // รหัสเอกสาร (จำเป็น)
+	TaskID          string `query:"task_id"`          // รหัส task (กรณี step)
+	Category        string `query:"category"`         // กรองตามหมวด
+	IncludeChildren bool   `query:"include_children"` // งานป้าย: รวมไฟล์ของ task/step ในงาน (gallery)
+}
+
+// ---------- Response DTO ----------
+
+type AttachmentDTO struct {
+	CreatedAt      time.Time `json:"created_at"`
+	AttachmentID   string    `json:"attachment_id"`
+	EntityType     string    `json:"entity_type"`
+	EntityID       string    `json:"entity_id"`
+	ParentID       string    `json:"parent_id,omitempty"`
+	JobID          string    `json:"job_id,omitempty"`
+	Category       string    `json:"category"`
+	Caption        string    `json:"caption,omitempty"`
+	FileName       string    `json:"file_name"`
+	FileURL        string    `json:"file_url"`
+	ThumbnailURL   string    `json:"thumbnail_url,omitempty"`
+	ContentType    string    `json:"content_type"`
+	UploadedBy     string    `json:"uploaded_by"`
+	UploadedByName string    `json:"uploaded_by_name"`
+	Size           int64     `json:"size"`
+}
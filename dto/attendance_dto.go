@@ -0,0 +1,184 @@
+package dto
+
+import "time"
+
+// ---------- Request DTO ----------
+
+type UpsertAttendanceSiteDTO struct {
+	SiteID      string   `json:"site_id"`   // ว่าง = สร้างใหม่
+	Name        string   `json:"name"`      // ชื่อสถานที่ (ว่าง = ชื่องานของใบงาน สำหรับหน้างานติดตั้ง)
+	SiteType    string   `json:"site_type"` // shop|office|install (จำเป็น)
+	JobID       string   `json:"job_id"`    // ใบงาน (จำเป็นเมื่อ install)
+	Latitude    float64  `json:"latitude"`  // ละติจูด (จำเป็น)
+	Longitude   float64  `json:"longitude"` // ลองจิจูด (จำเป็น)
+	RadiusM     float64  `json:"radius_m"`  // รัศมี (เมตร) ว่าง = 100
+	Departments []string `json:"departments"`
+	IsActive    *bool    `json:"is_active"` // ว่าง = เปิดใช้งาน
+}
+
+type UpsertWorkShiftDTO struct {
+	ShiftID           string   `json:"shift_id"`   // ว่าง = สร้างใหม่
+	Name              string   `json:"name"`       // ชื่อกะ (จำเป็น)
+	StartTime         string   `json:"start_time"` // HH:MM (จำเป็น)
+	EndTime           string   `json:"end_time"`   // HH:MM (น้อยกว่าเวลาเข้า = กะข้ามวัน)
+	BreakMinutes      int      `json:"break_minutes"`
+	LateGraceMinutes  int      `json:"late_grace_minutes"`
+	EarlyGraceMinutes int      `json:"early_grace_minutes"`
+	OTMinMinutes      int      `json:"ot_min_minutes"`
+	WorkDays          []int    `json:"work_days"` // 0=อาทิตย์ .. 6=เสาร์ (ว่าง = จันทร์-ศุกร์)
+	Departments       []string `json:"departments"`
+	Users             []string `json:"users"`
+	IsDefault         bool     `json:"is_default"` // กะเริ่มต้นของบริษัท (มีได้กะเดียว)
+}
+
+type AttendancePunchDTO struct {
+	Latitude  float64 `json:"latitude"`   // (จำเป็น)
+	Longitude float64 `json:"longitude"`  // (จำเป็น)
+	AccuracyM float64 `json:"accuracy_m"` // ความแม่นยำจากเบราว์เซอร์ (เมตร)
+	Note      string  `json:"note"`       // หมายเหตุ / เหตุผลทำ OT ตอนลงเวลาออก
+}
+
+type AttendanceOvertimeDecisionDTO struct {
+	Approve         bool   `json:"approve"`
+	ApprovedMinutes *int   `json:"approved_minutes"` // ว่าง = ตามที่คำนวณได้
+	Note            string `json:"note"`
+}
+
+type AttendanceCorrectionDTO struct {
+	UserID   string `json:"user_id"`   // พนักงาน (จำเป็น)
+	WorkDate string `json:"work_date"` // YYYY-MM-DD (จำเป็น)
+	CheckIn  string `json:"check_in"`  // HH:MM (ว่าง = คงเดิม)
+	CheckOut string `json:"check_out"` // HH:MM (ว่าง = คงเดิม, น้อยกว่าเวลาเข้า = วันถัดไป)
+	Reason   string `json:"reason"`    // เหตุผล (จำเป็น)
+}
+
+type RequestListAttendance struct {
+	UserID       string `query:"user_id"`
+	DepartmentID string `query:"department_id"`
+	StartDate    string `query:"start_date"` // YYYY-MM-DD
+	EndDate      string `query:"end_date"`   // YYYY-MM-DD
+	OTStatus     string `query:"ot_status"`  // pending|approved|rejected
+	Page         int    `query:"page"`
+	Limit        int    `query:"limit"`
+}
+
+type RequestAttendanceSummary struct {
+	Month        string `query:"month"`         // YYYY-MM (ว่าง = เดือนนี้)
+	UserID       string `query:"user_id"`       // ว่าง = ทุกคนที่มีสิทธิ์ดู
+	DepartmentID string `query:"department_id"` // กรองตามแผนก
+}
+
+// ---------- Response DTO ----------
+
+type AttendanceSiteDTO struct {
+	SiteID      string    `json:"site_id"`
+	Name        string    `json:"name"`
+	SiteType    string    `json:"site_type"`
+	JobID       string    `json:"job_id"`
+	Latitude    float64   `json:"latitude"`
+	Longitude   float64   `json:"longitude"`
+	RadiusM     float64   `json:"radius_m"`
+	Departments []string  `json:"departments"`
+	IsActive    bool      `json:"is_active"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type WorkShiftDTO struct {
+	ShiftID           string     `json:"shift_id"` // ว่าง = กะมาตรฐานของระบบ
+	Name              string     `json:"name"`
+	StartTime         string     `json:"start_time"`
+	EndTime           string     `json:"end_time"`
+	BreakMinutes      int        `json:"break_minutes"`
+	LateGraceMinutes  int        `json:"late_grace_minutes"`
+	EarlyGraceMinutes int        `json:"early_grace_minutes"`
+	OTMinMinutes      int        `json:"ot_min_minutes"`
+	WorkDays          []int      `json:"work_days"`
+	Departments       []string   `json:"departments"`
+	Users             []string   `json:"users"`
+	IsDefault         bool       `json:"is_default"`
+	UpdatedAt         *time.Time `json:"updated_at"`
+}
+
+type AttendanceRecordDTO struct {
+	RecordID          string                 `json:"record_id"`
+	UserID            string                 `json:"user_id"`
+	UserName          string                 `json:"user_name"`
+	DepartmentID      string                 `json:"department_id"`
+	DepartmentName    string                 `json:"department_name"`
+	WorkDate          string                 `json:"work_date"` // YYYY-MM-DD
+	ShiftName         string                 `json:"shift_name"`
+	ShiftStart        time.Time              `json:"shift_start"`
+	ShiftEnd          time.Time              `json:"shift_end"`
+	Scheduled         bool                   `json:"scheduled"`
+	CheckIn           *AttendancePunchInfo   `json:"check_in"`
+	CheckOut          *AttendancePunchInfo   `json:"check_out"`
+	WorkedMinutes     int                    `json:"worked_minutes"`
+	LateMinutes       int                    `json:"late_minutes"`
+	EarlyLeaveMinutes int                    `json:"early_leave_minutes"`
+	OvertimeMinutes   int                    `json:"overtime_minutes"`
+	OTStatus          string                 `json:"ot_status"`
+	OTApprovedMinutes int                    `json:"ot_approved_minutes"`
+	OTReason          string                 `json:"ot_reason"`
+	OTReviewedBy      string                 `json:"ot_reviewed_by"`
+	OTReviewNote      string                 `json:"ot_review_note"`
+	Corrections       []AttendanceCorrection `json:"corrections"`
+}
+
+type AttendancePunchInfo struct {
+	At        time.Time `json:"at"`
+	Source    string    `json:"source"`
+	SiteID    string    `json:"site_id"`
+	SiteName  string    `json:"site_name"`
+	DistanceM float64   `json:"distance_m"`
+	AccuracyM float64   `json:"accuracy_m"`
+	Note      string    `json:"note"`
+}
+
+type AttendanceCorrection struct {
+	CorrectedAt     time.Time  `json:"corrected_at"`
+	CorrectedBy     string     `json:"corrected_by"`
+	CorrectedByName string     `json:"corrected_by_name"`
+	Reason          string     `json:"reason"`
+	PrevCheckIn     *time.Time `json:"prev_check_in"`
+	PrevCheckOut    *time.Time `json:"prev_check_out"`
+	CheckIn         *time.Time `json:"check_in"`
+	CheckOut        *time.Time `json:"check_out"`
+}
+
+type AttendanceTodayDTO struct {
+	Date    string               `json:"date"`
+	Shift   WorkShiftDTO         `json:"shift"`
+	Record  *AttendanceRecordDTO `json:"record"` // nil = ยังไม่ลงเวลาเข้า
+	OnLeave bool                 `json:"on_leave"`
+	Holiday string               `json:"holiday"` // ชื่อวันหยุด (ว่าง = ไม่ใช่วันหยุด)
+}
+
+// AttendanceMonthSummaryDTO สรุปเวลาทำงานรายเดือนต่อพนักงาน ใช้เป็นข้อมูลตั้งต้นของเงินเดือนและ KPI
+type AttendanceMonthSummaryDTO struct {
+	Month             string  `json:"month"` // YYYY-MM
+	UserID            string  `json:"user_id"`
+	UserName          string  `json:"user_name"`
+	EmployeeCode      string  `json:"employee_code"`
+	DepartmentID      string  `json:"department_id"`
+	DepartmentName    string  `json:"department_name"`
+	ShiftName         string  `json:"shift_name"`
+	ScheduledDays     int     `json:"scheduled_days"`    // วันทำงานตามกะ (หักวันหยุดแล้ว)
+	ElapsedDays       int     `json:"elapsed_days"`      // วันทำงานที่ผ่านมาแล้ว (ถึงเมื่อวาน)
+	PresentDays       int     `json:"present_days"`      // วันทำงานที่มาลงเวลา
+	HolidayWorkDays   int     `json:"holiday_work_days"` // มาทำงานในวันหยุด
+	LeaveDays         float64 `json:"leave_days"`        // ลาที่อนุมัติแล้ว
+	UnpaidLeaveDays   float64 `json:"unpaid_leave_days"` // ลาที่เกินสิทธิ์ได้รับค่าจ้างในเดือนนี้
+	AbsentDays        float64 `json:"absent_days"`       // ขาดงาน (ไม่มาและไม่ได้ลา)
+	LateCount         int     `json:"late_count"`
+	LateMinutes       int     `json:"late_minutes"`
+	EarlyLeaveCount   int     `json:"early_leave_count"`
+	EarlyLeaveMinutes int     `json:"early_leave_minutes"`
+	IncompleteDays    int     `json:"incomplete_days"` // ลงเวลาเข้าแต่ไม่ลงเวลาออก
+	WorkedHours       float64 `json:"worked_hours"`
+	OTApprovedHours   float64 `json:"ot_approved_hours"` // ใช้คิดค่าล่วงเวลา
+	OTHolidayHours    float64 `json:"ot_holiday_hours"`  // ส่วนของ OT ที่อนุมัติซึ่งทำในวันหยุด (ค่าตอบแทนต่างอัตรา)
+	OTPendingHours    float64 `json:"ot_pending_hours"`
+	Corrections       int     `json:"corrections"`
+	AttendanceRate    float64 `json:"attendance_rate"`  // % มาทำงาน+ลา เทียบวันทำงานที่ผ่านมา
+	PunctualityRate   float64 `json:"punctuality_rate"` // % วันที่มาตรงเวลาเทียบวันที่มาทำงาน
+}
@@ -0,0 +1,126 @@
+package dto
+
+import "time"
+
+// ---------- Request DTO ----------
+type RequestTaskBoard struct {
+	GroupBy      string `query:"group_by"`      // status|step|assignee (ค่าเริ่มต้น status)
+	DepartmentID string `query:"department_id"` // แผนก
+	WorkFlowID   string `query:"workflow_id"`   // workflow (group_by=step จะเรียงคอลัมน์ตามลำดับ step)
+	Assignee     string `query:"assignee"`      // ผู้รับผิดชอบหลัก
+	Search       string `query:"search"`        // ค้นหาชื่อโปรเจกต์/งาน/ผู้รับผิดชอบ
+	DoneDays     int    `query:"done_days"`     // คอลัมน์ done แสดงงานที่ปิดภายในกี่วัน (ค่าเริ่มต้น 14)
+	CardLimit    int    `query:"card_limit"`    // จำนวนการ์ดสูงสุดต่อคอลัมน์ (ค่าเริ่มต้น 50; count ยังนับทั้งหมด)
+}
+
+// BoardMoveDTO ลากการ์ดไปคอลัมน์อื่น ระบบจะเปลี่ยนสถานะ step ให้สอดคล้อง
+type BoardMoveDTO struct {
+	TaskID   string `json:"task_id"`
+	GroupBy  string `json:"group_by"`          // status|step|assignee
+	ToColumn string `json:"to_column"`         // คอลัมน์ปลายทาง (สถานะ/ชื่อ step/user_id)
+	StepID   string `json:"step_id,omitempty"` // step ที่ต้องการเปลี่ยน (งานคู่ขนานที่มีหลาย step เริ่มได้)
+	Force    bool   `json:"force,omitempty"`   // ข้าม WIP limit (admin เท่านั้น)
+}
+
+type UpsertBoardWIPLimitDTO struct {
+	GroupBy      string `json:"group_by"`                // status|step|assignee
+	ColumnKey    string `json:"column_key"`              // ค่าคอลัมน์
+	WorkFlowID   string `json:"workflow_id,omitempty"`   // ว่าง = ทุก workflow
+	DepartmentID string `json:"department_id,omitempty"` // ว่าง = ทุกแผนก
+	Limit        int    `json:"limit"`                   // 0 = ยกเลิก limit
+}
+
+type RequestListBoardWIPLimits struct {
+	GroupBy      string `query:"group_by"`
+	WorkFlowID   string `query:"workflow_id"`
+	DepartmentID string `query:"department_id"`
+}
+
+type RequestTaskCalendar struct {
+	From         string `query:"from"` // YYYY-MM-DD
+	To           string `query:"to"`   // YYYY-MM-DD (ไม่เกิน 93 วัน)
+	DepartmentID string `query:"department_id"`
+	Assignee     string `query:"assignee"` // ผู้รับผิดชอบงานหรือ step
+	WorkFlowID   string `query:"workflow_id"`
+	IncludeSteps *bool  `query:"include_steps"` // แสดงช่วงเวลาของ step ด้วย (ค่าเริ่มต้น true)
+}
+
+// ---------- Response DTO ----------
+type TaskCardDTO struct {
+	StartDate     time.Time `json:"start_date"`
+	EndDate       time.Time `json:"end_date"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	TaskID        string    `json:"task_id"`
+	ProjectName   string    `json:"project_name"`
+	JobID         string    `json:"job_id"`
+	JobName       string    `json:"job_name"`
+	Department    string    `json:"department_id"`
+	Assignee      string    `json:"assignee"`
+	AssigneeName  string    `json:"assignee_name"`
+	Importance    string    `json:"importance"`
+	Status        string    `json:"status"`
+	StepName      string    `json:"step_name"`
+	CurrentStepID string    `json:"current_step_id,omitempty"` // step ที่กำลังทำ/เริ่มได้
+	ReadySteps    []string  `json:"ready_steps,omitempty"`
+	StepsDone     int       `json:"steps_done"`
+	StepsTotal    int       `json:"steps_total"`
+	Progress      float64   `json:"progress"` // % step ที่ปิดแล้ว
+	SLAStatus     string    `json:"sla_status,omitempty"`
+	Overdue       bool      `json:"overdue"` // เลยวันสิ้นสุดแล้วยังไม่เสร็จ
+}
+
+type BoardColumnDTO struct {
+	Key       string        `json:"key"`
+	Title     string        `json:"title"`
+	Count     int           `json:"count"`     // จำนวนงานทั้งหมดในคอลัมน์
+	WIPLimit  int           `json:"wip_limit"` // 0 = ไม่จำกัด
+	OverLimit bool          `json:"over_limit"`
+	Tasks     []TaskCardDTO `json:"tasks"`
+}
+
+type TaskBoardDTO struct {
+	GroupBy string           `json:"group_by"`
+	Total   int              `json:"total"`
+	Columns []BoardColumnDTO `json:"columns"`
+}
+
+type BoardWIPLimitDTO struct {
+	UpdatedAt    time.Time `json:"updated_at"`
+	LimitID      string    `json:"limit_id"`
+	GroupBy      string    `json:"group_by"`
+	ColumnKey    string    `json:"column_key"`
+	WorkFlowID   string    `json:"workflow_id,omitempty"`
+	DepartmentID string    `json:"department_id,omitempty"`
+	Limit        int       `json:"limit"`
+	UpdatedBy    string    `json:"updated_by"`
+}
+
+type CalendarEventDTO struct {
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	Type         string    `json:"type"` // task|step|holiday
+	AllDay       bool      `json:"all_day"`
+	Title        string    `json:"title"`
+	TaskID       string    `json:"task_id,omitempty"`
+	StepID       string    `json:"step_id,omitempty"`
+	JobID        string    `json:"job_id,omitempty"`
+	Status       string    `json:"status,omitempty"`
+	Assignee     string    `json:"assignee,omitempty"`
+	AssigneeName string    `json:"assignee_name,omitempty"`
+	Department   string    `json:"department_id,omitempty"`
+	SLAStatus    string    `json:"sla_status,omitempty"`
+}
+
+type CalendarDayDTO struct {
+	Date      string `json:"date"` // YYYY-MM-DD
+	Tasks     int    `json:"tasks"`
+	Steps     int    `json:"steps"`
+	IsHoliday bool   `json:"is_holiday"`
+}
+
+type TaskCalendarDTO struct {
+	From   string             `json:"from"`
+	To     string             `json:"to"`
+	Events []CalendarEventDTO `json:"events"`
+	Days   []CalendarDayDTO   `json:"days"`
+}
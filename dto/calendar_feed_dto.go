@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+// ---------- Request DTO ----------
+type CreateCalendarFeedDTO struct {
+	Name         string `json:"name"`                    // ชื่อปฏิทิน (ว่าง = "งานของฉัน")
+	DepartmentID string `json:"department_id,omitempty"` // ผู้จัดการแผนก: ปฏิทินของทั้งแผนก
+	IncludeSteps *bool  `json:"include_steps,omitempty"` // ค่าเริ่มต้น true
+	IncludeJobs  *bool  `json:"include_jobs,omitempty"`  // ค่าเริ่มต้น true
+}
+
+// ---------- Response DTO ----------
+type CalendarFeedDTO struct {
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	LastAccessAt *time.Time `json:"last_access_at"`
+	FeedID       string     `json:"feed_id"`
+	UserID       string     `json:"user_id"`
+	Name         string     `json:"name"`
+	TokenHint    string     `json:"token_hint"`
+	DepartmentID string     `json:"department_id,omitempty"`
+	IncludeSteps bool       `json:"include_steps"`
+	IncludeJobs  bool       `json:"include_jobs"`
+	Active       bool       `json:"active"`
+}
+
+// CalendarFeedTokenDTO ลิงก์สำหรับ subscribe (แสดง token ครั้งเดียว)
+type CalendarFeedTokenDTO struct {
+	Feed    CalendarFeedDTO `json:"feed"`
+	Token   string          `json:"token"`
+	FeedURL string          `json:"feed_url"` // URL สำหรับเพิ่มใน Google Calendar / ปฏิทินมือถือ
+}
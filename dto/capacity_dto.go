@@ -0,0 +1,97 @@
+package dto
+
+import "time"
+
+// ---------- Request DTO ----------
+
+type UpsertDepartmentCapacityDTO struct {
+	DepartmentID string  `json:"department_id"` // แผนก (จำเป็น)
+	WorkDays     []int   `json:"work_days"`     // วันทำงาน 0=อาทิตย์ .. 6=เสาร์ (ว่าง = จันทร์-ศุกร์)
+	HoursPerDay  float64 `json:"hours_per_day"` // ชั่วโมงทำงานต่อคนต่อวัน
+	Efficiency   float64 `json:"efficiency"`    // สัดส่วนเวลาที่ใช้ผลิตจริง (0-1) ว่าง = 1
+}
+
+type CreateHolidayDTO struct {
+	Date         string `json:"date"`          // วันที่ (YYYY-MM-DD)
+	Name         string `json:"name"`          // ชื่อวันหยุด
+	DepartmentID string `json:"department_id"` // แผนก (ว่าง = ทุกแผนก)
+}
+
+type RequestListHolidays struct {
+	Year         int    `query:"year"`          // ปี ค.ศ. (ว่าง = ปีปัจจุบัน)
+	DepartmentID string `query:"department_id"` // กรองตามแผนก (รวมวันหยุดทั้งบริษัท)
+}
+
+type RequestEarliestCompletion struct {
+	SignTypeID    string  `query:"sign_type_id"`   // ประเภทป้าย (จำเป็น)
+	InstallOption string  `query:"install_option"` // none|self|shop
+	Width         float64 `query:"width"`          // กว้าง (ซม.)
+	Height        float64 `query:"height"`         // สูง (ซม.)
+	Quantity      int     `query:"quantity"`       // จำนวน (ว่าง = 1)
+	StartDate     string  `query:"start_date"`     // เริ่มนับจากวันที่ (YYYY-MM-DD) ว่าง = วันนี้
+}
+
+type RequestCapacityLoad struct {
+	DepartmentID string `query:"department_id"` // แผนก (ว่าง = ทุกแผนก)
+	Weeks        int    `query:"weeks"`         // จำนวนสัปดาห์ (ค่าเริ่มต้น 4)
+}
+
+// ---------- Response DTO ----------
+
+type DepartmentCapacityDTO struct {
+	UpdatedAt      time.Time `json:"updated_at"`
+	CapacityID     string    `json:"capacity_id"`
+	DepartmentID   string    `json:"department_id"`
+	DepartmentName string    `json:"department_name"`
+	WorkDays       []int     `json:"work_days"`
+	HoursPerDay    float64   `json:"hours_per_day"`
+	Efficiency     float64   `json:"efficiency"`
+	People         int       `json:"people"`          // จำนวนพนักงานในแผนก
+	DailyHours     float64   `json:"daily_hours"`     // ชั่วโมงผลิตต่อวันทำงาน
+	OpenTaskHours  float64   `json:"open_task_hours"` // ชั่วโมงงานค้าง (step ที่ยังไม่เสร็จ)
+}
+
+type HolidayDTO struct {
+	Date         time.Time `json:"date"`
+	HolidayID    string    `json:"holiday_id"`
+	Name         string    `json:"name"`
+	DepartmentID string    `json:"department_id,omitempty"`
+}
+
+type CompletionStageDTO struct {
+	StartDate      time.Time `json:"start_date"`      // วันที่เริ่มได้เร็วที่สุด
+	EndDate        time.Time `json:"end_date"`        // วันที่เสร็จ
+	WorkFlowID     string    `json:"workflow_id"`     // workflow
+	WorkFlowName   string    `json:"workflow_name"`   // ชื่อ workflow
+	DepartmentID   string    `json:"department_id"`   // แผนกที่ทำ
+	DepartmentName string    `json:"department_name"` // ชื่อแผนก
+	Hours          float64   `json:"hours"`           // ชั่วโมงที่ต้องใช้ (ปรับตามขนาดแล้ว)
+}
+
+type EarliestCompletionDTO struct {
+	CompletionDate time.Time            `json:"completion_date"` // วันที่เสร็จเร็วที่สุด
+	SignTypeID     string               `json:"sign_type_id"`
+	InstallOption  string               `json:"install_option,omitempty"`
+	AreaSqm        float64              `json:"area_sqm"`    // พื้นที่ต่อชิ้น (ตร.ม.)
+	SizeFactor     float64              `json:"size_factor"` // ตัวคูณชั่วโมงตามขนาดและจำนวน
+	TotalHours     float64              `json:"total_hours"` // ชั่วโมงรวมของงานใหม่
+	LeadDays       int                  `json:"lead_days"`   // จำนวนวันนับจากวันเริ่มถึงวันเสร็จ
+	Stages         []CompletionStageDTO `json:"stages"`
+}
+
+type CapacityLoadWeekDTO struct {
+	WeekStart     time.Time `json:"week_start"`      // วันจันทร์ของสัปดาห์
+	WeekEnd       time.Time `json:"week_end"`        // วันอาทิตย์ของสัปดาห์
+	CapacityHours float64   `json:"capacity_hours"`  // ชั่วโมงที่ทำได้
+	LoadHours     float64   `json:"load_hours"`      // ชั่วโมงงานที่วางแผนไว้
+	FreeHours     float64   `json:"free_hours"`      // ชั่วโมงว่าง (ติดลบ = เกินกำลัง)
+	UtilizationPc float64   `json:"utilization_pct"` // อัตราการใช้กำลังการผลิต (%)
+}
+
+type CapacityLoadDTO struct {
+	DepartmentID   string                `json:"department_id"`
+	DepartmentName string                `json:"department_name"`
+	People         int                   `json:"people"`
+	OverdueHours   float64               `json:"overdue_hours"` // ชั่วโมงของงานที่เลยกำหนดแล้ว (รวมไว้ในสัปดาห์แรก)
+	Weeks          []CapacityLoadWeekDTO `json:"weeks"`
+}
@@ -0,0 +1,80 @@
+package dto
+
+import "time"
+
+// ---------- Request DTO ----------
+
+type CreateCommentDTO struct {
+	EntityType    string   `json:"entity_type" example:"task"` // task|sign_job
+	EntityID      string   `json:"entity_id"`
+	StepID        string   `json:"step_id,omitempty"`   // เฉพาะ task
+	ParentID      string   `json:"parent_id,omitempty"` // ตอบกลับความคิดเห็น
+	Body          string   `json:"body" example:"ตรวจแบบแล้ว @[สมชาย](user-id) ช่วยยืนยันสีอีกครั้ง"`
+	Mentions      []string `json:"mentions,omitempty"`       // user_id เพิ่มเติม (นอกจาก @[ชื่อ](user_id) ในข้อความ)
+	AttachmentIDs []string `json:"attachment_ids,omitempty"` // ไฟล์ที่อัปโหลดไว้กับ task/step/งานป้ายเดียวกัน
+}
+
+type UpdateCommentDTO struct {
+	Body          string   `json:"body"`
+	Mentions      []string `json:"mentions,omitempty"`
+	AttachmentIDs []string `json:"attachment_ids,omitempty"`
+}
+
+type RequestListComments struct {
+	EntityType string `query:"entity_type"`
+	EntityID   string `query:"entity_id"`
+	StepID     string `query:"step_id"`
+}
+
+type RequestActivityFeed struct {
+	EntityType string `query:"entity_type"` // task|sign_job
+	EntityID   string `query:"entity_id"`
+	Limit      int    `query:"limit"` // ค่าเริ่มต้น 100
+}
+
+// ---------- Response DTO ----------
+
+type CommentDTO struct {
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	DeletedAt   *time.Time       `json:"deleted_at,omitempty"`
+	CommentID   string           `json:"comment_id"`
+	EntityType  string           `json:"entity_type"`
+	EntityID    string           `json:"entity_id"`
+	StepID      string           `json:"step_id,omitempty"`
+	ParentID    string           `json:"parent_id,omitempty"`
+	Body        string           `json:"body"`
+	Mentions    []MentionDTO     `json:"mentions"`
+	Attachments []AttachmentDTO  `json:"attachments"`
+	AuthorID    string           `json:"author_id"`
+	AuthorName  string           `json:"author_name"`
+	Edited      bool             `json:"edited"`
+	Deleted     bool             `json:"deleted"`
+	Edits       []CommentEditDTO `json:"edits,omitempty"`
+	Replies     []CommentDTO     `json:"replies"`
+}
+
+type MentionDTO struct {
+	UserID   string `json:"user_id"`
+	UserName string `json:"user_name"`
+}
+
+type CommentEditDTO struct {
+	EditedAt time.Time `json:"edited_at"`
+	EditedBy string    `json:"edited_by"`
+	PrevBody string    `json:"prev_body"`
+}
+
+type ActivityFeedItemDTO struct {
+	At        time.Time   `json:"at"`
+	Type      string      `json:"type"` // comment|step_status|step_note|step_assigned|task_status|task_updated
+	TaskID    string      `json:"task_id,omitempty"`
+	StepID    string      `json:"step_id,omitempty"`
+	StepName  string      `json:"step_name,omitempty"`
+	FromValue string      `json:"from_value,omitempty"`
+	ToValue   string      `json:"to_value,omitempty"`
+	Message   string      `json:"message,omitempty"`
+	ActorID   string      `json:"actor_id"`
+	ActorName string      `json:"actor_name"`
+	Comment   *CommentDTO `json:"comment,omitempty"`
+}
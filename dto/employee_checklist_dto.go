@@ -0,0 +1,127 @@
+package dto
+
+import "time"
+
+// ---------- Request DTO ----------
+
+type UpsertChecklistTemplateDTO struct {
+	TemplateID string                     `json:"template_id"` // ว่าง = สร้างใหม่
+	Kind       string                     `json:"kind"`        // onboarding|offboarding (จำเป็น)
+	PositionID string                     `json:"position_id"` // ว่าง = ค่าเริ่มต้นของบริษัท
+	Name       string                     `json:"name"`        // ชื่อแม่แบบ (จำเป็น)
+	Items      []ChecklistTemplateItemDTO `json:"items"`       // อย่างน้อย 1 รายการ
+}
+
+type ChecklistTemplateItemDTO struct {
+	ItemID      string `json:"item_id"` // ว่าง = รายการใหม่
+	Title       string `json:"title"`   // (จำเป็น)
+	Description string `json:"description"`
+	Owner       string `json:"owner"`      // hr|it|manager (system เฉพาะ auto_check ของ offboarding)
+	Mandatory   bool   `json:"mandatory"`  // บังคับ
+	DueDays     int    `json:"due_days"`   // นับจากวันเริ่มงาน/วันทำงานวันสุดท้าย
+	AutoCheck   string `json:"auto_check"` // id_card|bank_info|document:<type>|tasks_reassigned|access_revoked (ว่าง = ติ๊กเอง)
+}
+
+type RequestListChecklistTemplates struct {
+	Kind       string `query:"kind"`        // onboarding|offboarding
+	PositionID string `query:"position_id"` // กรองตามตำแหน่ง
+}
+
+type RequestListChecklists struct {
+	Kind         string `query:"kind"`   // onboarding|offboarding
+	Status       string `query:"status"` // open|completed
+	DepartmentID string `query:"department_id"`
+	Page         int    `query:"page"`
+	Limit        int    `query:"limit"`
+}
+
+type UpdateChecklistItemDTO struct {
+	Done bool   `json:"done"` // true = เสร็จ, false = เปิดกลับ
+	Note string `json:"note"`
+}
+
+type StartOffboardingDTO struct {
+	UserID          string `json:"user_id"`           // พนักงานที่ออก (จำเป็น)
+	LastWorkingDate string `json:"last_working_date"` // YYYY-MM-DD (จำเป็น) ยกเลิกสิทธิ์เข้าระบบหลังสิ้นวันนี้
+	ReassignTo      string `json:"reassign_to"`       // ผู้รับงานที่ยังไม่ปิด (ว่าง = ผู้จัดการแผนก)
+	Note            string `json:"note"`
+}
+
+type CancelOffboardingDTO struct {
+	Note string `json:"note"` // เหตุผล เช่น เปลี่ยนใจไม่ออก / รับกลับเข้าทำงาน
+}
+
+// ---------- Response DTO ----------
+
+type ChecklistTemplateDTO struct {
+	TemplateID   string                     `json:"template_id"`
+	Kind         string                     `json:"kind"`
+	PositionID   string                     `json:"position_id"`
+	PositionName string                     `json:"position_name"` // ว่าง = ค่าเริ่มต้นของบริษัท
+	Name         string                     `json:"name"`
+	Items        []ChecklistTemplateItemDTO `json:"items"`
+	UpdatedBy    string                     `json:"updated_by"`
+	UpdatedAt    time.Time                  `json:"updated_at"`
+}
+
+type EmployeeChecklistDTO struct {
+	ChecklistID      string             `json:"checklist_id"`
+	Kind             string             `json:"kind"`
+	UserID           string             `json:"user_id"`
+	UserName         string             `json:"user_name"`
+	EmployeeCode     string             `json:"employee_code"`
+	PositionID       string             `json:"position_id"`
+	PositionName     string             `json:"position_name"`
+	DepartmentID     string             `json:"department_id"`
+	DepartmentName   string             `json:"department_name"`
+	TemplateID       string             `json:"template_id"` // ว่าง = รายการมาตรฐานของระบบ
+	Status           string             `json:"status"`
+	StartDate        time.Time          `json:"start_date"`
+	Done             int                `json:"done"`
+	Total            int                `json:"total"`
+	MandatoryPending int                `json:"mandatory_pending"` // onboarding: ต้องเป็น 0 จึงอนุมัติผู้ใช้ได้
+	Items            []ChecklistItemDTO `json:"items"`
+	ReassignTo       string             `json:"reassign_to"`
+	ReassignToName   string             `json:"reassign_to_name"`
+	ReassignedTasks  int                `json:"reassigned_tasks"`
+	AccessRevokedAt  *time.Time         `json:"access_revoked_at"`
+	CompletedAt      *time.Time         `json:"completed_at"`
+	Note             string             `json:"note"`
+	CreatedAt        time.Time          `json:"created_at"`
+}
+
+type ChecklistItemDTO struct {
+	ItemID          string     `json:"item_id"`
+	Title           string     `json:"title"`
+	Description     string     `json:"description"`
+	Owner           string     `json:"owner"`
+	OwnerUserID     string     `json:"owner_user_id"`
+	OwnerName       string     `json:"owner_name"`
+	Mandatory       bool       `json:"mandatory"`
+	AutoCheck       string     `json:"auto_check"`
+	Status          string     `json:"status"`
+	DueDate         time.Time  `json:"due_date"`
+	Overdue         bool       `json:"overdue"`
+	CompletedBy     string     `json:"completed_by"`
+	CompletedByName string     `json:"completed_by_name"`
+	CompletedAt     *time.Time `json:"completed_at"`
+	Note            string     `json:"note"`
+}
+
+// MyChecklistItemDTO รายการที่ค้างอยู่กับผู้ใช้ (ผู้จัดการ: รายการของลูกทีม, admin: รายการ HR/IT)
+type MyChecklistItemDTO struct {
+	ChecklistID string           `json:"checklist_id"`
+	Kind        string           `json:"kind"`
+	UserID      string           `json:"user_id"`
+	UserName    string           `json:"user_name"`
+	Item        ChecklistItemDTO `json:"item"`
+}
+
+// ChecklistRunResult ผลการรันยกเลิกสิทธิ์พนักงานที่พ้นวันทำงานวันสุดท้าย
+type ChecklistRunResult struct {
+	RunAt           time.Time `json:"run_at"`
+	Due             int       `json:"due"`              // offboarding ที่ถึงกำหนดยกเลิกสิทธิ์
+	Revoked         int       `json:"revoked"`          // ยกเลิกสิทธิ์แล้ว
+	ReassignedTasks int       `json:"reassigned_tasks"` // งานที่โอนเพิ่มในรอบนี้
+	Errors          []string  `json:"errors"`
+}
@@ -0,0 +1,59 @@
+package dto
+
+import "time"
+
+// ---------- Request DTO ----------
+
+type RequestEmploymentHeadcount struct {
+	Date         string `query:"date"`          // YYYY-MM-DD (ว่าง = วันนี้)
+	DepartmentID string `query:"department_id"` // กรองตามแผนก
+}
+
+// ---------- Response DTO ----------
+
+type EmploymentTimelineDTO struct {
+	UserID       string                       `json:"user_id"`
+	UserName     string                       `json:"user_name"`
+	EmployeeCode string                       `json:"employee_code"`
+	HireDate     time.Time                    `json:"hire_date"`
+	Entries      []EmploymentTimelineEntryDTO `json:"entries"` // เรียงจากเก่าไปใหม่ รวมรายการที่ตั้งเวลาไว้
+}
+
+type EmploymentTimelineEntryDTO struct {
+	HistoryID      string     `json:"history_id"`
+	FromDate       time.Time  `json:"from_date"`
+	ToDate         *time.Time `json:"to_date"`
+	PositionID     string     `json:"position_id"`
+	PositionName   string     `json:"position_name"`
+	DepartmentID   string     `json:"department_id"`
+	DepartmentName string     `json:"department_name"`
+	EmploymentType string     `json:"employment_type"`
+	ChangeType     string     `json:"change_type"` // hire|change
+	Status         string     `json:"status"`      // applied|scheduled
+	State          string     `json:"state"`       // past|current|upcoming
+	Changes        []string   `json:"changes"`     // position|department|employment_type ที่ต่างจากรายการก่อนหน้า
+	ChangedBy      string     `json:"changed_by"`
+	ChangedByName  string     `json:"changed_by_name"`
+	Note           string     `json:"note"`
+}
+
+type EmploymentHeadcountDTO struct {
+	Date        string                   `json:"date"` // YYYY-MM-DD
+	Total       int                      `json:"total"`
+	Departments []EmploymentHeadcountRow `json:"departments"`
+}
+
+type EmploymentHeadcountRow struct {
+	DepartmentID     string         `json:"department_id"`
+	DepartmentName   string         `json:"department_name"`
+	Headcount        int            `json:"headcount"`
+	ByEmploymentType map[string]int `json:"by_employment_type"`
+}
+
+// EmploymentHistoryRunResult ผลการปรับข้อมูลพนักงานตามรายการที่ถึงวันมีผล
+type EmploymentHistoryRunResult struct {
+	RunAt   time.Time `json:"run_at"`
+	Due     int       `json:"due"`     // รายการที่ถึงวันมีผล
+	Applied int       `json:"applied"` // พนักงานที่ปรับข้อมูลแล้ว
+	Errors  []string  `json:"errors"`
+}
@@ -12,6 +12,7 @@ type CreateExpenseDTO struct {
 	TxnDate               string  `json:"txn_date" binding:"required"`
 	PaymentMethod         string  `json:"payment_method,omitempty"`
 	ReferenceNo           string  `json:"reference_no,omitempty"`
+	JobID                 string  `json:"job_id,omitempty"` // รหัสงานป้ายที่เกี่ยวข้อง (ถ้ามี)
 	Amount                float64 `json:"amount" binding:"required"`
 }
 
@@ -24,6 +25,7 @@ type UpdateExpenseDTO struct {
 	TxnDate               string  `json:"txn_date" binding:"required"`
 	PaymentMethod         string  `json:"payment_method,omitempty"`
 	ReferenceNo           string  `json:"reference_no,omitempty"`
+	JobID                 string  `json:"job_id,omitempty"` // รหัสงานป้ายที่เกี่ยวข้อง (ถ้ามี)
 	Amount                float64 `json:"amount" binding:"required"`
 }
 
@@ -60,6 +62,7 @@ type ExpenseDTO struct {
 	Currency                  string     `json:"currency"`
 	PaymentMethod             string     `json:"payment_method,omitempty"`
 	ReferenceNo               string     `json:"reference_no,omitempty"`
+	JobID                     string     `json:"job_id,omitempty"`
 	CreatedBy                 string     `json:"created_by"`
 	Amount                    float64    `json:"amount"`
 }
@@ -0,0 +1,100 @@
+package dto
+
+import "time"
+
+// ---------- Request DTO ----------
+
+type CreateJobMaterialDTO struct {
+	JobID    string  `json:"job_id"`    // รหัสงานป้าย (จำเป็น)
+	Name     string  `json:"name"`      // ชื่อวัสดุ (จำเป็น)
+	Unit     string  `json:"unit"`      // หน่วยนับ
+	UsedAt   string  `json:"used_at"`   // วันที่เบิกใช้ (YYYY-MM-DD) ว่างได้ = วันนี้
+	Note     string  `json:"note"`      // หมายเหตุ
+	Quantity float64 `json:"quantity"`  // จำนวนที่ใช้
+	UnitCost float64 `json:"unit_cost"` // ต้นทุนต่อหน่วย
+}
+
+type UpsertLaborRateDTO struct {
+	UserID     string  `json:"user_id"`     // กำหนดรายบุคคล (เลือกอย่างใดอย่างหนึ่ง)
+	PositionID string  `json:"position_id"` // กำหนดรายตำแหน่ง (เลือกอย่างใดอย่างหนึ่ง)
+	HourlyRate float64 `json:"hourly_rate"` // ค่าแรงต่อชั่วโมง (บาท)
+}
+
+type RequestJobMarginSummary struct {
+	StartDate string `query:"start_date"` // วันที่เริ่ม (YYYY-MM-DD) อ้างอิงวันที่สร้างงาน
+	EndDate   string `query:"end_date"`   // วันที่สิ้นสุด (YYYY-MM-DD)
+}
+
+type RequestLeastProfitableJobs struct {
+	Month string `query:"month"` // เดือนที่ต้องการ (YYYY-MM) ว่างได้ = เดือนปัจจุบัน
+	Limit int    `query:"limit"` // จำนวนงานที่ต้องการ (ค่าเริ่มต้น 10)
+}
+
+// ---------- Response DTO ----------
+
+type JobMaterialDTO struct {
+	UsedAt     time.Time `json:"used_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	MaterialID string    `json:"material_id"`
+	JobID      string    `json:"job_id"`
+	Name       string    `json:"name"`
+	Unit       string    `json:"unit"`
+	Note       string    `json:"note"`
+	CreatedBy  string    `json:"created_by"`
+	Quantity   float64   `json:"quantity"`
+	UnitCost   float64   `json:"unit_cost"`
+	Total      float64   `json:"total"`
+}
+
+type LaborRateDTO struct {
+	UpdatedAt    time.Time `json:"updated_at"`
+	RateID       string    `json:"rate_id"`
+	UserID       string    `json:"user_id,omitempty"`
+	UserName     string    `json:"user_name,omitempty"`
+	PositionID   string    `json:"position_id,omitempty"`
+	PositionName string    `json:"position_name,omitempty"`
+	HourlyRate   float64   `json:"hourly_rate"`
+}
+
+type JobLaborLineDTO struct {
+	TaskID       string  `json:"task_id"`       // รหัสงาน
+	StepID       string  `json:"step_id"`       // รหัสขั้นตอน
+	StepName     string  `json:"step_name"`     // ชื่อขั้นตอน
+	AssigneeID   string  `json:"assignee_id"`   // ผู้รับผิดชอบ
+	AssigneeName string  `json:"assignee_name"` // ชื่อผู้รับผิดชอบ
+	Hours        float64 `json:"hours"`         // ชั่วโมงทำงาน
+	HourlyRate   float64 `json:"hourly_rate"`   // ค่าแรงต่อชั่วโมงที่ใช้คำนวณ
+	Cost         float64 `json:"cost"`          // ต้นทุนค่าแรง
+}
+
+type JobCostDTO struct {
+	CreatedAt          time.Time         `json:"created_at"`            // วันที่สร้างงาน
+	JobID              string            `json:"job_id"`                // รหัสงานป้าย
+	JobName            string            `json:"job_name"`              // ชื่องาน
+	CompanyName        string            `json:"company_name"`          // ชื่อลูกค้า
+	SignTypeID         string            `json:"sign_type_id"`          // ประเภทป้าย
+	SignTypeName       string            `json:"sign_type_name"`        // ชื่อประเภทป้าย
+	CustomerTypeID     string            `json:"customer_type_id"`      // ประเภทลูกค้า
+	CustomerTypeName   string            `json:"customer_type_name"`    // ชื่อประเภทลูกค้า
+	Status             string            `json:"status"`                // สถานะงาน
+	Materials          []JobMaterialDTO  `json:"materials,omitempty"`   // รายการวัสดุ
+	LaborLines         []JobLaborLineDTO `json:"labor_lines,omitempty"` // รายการค่าแรง
+	QuotedPrice        float64           `json:"quoted_price"`          // ราคาที่เสนอ (price_thb)
+	MaterialCost       float64           `json:"material_cost"`         // ต้นทุนวัสดุ
+	LaborCost          float64           `json:"labor_cost"`            // ต้นทุนค่าแรง
+	ExpenseCost        float64           `json:"expense_cost"`          // รายจ่ายที่ผูกกับงาน
+	PayableCost        float64           `json:"payable_cost"`          // เจ้าหนี้ที่ผูกกับงาน
+	TotalCost          float64           `json:"total_cost"`            // ต้นทุนรวม
+	GrossProfit        float64           `json:"gross_profit"`          // กำไรขั้นต้น
+	GrossMarginPercent float64           `json:"gross_margin_percent"`  // อัตรากำไรขั้นต้น (%)
+}
+
+type JobMarginGroupDTO struct {
+	GroupID            string  `json:"group_id"`             // รหัสกลุ่ม (sign_type_id / customer_type_id)
+	GroupName          string  `json:"group_name"`           // ชื่อกลุ่ม
+	JobCount           int     `json:"job_count"`            // จำนวนงาน
+	QuotedPrice        float64 `json:"quoted_price"`         // ราคาที่เสนอรวม
+	TotalCost          float64 `json:"total_cost"`           // ต้นทุนรวม
+	GrossProfit        float64 `json:"gross_profit"`         // กำไรขั้นต้นรวม
+	GrossMarginPercent float64 `json:"gross_margin_percent"` // อัตรากำไรขั้นต้น (%)
+}
@@ -0,0 +1,88 @@
+package dto
+
+import "time"
+
+// ---------- Request DTO ----------
+
+type RequestKPIAnalytics struct {
+	StartDate      string `query:"start_date"`      // YYYY-MM-DD (ว่าง = ต้นเดือนเมื่อ 11 เดือนก่อน)
+	EndDate        string `query:"end_date"`        // YYYY-MM-DD รวมวันสุดท้าย (ว่าง = วันนี้)
+	DepartmentID   string `query:"department_id"`   // กรองแผนก (ผู้จัดการเลือกได้เฉพาะแผนกที่ดูแล)
+	UserID         string `query:"user_id"`         // กรองพนักงาน (trends group_by=employee)
+	Interval       string `query:"interval"`        // month|quarter (ค่าเริ่มต้น month)
+	GroupBy        string `query:"group_by"`        // trends: employee|category|department (ค่าเริ่มต้น department)
+	BucketSize     int    `query:"bucket_size"`     // distribution: ขนาดช่วงคะแนน (ค่าเริ่มต้น 10)
+	Limit          int    `query:"limit"`           // performers: จำนวนอันดับบน/ล่าง (ค่าเริ่มต้น 5 สูงสุด 50)
+	MinEvaluations int    `query:"min_evaluations"` // performers/correlation: จำนวนการประเมินขั้นต่ำต่อคน (ค่าเริ่มต้น 1)
+}
+
+// ---------- Response DTO ----------
+
+type KPITrendPointDTO struct {
+	Period      string  `json:"period"`      // 2025-03 | 2025-Q1
+	Evaluations int     `json:"evaluations"` // จำนวนการประเมินในช่วง
+	Score       float64 `json:"score"`       // คะแนนเฉลี่ย 0..100 (0 เมื่อไม่มีการประเมิน)
+}
+
+type KPITrendSeriesDTO struct {
+	Key         string             `json:"key"`  // user_id | category | department_id | company
+	Name        string             `json:"name"` // ชื่อที่แสดง
+	Evaluations int                `json:"evaluations"`
+	Score       float64            `json:"score"`      // คะแนนเฉลี่ยทั้งช่วง
+	VsCompany   float64            `json:"vs_company"` // ต่างจากค่าเฉลี่ยบริษัททั้งช่วง (+ = สูงกว่า)
+	Points      []KPITrendPointDTO `json:"points"`
+}
+
+type KPITrendDTO struct {
+	GroupBy   string              `json:"group_by"`
+	Interval  string              `json:"interval"`
+	StartDate time.Time           `json:"start_date"`
+	EndDate   time.Time           `json:"end_date"`
+	Periods   []string            `json:"periods"`
+	Company   KPITrendSeriesDTO   `json:"company"` // ค่าเฉลี่ยทั้งบริษัท (ทุกแผนก)
+	Series    []KPITrendSeriesDTO `json:"series"`
+}
+
+type KPIHistogramBucketDTO struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"` // ไม่รวมค่านี้ ยกเว้นช่วงสุดท้าย (รวม 100)
+	Count int     `json:"count"`
+}
+
+type KPIDistributionDTO struct {
+	StartDate  time.Time               `json:"start_date"`
+	EndDate    time.Time               `json:"end_date"`
+	BucketSize int                     `json:"bucket_size"`
+	Employees  int                     `json:"employees"` // จำนวนพนักงานที่มีการประเมินในช่วง
+	Mean       float64                 `json:"mean"`
+	Median     float64                 `json:"median"`
+	Buckets    []KPIHistogramBucketDTO `json:"buckets"` // นับจากคะแนนเฉลี่ยรายคน
+}
+
+type KPIEmployeeStatDTO struct {
+	UserID         string  `json:"user_id"`
+	Name           string  `json:"name"`
+	DepartmentID   string  `json:"department_id"`
+	DepartmentName string  `json:"department_name"`
+	Evaluations    int     `json:"evaluations"`
+	Score          float64 `json:"score"`           // คะแนนเฉลี่ยในช่วง 0..100
+	CompletedTasks int     `json:"completed_tasks"` // งานที่เสร็จในช่วง (throughput)
+	OverdueTasks   int     `json:"overdue_tasks"`   // งานที่ครบกำหนดในช่วงแต่เสร็จช้าหรือยังค้าง
+}
+
+type KPIPerformersDTO struct {
+	StartDate    time.Time            `json:"start_date"`
+	EndDate      time.Time            `json:"end_date"`
+	CompanyScore float64              `json:"company_score"`
+	Top          []KPIEmployeeStatDTO `json:"top"`
+	Bottom       []KPIEmployeeStatDTO `json:"bottom"`
+}
+
+type KPICorrelationDTO struct {
+	StartDate   time.Time            `json:"start_date"`
+	EndDate     time.Time            `json:"end_date"`
+	Employees   int                  `json:"employees"`
+	ThroughputR *float64             `json:"throughput_r"` // สหสัมพันธ์ คะแนน × งานที่เสร็จ (-1..1, ว่าง = ข้อมูลไม่พอ)
+	OverdueR    *float64             `json:"overdue_r"`    // สหสัมพันธ์ คะแนน × งานที่ล่าช้า
+	Rows        []KPIEmployeeStatDTO `json:"rows"`
+}
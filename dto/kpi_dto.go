@@ -3,10 +3,13 @@ package dto
 import "time"
 
 type CreateKPITemplateDTO struct {
-	KPIName     string                     `json:"kpi_name"`
-	Department  string                     `json:"department_id"` // แผนก
-	Items       []CreateKPITemplateItemDTO `json:"items"`         // รายการ KPI
-	TotalWeight int                        `json:"total_weight"`  // น้ำหนักรวม (ต้อง = 100)
+	KPIName       string                     `json:"kpi_name"`
+	Department    string                     `json:"department_id"`  // แผนก
+	Items         []CreateKPITemplateItemDTO `json:"items"`          // รายการ KPI
+	TotalWeight   int                        `json:"total_weight"`   // น้ำหนักรวม (ต้อง = 100)
+	ScoringMethod string                     `json:"scoring_method"` // weighted|average|bands (ว่าง = weighted)
+	Bands         []KPIRatingBandDTO         `json:"bands"`          // ช่วงเกรด (bands) ว่าง = A/B/C/D เริ่มต้น
+	Evaluator     *KPIEvaluatorPolicyDTO     `json:"evaluator"`      // กฎเลือกผู้ประเมิน (ว่าง = ผู้จัดการแผนก เตือนทุก 3 วัน ไม่ปิดอัตโนมัติ)
 }
 
 type CreateKPITemplateItemDTO struct {
@@ -18,10 +21,13 @@ type CreateKPITemplateItemDTO struct {
 }
 
 type UpdateKPITemplateDTO struct {
-	Items       *[]CreateKPITemplateItemDTO `json:"items"` // รายการ KPI
-	KPIName     string                      `json:"kpi_name"`
-	Department  string                      `json:"department_id"` // แผนก
-	TotalWeight int                         `json:"total_weight"`  // น้ำหนักรวม (ต้อง = 100)
+	Items         *[]CreateKPITemplateItemDTO `json:"items"` // รายการ KPI
+	KPIName       string                      `json:"kpi_name"`
+	Department    string                      `json:"department_id"`  // แผนก
+	TotalWeight   int                         `json:"total_weight"`   // น้ำหนักรวม (ต้อง = 100)
+	ScoringMethod string                      `json:"scoring_method"` // ว่าง = คงเดิม
+	Bands         *[]KPIRatingBandDTO         `json:"bands"`          // nil = คงเดิม
+	Evaluator     *KPIEvaluatorPolicyDTO      `json:"evaluator"`      // nil = คงเดิม (มีผลกับแบบประเมินที่สร้างหลังจากนี้)
 }
 
 // added for list query
@@ -35,15 +41,40 @@ type KPITemplateListQuery struct {
 }
 
 type KPITemplateDTO struct {
-	CreatedAt   time.Time            `json:"created_at"`
-	UpdatedAt   time.Time            `json:"updated_at"`
-	KPIID       string               `json:"kpi_id"`
-	KPIName     string               `json:"kpi_name"`
-	Department  string               `json:"department_id"`
-	CreatedBy   string               `json:"created_by"`
-	Items       []KPITemplateItemDTO `json:"items"`
-	TotalWeight int                  `json:"total_weight"`
-	Version     int                  `json:"version"`
+	CreatedAt     time.Time             `json:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at"`
+	KPIID         string                `json:"kpi_id"`
+	KPIName       string                `json:"kpi_name"`
+	Department    string                `json:"department_id"`
+	CreatedBy     string                `json:"created_by"`
+	Items         []KPITemplateItemDTO  `json:"items"`
+	TotalWeight   int                   `json:"total_weight"`
+	ScoringMethod string                `json:"scoring_method"`
+	Bands         []KPIRatingBandDTO    `json:"bands,omitempty"`
+	Evaluator     KPIEvaluatorPolicyDTO `json:"evaluator"`
+	Version       int                   `json:"version"`
+}
+
+type KPIEvaluatorPolicyDTO struct {
+	Rule          string `json:"rule"`                  // department_manager|task_creator|reviewer
+	ReviewerID    string `json:"reviewer_id,omitempty"` // จำเป็นเมื่อ rule = reviewer
+	ReminderDays  int    `json:"reminder_days"`         // เตือนผู้ประเมินทุกกี่วัน (0 = ไม่เตือน)
+	AutoCloseDays int    `json:"auto_close_days"`       // ปิดแบบประเมินที่ค้างเกินกี่วัน (0 = ไม่ปิด)
+}
+
+type KPIRatingBandDTO struct {
+	Grade      string  `json:"grade"`       // เกรด เช่น A
+	MinPercent float64 `json:"min_percent"` // เปอร์เซ็นต์ขั้นต่ำ (0..100)
+	Score      float64 `json:"score"`       // คะแนนที่ได้ (0..100)
+}
+
+// KPIRecomputeResult ผลคำนวณคะแนนการประเมินย้อนหลังใหม่ตามวิธีของ template
+type KPIRecomputeResult struct {
+	RunAt       time.Time `json:"run_at"`
+	Evaluations int       `json:"evaluations"` // การประเมินที่ตรวจ
+	Updated     int       `json:"updated"`     // การประเมินที่คะแนนเปลี่ยน
+	Users       int       `json:"users"`       // ผู้ใช้ที่คำนวณ KPI รวมใหม่
+	DurationMS  int64     `json:"duration_ms"`
 }
 
 type KPITemplateItemDTO struct {
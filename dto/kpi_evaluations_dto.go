@@ -16,6 +16,29 @@ type UpdateKPIEvaluationRequest struct {
 	Feedback string            `json:"feedback,omitempty"`        // คอมเมนต์รวม (ถ้ามี)
 	Scores   []KPIScoreRequest `json:"scores" binding:"required"` // รายการคะแนนแต่ละ item
 }
+type InviteKPIPeersRequest struct {
+	PeerIDs []string `json:"peer_ids"` // เพื่อนร่วมงานที่ให้ประเมิน (แทนที่รายชื่อเดิม ผู้ที่ส่งคะแนนแล้วจะถูกคงไว้)
+}
+
+type AcknowledgeKPIEvaluationRequest struct {
+	Comment string `json:"comment,omitempty"` // ความเห็นของผู้ถูกประเมิน (ถ้ามี)
+}
+
+type DisputeKPIEvaluationRequest struct {
+	Reason string `json:"reason"` // เหตุผลที่โต้แย้ง (จำเป็น)
+}
+
+type ResolveKPIDisputeRequest struct {
+	Decision   string            `json:"decision"`           // upheld (คงคะแนน) | revised (แก้คะแนน)
+	Resolution string            `json:"resolution"`         // ความเห็นผู้พิจารณา (จำเป็น)
+	Feedback   string            `json:"feedback,omitempty"` // คอมเมนต์รวมใหม่ (เฉพาะ revised ว่าง = คงเดิม)
+	Scores     []KPIScoreRequest `json:"scores,omitempty"`   // คะแนนใหม่ (จำเป็นเมื่อ revised)
+}
+
+type AssignKPIEvaluatorRequest struct {
+	EvaluatorID string `json:"evaluator_id"` // ผู้ประเมินหลักคนใหม่ (ว่าง = เลือกใหม่ตามกฎของ template)
+}
+
 type KPIScoreRequest struct {
 	ItemID string  `json:"item_id" binding:"required"` // อ้างถึง item ใน KPI template
 	Notes  string  `json:"notes,omitempty"`            // หมายเหตุเพิ่มเติม (ถ้ามี)
@@ -34,29 +57,86 @@ type RequestListKPIEvaluation struct {
 // <===================== Response ===============================>
 
 type KPIEvaluationResponse struct {
-	FinishedAt     time.Time          `json:"finished_at"`
-	CreatedAt      time.Time          `json:"created_at"`
-	UpdatedAt      time.Time          `json:"updated_at"`
-	EvaluationID   string             `json:"evaluation_id"`
-	JobID          string             `json:"job_id"`
-	JobName        string             `json:"job_name"`
-	ProjectID      string             `json:"project_id"`
-	ProjectName    string             `json:"project_name"`
-	TaskID         string             `json:"task_id,omitempty"`
-	Description    string             `json:"description,omitempty"`
-	KPIID          string             `json:"kpi_id"`
-	KPIName        string             `json:"kpi_name"`
-	EvaluatorID    string             `json:"evaluator_id"`
-	EvaluatorName  string             `json:"evaluator_name"`
-	EvaluateeID    string             `json:"evaluatee_id"`
-	EvaluateeName  string             `json:"evaluatee_name"`
-	Department     string             `json:"department_id"`
-	DepartmentName string             `json:"department_name"`
-	Feedback       string             `json:"feedback,omitempty"`
-	Scores         []KPIScoreResponse `json:"scores"`
-	Version        int                `json:"version"`
-	TotalScore     float64            `json:"total_score"`
-	IsEvaluated    bool               `json:"is_evaluated"` // ประเมินแล้วหรือยัง
+	FinishedAt      time.Time          `json:"finished_at"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+	EvaluationID    string             `json:"evaluation_id"`
+	JobID           string             `json:"job_id"`
+	JobName         string             `json:"job_name"`
+	ProjectID       string             `json:"project_id"`
+	ProjectName     string             `json:"project_name"`
+	TaskID          string             `json:"task_id,omitempty"`
+	StepIDs         []string           `json:"step_ids,omitempty"` // step ที่ผู้ถูกประเมินรับผิดชอบ
+	Description     string             `json:"description,omitempty"`
+	KPIID           string             `json:"kpi_id"`
+	KPIName         string             `json:"kpi_name"`
+	EvaluatorID     string             `json:"evaluator_id"`
+	EvaluatorName   string             `json:"evaluator_name"`
+	EvaluateeID     string             `json:"evaluatee_id"`
+	EvaluateeName   string             `json:"evaluatee_name"`
+	Department      string             `json:"department_id"`
+	DepartmentName  string             `json:"department_name"`
+	Feedback        string             `json:"feedback,omitempty"`
+	Scores          []KPIScoreResponse `json:"scores"`
+	Version         int                `json:"version"`
+	TotalScore      float64            `json:"total_score"`
+	Percent         float64            `json:"percent"`                  // เปอร์เซ็นต์ที่ทำได้จริง
+	Grade           string             `json:"grade,omitempty"`          // เกรด (วิธี bands)
+	ScoringMethod   string             `json:"scoring_method,omitempty"` // วิธีคิดคะแนน
+	IsEvaluated     bool               `json:"is_evaluated"`             // ประเมินแล้วหรือยัง
+	SLABreaches     int                `json:"sla_breaches"`             // จำนวนครั้งที่เกินกำหนด SLA ในงานนี้
+	SLAOverdueHours float64            `json:"sla_overdue_hours"`        // ชั่วโมงเกินกำหนดรวม
+
+	ViewerRoles []string                `json:"viewer_roles,omitempty"` // บทบาทของผู้เรียกในการประเมินนี้ (self|manager|peer)
+	SelfReview  *KPIRoleReviewResponse  `json:"self_review,omitempty"`  // ผลประเมินตนเอง
+	PeerIDs     []string                `json:"peer_ids,omitempty"`     // เฉพาะผู้จัดการ/admin
+	PeerReviews []KPIRoleReviewResponse `json:"peer_reviews,omitempty"` // ผู้จัดการ/admin เห็นทั้งหมด peer เห็นเฉพาะของตน
+	PeerCount   int                     `json:"peer_count"`             // จำนวนเพื่อนร่วมงานที่ส่งคะแนนแล้ว
+	PeerPercent *float64                `json:"peer_percent,omitempty"` // เปอร์เซ็นต์เฉลี่ยจากเพื่อนร่วมงาน (ผู้ถูกประเมินเห็นเฉพาะค่านี้)
+	AckStatus   string                  `json:"ack_status,omitempty"`   // pending|acknowledged|disputed|resolved
+	AckComment  string                  `json:"ack_comment,omitempty"`
+	AckAt       *time.Time              `json:"ack_at,omitempty"`
+	Disputes    []KPIDisputeResponse    `json:"disputes,omitempty"`
+
+	EvaluatorRule string     `json:"evaluator_rule,omitempty"`
+	AssignedAt    *time.Time `json:"assigned_at,omitempty"`
+	ClosedAt      *time.Time `json:"closed_at,omitempty"`
+	CloseReason   string     `json:"close_reason,omitempty"`
+}
+
+type KPIRoleReviewResponse struct {
+	EvaluatorID   string             `json:"evaluator_id,omitempty"`
+	EvaluatorName string             `json:"evaluator_name,omitempty"`
+	Scores        []KPIScoreResponse `json:"scores"`
+	Feedback      string             `json:"feedback,omitempty"`
+	TotalScore    float64            `json:"total_score"`
+	Percent       float64            `json:"percent"`
+	Grade         string             `json:"grade,omitempty"`
+	SubmittedAt   time.Time          `json:"submitted_at"`
+}
+
+type KPIDisputeResponse struct {
+	Reason     string     `json:"reason"`
+	RaisedAt   time.Time  `json:"raised_at"`
+	Version    int        `json:"version"`
+	Decision   string     `json:"decision,omitempty"`
+	Resolution string     `json:"resolution,omitempty"`
+	ResolvedBy string     `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+type KPIRevisionResponse struct {
+	Version       int                `json:"version"`
+	Role          string             `json:"role"`
+	EvaluatorID   string             `json:"evaluator_id,omitempty"` // peer ไม่แสดงตัวตนต่อผู้ถูกประเมิน
+	EvaluatorName string             `json:"evaluator_name,omitempty"`
+	Scores        []KPIScoreResponse `json:"scores"`
+	Feedback      string             `json:"feedback,omitempty"`
+	TotalScore    float64            `json:"total_score"`
+	Percent       float64            `json:"percent"`
+	Grade         string             `json:"grade,omitempty"`
+	Reason        string             `json:"reason,omitempty"`
+	RevisedAt     time.Time          `json:"revised_at"`
 }
 
 type KPIScoreResponse struct {
@@ -68,3 +148,36 @@ type KPIScoreResponse struct {
 	MaxScore int    `json:"max_score"`
 	Score    int    `json:"score"`
 }
+
+type RequestRecomputeKPI struct {
+	KPIID string `query:"kpi_id"` // ว่าง = ทุก template
+}
+
+type KPIInboxItemDTO struct {
+	Action         string     `json:"action"` // evaluate | peer_review | resolve_dispute
+	EvaluationID   string     `json:"evaluation_id"`
+	TaskID         string     `json:"task_id,omitempty"`
+	JobID          string     `json:"job_id"`
+	JobName        string     `json:"job_name"`
+	KPIID          string     `json:"kpi_id"`
+	KPIName        string     `json:"kpi_name"`
+	EvaluateeID    string     `json:"evaluatee_id"`
+	EvaluateeName  string     `json:"evaluatee_name"`
+	DepartmentID   string     `json:"department_id"`
+	DepartmentName string     `json:"department_name"`
+	CreatedAt      time.Time  `json:"created_at"`
+	WaitingDays    int        `json:"waiting_days"`            // ค้างมากี่วัน
+	AutoCloseAt    *time.Time `json:"auto_close_at,omitempty"` // จะถูกปิดอัตโนมัติเมื่อ (เฉพาะ evaluate)
+}
+
+type KPIEvaluationRunResult struct {
+	RunAt      time.Time `json:"run_at"`
+	Open       int       `json:"open"`     // แบบประเมินที่ยังค้าง (ก่อนปิด)
+	Assigned   int       `json:"assigned"` // มอบหมายผู้ประเมินให้แบบประเมินที่ยังว่าง
+	Reminded   int       `json:"reminded"` // แบบประเมินที่ส่งเตือน
+	Emails     int       `json:"emails"`   // อีเมลที่ส่ง (หนึ่งฉบับต่อผู้ประเมิน)
+	Failed     int       `json:"failed"`   // ส่งอีเมลไม่สำเร็จ
+	Closed     int       `json:"closed"`   // ปิดอัตโนมัติเพราะค้างเกินกำหนด
+	Errors     []string  `json:"errors"`
+	DurationMS int64     `json:"duration_ms"`
+}
@@ -0,0 +1,155 @@
+package dto
+
+import "time"
+
+// ---------- Request DTO ----------
+
+type UpsertLeavePolicyDTO struct {
+	LeaveType     string         `json:"leave_type"`      // sick|personal|annual|maternity|ordination|military (จำเป็น)
+	PositionID    string         `json:"position_id"`     // ว่าง = ทุกตำแหน่ง
+	Tiers         []LeaveTierDTO `json:"tiers"`           // สิทธิ์ต่อปีตามอายุงาน (อย่างน้อย 1 ขั้น)
+	PaidDays      *float64       `json:"paid_days"`       // วันที่ได้รับค่าจ้างต่อปี (ว่าง = เท่าสิทธิ์ขั้นสูงสุด)
+	MaxCarryOver  float64        `json:"max_carry_over"`  // ยกยอดไปปีถัดไปได้สูงสุด (0 = ไม่ยกยอด)
+	AllowExceed   bool           `json:"allow_exceed"`    // ลาเกินสิทธิ์ได้ (ส่วนเกินไม่ได้รับค่าจ้าง)
+	AllowHalfDay  bool           `json:"allow_half_day"`  // ลาครึ่งวันได้
+	CalendarDays  bool           `json:"calendar_days"`   // นับวันปฏิทินรวมวันหยุด
+	MinNoticeDays int            `json:"min_notice_days"` // ต้องยื่นล่วงหน้ากี่วัน
+}
+
+type LeaveTierDTO struct {
+	MinServiceMonths int     `json:"min_service_months"` // อายุงานขั้นต่ำ (เดือน)
+	Days             float64 `json:"days"`               // วันลาต่อปี
+}
+
+type CreateLeaveRequestDTO struct {
+	UserID     string `json:"user_id"`    // HR ยื่นแทนพนักงาน (ว่าง = ตนเอง)
+	LeaveType  string `json:"leave_type"` // ประเภทการลา (จำเป็น)
+	StartDate  string `json:"start_date"` // YYYY-MM-DD (จำเป็น)
+	EndDate    string `json:"end_date"`   // YYYY-MM-DD (ว่าง = วันเดียว)
+	HalfDay    string `json:"half_day"`   // morning|afternoon เฉพาะลาวันเดียว
+	Reason     string `json:"reason"`
+	Attachment string `json:"attachment"` // ลิงก์เอกสารแนบ เช่น ใบรับรองแพทย์
+}
+
+type LeaveDecisionDTO struct {
+	Comment string `json:"comment"` // ความเห็น (จำเป็นเมื่อไม่อนุมัติ/ยกเลิก)
+}
+
+type RequestListLeaveRequests struct {
+	UserID       string `query:"user_id"`
+	DepartmentID string `query:"department_id"`
+	LeaveType    string `query:"leave_type"`
+	Status       string `query:"status"` // pending|approved|recorded|rejected|cancelled
+	Year         int    `query:"year"`
+	Page         int    `query:"page"`
+	Limit        int    `query:"limit"`
+}
+
+type RequestLeaveBalance struct {
+	UserID string `query:"user_id"` // ว่าง = ตนเอง
+	Year   int    `query:"year"`    // ว่าง = ปีปัจจุบัน
+}
+
+type RequestLeaveCalendar struct {
+	DepartmentID string `query:"department_id"` // ว่าง = แผนกของตนเอง (admin = ทุกแผนก)
+	StartDate    string `query:"start_date"`    // YYYY-MM-DD (ว่าง = ต้นเดือนนี้)
+	EndDate      string `query:"end_date"`      // YYYY-MM-DD (ว่าง = สิ้นเดือนของ start_date, ไม่เกิน 92 วัน)
+}
+
+// ---------- Response DTO ----------
+
+type LeavePolicyDTO struct {
+	UpdatedAt     *time.Time     `json:"updated_at"`
+	PolicyID      string         `json:"policy_id"` // ว่าง = ค่าเริ่มต้นตามกฎหมาย
+	LeaveType     string         `json:"leave_type"`
+	LeaveTypeName string         `json:"leave_type_name"`
+	PositionID    string         `json:"position_id"`
+	PositionName  string         `json:"position_name"`
+	Tiers         []LeaveTierDTO `json:"tiers"`
+	PaidDays      float64        `json:"paid_days"`
+	MaxCarryOver  float64        `json:"max_carry_over"`
+	AllowExceed   bool           `json:"allow_exceed"`
+	AllowHalfDay  bool           `json:"allow_half_day"`
+	CalendarDays  bool           `json:"calendar_days"`
+	MinNoticeDays int            `json:"min_notice_days"`
+	IsDefault     bool           `json:"is_default"`
+}
+
+type LeaveBalanceDTO struct {
+	LeaveType     string  `json:"leave_type"`
+	LeaveTypeName string  `json:"leave_type_name"`
+	Entitled      float64 `json:"entitled"`     // สิทธิ์ของปีตามอายุงาน
+	CarriedOver   float64 `json:"carried_over"` // ยกยอดจากปีก่อน
+	Used          float64 `json:"used"`         // อนุมัติ/บันทึกแล้ว
+	Pending       float64 `json:"pending"`      // รออนุมัติ
+	Remaining     float64 `json:"remaining"`    // คงเหลือ (หักรออนุมัติแล้ว)
+	PaidDays      float64 `json:"paid_days"`
+	UnpaidDays    float64 `json:"unpaid_days"` // วันที่ใช้เกินวันได้รับค่าจ้าง
+	AllowHalfDay  bool    `json:"allow_half_day"`
+}
+
+type LeaveBalanceSummaryDTO struct {
+	UserID        string            `json:"user_id"`
+	UserName      string            `json:"user_name"`
+	Year          int               `json:"year"`
+	ServiceMonths int               `json:"service_months"`
+	Balances      []LeaveBalanceDTO `json:"balances"`
+}
+
+type LeaveRequestDTO struct {
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	StartDate       time.Time  `json:"start_date"`
+	EndDate         time.Time  `json:"end_date"`
+	DecidedAt       *time.Time `json:"decided_at"`
+	RecordedAt      *time.Time `json:"recorded_at"`
+	CancelledAt     *time.Time `json:"cancelled_at"`
+	RequestID       string     `json:"request_id"`
+	UserID          string     `json:"user_id"`
+	UserName        string     `json:"user_name"`
+	DepartmentID    string     `json:"department_id"`
+	DepartmentName  string     `json:"department_name"`
+	LeaveType       string     `json:"leave_type"`
+	LeaveTypeName   string     `json:"leave_type_name"`
+	HalfDay         string     `json:"half_day"`
+	Days            float64    `json:"days"`
+	Year            int        `json:"year"`
+	Reason          string     `json:"reason"`
+	Attachment      string     `json:"attachment"`
+	Status          string     `json:"status"`
+	ApproverID      string     `json:"approver_id"`
+	ApproverName    string     `json:"approver_name"`
+	CreatedBy       string     `json:"created_by"`
+	DecidedBy       string     `json:"decided_by"`
+	DecisionComment string     `json:"decision_comment"`
+	RecordedBy      string     `json:"recorded_by"`
+	HRNote          string     `json:"hr_note"`
+	CancelledBy     string     `json:"cancelled_by"`
+	CancelReason    string     `json:"cancel_reason"`
+}
+
+type LeaveCalendarDTO struct {
+	StartDate string                `json:"start_date"`
+	EndDate   string                `json:"end_date"`
+	Days      []LeaveCalendarDayDTO `json:"days"`
+}
+
+type LeaveCalendarDayDTO struct {
+	Date        string                  `json:"date"` // YYYY-MM-DD
+	IsHoliday   bool                    `json:"is_holiday"`
+	HolidayName string                  `json:"holiday_name"`
+	OnLeave     float64                 `json:"on_leave"` // จำนวนคนที่ลา (อนุมัติแล้ว ครึ่งวัน = 0.5)
+	Leaves      []LeaveCalendarEntryDTO `json:"leaves"`
+}
+
+type LeaveCalendarEntryDTO struct {
+	RequestID      string `json:"request_id"`
+	UserID         string `json:"user_id"`
+	UserName       string `json:"user_name"`
+	DepartmentID   string `json:"department_id"`
+	DepartmentName string `json:"department_name"`
+	LeaveType      string `json:"leave_type"`
+	LeaveTypeName  string `json:"leave_type_name"`
+	HalfDay        string `json:"half_day"`
+	Status         string `json:"status"` // pending แสดงเป็นลาที่รออนุมัติ
+}
@@ -16,6 +16,7 @@ type CreatePayableDTO struct {
 	Phone       string             `json:"phone" bson:"phone"`               // เบอร์โทรศัพท์ผู้ขาย / เจ้าหนี้
 	Address     string             `json:"address" bson:"address"`           // ที่อยู่ผู้ขาย / เจ้าหนี้
 	Note        string             `json:"note,omitempty"`                   // หมายเหตุ
+	JobID       string             `json:"job_id,omitempty"`                 // รหัสงานป้ายที่เกี่ยวข้อง (ถ้ามี)
 	Items       []ReceiptItemDTO   `json:"items,omitempty"`                  // รายการสินค้า/บริการ
 	Amount      float64            `json:"amount"`                           // จำนวนเงิน
 	Balance     float64            `json:"balance"`                          // ยอดคงเหลือ
@@ -34,6 +35,7 @@ type UpdatePayableDTO struct {
 	Phone       string             `json:"phone" bson:"phone"`               // เบอร์โทรศัพท์ผู้ขาย / เจ้าหนี้
 	Address     string             `json:"address" bson:"address"`           // ที่อยู่ผู้ขาย / เจ้าหนี้
 	Note        string             `json:"note,omitempty"`                   // หมายเหตุ
+	JobID       string             `json:"job_id,omitempty"`                 // รหัสงานป้ายที่เกี่ยวข้อง (ถ้ามี)
 	Items       []ReceiptItemDTO   `json:"items,omitempty"`                  // รายการสินค้า/บริการ
 	Amount      float64            `json:"amount,omitempty"`                 // จำนวนเงิน
 	Balance     float64            `json:"balance,omitempty"`                // ยอดคงเหลือ
@@ -75,6 +77,7 @@ type PayableDTO struct {
 	Phone        string                  `json:"phone" bson:"phone"`               // เบอร์โทรศัพท์ผู้ขาย / เจ้าหนี้
 	Address      string                  `json:"address" bson:"address"`           // ที่อยู่ผู้ขาย / เจ้าหนี้
 	Note         string                  `json:"note"`                             // หมายเหตุ
+	JobID        string                  `json:"job_id,omitempty"`                 // รหัสงานป้ายที่เกี่ยวข้อง
 	Items        []ReceiptItemDTO        `json:"items,omitempty"`                  // รายการสินค้า/บริการ
 	Transactions []PaymentTransactionDTO `json:"transactions"`                     // รายการชำระเงิน
 	Amount       float64                 `json:"amount"`                           // จำนวนเงิน
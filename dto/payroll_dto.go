@@ -0,0 +1,151 @@
+package dto
+
+import "time"
+
+// ---------- Request DTO ----------
+
+type UpsertSalaryStructureDTO struct {
+	UserID            string       `json:"user_id"`            // พนักงาน (จำเป็น)
+	PayBasis          string       `json:"pay_basis"`          // monthly|daily (ว่าง = monthly)
+	BaseSalary        float64      `json:"base_salary"`        // เงินเดือน (จำเป็นเมื่อ monthly)
+	DailyRate         float64      `json:"daily_rate"`         // ค่าจ้างต่อวัน (จำเป็นเมื่อ daily)
+	HoursPerDay       float64      `json:"hours_per_day"`      // ว่าง = 8
+	OTMultiplier      float64      `json:"ot_multiplier"`      // ว่าง = 1.5
+	HolidayMultiplier float64      `json:"holiday_multiplier"` // ว่าง = 1 (รายเดือน) / 2 (รายวัน)
+	Allowances        []PayItemDTO `json:"allowances"`
+	Deductions        []PayItemDTO `json:"deductions"`
+	SSFEnabled        *bool        `json:"ssf_enabled"`    // ว่าง = หักประกันสังคม
+	TaxDeductions     float64      `json:"tax_deductions"` // ค่าลดหย่อนอื่นทั้งปี (บาท)
+}
+
+type PayItemDTO struct {
+	Name    string  `json:"name"`
+	Amount  float64 `json:"amount"`
+	Taxable bool    `json:"taxable"` // เงินได้ที่ต้องเสียภาษี
+	IsWage  bool    `json:"is_wage"` // นับเป็นค่าจ้างสำหรับประกันสังคม
+}
+
+type CreatePayrollRunDTO struct {
+	Month   string `json:"month"`    // YYYY-MM (จำเป็น)
+	PayDate string `json:"pay_date"` // YYYY-MM-DD (ว่าง = วันสุดท้ายของเดือน)
+	BankID  string `json:"bank_id"`  // บัญชีบริษัทที่จ่าย (ว่าง = บัญชีบริษัทหลัก)
+	Note    string `json:"note"`
+}
+
+type PayrollAdjustmentDTO struct {
+	UserID  string  `json:"user_id"` // พนักงาน (จำเป็น)
+	Name    string  `json:"name"`    // รายการ (จำเป็น)
+	Amount  float64 `json:"amount"`  // บวก = เงินได้, ลบ = เงินหัก
+	Taxable bool    `json:"taxable"` // มีผลต่อเงินได้ที่ต้องเสียภาษี
+}
+
+type PayrollDecisionDTO struct {
+	Note string `json:"note"` // หมายเหตุ (จำเป็นเมื่อยกเลิก)
+}
+
+type PayrollPaymentDTO struct {
+	PaymentMethod string `json:"payment_method"` // ว่าง = transfer
+	ReferenceNo   string `json:"reference_no"`   // เลขอ้างอิงการโอน
+	Note          string `json:"note"`
+}
+
+type RequestListPayrollRuns struct {
+	Year   int    `query:"year"`
+	Status string `query:"status"` // draft|approved|paid|cancelled
+	Page   int    `query:"page"`
+	Limit  int    `query:"limit"`
+}
+
+type RequestMyPayslips struct {
+	Year int `query:"year"` // ว่าง = ปีปัจจุบัน
+}
+
+// ---------- Response DTO ----------
+
+type SalaryStructureDTO struct {
+	UpdatedAt         *time.Time   `json:"updated_at"`
+	StructureID       string       `json:"structure_id"`
+	UserID            string       `json:"user_id"`
+	UserName          string       `json:"user_name"`
+	EmployeeCode      string       `json:"employee_code"`
+	EmploymentType    string       `json:"employment_type"`
+	PayBasis          string       `json:"pay_basis"`
+	BaseSalary        float64      `json:"base_salary"`
+	DailyRate         float64      `json:"daily_rate"`
+	HoursPerDay       float64      `json:"hours_per_day"`
+	OTMultiplier      float64      `json:"ot_multiplier"`
+	HolidayMultiplier float64      `json:"holiday_multiplier"`
+	Allowances        []PayItemDTO `json:"allowances"`
+	Deductions        []PayItemDTO `json:"deductions"`
+	SSFEnabled        bool         `json:"ssf_enabled"`
+	TaxDeductions     float64      `json:"tax_deductions"`
+	HasBankAccount    bool         `json:"has_bank_account"`
+}
+
+type PayrollRunDTO struct {
+	CreatedAt        time.Time    `json:"created_at"`
+	CalculatedAt     time.Time    `json:"calculated_at"`
+	PayDate          time.Time    `json:"pay_date"`
+	ApprovedAt       *time.Time   `json:"approved_at"`
+	PaidAt           *time.Time   `json:"paid_at"`
+	CancelledAt      *time.Time   `json:"cancelled_at"`
+	RunID            string       `json:"run_id"`
+	Month            string       `json:"month"`
+	Status           string       `json:"status"`
+	BankID           string       `json:"bank_id"`
+	EmployeeCount    int          `json:"employee_count"`
+	TotalGross       float64      `json:"total_gross"`
+	TotalSSFEmployee float64      `json:"total_ssf_employee"`
+	TotalSSFEmployer float64      `json:"total_ssf_employer"`
+	TotalTax         float64      `json:"total_tax"`
+	TotalDeductions  float64      `json:"total_deductions"`
+	TotalNet         float64      `json:"total_net"`
+	ExpenseID        string       `json:"expense_id"`
+	Note             string       `json:"note"`
+	CreatedBy        string       `json:"created_by"`
+	ApprovedBy       string       `json:"approved_by"`
+	PaidBy           string       `json:"paid_by"`
+	CancelReason     string       `json:"cancel_reason"`
+	Payslips         []PayslipDTO `json:"payslips,omitempty"`
+	Warnings         []string     `json:"warnings,omitempty"` // เช่น พนักงานไม่มีบัญชีธนาคาร, OT รออนุมัติ
+}
+
+type PayslipDTO struct {
+	PayslipID       string       `json:"payslip_id"`
+	RunID           string       `json:"run_id"`
+	Month           string       `json:"month"`
+	RunStatus       string       `json:"run_status"`
+	UserID          string       `json:"user_id"`
+	EmployeeCode    string       `json:"employee_code"`
+	Name            string       `json:"name"`
+	DepartmentID    string       `json:"department_id"`
+	BankName        string       `json:"bank_name"`
+	AccountNo       string       `json:"account_no"`
+	PayBasis        string       `json:"pay_basis"`
+	ScheduledDays   int          `json:"scheduled_days"`
+	PresentDays     int          `json:"present_days"`
+	AbsentDays      float64      `json:"absent_days"`
+	LeaveDays       float64      `json:"leave_days"`
+	UnpaidLeaveDays float64      `json:"unpaid_leave_days"`
+	OTHours         float64      `json:"ot_hours"`
+	HolidayOTHours  float64      `json:"holiday_ot_hours"`
+	Earnings        []PayLineDTO `json:"earnings"`
+	Deductions      []PayLineDTO `json:"deductions"`
+	Gross           float64      `json:"gross"`
+	TaxableIncome   float64      `json:"taxable_income"`
+	SSFEmployee     float64      `json:"ssf_employee"`
+	SSFEmployer     float64      `json:"ssf_employer"`
+	WithholdingTax  float64      `json:"withholding_tax"`
+	TotalDeduction  float64      `json:"total_deduction"`
+	Net             float64      `json:"net"`
+	YTDIncome       float64      `json:"ytd_income"`
+	YTDTax          float64      `json:"ytd_tax"`
+	YTDSSF          float64      `json:"ytd_ssf"`
+}
+
+type PayLineDTO struct {
+	Code    string  `json:"code"`
+	Name    string  `json:"name"`
+	Amount  float64 `json:"amount"`
+	Taxable bool    `json:"taxable"`
+}
@@ -0,0 +1,106 @@
+package dto
+
+import "time"
+
+// ---------- Request DTO ----------
+
+type CreateRecurringTaskDTO struct {
+	Name         string   `json:"name"`          // ชื่องาน (จำเป็น)
+	Description  string   `json:"description"`   // รายละเอียดงาน
+	WorkflowID   string   `json:"workflow_id"`   // workflow template (จำเป็น)
+	ProjectID    string   `json:"project_id"`    // โปรเจกต์
+	ProjectName  string   `json:"project_name"`  // ชื่อโปรเจกต์
+	DepartmentID string   `json:"department_id"` // แผนก (ว่าง = แผนกของ workflow)
+	Importance   string   `json:"importance"`    // low|medium|high (ค่าเริ่มต้น medium)
+	KPIID        string   `json:"kpi_id"`        // KPI ที่ใช้ประเมิน
+	Schedule     string   `json:"schedule"`      // cron เช่น "0 8 * * 1" หรือ RRULE เช่น "FREQ=WEEKLY;BYDAY=MO;BYHOUR=8"
+	StartAt      string   `json:"start_at"`      // YYYY-MM-DD หรือ YYYY-MM-DDTHH:mm ตามเวลาไทย (ว่าง = ตอนนี้)
+	DurationDays int      `json:"duration_days"` // จำนวนวันของงานแต่ละรอบ (ค่าเริ่มต้น 1)
+	Assignees    []string `json:"assignees"`     // ผู้รับผิดชอบแบบวนคิว (จำเป็นอย่างน้อย 1 คน)
+}
+
+type UpdateRecurringTaskDTO struct {
+	Name         *string   `json:"name,omitempty"`
+	Description  *string   `json:"description,omitempty"`
+	WorkflowID   *string   `json:"workflow_id,omitempty"`
+	ProjectID    *string   `json:"project_id,omitempty"`
+	ProjectName  *string   `json:"project_name,omitempty"`
+	DepartmentID *string   `json:"department_id,omitempty"`
+	Importance   *string   `json:"importance,omitempty"`
+	KPIID        *string   `json:"kpi_id,omitempty"`
+	Schedule     *string   `json:"schedule,omitempty"`
+	StartAt      *string   `json:"start_at,omitempty"`
+	DurationDays *int      `json:"duration_days,omitempty"`
+	Assignees    *[]string `json:"assignees,omitempty"` // เปลี่ยนคิวแล้วเริ่มนับจากคนแรกใหม่
+	IsActive     *bool     `json:"is_active,omitempty"`
+}
+
+type RequestListRecurringTask struct {
+	Search       string `query:"search"`
+	DepartmentID string `query:"department_id"`
+	WorkflowID   string `query:"workflow_id"`
+	Active       string `query:"active"` // true|false (ว่าง = ทั้งหมด)
+	Page         int    `query:"page"`
+	Limit        int    `query:"limit"`
+}
+
+// ---------- Response DTO ----------
+
+type RecurringAssigneeDTO struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+	IsNext bool   `json:"is_next"` // คนที่จะได้งานรอบถัดไป
+}
+
+type RecurringOccurrenceDTO struct {
+	RunAt    time.Time `json:"run_at"`
+	Assignee string    `json:"assignee"`
+}
+
+type RecurringTaskDTO struct {
+	CreatedAt      time.Time                `json:"created_at"`
+	UpdatedAt      time.Time                `json:"updated_at"`
+	RecurringID    string                   `json:"recurring_id"`
+	Name           string                   `json:"name"`
+	Description    string                   `json:"description"`
+	WorkFlowID     string                   `json:"workflow_id"`
+	WorkFlowName   string                   `json:"workflow_name"`
+	ProjectID      string                   `json:"project_id"`
+	ProjectName    string                   `json:"project_name"`
+	DepartmentID   string                   `json:"department_id"`
+	DepartmentName string                   `json:"department_name"`
+	Importance     string                   `json:"importance"`
+	KPIID          string                   `json:"kpi_id"`
+	Schedule       string                   `json:"schedule"`
+	StartAt        time.Time                `json:"start_at"`
+	DurationDays   int                      `json:"duration_days"`
+	Assignees      []RecurringAssigneeDTO   `json:"assignees"`
+	IsActive       bool                     `json:"is_active"`
+	NextRunAt      *time.Time               `json:"next_run_at"`
+	LastRunAt      *time.Time               `json:"last_run_at,omitempty"`
+	LastTaskID     string                   `json:"last_task_id,omitempty"`
+	LastError      string                   `json:"last_error,omitempty"`
+	RunCount       int                      `json:"run_count"`
+	Upcoming       []RecurringOccurrenceDTO `json:"upcoming,omitempty"` // รอบถัดไป (เฉพาะดูรายการเดียว)
+	CreatedBy      string                   `json:"created_by"`
+}
+
+type RecurringSpawnDTO struct {
+	RecurringID string    `json:"recurring_id"`
+	TaskID      string    `json:"task_id"`
+	JobName     string    `json:"job_name"`
+	RunAt       time.Time `json:"run_at"`
+	Assignee    string    `json:"assignee"`
+}
+
+type RecurringRunResult struct {
+	Checked    int                 `json:"checked"`     // จำนวนงานประจำที่ถึงรอบ
+	Spawned    int                 `json:"spawned"`     // สร้างงานสำเร็จ
+	Missed     int                 `json:"missed"`      // รอบที่ตกหล่น (ระบบหยุดไป) ข้ามโดยไม่สร้างย้อนหลัง
+	Failed     int                 `json:"failed"`      // สร้างงานไม่สำเร็จ
+	Finished   int                 `json:"finished"`    // งานประจำที่ครบรอบแล้ว (ปิดอัตโนมัติ)
+	Tasks      []RecurringSpawnDTO `json:"tasks"`       // งานที่สร้าง
+	Errors     []string            `json:"errors"`      // ข้อผิดพลาด
+	RunAt      time.Time           `json:"run_at"`      // เวลาที่รัน
+	DurationMS int64               `json:"duration_ms"` // เวลาที่ใช้
+}
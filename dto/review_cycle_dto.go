@@ -0,0 +1,183 @@
+package dto
+
+import "time"
+
+// ---------- Request DTO ----------
+
+type CreateReviewCycleDTO struct {
+	Name         string             `json:"name"`          // ชื่อรอบ (จำเป็น)
+	CycleType    string             `json:"cycle_type"`    // quarterly|annual
+	PeriodStart  string             `json:"period_start"`  // YYYY-MM-DD ช่วงผลงานที่นำมาคิด
+	PeriodEnd    string             `json:"period_end"`    // YYYY-MM-DD (รวมวันสุดท้าย)
+	OpenAt       string             `json:"open_at"`       // YYYY-MM-DD วันเปิดให้ประเมิน (ว่าง = วันถัดจาก period_end)
+	CloseAt      string             `json:"close_at"`      // YYYY-MM-DD วันปิดรับการประเมิน (จำเป็น, รวมทั้งวัน)
+	Departments  []string           `json:"departments"`   // แผนกที่เข้าร่วม (ว่าง = ทุกแผนก)
+	Items        []ReviewItemDTO    `json:"items"`         // หัวข้อประเมิน (weight รวมต้อง = 100)
+	TaskWeight   *int               `json:"task_weight"`   // สัดส่วน KPI งาน 0..100 (ค่าเริ่มต้น 50)
+	Bands        []KPIRatingBandDTO `json:"bands"`         // ช่วงเกรดผลสุดท้าย (ว่าง = A/B/C/D เริ่มต้น)
+	ReminderDays *int               `json:"reminder_days"` // เตือนก่อนปิดรอบกี่วัน (ค่าเริ่มต้น 3, 0 = ไม่เตือน)
+}
+
+type UpdateReviewCycleDTO struct {
+	Name         *string             `json:"name,omitempty"`
+	CycleType    *string             `json:"cycle_type,omitempty"`    // เฉพาะรอบที่ยังไม่เปิด
+	PeriodStart  *string             `json:"period_start,omitempty"`  // เฉพาะรอบที่ยังไม่เปิด
+	PeriodEnd    *string             `json:"period_end,omitempty"`    // เฉพาะรอบที่ยังไม่เปิด
+	OpenAt       *string             `json:"open_at,omitempty"`       // เฉพาะรอบที่ยังไม่เปิด
+	CloseAt      *string             `json:"close_at,omitempty"`      // เลื่อนวันปิดได้จนกว่าจะปิดรอบ
+	Departments  *[]string           `json:"departments,omitempty"`   // เฉพาะรอบที่ยังไม่เปิด
+	Items        *[]ReviewItemDTO    `json:"items,omitempty"`         // เฉพาะรอบที่ยังไม่เปิด
+	TaskWeight   *int                `json:"task_weight,omitempty"`   // เฉพาะรอบที่ยังไม่เปิด
+	Bands        *[]KPIRatingBandDTO `json:"bands,omitempty"`         // เฉพาะรอบที่ยังไม่เปิด
+	ReminderDays *int                `json:"reminder_days,omitempty"` // แก้ได้จนกว่าจะปิดรอบ
+}
+
+type ReviewItemDTO struct {
+	ItemID      string `json:"item_id"` // ว่าง = สร้างใหม่
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Category    string `json:"category"` // เช่น attendance, behavior
+	MaxScore    int    `json:"max_score"`
+	Weight      int    `json:"weight"`
+}
+
+type RequestListReviewCycle struct {
+	Search    string `query:"search"`
+	CycleType string `query:"cycle_type"` // quarterly|annual
+	Status    string `query:"status"`     // scheduled|open|closed
+	Page      int    `query:"page"`
+	Limit     int    `query:"limit"`
+}
+
+type RequestListPerformanceReview struct {
+	DepartmentID string `query:"department_id"`
+	Status       string `query:"status"` // self_pending|manager_pending|calibration|finalized
+	Page         int    `query:"page"`
+	Limit        int    `query:"limit"`
+}
+
+type RequestMyPerformanceReview struct {
+	Role   string `query:"role"`   // self (ของฉัน) | manager (ที่ฉันต้องประเมิน) ค่าเริ่มต้น self
+	Status string `query:"status"` // กรองสถานะ
+}
+
+type RequestReviewResults struct {
+	UserID string `query:"user_id"` // ว่าง = ของฉัน (ดูของคนอื่นได้เฉพาะ admin/ผู้จัดการแผนก)
+}
+
+type SubmitReviewScoresDTO struct {
+	Scores  []KPIScoreRequest `json:"scores"`  // คะแนนรายหัวข้อ (ต้องครบทุกหัวข้อ)
+	Comment string            `json:"comment"` // ความเห็น
+}
+
+type FinalizeReviewDTO struct {
+	FinalScore *float64 `json:"final_score"` // คะแนนหลังปรับเทียบ 0..100 (ว่าง = ใช้คะแนนแนะนำ)
+	Note       string   `json:"note"`        // เหตุผลการปรับเทียบ (จำเป็นเมื่อคะแนนต่างจากคะแนนแนะนำ)
+}
+
+// ---------- Response DTO ----------
+
+type ReviewCycleDTO struct {
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+	CycleID      string             `json:"cycle_id"`
+	Name         string             `json:"name"`
+	CycleType    string             `json:"cycle_type"`
+	PeriodStart  time.Time          `json:"period_start"`
+	PeriodEnd    time.Time          `json:"period_end"`
+	OpenAt       time.Time          `json:"open_at"`
+	CloseAt      time.Time          `json:"close_at"`
+	Departments  []string           `json:"departments"`
+	Items        []ReviewItemDTO    `json:"items"`
+	TaskWeight   int                `json:"task_weight"`
+	Bands        []KPIRatingBandDTO `json:"bands"`
+	ReminderDays int                `json:"reminder_days"`
+	Status       string             `json:"status"`
+	Participants int                `json:"participants"`
+	OpenedAt     *time.Time         `json:"opened_at,omitempty"`
+	ClosedAt     *time.Time         `json:"closed_at,omitempty"`
+	RemindedAt   *time.Time         `json:"reminded_at,omitempty"`
+	CreatedBy    string             `json:"created_by"`
+}
+
+type ReviewTaskKPIDTO struct {
+	Evaluations  int       `json:"evaluations"`
+	Score        float64   `json:"score"`
+	CalculatedAt time.Time `json:"calculated_at"`
+}
+
+type PerformanceReviewDTO struct {
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+	ReviewID           string             `json:"review_id"`
+	CycleID            string             `json:"cycle_id"`
+	CycleName          string             `json:"cycle_name"`
+	CycleStatus        string             `json:"cycle_status"`
+	UserID             string             `json:"user_id"`
+	UserName           string             `json:"user_name"`
+	DepartmentID       string             `json:"department_id"`
+	DepartmentName     string             `json:"department_name"`
+	ManagerID          string             `json:"manager_id"`
+	ManagerName        string             `json:"manager_name"`
+	Status             string             `json:"status"`
+	TaskKPI            ReviewTaskKPIDTO   `json:"task_kpi"`
+	SelfScores         []KPIScoreResponse `json:"self_scores"`
+	SelfComment        string             `json:"self_comment"`
+	SelfPercent        float64            `json:"self_percent"`
+	SelfSubmittedAt    *time.Time         `json:"self_submitted_at,omitempty"`
+	ManagerScores      []KPIScoreResponse `json:"manager_scores,omitempty"` // พนักงานเห็นหลังยืนยันผลแล้วเท่านั้น
+	ManagerComment     string             `json:"manager_comment,omitempty"`
+	ManagerPercent     float64            `json:"manager_percent"`
+	ManagerSubmittedAt *time.Time         `json:"manager_submitted_at,omitempty"`
+	SuggestedScore     float64            `json:"suggested_score"`
+	FinalScore         *float64           `json:"final_score,omitempty"`
+	FinalGrade         string             `json:"final_grade,omitempty"`
+	CalibrationNote    string             `json:"calibration_note,omitempty"`
+	FinalizedBy        string             `json:"finalized_by,omitempty"`
+	FinalizedAt        *time.Time         `json:"finalized_at,omitempty"`
+}
+
+type ReviewProgressCountDTO struct {
+	Total            int     `json:"total"`
+	SelfSubmitted    int     `json:"self_submitted"`
+	ManagerSubmitted int     `json:"manager_submitted"`
+	Finalized        int     `json:"finalized"`
+	Percent          float64 `json:"percent"` // ยืนยันผลแล้ว / ทั้งหมด × 100
+}
+
+type ReviewDepartmentProgressDTO struct {
+	DepartmentID   string `json:"department_id"`
+	DepartmentName string `json:"department_name"`
+	ReviewProgressCountDTO
+}
+
+type ReviewCycleProgressDTO struct {
+	CycleID     string                        `json:"cycle_id"`
+	Name        string                        `json:"name"`
+	Status      string                        `json:"status"`
+	CloseAt     time.Time                     `json:"close_at"`
+	Overall     ReviewProgressCountDTO        `json:"overall"`
+	ByStatus    map[string]int                `json:"by_status"`
+	Departments []ReviewDepartmentProgressDTO `json:"departments"`
+}
+
+type ReviewSyncResult struct {
+	Added        int `json:"added"`        // ผู้ถูกประเมินที่เพิ่มใหม่
+	Participants int `json:"participants"` // ผู้ถูกประเมินทั้งหมดของรอบ
+}
+
+type ReviewReminderResult struct {
+	CycleID   string `json:"cycle_id"`
+	Employees int    `json:"employees"` // พนักงานที่ได้รับเตือนให้ประเมินตนเอง
+	Managers  int    `json:"managers"`  // ผู้จัดการที่ได้รับเตือน
+	Failed    int    `json:"failed"`    // ส่งอีเมลไม่สำเร็จ
+}
+
+type ReviewCycleRunResult struct {
+	RunAt      time.Time              `json:"run_at"`
+	Opened     []string               `json:"opened"`    // รอบที่เปิดอัตโนมัติ
+	Closed     []string               `json:"closed"`    // รอบที่ปิดอัตโนมัติ
+	Reminders  []ReviewReminderResult `json:"reminders"` // การส่งเตือน
+	Errors     []string               `json:"errors"`
+	DurationMS int64                  `json:"duration_ms"`
+}
@@ -157,4 +157,26 @@ type SignJobDTO struct { // DTO สำหรับส่งกลับให้
 	WaitPrice         bool    `json:"waitprice"`          // รอราคา
 	WaitConfirm       bool    `json:"waitconfirm"`        // รอยืนยัน
 
+	// ---------- การยกเลิก ----------
+	CancelledAt  *time.Time `json:"cancelled_at,omitempty"`  // วันที่ยกเลิกงาน
+	CancelledBy  string     `json:"cancelled_by,omitempty"`  // ผู้ยกเลิกงาน
+	CancelReason string     `json:"cancel_reason,omitempty"` // เหตุผลการยกเลิก
+}
+
+type CancelSignJobDTO struct { // DTO สำหรับยกเลิกงานป้าย
+	Reason       string  `json:"reason"`        // เหตุผลการยกเลิก (จำเป็น)
+	RefundAmount float64 `json:"refund_amount"` // ยอดเงินที่ต้องคืนลูกค้า (0 = ริบมัดจำทั้งหมด) ไม่เกินยอดที่รับมาแล้ว
+	RefundDue    string  `json:"refund_due"`    // กำหนดคืนเงิน (YYYY-MM-DD) ว่างได้ = 7 วัน
+}
+
+type CancelSignJobResultDTO struct { // ผลการยกเลิกงานป้าย
+	JobID             string   `json:"job_id"`              // รหัสงาน
+	ReversedIncomeIDs []string `json:"reversed_income_ids"` // รายได้ที่ถูกกลับรายการ
+	ClosedReceivables []string `json:"closed_receivables"`  // ลูกหนี้ที่ถูกปิด
+	RefundPayableID   string   `json:"refund_payable_id"`   // เจ้าหนี้คืนเงิน (ถ้ามี)
+	CancelledTaskIDs  []string `json:"cancelled_task_ids"`  // งานที่ถูกยกเลิก
+	ReceivedAmount    float64  `json:"received_amount"`     // ยอดเงินที่รับมาแล้ว
+	RefundAmount      float64  `json:"refund_amount"`       // ยอดที่ต้องคืน
+	RetainedAmount    float64  `json:"retained_amount"`     // ยอดที่ริบไว้
+	WrittenOffBalance float64  `json:"written_off_balance"` // ยอดลูกหนี้คงค้างที่ปิดทิ้ง
 }
@@ -0,0 +1,63 @@
+package dto
+
+import "time"
+
+// ---------- Request DTO ----------
+
+type SignTypeWorkflowItemDTO struct {
+	WorkFlowID   string   `json:"workflow_id"`   // workflow template (จำเป็น)
+	DepartmentID string   `json:"department_id"` // แผนกเริ่มต้น (ว่าง = ใช้แผนกของ workflow)
+	AssigneeID   string   `json:"assignee_id"`   // ผู้รับผิดชอบเริ่มต้น
+	AssigneePool []string `json:"assignee_pool"` // กลุ่มผู้รับผิดชอบแบบวนคิว
+	KPIID        string   `json:"kpi_id"`        // KPI ที่ใช้ประเมิน
+	Importance   string   `json:"importance"`    // low|mid|high
+	Order        int      `json:"order"`         // ลำดับการผลิต
+}
+
+type CreateSignTypeWorkflowDTO struct {
+	SignTypeID    string                    `json:"sign_type_id"`   // ประเภทป้าย (จำเป็น)
+	InstallOption string                    `json:"install_option"` // none|self|shop (ว่าง = ทุกตัวเลือก)
+	Items         []SignTypeWorkflowItemDTO `json:"items"`          // workflow ที่ต้องทำ
+	BaseAreaSqm   float64                   `json:"base_area_sqm"`  // พื้นที่อ้างอิงของชั่วโมงใน workflow (ตร.ม.)
+}
+
+type UpdateSignTypeWorkflowDTO struct {
+	InstallOption *string                    `json:"install_option,omitempty"`
+	Items         *[]SignTypeWorkflowItemDTO `json:"items,omitempty"`
+	IsActive      *bool                      `json:"is_active,omitempty"`
+	BaseAreaSqm   *float64                   `json:"base_area_sqm,omitempty"`
+}
+
+type RequestListSignTypeWorkflow struct {
+	SignTypeID string `query:"sign_type_id"`
+	Page       int    `query:"page"`
+	Limit      int    `query:"limit"`
+}
+
+// ---------- Response DTO ----------
+
+type SignTypeWorkflowItemResponse struct {
+	WorkFlowID     string   `json:"workflow_id"`
+	WorkFlowName   string   `json:"workflow_name"`
+	DepartmentID   string   `json:"department_id"`
+	DepartmentName string   `json:"department_name"`
+	AssigneeID     string   `json:"assignee_id"`
+	AssigneePool   []string `json:"assignee_pool"`
+	KPIID          string   `json:"kpi_id"`
+	Importance     string   `json:"importance"`
+	Order          int      `json:"order"`
+	TotalHours     float64  `json:"total_hours"`
+}
+
+type SignTypeWorkflowDTO struct {
+	CreatedAt     time.Time                      `json:"created_at"`
+	UpdatedAt     time.Time                      `json:"updated_at"`
+	MappingID     string                         `json:"mapping_id"`
+	SignTypeID    string                         `json:"sign_type_id"`
+	SignTypeName  string                         `json:"sign_type_name"`
+	InstallOption string                         `json:"install_option"`
+	CreatedBy     string                         `json:"created_by"`
+	Items         []SignTypeWorkflowItemResponse `json:"items"`
+	IsActive      bool                           `json:"is_active"`
+	BaseAreaSqm   float64                        `json:"base_area_sqm"`
+}
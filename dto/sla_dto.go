@@ -0,0 +1,113 @@
+package dto
+
+import "time"
+
+// ---------- Request DTO ----------
+
+type CreateSLARuleDTO struct {
+	Name        string  `json:"name" example:"ติดตั้งหน้างาน"`
+	Scope       string  `json:"scope" example:"step"` // step|importance
+	WorkFlowID  string  `json:"workflow_id,omitempty"`
+	StepName    string  `json:"step_name,omitempty" example:"ติดตั้ง"`
+	Importance  string  `json:"importance,omitempty" example:"high"`
+	TargetHours float64 `json:"target_hours" example:"24"`            // scope=step: 0 = ใช้ชั่วโมงประมาณการของ step
+	HoursFactor float64 `json:"hours_factor,omitempty" example:"1.5"` // scope=importance: ตัวคูณชั่วโมงประมาณการของ step
+	WarnPercent float64 `json:"warn_percent" example:"80"`            // 0 = ค่าเริ่มต้น 80
+	Active      *bool   `json:"active,omitempty"`                     // ค่าเริ่มต้น true
+}
+
+type UpdateSLARuleDTO struct {
+	Name        *string  `json:"name,omitempty"`
+	TargetHours *float64 `json:"target_hours,omitempty"`
+	HoursFactor *float64 `json:"hours_factor,omitempty"`
+	WarnPercent *float64 `json:"warn_percent,omitempty"`
+	Active      *bool    `json:"active,omitempty"`
+}
+
+type RequestListSLARules struct {
+	Scope      string `query:"scope"`
+	WorkFlowID string `query:"workflow_id"`
+}
+
+type RequestListSLABreaches struct {
+	Page         int    `query:"page"`
+	Limit        int    `query:"limit"`
+	Kind         string `query:"kind"`   // task|step
+	Level        string `query:"level"`  // warning|overdue
+	Status       string `query:"status"` // open|resolved
+	DepartmentID string `query:"department_id"`
+	UserID       string `query:"user_id"`
+	TaskID       string `query:"task_id"`
+	From         string `query:"from"` // YYYY-MM-DD (detected_at)
+	To           string `query:"to"`
+}
+
+type RequestSLABreachSummary struct {
+	GroupBy      string `query:"group_by"` // user|department|workflow
+	DepartmentID string `query:"department_id"`
+	From         string `query:"from"`
+	To           string `query:"to"`
+}
+
+// ---------- Response DTO ----------
+
+type SLARuleDTO struct {
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	RuleID       string    `json:"rule_id"`
+	Name         string    `json:"name"`
+	Scope        string    `json:"scope"`
+	WorkFlowID   string    `json:"workflow_id,omitempty"`
+	WorkFlowName string    `json:"workflow_name,omitempty"`
+	StepName     string    `json:"step_name,omitempty"`
+	Importance   string    `json:"importance,omitempty"`
+	TargetHours  float64   `json:"target_hours"`
+	HoursFactor  float64   `json:"hours_factor,omitempty"`
+	WarnPercent  float64   `json:"warn_percent"`
+	Active       bool      `json:"active"`
+	CreatedBy    string    `json:"created_by"`
+}
+
+type SLABreachDTO struct {
+	DueAt          time.Time  `json:"due_at"`
+	DetectedAt     time.Time  `json:"detected_at"`
+	EscalatedAt    *time.Time `json:"escalated_at,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	BreachID       string     `json:"breach_id"`
+	RuleID         string     `json:"rule_id,omitempty"`
+	Kind           string     `json:"kind"`
+	Level          string     `json:"level"`
+	Status         string     `json:"status"`
+	TaskID         string     `json:"task_id"`
+	JobName        string     `json:"job_name"`
+	StepID         string     `json:"step_id,omitempty"`
+	StepName       string     `json:"step_name,omitempty"`
+	WorkFlowID     string     `json:"workflow_id"`
+	UserID         string     `json:"user_id"`
+	UserName       string     `json:"user_name"`
+	DepartmentID   string     `json:"department_id"`
+	DepartmentName string     `json:"department_name"`
+	EscalatedTo    string     `json:"escalated_to,omitempty"`
+	OverdueHours   float64    `json:"overdue_hours"`
+}
+
+type SLABreachSummaryDTO struct {
+	Key          string  `json:"key"`
+	Name         string  `json:"name"`
+	Warnings     int     `json:"warnings"`      // จำนวนครั้งที่ถูกเตือน
+	Overdue      int     `json:"overdue"`       // จำนวนครั้งที่เกินกำหนด
+	OpenOverdue  int     `json:"open_overdue"`  // เกินกำหนดและยังไม่ปิด
+	OverdueHours float64 `json:"overdue_hours"` // ชั่วโมงเกินกำหนดรวม
+}
+
+// SLACheckResult ผลการรันตรวจ SLA หนึ่งรอบ
+type SLACheckResult struct {
+	RunAt           string `json:"run_at"`
+	CheckedTasks    int    `json:"checked_tasks"`
+	Warnings        int    `json:"warnings"`         // การเตือนใหม่ในรอบนี้
+	Overdue         int    `json:"overdue"`          // เกินกำหนดใหม่ในรอบนี้
+	Escalated       int    `json:"escalated"`        // ส่งแจ้งผู้จัดการแผนก
+	Resolved        int    `json:"resolved"`         // breach ที่ปิดในรอบนี้
+	UpdatedTasks    int    `json:"updated_tasks"`    // งานที่สถานะ SLA เปลี่ยน
+	UpdatedBreaches int    `json:"updated_breaches"` // breach ที่อัปเดตชั่วโมงเกินกำหนด
+}
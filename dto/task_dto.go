@@ -23,9 +23,10 @@ type CreateTaskRequest struct {
 }
 
 type ExtraStepRequest struct {
-	StepName    string  `json:"step_name"`
-	Description string  `json:"description,omitempty"`
-	Hours       float64 `json:"hours"`
+	StepName    string   `json:"step_name"`
+	Description string   `json:"description,omitempty"`
+	Hours       float64  `json:"hours"`
+	Checklist   []string `json:"checklist,omitempty"` // รายการตรวจสอบของ step
 }
 
 type UpdateTaskRequest struct {
@@ -73,12 +74,36 @@ type TaskStepPatch struct {
 	StepID      string     `json:"step_id"` // target
 }
 
+// มอบหมายผู้รับผิดชอบ step
+type AssignStepRequest struct {
+	Assignee string `json:"assignee"` // user_id ผู้รับผิดชอบ step (ว่าง = คืนให้ผู้รับผิดชอบหลักของงาน)
+}
+
 // อัปเดตสเต็ปเดียว
 type UpdateStepStatusNoteRequest struct {
 	Status *string `json:"status,omitempty"` // todo|in_progress|skip|done (optional)
 	Notes  *string `json:"notes,omitempty"`  // optional
 }
 
+type AddChecklistItemRequest struct {
+	Text string `json:"text"` // ข้อความรายการตรวจสอบ
+}
+
+type CheckChecklistItemRequest struct {
+	Checked bool `json:"checked"` // true = ติ๊ก, false = ยกเลิกติ๊ก
+}
+
+// เปลี่ยนผู้รับผิดชอบหลักของงาน
+type ReassignTaskRequest struct {
+	Assignee      string `json:"assignee"`       // user_id ผู้รับผิดชอบใหม่
+	Reason        string `json:"reason"`         // เหตุผล (บังคับ)
+	TransferSteps bool   `json:"transfer_steps"` // โอน step ที่ยังไม่ปิดซึ่งระบุผู้รับผิดชอบเดิมไว้ตรงๆ ไปด้วย
+}
+
+type RequestTaskStats struct {
+	UserID string `query:"user_id"` // ว่าง = ทุกคน
+}
+
 type RequestListTask struct {
 	Search     string `query:"search"`        // คำค้นหาสำหรับกรองข้อมูล
 	Department string `query:"department_id"` // แผนก
@@ -114,13 +139,17 @@ type TaskDTO struct {
 	KPIID      string `json:"kpi_id"`      // รหัส KPI ที่เกี่ยวข้อง
 	WorkFlowID string `json:"workflow_id"` // รหัส Workflow (อ้างอิง template/ค้นสถิติ)
 
-	Status        string `json:"status"`          // สถานะปัจจุบันของงาน (todos|in_progress|skip|done)
-	StepName      string `json:"step_name"`       // ชื่อขั้นตอนปัจจุบัน
-	CreatedBy     string `json:"created_by"`      // ผู้สร้างงาน
-	CreatedByName string `json:"created_by_name"` // ชื่อผู้สร้างงาน
+	Status        string   `json:"status"`               // สถานะปัจจุบันของงาน (todos|in_progress|skip|done)
+	StepName      string   `json:"step_name"`            // ชื่อขั้นตอนปัจจุบัน
+	ReadySteps    []string `json:"ready_steps"`          // ชื่อขั้นตอนที่เริ่มได้ทันที
+	SLAStatus     string   `json:"sla_status,omitempty"` // สถานะ SLA (on_track|warning|overdue)
+	CreatedBy     string   `json:"created_by"`           // ผู้สร้างงาน
+	CreatedByName string   `json:"created_by_name"`      // ชื่อผู้สร้างงาน
 
 	AppliedWorkflow TaskAppliedWorkflow `json:"applied_workflow"` // Snapshot workflow ที่ใช้ในงานนี้
 
+	ReassignHistory []TaskReassignmentDTO `json:"reassign_history,omitempty"` // ประวัติการเปลี่ยนผู้รับผิดชอบหลัก
+
 	Width    float64 `json:"width"`    // ความกว้าง (ซม.)
 	Height   float64 `json:"height"`   // ความสูง (ซม.)
 	Quantity int     `json:"quantity"` // จำนวน
@@ -138,17 +167,33 @@ type TaskAppliedWorkflow struct {
 }
 
 type TaskWorkflowStep struct {
-	CreatedAt   time.Time  `json:"created_at"`             // วันที่สร้างขั้นตอนนี้
-	UpdatedAt   time.Time  `json:"updated_at"`             // วันที่อัปเดตขั้นตอนล่าสุด
-	StartedAt   *time.Time `json:"started_at,omitempty"`   // เวลาที่เริ่ม (optional)
-	CompletedAt *time.Time `json:"completed_at,omitempty"` // เวลาที่เสร็จ (optional)
-	StepID      string     `json:"step_id"`                // รหัส Step (UUID)
-	StepName    string     `json:"step_name"`              // ชื่อ Step
-	Description string     `json:"description,omitempty"`  // รายละเอียด (ไม่บังคับ)
-	Status      string     `json:"status"`                 // สถานะ (todo|in_progress|skip|done)
-	Notes       string     `json:"notes,omitempty"`        // บันทึก/หมายเหตุ
-	Hours       float64    `json:"hours"`                  // ชั่วโมงที่ใช้ (รองรับทศนิยม เช่น 0.5)
-	Order       int        `json:"order"`                  // ลำดับขั้นตอน (1..N)
+	CreatedAt    time.Time  `json:"created_at"`              // วันที่สร้างขั้นตอนนี้
+	UpdatedAt    time.Time  `json:"updated_at"`              // วันที่อัปเดตขั้นตอนล่าสุด
+	StartedAt    *time.Time `json:"started_at,omitempty"`    // เวลาที่เริ่ม (optional)
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`  // เวลาที่เสร็จ (optional)
+	StepID       string     `json:"step_id"`                 // รหัส Step (UUID)
+	StepName     string     `json:"step_name"`               // ชื่อ Step
+	Description  string     `json:"description,omitempty"`   // รายละเอียด (ไม่บังคับ)
+	Status       string     `json:"status"`                  // สถานะ (todo|in_progress|skip|done)
+	Notes        string     `json:"notes,omitempty"`         // บันทึก/หมายเหตุ
+	Hours        float64    `json:"hours"`                   // ชั่วโมงที่ใช้ (รองรับทศนิยม เช่น 0.5)
+	Order        int        `json:"order"`                   // ลำดับขั้นตอน (1..N)
+	DependsOn    []string   `json:"depends_on,omitempty"`    // step_id ที่ต้องเสร็จก่อนเริ่ม
+	Department   string     `json:"department_id,omitempty"` // แผนกที่ทำ step นี้
+	Assignee     string     `json:"assignee"`                // ผู้รับผิดชอบ step (คำนวณจากผู้รับผิดชอบหลักถ้าไม่ได้ระบุ)
+	AssigneeName string     `json:"assignee_name"`           // ชื่อผู้รับผิดชอบ step
+	SLADueAt     *time.Time `json:"sla_due_at,omitempty"`    // กำหนดเสร็จตาม SLA
+	SLAStatus    string     `json:"sla_status,omitempty"`    // on_track|warning|overdue
+
+	Checklist []TaskChecklistItem `json:"checklist,omitempty"` // รายการตรวจสอบของ step
+}
+
+type TaskChecklistItem struct {
+	ItemID    string     `json:"item_id"`
+	Text      string     `json:"text"`
+	Checked   bool       `json:"checked"`
+	CheckedBy string     `json:"checked_by,omitempty"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
 }
 
 // NEW
@@ -203,5 +248,47 @@ type PutWorkflowStep struct {
 	Status      string     `json:"status"` // todo|in_progress|skip|done
 	Notes       string     `json:"notes"`
 	Hours       float64    `json:"hours"`
-	Order       int        `json:"order"` // จะถูก normalize และ reindex เป็น 1..N
+	Order       int        `json:"order"`                   // จะถูก normalize และ reindex เป็น 1..N
+	DependsOn   []string   `json:"depends_on,omitempty"`    // step_id ที่ต้องเสร็จก่อน (ต้องอยู่ในชุด steps เดียวกัน)
+	Department  string     `json:"department_id,omitempty"` // ว่าง = คงของเดิม
+	Assignee    string     `json:"assignee,omitempty"`      // ผู้รับผิดชอบ step (ว่าง = คงของเดิม)
+}
+
+type TaskReassignmentDTO struct {
+	ReassignedAt time.Time `json:"reassigned_at"`
+	FromAssignee string    `json:"from_assignee"`
+	FromName     string    `json:"from_name,omitempty"`
+	ToAssignee   string    `json:"to_assignee"`
+	ToName       string    `json:"to_name,omitempty"`
+	Reason       string    `json:"reason,omitempty"`
+	StepIDs      []string  `json:"step_ids,omitempty"`
+	ReassignedBy string    `json:"reassigned_by"`
+}
+
+// ตัวเลขรวมของ user_task_stats
+type TaskStatsTotalsDTO struct {
+	Assigned   int `json:"assigned"`
+	Open       int `json:"open"`
+	InProgress int `json:"in_progress"`
+	Completed  int `json:"completed"`
+}
+
+// TaskStatsDriftDTO ผู้ใช้ที่ตัวเลขใน user_task_stats ไม่ตรงกับงานจริง
+type TaskStatsDriftDTO struct {
+	UserID       string             `json:"user_id"`
+	DepartmentID string             `json:"department_id,omitempty"`
+	Stored       TaskStatsTotalsDTO `json:"stored"`  // ค่าที่เก็บอยู่
+	Actual       TaskStatsTotalsDTO `json:"actual"`  // ค่าที่นับจาก tasks
+	Missing      bool               `json:"missing"` // ยังไม่มีเอกสาร stats
+}
+
+// TaskStatsCheckResult ผลตรวจ/สร้าง user_task_stats ใหม่จาก tasks
+type TaskStatsCheckResult struct {
+	RunAt      time.Time           `json:"run_at"`
+	Tasks      int                 `json:"tasks"`    // งานที่นับ
+	Users      int                 `json:"users"`    // ผู้ใช้ที่ตรวจ
+	Drifted    int                 `json:"drifted"`  // ผู้ใช้ที่ตัวเลขไม่ตรง
+	Repaired   int                 `json:"repaired"` // ผู้ใช้ที่เขียนค่าใหม่แล้ว (rebuild)
+	Drift      []TaskStatsDriftDTO `json:"drift"`
+	DurationMS int64               `json:"duration_ms"`
 }
@@ -0,0 +1,145 @@
+package dto
+
+import "time"
+
+// ---------- Request DTO ----------
+
+type StartTimerDTO struct {
+	TaskID string `json:"task_id"` // งาน (จำเป็น)
+	StepID string `json:"step_id"` // ขั้นตอน (จำเป็น)
+	Note   string `json:"note"`    // หมายเหตุ
+}
+
+type StopTimerDTO struct {
+	Note    string `json:"note"`     // หมายเหตุ (ว่าง = คงของเดิม)
+	EndedAt string `json:"ended_at"` // RFC3339 เวลาหยุดจริง (ว่าง = ตอนนี้) จำเป็นเมื่อจับเวลานานเกิน 12 ชั่วโมง
+}
+
+type CreateManualTimeEntryDTO struct {
+	TaskID    string  `json:"task_id"`    // งาน (จำเป็น)
+	StepID    string  `json:"step_id"`    // ขั้นตอน (จำเป็น)
+	StartedAt string  `json:"started_at"` // เวลาเริ่ม RFC3339 (ใช้คู่กับ ended_at)
+	EndedAt   string  `json:"ended_at"`   // เวลาสิ้นสุด RFC3339
+	WorkDate  string  `json:"work_date"`  // หรือระบุวันที่ (YYYY-MM-DD) + hours แทนช่วงเวลา
+	Hours     float64 `json:"hours"`      // ชั่วโมง (ใช้คู่กับ work_date)
+	Note      string  `json:"note"`       // หมายเหตุ
+}
+
+type UpdateTimeEntryDTO struct {
+	StartedAt string  `json:"started_at"` // เวลาเริ่มใหม่ RFC3339 (ว่าง = คงเดิม)
+	EndedAt   string  `json:"ended_at"`   // เวลาสิ้นสุดใหม่ RFC3339 (ว่าง = คงเดิม)
+	Note      *string `json:"note"`       // หมายเหตุใหม่
+	Reason    string  `json:"reason"`     // เหตุผลการแก้ไข (จำเป็น)
+}
+
+type RequestListTimeEntries struct {
+	TaskID string `query:"task_id"` // กรองตามงาน
+	StepID string `query:"step_id"` // กรองตามขั้นตอน
+	UserID string `query:"user_id"` // กรองตามผู้ใช้ (ว่าง = ตัวเอง ยกเว้น admin/กรองตามงาน)
+	From   string `query:"from"`    // ตั้งแต่วันที่ (YYYY-MM-DD)
+	To     string `query:"to"`      // ถึงวันที่ (YYYY-MM-DD)
+}
+
+type RequestTimeReport struct {
+	GroupBy      string `query:"group_by"`      // workflow|user
+	From         string `query:"from"`          // ตั้งแต่วันที่ (YYYY-MM-DD) ว่าง = 30 วันก่อน
+	To           string `query:"to"`            // ถึงวันที่ (YYYY-MM-DD) ว่าง = วันนี้
+	DepartmentID string `query:"department_id"` // แผนกของผู้ลงเวลา
+	WorkFlowID   string `query:"workflow_id"`   // template
+	UserID       string `query:"user_id"`       // ผู้ใช้
+}
+
+type RequestTimesheet struct {
+	UserID string `query:"user_id"` // ผู้ใช้ (ว่าง = ตัวเอง)
+	Week   string `query:"week"`    // วันใดก็ได้ในสัปดาห์ (YYYY-MM-DD) ว่าง = สัปดาห์นี้
+}
+
+type SubmitTimesheetDTO struct {
+	Week string `json:"week"` // วันใดก็ได้ในสัปดาห์ (YYYY-MM-DD)
+}
+
+type ReviewTimesheetDTO struct {
+	Approve bool   `json:"approve"` // true = อนุมัติ, false = ตีกลับ
+	Note    string `json:"note"`    // หมายเหตุ (จำเป็นเมื่อตีกลับ)
+}
+
+type RequestListTimesheets struct {
+	DepartmentID string `query:"department_id"` // แผนก (ว่าง = แผนกที่ตัวเองเป็นผู้จัดการ)
+	Status       string `query:"status"`        // submitted|approved|rejected
+	Week         string `query:"week"`          // วันใดก็ได้ในสัปดาห์ (YYYY-MM-DD)
+}
+
+// ---------- Response DTO ----------
+
+type TimeEntryDTO struct {
+	StartedAt    time.Time          `json:"started_at"`
+	EndedAt      *time.Time         `json:"ended_at"`
+	WorkDate     string             `json:"work_date"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+	EntryID      string             `json:"entry_id"`
+	TaskID       string             `json:"task_id"`
+	StepID       string             `json:"step_id"`
+	StepName     string             `json:"step_name"`
+	WorkFlowID   string             `json:"workflow_id"`
+	JobID        string             `json:"job_id,omitempty"`
+	UserID       string             `json:"user_id"`
+	UserName     string             `json:"user_name"`
+	DepartmentID string             `json:"department_id"`
+	Source       string             `json:"source"` // timer|manual
+	Note         string             `json:"note,omitempty"`
+	Edits        []TimeEntryEditDTO `json:"edits,omitempty"`
+	Hours        float64            `json:"hours"`   // ชั่วโมง (กำลังจับเวลา = ถึงตอนนี้)
+	Running      bool               `json:"running"` // กำลังจับเวลาอยู่
+}
+
+type TimeEntryEditDTO struct {
+	EditedAt      time.Time  `json:"edited_at"`
+	PrevStartedAt time.Time  `json:"prev_started_at"`
+	PrevEndedAt   *time.Time `json:"prev_ended_at"`
+	EditedBy      string     `json:"edited_by"`
+	Reason        string     `json:"reason"`
+	PrevHours     float64    `json:"prev_hours"`
+}
+
+type TimesheetDayDTO struct {
+	Date    string         `json:"date"`
+	Hours   float64        `json:"hours"`
+	Entries []TimeEntryDTO `json:"entries"`
+}
+
+type TimesheetDTO struct {
+	SubmittedAt  *time.Time        `json:"submitted_at"`
+	ReviewedAt   *time.Time        `json:"reviewed_at"`
+	TimesheetID  string            `json:"timesheet_id,omitempty"`
+	UserID       string            `json:"user_id"`
+	UserName     string            `json:"user_name"`
+	DepartmentID string            `json:"department_id"`
+	WeekStart    string            `json:"week_start"`
+	WeekEnd      string            `json:"week_end"`
+	Status       string            `json:"status"` // draft|submitted|approved|rejected
+	ReviewedBy   string            `json:"reviewed_by,omitempty"`
+	ReviewNote   string            `json:"review_note,omitempty"`
+	Days         []TimesheetDayDTO `json:"days,omitempty"`
+	TotalHours   float64           `json:"total_hours"`
+}
+
+type EstimateVsActualStepDTO struct {
+	StepName        string  `json:"step_name"`
+	EstimatedHours  float64 `json:"estimated_hours"`
+	ActualHours     float64 `json:"actual_hours"`
+	VarianceHours   float64 `json:"variance_hours"`   // actual - estimated
+	VariancePercent float64 `json:"variance_percent"` // (actual - estimated) / estimated x 100
+}
+
+type EstimateVsActualDTO struct {
+	Key             string                    `json:"key"`  // workflow_id หรือ user_id
+	Name            string                    `json:"name"` // ชื่อ template หรือชื่อผู้ใช้
+	TaskCount       int                       `json:"task_count"`
+	StepCount       int                       `json:"step_count"`
+	EstimatedHours  float64                   `json:"estimated_hours"`
+	ActualHours     float64                   `json:"actual_hours"`
+	VarianceHours   float64                   `json:"variance_hours"`
+	VariancePercent float64                   `json:"variance_percent"`
+	Steps           []EstimateVsActualStepDTO `json:"steps,omitempty"` // แยกตามชื่อขั้นตอน (เฉพาะ group_by=workflow)
+}
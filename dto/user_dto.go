@@ -64,27 +64,27 @@ type RequestGetUserAll struct {
 }
 
 type RequestUpdateUser struct {
-	Email             string              `json:"email"`              // อีเมลของผู้ใช้
-	TitleTH           string              `json:"title_th"`           // คำนำหน้าชื่อ (ภาษาไทย)
-	TitleEN           string              `json:"title_en"`           // คำนำหน้าชื่อ (ภาษาอังกฤษ)
-	FirstNameTH       string              `json:"first_name_th"`      // ชื่อจริงของพนักงาน
-	LastNameTH        string              `json:"last_name_th"`       // นามสกุลของพนักงาน
-	FirstNameEN       string              `json:"first_name_en"`      // ชื่อจริงของพนักงาน (ภาษาอังกฤษ)
-	LastNameEN        string              `json:"last_name_en"`       // นามสกุลของพนักงาน (ภาษาอังกฤษ)
-	NickName          string              `json:"nickname"`           // ชื่อเล่นของพนักงาน
-	IDCard            string              `json:"id_card"`            // หมายเลขบัตรประชาชน (อาจเข้ารหัสก่อนจัดเก็บ)
-	Phone             string              `json:"phone"`              // เบอร์โทรศัพท์ของพนักงาน
-	EmployeeCode      string              `json:"employee_code"`      // รหัสพนักงาน (อาจใช้สำหรับอ้างอิงภายใน)
-	Gender            string              `json:"gender"`             // เพศของพนักงาน
-	BirthDate         string              `json:"birth_date"`         // วันเดือนปีเกิดของพนักงาน (รูปแบบ string)
-	PositionID        string              `json:"position_id"`        // รหัสตำแหน่งงาน (FK ไปยัง Positions)
-	DepartmentID      string              `json:"department_id"`      // รหัสแผนก (FK ไปยัง Departments)
-	HireDate          string              `json:"hire_date"`          // วันที่เริ่มงาน
-	EmploymentType    string              `json:"employment_type"`    // ประเภทการจ้างงาน (เช่น full-time, part-time)
-	EmploymentHistory []EmploymentHistory `json:"employment_history"` // ประวัติการจ้างงาน (อาจมีหลายรายการ)
-	Address           Address             `json:"address"`            // ที่อยู่ของพนักงาน
-	BankInfo          BankInfo            `json:"bank_info"`          // ข้อมูลบัญชีธนาคารของพนักงาน
-	Documents         []Document          `json:"documents"`          // รายการเอกสารที่เกี่ยวข้องกับพนักงาน
+	Email          string     `json:"email"`           // อีเมลของผู้ใช้
+	TitleTH        string     `json:"title_th"`        // คำนำหน้าชื่อ (ภาษาไทย)
+	TitleEN        string     `json:"title_en"`        // คำนำหน้าชื่อ (ภาษาอังกฤษ)
+	FirstNameTH    string     `json:"first_name_th"`   // ชื่อจริงของพนักงาน
+	LastNameTH     string     `json:"last_name_th"`    // นามสกุลของพนักงาน
+	FirstNameEN    string     `json:"first_name_en"`   // ชื่อจริงของพนักงาน (ภาษาอังกฤษ)
+	LastNameEN     string     `json:"last_name_en"`    // นามสกุลของพนักงาน (ภาษาอังกฤษ)
+	NickName       string     `json:"nickname"`        // ชื่อเล่นของพนักงาน
+	IDCard         string     `json:"id_card"`         // หมายเลขบัตรประชาชน (อาจเข้ารหัสก่อนจัดเก็บ)
+	Phone          string     `json:"phone"`           // เบอร์โทรศัพท์ของพนักงาน
+	EmployeeCode   string     `json:"employee_code"`   // รหัสพนักงาน (อาจใช้สำหรับอ้างอิงภายใน)
+	Gender         string     `json:"gender"`          // เพศของพนักงาน
+	BirthDate      string     `json:"birth_date"`      // วันเดือนปีเกิดของพนักงาน (รูปแบบ string)
+	PositionID     string     `json:"position_id"`     // รหัสตำแหน่งงาน (FK ไปยัง Positions)
+	DepartmentID   string     `json:"department_id"`   // รหัสแผนก (FK ไปยัง Departments)
+	HireDate       string     `json:"hire_date"`       // วันที่เริ่มงาน
+	EmploymentType string     `json:"employment_type"` // ประเภทการจ้างงาน (เช่น full-time, part-time)
+	EffectiveDate  string     `json:"effective_date"`  // วันที่ตำแหน่ง/แผนก/ประเภทการจ้างใหม่มีผล YYYY-MM-DD (ว่าง = วันนี้)
+	Address        Address    `json:"address"`         // ที่อยู่ของพนักงาน
+	BankInfo       BankInfo   `json:"bank_info"`       // ข้อมูลบัญชีธนาคารของพนักงาน
+	Documents      []Document `json:"documents"`       // รายการเอกสารที่เกี่ยวข้องกับพนักงาน
 }
 
 type RequestUpdateDocuments struct {
@@ -133,10 +133,13 @@ type EmploymentHistory struct {
 	UpdatedAt      time.Time  `json:"updated_at"`
 	ToDate         *time.Time `json:"to_date"`         // วันที่สิ้นสุด (nullable ถ้ายังทำอยู่)
 	DeletedAt      *time.Time `json:"deleted_at"`      // soft delete
+	HistoryID      string     `json:"history_id"`      // รหัสรายการประวัติ
 	UserID         string     `json:"user_id"`         // รหัสผู้ใช้ที่เกี่ยวข้อง
 	PositionID     string     `json:"position_id"`     // ตำแหน่งในช่วงเวลานั้น
 	DepartmentID   string     `json:"department_id"`   // แผนกในช่วงเวลานั้น
 	EmploymentType string     `json:"employment_type"` // ประเภทการจ้าง (เช่น full-time, intern)
+	ChangeType     string     `json:"change_type"`     // hire|change
+	Status         string     `json:"status"`          // applied|scheduled
 	Note           string     `json:"note,omitempty"`  // หมายเหตุ (ถ้ามี)
 }
 
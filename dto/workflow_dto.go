@@ -11,10 +11,14 @@ type CreateWorkflowTemplateDTO struct {
 }
 
 type CreateWorkflowStepDTO struct {
-	StepName    string  `json:"step_name"`             // ชื่อ Template
-	Description string  `json:"description,omitempty"` // คำอธิบาย (ไม่บังคับ)
-	Hours       float64 `json:"hours"`                 // ชั่วโมง (รองรับทศนิยม)
-	Order       int     `json:"order"`                 // ลำดับ (1,2,3,...)
+	StepID      string   `json:"step_id,omitempty"`       // step_id เดิม ตอนแก้ไข template (คงตัวตน step ข้ามเวอร์ชัน; ว่าง = จับคู่จากชื่อ)
+	StepName    string   `json:"step_name"`               // ชื่อ Template
+	Description string   `json:"description,omitempty"`   // คำอธิบาย (ไม่บังคับ)
+	Hours       float64  `json:"hours"`                   // ชั่วโมง (รองรับทศนิยม)
+	Order       int      `json:"order"`                   // ลำดับ (1,2,3,...)
+	DependsOn   []int    `json:"depends_on,omitempty"`    // order ของ step ที่ต้องเสร็จก่อน (ว่าง = เริ่มได้เลย)
+	Department  string   `json:"department_id,omitempty"` // แผนกที่ทำ step นี้ (ว่าง = แผนกของ template)
+	Checklist   []string `json:"checklist,omitempty"`     // รายการตรวจสอบที่ต้องติ๊กครบก่อนปิด step
 }
 
 // Partial update payload (use pointer fields)
@@ -23,6 +27,7 @@ type UpdateWorkflowTemplateDTO struct {
 	WorkFlowName string                   `json:"workflow_name"` // ชื่อ Template
 	Department   string                   `json:"department_id,omitempty"`
 	Description  string                   `json:"description,omitempty"`
+	ChangeNote   string                   `json:"change_note,omitempty"` // หมายเหตุของเวอร์ชันใหม่
 }
 
 type RequestListWorkflow struct {
@@ -34,6 +39,20 @@ type RequestListWorkflow struct {
 	Limit      int    `query:"limit"`         // จำนวนรายการต่อหน้า
 }
 
+type RequestWorkflowDiff struct {
+	From int `query:"from"` // เวอร์ชันต้นทาง (ว่าง = to-1)
+	To   int `query:"to"`   // เวอร์ชันปลายทาง (ว่าง = เวอร์ชันล่าสุด)
+}
+
+// MigrateWorkflowTasksDTO ย้ายงานที่ยังไม่เสร็จจากเวอร์ชันเก่าไปเวอร์ชันใหม่ของ template
+type MigrateWorkflowTasksDTO struct {
+	FromVersion int               `json:"from_version"`           // เวอร์ชันที่งานใช้อยู่
+	ToVersion   int               `json:"to_version,omitempty"`   // เวอร์ชันปลายทาง (ว่าง = ล่าสุด)
+	TaskIDs     []string          `json:"task_ids,omitempty"`     // งานที่ต้องการย้าย (ว่าง = ทุกงานที่ยังไม่เสร็จบนเวอร์ชันต้นทาง)
+	StepMapping map[string]string `json:"step_mapping,omitempty"` // step_id เวอร์ชันเก่า -> step_id เวอร์ชันใหม่ ("" = ตัดทิ้ง) ทับการจับคู่อัตโนมัติ
+	DryRun      bool              `json:"dry_run"`                // true = แสดงผลลัพธ์โดยไม่บันทึก
+}
+
 // ---------- Response DTO ----------
 type WorkflowTemplateDTO struct {
 	CreatedAt    time.Time         `json:"created_at"`
@@ -57,4 +76,82 @@ type WorkflowStepDTO struct {
 	Description string    `json:"description,omitempty"`
 	Hours       float64   `json:"hours"`
 	Order       int       `json:"order"`
+	DependsOn   []string  `json:"depends_on,omitempty"` // step_id ที่ต้องเสร็จก่อน
+	Department  string    `json:"department_id,omitempty"`
+	Checklist   []string  `json:"checklist,omitempty"`
+}
+
+type WorkflowVersionDTO struct {
+	CreatedAt    time.Time         `json:"created_at"`
+	VersionID    string            `json:"version_id"`
+	WorkFlowID   string            `json:"workflow_id"`
+	Version      int               `json:"version"`
+	WorkFlowName string            `json:"workflow_name"`
+	Department   string            `json:"department_id"`
+	Description  string            `json:"description"`
+	Steps        []WorkflowStepDTO `json:"steps"`
+	TotalHours   float64           `json:"total_hours"`
+	ChangeNote   string            `json:"change_note,omitempty"`
+	CreatedBy    string            `json:"created_by"`
+	IsCurrent    bool              `json:"is_current"` // เป็นเวอร์ชันปัจจุบันของ template
+}
+
+type WorkflowFieldChangeDTO struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+type WorkflowStepChangeDTO struct {
+	StepID   string                   `json:"step_id"`
+	StepName string                   `json:"step_name"`
+	Changes  []WorkflowFieldChangeDTO `json:"changes"`
+}
+
+type WorkflowVersionDiffDTO struct {
+	WorkFlowID   string                   `json:"workflow_id"`
+	FromVersion  int                      `json:"from_version"`
+	ToVersion    int                      `json:"to_version"`
+	Fields       []WorkflowFieldChangeDTO `json:"fields"`        // ชื่อ/แผนก/รายละเอียดของ template
+	AddedSteps   []WorkflowStepDTO        `json:"added_steps"`   // step ที่เพิ่มใหม่
+	RemovedSteps []WorkflowStepDTO        `json:"removed_steps"` // step ที่ถูกตัดออก
+	ChangedSteps []WorkflowStepChangeDTO  `json:"changed_steps"` // step ที่แก้ไข
+	HoursDelta   float64                  `json:"hours_delta"`   // ชั่วโมงรวมที่เปลี่ยน (to - from)
+}
+
+type WorkflowStepMappingDTO struct {
+	FromStepID   string `json:"from_step_id"`
+	FromStepName string `json:"from_step_name"`
+	ToStepID     string `json:"to_step_id"` // ว่าง = step ถูกตัดทิ้ง
+	ToStepName   string `json:"to_step_name"`
+}
+
+type WorkflowTaskStepPlanDTO struct {
+	StepID        string   `json:"step_id"` // step_id ในงาน (step เดิมคงรหัสเดิม)
+	StepName      string   `json:"step_name"`
+	Action        string   `json:"action"` // kept|merged|added|removed|custom
+	FromStepNames []string `json:"from_step_names,omitempty"`
+	Status        string   `json:"status"`
+}
+
+type WorkflowTaskMigrationDTO struct {
+	TaskID     string                    `json:"task_id"`
+	JobName    string                    `json:"job_name"`
+	Status     string                    `json:"status"`               // สถานะงานก่อนย้าย
+	NewStatus  string                    `json:"new_status,omitempty"` // สถานะงานหลังย้าย
+	Migrated   bool                      `json:"migrated"`             // ย้ายแล้ว (dry run = ย้ายได้)
+	SkipReason string                    `json:"skip_reason,omitempty"`
+	Steps      []WorkflowTaskStepPlanDTO `json:"steps,omitempty"`
+	Warnings   []string                  `json:"warnings,omitempty"`
+}
+
+type WorkflowMigrationResultDTO struct {
+	WorkFlowID  string                     `json:"workflow_id"`
+	FromVersion int                        `json:"from_version"`
+	ToVersion   int                        `json:"to_version"`
+	DryRun      bool                       `json:"dry_run"`
+	StepMapping []WorkflowStepMappingDTO   `json:"step_mapping"`
+	Tasks       []WorkflowTaskMigrationDTO `json:"tasks"`
+	Migrated    int                        `json:"migrated"`
+	Skipped     int                        `json:"skipped"`
 }
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"strings"
 
 	"github.com/Be2Bag/erp-demo/dto"
@@ -37,6 +38,7 @@ func (h *AdminHandler) AdminRoutes(router fiber.Router) {
 // @Param request body dto.RequestUpdateUserStatus true "Request Update User Status"
 // @Success 200 {object} dto.BaseResponse
 // @Failure 400 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
 // @Failure 500 {object} dto.BaseResponse
 // @Router /v1/admin/update-status-user [put]
 func (h *AdminHandler) UpdateStatusUser(c *fiber.Ctx) error {
@@ -85,6 +87,16 @@ func (h *AdminHandler) UpdateStatusUser(c *fiber.Ctx) error {
 			})
 		}
 
+		if errors.Is(errOnUpdate, ports.ErrChecklistIncomplete) || errors.Is(errOnUpdate, ports.ErrChecklistConflict) {
+			return c.Status(fiber.StatusConflict).JSON(dto.BaseResponse{
+				StatusCode: fiber.StatusConflict,
+				MessageEN:  "Cannot approve user: " + errOnUpdate.Error(),
+				MessageTH:  "ไม่สามารถอนุมัติผู้ใช้ได้ (รายการ onboarding ที่บังคับยังไม่ครบหรือถูกยกเลิกสิทธิ์แล้ว)",
+				Status:     "error",
+				Data:       nil,
+			})
+		}
+
 		if strings.Contains(errOnUpdate.Error(), "user status is not pending") {
 			return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
 				StatusCode: fiber.StatusBadRequest,
@@ -156,7 +168,7 @@ func (h *AdminHandler) UpdatePositionUser(c *fiber.Ctx) error {
 		})
 	}
 
-	errOnUpdate := h.svc.UpdateUserPosition(c.Context(), req)
+	errOnUpdate := h.svc.UpdateUserPosition(c.Context(), req, claims)
 	if errOnUpdate != nil {
 
 		if errOnUpdate == mongo.ErrNoDocuments {
@@ -169,6 +181,16 @@ func (h *AdminHandler) UpdatePositionUser(c *fiber.Ctx) error {
 			})
 		}
 
+		if errors.Is(errOnUpdate, ports.ErrEmploymentHistoryConflict) {
+			return c.Status(fiber.StatusConflict).JSON(dto.BaseResponse{
+				StatusCode: fiber.StatusConflict,
+				MessageEN:  "Failed to update user position: " + errOnUpdate.Error(),
+				MessageTH:  "วันที่มีผลไม่ถูกต้อง",
+				Status:     "error",
+				Data:       nil,
+			})
+		}
+
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.BaseResponse{
 			StatusCode: fiber.StatusInternalServerError,
 			MessageEN:  "Failed to update user position",
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/middleware"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type AttachmentHandler struct {
+	svc ports.AttachmentService
+	mdw *middleware.Middleware
+}
+
+func NewAttachmentHandler(s ports.AttachmentService, mdw *middleware.Middleware) *AttachmentHandler {
+	return &AttachmentHandler{svc: s, mdw: mdw}
+}
+
+func (h *AttachmentHandler) AttachmentRoutes(router fiber.Router) {
+	versionOne := router.Group("v1")
+	attachment := versionOne.Group("attachment")
+
+	attachment.Post("/upload", h.mdw.AuthCookieMiddleware(), h.UploadAttachment)
+	attachment.Get("/list", h.mdw.AuthCookieMiddleware(), h.ListAttachments)
+	attachment.Delete("/:id", h.mdw.AuthCookieMiddleware(), h.DeleteAttachment)
+}
+
+// @Summary Upload attachment
+// @Description แนบไฟล์กับงานป้าย, task, step, ใบเสร็จ, เจ้าหนี้ หรือรายจ่าย (รูปภาพจะสร้างรูปย่ออัตโนมัติ)
+// @Tags Attachment
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "File"
+// @Param entity_type formData string true "sign_job|task|step|receipt|payable|expense"
+// @Param entity_id formData string true "Entity ID"
+// @Param task_id formData string false "Task ID (required for step)"
+// @Param category formData string false "photo|design|proof|document|invoice|other"
+// @Param caption formData string false "Caption"
+// @Success 201 {object} dto.BaseResponse{data=dto.AttachmentDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 500 {object} dto.BaseResponse
+// @Router /v1/attachment/upload [post]
+func (h *AttachmentHandler) UploadAttachment(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.CreateAttachmentDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Failed to parse uploaded file",
+			MessageTH:  "ไม่สามารถแยกไฟล์ที่อัปโหลดได้",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	// บันทึกไฟล์ชั่วคราว (ตั้งชื่อใหม่เพื่อไม่ให้ชนกันเมื่ออัปโหลดพร้อมกัน)
+	tempFilePath := fmt.Sprintf("./temp/%s%s", uuid.NewString(), filepath.Ext(fileHeader.Filename))
+	if err := c.SaveFile(fileHeader, tempFilePath); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusInternalServerError,
+			MessageEN:  "Failed to save uploaded file",
+			MessageTH:  "ไม่สามารถบันทึกไฟล์ที่อัปโหลดได้",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	defer os.Remove(tempFilePath)
+
+	file := dto.AttachmentFile{
+		Path:        tempFilePath,
+		Name:        fileHeader.Filename,
+		ContentType: fileHeader.Header.Get("Content-Type"),
+		Size:        fileHeader.Size,
+	}
+
+	result, err := h.svc.UploadAttachment(c.Context(), req, file, claims)
+	if err != nil {
+		return attachmentError(c, err, "Failed to upload attachment", "อัปโหลดไฟล์แนบไม่สำเร็จ")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusCreated,
+		MessageEN:  "Attachment uploaded successfully",
+		MessageTH:  "อัปโหลดไฟล์แนบเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary List attachments
+// @Description รายการไฟล์แนบของเอกสาร (งานป้าย/task ใส่ include_children=true เพื่อรวมไฟล์ของ task/step ภายใน)
+// @Tags Attachment
+// @Produce json
+// @Param entity_type query string true "sign_job|task|step|receipt|payable|expense"
+// @Param entity_id query string true "Entity ID"
+// @Param task_id query string false "Task ID (required for step)"
+// @Param category query string false "Category"
+// @Param include_children query bool false "Include task/step attachments"
+// @Success 200 {object} dto.BaseResponse{data=[]dto.AttachmentDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/attachment/list [get]
+func (h *AttachmentHandler) ListAttachments(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestListAttachments
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	list, err := h.svc.ListAttachments(c.Context(), req, claims)
+	if err != nil {
+		return attachmentError(c, err, "Failed to list attachments", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       list,
+	})
+}
+
+// @Summary Delete attachment
+// @Description ลบไฟล์แนบ (ผู้อัปโหลดหรือ admin เท่านั้น)
+// @Tags Attachment
+// @Produce json
+// @Param id path string true "Attachment ID"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 500 {object} dto.BaseResponse
+// @Router /v1/attachment/{id} [delete]
+func (h *AttachmentHandler) DeleteAttachment(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.DeleteAttachment(c.Context(), c.Params("id"), claims); err != nil {
+		return attachmentError(c, err, "Failed to delete attachment", "ลบไฟล์แนบไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Attachment deleted successfully",
+		MessageTH:  "ลบไฟล์แนบเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+func attachmentError(c *fiber.Ctx, err error, messageEN, messageTH string) error {
+	switch {
+	case errors.Is(err, ports.ErrAttachmentForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusNotFound,
+			MessageEN:  "Not found",
+			MessageTH:  "ไม่พบข้อมูล",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusBadRequest,
+		MessageEN:  messageEN + ": " + err.Error(),
+		MessageTH:  messageTH,
+		Status:     "error",
+		Data:       nil,
+	})
+}
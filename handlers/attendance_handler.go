@@ -0,0 +1,658 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/middleware"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type AttendanceHandler struct {
+	svc ports.AttendanceService
+	mdw *middleware.Middleware
+}
+
+func NewAttendanceHandler(s ports.AttendanceService, mdw *middleware.Middleware) *AttendanceHandler {
+	return &AttendanceHandler{svc: s, mdw: mdw}
+}
+
+func (h *AttendanceHandler) AttendanceRoutes(router fiber.Router) {
+	versionOne := router.Group("v1")
+	attendance := versionOne.Group("attendance")
+
+	attendance.Get("/site/list", h.mdw.AuthCookieMiddleware(), h.ListAttendanceSites)
+	attendance.Put("/site", h.mdw.AuthCookieMiddleware(), h.UpsertAttendanceSite)
+	attendance.Delete("/site/:id", h.mdw.AuthCookieMiddleware(), h.DeleteAttendanceSite)
+	attendance.Get("/shift/list", h.mdw.AuthCookieMiddleware(), h.ListWorkShifts)
+	attendance.Put("/shift", h.mdw.AuthCookieMiddleware(), h.UpsertWorkShift)
+	attendance.Delete("/shift/:id", h.mdw.AuthCookieMiddleware(), h.DeleteWorkShift)
+	attendance.Post("/check-in", h.mdw.AuthCookieMiddleware(), h.CheckIn)
+	attendance.Post("/check-out", h.mdw.AuthCookieMiddleware(), h.CheckOut)
+	attendance.Get("/me/today", h.mdw.AuthCookieMiddleware(), h.GetMyAttendanceToday)
+	attendance.Get("/list", h.mdw.AuthCookieMiddleware(), h.ListAttendance)
+	attendance.Get("/summary", h.mdw.AuthCookieMiddleware(), h.GetAttendanceSummary)
+	attendance.Post("/correct", h.mdw.AuthCookieMiddleware(), h.CorrectAttendance)
+	attendance.Post("/:id/overtime", h.mdw.AuthCookieMiddleware(), h.DecideOvertime)
+}
+
+// @Summary List attendance sites
+// @Description สถานที่ที่ลงเวลาได้ (หน้าร้าน สำนักงาน หน้างานติดตั้งของใบงาน) พนักงานเห็นเฉพาะที่เปิดใช้งาน
+// @Tags Attendance
+// @Produce json
+// @Success 200 {object} dto.BaseResponse{data=[]dto.AttendanceSiteDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Router /v1/attendance/site/list [get]
+func (h *AttendanceHandler) ListAttendanceSites(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListAttendanceSites(c.Context(), claims)
+	if err != nil {
+		return attendanceError(c, err, "Failed to list attendance sites", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Create or update attendance site
+// @Description admin กำหนดพิกัดและรัศมีของสถานที่ลงเวลา (install ต้องระบุ job_id ใช้ได้จนใบงานเสร็จ/ยกเลิก)
+// @Tags Attendance
+// @Accept json
+// @Produce json
+// @Param body body dto.UpsertAttendanceSiteDTO true "UpsertAttendanceSiteDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.AttendanceSiteDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/attendance/site [put]
+func (h *AttendanceHandler) UpsertAttendanceSite(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.UpsertAttendanceSiteDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.UpsertAttendanceSite(c.Context(), req, claims)
+	if err != nil {
+		return attendanceError(c, err, "Failed to save attendance site", "บันทึกสถานที่ลงเวลาไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Attendance site saved",
+		MessageTH:  "บันทึกสถานที่ลงเวลาเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Delete attendance site
+// @Description admin ลบสถานที่ลงเวลา
+// @Tags Attendance
+// @Produce json
+// @Param id path string true "Attendance Site ID"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/attendance/site/{id} [delete]
+func (h *AttendanceHandler) DeleteAttendanceSite(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.DeleteAttendanceSite(c.Context(), c.Params("id"), claims); err != nil {
+		return attendanceError(c, err, "Failed to delete attendance site", "ลบสถานที่ลงเวลาไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Attendance site deleted",
+		MessageTH:  "ลบสถานที่ลงเวลาเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+// @Summary List work shifts
+// @Description กะการทำงานทั้งหมด (ยังไม่ตั้งกะเริ่มต้นจะแสดงกะมาตรฐาน 08:30-17:30)
+// @Tags Attendance
+// @Produce json
+// @Success 200 {object} dto.BaseResponse{data=[]dto.WorkShiftDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Router /v1/attendance/shift/list [get]
+func (h *AttendanceHandler) ListWorkShifts(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListWorkShifts(c.Context(), claims)
+	if err != nil {
+		return attendanceError(c, err, "Failed to list work shifts", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Create or update work shift
+// @Description admin กำหนดกะ เวลาพัก ช่วงผ่อนผันสาย/ออกก่อน OT ขั้นต่ำ และผู้ใช้กะ (รายคนหรือแผนก)
+// @Tags Attendance
+// @Accept json
+// @Produce json
+// @Param body body dto.UpsertWorkShiftDTO true "UpsertWorkShiftDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.WorkShiftDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/attendance/shift [put]
+func (h *AttendanceHandler) UpsertWorkShift(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.UpsertWorkShiftDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.UpsertWorkShift(c.Context(), req, claims)
+	if err != nil {
+		return attendanceError(c, err, "Failed to save work shift", "บันทึกกะการทำงานไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Work shift saved",
+		MessageTH:  "บันทึกกะการทำงานเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Delete work shift
+// @Description admin ลบกะการทำงาน (พนักงานกลับไปใช้กะของแผนกหรือกะเริ่มต้น รายการที่ลงเวลาแล้วไม่เปลี่ยน)
+// @Tags Attendance
+// @Produce json
+// @Param id path string true "Work Shift ID"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/attendance/shift/{id} [delete]
+func (h *AttendanceHandler) DeleteWorkShift(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.DeleteWorkShift(c.Context(), c.Params("id"), claims); err != nil {
+		return attendanceError(c, err, "Failed to delete work shift", "ลบกะการทำงานไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Work shift deleted",
+		MessageTH:  "ลบกะการทำงานเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+// @Summary Check in
+// @Description ลงเวลาเข้าพร้อมพิกัด GPS ต้องอยู่ในรัศมีของสถานที่ลงเวลา
+// @Tags Attendance
+// @Accept json
+// @Produce json
+// @Param body body dto.AttendancePunchDTO true "AttendancePunchDTO"
+// @Success 201 {object} dto.BaseResponse{data=dto.AttendanceRecordDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/attendance/check-in [post]
+func (h *AttendanceHandler) CheckIn(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.AttendancePunchDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.CheckIn(c.Context(), req, claims)
+	if err != nil {
+		return attendanceError(c, err, "Failed to check in", "ลงเวลาเข้าไม่สำเร็จ")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusCreated,
+		MessageEN:  "Checked in",
+		MessageTH:  "ลงเวลาเข้าเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Check out
+// @Description ลงเวลาออกพร้อมพิกัด GPS คำนวณเวลาทำงาน สาย ออกก่อน และ OT (OT รอผู้จัดการอนุมัติ)
+// @Tags Attendance
+// @Accept json
+// @Produce json
+// @Param body body dto.AttendancePunchDTO true "AttendancePunchDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.AttendanceRecordDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/attendance/check-out [post]
+func (h *AttendanceHandler) CheckOut(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.AttendancePunchDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.CheckOut(c.Context(), req, claims)
+	if err != nil {
+		return attendanceError(c, err, "Failed to check out", "ลงเวลาออกไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Checked out",
+		MessageTH:  "ลงเวลาออกเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary My attendance today
+// @Description กะ การลงเวลา วันหยุด และการลาของวันนี้ สำหรับหน้าลงเวลาบนมือถือ
+// @Tags Attendance
+// @Produce json
+// @Success 200 {object} dto.BaseResponse{data=dto.AttendanceTodayDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/attendance/me/today [get]
+func (h *AttendanceHandler) GetMyAttendanceToday(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.GetMyAttendanceToday(c.Context(), claims)
+	if err != nil {
+		return attendanceError(c, err, "Failed to get attendance", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary List attendance records
+// @Description รายการลงเวลา (พนักงานเห็นของตนเอง ผู้จัดการเห็นแผนกที่ดูแล admin เห็นทั้งหมด)
+// @Tags Attendance
+// @Produce json
+// @Param user_id query string false "User ID"
+// @Param department_id query string false "Department ID"
+// @Param start_date query string false "YYYY-MM-DD"
+// @Param end_date query string false "YYYY-MM-DD"
+// @Param ot_status query string false "pending | approved | rejected"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} dto.BaseResponse{data=dto.Pagination}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Router /v1/attendance/list [get]
+func (h *AttendanceHandler) ListAttendance(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestListAttendance
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListAttendance(c.Context(), req, claims)
+	if err != nil {
+		return attendanceError(c, err, "Failed to list attendance records", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Monthly attendance summary
+// @Description สรุปรายเดือนต่อพนักงาน: วันทำงาน มา ขาด ลา สาย ออกก่อน ชั่วโมงทำงาน OT ที่อนุมัติ (ใช้คิดเงินเดือนและ KPI)
+// @Tags Attendance
+// @Produce json
+// @Param month query string false "YYYY-MM (ค่าเริ่มต้น เดือนนี้)"
+// @Param user_id query string false "User ID"
+// @Param department_id query string false "Department ID"
+// @Success 200 {object} dto.BaseResponse{data=[]dto.AttendanceMonthSummaryDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/attendance/summary [get]
+func (h *AttendanceHandler) GetAttendanceSummary(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestAttendanceSummary
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.GetAttendanceSummary(c.Context(), req, claims)
+	if err != nil {
+		return attendanceError(c, err, "Failed to get attendance summary", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Correct attendance
+// @Description ผู้จัดการแผนก/admin แก้ไขหรือบันทึกเวลาเข้า-ออกแทนพนักงาน ต้องระบุเหตุผล (เก็บประวัติการแก้ไข)
+// @Tags Attendance
+// @Accept json
+// @Produce json
+// @Param body body dto.AttendanceCorrectionDTO true "AttendanceCorrectionDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.AttendanceRecordDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/attendance/correct [post]
+func (h *AttendanceHandler) CorrectAttendance(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.AttendanceCorrectionDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.CorrectAttendance(c.Context(), req, claims)
+	if err != nil {
+		return attendanceError(c, err, "Failed to correct attendance", "แก้ไขเวลาทำงานไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Attendance corrected",
+		MessageTH:  "แก้ไขเวลาทำงานเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Approve or reject overtime
+// @Description ผู้จัดการแผนก/admin อนุมัติ OT (ระบุนาทีที่อนุมัติได้) หรือไม่อนุมัติพร้อมเหตุผล
+// @Tags Attendance
+// @Accept json
+// @Produce json
+// @Param id path string true "Attendance Record ID"
+// @Param body body dto.AttendanceOvertimeDecisionDTO true "AttendanceOvertimeDecisionDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.AttendanceRecordDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/attendance/{id}/overtime [post]
+func (h *AttendanceHandler) DecideOvertime(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.AttendanceOvertimeDecisionDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.DecideOvertime(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return attendanceError(c, err, "Failed to review overtime", "พิจารณา OT ไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Overtime reviewed",
+		MessageTH:  "พิจารณา OT เรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+func attendanceError(c *fiber.Ctx, err error, messageEN, messageTH string) error {
+	switch {
+	case errors.Is(err, ports.ErrAttendanceForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, ports.ErrAttendanceOutsideSite):
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  messageEN + ": " + err.Error(),
+			MessageTH:  "ตำแหน่งของคุณไม่อยู่ในพื้นที่ลงเวลา",
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, ports.ErrAttendanceConflict):
+		return c.Status(fiber.StatusConflict).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusConflict,
+			MessageEN:  messageEN + ": " + err.Error(),
+			MessageTH:  messageTH,
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusNotFound,
+			MessageEN:  "Not found",
+			MessageTH:  "ไม่พบข้อมูล",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusBadRequest,
+		MessageEN:  messageEN + ": " + err.Error(),
+		MessageTH:  messageTH,
+		Status:     "error",
+		Data:       nil,
+	})
+}
@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/middleware"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type BoardHandler struct {
+	svc ports.BoardService
+	mdw *middleware.Middleware
+}
+
+func NewBoardHandler(s ports.BoardService, mdw *middleware.Middleware) *BoardHandler {
+	return &BoardHandler{svc: s, mdw: mdw}
+}
+
+func (h *BoardHandler) BoardRoutes(router fiber.Router) {
+	versionOne := router.Group("v1")
+	board := versionOne.Group("board")
+
+	board.Get("/tasks", h.mdw.AuthCookieMiddleware(), h.GetBoard)
+	board.Put("/move", h.mdw.AuthCookieMiddleware(), h.MoveCard)
+	board.Put("/wip-limit", h.mdw.AuthCookieMiddleware(), h.UpsertWIPLimit)
+	board.Get("/wip-limit/list", h.mdw.AuthCookieMiddleware(), h.ListWIPLimits)
+	board.Get("/calendar", h.mdw.AuthCookieMiddleware(), h.GetCalendar)
+}
+
+// @Summary Task kanban board
+// @Description บอร์ด kanban: งานจัดกลุ่มเป็นคอลัมน์ตามสถานะ ขั้นตอน หรือผู้รับผิดชอบ พร้อมจำนวนและ WIP limit ของแต่ละคอลัมน์
+// @Tags Board
+// @Produce json
+// @Param group_by query string false "status|step|assignee"
+// @Param department_id query string false "Department ID"
+// @Param workflow_id query string false "Workflow ID"
+// @Param assignee query string false "Assignee user ID"
+// @Param search query string false "Search"
+// @Param done_days query int false "Days of done tasks to show (default 14)"
+// @Param card_limit query int false "Max cards per column (default 50)"
+// @Success 200 {object} dto.BaseResponse{data=dto.TaskBoardDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Router /v1/board/tasks [get]
+func (h *BoardHandler) GetBoard(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestTaskBoard
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.GetBoard(c.Context(), req, claims)
+	if err != nil {
+		return boardError(c, err, "Failed to load board", "ไม่สามารถดึงข้อมูลบอร์ดได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Move task card
+// @Description ลากการ์ดไปคอลัมน์อื่น ระบบจะเปลี่ยนสถานะ step หรือผู้รับผิดชอบ step ให้สอดคล้องกับคอลัมน์ปลายทาง
+// @Tags Board
+// @Accept json
+// @Produce json
+// @Param body body dto.BoardMoveDTO true "BoardMoveDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.TaskCardDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/board/move [put]
+func (h *BoardHandler) MoveCard(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.BoardMoveDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.MoveCard(c.Context(), req, claims)
+	if err != nil {
+		return boardError(c, err, "Failed to move task", "ย้ายงานไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Task moved",
+		MessageTH:  "ย้ายงานเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Set column WIP limit
+// @Description ตั้ง WIP limit ของคอลัมน์ (limit=0 คือยกเลิก) admin หรือผู้จัดการแผนกที่ระบุ
+// @Tags Board
+// @Accept json
+// @Produce json
+// @Param body body dto.UpsertBoardWIPLimitDTO true "UpsertBoardWIPLimitDTO"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/board/wip-limit [put]
+func (h *BoardHandler) UpsertWIPLimit(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.UpsertBoardWIPLimitDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.UpsertWIPLimit(c.Context(), req, claims); err != nil {
+		return boardError(c, err, "Failed to set WIP limit", "ตั้งค่า WIP limit ไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "WIP limit saved",
+		MessageTH:  "บันทึก WIP limit เรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+// @Summary List WIP limits
+// @Description รายการ WIP limit ของบอร์ด
+// @Tags Board
+// @Produce json
+// @Param group_by query string false "status|step|assignee"
+// @Param workflow_id query string false "Workflow ID"
+// @Param department_id query string false "Department ID"
+// @Success 200 {object} dto.BaseResponse{data=[]dto.BoardWIPLimitDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Router /v1/board/wip-limit/list [get]
+func (h *BoardHandler) ListWIPLimits(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestListBoardWIPLimits
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListWIPLimits(c.Context(), req, claims)
+	if err != nil {
+		return boardError(c, err, "Failed to list WIP limits", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Task calendar
+// @Description งานและช่วงเวลาของ step ในช่วงวันที่ (มุมมองสัปดาห์/เดือน) พร้อมวันหยุดและจำนวนงานต่อวัน
+// @Tags Board
+// @Produce json
+// @Param from query string false "YYYY-MM-DD (default: first day of this month)"
+// @Param to query string false "YYYY-MM-DD (default: last day of this month)"
+// @Param department_id query string false "Department ID"
+// @Param assignee query string false "Assignee user ID"
+// @Param workflow_id query string false "Workflow ID"
+// @Param include_steps query bool false "Include step events (default true)"
+// @Success 200 {object} dto.BaseResponse{data=dto.TaskCalendarDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Router /v1/board/calendar [get]
+func (h *BoardHandler) GetCalendar(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestTaskCalendar
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.GetCalendar(c.Context(), req, claims)
+	if err != nil {
+		return boardError(c, err, "Failed to load calendar", "ไม่สามารถดึงข้อมูลปฏิทินได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+func boardError(c *fiber.Ctx, err error, messageEN, messageTH string) error {
+	switch {
+	case errors.Is(err, ports.ErrBoardForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, ports.ErrBoardWIPLimit):
+		return c.Status(fiber.StatusConflict).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusConflict,
+			MessageEN:  err.Error(),
+			MessageTH:  "คอลัมน์ปลายทางเต็มตาม WIP limit",
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, ports.ErrStepChecklistIncomplete):
+		return c.Status(fiber.StatusConflict).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusConflict,
+			MessageEN:  err.Error(),
+			MessageTH:  "ยังปิดขั้นตอนนี้ไม่ได้ ติ๊กรายการตรวจสอบยังไม่ครบ",
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusNotFound,
+			MessageEN:  "Not found",
+			MessageTH:  "ไม่พบข้อมูล",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusBadRequest,
+		MessageEN:  messageEN + ": " + err.Error(),
+		MessageTH:  messageTH,
+		Status:     "error",
+		Data:       nil,
+	})
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/middleware"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type CalendarFeedHandler struct {
+	svc ports.CalendarFeedService
+	mdw *middleware.Middleware
+}
+
+func NewCalendarFeedHandler(s ports.CalendarFeedService, mdw *middleware.Middleware) *CalendarFeedHandler {
+	return &CalendarFeedHandler{svc: s, mdw: mdw}
+}
+
+func (h *CalendarFeedHandler) CalendarFeedRoutes(router fiber.Router) {
+	versionOne := router.Group("v1")
+	feed := versionOne.Group("calendar-feed")
+
+	feed.Post("/create", h.mdw.AuthCookieMiddleware(), h.CreateFeed)
+	feed.Get("/list", h.mdw.AuthCookieMiddleware(), h.ListFeeds)
+	feed.Put("/:id/rotate", h.mdw.AuthCookieMiddleware(), h.RotateFeed)
+	feed.Delete("/:id", h.mdw.AuthCookieMiddleware(), h.RevokeFeed)
+	// แอปปฏิทินดึงข้อมูลโดยไม่มี cookie ใช้ token ใน URL แทน
+	feed.Get("/ical/:token", h.GetICal)
+}
+
+// @Summary Create iCal feed link
+// @Description สร้างลิงก์ปฏิทิน iCal ส่วนตัว (token ลับแสดงครั้งเดียว) ระบุ department_id เพื่อดูงานทั้งแผนก (ผู้จัดการแผนกหรือ admin)
+// @Tags CalendarFeed
+// @Accept json
+// @Produce json
+// @Param body body dto.CreateCalendarFeedDTO true "CreateCalendarFeedDTO"
+// @Success 201 {object} dto.BaseResponse{data=dto.CalendarFeedTokenDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/calendar-feed/create [post]
+func (h *CalendarFeedHandler) CreateFeed(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.CreateCalendarFeedDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.CreateFeed(c.Context(), req, claims)
+	if err != nil {
+		return calendarFeedError(c, err, "Failed to create calendar feed", "สร้างลิงก์ปฏิทินไม่สำเร็จ")
+	}
+
+	result.FeedURL = c.BaseURL() + result.FeedURL
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusCreated,
+		MessageEN:  "Calendar feed created",
+		MessageTH:  "สร้างลิงก์ปฏิทินเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary List my iCal feed links
+// @Description รายการลิงก์ปฏิทินของผู้ใช้ (ไม่แสดง token เต็ม)
+// @Tags CalendarFeed
+// @Produce json
+// @Success 200 {object} dto.BaseResponse{data=[]dto.CalendarFeedDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Router /v1/calendar-feed/list [get]
+func (h *CalendarFeedHandler) ListFeeds(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListFeeds(c.Context(), claims)
+	if err != nil {
+		return calendarFeedError(c, err, "Failed to list calendar feeds", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Rotate iCal feed token
+// @Description ออก token ใหม่ ลิงก์เดิมจะใช้ไม่ได้ทันที
+// @Tags CalendarFeed
+// @Produce json
+// @Param id path string true "Feed ID"
+// @Success 200 {object} dto.BaseResponse{data=dto.CalendarFeedTokenDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/calendar-feed/{id}/rotate [put]
+func (h *CalendarFeedHandler) RotateFeed(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.RotateFeed(c.Context(), c.Params("id"), claims)
+	if err != nil {
+		return calendarFeedError(c, err, "Failed to rotate calendar feed", "เปลี่ยนลิงก์ปฏิทินไม่สำเร็จ")
+	}
+
+	result.FeedURL = c.BaseURL() + result.FeedURL
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Calendar feed rotated",
+		MessageTH:  "เปลี่ยนลิงก์ปฏิทินเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Revoke iCal feed link
+// @Description ยกเลิกลิงก์ปฏิทิน (เจ้าของหรือ admin)
+// @Tags CalendarFeed
+// @Produce json
+// @Param id path string true "Feed ID"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/calendar-feed/{id} [delete]
+func (h *CalendarFeedHandler) RevokeFeed(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.RevokeFeed(c.Context(), c.Params("id"), claims); err != nil {
+		return calendarFeedError(c, err, "Failed to revoke calendar feed", "ยกเลิกลิงก์ปฏิทินไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Calendar feed revoked",
+		MessageTH:  "ยกเลิกลิงก์ปฏิทินเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+// @Summary iCal feed
+// @Description ปฏิทิน iCal (RFC 5545) สำหรับ subscribe จาก Google/Outlook/Apple Calendar
+// @Tags CalendarFeed
+// @Produce plain
+// @Param token path string true "Feed token (.ics)"
+// @Success 200 {string} string "text/calendar"
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/calendar-feed/ical/{token} [get]
+func (h *CalendarFeedHandler) GetICal(c *fiber.Ctx) error {
+	body, err := h.svc.RenderFeed(c.Context(), c.Params("token"))
+	if err != nil {
+		return calendarFeedError(c, err, "Failed to load calendar", "ไม่สามารถดึงข้อมูลปฏิทินได้")
+	}
+
+	c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.SendString(body)
+}
+
+func calendarFeedError(c *fiber.Ctx, err error, messageEN, messageTH string) error {
+	switch {
+	case errors.Is(err, ports.ErrCalendarFeedForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusNotFound,
+			MessageEN:  "Not found",
+			MessageTH:  "ไม่พบข้อมูล",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusBadRequest,
+		MessageEN:  messageEN + ": " + err.Error(),
+		MessageTH:  messageTH,
+		Status:     "error",
+		Data:       nil,
+	})
+}
@@ -0,0 +1,442 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/middleware"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type CapacityHandler struct {
+	svc ports.CapacityService
+	mdw *middleware.Middleware
+}
+
+func NewCapacityHandler(s ports.CapacityService, mdw *middleware.Middleware) *CapacityHandler {
+	return &CapacityHandler{svc: s, mdw: mdw}
+}
+
+func (h *CapacityHandler) CapacityRoutes(router fiber.Router) {
+	versionOne := router.Group("v1")
+	capacity := versionOne.Group("capacity")
+
+	capacity.Get("/department/list", h.mdw.AuthCookieMiddleware(), h.ListDepartmentCapacities)
+	capacity.Put("/department", h.mdw.AuthCookieMiddleware(), h.UpsertDepartmentCapacity)
+	capacity.Get("/holiday/list", h.mdw.AuthCookieMiddleware(), h.ListHolidays)
+	capacity.Post("/holiday", h.mdw.AuthCookieMiddleware(), h.CreateHoliday)
+	capacity.Delete("/holiday/:id", h.mdw.AuthCookieMiddleware(), h.DeleteHoliday)
+	capacity.Get("/earliest-completion", h.mdw.AuthCookieMiddleware(), h.EarliestCompletion)
+	capacity.Get("/load", h.mdw.AuthCookieMiddleware(), h.DepartmentLoad)
+}
+
+// @Summary List department capacities
+// @Description กำลังการผลิตของทุกแผนก (ชั่วโมง/คน/วัน วันทำงาน จำนวนคน และชั่วโมงงานค้าง)
+// @Tags Capacity
+// @Produce json
+// @Success 200 {object} dto.BaseResponse{data=[]dto.DepartmentCapacityDTO}
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 500 {object} dto.BaseResponse
+// @Router /v1/capacity/department/list [get]
+func (h *CapacityHandler) ListDepartmentCapacities(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	list, err := h.svc.ListDepartmentCapacities(c.Context(), claims)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusInternalServerError,
+			MessageEN:  "Failed to list capacities: " + err.Error(),
+			MessageTH:  "ไม่สามารถดึงข้อมูลได้",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       list,
+	})
+}
+
+// @Summary Set department capacity
+// @Description ตั้งค่าชั่วโมงทำงานต่อคนต่อวัน วันทำงาน และสัดส่วนเวลาผลิตของแผนก (admin เท่านั้น)
+// @Tags Capacity
+// @Accept json
+// @Produce json
+// @Param body body dto.UpsertDepartmentCapacityDTO true "Department capacity"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/capacity/department [put]
+func (h *CapacityHandler) UpsertDepartmentCapacity(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	if claims.Role != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.UpsertDepartmentCapacityDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.UpsertDepartmentCapacity(c.Context(), req, claims); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+				StatusCode: fiber.StatusNotFound,
+				MessageEN:  "Department not found",
+				MessageTH:  "ไม่พบแผนก",
+				Status:     "error",
+				Data:       nil,
+			})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Failed to save capacity: " + err.Error(),
+			MessageTH:  "บันทึกกำลังการผลิตไม่สำเร็จ",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Capacity saved successfully",
+		MessageTH:  "บันทึกกำลังการผลิตเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+// @Summary List holidays
+// @Description รายการวันหยุดของปี (รวมวันหยุดทั้งบริษัทเมื่อกรองตามแผนก)
+// @Tags Capacity
+// @Produce json
+// @Param year query int false "Year (ค.ศ.)"
+// @Param department_id query string false "Department ID"
+// @Success 200 {object} dto.BaseResponse{data=[]dto.HolidayDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 500 {object} dto.BaseResponse
+// @Router /v1/capacity/holiday/list [get]
+func (h *CapacityHandler) ListHolidays(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestListHolidays
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	list, err := h.svc.ListHolidays(c.Context(), req, claims)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusInternalServerError,
+			MessageEN:  "Failed to list holidays: " + err.Error(),
+			MessageTH:  "ไม่สามารถดึงข้อมูลได้",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       list,
+	})
+}
+
+// @Summary Create holiday
+// @Description เพิ่มวันหยุด (ว่าง department_id = หยุดทั้งบริษัท, admin เท่านั้น)
+// @Tags Capacity
+// @Accept json
+// @Produce json
+// @Param body body dto.CreateHolidayDTO true "Holiday"
+// @Success 201 {object} dto.BaseResponse
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/capacity/holiday [post]
+func (h *CapacityHandler) CreateHoliday(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	if claims.Role != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.CreateHolidayDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.CreateHoliday(c.Context(), req, claims); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Failed to create holiday: " + err.Error(),
+			MessageTH:  "เพิ่มวันหยุดไม่สำเร็จ",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusCreated,
+		MessageEN:  "Holiday created successfully",
+		MessageTH:  "เพิ่มวันหยุดเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+// @Summary Delete holiday
+// @Description ลบวันหยุด (soft delete, admin เท่านั้น)
+// @Tags Capacity
+// @Produce json
+// @Param id path string true "Holiday ID"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 500 {object} dto.BaseResponse
+// @Router /v1/capacity/holiday/{id} [delete]
+func (h *CapacityHandler) DeleteHoliday(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	if claims.Role != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.DeleteHoliday(c.Context(), c.Params("id"), claims); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+				StatusCode: fiber.StatusNotFound,
+				MessageEN:  "Holiday not found",
+				MessageTH:  "ไม่พบวันหยุด",
+				Status:     "error",
+				Data:       nil,
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusInternalServerError,
+			MessageEN:  "Failed to delete holiday: " + err.Error(),
+			MessageTH:  "ลบวันหยุดไม่สำเร็จ",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Holiday deleted successfully",
+		MessageTH:  "ลบวันหยุดเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+// @Summary Earliest feasible completion date
+// @Description คำนวณวันที่เสร็จเร็วที่สุดของงานป้ายใหม่ตามประเภทป้ายและขนาด โดยเทียบกับกำลังการผลิตและงานค้างของแต่ละแผนก
+// @Tags Capacity
+// @Produce json
+// @Param sign_type_id query string true "Sign Type ID"
+// @Param install_option query string false "none|self|shop"
+// @Param width query number false "Width (cm)"
+// @Param height query number false "Height (cm)"
+// @Param quantity query int false "Quantity"
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Success 200 {object} dto.BaseResponse{data=dto.EarliestCompletionDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/capacity/earliest-completion [get]
+func (h *CapacityHandler) EarliestCompletion(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestEarliestCompletion
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.EarliestCompletion(c.Context(), req, claims)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+				StatusCode: fiber.StatusNotFound,
+				MessageEN:  "No workflow mapping for this sign type",
+				MessageTH:  "ไม่พบ workflow ของประเภทป้ายนี้",
+				Status:     "error",
+				Data:       nil,
+			})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Failed to estimate completion date: " + err.Error(),
+			MessageTH:  "คำนวณวันที่เสร็จไม่สำเร็จ",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Department load chart
+// @Description ชั่วโมงงานที่วางแผนไว้เทียบกับกำลังการผลิตรายสัปดาห์ของแต่ละแผนก (ค่าเริ่มต้น 4 สัปดาห์)
+// @Tags Capacity
+// @Produce json
+// @Param department_id query string false "Department ID"
+// @Param weeks query int false "Weeks (default 4)"
+// @Success 200 {object} dto.BaseResponse{data=[]dto.CapacityLoadDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 500 {object} dto.BaseResponse
+// @Router /v1/capacity/load [get]
+func (h *CapacityHandler) DepartmentLoad(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestCapacityLoad
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.DepartmentLoad(c.Context(), req, claims)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusInternalServerError,
+			MessageEN:  "Failed to load capacity chart: " + err.Error(),
+			MessageTH:  "ไม่สามารถดึงข้อมูลได้",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/middleware"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type CommentHandler struct {
+	svc ports.CommentService
+	mdw *middleware.Middleware
+}
+
+func NewCommentHandler(s ports.CommentService, mdw *middleware.Middleware) *CommentHandler {
+	return &CommentHandler{svc: s, mdw: mdw}
+}
+
+func (h *CommentHandler) CommentRoutes(router fiber.Router) {
+	versionOne := router.Group("v1")
+	comment := versionOne.Group("comment")
+
+	comment.Post("/create", h.mdw.AuthCookieMiddleware(), h.CreateComment)
+	comment.Get("/list", h.mdw.AuthCookieMiddleware(), h.ListComments)
+	comment.Get("/feed", h.mdw.AuthCookieMiddleware(), h.ActivityFeed)
+	comment.Put("/:id", h.mdw.AuthCookieMiddleware(), h.UpdateComment)
+	comment.Delete("/:id", h.mdw.AuthCookieMiddleware(), h.DeleteComment)
+}
+
+// @Summary Create comment
+// @Description แสดงความคิดเห็นในงาน (task/step) หรืองานป้าย รองรับการตอบกลับ (parent_id), @mention และแนบไฟล์ที่อัปโหลดไว้แล้ว
+// @Tags Comment
+// @Accept json
+// @Produce json
+// @Param body body dto.CreateCommentDTO true "CreateCommentDTO"
+// @Success 201 {object} dto.BaseResponse{data=dto.CommentDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/comment/create [post]
+func (h *CommentHandler) CreateComment(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.CreateCommentDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.CreateComment(c.Context(), req, claims)
+	if err != nil {
+		return commentError(c, err, "Failed to create comment", "แสดงความคิดเห็นไม่สำเร็จ")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusCreated,
+		MessageEN:  "Comment created",
+		MessageTH:  "แสดงความคิดเห็นเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary List comments
+// @Description รายการความคิดเห็นแบบ thread ของงานหรืองานป้าย
+// @Tags Comment
+// @Produce json
+// @Param entity_type query string true "task|sign_job"
+// @Param entity_id query string true "Task ID or Job ID"
+// @Param step_id query string false "Step ID"
+// @Success 200 {object} dto.BaseResponse{data=[]dto.CommentDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/comment/list [get]
+func (h *CommentHandler) ListComments(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestListComments
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListComments(c.Context(), req, claims)
+	if err != nil {
+		return commentError(c, err, "Failed to list comments", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Activity feed
+// @Description ไทม์ไลน์รวมความคิดเห็นและการเปลี่ยนสถานะ/มอบหมายของงาน (งานป้ายรวมทุก task ในงาน) เรียงใหม่ไปเก่า
+// @Tags Comment
+// @Produce json
+// @Param entity_type query string true "task|sign_job"
+// @Param entity_id query string true "Task ID or Job ID"
+// @Param limit query int false "Limit (default 100)"
+// @Success 200 {object} dto.BaseResponse{data=[]dto.ActivityFeedItemDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/comment/feed [get]
+func (h *CommentHandler) ActivityFeed(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestActivityFeed
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ActivityFeed(c.Context(), req, claims)
+	if err != nil {
+		return commentError(c, err, "Failed to load activity feed", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Update comment
+// @Description แก้ไขความคิดเห็นของตนเอง (เก็บประวัติการแก้ไข)
+// @Tags Comment
+// @Accept json
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Param body body dto.UpdateCommentDTO true "UpdateCommentDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.CommentDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/comment/{id} [put]
+func (h *CommentHandler) UpdateComment(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.UpdateCommentDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.UpdateComment(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return commentError(c, err, "Failed to update comment", "แก้ไขความคิดเห็นไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Comment updated",
+		MessageTH:  "แก้ไขความคิดเห็นเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Delete comment
+// @Description ลบความคิดเห็น (ผู้เขียนหรือ admin) คำตอบใน thread ยังคงอยู่
+// @Tags Comment
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/comment/{id} [delete]
+func (h *CommentHandler) DeleteComment(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.DeleteComment(c.Context(), c.Params("id"), claims); err != nil {
+		return commentError(c, err, "Failed to delete comment", "ลบความคิดเห็นไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Comment deleted",
+		MessageTH:  "ลบความคิดเห็นเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+func commentError(c *fiber.Ctx, err error, messageEN, messageTH string) error {
+	switch {
+	case errors.Is(err, ports.ErrCommentForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusNotFound,
+			MessageEN:  "Not found",
+			MessageTH:  "ไม่พบข้อมูล",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusBadRequest,
+		MessageEN:  messageEN + ": " + err.Error(),
+		MessageTH:  messageTH,
+		Status:     "error",
+		Data:       nil,
+	})
+}
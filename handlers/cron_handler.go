@@ -8,12 +8,26 @@ import (
 
 type CronHandler struct {
 	statusChecker *cron.StatusChecker
+	slaChecker    *cron.SLAChecker
+	recurringRun  *cron.RecurringTaskRunner
+	taskStats     *cron.TaskStatsChecker
+	reviewCycles  *cron.ReviewCycleRunner
+	kpiEvals      *cron.KPIEvaluationRunner
+	employment    *cron.EmploymentHistoryRunner
+	offboarding   *cron.EmployeeChecklistRunner
 	middleware    *middleware.Middleware
 }
 
-func NewCronHandler(statusChecker *cron.StatusChecker, middleware *middleware.Middleware) *CronHandler {
+func NewCronHandler(statusChecker *cron.StatusChecker, slaChecker *cron.SLAChecker, recurringRun *cron.RecurringTaskRunner, taskStats *cron.TaskStatsChecker, reviewCycles *cron.ReviewCycleRunner, kpiEvals *cron.KPIEvaluationRunner, employment *cron.EmploymentHistoryRunner, offboarding *cron.EmployeeChecklistRunner, middleware *middleware.Middleware) *CronHandler {
 	return &CronHandler{
 		statusChecker: statusChecker,
+		slaChecker:    slaChecker,
+		recurringRun:  recurringRun,
+		taskStats:     taskStats,
+		reviewCycles:  reviewCycles,
+		kpiEvals:      kpiEvals,
+		employment:    employment,
+		offboarding:   offboarding,
 		middleware:    middleware,
 	}
 }
@@ -71,6 +85,377 @@ func (h *CronHandler) GetLastRunSummary(c *fiber.Ctx) error {
 	})
 }
 
+// RunSLACheck
+// @Summary รัน cronjob ตรวจสอบ SLA ทันที
+// @Description ตรวจงาน/step ที่ใกล้หรือเกินกำหนดตาม SLA บันทึก breach และแจ้งผู้จัดการแผนกทันที (ไม่ต้องรอรอบรายชั่วโมง)
+// @Tags Cron
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "สำเร็จ พร้อมผลสรุปการตรวจ"
+// @Failure 500 {object} map[string]interface{} "เกิดข้อผิดพลาด"
+// @Router /cron/sla-check [post]
+func (h *CronHandler) RunSLACheck(c *fiber.Ctx) error {
+	summary, err := h.slaChecker.RunNow()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "รัน cronjob ไม่สำเร็จ",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "รัน cronjob สำเร็จ",
+		"data":    summary,
+	})
+}
+
+// GetLastSLARunSummary
+// @Summary ดูผลสรุปการตรวจ SLA ครั้งล่าสุด
+// @Description ดึงข้อมูลผลสรุปการตรวจ SLA ครั้งล่าสุด
+// @Tags Cron
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "สำเร็จ พร้อมผลสรุป"
+// @Router /cron/sla-last-run [get]
+func (h *CronHandler) GetLastSLARunSummary(c *fiber.Ctx) error {
+	summary := h.slaChecker.GetLastRunSummary()
+	if summary == nil {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"message": "ยังไม่มีการรัน cronjob",
+			"data":    nil,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "ดึงข้อมูลสำเร็จ",
+		"data":    summary,
+	})
+}
+
+// RunRecurringTasks
+// @Summary รัน cronjob สร้างงานประจำทันที
+// @Description สร้างงานจากรายการงานประจำที่ถึงรอบทันที (ไม่ต้องรอรอบ 5 นาที)
+// @Tags Cron
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "สำเร็จ พร้อมผลสรุปงานที่สร้าง"
+// @Failure 500 {object} map[string]interface{} "เกิดข้อผิดพลาด"
+// @Router /cron/recurring-run [post]
+func (h *CronHandler) RunRecurringTasks(c *fiber.Ctx) error {
+	summary, err := h.recurringRun.RunNow()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "รัน cronjob ไม่สำเร็จ",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "รัน cronjob สำเร็จ",
+		"data":    summary,
+	})
+}
+
+// GetLastRecurringRunSummary
+// @Summary ดูผลสรุปการสร้างงานประจำครั้งล่าสุด
+// @Description ดึงข้อมูลผลสรุปการสร้างงานประจำครั้งล่าสุด
+// @Tags Cron
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "สำเร็จ พร้อมผลสรุป"
+// @Router /cron/recurring-last-run [get]
+func (h *CronHandler) GetLastRecurringRunSummary(c *fiber.Ctx) error {
+	summary := h.recurringRun.GetLastRunSummary()
+	if summary == nil {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"message": "ยังไม่มีการรัน cronjob",
+			"data":    nil,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "ดึงข้อมูลสำเร็จ",
+		"data":    summary,
+	})
+}
+
+// RunTaskStatsCheck
+// @Summary รัน cronjob ตรวจและแก้สถิติงานทันที
+// @Description นับ user_task_stats ใหม่จากงานจริง รายงานผู้ใช้ที่ตัวเลขไม่ตรงและเขียนค่าใหม่ (ไม่ต้องรอรอบ 02:00 น.)
+// @Tags Cron
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "สำเร็จ พร้อมรายการที่ไม่ตรง"
+// @Failure 500 {object} map[string]interface{} "เกิดข้อผิดพลาด"
+// @Router /cron/task-stats-check [post]
+func (h *CronHandler) RunTaskStatsCheck(c *fiber.Ctx) error {
+	summary, err := h.taskStats.RunNow()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "รัน cronjob ไม่สำเร็จ",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "รัน cronjob สำเร็จ",
+		"data":    summary,
+	})
+}
+
+// GetLastTaskStatsRunSummary
+// @Summary ดูผลสรุปการตรวจสถิติงานครั้งล่าสุด
+// @Description ดึงข้อมูลผลสรุปการตรวจ/แก้ user_task_stats ครั้งล่าสุด
+// @Tags Cron
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "สำเร็จ พร้อมผลสรุป"
+// @Router /cron/task-stats-last-run [get]
+func (h *CronHandler) GetLastTaskStatsRunSummary(c *fiber.Ctx) error {
+	summary := h.taskStats.GetLastRunSummary()
+	if summary == nil {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"message": "ยังไม่มีการรัน cronjob",
+			"data":    nil,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "ดึงข้อมูลสำเร็จ",
+		"data":    summary,
+	})
+}
+
+// RunReviewCycles
+// @Summary รัน cronjob รอบประเมินผลงานทันที
+// @Description เปิดรอบที่ถึงวันเปิด ปิดรอบที่เลยวันปิด และส่งอีเมลเตือนรอบที่ใกล้ปิด (ไม่ต้องรอรอบทุกชั่วโมง)
+// @Tags Cron
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "สำเร็จ พร้อมรอบที่เปิด/ปิด/ส่งเตือน"
+// @Failure 500 {object} map[string]interface{} "เกิดข้อผิดพลาด"
+// @Router /cron/review-cycle-run [post]
+func (h *CronHandler) RunReviewCycles(c *fiber.Ctx) error {
+	summary, err := h.reviewCycles.RunNow()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "รัน cronjob ไม่สำเร็จ",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "รัน cronjob สำเร็จ",
+		"data":    summary,
+	})
+}
+
+// GetLastReviewCycleRunSummary
+// @Summary ดูผลสรุปการตรวจรอบประเมินผลงานครั้งล่าสุด
+// @Description ดึงข้อมูลผลสรุปการเปิด/ปิดรอบประเมินและการส่งเตือนครั้งล่าสุด
+// @Tags Cron
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "สำเร็จ พร้อมผลสรุป"
+// @Router /cron/review-cycle-last-run [get]
+func (h *CronHandler) GetLastReviewCycleRunSummary(c *fiber.Ctx) error {
+	summary := h.reviewCycles.GetLastRunSummary()
+	if summary == nil {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"message": "ยังไม่มีการรัน cronjob",
+			"data":    nil,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "ดึงข้อมูลสำเร็จ",
+		"data":    summary,
+	})
+}
+
+// RunKPIEvaluations
+// @Summary รัน cronjob ติดตามแบบประเมิน KPI ที่ค้างทันที
+// @Description มอบหมายผู้ประเมินให้แบบประเมินที่ยังไม่มีผู้ประเมิน ส่งอีเมลเตือนผู้ประเมิน และปิดแบบประเมินที่ค้างเกินกำหนด
+// @Tags Cron
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "สำเร็จ พร้อมจำนวนที่มอบหมาย/เตือน/ปิด"
+// @Failure 500 {object} map[string]interface{} "เกิดข้อผิดพลาด"
+// @Router /cron/kpi-evaluation-run [post]
+func (h *CronHandler) RunKPIEvaluations(c *fiber.Ctx) error {
+	summary, err := h.kpiEvals.RunNow()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "รัน cronjob ไม่สำเร็จ",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "รัน cronjob สำเร็จ",
+		"data":    summary,
+	})
+}
+
+// GetLastKPIEvaluationRunSummary
+// @Summary ดูผลสรุปการติดตามแบบประเมิน KPI ครั้งล่าสุด
+// @Description ดึงข้อมูลผลสรุปการมอบหมาย ส่งเตือน และปิดแบบประเมินครั้งล่าสุด
+// @Tags Cron
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "สำเร็จ พร้อมผลสรุป"
+// @Router /cron/kpi-evaluation-last-run [get]
+func (h *CronHandler) GetLastKPIEvaluationRunSummary(c *fiber.Ctx) error {
+	summary := h.kpiEvals.GetLastRunSummary()
+	if summary == nil {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"message": "ยังไม่มีการรัน cronjob",
+			"data":    nil,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "ดึงข้อมูลสำเร็จ",
+		"data":    summary,
+	})
+}
+
+// RunEmploymentHistory
+// @Summary รัน cronjob ปรับประวัติการจ้างงานที่ถึงวันมีผลทันที
+// @Description ปรับตำแหน่ง/แผนก/ประเภทการจ้างของพนักงานตามการเปลี่ยนแปลงที่ตั้งวันที่มีผลล่วงหน้าไว้และถึงวันแล้ว
+// @Tags Cron
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "สำเร็จ พร้อมจำนวนที่ปรับ"
+// @Failure 500 {object} map[string]interface{} "เกิดข้อผิดพลาด"
+// @Router /cron/employment-history-run [post]
+func (h *CronHandler) RunEmploymentHistory(c *fiber.Ctx) error {
+	summary, err := h.employment.RunNow()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "รัน cronjob ไม่สำเร็จ",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "รัน cronjob สำเร็จ",
+		"data":    summary,
+	})
+}
+
+// GetLastEmploymentHistoryRunSummary
+// @Summary ดูผลสรุปการปรับประวัติการจ้างงานครั้งล่าสุด
+// @Description ดึงข้อมูลผลสรุปการปรับการเปลี่ยนแปลงที่ถึงวันมีผลครั้งล่าสุด
+// @Tags Cron
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "สำเร็จ พร้อมผลสรุป"
+// @Router /cron/employment-history-last-run [get]
+func (h *CronHandler) GetLastEmploymentHistoryRunSummary(c *fiber.Ctx) error {
+	summary := h.employment.GetLastRunSummary()
+	if summary == nil {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"message": "ยังไม่มีการรัน cronjob",
+			"data":    nil,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "ดึงข้อมูลสำเร็จ",
+		"data":    summary,
+	})
+}
+
+// RunOffboarding
+// @Summary รัน cronjob ยกเลิกสิทธิ์พนักงานที่ offboarding ทันที
+// @Description โอนงานที่ยังค้างและยกเลิกสิทธิ์เข้าระบบของพนักงานที่พ้นวันทำงานวันสุดท้ายแล้ว
+// @Tags Cron
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "สำเร็จ พร้อมจำนวนที่ยกเลิกสิทธิ์"
+// @Failure 500 {object} map[string]interface{} "เกิดข้อผิดพลาด"
+// @Router /cron/offboarding-run [post]
+func (h *CronHandler) RunOffboarding(c *fiber.Ctx) error {
+	summary, err := h.offboarding.RunNow()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "รัน cronjob ไม่สำเร็จ",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "รัน cronjob สำเร็จ",
+		"data":    summary,
+	})
+}
+
+// GetLastOffboardingRunSummary
+// @Summary ดูผลสรุปการยกเลิกสิทธิ์พนักงานที่ offboarding ครั้งล่าสุด
+// @Description ดึงข้อมูลผลสรุปการยกเลิกสิทธิ์พนักงานที่พ้นวันทำงานวันสุดท้ายครั้งล่าสุด
+// @Tags Cron
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "สำเร็จ พร้อมผลสรุป"
+// @Router /cron/offboarding-last-run [get]
+func (h *CronHandler) GetLastOffboardingRunSummary(c *fiber.Ctx) error {
+	summary := h.offboarding.GetLastRunSummary()
+	if summary == nil {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"message": "ยังไม่มีการรัน cronjob",
+			"data":    nil,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "ดึงข้อมูลสำเร็จ",
+		"data":    summary,
+	})
+}
+
 // CronRoutes กำหนด routes สำหรับ Cron
 func (h *CronHandler) CronRoutes(r fiber.Router) {
 	cronGroup := r.Group("/cron")
@@ -78,4 +463,18 @@ func (h *CronHandler) CronRoutes(r fiber.Router) {
 	// ต้อง authenticate ก่อนเรียกใช้ (ใช้เฉพาะ Admin)
 	cronGroup.Post("/status-check", h.middleware.AuthCookieMiddleware(), h.RunStatusCheck)
 	cronGroup.Get("/last-run", h.middleware.AuthCookieMiddleware(), h.GetLastRunSummary)
+	cronGroup.Post("/sla-check", h.middleware.AuthCookieMiddleware(), h.RunSLACheck)
+	cronGroup.Get("/sla-last-run", h.middleware.AuthCookieMiddleware(), h.GetLastSLARunSummary)
+	cronGroup.Post("/recurring-run", h.middleware.AuthCookieMiddleware(), h.RunRecurringTasks)
+	cronGroup.Get("/recurring-last-run", h.middleware.AuthCookieMiddleware(), h.GetLastRecurringRunSummary)
+	cronGroup.Post("/task-stats-check", h.middleware.AuthCookieMiddleware(), h.RunTaskStatsCheck)
+	cronGroup.Get("/task-stats-last-run", h.middleware.AuthCookieMiddleware(), h.GetLastTaskStatsRunSummary)
+	cronGroup.Post("/review-cycle-run", h.middleware.AuthCookieMiddleware(), h.RunReviewCycles)
+	cronGroup.Get("/review-cycle-last-run", h.middleware.AuthCookieMiddleware(), h.GetLastReviewCycleRunSummary)
+	cronGroup.Post("/kpi-evaluation-run", h.middleware.AuthCookieMiddleware(), h.RunKPIEvaluations)
+	cronGroup.Get("/kpi-evaluation-last-run", h.middleware.AuthCookieMiddleware(), h.GetLastKPIEvaluationRunSummary)
+	cronGroup.Post("/employment-history-run", h.middleware.AuthCookieMiddleware(), h.RunEmploymentHistory)
+	cronGroup.Get("/employment-history-last-run", h.middleware.AuthCookieMiddleware(), h.GetLastEmploymentHistoryRunSummary)
+	cronGroup.Post("/offboarding-run", h.middleware.AuthCookieMiddleware(), h.RunOffboarding)
+	cronGroup.Get("/offboarding-last-run", h.middleware.AuthCookieMiddleware(), h.GetLastOffboardingRunSummary)
 }
@@ -0,0 +1,479 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/middleware"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type EmployeeChecklistHandler struct {
+	svc ports.EmployeeChecklistService
+	mdw *middleware.Middleware
+}
+
+func NewEmployeeChecklistHandler(s ports.EmployeeChecklistService, mdw *middleware.Middleware) *EmployeeChecklistHandler {
+	return &EmployeeChecklistHandler{svc: s, mdw: mdw}
+}
+
+func (h *EmployeeChecklistHandler) EmployeeChecklistRoutes(router fiber.Router) {
+	versionOne := router.Group("v1")
+	checklists := versionOne.Group("checklists")
+
+	checklists.Get("/templates", h.mdw.AuthCookieMiddleware(), h.ListChecklistTemplates)
+	checklists.Put("/templates", h.mdw.AuthCookieMiddleware(), h.UpsertChecklistTemplate)
+	checklists.Delete("/templates/:id", h.mdw.AuthCookieMiddleware(), h.DeleteChecklistTemplate)
+	checklists.Get("/list", h.mdw.AuthCookieMiddleware(), h.ListChecklists)
+	checklists.Get("/my-items", h.mdw.AuthCookieMiddleware(), h.ListMyChecklistItems)
+	checklists.Get("/user/:user_id", h.mdw.AuthCookieMiddleware(), h.GetUserChecklists)
+	checklists.Post("/offboarding", h.mdw.AuthCookieMiddleware(), h.StartOffboarding)
+	checklists.Post("/offboarding/:id/cancel", h.mdw.AuthCookieMiddleware(), h.CancelOffboarding)
+	checklists.Put("/:id/items/:item_id", h.mdw.AuthCookieMiddleware(), h.UpdateChecklistItem)
+}
+
+// @Summary List checklist templates
+// @Description แม่แบบ checklist onboarding/offboarding แยกตามตำแหน่ง (admin)
+// @Tags Checklist
+// @Produce json
+// @Param kind query string false "onboarding | offboarding"
+// @Param position_id query string false "Position ID"
+// @Success 200 {object} dto.BaseResponse{data=[]dto.ChecklistTemplateDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/checklists/templates [get]
+func (h *EmployeeChecklistHandler) ListChecklistTemplates(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestListChecklistTemplates
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListChecklistTemplates(c.Context(), req, claims)
+	if err != nil {
+		return employeeChecklistError(c, err, "Failed to list checklist templates", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Create or update checklist template
+// @Description admin กำหนดรายการ onboarding/offboarding ต่อตำแหน่ง (position_id ว่าง = ค่าเริ่มต้นของบริษัท) พร้อมผู้รับผิดชอบ (hr, it, manager) กำหนดเสร็จ และรายการบังคับ
+// @Tags Checklist
+// @Accept json
+// @Produce json
+// @Param body body dto.UpsertChecklistTemplateDTO true "UpsertChecklistTemplateDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.ChecklistTemplateDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/checklists/templates [put]
+func (h *EmployeeChecklistHandler) UpsertChecklistTemplate(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.UpsertChecklistTemplateDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.UpsertChecklistTemplate(c.Context(), req, claims)
+	if err != nil {
+		return employeeChecklistError(c, err, "Failed to save checklist template", "บันทึกแม่แบบ checklist ไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Checklist template saved",
+		MessageTH:  "บันทึกแม่แบบ checklist เรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Delete checklist template
+// @Description ลบแม่แบบ checklist (admin) checklist ของพนักงานที่สร้างไปแล้วไม่เปลี่ยน
+// @Tags Checklist
+// @Produce json
+// @Param id path string true "Template ID"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/checklists/templates/{id} [delete]
+func (h *EmployeeChecklistHandler) DeleteChecklistTemplate(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.DeleteChecklistTemplate(c.Context(), c.Params("id"), claims); err != nil {
+		return employeeChecklistError(c, err, "Failed to delete checklist template", "ลบแม่แบบ checklist ไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Checklist template deleted",
+		MessageTH:  "ลบแม่แบบ checklist เรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+// @Summary List employee checklists
+// @Description รายการ checklist ของพนักงานทั้งหมดแบบแบ่งหน้า (admin)
+// @Tags Checklist
+// @Produce json
+// @Param kind query string false "onboarding | offboarding"
+// @Param status query string false "open | completed"
+// @Param department_id query string false "Department ID"
+// @Param page query int false "Page"
+// @Param limit query int false "Limit"
+// @Success 200 {object} dto.BaseResponse{data=dto.Pagination}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/checklists/list [get]
+func (h *EmployeeChecklistHandler) ListChecklists(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestListChecklists
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListChecklists(c.Context(), req, claims)
+	if err != nil {
+		return employeeChecklistError(c, err, "Failed to list checklists", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary My pending checklist items
+// @Description รายการที่ค้างอยู่กับผู้ใช้ ผู้จัดการเห็นรายการของลูกทีม admin เห็นรายการ HR/IT
+// @Tags Checklist
+// @Produce json
+// @Success 200 {object} dto.BaseResponse{data=[]dto.MyChecklistItemDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Router /v1/checklists/my-items [get]
+func (h *EmployeeChecklistHandler) ListMyChecklistItems(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListMyChecklistItems(c.Context(), claims)
+	if err != nil {
+		return employeeChecklistError(c, err, "Failed to list checklist items", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Employee checklists
+// @Description checklist onboarding/offboarding ของพนักงาน (admin, ผู้จัดการแผนก หรือเจ้าของข้อมูล) รายการที่ตรวจจากข้อมูลจริงจะอัปเดตอัตโนมัติ
+// @Tags Checklist
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {object} dto.BaseResponse{data=[]dto.EmployeeChecklistDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/checklists/user/{user_id} [get]
+func (h *EmployeeChecklistHandler) GetUserChecklists(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.GetUserChecklists(c.Context(), c.Params("user_id"), claims)
+	if err != nil {
+		return employeeChecklistError(c, err, "Failed to get checklists", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Start offboarding
+// @Description admin เริ่ม offboarding: โอนงานที่ยังไม่ปิดให้ผู้รับงาน (ค่าเริ่มต้น ผู้จัดการแผนก) ทันที และยกเลิกสิทธิ์เข้าระบบหลังสิ้นวันทำงานวันสุดท้าย
+// @Tags Checklist
+// @Accept json
+// @Produce json
+// @Param body body dto.StartOffboardingDTO true "StartOffboardingDTO"
+// @Success 201 {object} dto.BaseResponse{data=dto.EmployeeChecklistDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/checklists/offboarding [post]
+func (h *EmployeeChecklistHandler) StartOffboarding(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.StartOffboardingDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.StartOffboarding(c.Context(), req, claims)
+	if err != nil {
+		return employeeChecklistError(c, err, "Failed to start offboarding", "เริ่ม offboarding ไม่สำเร็จ")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusCreated,
+		MessageEN:  "Offboarding started",
+		MessageTH:  "เริ่ม offboarding เรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Cancel offboarding
+// @Description admin ยกเลิก offboarding (ไม่ออกแล้วหรือรับกลับเข้าทำงาน) ล้างการยกเลิกสิทธิ์ ถ้าสถานะเป็น inactive แล้วต้องอนุมัติผู้ใช้ใหม่ งานที่โอนไปแล้วไม่ย้ายกลับ
+// @Tags Checklist
+// @Accept json
+// @Produce json
+// @Param id path string true "Checklist ID"
+// @Param body body dto.CancelOffboardingDTO false "CancelOffboardingDTO"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/checklists/offboarding/{id}/cancel [post]
+func (h *EmployeeChecklistHandler) CancelOffboarding(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.CancelOffboardingDTO
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+				StatusCode: fiber.StatusBadRequest,
+				MessageEN:  "Invalid request payload",
+				MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+				Status:     "error",
+				Data:       nil,
+			})
+		}
+	}
+
+	if err := h.svc.CancelOffboarding(c.Context(), c.Params("id"), req, claims); err != nil {
+		return employeeChecklistError(c, err, "Failed to cancel offboarding", "ยกเลิก offboarding ไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Offboarding cancelled",
+		MessageTH:  "ยกเลิก offboarding เรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+// @Summary Update checklist item
+// @Description ติ๊กหรือเปิดรายการกลับ (admin หรือผู้จัดการที่รับผิดชอบรายการ) รายการที่ตรวจจากข้อมูลจริงติ๊กได้เมื่อข้อมูลครบ
+// @Tags Checklist
+// @Accept json
+// @Produce json
+// @Param id path string true "Checklist ID"
+// @Param item_id path string true "Item ID"
+// @Param body body dto.UpdateChecklistItemDTO true "UpdateChecklistItemDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.EmployeeChecklistDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/checklists/{id}/items/{item_id} [put]
+func (h *EmployeeChecklistHandler) UpdateChecklistItem(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.UpdateChecklistItemDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.UpdateChecklistItem(c.Context(), c.Params("id"), c.Params("item_id"), req, claims)
+	if err != nil {
+		return employeeChecklistError(c, err, "Failed to update checklist item", "อัปเดตรายการไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Checklist item updated",
+		MessageTH:  "อัปเดตรายการเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+func employeeChecklistError(c *fiber.Ctx, err error, messageEN, messageTH string) error {
+	switch {
+	case errors.Is(err, ports.ErrChecklistForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, ports.ErrChecklistConflict), errors.Is(err, ports.ErrChecklistIncomplete):
+		return c.Status(fiber.StatusConflict).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusConflict,
+			MessageEN:  messageEN + ": " + err.Error(),
+			MessageTH:  messageTH,
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusNotFound,
+			MessageEN:  "Not found",
+			MessageTH:  "ไม่พบข้อมูล",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusBadRequest,
+		MessageEN:  messageEN + ": " + err.Error(),
+		MessageTH:  messageTH,
+		Status:     "error",
+		Data:       nil,
+	})
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/middleware"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type EmploymentHistoryHandler struct {
+	svc ports.EmploymentHistoryService
+	mdw *middleware.Middleware
+}
+
+func NewEmploymentHistoryHandler(s ports.EmploymentHistoryService, mdw *middleware.Middleware) *EmploymentHistoryHandler {
+	return &EmploymentHistoryHandler{svc: s, mdw: mdw}
+}
+
+func (h *EmploymentHistoryHandler) EmploymentHistoryRoutes(router fiber.Router) {
+	versionOne := router.Group("v1")
+	employment := versionOne.Group("employment")
+
+	employment.Get("/headcount", h.mdw.AuthCookieMiddleware(), h.GetHeadcountAsOf)
+	employment.Get("/:user_id/timeline", h.mdw.AuthCookieMiddleware(), h.GetEmploymentTimeline)
+	employment.Delete("/:id", h.mdw.AuthCookieMiddleware(), h.CancelScheduledEmploymentChange)
+}
+
+// @Summary Employment timeline
+// @Description ประวัติตำแหน่ง/แผนก/ประเภทการจ้างของพนักงานตามช่วงเวลา รวมการเปลี่ยนแปลงที่ตั้งวันมีผลล่วงหน้า (admin, ผู้จัดการแผนก หรือเจ้าของข้อมูล)
+// @Tags Employment
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {object} dto.BaseResponse{data=dto.EmploymentTimelineDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/employment/{user_id}/timeline [get]
+func (h *EmploymentHistoryHandler) GetEmploymentTimeline(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.GetEmploymentTimeline(c.Context(), c.Params("user_id"), claims)
+	if err != nil {
+		return employmentHistoryError(c, err, "Failed to get employment timeline", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Headcount as of date
+// @Description จำนวนพนักงานแยกตามแผนกและประเภทการจ้าง ณ วันที่ระบุ คำนวณจากประวัติการจ้างงาน (admin)
+// @Tags Employment
+// @Produce json
+// @Param date query string false "YYYY-MM-DD (ค่าเริ่มต้น วันนี้)"
+// @Param department_id query string false "Department ID"
+// @Success 200 {object} dto.BaseResponse{data=dto.EmploymentHeadcountDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/employment/headcount [get]
+func (h *EmploymentHistoryHandler) GetHeadcountAsOf(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestEmploymentHeadcount
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.GetHeadcountAsOf(c.Context(), req, claims)
+	if err != nil {
+		return employmentHistoryError(c, err, "Failed to get headcount", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Cancel scheduled employment change
+// @Description ยกเลิกการเปลี่ยนตำแหน่ง/แผนกที่ตั้งวันมีผลล่วงหน้าไว้และยังไม่มีผล (admin)
+// @Tags Employment
+// @Produce json
+// @Param id path string true "History ID"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/employment/{id} [delete]
+func (h *EmploymentHistoryHandler) CancelScheduledEmploymentChange(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.CancelScheduledEmploymentChange(c.Context(), c.Params("id"), claims); err != nil {
+		return employmentHistoryError(c, err, "Failed to cancel employment change", "ยกเลิกการเปลี่ยนแปลงไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Employment change cancelled",
+		MessageTH:  "ยกเลิกการเปลี่ยนแปลงเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+func employmentHistoryError(c *fiber.Ctx, err error, messageEN, messageTH string) error {
+	switch {
+	case errors.Is(err, ports.ErrEmploymentHistoryForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, ports.ErrEmploymentHistoryConflict):
+		return c.Status(fiber.StatusConflict).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusConflict,
+			MessageEN:  messageEN + ": " + err.Error(),
+			MessageTH:  messageTH,
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusNotFound,
+			MessageEN:  "Not found",
+			MessageTH:  "ไม่พบข้อมูล",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusBadRequest,
+		MessageEN:  messageEN + ": " + err.Error(),
+		MessageTH:  messageTH,
+		Status:     "error",
+		Data:       nil,
+	})
+}
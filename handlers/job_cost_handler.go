@@ -0,0 +1,589 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/middleware"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type JobCostHandler struct {
+	svc ports.JobCostService
+	mdw *middleware.Middleware
+}
+
+func NewJobCostHandler(s ports.JobCostService, mdw *middleware.Middleware) *JobCostHandler {
+	return &JobCostHandler{svc: s, mdw: mdw}
+}
+
+func (h *JobCostHandler) JobCostRoutes(router fiber.Router) {
+	versionOne := router.Group("v1")
+	jobCost := versionOne.Group("job-cost")
+
+	jobCost.Post("/material", h.mdw.AuthCookieMiddleware(), h.CreateJobMaterial)
+	jobCost.Delete("/material/:id", h.mdw.AuthCookieMiddleware(), h.DeleteJobMaterial)
+	jobCost.Get("/labor-rate/list", h.mdw.AuthCookieMiddleware(), h.ListLaborRates)
+	jobCost.Put("/labor-rate", h.mdw.AuthCookieMiddleware(), h.UpsertLaborRate)
+	jobCost.Delete("/labor-rate/:id", h.mdw.AuthCookieMiddleware(), h.DeleteLaborRate)
+	jobCost.Get("/summary/sign-type", h.mdw.AuthCookieMiddleware(), h.SummaryMarginBySignType)
+	jobCost.Get("/summary/customer-type", h.mdw.AuthCookieMiddleware(), h.SummaryMarginByCustomerType)
+	jobCost.Get("/least-profitable", h.mdw.AuthCookieMiddleware(), h.LeastProfitableJobs)
+	jobCost.Get("/:id", h.mdw.AuthCookieMiddleware(), h.GetJobCost)
+}
+
+// @Summary Add material usage to a sign job
+// @Description บันทึกวัสดุที่เบิกใช้ในงานป้าย (ใช้คำนวณต้นทุนงาน)
+// @Tags JobCost
+// @Accept json
+// @Produce json
+// @Param body body dto.CreateJobMaterialDTO true "Material usage"
+// @Success 201 {object} dto.BaseResponse
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 500 {object} dto.BaseResponse
+// @Router /v1/job-cost/material [post]
+func (h *JobCostHandler) CreateJobMaterial(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	if claims.Role != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.CreateJobMaterialDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.CreateJobMaterial(c.Context(), req, claims); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+				StatusCode: fiber.StatusNotFound,
+				MessageEN:  "Sign job not found",
+				MessageTH:  "ไม่พบงานป้าย",
+				Status:     "error",
+				Data:       nil,
+			})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Failed to add material: " + err.Error(),
+			MessageTH:  "บันทึกวัสดุไม่สำเร็จ",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusCreated,
+		MessageEN:  "Material added successfully",
+		MessageTH:  "บันทึกวัสดุเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+// @Summary Delete material usage
+// @Description ลบรายการวัสดุที่บันทึกไว้ (soft delete)
+// @Tags JobCost
+// @Produce json
+// @Param id path string true "Material ID"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 500 {object} dto.BaseResponse
+// @Router /v1/job-cost/material/{id} [delete]
+func (h *JobCostHandler) DeleteJobMaterial(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	if claims.Role != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.DeleteJobMaterial(c.Context(), c.Params("id"), claims); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+				StatusCode: fiber.StatusNotFound,
+				MessageEN:  "Material not found",
+				MessageTH:  "ไม่พบรายการวัสดุ",
+				Status:     "error",
+				Data:       nil,
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusInternalServerError,
+			MessageEN:  "Failed to delete material",
+			MessageTH:  "ลบรายการวัสดุไม่สำเร็จ",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Deleted",
+		MessageTH:  "ลบแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+// @Summary List labor rates
+// @Description รายการอัตราค่าแรงต่อชั่วโมง (รายบุคคล / รายตำแหน่ง)
+// @Tags JobCost
+// @Produce json
+// @Success 200 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 500 {object} dto.BaseResponse
+// @Router /v1/job-cost/labor-rate/list [get]
+func (h *JobCostHandler) ListLaborRates(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	if claims.Role != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	rates, err := h.svc.ListLaborRates(c.Context(), claims)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusInternalServerError,
+			MessageEN:  "Failed to list labor rates",
+			MessageTH:  "ไม่สามารถดึงอัตราค่าแรงได้",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       rates,
+	})
+}
+
+// @Summary Create or update a labor rate
+// @Description กำหนดอัตราค่าแรงต่อชั่วโมง รายบุคคลหรือรายตำแหน่ง (admin เท่านั้น)
+// @Tags JobCost
+// @Accept json
+// @Produce json
+// @Param body body dto.UpsertLaborRateDTO true "Labor rate"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/job-cost/labor-rate [put]
+func (h *JobCostHandler) UpsertLaborRate(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	if claims.Role != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.UpsertLaborRateDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.UpsertLaborRate(c.Context(), req, claims); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Failed to save labor rate: " + err.Error(),
+			MessageTH:  "บันทึกอัตราค่าแรงไม่สำเร็จ",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Labor rate saved successfully",
+		MessageTH:  "บันทึกอัตราค่าแรงเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+// @Summary Delete a labor rate
+// @Description ลบอัตราค่าแรง (admin เท่านั้น)
+// @Tags JobCost
+// @Produce json
+// @Param id path string true "Rate ID"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/job-cost/labor-rate/{id} [delete]
+func (h *JobCostHandler) DeleteLaborRate(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	if claims.Role != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.DeleteLaborRate(c.Context(), c.Params("id"), claims); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+				StatusCode: fiber.StatusNotFound,
+				MessageEN:  "Labor rate not found",
+				MessageTH:  "ไม่พบอัตราค่าแรง",
+				Status:     "error",
+				Data:       nil,
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusInternalServerError,
+			MessageEN:  "Failed to delete labor rate",
+			MessageTH:  "ลบอัตราค่าแรงไม่สำเร็จ",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Deleted",
+		MessageTH:  "ลบแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+// @Summary Get job cost and gross margin
+// @Description ต้นทุนจริงของงานป้าย (วัสดุ + ค่าแรง + รายจ่าย + เจ้าหนี้) เทียบราคาที่เสนอ
+// @Tags JobCost
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} dto.BaseResponse{data=dto.JobCostDTO}
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 500 {object} dto.BaseResponse
+// @Router /v1/job-cost/{id} [get]
+func (h *JobCostHandler) GetJobCost(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	if claims.Role != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.GetJobCost(c.Context(), c.Params("id"), claims)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+				StatusCode: fiber.StatusNotFound,
+				MessageEN:  "Sign job not found",
+				MessageTH:  "ไม่พบงานป้าย",
+				Status:     "error",
+				Data:       nil,
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusInternalServerError,
+			MessageEN:  "Failed to get job cost",
+			MessageTH:  "ไม่สามารถคำนวณต้นทุนงานได้",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Gross margin by sign type
+// @Description สรุปกำไรขั้นต้นแยกตามประเภทป้าย
+// @Tags JobCost
+// @Produce json
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/job-cost/summary/sign-type [get]
+func (h *JobCostHandler) SummaryMarginBySignType(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	if claims.Role != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	var req dto.RequestJobMarginSummary
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.SummaryMarginBySignType(c.Context(), req, claims)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Failed to get margin summary: " + err.Error(),
+			MessageTH:  "ไม่สามารถดึงข้อมูลสรุปกำไรได้",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Gross margin by customer type
+// @Description สรุปกำไรขั้นต้นแยกตามประเภทลูกค้า
+// @Tags JobCost
+// @Produce json
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/job-cost/summary/customer-type [get]
+func (h *JobCostHandler) SummaryMarginByCustomerType(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	if claims.Role != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	var req dto.RequestJobMarginSummary
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.SummaryMarginByCustomerType(c.Context(), req, claims)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Failed to get margin summary: " + err.Error(),
+			MessageTH:  "ไม่สามารถดึงข้อมูลสรุปกำไรได้",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Least profitable jobs of a month
+// @Description รายงานงานป้ายที่อัตรากำไรขั้นต้นต่ำสุดประจำเดือน
+// @Tags JobCost
+// @Produce json
+// @Param month query string false "Month (YYYY-MM)"
+// @Param limit query int false "Number of jobs (default 10)"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/job-cost/least-profitable [get]
+func (h *JobCostHandler) LeastProfitableJobs(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	if claims.Role != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	var req dto.RequestLeastProfitableJobs
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	result, err := h.svc.LeastProfitableJobs(c.Context(), req, claims)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Failed to get report: " + err.Error(),
+			MessageTH:  "ไม่สามารถดึงรายงานได้",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
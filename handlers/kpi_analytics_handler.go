@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/middleware"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/gofiber/fiber/v2"
+)
+
+type KPIAnalyticsHandler struct {
+	svc ports.KPIAnalyticsService
+	mdw *middleware.Middleware
+}
+
+func NewKPIAnalyticsHandler(s ports.KPIAnalyticsService, mdw *middleware.Middleware) *KPIAnalyticsHandler {
+	return &KPIAnalyticsHandler{svc: s, mdw: mdw}
+}
+
+func (h *KPIAnalyticsHandler) KPIAnalyticsRoutes(router fiber.Router) {
+	versionOne := router.Group("v1")
+	analytics := versionOne.Group("kpi-analytics")
+
+	analytics.Get("/trends", h.mdw.AuthCookieMiddleware(), h.KPITrends)
+	analytics.Get("/distribution", h.mdw.AuthCookieMiddleware(), h.KPIDistribution)
+	analytics.Get("/performers", h.mdw.AuthCookieMiddleware(), h.KPIPerformers)
+	analytics.Get("/correlation", h.mdw.AuthCookieMiddleware(), h.KPICorrelation)
+}
+
+// @Summary KPI trends
+// @Description แนวโน้มคะแนน KPI รายเดือน/ไตรมาส แยกตามพนักงาน หมวดหัวข้อ หรือแผนก เทียบค่าเฉลี่ยทั้งบริษัท (พนักงานทั่วไปดูได้เฉพาะของตนเอง)
+// @Tags KPIAnalytics
+// @Produce json
+// @Param start_date query string false "YYYY-MM-DD (ค่าเริ่มต้น 12 เดือนย้อนหลัง)"
+// @Param end_date query string false "YYYY-MM-DD (ค่าเริ่มต้น วันนี้)"
+// @Param department_id query string false "Department ID"
+// @Param user_id query string false "User ID (group_by=employee)"
+// @Param group_by query string false "employee | category | department"
+// @Param interval query string false "month | quarter"
+// @Success 200 {object} dto.BaseResponse{data=dto.KPITrendDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/kpi-analytics/trends [get]
+func (h *KPIAnalyticsHandler) KPITrends(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestKPIAnalytics
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.KPITrends(c.Context(), req, claims)
+	if err != nil {
+		return kpiAnalyticsError(c, err, "Failed to get KPI trends", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary KPI score distribution
+// @Description ฮิสโตแกรมคะแนน KPI เฉลี่ยรายพนักงานในช่วง (admin/ผู้จัดการแผนก)
+// @Tags KPIAnalytics
+// @Produce json
+// @Param start_date query string false "YYYY-MM-DD (ค่าเริ่มต้น 12 เดือนย้อนหลัง)"
+// @Param end_date query string false "YYYY-MM-DD (ค่าเริ่มต้น วันนี้)"
+// @Param department_id query string false "Department ID"
+// @Param bucket_size query int false "ขนาดช่วงคะแนน (ค่าเริ่มต้น 10)"
+// @Success 200 {object} dto.BaseResponse{data=dto.KPIDistributionDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/kpi-analytics/distribution [get]
+func (h *KPIAnalyticsHandler) KPIDistribution(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestKPIAnalytics
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.KPIDistribution(c.Context(), req, claims)
+	if err != nil {
+		return kpiAnalyticsError(c, err, "Failed to get KPI distribution", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Top and bottom performers
+// @Description พนักงานคะแนน KPI สูงสุด/ต่ำสุดในช่วง พร้อมจำนวนงานที่เสร็จและล่าช้า (admin/ผู้จัดการแผนก)
+// @Tags KPIAnalytics
+// @Produce json
+// @Param start_date query string false "YYYY-MM-DD (ค่าเริ่มต้น 12 เดือนย้อนหลัง)"
+// @Param end_date query string false "YYYY-MM-DD (ค่าเริ่มต้น วันนี้)"
+// @Param department_id query string false "Department ID"
+// @Param limit query int false "จำนวนอันดับ (ค่าเริ่มต้น 5)"
+// @Param min_evaluations query int false "จำนวนการประเมินขั้นต่ำ (ค่าเริ่มต้น 1)"
+// @Success 200 {object} dto.BaseResponse{data=dto.KPIPerformersDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/kpi-analytics/performers [get]
+func (h *KPIAnalyticsHandler) KPIPerformers(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestKPIAnalytics
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.KPIPerformers(c.Context(), req, claims)
+	if err != nil {
+		return kpiAnalyticsError(c, err, "Failed to get performers", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary KPI vs throughput correlation
+// @Description สหสัมพันธ์ระหว่างคะแนน KPI กับจำนวนงานที่เสร็จและงานที่ล่าช้ารายพนักงาน (admin/ผู้จัดการแผนก)
+// @Tags KPIAnalytics
+// @Produce json
+// @Param start_date query string false "YYYY-MM-DD (ค่าเริ่มต้น 12 เดือนย้อนหลัง)"
+// @Param end_date query string false "YYYY-MM-DD (ค่าเริ่มต้น วันนี้)"
+// @Param department_id query string false "Department ID"
+// @Param min_evaluations query int false "จำนวนการประเมินขั้นต่ำ (ค่าเริ่มต้น 1)"
+// @Success 200 {object} dto.BaseResponse{data=dto.KPICorrelationDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/kpi-analytics/correlation [get]
+func (h *KPIAnalyticsHandler) KPICorrelation(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestKPIAnalytics
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.KPICorrelation(c.Context(), req, claims)
+	if err != nil {
+		return kpiAnalyticsError(c, err, "Failed to get KPI correlation", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+func kpiAnalyticsError(c *fiber.Ctx, err error, messageEN, messageTH string) error {
+	if errors.Is(err, ports.ErrKPIAnalyticsForbidden) {
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusBadRequest,
+		MessageEN:  messageEN + ": " + err.Error(),
+		MessageTH:  messageTH,
+		Status:     "error",
+		Data:       nil,
+	})
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+
 	"github.com/Be2Bag/erp-demo/dto"
 	"github.com/Be2Bag/erp-demo/middleware"
 	"github.com/Be2Bag/erp-demo/ports"
@@ -23,8 +25,18 @@ func (h *KPIEvaluationHandler) KPIEvaluationRoutes(router fiber.Router) {
 
 	kpiEvaluations.Get("/list", h.mdw.AuthCookieMiddleware(), h.GetKPIEvaluationList)
 	// kpiEvaluations.Post("/create", h.mdw.AuthCookieMiddleware(), h.CreateKPIEvaluation)
+	kpiEvaluations.Post("/recompute", h.mdw.AuthCookieMiddleware(), h.RecomputeKPIEvaluations)
+	kpiEvaluations.Get("/inbox", h.mdw.AuthCookieMiddleware(), h.GetKPIEvaluationInbox)
 	kpiEvaluations.Get("/:id", h.mdw.AuthCookieMiddleware(), h.GetKPIEvaluationByID)
 	kpiEvaluations.Put("/:id", h.mdw.AuthCookieMiddleware(), h.UpdateKPIEvaluation)
+	kpiEvaluations.Get("/:id/history", h.mdw.AuthCookieMiddleware(), h.GetKPIEvaluationHistory)
+	kpiEvaluations.Put("/:id/self", h.mdw.AuthCookieMiddleware(), h.SubmitSelfKPIEvaluation)
+	kpiEvaluations.Post("/:id/peers", h.mdw.AuthCookieMiddleware(), h.InviteKPIPeers)
+	kpiEvaluations.Put("/:id/peer", h.mdw.AuthCookieMiddleware(), h.SubmitPeerKPIEvaluation)
+	kpiEvaluations.Post("/:id/acknowledge", h.mdw.AuthCookieMiddleware(), h.AcknowledgeKPIEvaluation)
+	kpiEvaluations.Post("/:id/dispute", h.mdw.AuthCookieMiddleware(), h.DisputeKPIEvaluation)
+	kpiEvaluations.Post("/:id/resolve", h.mdw.AuthCookieMiddleware(), h.ResolveKPIDispute)
+	kpiEvaluations.Put("/:id/evaluator", h.mdw.AuthCookieMiddleware(), h.AssignKPIEvaluator)
 	// kpiEvaluations.Delete("/:id", h.mdw.AuthCookieMiddleware(), h.DeleteKPIEvaluation)
 
 	// kpiEvaluations.Get("/", h.mdw.AuthCookieMiddleware(), h.GetKPIEvaluations)
@@ -98,7 +110,7 @@ func (h *KPIEvaluationHandler) GetKPIEvaluationList(c *fiber.Ctx) error {
 }
 
 // @Summary Update KPI Evaluation
-// @Description Update an existing KPI evaluation
+// @Description ผู้ประเมินหลัก (ผู้ประเมินที่กำหนด ผู้จัดการแผนก ผู้สร้างงาน หรือ admin) ให้คะแนน ทุกครั้งเก็บเป็นประวัติ
 // @Tags KPI Evaluations
 // @Accept json
 // @Produce json
@@ -131,13 +143,7 @@ func (h *KPIEvaluationHandler) UpdateKPIEvaluation(c *fiber.Ctx) error {
 	}
 
 	if err := h.svc.UpdateKPIEvaluation(c.Context(), evaluationID, req, claims); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(dto.BaseResponse{
-			StatusCode: fiber.StatusInternalServerError,
-			MessageEN:  err.Error(),
-			MessageTH:  "ไม่สามารถอัปเดตการประเมิน KPI ได้",
-			Status:     "error",
-			Data:       nil,
-		})
+		return kpiEvaluationError(c, err, "Failed to update KPI evaluation", "ไม่สามารถอัปเดตการประเมิน KPI ได้")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(dto.BaseResponse{
@@ -209,3 +215,530 @@ func (h *KPIEvaluationHandler) GetKPIEvaluationByID(c *fiber.Ctx) error {
 		Data:       kpiEvaluation,
 	})
 }
+
+func (h *KPIEvaluationHandler) RecomputeKPIEvaluations(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if claims.Role != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestRecomputeKPI
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.RecomputeKPIEvaluations(c.Context(), req.KPIID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusInternalServerError,
+			MessageEN:  err.Error(),
+			MessageTH:  "ไม่สามารถคำนวณคะแนน KPI ใหม่ได้",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "OK",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Submit self evaluation
+// @Description ผู้ถูกประเมินให้คะแนนตนเองตามหัวข้อเดียวกับผู้ประเมินหลัก (ไม่นำไปคิด KPI ส่งซ้ำได้จนกว่าจะรับทราบผล)
+// @Tags KPI Evaluations
+// @Accept json
+// @Produce json
+// @Param id path string true "KPI Evaluation ID"
+// @Param body body dto.UpdateKPIEvaluationRequest true "UpdateKPIEvaluationRequest"
+// @Success 200 {object} dto.BaseResponse{data=dto.KPIEvaluationResponse}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/kpi-evaluations/{id}/self [put]
+func (h *KPIEvaluationHandler) SubmitSelfKPIEvaluation(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.UpdateKPIEvaluationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.SubmitSelfKPIEvaluation(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return kpiEvaluationError(c, err, "Failed to submit self evaluation", "ส่งการประเมินตนเองไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Self evaluation submitted",
+		MessageTH:  "ส่งการประเมินตนเองเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Invite peer reviewers
+// @Description ผู้ประเมินหลัก/admin กำหนดเพื่อนร่วมงานที่ให้ประเมิน (ผู้ที่ส่งคะแนนแล้วจะคงอยู่ในรายชื่อ)
+// @Tags KPI Evaluations
+// @Accept json
+// @Produce json
+// @Param id path string true "KPI Evaluation ID"
+// @Param body body dto.InviteKPIPeersRequest true "InviteKPIPeersRequest"
+// @Success 200 {object} dto.BaseResponse{data=dto.KPIEvaluationResponse}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/kpi-evaluations/{id}/peers [post]
+func (h *KPIEvaluationHandler) InviteKPIPeers(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.InviteKPIPeersRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.InviteKPIPeers(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return kpiEvaluationError(c, err, "Failed to invite peers", "เชิญผู้ประเมินไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Peers invited",
+		MessageTH:  "เชิญผู้ประเมินเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Submit peer evaluation
+// @Description เพื่อนร่วมงานที่ได้รับเชิญให้คะแนน (คนละหนึ่งชุด ผู้ถูกประเมินเห็นเฉพาะค่าเฉลี่ย)
+// @Tags KPI Evaluations
+// @Accept json
+// @Produce json
+// @Param id path string true "KPI Evaluation ID"
+// @Param body body dto.UpdateKPIEvaluationRequest true "UpdateKPIEvaluationRequest"
+// @Success 200 {object} dto.BaseResponse{data=dto.KPIEvaluationResponse}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/kpi-evaluations/{id}/peer [put]
+func (h *KPIEvaluationHandler) SubmitPeerKPIEvaluation(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.UpdateKPIEvaluationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.SubmitPeerKPIEvaluation(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return kpiEvaluationError(c, err, "Failed to submit peer evaluation", "ส่งการประเมินไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Peer evaluation submitted",
+		MessageTH:  "ส่งการประเมินเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Acknowledge evaluation result
+// @Description ผู้ถูกประเมินรับทราบผลประเมิน
+// @Tags KPI Evaluations
+// @Accept json
+// @Produce json
+// @Param id path string true "KPI Evaluation ID"
+// @Param body body dto.AcknowledgeKPIEvaluationRequest true "AcknowledgeKPIEvaluationRequest"
+// @Success 200 {object} dto.BaseResponse{data=dto.KPIEvaluationResponse}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/kpi-evaluations/{id}/acknowledge [post]
+func (h *KPIEvaluationHandler) AcknowledgeKPIEvaluation(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.AcknowledgeKPIEvaluationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.AcknowledgeKPIEvaluation(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return kpiEvaluationError(c, err, "Failed to acknowledge evaluation", "รับทราบผลประเมินไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Evaluation acknowledged",
+		MessageTH:  "รับทราบผลประเมินเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Dispute evaluation result
+// @Description ผู้ถูกประเมินโต้แย้งคะแนนพร้อมเหตุผล ระหว่างโต้แย้งแก้คะแนนได้ผ่านการพิจารณาข้อโต้แย้งเท่านั้น
+// @Tags KPI Evaluations
+// @Accept json
+// @Produce json
+// @Param id path string true "KPI Evaluation ID"
+// @Param body body dto.DisputeKPIEvaluationRequest true "DisputeKPIEvaluationRequest"
+// @Success 200 {object} dto.BaseResponse{data=dto.KPIEvaluationResponse}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/kpi-evaluations/{id}/dispute [post]
+func (h *KPIEvaluationHandler) DisputeKPIEvaluation(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.DisputeKPIEvaluationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.DisputeKPIEvaluation(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return kpiEvaluationError(c, err, "Failed to dispute evaluation", "โต้แย้งผลประเมินไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Dispute submitted",
+		MessageTH:  "ส่งข้อโต้แย้งเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Resolve evaluation dispute
+// @Description ผู้จัดการแผนก/HR พิจารณาข้อโต้แย้ง upheld = คงคะแนน, revised = แก้คะแนน (เก็บประวัติและคำนวณ KPI ใหม่)
+// @Tags KPI Evaluations
+// @Accept json
+// @Produce json
+// @Param id path string true "KPI Evaluation ID"
+// @Param body body dto.ResolveKPIDisputeRequest true "ResolveKPIDisputeRequest"
+// @Success 200 {object} dto.BaseResponse{data=dto.KPIEvaluationResponse}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/kpi-evaluations/{id}/resolve [post]
+func (h *KPIEvaluationHandler) ResolveKPIDispute(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.ResolveKPIDisputeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ResolveKPIDispute(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return kpiEvaluationError(c, err, "Failed to resolve dispute", "พิจารณาข้อโต้แย้งไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Dispute resolved",
+		MessageTH:  "พิจารณาข้อโต้แย้งเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary KPI evaluation score history
+// @Description ประวัติคะแนนทุกครั้งที่ส่ง/แก้ไข ล่าสุดก่อน (ผู้ถูกประเมินไม่เห็นคะแนนรายคนของเพื่อนร่วมงาน)
+// @Tags KPI Evaluations
+// @Produce json
+// @Param id path string true "KPI Evaluation ID"
+// @Success 200 {object} dto.BaseResponse{data=[]dto.KPIRevisionResponse}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/kpi-evaluations/{id}/history [get]
+func (h *KPIEvaluationHandler) GetKPIEvaluationHistory(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.GetKPIEvaluationHistory(c.Context(), c.Params("id"), claims)
+	if err != nil {
+		return kpiEvaluationError(c, err, "Failed to get evaluation history", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary KPI evaluations waiting for me
+// @Description แบบประเมินที่รอผู้ใช้ดำเนินการ: ให้คะแนนในฐานะผู้ประเมินหลัก, ประเมินในฐานะเพื่อนร่วมงาน, พิจารณาข้อโต้แย้ง (เรียงจากค้างนานสุด)
+// @Tags KPI Evaluations
+// @Produce json
+// @Success 200 {object} dto.BaseResponse{data=[]dto.KPIInboxItemDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/kpi-evaluations/inbox [get]
+func (h *KPIEvaluationHandler) GetKPIEvaluationInbox(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListKPIEvaluationInbox(c.Context(), claims)
+	if err != nil {
+		return kpiEvaluationError(c, err, "Failed to get evaluation inbox", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Assign KPI evaluator
+// @Description admin/ผู้จัดการแผนกกำหนดผู้ประเมินหลัก (ไม่ระบุ = เลือกใหม่ตามกฎของ KPI template) แบบประเมินที่ถูกปิดอัตโนมัติจะถูกเปิดใหม่
+// @Tags KPI Evaluations
+// @Accept json
+// @Produce json
+// @Param id path string true "KPI Evaluation ID"
+// @Param body body dto.AssignKPIEvaluatorRequest true "AssignKPIEvaluatorRequest"
+// @Success 200 {object} dto.BaseResponse{data=dto.KPIEvaluationResponse}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/kpi-evaluations/{id}/evaluator [put]
+func (h *KPIEvaluationHandler) AssignKPIEvaluator(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.AssignKPIEvaluatorRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.AssignKPIEvaluator(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return kpiEvaluationError(c, err, "Failed to assign evaluator", "กำหนดผู้ประเมินไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Evaluator assigned",
+		MessageTH:  "กำหนดผู้ประเมินเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+func kpiEvaluationError(c *fiber.Ctx, err error, messageEN, messageTH string) error {
+	switch {
+	case errors.Is(err, ports.ErrKPIEvaluationForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, ports.ErrKPIEvaluationDisputed):
+		return c.Status(fiber.StatusConflict).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusConflict,
+			MessageEN:  messageEN + ": " + err.Error(),
+			MessageTH:  "มีข้อโต้แย้งค้างอยู่ กรุณาพิจารณาข้อโต้แย้งก่อน",
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, ports.ErrKPIEvaluationConflict):
+		return c.Status(fiber.StatusConflict).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusConflict,
+			MessageEN:  messageEN + ": " + err.Error(),
+			MessageTH:  messageTH,
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusNotFound,
+			MessageEN:  "Not found",
+			MessageTH:  "ไม่พบข้อมูล",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusBadRequest,
+		MessageEN:  messageEN + ": " + err.Error(),
+		MessageTH:  messageTH,
+		Status:     "error",
+		Data:       nil,
+	})
+}
@@ -0,0 +1,668 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/middleware"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type LeaveHandler struct {
+	svc ports.LeaveService
+	mdw *middleware.Middleware
+}
+
+func NewLeaveHandler(s ports.LeaveService, mdw *middleware.Middleware) *LeaveHandler {
+	return &LeaveHandler{svc: s, mdw: mdw}
+}
+
+func (h *LeaveHandler) LeaveRoutes(router fiber.Router) {
+	versionOne := router.Group("v1")
+	leaves := versionOne.Group("leaves")
+
+	leaves.Get("/policy/list", h.mdw.AuthCookieMiddleware(), h.ListLeavePolicies)
+	leaves.Put("/policy", h.mdw.AuthCookieMiddleware(), h.UpsertLeavePolicy)
+	leaves.Delete("/policy/:id", h.mdw.AuthCookieMiddleware(), h.DeleteLeavePolicy)
+	leaves.Get("/balance", h.mdw.AuthCookieMiddleware(), h.GetLeaveBalances)
+	leaves.Post("/request", h.mdw.AuthCookieMiddleware(), h.CreateLeaveRequest)
+	leaves.Get("/list", h.mdw.AuthCookieMiddleware(), h.ListLeaveRequests)
+	leaves.Get("/approvals", h.mdw.AuthCookieMiddleware(), h.ListLeaveApprovals)
+	leaves.Get("/calendar", h.mdw.AuthCookieMiddleware(), h.GetLeaveCalendar)
+	leaves.Get("/:id", h.mdw.AuthCookieMiddleware(), h.GetLeaveRequest)
+	leaves.Post("/:id/approve", h.mdw.AuthCookieMiddleware(), h.ApproveLeaveRequest)
+	leaves.Post("/:id/reject", h.mdw.AuthCookieMiddleware(), h.RejectLeaveRequest)
+	leaves.Post("/:id/record", h.mdw.AuthCookieMiddleware(), h.RecordLeaveRequest)
+	leaves.Post("/:id/cancel", h.mdw.AuthCookieMiddleware(), h.CancelLeaveRequest)
+}
+
+// @Summary List leave policies
+// @Description นโยบายสิทธิ์การลาทุกประเภท (ประเภทที่ยังไม่ได้ตั้งแสดงค่าเริ่มต้นตามกฎหมายแรงงาน is_default=true)
+// @Tags Leaves
+// @Produce json
+// @Success 200 {object} dto.BaseResponse{data=[]dto.LeavePolicyDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Router /v1/leaves/policy/list [get]
+func (h *LeaveHandler) ListLeavePolicies(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListLeavePolicies(c.Context(), claims)
+	if err != nil {
+		return leaveError(c, err, "Failed to list leave policies", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Create or update leave policy
+// @Description HR ตั้งสิทธิ์การลาต่อปีตามอายุงาน การยกยอด และลาครึ่งวัน ต่อประเภทการลา (ระบุ position_id = เฉพาะตำแหน่ง)
+// @Tags Leaves
+// @Accept json
+// @Produce json
+// @Param body body dto.UpsertLeavePolicyDTO true "UpsertLeavePolicyDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.LeavePolicyDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/leaves/policy [put]
+func (h *LeaveHandler) UpsertLeavePolicy(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.UpsertLeavePolicyDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.UpsertLeavePolicy(c.Context(), req, claims)
+	if err != nil {
+		return leaveError(c, err, "Failed to save leave policy", "บันทึกนโยบายการลาไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Leave policy saved",
+		MessageTH:  "บันทึกนโยบายการลาเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Delete leave policy
+// @Description HR ลบนโยบายการลา (ประเภทนั้นกลับไปใช้นโยบายทุกตำแหน่งหรือค่าเริ่มต้นตามกฎหมาย)
+// @Tags Leaves
+// @Produce json
+// @Param id path string true "Leave Policy ID"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/leaves/policy/{id} [delete]
+func (h *LeaveHandler) DeleteLeavePolicy(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.DeleteLeavePolicy(c.Context(), c.Params("id"), claims); err != nil {
+		return leaveError(c, err, "Failed to delete leave policy", "ลบนโยบายการลาไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Leave policy deleted",
+		MessageTH:  "ลบนโยบายการลาเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+// @Summary Leave balances
+// @Description สิทธิ์ลาคงเหลือของปีแยกตามประเภท รวมยอดยกมาและวันที่รออนุมัติ (ผู้จัดการ/HR ดูของพนักงานได้)
+// @Tags Leaves
+// @Produce json
+// @Param user_id query string false "User ID (ว่าง = ตนเอง)"
+// @Param year query int false "ปี (ค่าเริ่มต้น ปีปัจจุบัน)"
+// @Success 200 {object} dto.BaseResponse{data=dto.LeaveBalanceSummaryDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/leaves/balance [get]
+func (h *LeaveHandler) GetLeaveBalances(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestLeaveBalance
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.GetLeaveBalances(c.Context(), req, claims)
+	if err != nil {
+		return leaveError(c, err, "Failed to get leave balances", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Create leave request
+// @Description ยื่นใบลา (ไม่นับวันหยุดประจำสัปดาห์และวันหยุดบริษัท ลาครึ่งวันได้เฉพาะวันเดียว) ส่งให้ผู้จัดการแผนกอนุมัติ
+// @Tags Leaves
+// @Accept json
+// @Produce json
+// @Param body body dto.CreateLeaveRequestDTO true "CreateLeaveRequestDTO"
+// @Success 201 {object} dto.BaseResponse{data=dto.LeaveRequestDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/leaves/request [post]
+func (h *LeaveHandler) CreateLeaveRequest(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.CreateLeaveRequestDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.CreateLeaveRequest(c.Context(), req, claims)
+	if err != nil {
+		return leaveError(c, err, "Failed to create leave request", "ยื่นใบลาไม่สำเร็จ")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusCreated,
+		MessageEN:  "Leave request submitted",
+		MessageTH:  "ยื่นใบลาเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary List leave requests
+// @Description รายการใบลา (พนักงานเห็นของตนเอง ผู้จัดการเห็นแผนกที่ดูแล HR เห็นทั้งหมด)
+// @Tags Leaves
+// @Produce json
+// @Param user_id query string false "User ID"
+// @Param department_id query string false "Department ID"
+// @Param leave_type query string false "sick | personal | annual | maternity | ordination | military"
+// @Param status query string false "pending | approved | recorded | rejected | cancelled"
+// @Param year query int false "ปีของสิทธิ์"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} dto.BaseResponse{data=dto.Pagination}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Router /v1/leaves/list [get]
+func (h *LeaveHandler) ListLeaveRequests(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestListLeaveRequests
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListLeaveRequests(c.Context(), req, claims)
+	if err != nil {
+		return leaveError(c, err, "Failed to list leave requests", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Leave requests waiting for me
+// @Description ใบลาที่รอผู้จัดการอนุมัติ และ (HR) ใบลาที่ไม่มีผู้จัดการ/อนุมัติแล้วรอบันทึก
+// @Tags Leaves
+// @Produce json
+// @Success 200 {object} dto.BaseResponse{data=[]dto.LeaveRequestDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Router /v1/leaves/approvals [get]
+func (h *LeaveHandler) ListLeaveApprovals(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListLeaveApprovals(c.Context(), claims)
+	if err != nil {
+		return leaveError(c, err, "Failed to list leave approvals", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Team leave calendar
+// @Description ปฏิทินการลาของทีมรายวันพร้อมวันหยุด (on_leave นับเฉพาะใบลาที่อนุมัติแล้ว)
+// @Tags Leaves
+// @Produce json
+// @Param department_id query string false "Department ID (ว่าง = แผนกของตนเอง)"
+// @Param start_date query string false "YYYY-MM-DD (ค่าเริ่มต้น ต้นเดือนนี้)"
+// @Param end_date query string false "YYYY-MM-DD (ค่าเริ่มต้น สิ้นเดือน)"
+// @Success 200 {object} dto.BaseResponse{data=dto.LeaveCalendarDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/leaves/calendar [get]
+func (h *LeaveHandler) GetLeaveCalendar(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestLeaveCalendar
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.GetLeaveCalendar(c.Context(), req, claims)
+	if err != nil {
+		return leaveError(c, err, "Failed to get leave calendar", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Get leave request
+// @Description รายละเอียดใบลา
+// @Tags Leaves
+// @Produce json
+// @Param id path string true "Leave Request ID"
+// @Success 200 {object} dto.BaseResponse{data=dto.LeaveRequestDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/leaves/{id} [get]
+func (h *LeaveHandler) GetLeaveRequest(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.GetLeaveRequest(c.Context(), c.Params("id"), claims)
+	if err != nil {
+		return leaveError(c, err, "Failed to get leave request", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Approve leave request
+// @Description ผู้จัดการแผนก/HR อนุมัติใบลา (ตรวจสิทธิ์คงเหลืออีกครั้ง) ใบลาที่อนุมัติแล้วลดกำลังการผลิตของแผนก
+// @Tags Leaves
+// @Accept json
+// @Produce json
+// @Param id path string true "Leave Request ID"
+// @Param body body dto.LeaveDecisionDTO true "LeaveDecisionDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.LeaveRequestDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/leaves/{id}/approve [post]
+func (h *LeaveHandler) ApproveLeaveRequest(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.LeaveDecisionDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ApproveLeaveRequest(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return leaveError(c, err, "Failed to approve leave request", "อนุมัติใบลาไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Leave request approved",
+		MessageTH:  "อนุมัติใบลาเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Reject leave request
+// @Description ผู้จัดการแผนก/HR ไม่อนุมัติใบลา (ต้องระบุเหตุผล)
+// @Tags Leaves
+// @Accept json
+// @Produce json
+// @Param id path string true "Leave Request ID"
+// @Param body body dto.LeaveDecisionDTO true "LeaveDecisionDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.LeaveRequestDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/leaves/{id}/reject [post]
+func (h *LeaveHandler) RejectLeaveRequest(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.LeaveDecisionDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.RejectLeaveRequest(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return leaveError(c, err, "Failed to reject leave request", "ไม่อนุมัติใบลาไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Leave request rejected",
+		MessageTH:  "ไม่อนุมัติใบลาเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Record leave request
+// @Description HR บันทึกใบลาที่อนุมัติแล้วเข้าระบบบุคคล
+// @Tags Leaves
+// @Accept json
+// @Produce json
+// @Param id path string true "Leave Request ID"
+// @Param body body dto.LeaveDecisionDTO true "LeaveDecisionDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.LeaveRequestDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/leaves/{id}/record [post]
+func (h *LeaveHandler) RecordLeaveRequest(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.LeaveDecisionDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.RecordLeaveRequest(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return leaveError(c, err, "Failed to record leave request", "บันทึกใบลาไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Leave request recorded",
+		MessageTH:  "บันทึกใบลาเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Cancel leave request
+// @Description ผู้ลายกเลิกได้ก่อนวันเริ่มลา HR ยกเลิกใบลาที่ยังมีผลได้ทุกใบ (คืนสิทธิ์และกำลังการผลิต)
+// @Tags Leaves
+// @Accept json
+// @Produce json
+// @Param id path string true "Leave Request ID"
+// @Param body body dto.LeaveDecisionDTO true "LeaveDecisionDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.LeaveRequestDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/leaves/{id}/cancel [post]
+func (h *LeaveHandler) CancelLeaveRequest(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.LeaveDecisionDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.CancelLeaveRequest(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return leaveError(c, err, "Failed to cancel leave request", "ยกเลิกใบลาไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Leave request cancelled",
+		MessageTH:  "ยกเลิกใบลาเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+func leaveError(c *fiber.Ctx, err error, messageEN, messageTH string) error {
+	switch {
+	case errors.Is(err, ports.ErrLeaveForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, ports.ErrLeaveConflict):
+		return c.Status(fiber.StatusConflict).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusConflict,
+			MessageEN:  messageEN + ": " + err.Error(),
+			MessageTH:  messageTH,
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusNotFound,
+			MessageEN:  "Not found",
+			MessageTH:  "ไม่พบข้อมูล",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusBadRequest,
+		MessageEN:  messageEN + ": " + err.Error(),
+		MessageTH:  messageTH,
+		Status:     "error",
+		Data:       nil,
+	})
+}
@@ -0,0 +1,688 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/middleware"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type PayrollHandler struct {
+	svc ports.PayrollService
+	mdw *middleware.Middleware
+}
+
+func NewPayrollHandler(s ports.PayrollService, mdw *middleware.Middleware) *PayrollHandler {
+	return &PayrollHandler{svc: s, mdw: mdw}
+}
+
+func (h *PayrollHandler) PayrollRoutes(router fiber.Router) {
+	versionOne := router.Group("v1")
+	payroll := versionOne.Group("payroll")
+
+	payroll.Get("/salary/list", h.mdw.AuthCookieMiddleware(), h.ListSalaryStructures)
+	payroll.Put("/salary", h.mdw.AuthCookieMiddleware(), h.UpsertSalaryStructure)
+	payroll.Get("/salary/:user_id", h.mdw.AuthCookieMiddleware(), h.GetSalaryStructure)
+	payroll.Post("/run", h.mdw.AuthCookieMiddleware(), h.CreatePayrollRun)
+	payroll.Get("/run/list", h.mdw.AuthCookieMiddleware(), h.ListPayrollRuns)
+	payroll.Get("/run/:id", h.mdw.AuthCookieMiddleware(), h.GetPayrollRun)
+	payroll.Post("/run/:id/recalculate", h.mdw.AuthCookieMiddleware(), h.RecalculatePayrollRun)
+	payroll.Post("/run/:id/adjustment", h.mdw.AuthCookieMiddleware(), h.AddPayrollAdjustment)
+	payroll.Post("/run/:id/approve", h.mdw.AuthCookieMiddleware(), h.ApprovePayrollRun)
+	payroll.Post("/run/:id/pay", h.mdw.AuthCookieMiddleware(), h.PayPayrollRun)
+	payroll.Post("/run/:id/cancel", h.mdw.AuthCookieMiddleware(), h.CancelPayrollRun)
+	payroll.Get("/run/:id/bank-file", h.mdw.AuthCookieMiddleware(), h.DownloadPayrollBankFile)
+	payroll.Get("/payslip/me", h.mdw.AuthCookieMiddleware(), h.ListMyPayslips)
+	payroll.Get("/payslip/:id/pdf", h.mdw.AuthCookieMiddleware(), h.DownloadPayslipPDF)
+}
+
+// @Summary List salary structures
+// @Description admin ดูโครงสร้างเงินเดือนของพนักงานทั้งหมด
+// @Tags Payroll
+// @Produce json
+// @Success 200 {object} dto.BaseResponse{data=[]dto.SalaryStructureDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/payroll/salary/list [get]
+func (h *PayrollHandler) ListSalaryStructures(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListSalaryStructures(c.Context(), claims)
+	if err != nil {
+		return payrollError(c, err, "Failed to list salary structures", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Get salary structure
+// @Description โครงสร้างเงินเดือนของพนักงาน (พนักงานดูได้เฉพาะของตนเอง)
+// @Tags Payroll
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {object} dto.BaseResponse{data=dto.SalaryStructureDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/payroll/salary/{user_id} [get]
+func (h *PayrollHandler) GetSalaryStructure(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.GetSalaryStructure(c.Context(), c.Params("user_id"), claims)
+	if err != nil {
+		return payrollError(c, err, "Failed to get salary structure", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Create or update salary structure
+// @Description admin กำหนดฐานเงินเดือน (รายเดือน/รายวัน) อัตรา OT เงินเพิ่ม รายการหัก ประกันสังคม และค่าลดหย่อนภาษี
+// @Tags Payroll
+// @Accept json
+// @Produce json
+// @Param body body dto.UpsertSalaryStructureDTO true "UpsertSalaryStructureDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.SalaryStructureDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/payroll/salary [put]
+func (h *PayrollHandler) UpsertSalaryStructure(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.UpsertSalaryStructureDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.UpsertSalaryStructure(c.Context(), req, claims)
+	if err != nil {
+		return payrollError(c, err, "Failed to save salary structure", "บันทึกโครงสร้างเงินเดือนไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Salary structure saved",
+		MessageTH:  "บันทึกโครงสร้างเงินเดือนเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Create payroll run
+// @Description admin สร้างรอบเงินเดือนประจำเดือน (draft) คำนวณจากโครงสร้างเงินเดือน สรุปการลงเวลา OT ที่อนุมัติ ประกันสังคม และภาษีหัก ณ ที่จ่าย (ภ.ง.ด.1)
+// @Tags Payroll
+// @Accept json
+// @Produce json
+// @Param body body dto.CreatePayrollRunDTO true "CreatePayrollRunDTO"
+// @Success 201 {object} dto.BaseResponse{data=dto.PayrollRunDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/payroll/run [post]
+func (h *PayrollHandler) CreatePayrollRun(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.CreatePayrollRunDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.CreatePayrollRun(c.Context(), req, claims)
+	if err != nil {
+		return payrollError(c, err, "Failed to create payroll run", "สร้างรอบเงินเดือนไม่สำเร็จ")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusCreated,
+		MessageEN:  "Payroll run created",
+		MessageTH:  "สร้างรอบเงินเดือนเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary List payroll runs
+// @Description รายการรอบเงินเดือน (admin)
+// @Tags Payroll
+// @Produce json
+// @Param year query int false "Year"
+// @Param status query string false "draft | approved | paid | cancelled"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} dto.BaseResponse{data=dto.Pagination}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/payroll/run/list [get]
+func (h *PayrollHandler) ListPayrollRuns(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestListPayrollRuns
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListPayrollRuns(c.Context(), req, claims)
+	if err != nil {
+		return payrollError(c, err, "Failed to list payroll runs", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Get payroll run
+// @Description รายละเอียดรอบเงินเดือนพร้อมสลิปของพนักงานทุกคน
+// @Tags Payroll
+// @Produce json
+// @Param id path string true "Payroll Run ID"
+// @Success 200 {object} dto.BaseResponse{data=dto.PayrollRunDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/payroll/run/{id} [get]
+func (h *PayrollHandler) GetPayrollRun(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.GetPayrollRun(c.Context(), c.Params("id"), claims)
+	if err != nil {
+		return payrollError(c, err, "Failed to get payroll run", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Recalculate payroll run
+// @Description คำนวณรอบเงินเดือน (draft) ใหม่จากข้อมูลล่าสุด รายการปรับที่เพิ่มไว้ยังคงอยู่
+// @Tags Payroll
+// @Produce json
+// @Param id path string true "Payroll Run ID"
+// @Success 200 {object} dto.BaseResponse{data=dto.PayrollRunDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/payroll/run/{id}/recalculate [post]
+func (h *PayrollHandler) RecalculatePayrollRun(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.RecalculatePayrollRun(c.Context(), c.Params("id"), claims)
+	if err != nil {
+		return payrollError(c, err, "Failed to recalculate payroll run", "คำนวณเงินเดือนใหม่ไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Payroll run recalculated",
+		MessageTH:  "คำนวณเงินเดือนใหม่เรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Add payroll adjustment
+// @Description เพิ่มรายการปรับเฉพาะเดือนให้พนักงานในรอบ draft (บวก = เงินได้ เช่น โบนัส, ลบ = เงินหัก) แล้วคำนวณใหม่
+// @Tags Payroll
+// @Accept json
+// @Produce json
+// @Param id path string true "Payroll Run ID"
+// @Param body body dto.PayrollAdjustmentDTO true "PayrollAdjustmentDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.PayrollRunDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/payroll/run/{id}/adjustment [post]
+func (h *PayrollHandler) AddPayrollAdjustment(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.PayrollAdjustmentDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.AddPayrollAdjustment(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return payrollError(c, err, "Failed to add payroll adjustment", "เพิ่มรายการปรับไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Payroll adjustment added",
+		MessageTH:  "เพิ่มรายการปรับเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Approve payroll run
+// @Description admin อนุมัติรอบเงินเดือน (draft -> approved) หลังอนุมัติพนักงานดาวน์โหลดสลิปได้
+// @Tags Payroll
+// @Accept json
+// @Produce json
+// @Param id path string true "Payroll Run ID"
+// @Param body body dto.PayrollDecisionDTO true "PayrollDecisionDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.PayrollRunDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/payroll/run/{id}/approve [post]
+func (h *PayrollHandler) ApprovePayrollRun(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.PayrollDecisionDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ApprovePayrollRun(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return payrollError(c, err, "Failed to approve payroll run", "อนุมัติรอบเงินเดือนไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Payroll run approved",
+		MessageTH:  "อนุมัติรอบเงินเดือนเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Mark payroll run as paid
+// @Description บันทึกการโอนเงินเดือน (approved -> paid) สร้างรายจ่ายหนึ่งรายการ (เงินได้รวม + ประกันสังคมส่วนนายจ้าง) และแจ้งพนักงานทางอีเมล
+// @Tags Payroll
+// @Accept json
+// @Produce json
+// @Param id path string true "Payroll Run ID"
+// @Param body body dto.PayrollPaymentDTO true "PayrollPaymentDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.PayrollRunDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/payroll/run/{id}/pay [post]
+func (h *PayrollHandler) PayPayrollRun(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.PayrollPaymentDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.PayPayrollRun(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return payrollError(c, err, "Failed to pay payroll run", "บันทึกการจ่ายเงินเดือนไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Payroll run paid",
+		MessageTH:  "บันทึกการจ่ายเงินเดือนเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Cancel payroll run
+// @Description ยกเลิกรอบเงินเดือนที่ยังไม่จ่าย ต้องระบุเหตุผล (สร้างรอบใหม่ของเดือนเดียวกันได้)
+// @Tags Payroll
+// @Accept json
+// @Produce json
+// @Param id path string true "Payroll Run ID"
+// @Param body body dto.PayrollDecisionDTO true "PayrollDecisionDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.PayrollRunDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/payroll/run/{id}/cancel [post]
+func (h *PayrollHandler) CancelPayrollRun(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.PayrollDecisionDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.CancelPayrollRun(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return payrollError(c, err, "Failed to cancel payroll run", "ยกเลิกรอบเงินเดือนไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Payroll run cancelled",
+		MessageTH:  "ยกเลิกรอบเงินเดือนเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Download payroll bank file
+// @Description ไฟล์ CSV สำหรับโอนเงินเดือนแบบกลุ่ม (รอบที่อนุมัติแล้ว ต้องมีบัญชีธนาคารครบทุกคน)
+// @Tags Payroll
+// @Produce text/csv
+// @Param id path string true "Payroll Run ID"
+// @Success 200 {file} file
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/payroll/run/{id}/bank-file [get]
+func (h *PayrollHandler) DownloadPayrollBankFile(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	content, filename, err := h.svc.BuildPayrollBankFile(c.Context(), c.Params("id"), claims)
+	if err != nil {
+		return payrollError(c, err, "Failed to build bank file", "สร้างไฟล์โอนเงินไม่สำเร็จ")
+	}
+
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	return c.Send(content)
+}
+
+// @Summary My payslips
+// @Description สลิปเงินเดือนของฉันในรอบที่อนุมัติแล้ว
+// @Tags Payroll
+// @Produce json
+// @Param year query int false "Year (ค่าเริ่มต้น ปีนี้)"
+// @Success 200 {object} dto.BaseResponse{data=[]dto.PayslipDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Router /v1/payroll/payslip/me [get]
+func (h *PayrollHandler) ListMyPayslips(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestMyPayslips
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListMyPayslips(c.Context(), req, claims)
+	if err != nil {
+		return payrollError(c, err, "Failed to list payslips", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Download payslip PDF
+// @Description ดาวน์โหลดสลิปเงินเดือน PDF (พนักงานดาวน์โหลดได้เมื่อรอบอนุมัติแล้ว)
+// @Tags Payroll
+// @Produce application/pdf
+// @Param id path string true "Payslip ID"
+// @Success 200 {file} file
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/payroll/payslip/{id}/pdf [get]
+func (h *PayrollHandler) DownloadPayslipPDF(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	content, filename, err := h.svc.RenderPayslipPDF(c.Context(), c.Params("id"), claims)
+	if err != nil {
+		return payrollError(c, err, "Failed to render payslip", "สร้างสลิปเงินเดือนไม่สำเร็จ")
+	}
+
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	return c.Send(content)
+}
+
+func payrollError(c *fiber.Ctx, err error, messageEN, messageTH string) error {
+	switch {
+	case errors.Is(err, ports.ErrPayrollForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, ports.ErrPayrollConflict):
+		return c.Status(fiber.StatusConflict).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusConflict,
+			MessageEN:  messageEN + ": " + err.Error(),
+			MessageTH:  messageTH,
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusNotFound,
+			MessageEN:  "Not found",
+			MessageTH:  "ไม่พบข้อมูล",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusBadRequest,
+		MessageEN:  messageEN + ": " + err.Error(),
+		MessageTH:  messageTH,
+		Status:     "error",
+		Data:       nil,
+	})
+}
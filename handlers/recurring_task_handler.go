@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/middleware"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type RecurringTaskHandler struct {
+	svc ports.RecurringTaskService
+	mdw *middleware.Middleware
+}
+
+func NewRecurringTaskHandler(s ports.RecurringTaskService, mdw *middleware.Middleware) *RecurringTaskHandler {
+	return &RecurringTaskHandler{svc: s, mdw: mdw}
+}
+
+func (h *RecurringTaskHandler) RecurringTaskRoutes(router fiber.Router) {
+	versionOne := router.Group("v1")
+	recurring := versionOne.Group("recurring-task")
+
+	recurring.Post("/create", h.mdw.AuthCookieMiddleware(), h.CreateRecurringTask)
+	recurring.Get("/list", h.mdw.AuthCookieMiddleware(), h.ListRecurringTasks)
+	recurring.Get("/:id", h.mdw.AuthCookieMiddleware(), h.GetRecurringTask)
+	recurring.Put("/:id", h.mdw.AuthCookieMiddleware(), h.UpdateRecurringTask)
+	recurring.Delete("/:id", h.mdw.AuthCookieMiddleware(), h.DeleteRecurringTask)
+	recurring.Post("/:id/run", h.mdw.AuthCookieMiddleware(), h.RunRecurringTaskNow)
+}
+
+// @Summary Create recurring task
+// @Description สร้างงานประจำจาก workflow template ตามตาราง cron (เช่น "0 8 * * 1") หรือ RRULE (เช่น "FREQ=WEEKLY;BYDAY=MO") หมุนเวียนผู้รับผิดชอบตามลำดับ (ผู้จัดการแผนกหรือ admin)
+// @Tags RecurringTask
+// @Accept json
+// @Produce json
+// @Param body body dto.CreateRecurringTaskDTO true "CreateRecurringTaskDTO"
+// @Success 201 {object} dto.BaseResponse{data=dto.RecurringTaskDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/recurring-task/create [post]
+func (h *RecurringTaskHandler) CreateRecurringTask(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.CreateRecurringTaskDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.CreateRecurringTask(c.Context(), req, claims)
+	if err != nil {
+		return recurringTaskError(c, err, "Failed to create recurring task", "สร้างงานประจำไม่สำเร็จ")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusCreated,
+		MessageEN:  "Recurring task created",
+		MessageTH:  "สร้างงานประจำเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary List recurring tasks
+// @Description รายการงานประจำ (ผู้ใช้ทั่วไปเห็นเฉพาะที่ตัวเองสร้าง อยู่ในคิว หรือแผนกที่ตัวเองเป็นผู้จัดการ)
+// @Tags RecurringTask
+// @Produce json
+// @Param search query string false "ค้นหาจากชื่อ"
+// @Param department_id query string false "Department ID"
+// @Param workflow_id query string false "Workflow ID"
+// @Param active query string false "true | false"
+// @Param page query int false "หน้า"
+// @Param limit query int false "จำนวนต่อหน้า"
+// @Success 200 {object} dto.BaseResponse{data=dto.Pagination}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Router /v1/recurring-task/list [get]
+func (h *RecurringTaskHandler) ListRecurringTasks(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestListRecurringTask
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListRecurringTasks(c.Context(), req, claims)
+	if err != nil {
+		return recurringTaskError(c, err, "Failed to list recurring tasks", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Get recurring task
+// @Description รายละเอียดงานประจำพร้อมรอบถัดไปและผู้รับผิดชอบตามคิว
+// @Tags RecurringTask
+// @Produce json
+// @Param id path string true "Recurring Task ID"
+// @Success 200 {object} dto.BaseResponse{data=dto.RecurringTaskDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/recurring-task/{id} [get]
+func (h *RecurringTaskHandler) GetRecurringTask(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.GetRecurringTask(c.Context(), c.Params("id"), claims)
+	if err != nil {
+		return recurringTaskError(c, err, "Failed to get recurring task", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Update recurring task
+// @Description แก้ไขงานประจำ เปลี่ยนตารางหรือเปิดใช้งานใหม่จะคำนวณรอบถัดไปใหม่ เปลี่ยนรายชื่อผู้รับผิดชอบจะเริ่มคิวใหม่
+// @Tags RecurringTask
+// @Accept json
+// @Produce json
+// @Param id path string true "Recurring Task ID"
+// @Param body body dto.UpdateRecurringTaskDTO true "UpdateRecurringTaskDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.RecurringTaskDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/recurring-task/{id} [put]
+func (h *RecurringTaskHandler) UpdateRecurringTask(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.UpdateRecurringTaskDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.UpdateRecurringTask(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return recurringTaskError(c, err, "Failed to update recurring task", "แก้ไขงานประจำไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Recurring task updated",
+		MessageTH:  "แก้ไขงานประจำเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Spawn recurring task now
+// @Description สร้างงานรอบพิเศษทันทีให้ผู้รับผิดชอบคนถัดไป (ไม่เลื่อนรอบตามตาราง)
+// @Tags RecurringTask
+// @Produce json
+// @Param id path string true "Recurring Task ID"
+// @Success 201 {object} dto.BaseResponse{data=dto.RecurringSpawnDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/recurring-task/{id}/run [post]
+func (h *RecurringTaskHandler) RunRecurringTaskNow(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.RunRecurringTaskNow(c.Context(), c.Params("id"), claims)
+	if err != nil {
+		return recurringTaskError(c, err, "Failed to spawn task", "สร้างงานไม่สำเร็จ")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusCreated,
+		MessageEN:  "Task created",
+		MessageTH:  "สร้างงานเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Delete recurring task
+// @Description ลบงานประจำ (งานที่สร้างไปแล้วไม่ถูกลบ)
+// @Tags RecurringTask
+// @Produce json
+// @Param id path string true "Recurring Task ID"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/recurring-task/{id} [delete]
+func (h *RecurringTaskHandler) DeleteRecurringTask(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.DeleteRecurringTask(c.Context(), c.Params("id"), claims); err != nil {
+		return recurringTaskError(c, err, "Failed to delete recurring task", "ลบงานประจำไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Recurring task deleted",
+		MessageTH:  "ลบงานประจำเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+func recurringTaskError(c *fiber.Ctx, err error, messageEN, messageTH string) error {
+	switch {
+	case errors.Is(err, ports.ErrRecurringTaskForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusNotFound,
+			MessageEN:  "Not found",
+			MessageTH:  "ไม่พบข้อมูล",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusBadRequest,
+		MessageEN:  messageEN + ": " + err.Error(),
+		MessageTH:  messageTH,
+		Status:     "error",
+		Data:       nil,
+	})
+}
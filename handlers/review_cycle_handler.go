@@ -0,0 +1,826 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/middleware"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type ReviewCycleHandler struct {
+	svc ports.ReviewCycleService
+	mdw *middleware.Middleware
+}
+
+func NewReviewCycleHandler(s ports.ReviewCycleService, mdw *middleware.Middleware) *ReviewCycleHandler {
+	return &ReviewCycleHandler{svc: s, mdw: mdw}
+}
+
+func (h *ReviewCycleHandler) ReviewCycleRoutes(router fiber.Router) {
+	versionOne := router.Group("v1")
+	cycles := versionOne.Group("review-cycle")
+
+	cycles.Post("/create", h.mdw.AuthCookieMiddleware(), h.CreateReviewCycle)
+	cycles.Get("/list", h.mdw.AuthCookieMiddleware(), h.ListReviewCycles)
+	cycles.Get("/results", h.mdw.AuthCookieMiddleware(), h.ListReviewResults)
+	cycles.Get("/reviews/me", h.mdw.AuthCookieMiddleware(), h.ListMyPerformanceReviews)
+	cycles.Get("/review/:review_id", h.mdw.AuthCookieMiddleware(), h.GetPerformanceReview)
+	cycles.Put("/review/:review_id/self", h.mdw.AuthCookieMiddleware(), h.SubmitSelfReview)
+	cycles.Put("/review/:review_id/manager", h.mdw.AuthCookieMiddleware(), h.SubmitManagerReview)
+	cycles.Put("/review/:review_id/finalize", h.mdw.AuthCookieMiddleware(), h.FinalizeReview)
+	cycles.Get("/:id", h.mdw.AuthCookieMiddleware(), h.GetReviewCycle)
+	cycles.Put("/:id", h.mdw.AuthCookieMiddleware(), h.UpdateReviewCycle)
+	cycles.Delete("/:id", h.mdw.AuthCookieMiddleware(), h.DeleteReviewCycle)
+	cycles.Post("/:id/open", h.mdw.AuthCookieMiddleware(), h.OpenReviewCycle)
+	cycles.Post("/:id/close", h.mdw.AuthCookieMiddleware(), h.CloseReviewCycle)
+	cycles.Post("/:id/participants/sync", h.mdw.AuthCookieMiddleware(), h.SyncReviewParticipants)
+	cycles.Get("/:id/progress", h.mdw.AuthCookieMiddleware(), h.GetReviewCycleProgress)
+	cycles.Post("/:id/remind", h.mdw.AuthCookieMiddleware(), h.SendReviewReminders)
+	cycles.Get("/:id/reviews", h.mdw.AuthCookieMiddleware(), h.ListPerformanceReviews)
+}
+
+// @Summary Create review cycle
+// @Description สร้างรอบประเมินผลงานรายไตรมาส/รายปี กำหนดช่วงผลงาน วันเปิด-ปิด แผนกที่เข้าร่วม หัวข้อประเมินของผู้จัดการ และสัดส่วน KPI งาน (admin/HR)
+// @Tags ReviewCycle
+// @Accept json
+// @Produce json
+// @Param body body dto.CreateReviewCycleDTO true "CreateReviewCycleDTO"
+// @Success 201 {object} dto.BaseResponse{data=dto.ReviewCycleDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/review-cycle/create [post]
+func (h *ReviewCycleHandler) CreateReviewCycle(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.CreateReviewCycleDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.CreateReviewCycle(c.Context(), req, claims)
+	if err != nil {
+		return reviewCycleError(c, err, "Failed to create review cycle", "สร้างรอบประเมินไม่สำเร็จ")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusCreated,
+		MessageEN:  "Review cycle created",
+		MessageTH:  "สร้างรอบประเมินเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary List review cycles
+// @Description รายการรอบประเมิน (ผู้ใช้ทั่วไปไม่เห็นรอบที่ยังไม่เปิด)
+// @Tags ReviewCycle
+// @Produce json
+// @Param search query string false "ค้นหาจากชื่อ"
+// @Param cycle_type query string false "quarterly | annual"
+// @Param status query string false "scheduled | open | closed"
+// @Param page query int false "หน้า"
+// @Param limit query int false "จำนวนต่อหน้า"
+// @Success 200 {object} dto.BaseResponse{data=dto.Pagination}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Router /v1/review-cycle/list [get]
+func (h *ReviewCycleHandler) ListReviewCycles(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestListReviewCycle
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListReviewCycles(c.Context(), req, claims)
+	if err != nil {
+		return reviewCycleError(c, err, "Failed to list review cycles", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary My performance reviews
+// @Description แบบประเมินของฉัน (role=self) หรือที่ฉันต้องประเมินในฐานะผู้จัดการ (role=manager)
+// @Tags ReviewCycle
+// @Produce json
+// @Param role query string false "self | manager"
+// @Param status query string false "self_pending | manager_pending | calibration | finalized"
+// @Success 200 {object} dto.BaseResponse{data=[]dto.PerformanceReviewDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Router /v1/review-cycle/reviews/me [get]
+func (h *ReviewCycleHandler) ListMyPerformanceReviews(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestMyPerformanceReview
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListMyPerformanceReviews(c.Context(), req, claims)
+	if err != nil {
+		return reviewCycleError(c, err, "Failed to list performance reviews", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Locked review results
+// @Description ผลประเมินสุดท้ายที่ยืนยันแล้วของพนักงานทุกรอบ (ดูของคนอื่นได้เฉพาะ admin/ผู้จัดการแผนก)
+// @Tags ReviewCycle
+// @Produce json
+// @Param user_id query string false "User ID (ว่าง = ของฉัน)"
+// @Success 200 {object} dto.BaseResponse{data=[]dto.PerformanceReviewDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/review-cycle/results [get]
+func (h *ReviewCycleHandler) ListReviewResults(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestReviewResults
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListReviewResults(c.Context(), req, claims)
+	if err != nil {
+		return reviewCycleError(c, err, "Failed to list review results", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Get performance review
+// @Description แบบประเมินรายคน พนักงานเห็นคะแนนของผู้จัดการหลังยืนยันผลแล้วเท่านั้น
+// @Tags ReviewCycle
+// @Produce json
+// @Param review_id path string true "Review ID"
+// @Success 200 {object} dto.BaseResponse{data=dto.PerformanceReviewDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/review-cycle/review/{review_id} [get]
+func (h *ReviewCycleHandler) GetPerformanceReview(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.GetPerformanceReview(c.Context(), c.Params("review_id"), claims)
+	if err != nil {
+		return reviewCycleError(c, err, "Failed to get performance review", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Submit self-assessment
+// @Description พนักงานประเมินตนเองครบทุกหัวข้อ (ส่งซ้ำได้จนกว่าจะปิดรอบ)
+// @Tags ReviewCycle
+// @Accept json
+// @Produce json
+// @Param review_id path string true "Review ID"
+// @Param body body dto.SubmitReviewScoresDTO true "SubmitReviewScoresDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.PerformanceReviewDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/review-cycle/review/{review_id}/self [put]
+func (h *ReviewCycleHandler) SubmitSelfReview(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.SubmitReviewScoresDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.SubmitSelfReview(c.Context(), c.Params("review_id"), req, claims)
+	if err != nil {
+		return reviewCycleError(c, err, "Failed to submit self-assessment", "ส่งแบบประเมินตนเองไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Self-assessment submitted",
+		MessageTH:  "ส่งแบบประเมินตนเองเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Submit manager assessment
+// @Description ผู้จัดการแผนก (หรือ HR) ให้คะแนนครบทุกหัวข้อ ระบบคิด KPI ของงานในช่วงรอบใหม่และคำนวณคะแนนแนะนำ
+// @Tags ReviewCycle
+// @Accept json
+// @Produce json
+// @Param review_id path string true "Review ID"
+// @Param body body dto.SubmitReviewScoresDTO true "SubmitReviewScoresDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.PerformanceReviewDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/review-cycle/review/{review_id}/manager [put]
+func (h *ReviewCycleHandler) SubmitManagerReview(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.SubmitReviewScoresDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.SubmitManagerReview(c.Context(), c.Params("review_id"), req, claims)
+	if err != nil {
+		return reviewCycleError(c, err, "Failed to submit manager assessment", "ส่งผลการประเมินไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Manager assessment submitted",
+		MessageTH:  "ส่งผลการประเมินเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Finalize performance review
+// @Description HR ปรับเทียบคะแนนสุดท้าย (ว่าง = ใช้คะแนนแนะนำ) ตัดเกรดตาม bands ของรอบ แล้วล็อกผล
+// @Tags ReviewCycle
+// @Accept json
+// @Produce json
+// @Param review_id path string true "Review ID"
+// @Param body body dto.FinalizeReviewDTO true "FinalizeReviewDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.PerformanceReviewDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/review-cycle/review/{review_id}/finalize [put]
+func (h *ReviewCycleHandler) FinalizeReview(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.FinalizeReviewDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.FinalizeReview(c.Context(), c.Params("review_id"), req, claims)
+	if err != nil {
+		return reviewCycleError(c, err, "Failed to finalize review", "ยืนยันผลประเมินไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Review finalized",
+		MessageTH:  "ยืนยันผลประเมินเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Get review cycle
+// @Description รายละเอียดรอบประเมิน
+// @Tags ReviewCycle
+// @Produce json
+// @Param id path string true "Cycle ID"
+// @Success 200 {object} dto.BaseResponse{data=dto.ReviewCycleDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/review-cycle/{id} [get]
+func (h *ReviewCycleHandler) GetReviewCycle(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.GetReviewCycle(c.Context(), c.Params("id"), claims)
+	if err != nil {
+		return reviewCycleError(c, err, "Failed to get review cycle", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Update review cycle
+// @Description แก้ไขรอบประเมิน รอบที่เปิดแล้วแก้ได้เฉพาะชื่อ วันปิด และจำนวนวันเตือน รอบที่ปิดแล้วแก้ไม่ได้ (admin/HR)
+// @Tags ReviewCycle
+// @Accept json
+// @Produce json
+// @Param id path string true "Cycle ID"
+// @Param body body dto.UpdateReviewCycleDTO true "UpdateReviewCycleDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.ReviewCycleDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/review-cycle/{id} [put]
+func (h *ReviewCycleHandler) UpdateReviewCycle(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.UpdateReviewCycleDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.UpdateReviewCycle(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return reviewCycleError(c, err, "Failed to update review cycle", "แก้ไขรอบประเมินไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Review cycle updated",
+		MessageTH:  "แก้ไขรอบประเมินเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Open review cycle now
+// @Description เปิดรอบประเมินทันทีและสร้างแบบประเมินให้พนักงานในแผนกที่เข้าร่วม (admin/HR)
+// @Tags ReviewCycle
+// @Produce json
+// @Param id path string true "Cycle ID"
+// @Success 200 {object} dto.BaseResponse{data=dto.ReviewCycleDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/review-cycle/{id}/open [post]
+func (h *ReviewCycleHandler) OpenReviewCycle(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.OpenReviewCycle(c.Context(), c.Params("id"), claims)
+	if err != nil {
+		return reviewCycleError(c, err, "Failed to open review cycle", "เปิดรอบประเมินไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Review cycle opened",
+		MessageTH:  "เปิดรอบประเมินเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Close review cycle now
+// @Description ปิดรับการประเมินทันที HR ยังปรับเทียบและยืนยันผลต่อได้ (admin/HR)
+// @Tags ReviewCycle
+// @Produce json
+// @Param id path string true "Cycle ID"
+// @Success 200 {object} dto.BaseResponse{data=dto.ReviewCycleDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/review-cycle/{id}/close [post]
+func (h *ReviewCycleHandler) CloseReviewCycle(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.CloseReviewCycle(c.Context(), c.Params("id"), claims)
+	if err != nil {
+		return reviewCycleError(c, err, "Failed to close review cycle", "ปิดรอบประเมินไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Review cycle closed",
+		MessageTH:  "ปิดรอบประเมินเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Sync review participants
+// @Description เพิ่มแบบประเมินให้พนักงานที่เข้ามาใหม่ในแผนกที่เข้าร่วมระหว่างรอบเปิด (admin/HR)
+// @Tags ReviewCycle
+// @Produce json
+// @Param id path string true "Cycle ID"
+// @Success 200 {object} dto.BaseResponse{data=dto.ReviewSyncResult}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/review-cycle/{id}/participants/sync [post]
+func (h *ReviewCycleHandler) SyncReviewParticipants(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.SyncReviewParticipants(c.Context(), c.Params("id"), claims)
+	if err != nil {
+		return reviewCycleError(c, err, "Failed to sync participants", "เพิ่มผู้เข้าร่วมไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Review cycle progress
+// @Description ความคืบหน้าของรอบ แยกตามสถานะและแผนก (ผู้จัดการเห็นเฉพาะแผนกตัวเอง)
+// @Tags ReviewCycle
+// @Produce json
+// @Param id path string true "Cycle ID"
+// @Success 200 {object} dto.BaseResponse{data=dto.ReviewCycleProgressDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/review-cycle/{id}/progress [get]
+func (h *ReviewCycleHandler) GetReviewCycleProgress(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.GetReviewCycleProgress(c.Context(), c.Params("id"), claims)
+	if err != nil {
+		return reviewCycleError(c, err, "Failed to get review progress", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Send review reminders
+// @Description ส่งอีเมลเตือนพนักงานที่ยังไม่ประเมินตนเองและผู้จัดการที่ยังประเมินไม่ครบ (admin/HR)
+// @Tags ReviewCycle
+// @Produce json
+// @Param id path string true "Cycle ID"
+// @Success 200 {object} dto.BaseResponse{data=dto.ReviewReminderResult}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/review-cycle/{id}/remind [post]
+func (h *ReviewCycleHandler) SendReviewReminders(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.SendReviewReminders(c.Context(), c.Params("id"), claims)
+	if err != nil {
+		return reviewCycleError(c, err, "Failed to send reminders", "ส่งอีเมลเตือนไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary List performance reviews of cycle
+// @Description แบบประเมินในรอบ (ผู้ใช้ทั่วไปเห็นของตัวเองและลูกทีมที่ต้องประเมิน)
+// @Tags ReviewCycle
+// @Produce json
+// @Param id path string true "Cycle ID"
+// @Param department_id query string false "Department ID"
+// @Param status query string false "self_pending | manager_pending | calibration | finalized"
+// @Param page query int false "หน้า"
+// @Param limit query int false "จำนวนต่อหน้า"
+// @Success 200 {object} dto.BaseResponse{data=dto.Pagination}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/review-cycle/{id}/reviews [get]
+func (h *ReviewCycleHandler) ListPerformanceReviews(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestListPerformanceReview
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListPerformanceReviews(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return reviewCycleError(c, err, "Failed to list performance reviews", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Delete review cycle
+// @Description ลบรอบประเมินที่ยังไม่เปิด (admin/HR)
+// @Tags ReviewCycle
+// @Produce json
+// @Param id path string true "Cycle ID"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/review-cycle/{id} [delete]
+func (h *ReviewCycleHandler) DeleteReviewCycle(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.DeleteReviewCycle(c.Context(), c.Params("id"), claims); err != nil {
+		return reviewCycleError(c, err, "Failed to delete review cycle", "ลบรอบประเมินไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Review cycle deleted",
+		MessageTH:  "ลบรอบประเมินเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+func reviewCycleError(c *fiber.Ctx, err error, messageEN, messageTH string) error {
+	switch {
+	case errors.Is(err, ports.ErrReviewForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, ports.ErrReviewLocked):
+		return c.Status(fiber.StatusConflict).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusConflict,
+			MessageEN:  messageEN + ": " + err.Error(),
+			MessageTH:  "รอบประเมินหรือผลประเมินถูกล็อกแล้ว",
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusNotFound,
+			MessageEN:  "Not found",
+			MessageTH:  "ไม่พบข้อมูล",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusBadRequest,
+		MessageEN:  messageEN + ": " + err.Error(),
+		MessageTH:  messageTH,
+		Status:     "error",
+		Data:       nil,
+	})
+}
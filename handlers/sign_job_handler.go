@@ -28,6 +28,8 @@ func (h *SignJobHandler) SignJobRoutes(router fiber.Router) {
 	signJob.Get("/list", h.mdw.AuthCookieMiddleware(), h.ListSignJobs)
 	signJob.Put("/verify/:id", h.mdw.AuthCookieMiddleware(), h.VerifySignJob)
 	signJob.Put("/confirm/:id", h.mdw.AuthCookieMiddleware(), h.ConfirmSignJob)
+	signJob.Put("/cancel/:id", h.mdw.AuthCookieMiddleware(), h.CancelSignJob)
+	signJob.Put("/generate-tasks/:id", h.mdw.AuthCookieMiddleware(), h.GenerateProductionTasks)
 	signJob.Get("/:id", h.mdw.AuthCookieMiddleware(), h.GetSignJobByID)
 	signJob.Put("/:id", h.mdw.AuthCookieMiddleware(), h.UpdateSignJobByID)
 	signJob.Delete("/:id", h.mdw.AuthCookieMiddleware(), h.DeleteSignJobByID)
@@ -97,7 +99,7 @@ func (h *SignJobHandler) CreateSignJob(c *fiber.Ctx) error {
 // @Param page query int false "Page number (default 1)"
 // @Param limit query int false "Page limit (default 10)"
 // @Param search query string false "ค้นหาด้วย ชื่อโปรเจกต์, ชื่องาน,ชื่อบริษัท,ชื่อผู้ติดต่อ "
-// @Param status query string false "สถานะงาน in_progress, done"
+// @Param status query string false "สถานะงาน in_progress, done, cancelled"
 // @Param sort_by query string false "เรียงตาม created_at updated_at due_date job_name project_name company_name status price_thb quantity"
 // @Param sort_order query string false "เรียงลำดับ (asc เก่า→ใหม่ | desc ใหม่→เก่า (ค่าเริ่มต้น))"
 // @Success 200 {object} dto.BaseResponse{data=dto.Pagination}
@@ -427,3 +429,128 @@ func (h *SignJobHandler) ConfirmSignJob(c *fiber.Ctx) error {
 		Data:       nil,
 	})
 }
+
+// @Summary Cancel Sign Job
+// @Description ยกเลิกงานป้ายพร้อมเหตุผล: ปิดลูกหนี้ กลับรายการรายได้ที่ยังไม่ได้รับเงิน สร้างเจ้าหนี้คืนเงิน (ถ้ามี) และยกเลิกงานย่อยที่ยังไม่เสร็จ (admin เท่านั้น)
+// @Tags SignJob
+// @Accept json
+// @Produce json
+// @Param id path string true "Sign Job ID"
+// @Param request body dto.CancelSignJobDTO true "Cancel Sign Job"
+// @Success 200 {object} dto.BaseResponse{data=dto.CancelSignJobResultDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/sign-job/cancel/{id} [put]
+func (h *SignJobHandler) CancelSignJob(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	if claims.Role != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.CancelSignJobDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.CancelSignJob(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+				StatusCode: fiber.StatusNotFound,
+				MessageEN:  "Sign job not found",
+				MessageTH:  "ไม่พบงานป้าย",
+				Status:     "error",
+				Data:       nil,
+			})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Failed to cancel sign job: " + err.Error(),
+			MessageTH:  "ยกเลิกงานไม่สำเร็จ",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Sign job cancelled",
+		MessageTH:  "ยกเลิกงานเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Generate production tasks
+// @Description สร้าง task การผลิตจาก workflow ที่ผูกกับประเภทป้าย (ใช้เมื่อเพิ่ม mapping หลังยืนยันงาน หรือสร้างไม่ครบ จะสร้างเฉพาะ workflow ที่ยังขาด)
+// @Tags SignJob
+// @Produce json
+// @Param id path string true "Sign Job ID"
+// @Success 200 {object} dto.BaseResponse{data=[]string}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/sign-job/generate-tasks/{id} [put]
+func (h *SignJobHandler) GenerateProductionTasks(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	taskIDs, err := h.svc.GenerateProductionTasks(c.Context(), c.Params("id"), claims)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+				StatusCode: fiber.StatusNotFound,
+				MessageEN:  "Sign job not found",
+				MessageTH:  "ไม่พบงานป้าย",
+				Status:     "error",
+				Data:       nil,
+			})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Failed to generate tasks: " + err.Error(),
+			MessageTH:  "สร้างงานไม่สำเร็จ",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       taskIDs,
+	})
+}
@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/middleware"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type SignTypeWorkflowHandler struct {
+	svc ports.SignTypeWorkflowService
+	mdw *middleware.Middleware
+}
+
+func NewSignTypeWorkflowHandler(s ports.SignTypeWorkflowService, mdw *middleware.Middleware) *SignTypeWorkflowHandler {
+	return &SignTypeWorkflowHandler{svc: s, mdw: mdw}
+}
+
+func (h *SignTypeWorkflowHandler) SignTypeWorkflowRoutes(router fiber.Router) {
+	versionOne := router.Group("v1")
+	stw := versionOne.Group("sign-type-workflow")
+
+	stw.Post("/create", h.mdw.AuthCookieMiddleware(), h.CreateSignTypeWorkflow)
+	stw.Get("/list", h.mdw.AuthCookieMiddleware(), h.ListSignTypeWorkflows)
+	stw.Get("/:id", h.mdw.AuthCookieMiddleware(), h.GetSignTypeWorkflowByID)
+	stw.Put("/:id", h.mdw.AuthCookieMiddleware(), h.UpdateSignTypeWorkflow)
+	stw.Delete("/:id", h.mdw.AuthCookieMiddleware(), h.DeleteSignTypeWorkflow)
+}
+
+// @Summary Create sign type workflow mapping
+// @Description ผูกประเภทป้าย (และตัวเลือกติดตั้ง) กับ workflow ที่ใช้ผลิต เพื่อสร้าง task อัตโนมัติเมื่อยืนยันงาน (admin เท่านั้น)
+// @Tags SignTypeWorkflow
+// @Accept json
+// @Produce json
+// @Param body body dto.CreateSignTypeWorkflowDTO true "Sign type workflow mapping"
+// @Success 201 {object} dto.BaseResponse
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/sign-type-workflow/create [post]
+func (h *SignTypeWorkflowHandler) CreateSignTypeWorkflow(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	if claims.Role != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.CreateSignTypeWorkflowDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.CreateSignTypeWorkflow(c.Context(), req, claims); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+				StatusCode: fiber.StatusNotFound,
+				MessageEN:  "Sign type not found",
+				MessageTH:  "ไม่พบประเภทป้าย",
+				Status:     "error",
+				Data:       nil,
+			})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Failed to create mapping: " + err.Error(),
+			MessageTH:  "สร้างการผูก workflow ไม่สำเร็จ",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusCreated,
+		MessageEN:  "Mapping created successfully",
+		MessageTH:  "สร้างการผูก workflow เรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+// @Summary List sign type workflow mappings
+// @Description รายการการผูกประเภทป้ายกับ workflow
+// @Tags SignTypeWorkflow
+// @Produce json
+// @Param sign_type_id query string false "Sign Type ID"
+// @Param page query int false "Page"
+// @Param limit query int false "Limit"
+// @Success 200 {object} dto.BaseResponse{data=dto.Pagination}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 500 {object} dto.BaseResponse
+// @Router /v1/sign-type-workflow/list [get]
+func (h *SignTypeWorkflowHandler) ListSignTypeWorkflows(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestListSignTypeWorkflow
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if req.Limit > 100 || req.Limit <= 0 {
+		req.Limit = 10
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	list, err := h.svc.ListSignTypeWorkflows(c.Context(), claims, req.Page, req.Limit, req.SignTypeID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusInternalServerError,
+			MessageEN:  "Failed to list mappings: " + err.Error(),
+			MessageTH:  "ไม่สามารถดึงข้อมูลได้",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       list,
+	})
+}
+
+// @Summary Get sign type workflow mapping
+// @Description ดูรายละเอียดการผูกประเภทป้ายกับ workflow
+// @Tags SignTypeWorkflow
+// @Produce json
+// @Param id path string true "Mapping ID"
+// @Success 200 {object} dto.BaseResponse{data=dto.SignTypeWorkflowDTO}
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 500 {object} dto.BaseResponse
+// @Router /v1/sign-type-workflow/{id} [get]
+func (h *SignTypeWorkflowHandler) GetSignTypeWorkflowByID(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	mapping, err := h.svc.GetSignTypeWorkflowByID(c.Context(), c.Params("id"), claims)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+				StatusCode: fiber.StatusNotFound,
+				MessageEN:  "Mapping not found",
+				MessageTH:  "ไม่พบการผูก workflow",
+				Status:     "error",
+				Data:       nil,
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusInternalServerError,
+			MessageEN:  "Failed to get mapping: " + err.Error(),
+			MessageTH:  "ไม่สามารถดึงข้อมูลได้",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       mapping,
+	})
+}
+
+// @Summary Update sign type workflow mapping
+// @Description แก้ไขการผูกประเภทป้ายกับ workflow (admin เท่านั้น)
+// @Tags SignTypeWorkflow
+// @Accept json
+// @Produce json
+// @Param id path string true "Mapping ID"
+// @Param body body dto.UpdateSignTypeWorkflowDTO true "Update mapping"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/sign-type-workflow/{id} [put]
+func (h *SignTypeWorkflowHandler) UpdateSignTypeWorkflow(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	if claims.Role != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.UpdateSignTypeWorkflowDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.UpdateSignTypeWorkflow(c.Context(), c.Params("id"), req, claims); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+				StatusCode: fiber.StatusNotFound,
+				MessageEN:  "Mapping not found",
+				MessageTH:  "ไม่พบการผูก workflow",
+				Status:     "error",
+				Data:       nil,
+			})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Failed to update mapping: " + err.Error(),
+			MessageTH:  "แก้ไขการผูก workflow ไม่สำเร็จ",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Mapping updated successfully",
+		MessageTH:  "แก้ไขการผูก workflow เรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+// @Summary Delete sign type workflow mapping
+// @Description ลบการผูกประเภทป้ายกับ workflow (soft delete, admin เท่านั้น)
+// @Tags SignTypeWorkflow
+// @Produce json
+// @Param id path string true "Mapping ID"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 500 {object} dto.BaseResponse
+// @Router /v1/sign-type-workflow/{id} [delete]
+func (h *SignTypeWorkflowHandler) DeleteSignTypeWorkflow(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	if claims.Role != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.DeleteSignTypeWorkflow(c.Context(), c.Params("id"), claims); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+				StatusCode: fiber.StatusNotFound,
+				MessageEN:  "Mapping not found",
+				MessageTH:  "ไม่พบการผูก workflow",
+				Status:     "error",
+				Data:       nil,
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusInternalServerError,
+			MessageEN:  "Failed to delete mapping: " + err.Error(),
+			MessageTH:  "ลบการผูก workflow ไม่สำเร็จ",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Mapping deleted successfully",
+		MessageTH:  "ลบการผูก workflow เรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
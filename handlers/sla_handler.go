@@ -0,0 +1,347 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/middleware"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type SLAHandler struct {
+	svc ports.SLAService
+	mdw *middleware.Middleware
+}
+
+func NewSLAHandler(s ports.SLAService, mdw *middleware.Middleware) *SLAHandler {
+	return &SLAHandler{svc: s, mdw: mdw}
+}
+
+func (h *SLAHandler) SLARoutes(router fiber.Router) {
+	versionOne := router.Group("v1")
+	sla := versionOne.Group("sla")
+
+	sla.Post("/rule/create", h.mdw.AuthCookieMiddleware(), h.CreateRule)
+	sla.Get("/rule/list", h.mdw.AuthCookieMiddleware(), h.ListRules)
+	sla.Put("/rule/:id", h.mdw.AuthCookieMiddleware(), h.UpdateRule)
+	sla.Delete("/rule/:id", h.mdw.AuthCookieMiddleware(), h.DeleteRule)
+	sla.Get("/breach/list", h.mdw.AuthCookieMiddleware(), h.ListBreaches)
+	sla.Get("/breach/summary", h.mdw.AuthCookieMiddleware(), h.BreachSummary)
+}
+
+// @Summary Create SLA rule
+// @Description สร้างกฎ SLA ต่อ step ของ workflow หรือระดับความสำคัญของงาน (admin เท่านั้น)
+// @Tags SLA
+// @Accept json
+// @Produce json
+// @Param body body dto.CreateSLARuleDTO true "CreateSLARuleDTO"
+// @Success 201 {object} dto.BaseResponse{data=dto.SLARuleDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/sla/rule/create [post]
+func (h *SLAHandler) CreateRule(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.CreateSLARuleDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.CreateRule(c.Context(), req, claims)
+	if err != nil {
+		return slaError(c, err, "Failed to create SLA rule", "สร้างกฎ SLA ไม่สำเร็จ")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusCreated,
+		MessageEN:  "SLA rule created",
+		MessageTH:  "สร้างกฎ SLA เรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary List SLA rules
+// @Description รายการกฎ SLA
+// @Tags SLA
+// @Produce json
+// @Param scope query string false "step|importance"
+// @Param workflow_id query string false "Workflow ID"
+// @Success 200 {object} dto.BaseResponse{data=[]dto.SLARuleDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Router /v1/sla/rule/list [get]
+func (h *SLAHandler) ListRules(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestListSLARules
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListRules(c.Context(), req, claims)
+	if err != nil {
+		return slaError(c, err, "Failed to list SLA rules", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Update SLA rule
+// @Description แก้ไขกฎ SLA (admin เท่านั้น)
+// @Tags SLA
+// @Accept json
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Param body body dto.UpdateSLARuleDTO true "UpdateSLARuleDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.SLARuleDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/sla/rule/{id} [put]
+func (h *SLAHandler) UpdateRule(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.UpdateSLARuleDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.UpdateRule(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return slaError(c, err, "Failed to update SLA rule", "แก้ไขกฎ SLA ไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "SLA rule updated",
+		MessageTH:  "แก้ไขกฎ SLA เรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Delete SLA rule
+// @Description ลบกฎ SLA (admin เท่านั้น)
+// @Tags SLA
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/sla/rule/{id} [delete]
+func (h *SLAHandler) DeleteRule(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.DeleteRule(c.Context(), c.Params("id"), claims); err != nil {
+		return slaError(c, err, "Failed to delete SLA rule", "ลบกฎ SLA ไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "SLA rule deleted",
+		MessageTH:  "ลบกฎ SLA เรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+// @Summary List SLA breaches
+// @Description รายการการเตือน/เกินกำหนด SLA (ผู้จัดการเห็นแผนกที่ดูแล พนักงานเห็นของตนเอง)
+// @Tags SLA
+// @Produce json
+// @Param page query int false "Page"
+// @Param limit query int false "Limit"
+// @Param kind query string false "task|step"
+// @Param level query string false "warning|overdue"
+// @Param status query string false "open|resolved"
+// @Param department_id query string false "Department ID"
+// @Param user_id query string false "User ID"
+// @Param task_id query string false "Task ID"
+// @Param from query string false "YYYY-MM-DD"
+// @Param to query string false "YYYY-MM-DD"
+// @Success 200 {object} dto.BaseResponse{data=dto.Pagination}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/sla/breach/list [get]
+func (h *SLAHandler) ListBreaches(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestListSLABreaches
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListBreaches(c.Context(), req, claims)
+	if err != nil {
+		return slaError(c, err, "Failed to list SLA breaches", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary SLA breach summary
+// @Description สรุปจำนวนการเตือน/เกินกำหนดและชั่วโมงเกินกำหนด แยกตามผู้ใช้ แผนก หรือ workflow (ใช้ประกอบ KPI)
+// @Tags SLA
+// @Produce json
+// @Param group_by query string false "user|department|workflow"
+// @Param department_id query string false "Department ID"
+// @Param from query string false "YYYY-MM-DD"
+// @Param to query string false "YYYY-MM-DD"
+// @Success 200 {object} dto.BaseResponse{data=[]dto.SLABreachSummaryDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/sla/breach/summary [get]
+func (h *SLAHandler) BreachSummary(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestSLABreachSummary
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.BreachSummary(c.Context(), req, claims)
+	if err != nil {
+		return slaError(c, err, "Failed to build SLA summary", "ไม่สามารถสร้างรายงานได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+func slaError(c *fiber.Ctx, err error, messageEN, messageTH string) error {
+	switch {
+	case errors.Is(err, ports.ErrSLAForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusNotFound,
+			MessageEN:  "Not found",
+			MessageTH:  "ไม่พบข้อมูล",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusBadRequest,
+		MessageEN:  messageEN + ": " + err.Error(),
+		MessageTH:  messageTH,
+		Status:     "error",
+		Data:       nil,
+	})
+}
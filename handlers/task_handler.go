@@ -29,11 +29,18 @@ func (h *TaskHandler) TaskRoutes(router fiber.Router) {
 
 	tasks.Get("/list", h.mdw.AuthCookieMiddleware(), h.GetListTasks)
 	tasks.Post("/create", h.mdw.AuthCookieMiddleware(), h.CreateTask)
+	tasks.Get("/stats/drift", h.mdw.AuthCookieMiddleware(), h.CheckTaskStats)
+	tasks.Post("/stats/rebuild", h.mdw.AuthCookieMiddleware(), h.RebuildTaskStats)
 	tasks.Get("/:id", h.mdw.AuthCookieMiddleware(), h.GetTaskByID)
 	// tasks.Put("/:id", h.mdw.AuthCookieMiddleware(), h.UpdateTask)
 	tasks.Put("/:id", h.mdw.AuthCookieMiddleware(), h.PutTaskV2)
 	tasks.Delete("/:id", h.mdw.AuthCookieMiddleware(), h.DeleteTask)
 	tasks.Put("/:task_id/steps/:step_id", h.mdw.AuthCookieMiddleware(), h.UpdateStepStatusNote)
+	tasks.Post("/:task_id/reassign", h.mdw.AuthCookieMiddleware(), h.ReassignTask)
+	tasks.Put("/:task_id/steps/:step_id/assignee", h.mdw.AuthCookieMiddleware(), h.AssignStep)
+	tasks.Post("/:task_id/steps/:step_id/checklist", h.mdw.AuthCookieMiddleware(), h.AddChecklistItem)
+	tasks.Put("/:task_id/steps/:step_id/checklist/:item_id", h.mdw.AuthCookieMiddleware(), h.CheckChecklistItem)
+	tasks.Delete("/:task_id/steps/:step_id/checklist/:item_id", h.mdw.AuthCookieMiddleware(), h.RemoveChecklistItem)
 
 	// tasksV2.Put("/:id", h.mdw.AuthCookieMiddleware(), h.PutTaskV2)
 
@@ -323,6 +330,7 @@ func (h *TaskHandler) DeleteTask(c *fiber.Ctx) error {
 // @Success 200 {object} dto.BaseResponse
 // @Failure 400 {object} dto.BaseResponse
 // @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
 // @Failure 500 {object} dto.BaseResponse
 // @Router /v1/tasks/{task_id}/steps/{step_id} [put]
 // handlers/task.go
@@ -372,6 +380,14 @@ func (h *TaskHandler) UpdateStepStatusNote(c *fiber.Ctx) error {
 			statusCode = fiber.StatusNotFound
 			messageEN = "Task or step not found"
 			messageTH = "ไม่พบงานหรือขั้นตอน"
+		case errors.Is(errOnUpdate, ports.ErrStepPrerequisitesPending):
+			statusCode = fiber.StatusConflict
+			messageEN = errOnUpdate.Error()
+			messageTH = "ยังเริ่มขั้นตอนนี้ไม่ได้ ขั้นตอนก่อนหน้ายังไม่เสร็จ"
+		case errors.Is(errOnUpdate, ports.ErrStepChecklistIncomplete):
+			statusCode = fiber.StatusConflict
+			messageEN = errOnUpdate.Error()
+			messageTH = "ยังปิดขั้นตอนนี้ไม่ได้ ติ๊กรายการตรวจสอบยังไม่ครบ"
 		case strings.Contains(errOnUpdate.Error(), "user is not the assignee"):
 			statusCode = fiber.StatusForbidden
 			messageEN = errOnUpdate.Error()
@@ -447,6 +463,10 @@ func (h *TaskHandler) PutTaskV2(c *fiber.Ctx) error {
 			statusCode = fiber.StatusNotFound
 			messageEN = "Task or step not found"
 			messageTH = "ไม่พบงานหรือขั้นตอน"
+		case errors.Is(err, ports.ErrStepChecklistIncomplete):
+			statusCode = fiber.StatusConflict
+			messageEN = err.Error()
+			messageTH = "ยังปิดขั้นตอนไม่ได้ ติ๊กรายการตรวจสอบยังไม่ครบ"
 		case strings.Contains(err.Error(), "user is not the assignee"):
 			statusCode = fiber.StatusForbidden
 			messageEN = err.Error()
@@ -474,3 +494,432 @@ func (h *TaskHandler) PutTaskV2(c *fiber.Ctx) error {
 		Data:       nil,
 	})
 }
+
+// @Summary Assign step owner
+// @Description มอบหมายผู้รับผิดชอบ step (ผู้สร้างงาน, ผู้รับผิดชอบหลัก, ผู้จัดการแผนกของ step หรือ admin)
+// @Tags Tasks
+// @Accept json
+// @Produce json
+// @Param task_id path string true "Task ID"
+// @Param step_id path string true "Step ID"
+// @Param request body dto.AssignStepRequest true "Assign Step Request"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/tasks/{task_id}/steps/{step_id}/assignee [put]
+func (h *TaskHandler) AssignStep(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.AssignStepRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.AssignStep(c.Context(), c.Params("task_id"), c.Params("step_id"), req, claims); err != nil {
+		statusCode := fiber.StatusBadRequest
+		messageEN := "Failed to assign step: " + err.Error()
+		messageTH := "มอบหมายขั้นตอนไม่สำเร็จ"
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			statusCode = fiber.StatusNotFound
+			messageEN = "Task or step not found"
+			messageTH = "ไม่พบงานหรือขั้นตอน"
+		case errors.Is(err, ports.ErrStepForbidden):
+			statusCode = fiber.StatusForbidden
+			messageEN = "Forbidden"
+			messageTH = "ห้ามเข้าถึง"
+		}
+		return c.Status(statusCode).JSON(dto.BaseResponse{
+			StatusCode: statusCode,
+			MessageEN:  messageEN,
+			MessageTH:  messageTH,
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Step assigned",
+		MessageTH:  "มอบหมายขั้นตอนสำเร็จ",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+// @Summary Add step checklist item
+// @Description เพิ่มรายการตรวจสอบใน step (ผู้รับผิดชอบหลัก เจ้าของ step หรือ admin) ต้องติ๊กครบก่อนปิด step เป็น done
+// @Tags Tasks
+// @Accept json
+// @Produce json
+// @Param task_id path string true "Task ID"
+// @Param step_id path string true "Step ID"
+// @Param request body dto.AddChecklistItemRequest true "Checklist item"
+// @Success 201 {object} dto.BaseResponse{data=dto.TaskChecklistItem}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/tasks/{task_id}/steps/{step_id}/checklist [post]
+func (h *TaskHandler) AddChecklistItem(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.AddChecklistItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.AddChecklistItem(c.Context(), c.Params("task_id"), c.Params("step_id"), req, claims)
+	if err != nil {
+		return checklistError(c, err, "Failed to add checklist item", "เพิ่มรายการตรวจสอบไม่สำเร็จ")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusCreated,
+		MessageEN:  "Checklist item added",
+		MessageTH:  "เพิ่มรายการตรวจสอบเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Tick step checklist item
+// @Description ติ๊กหรือยกเลิกติ๊กรายการตรวจสอบของ step
+// @Tags Tasks
+// @Accept json
+// @Produce json
+// @Param task_id path string true "Task ID"
+// @Param step_id path string true "Step ID"
+// @Param item_id path string true "Checklist item ID"
+// @Param request body dto.CheckChecklistItemRequest true "Checked state"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/tasks/{task_id}/steps/{step_id}/checklist/{item_id} [put]
+func (h *TaskHandler) CheckChecklistItem(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.CheckChecklistItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.CheckChecklistItem(c.Context(), c.Params("task_id"), c.Params("step_id"), c.Params("item_id"), req, claims); err != nil {
+		return checklistError(c, err, "Failed to update checklist item", "อัปเดตรายการตรวจสอบไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Checklist item updated",
+		MessageTH:  "อัปเดตรายการตรวจสอบเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+// @Summary Remove step checklist item
+// @Description ลบรายการตรวจสอบออกจาก step
+// @Tags Tasks
+// @Produce json
+// @Param task_id path string true "Task ID"
+// @Param step_id path string true "Step ID"
+// @Param item_id path string true "Checklist item ID"
+// @Success 200 {object} dto.BaseResponse
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/tasks/{task_id}/steps/{step_id}/checklist/{item_id} [delete]
+func (h *TaskHandler) RemoveChecklistItem(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	if err := h.svc.RemoveChecklistItem(c.Context(), c.Params("task_id"), c.Params("step_id"), c.Params("item_id"), claims); err != nil {
+		return checklistError(c, err, "Failed to remove checklist item", "ลบรายการตรวจสอบไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Checklist item removed",
+		MessageTH:  "ลบรายการตรวจสอบเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       nil,
+	})
+}
+
+// @Summary Reassign task
+// @Description เปลี่ยนผู้รับผิดชอบหลักของงานพร้อมเหตุผล (ผู้สร้างงาน, ผู้รับผิดชอบหลัก, ผู้จัดการแผนก หรือ admin) บันทึกประวัติและย้ายสถิติงานให้อัตโนมัติ
+// @Tags Tasks
+// @Accept json
+// @Produce json
+// @Param task_id path string true "Task ID"
+// @Param body body dto.ReassignTaskRequest true "ReassignTaskRequest"
+// @Success 200 {object} dto.BaseResponse{data=dto.TaskReassignmentDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/tasks/{task_id}/reassign [post]
+func (h *TaskHandler) ReassignTask(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.ReassignTaskRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ReassignTask(c.Context(), c.Params("task_id"), req, claims)
+	if err != nil {
+		return reassignError(c, err, "Failed to reassign task", "เปลี่ยนผู้รับผิดชอบไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Task reassigned",
+		MessageTH:  "เปลี่ยนผู้รับผิดชอบเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Check task stats drift
+// @Description เทียบ user_task_stats กับงานจริงและรายงานผู้ใช้ที่ตัวเลขไม่ตรง (admin เท่านั้น ไม่แก้ข้อมูล)
+// @Tags Tasks
+// @Produce json
+// @Param user_id query string false "User ID (ว่าง = ทุกคน)"
+// @Success 200 {object} dto.BaseResponse{data=dto.TaskStatsCheckResult}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/tasks/stats/drift [get]
+func (h *TaskHandler) CheckTaskStats(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	if claims.Role != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestTaskStats
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.CheckTaskStats(c.Context(), req.UserID)
+	if err != nil {
+		return taskStatsError(c, err, "Failed to check task stats", "ตรวจสอบสถิติงานไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Rebuild task stats
+// @Description นับสถิติงานของผู้ใช้ใหม่จาก tasks แล้วเขียนทับเฉพาะคนที่ไม่ตรง (admin เท่านั้น ไม่แตะ KPI)
+// @Tags Tasks
+// @Produce json
+// @Param user_id query string false "User ID (ว่าง = ทุกคน)"
+// @Success 200 {object} dto.BaseResponse{data=dto.TaskStatsCheckResult}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/tasks/stats/rebuild [post]
+func (h *TaskHandler) RebuildTaskStats(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	if claims.Role != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestTaskStats
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.RebuildTaskStats(c.Context(), req.UserID)
+	if err != nil {
+		return taskStatsError(c, err, "Failed to rebuild task stats", "สร้างสถิติงานใหม่ไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Task stats rebuilt",
+		MessageTH:  "สร้างสถิติงานใหม่เรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+func reassignError(c *fiber.Ctx, err error, messageEN, messageTH string) error {
+	statusCode := fiber.StatusBadRequest
+	messageEN = messageEN + ": " + err.Error()
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		statusCode = fiber.StatusNotFound
+		messageEN = "Task not found"
+		messageTH = "ไม่พบงาน"
+	case errors.Is(err, ports.ErrTaskReassignForbidden):
+		statusCode = fiber.StatusForbidden
+		messageEN = "Forbidden"
+		messageTH = "ห้ามเข้าถึง"
+	case errors.Is(err, ports.ErrTaskReassignConflict):
+		statusCode = fiber.StatusConflict
+		messageTH = "งานถูกเปลี่ยนผู้รับผิดชอบไปแล้ว กรุณาโหลดข้อมูลใหม่"
+	}
+	return c.Status(statusCode).JSON(dto.BaseResponse{
+		StatusCode: statusCode,
+		MessageEN:  messageEN,
+		MessageTH:  messageTH,
+		Status:     "error",
+		Data:       nil,
+	})
+}
+
+func taskStatsError(c *fiber.Ctx, err error, messageEN, messageTH string) error {
+	return c.Status(fiber.StatusInternalServerError).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusInternalServerError,
+		MessageEN:  messageEN + ": " + err.Error(),
+		MessageTH:  messageTH,
+		Status:     "error",
+		Data:       nil,
+	})
+}
+
+func checklistError(c *fiber.Ctx, err error, messageEN, messageTH string) error {
+	statusCode := fiber.StatusBadRequest
+	messageEN = messageEN + ": " + err.Error()
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		statusCode = fiber.StatusNotFound
+		messageEN = "Task, step or checklist item not found"
+		messageTH = "ไม่พบงาน ขั้นตอน หรือรายการตรวจสอบ"
+	case errors.Is(err, ports.ErrStepForbidden):
+		statusCode = fiber.StatusForbidden
+		messageEN = "Forbidden"
+		messageTH = "ห้ามเข้าถึง"
+	}
+	return c.Status(statusCode).JSON(dto.BaseResponse{
+		StatusCode: statusCode,
+		MessageEN:  messageEN,
+		MessageTH:  messageTH,
+		Status:     "error",
+		Data:       nil,
+	})
+}
@@ -0,0 +1,603 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/middleware"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type TimeEntryHandler struct {
+	svc ports.TimeEntryService
+	mdw *middleware.Middleware
+}
+
+func NewTimeEntryHandler(s ports.TimeEntryService, mdw *middleware.Middleware) *TimeEntryHandler {
+	return &TimeEntryHandler{svc: s, mdw: mdw}
+}
+
+func (h *TimeEntryHandler) TimeEntryRoutes(router fiber.Router) {
+	versionOne := router.Group("v1")
+	timeEntry := versionOne.Group("time-entry")
+
+	timeEntry.Post("/start", h.mdw.AuthCookieMiddleware(), h.StartTimer)
+	timeEntry.Put("/stop", h.mdw.AuthCookieMiddleware(), h.StopTimer)
+	timeEntry.Get("/running", h.mdw.AuthCookieMiddleware(), h.GetRunningTimer)
+	timeEntry.Post("/manual", h.mdw.AuthCookieMiddleware(), h.CreateManualEntry)
+	timeEntry.Get("/list", h.mdw.AuthCookieMiddleware(), h.ListTimeEntries)
+	timeEntry.Get("/report/estimate-vs-actual", h.mdw.AuthCookieMiddleware(), h.EstimateVsActual)
+	timeEntry.Get("/timesheet", h.mdw.AuthCookieMiddleware(), h.GetTimesheet)
+	timeEntry.Get("/timesheet/list", h.mdw.AuthCookieMiddleware(), h.ListTimesheets)
+	timeEntry.Post("/timesheet/submit", h.mdw.AuthCookieMiddleware(), h.SubmitTimesheet)
+	timeEntry.Put("/timesheet/:id/review", h.mdw.AuthCookieMiddleware(), h.ReviewTimesheet)
+	timeEntry.Put("/:id", h.mdw.AuthCookieMiddleware(), h.UpdateTimeEntry)
+}
+
+// @Summary Start timer
+// @Description เริ่มจับเวลาทำงานของ step (ได้ครั้งละหนึ่งตัวต่อผู้ใช้)
+// @Tags TimeEntry
+// @Accept json
+// @Produce json
+// @Param body body dto.StartTimerDTO true "StartTimerDTO"
+// @Success 201 {object} dto.BaseResponse{data=dto.TimeEntryDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/time-entry/start [post]
+func (h *TimeEntryHandler) StartTimer(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.StartTimerDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.StartTimer(c.Context(), req, claims)
+	if err != nil {
+		return timeEntryError(c, err, "Failed to start timer", "เริ่มจับเวลาไม่สำเร็จ")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusCreated,
+		MessageEN:  "Timer started",
+		MessageTH:  "เริ่มจับเวลาแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Stop timer
+// @Description หยุดจับเวลาที่กำลังทำงานอยู่ ถ้าจับเวลานานเกิน 12 ชั่วโมงต้องส่ง ended_at (เวลาหยุดจริง) มายืนยัน
+// @Tags TimeEntry
+// @Accept json
+// @Produce json
+// @Param body body dto.StopTimerDTO true "StopTimerDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.TimeEntryDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/time-entry/stop [put]
+func (h *TimeEntryHandler) StopTimer(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.StopTimerDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.StopTimer(c.Context(), req, claims)
+	if err != nil {
+		return timeEntryError(c, err, "Failed to stop timer", "หยุดจับเวลาไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Timer stopped",
+		MessageTH:  "หยุดจับเวลาแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Get running timer
+// @Description ตัวจับเวลาที่กำลังทำงานของผู้ใช้ (null ถ้าไม่มี)
+// @Tags TimeEntry
+// @Produce json
+// @Success 200 {object} dto.BaseResponse{data=dto.TimeEntryDTO}
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 500 {object} dto.BaseResponse
+// @Router /v1/time-entry/running [get]
+func (h *TimeEntryHandler) GetRunningTimer(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.GetRunningTimer(c.Context(), claims)
+	if err != nil {
+		return timeEntryError(c, err, "Failed to get running timer", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Create manual time entry
+// @Description บันทึกเวลาย้อนหลังด้วยตนเอง (started_at/ended_at หรือ work_date/hours)
+// @Tags TimeEntry
+// @Accept json
+// @Produce json
+// @Param body body dto.CreateManualTimeEntryDTO true "CreateManualTimeEntryDTO"
+// @Success 201 {object} dto.BaseResponse{data=dto.TimeEntryDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/time-entry/manual [post]
+func (h *TimeEntryHandler) CreateManualEntry(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.CreateManualTimeEntryDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.CreateManualEntry(c.Context(), req, claims)
+	if err != nil {
+		return timeEntryError(c, err, "Failed to create time entry", "บันทึกเวลาไม่สำเร็จ")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusCreated,
+		MessageEN:  "Time entry created",
+		MessageTH:  "บันทึกเวลาเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Update time entry
+// @Description แก้ไขรายการเวลา (ต้องระบุเหตุผล ระบบเก็บประวัติการแก้ไข)
+// @Tags TimeEntry
+// @Accept json
+// @Produce json
+// @Param id path string true "Entry ID"
+// @Param body body dto.UpdateTimeEntryDTO true "UpdateTimeEntryDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.TimeEntryDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Failure 409 {object} dto.BaseResponse
+// @Router /v1/time-entry/{id} [put]
+func (h *TimeEntryHandler) UpdateTimeEntry(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.UpdateTimeEntryDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.UpdateTimeEntry(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return timeEntryError(c, err, "Failed to update time entry", "แก้ไขรายการเวลาไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Time entry updated",
+		MessageTH:  "แก้ไขรายการเวลาเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary List time entries
+// @Description รายการเวลาที่บันทึก (ของตนเอง ของงานที่เกี่ยวข้อง หรือของพนักงานในแผนกที่ดูแล)
+// @Tags TimeEntry
+// @Produce json
+// @Param task_id query string false "Task ID"
+// @Param step_id query string false "Step ID"
+// @Param user_id query string false "User ID"
+// @Param from query string false "YYYY-MM-DD"
+// @Param to query string false "YYYY-MM-DD"
+// @Success 200 {object} dto.BaseResponse{data=[]dto.TimeEntryDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/time-entry/list [get]
+func (h *TimeEntryHandler) ListTimeEntries(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestListTimeEntries
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListTimeEntries(c.Context(), req, claims)
+	if err != nil {
+		return timeEntryError(c, err, "Failed to list time entries", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Estimate vs actual report
+// @Description เปรียบเทียบชั่วโมงประมาณการของ step กับเวลาที่ลงจริง แยกตาม workflow หรือผู้ใช้
+// @Tags TimeEntry
+// @Produce json
+// @Param group_by query string false "workflow|user"
+// @Param from query string false "YYYY-MM-DD (default 30 days ago)"
+// @Param to query string false "YYYY-MM-DD (default today)"
+// @Param department_id query string false "Department ID"
+// @Param workflow_id query string false "Workflow ID"
+// @Param user_id query string false "User ID"
+// @Success 200 {object} dto.BaseResponse{data=[]dto.EstimateVsActualDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/time-entry/report/estimate-vs-actual [get]
+func (h *TimeEntryHandler) EstimateVsActual(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestTimeReport
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.EstimateVsActual(c.Context(), req, claims)
+	if err != nil {
+		return timeEntryError(c, err, "Failed to build report", "ไม่สามารถสร้างรายงานได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Get weekly timesheet
+// @Description ใบลงเวลารายสัปดาห์ (จันทร์-อาทิตย์) พร้อมสถานะการอนุมัติ
+// @Tags TimeEntry
+// @Produce json
+// @Param user_id query string false "User ID (default self)"
+// @Param week query string false "Any date in the week YYYY-MM-DD"
+// @Success 200 {object} dto.BaseResponse{data=dto.TimesheetDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/time-entry/timesheet [get]
+func (h *TimeEntryHandler) GetTimesheet(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestTimesheet
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.GetTimesheet(c.Context(), req, claims)
+	if err != nil {
+		return timeEntryError(c, err, "Failed to get timesheet", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Submit weekly timesheet
+// @Description ส่งใบลงเวลาของสัปดาห์ให้ผู้จัดการอนุมัติ (หลังส่งจะแก้ไขเวลาในสัปดาห์นั้นไม่ได้)
+// @Tags TimeEntry
+// @Accept json
+// @Produce json
+// @Param body body dto.SubmitTimesheetDTO true "SubmitTimesheetDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.TimesheetDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Router /v1/time-entry/timesheet/submit [post]
+func (h *TimeEntryHandler) SubmitTimesheet(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.SubmitTimesheetDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.SubmitTimesheet(c.Context(), req, claims)
+	if err != nil {
+		return timeEntryError(c, err, "Failed to submit timesheet", "ส่งใบลงเวลาไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Timesheet submitted",
+		MessageTH:  "ส่งใบลงเวลาเรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Approve or reject timesheet
+// @Description ผู้จัดการแผนกหรือ admin อนุมัติ/ตีกลับใบลงเวลา (ตีกลับต้องระบุหมายเหตุ)
+// @Tags TimeEntry
+// @Accept json
+// @Produce json
+// @Param id path string true "Timesheet ID"
+// @Param body body dto.ReviewTimesheetDTO true "ReviewTimesheetDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.TimesheetDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/time-entry/timesheet/{id}/review [put]
+func (h *TimeEntryHandler) ReviewTimesheet(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.ReviewTimesheetDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ReviewTimesheet(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return timeEntryError(c, err, "Failed to review timesheet", "อนุมัติใบลงเวลาไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Timesheet reviewed",
+		MessageTH:  "บันทึกผลการตรวจใบลงเวลาแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary List timesheets
+// @Description รายการใบลงเวลาของแผนกที่ดูแล
+// @Tags TimeEntry
+// @Produce json
+// @Param department_id query string false "Department ID"
+// @Param status query string false "submitted|approved|rejected"
+// @Param week query string false "Any date in the week YYYY-MM-DD"
+// @Success 200 {object} dto.BaseResponse{data=[]dto.TimesheetDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Router /v1/time-entry/timesheet/list [get]
+func (h *TimeEntryHandler) ListTimesheets(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestListTimesheets
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListTimesheets(c.Context(), req, claims)
+	if err != nil {
+		return timeEntryError(c, err, "Failed to list timesheets", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+func timeEntryError(c *fiber.Ctx, err error, messageEN, messageTH string) error {
+	switch {
+	case errors.Is(err, ports.ErrTimeEntryForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, ports.ErrTimeEntryOverlap), errors.Is(err, ports.ErrTimerNeedsConfirmation):
+		return c.Status(fiber.StatusConflict).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusConflict,
+			MessageEN:  messageEN + ": " + err.Error(),
+			MessageTH:  messageTH,
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusNotFound,
+			MessageEN:  "Not found",
+			MessageTH:  "ไม่พบข้อมูล",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusBadRequest,
+		MessageEN:  messageEN + ": " + err.Error(),
+		MessageTH:  messageTH,
+		Status:     "error",
+		Data:       nil,
+	})
+}
@@ -55,6 +55,7 @@ func (h *UpLoadHandler) Upload(c *fiber.Ctx) error {
 		"health":     true, //หลักฐานการตรวจสุขภาพ
 		"military":   true, //หลักฐานการผ่านการเกณฑ์ทหาร
 		"criminal":   true, //หลักฐานการตรวจประวัติอาชญากรรม
+		"contract":   true, //สัญญาจ้าง
 		"other":      true, //โฟลเดอร์อัปโหลดทั่วไป
 	}
 	if !allowedFolders[folder] {
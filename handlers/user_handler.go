@@ -402,6 +402,17 @@ func (h *UserHandler) UpdateUserByID(c *fiber.Ctx) error {
 		})
 	}
 
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
 	var req dto.RequestUpdateUser
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
@@ -413,7 +424,7 @@ func (h *UserHandler) UpdateUserByID(c *fiber.Ctx) error {
 		})
 	}
 
-	updatedUser, err := h.svc.UpdateUserByID(context.Background(), id, req)
+	updatedUser, err := h.svc.UpdateUserByID(context.Background(), id, req, claims)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.BaseResponse{
 			StatusCode: fiber.StatusInternalServerError,
@@ -492,7 +503,7 @@ func (h *UserHandler) DeleteUserByID(c *fiber.Ctx) error {
 // @Accept multipart/form-data
 // @Produce json
 // @Param user_id formData string true "User ID"
-// @Param type formData string true "Document type (avatars = รูปโปรไฟล์ , idcards = หลักฐานสำเนาบัตรประชาชน, graduation = หลักฐานการจบการศึกษา, transcript = หลักฐานการศึกษา, resume = หลักฐานการสมัครงาน, health = หลักฐานการตรวจสุขภาพ, military = หลักฐานการผ่านการเกณฑ์ทหาร, criminal = หลักฐานการตรวจประวัติอาชญากรรม, other = โฟลเดอร์อัปโหลดทั่วไป)"
+// @Param type formData string true "Document type (avatars = รูปโปรไฟล์ , idcards = หลักฐานสำเนาบัตรประชาชน, graduation = หลักฐานการจบการศึกษา, transcript = หลักฐานการศึกษา, resume = หลักฐานการสมัครงาน, health = หลักฐานการตรวจสุขภาพ, military = หลักฐานการผ่านการเกณฑ์ทหาร, criminal = หลักฐานการตรวจประวัติอาชญากรรม, contract = สัญญาจ้าง, other = โฟลเดอร์อัปโหลดทั่วไป)"
 // @Param file formData file true "Document file"
 // @Success 200 {object} dto.BaseResponse
 // @Failure 400 {object} dto.BaseError400ResponseSwagger
@@ -513,6 +524,7 @@ func (h *UserHandler) UpdateDocuments(c *fiber.Ctx) error {
 		"health":     true,
 		"military":   true,
 		"criminal":   true,
+		"contract":   true,
 		"other":      true,
 	}
 	if !allowedTypes[req.Type] {
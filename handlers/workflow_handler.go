@@ -27,6 +27,10 @@ func (h *WorkFlowHandler) WorkFlowRoutes(router fiber.Router) {
 	workFlow.Get("/:id", h.mdw.AuthCookieMiddleware(), h.GetWorkflowByID)
 	workFlow.Put("/:id", h.mdw.AuthCookieMiddleware(), h.UpdateWorkflow)
 	workFlow.Delete("/:id", h.mdw.AuthCookieMiddleware(), h.DeleteWorkflow)
+	workFlow.Get("/:id/versions", h.mdw.AuthCookieMiddleware(), h.ListWorkflowVersions)
+	workFlow.Get("/:id/versions/:version", h.mdw.AuthCookieMiddleware(), h.GetWorkflowVersion)
+	workFlow.Get("/:id/diff", h.mdw.AuthCookieMiddleware(), h.DiffWorkflowVersions)
+	workFlow.Post("/:id/migrate", h.mdw.AuthCookieMiddleware(), h.MigrateWorkflowTasks)
 }
 
 // @Summary Create a new workflow
@@ -294,3 +298,211 @@ func (h *WorkFlowHandler) DeleteWorkflow(c *fiber.Ctx) error {
 		Data:       nil,
 	})
 }
+
+// @Summary List workflow versions
+// @Description ประวัติเวอร์ชันของ workflow template (แต่ละเวอร์ชันแก้ไขไม่ได้)
+// @Tags Workflow
+// @Produce json
+// @Param id path string true "Workflow ID"
+// @Success 200 {object} dto.BaseResponse{data=[]dto.WorkflowVersionDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/workflow/{id}/versions [get]
+func (h *WorkFlowHandler) ListWorkflowVersions(c *fiber.Ctx) error {
+	if _, err := middleware.GetClaims(c); err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.ListWorkflowVersions(c.Context(), c.Params("id"))
+	if err != nil {
+		return workflowError(c, err, "Failed to list workflow versions", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Get workflow version
+// @Description ดู workflow template ณ เวอร์ชันที่ระบุ
+// @Tags Workflow
+// @Produce json
+// @Param id path string true "Workflow ID"
+// @Param version path int true "Version"
+// @Success 200 {object} dto.BaseResponse{data=dto.WorkflowVersionDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/workflow/{id}/versions/{version} [get]
+func (h *WorkFlowHandler) GetWorkflowVersion(c *fiber.Ctx) error {
+	if _, err := middleware.GetClaims(c); err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	version, err := c.ParamsInt("version")
+	if err != nil || version <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid version",
+			MessageTH:  "เวอร์ชันไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.GetWorkflowVersion(c.Context(), c.Params("id"), version)
+	if err != nil {
+		return workflowError(c, err, "Failed to get workflow version", "ไม่สามารถดึงข้อมูลได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Diff workflow versions
+// @Description เทียบสองเวอร์ชันของ template: ฟิลด์ที่เปลี่ยน และ step ที่เพิ่ม/ตัด/แก้ไข
+// @Tags Workflow
+// @Produce json
+// @Param id path string true "Workflow ID"
+// @Param from query int false "From version (default: to-1)"
+// @Param to query int false "To version (default: latest)"
+// @Success 200 {object} dto.BaseResponse{data=dto.WorkflowVersionDiffDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/workflow/{id}/diff [get]
+func (h *WorkFlowHandler) DiffWorkflowVersions(c *fiber.Ctx) error {
+	if _, err := middleware.GetClaims(c); err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.RequestWorkflowDiff
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid query parameters",
+			MessageTH:  "พารามิเตอร์ไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.DiffWorkflowVersions(c.Context(), c.Params("id"), req)
+	if err != nil {
+		return workflowError(c, err, "Failed to diff workflow versions", "ไม่สามารถเทียบเวอร์ชันได้")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Success",
+		MessageTH:  "สำเร็จ",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+// @Summary Migrate tasks to another workflow version
+// @Description ย้ายงานที่ยังไม่เสร็จไปใช้เวอร์ชันใหม่ของ template โดยคงสถานะ step ที่ทำแล้ว (dry_run = แสดงผลก่อนบันทึก, admin เท่านั้น)
+// @Tags Workflow
+// @Accept json
+// @Produce json
+// @Param id path string true "Workflow ID"
+// @Param body body dto.MigrateWorkflowTasksDTO true "MigrateWorkflowTasksDTO"
+// @Success 200 {object} dto.BaseResponse{data=dto.WorkflowMigrationResultDTO}
+// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.BaseResponse
+// @Failure 403 {object} dto.BaseResponse
+// @Failure 404 {object} dto.BaseResponse
+// @Router /v1/workflow/{id}/migrate [post]
+func (h *WorkFlowHandler) MigrateWorkflowTasks(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusUnauthorized,
+			MessageEN:  "Unauthorized",
+			MessageTH:  "ไม่ได้รับอนุญาต",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	var req dto.MigrateWorkflowTasksDTO
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusBadRequest,
+			MessageEN:  "Invalid request payload",
+			MessageTH:  "ข้อมูลที่ส่งมาไม่ถูกต้อง",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+
+	result, err := h.svc.MigrateWorkflowTasks(c.Context(), c.Params("id"), req, claims)
+	if err != nil {
+		return workflowError(c, err, "Failed to migrate tasks", "ย้ายงานไปเวอร์ชันใหม่ไม่สำเร็จ")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusOK,
+		MessageEN:  "Workflow migration completed",
+		MessageTH:  "ย้ายงานไปเวอร์ชันใหม่เรียบร้อยแล้ว",
+		Status:     "success",
+		Data:       result,
+	})
+}
+
+func workflowError(c *fiber.Ctx, err error, messageEN, messageTH string) error {
+	switch {
+	case errors.Is(err, ports.ErrWorkflowForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusForbidden,
+			MessageEN:  "Forbidden",
+			MessageTH:  "ห้ามเข้าถึง",
+			Status:     "error",
+			Data:       nil,
+		})
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return c.Status(fiber.StatusNotFound).JSON(dto.BaseResponse{
+			StatusCode: fiber.StatusNotFound,
+			MessageEN:  "Not found",
+			MessageTH:  "ไม่พบข้อมูล",
+			Status:     "error",
+			Data:       nil,
+		})
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(dto.BaseResponse{
+		StatusCode: fiber.StatusBadRequest,
+		MessageEN:  messageEN + ": " + err.Error(),
+		MessageTH:  messageTH,
+		Status:     "error",
+		Data:       nil,
+	})
+}
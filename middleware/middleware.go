@@ -15,13 +15,19 @@ import (
 )
 
 type Middleware struct {
-	JWT config.JWTConfig
+	JWT           config.JWTConfig
+	accessRevoked func(userID string) bool
 }
 
 func NewMiddleware(jwtCfg config.JWTConfig) *Middleware {
 	return &Middleware{JWT: jwtCfg}
 }
 
+// SetAccessCheck ตั้งตัวตรวจผู้ใช้ที่ถูกยกเลิกสิทธิ์ (offboarding) token เดิมที่ยังไม่หมดอายุจะใช้ไม่ได้
+func (m *Middleware) SetAccessCheck(fn func(userID string) bool) {
+	m.accessRevoked = fn
+}
+
 // Replace previous standalone AuthCookieMiddleware with method version.
 func (m *Middleware) AuthCookieMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -56,6 +62,16 @@ func (m *Middleware) AuthCookieMiddleware() fiber.Handler {
 			})
 		}
 
+		if m.accessRevoked != nil && m.accessRevoked(claims.UserID) {
+			return c.Status(fiber.StatusUnauthorized).JSON(dto.BaseResponse{
+				StatusCode: fiber.StatusUnauthorized,
+				MessageEN:  "Access has been revoked",
+				MessageTH:  "สิทธิ์การเข้าใช้งานถูกยกเลิกแล้ว",
+				Status:     "error",
+				Data:       nil,
+			})
+		}
+
 		c.Locals("auth_claims", claims)
 		return c.Next()
 	}
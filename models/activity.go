@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+const CollectionActivities = "activities"
+
+// Activity เหตุการณ์ที่เกิดกับ task (เปลี่ยนสถานะ step/งาน, แก้ไขงาน, มอบหมาย) ใช้รวมกับความคิดเห็นเป็น feed
+type Activity struct {
+	CreatedAt  time.Time `bson:"created_at" json:"created_at"`
+	ActivityID string    `bson:"activity_id" json:"activity_id"`                   // รหัสเหตุการณ์ (UUID)
+	EntityType string    `bson:"entity_type" json:"entity_type"`                   // task
+	EntityID   string    `bson:"entity_id" json:"entity_id"`                       // รหัส task
+	JobID      string    `bson:"job_id,omitempty" json:"job_id,omitempty"`         // งานป้ายที่เกี่ยวข้อง
+	StepID     string    `bson:"step_id,omitempty" json:"step_id,omitempty"`       // step ที่เกี่ยวข้อง
+	StepName   string    `bson:"step_name,omitempty" json:"step_name,omitempty"`   // ชื่อ step ตอนเกิดเหตุการณ์
+	Type       string    `bson:"type" json:"type"`                                 // step_status|step_note|step_assigned|step_checklist|task_status|task_updated|task_reassigned
+	FromValue  string    `bson:"from_value,omitempty" json:"from_value,omitempty"` // ค่าเดิม (สถานะ/ผู้รับผิดชอบ/บันทึก)
+	ToValue    string    `bson:"to_value,omitempty" json:"to_value,omitempty"`     // ค่าใหม่
+	Message    string    `bson:"message,omitempty" json:"message,omitempty"`       // คำอธิบายเพิ่มเติม
+	ActorID    string    `bson:"actor_id" json:"actor_id"`                         // ผู้ทำรายการ
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+const CollectionAttachments = "attachments"
+
+// Attachment ไฟล์แนบที่ผูกกับเอกสารใดก็ได้ (งานป้าย, task, step, ใบเสร็จ, เจ้าหนี้, รายจ่าย)
+type Attachment struct {
+	CreatedAt      time.Time  `bson:"created_at" json:"created_at"`                           // วันที่อัปโหลด
+	DeletedAt      *time.Time `bson:"deleted_at" json:"deleted_at"`                           // วันที่ลบ (soft delete)
+	AttachmentID   string     `bson:"attachment_id" json:"attachment_id"`                     // รหัสไฟล์แนบ (UUID)
+	EntityType     string     `bson:"entity_type" json:"entity_type"`                         // sign_job|task|step|receipt|payable|expense
+	EntityID       string     `bson:"entity_id" json:"entity_id"`                             // รหัสเอกสารที่แนบ
+	ParentID       string     `bson:"parent_id,omitempty" json:"parent_id,omitempty"`         // รหัส task กรณีแนบกับ step
+	JobID          string     `bson:"job_id,omitempty" json:"job_id,omitempty"`               // งานป้ายที่เกี่ยวข้อง (ใช้รวม gallery ของงาน)
+	Category       string     `bson:"category" json:"category"`                               // photo|design|proof|document|invoice|other
+	Caption        string     `bson:"caption,omitempty" json:"caption,omitempty"`             // คำอธิบายรูป/ไฟล์
+	FileName       string     `bson:"file_name" json:"file_name"`                             // ชื่อไฟล์ต้นฉบับ
+	FileKey        string     `bson:"file_key" json:"file_key"`                               // key ใน storage
+	FileURL        string     `bson:"file_url" json:"file_url"`                               // ลิงก์ไฟล์
+	ThumbnailKey   string     `bson:"thumbnail_key,omitempty" json:"thumbnail_key,omitempty"` // key ของรูปย่อ (เฉพาะรูปภาพ)
+	ThumbnailURL   string     `bson:"thumbnail_url,omitempty" json:"thumbnail_url,omitempty"` // ลิงก์รูปย่อ
+	ContentType    string     `bson:"content_type" json:"content_type"`                       // MIME type
+	UploadedBy     string     `bson:"uploaded_by" json:"uploaded_by"`                         // ผู้อัปโหลด
+	UploadedByName string     `bson:"uploaded_by_name" json:"uploaded_by_name"`               // ชื่อผู้อัปโหลด
+	DeletedBy      string     `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`       // ผู้ลบ
+	Size           int64      `bson:"size" json:"size"`                                       // ขนาดไฟล์ (bytes)
+}
@@ -0,0 +1,129 @@
+package models
+
+import "time"
+
+const (
+	CollectionAttendanceSites   = "attendance_sites"
+	CollectionWorkShifts        = "work_shifts"
+	CollectionAttendanceRecords = "attendance_records"
+)
+
+// ประเภทสถานที่ลงเวลา
+const (
+	SiteShop    = "shop"    // หน้าร้าน/โรงงาน
+	SiteOffice  = "office"  // สำนักงาน
+	SiteInstall = "install" // หน้างานติดตั้งของใบงาน (ใช้ได้ระหว่างใบงานยังไม่ปิด)
+)
+
+// สถานะ OT
+const (
+	OTNone     = ""
+	OTPending  = "pending"  // รอผู้จัดการอนุมัติ
+	OTApproved = "approved" // อนุมัติแล้ว (ใช้คิดค่าล่วงเวลา)
+	OTRejected = "rejected"
+)
+
+// ที่มาของเวลาเข้า-ออก
+const (
+	PunchGPS        = "gps"        // ลงเวลาผ่านมือถือพร้อมพิกัด
+	PunchCorrection = "correction" // ผู้จัดการแก้ไข/บันทึกแทน
+)
+
+// AttendanceSite สถานที่ที่อนุญาตให้ลงเวลา (รัศมีรอบพิกัด)
+type AttendanceSite struct {
+	CreatedAt   time.Time  `bson:"created_at" json:"created_at"`             // วันที่สร้าง
+	UpdatedAt   time.Time  `bson:"updated_at" json:"updated_at"`             // วันที่แก้ไขล่าสุด
+	DeletedAt   *time.Time `bson:"deleted_at" json:"deleted_at"`             // วันที่ลบ (soft delete)
+	SiteID      string     `bson:"site_id" json:"site_id"`                   // รหัสสถานที่ (UUID)
+	Name        string     `bson:"name" json:"name"`                         // ชื่อสถานที่
+	SiteType    string     `bson:"site_type" json:"site_type"`               // shop|office|install
+	JobID       string     `bson:"job_id,omitempty" json:"job_id,omitempty"` // ใบงานของหน้างานติดตั้ง
+	Latitude    float64    `bson:"latitude" json:"latitude"`                 // ละติจูด
+	Longitude   float64    `bson:"longitude" json:"longitude"`               // ลองจิจูด
+	RadiusM     float64    `bson:"radius_m" json:"radius_m"`                 // รัศมีที่อนุญาต (เมตร)
+	Departments []string   `bson:"departments" json:"departments"`           // แผนกที่ใช้ได้ (ว่าง = ทุกแผนก)
+	IsActive    bool       `bson:"is_active" json:"is_active"`               // เปิดใช้งาน
+	CreatedBy   string     `bson:"created_by" json:"created_by"`             // ผู้สร้าง
+}
+
+// WorkShift กะการทำงาน กำหนดให้พนักงานรายคนหรือทั้งแผนก (รายคนใช้ก่อน, ไม่มี = กะเริ่มต้นของบริษัท)
+type WorkShift struct {
+	CreatedAt         time.Time  `bson:"created_at" json:"created_at"`                   // วันที่สร้าง
+	UpdatedAt         time.Time  `bson:"updated_at" json:"updated_at"`                   // วันที่แก้ไขล่าสุด
+	DeletedAt         *time.Time `bson:"deleted_at" json:"deleted_at"`                   // วันที่ลบ (soft delete)
+	ShiftID           string     `bson:"shift_id" json:"shift_id"`                       // รหัสกะ (UUID)
+	Name              string     `bson:"name" json:"name"`                               // ชื่อกะ
+	StartTime         string     `bson:"start_time" json:"start_time"`                   // เวลาเข้า HH:MM (เวลาไทย)
+	EndTime           string     `bson:"end_time" json:"end_time"`                       // เวลาเลิก HH:MM (น้อยกว่าเวลาเข้า = ข้ามวัน)
+	BreakMinutes      int        `bson:"break_minutes" json:"break_minutes"`             // เวลาพัก (นาที)
+	LateGraceMinutes  int        `bson:"late_grace_minutes" json:"late_grace_minutes"`   // ผ่อนผันมาสาย (นาที)
+	EarlyGraceMinutes int        `bson:"early_grace_minutes" json:"early_grace_minutes"` // ผ่อนผันออกก่อน (นาที)
+	OTMinMinutes      int        `bson:"ot_min_minutes" json:"ot_min_minutes"`           // OT ขั้นต่ำที่นับ (นาที)
+	WorkDays          []int      `bson:"work_days" json:"work_days"`                     // วันทำงาน (0=อาทิตย์ .. 6=เสาร์)
+	Departments       []string   `bson:"departments" json:"departments"`                 // แผนกที่ใช้กะนี้
+	Users             []string   `bson:"users" json:"users"`                             // พนักงานที่ใช้กะนี้ (มาก่อนแผนก)
+	IsDefault         bool       `bson:"is_default" json:"is_default"`                   // กะเริ่มต้นของบริษัท
+	UpdatedBy         string     `bson:"updated_by" json:"updated_by"`                   // ผู้แก้ไขล่าสุด
+}
+
+// AttendanceRecord การลงเวลาของพนักงานหนึ่งคนต่อหนึ่งวันทำงาน (วันของเวลาเข้ากะ)
+type AttendanceRecord struct {
+	CreatedAt    time.Time  `bson:"created_at" json:"created_at"`       // วันที่สร้าง
+	UpdatedAt    time.Time  `bson:"updated_at" json:"updated_at"`       // วันที่แก้ไขล่าสุด
+	DeletedAt    *time.Time `bson:"deleted_at" json:"deleted_at"`       // วันที่ลบ (soft delete)
+	WorkDate     time.Time  `bson:"work_date" json:"work_date"`         // วันทำงาน (00:00 UTC ของวันตามเวลาไทย)
+	RecordID     string     `bson:"record_id" json:"record_id"`         // รหัสรายการ (UUID)
+	UserID       string     `bson:"user_id" json:"user_id"`             // พนักงาน
+	DepartmentID string     `bson:"department_id" json:"department_id"` // แผนกของพนักงาน ณ วันนั้น
+
+	// สำเนากะ ณ วันนั้น (แก้กะภายหลังไม่กระทบย้อนหลัง)
+	ShiftID    string    `bson:"shift_id" json:"shift_id"`
+	ShiftName  string    `bson:"shift_name" json:"shift_name"`
+	ShiftStart time.Time `bson:"shift_start" json:"shift_start"`
+	ShiftEnd   time.Time `bson:"shift_end" json:"shift_end"`
+	Scheduled  bool      `bson:"scheduled" json:"scheduled"` // วันทำงานตามกะ (false = มาทำวันหยุด)
+
+	BreakMinutes      int `bson:"break_minutes" json:"break_minutes"`
+	LateGraceMinutes  int `bson:"late_grace_minutes" json:"late_grace_minutes"`
+	EarlyGraceMinutes int `bson:"early_grace_minutes" json:"early_grace_minutes"`
+	OTMinMinutes      int `bson:"ot_min_minutes" json:"ot_min_minutes"`
+
+	CheckIn  *AttendancePunch `bson:"check_in,omitempty" json:"check_in"`   // เวลาเข้า
+	CheckOut *AttendancePunch `bson:"check_out,omitempty" json:"check_out"` // เวลาออก (nil = ยังไม่ลงเวลาออก)
+
+	WorkedMinutes     int    `bson:"worked_minutes" json:"worked_minutes"`           // เวลาทำงานสุทธิ (หักพัก)
+	LateMinutes       int    `bson:"late_minutes" json:"late_minutes"`               // มาสาย
+	EarlyLeaveMinutes int    `bson:"early_leave_minutes" json:"early_leave_minutes"` // ออกก่อนเวลา
+	OvertimeMinutes   int    `bson:"overtime_minutes" json:"overtime_minutes"`       // OT ที่คำนวณได้
+	OTStatus          string `bson:"ot_status" json:"ot_status"`                     // pending|approved|rejected (ว่าง = ไม่มี OT)
+	OTApprovedMinutes int    `bson:"ot_approved_minutes" json:"ot_approved_minutes"` // OT ที่อนุมัติ (ใช้คิดเงินเดือน)
+	OTReason          string `bson:"ot_reason,omitempty" json:"ot_reason"`           // เหตุผลทำ OT จากพนักงาน
+	OTReviewedBy      string `bson:"ot_reviewed_by,omitempty" json:"ot_reviewed_by"`
+	OTReviewNote      string `bson:"ot_review_note,omitempty" json:"ot_review_note"`
+
+	Corrections []AttendanceCorrection `bson:"corrections,omitempty" json:"corrections"` // ประวัติการแก้ไขโดยผู้จัดการ
+}
+
+// AttendancePunch การลงเวลาหนึ่งครั้ง
+type AttendancePunch struct {
+	At        time.Time `bson:"at" json:"at"`                 // เวลาลงเวลา
+	Source    string    `bson:"source" json:"source"`         // gps|correction
+	Latitude  float64   `bson:"latitude" json:"latitude"`     // พิกัดที่ส่งมา
+	Longitude float64   `bson:"longitude" json:"longitude"`   // พิกัดที่ส่งมา
+	AccuracyM float64   `bson:"accuracy_m" json:"accuracy_m"` // ความแม่นยำ GPS (เมตร)
+	SiteID    string    `bson:"site_id" json:"site_id"`       // สถานที่ที่ตรงกับพิกัด
+	SiteName  string    `bson:"site_name" json:"site_name"`
+	DistanceM float64   `bson:"distance_m" json:"distance_m"` // ระยะจากจุดศูนย์กลางสถานที่
+	Note      string    `bson:"note,omitempty" json:"note"`
+}
+
+// AttendanceCorrection ประวัติการแก้ไขเวลาเข้า-ออก (ต้องระบุเหตุผลทุกครั้ง)
+type AttendanceCorrection struct {
+	CorrectedAt  time.Time  `bson:"corrected_at" json:"corrected_at"`
+	CorrectedBy  string     `bson:"corrected_by" json:"corrected_by"`
+	Reason       string     `bson:"reason" json:"reason"`
+	PrevCheckIn  *time.Time `bson:"prev_check_in" json:"prev_check_in"`
+	PrevCheckOut *time.Time `bson:"prev_check_out" json:"prev_check_out"`
+	CheckIn      *time.Time `bson:"check_in" json:"check_in"`
+	CheckOut     *time.Time `bson:"check_out" json:"check_out"`
+}
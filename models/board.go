@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+const CollectionBoardWIPLimits = "board_wip_limits" // ชื่อ collection เก็บ WIP limit ของคอลัมน์ kanban
+
+// BoardWIPLimit จำนวนงานสูงสุดของคอลัมน์บนบอร์ด (จำกัดงานที่ทำพร้อมกัน)
+// scope ด้วย workflow/แผนกได้ ว่าง = ใช้กับทุกบอร์ดที่จัดกลุ่มแบบเดียวกัน
+type BoardWIPLimit struct {
+	CreatedAt    time.Time  `bson:"created_at" json:"created_at"`                           // วันที่สร้าง
+	UpdatedAt    time.Time  `bson:"updated_at" json:"updated_at"`                           // วันที่อัปเดตล่าสุด
+	DeletedAt    *time.Time `bson:"deleted_at" json:"deleted_at"`                           // วันที่ลบ (soft delete)
+	LimitID      string     `bson:"limit_id" json:"limit_id"`                               // รหัส (UUID)
+	GroupBy      string     `bson:"group_by" json:"group_by"`                               // status|step|assignee
+	ColumnKey    string     `bson:"column_key" json:"column_key"`                           // ค่าคอลัมน์ (สถานะ/ชื่อ step/user_id)
+	WorkFlowID   string     `bson:"workflow_id,omitempty" json:"workflow_id,omitempty"`     // จำกัดเฉพาะ workflow (ว่าง = ทั้งหมด)
+	DepartmentID string     `bson:"department_id,omitempty" json:"department_id,omitempty"` // จำกัดเฉพาะแผนก (ว่าง = ทั้งหมด)
+	Limit        int        `bson:"limit" json:"limit"`                                     // จำนวนงานสูงสุดในคอลัมน์
+	UpdatedBy    string     `bson:"updated_by" json:"updated_by"`                           // ผู้ตั้งค่าล่าสุด
+}
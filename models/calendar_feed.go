@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+const CollectionCalendarFeeds = "calendar_feeds" // ชื่อ collection เก็บลิงก์ iCal ของผู้ใช้
+
+// CalendarFeed ลิงก์ปฏิทิน iCal ส่วนตัว (เข้าถึงด้วย token ลับ ไม่ต้อง login)
+// เก็บเฉพาะ hash ของ token; token จริงแสดงครั้งเดียวตอนสร้าง/เปลี่ยนใหม่
+type CalendarFeed struct {
+	CreatedAt    time.Time  `bson:"created_at" json:"created_at"`                           // วันที่สร้าง
+	UpdatedAt    time.Time  `bson:"updated_at" json:"updated_at"`                           // วันที่อัปเดตล่าสุด
+	RevokedAt    *time.Time `bson:"revoked_at" json:"revoked_at"`                           // วันที่ยกเลิก (nil = ใช้งานได้)
+	LastAccessAt *time.Time `bson:"last_access_at,omitempty" json:"last_access_at"`         // เวลาที่แอปปฏิทินดึงล่าสุด
+	FeedID       string     `bson:"feed_id" json:"feed_id"`                                 // รหัส (UUID)
+	UserID       string     `bson:"user_id" json:"user_id"`                                 // เจ้าของลิงก์
+	Name         string     `bson:"name" json:"name"`                                       // ชื่อปฏิทินที่แสดงในแอป
+	TokenHash    string     `bson:"token_hash" json:"-"`                                    // hash ของ token
+	TokenHint    string     `bson:"token_hint" json:"token_hint"`                           // 4 ตัวท้ายของ token (ไว้แยกลิงก์)
+	DepartmentID string     `bson:"department_id,omitempty" json:"department_id,omitempty"` // ผู้จัดการ: แสดงงานทั้งแผนก (ว่าง = งานของตนเอง)
+	IncludeSteps bool       `bson:"include_steps" json:"include_steps"`                     // แสดงกำหนดส่งของ step
+	IncludeJobs  bool       `bson:"include_jobs" json:"include_jobs"`                       // แสดงวันกำหนดส่ง/ติดตั้งงานป้าย
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+const (
+	CollectionDepartmentCapacities = "department_capacities"
+	CollectionHolidays             = "holidays"
+)
+
+// DepartmentCapacity กำลังการผลิตของแผนก ใช้คำนวณชั่วโมงที่ทำได้ต่อวัน (ชั่วโมง/คน/วัน x จำนวนคนในแผนก)
+type DepartmentCapacity struct {
+	CreatedAt    time.Time  `bson:"created_at" json:"created_at"`       // วันที่สร้าง
+	UpdatedAt    time.Time  `bson:"updated_at" json:"updated_at"`       // วันที่แก้ไขล่าสุด
+	DeletedAt    *time.Time `bson:"deleted_at" json:"deleted_at"`       // วันที่ลบ (soft delete)
+	CapacityID   string     `bson:"capacity_id" json:"capacity_id"`     // รหัสการตั้งค่า (UUID)
+	DepartmentID string     `bson:"department_id" json:"department_id"` // แผนก
+	UpdatedBy    string     `bson:"updated_by" json:"updated_by"`       // ผู้แก้ไขล่าสุด
+	WorkDays     []int      `bson:"work_days" json:"work_days"`         // วันทำงานในสัปดาห์ (0=อาทิตย์ .. 6=เสาร์)
+	HoursPerDay  float64    `bson:"hours_per_day" json:"hours_per_day"` // ชั่วโมงทำงานต่อคนต่อวัน
+	Efficiency   float64    `bson:"efficiency" json:"efficiency"`       // สัดส่วนเวลาที่ใช้ผลิตจริง (0-1) ค่าเริ่มต้น 1
+}
+
+// Holiday วันหยุด (ว่าง department_id = หยุดทั้งบริษัท)
+type Holiday struct {
+	Date         time.Time  `bson:"date" json:"date"`                                       // วันที่หยุด (00:00 UTC)
+	CreatedAt    time.Time  `bson:"created_at" json:"created_at"`                           // วันที่สร้าง
+	DeletedAt    *time.Time `bson:"deleted_at" json:"deleted_at"`                           // วันที่ลบ (soft delete)
+	HolidayID    string     `bson:"holiday_id" json:"holiday_id"`                           // รหัสวันหยุด (UUID)
+	Name         string     `bson:"name" json:"name"`                                       // ชื่อวันหยุด
+	DepartmentID string     `bson:"department_id,omitempty" json:"department_id,omitempty"` // แผนกที่หยุด (ว่าง = ทุกแผนก)
+	CreatedBy    string     `bson:"created_by" json:"created_by"`                           // ผู้สร้าง
+}
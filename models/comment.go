@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+const CollectionComments = "comments"
+
+// Comment ความคิดเห็นบน task (หรือ step ของ task) และงานป้าย ตอบกลับเป็น thread ได้
+type Comment struct {
+	CreatedAt     time.Time     `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time     `bson:"updated_at" json:"updated_at"`
+	DeletedAt     *time.Time    `bson:"deleted_at" json:"deleted_at"`                             // วันที่ลบ (soft delete, thread ยังอยู่)
+	CommentID     string        `bson:"comment_id" json:"comment_id"`                             // รหัสความคิดเห็น (UUID)
+	EntityType    string        `bson:"entity_type" json:"entity_type"`                           // task|sign_job
+	EntityID      string        `bson:"entity_id" json:"entity_id"`                               // รหัส task หรืองานป้าย
+	StepID        string        `bson:"step_id,omitempty" json:"step_id,omitempty"`               // step ที่พูดถึง (เฉพาะ task)
+	JobID         string        `bson:"job_id,omitempty" json:"job_id,omitempty"`                 // งานป้ายที่เกี่ยวข้อง (ใช้รวม feed ของงาน)
+	ParentID      string        `bson:"parent_id,omitempty" json:"parent_id,omitempty"`           // ตอบกลับความคิดเห็นใด
+	Body          string        `bson:"body" json:"body"`                                         // ข้อความ
+	Mentions      []string      `bson:"mentions,omitempty" json:"mentions,omitempty"`             // user_id ที่ถูก @mention
+	AttachmentIDs []string      `bson:"attachment_ids,omitempty" json:"attachment_ids,omitempty"` // ไฟล์แนบ (อัปโหลดผ่าน attachment)
+	AuthorID      string        `bson:"author_id" json:"author_id"`                               // ผู้เขียน
+	DeletedBy     string        `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`         // ผู้ลบ
+	Edits         []CommentEdit `bson:"edits,omitempty" json:"edits,omitempty"`                   // ประวัติการแก้ไข
+}
+
+type CommentEdit struct {
+	EditedAt     time.Time `bson:"edited_at" json:"edited_at"`
+	EditedBy     string    `bson:"edited_by" json:"edited_by"`
+	PrevBody     string    `bson:"prev_body" json:"prev_body"`
+	PrevMentions []string  `bson:"prev_mentions,omitempty" json:"prev_mentions,omitempty"`
+}
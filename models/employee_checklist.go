@@ -0,0 +1,105 @@
+package models
+
+import "time"
+
+const (
+	CollectionChecklistTemplates = "checklist_templates"
+	CollectionEmployeeChecklists = "employee_checklists"
+)
+
+// ประเภท checklist
+const (
+	ChecklistOnboarding  = "onboarding"  // รับพนักงานเข้า ต้องครบรายการบังคับก่อนอนุมัติผู้ใช้
+	ChecklistOffboarding = "offboarding" // พนักงานออก
+)
+
+// ผู้รับผิดชอบรายการ
+const (
+	ChecklistOwnerHR      = "hr"      // ฝ่ายบุคคล (admin)
+	ChecklistOwnerIT      = "it"      // ฝ่ายไอที (admin)
+	ChecklistOwnerManager = "manager" // ผู้จัดการแผนกของพนักงาน
+	ChecklistOwnerSystem  = "system"  // ระบบทำให้อัตโนมัติ
+)
+
+// สถานะ checklist ของพนักงาน
+const (
+	ChecklistOpen      = "open"      // ยังมีรายการบังคับค้าง
+	ChecklistCompleted = "completed" // รายการบังคับครบแล้ว
+)
+
+// สถานะรายการ
+const (
+	ChecklistItemPending = "pending"
+	ChecklistItemDone    = "done"
+)
+
+// รายการที่ระบบตรวจจากข้อมูลจริง (auto_check) ติ๊กเองไม่ได้จนกว่าข้อมูลจะครบ
+const (
+	ChecklistCheckIDCard         = "id_card"          // เลขบัตรประชาชน + ไฟล์สำเนาบัตร (documents type idcards)
+	ChecklistCheckBankInfo       = "bank_info"        // ธนาคาร เลขบัญชี และชื่อบัญชี
+	ChecklistCheckDocumentPrefix = "document:"        // document:<type> มีไฟล์เอกสารประเภทนั้น เช่น document:contract
+	ChecklistCheckTasksReassign  = "tasks_reassigned" // (offboarding) โอนงานที่ยังไม่ปิดแล้ว
+	ChecklistCheckAccessRevoked  = "access_revoked"   // (offboarding) ยกเลิกสิทธิ์เข้าระบบแล้ว
+)
+
+// ChecklistTemplate แม่แบบ checklist ต่อตำแหน่ง (position_id ว่าง = ใช้กับทุกตำแหน่งที่ไม่มีแม่แบบของตัวเอง)
+type ChecklistTemplate struct {
+	CreatedAt  time.Time               `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time               `bson:"updated_at" json:"updated_at"`
+	DeletedAt  *time.Time              `bson:"deleted_at" json:"deleted_at"`
+	TemplateID string                  `bson:"template_id" json:"template_id"` // รหัสแม่แบบ (UUID)
+	Kind       string                  `bson:"kind" json:"kind"`               // onboarding|offboarding
+	PositionID string                  `bson:"position_id" json:"position_id"` // ตำแหน่ง (ว่าง = ค่าเริ่มต้นของบริษัท)
+	Name       string                  `bson:"name" json:"name"`
+	Items      []ChecklistTemplateItem `bson:"items" json:"items"`
+	UpdatedBy  string                  `bson:"updated_by" json:"updated_by"`
+}
+
+// ChecklistTemplateItem รายการในแม่แบบ
+type ChecklistTemplateItem struct {
+	ItemID      string `bson:"item_id" json:"item_id"`
+	Title       string `bson:"title" json:"title"`
+	Description string `bson:"description,omitempty" json:"description"`
+	Owner       string `bson:"owner" json:"owner"`                     // hr|it|manager|system
+	Mandatory   bool   `bson:"mandatory" json:"mandatory"`             // บังคับ (onboarding: ต้องครบก่อนอนุมัติผู้ใช้)
+	DueDays     int    `bson:"due_days" json:"due_days"`               // กำหนดเสร็จนับจากวันเริ่มงาน/วันทำงานวันสุดท้าย (ติดลบ = ก่อนวันนั้น)
+	AutoCheck   string `bson:"auto_check,omitempty" json:"auto_check"` // ตรวจจากข้อมูลจริง (ดู ChecklistCheck*)
+}
+
+// EmployeeChecklist checklist ของพนักงานหนึ่งคน (สำเนารายการจากแม่แบบ ณ วันที่สร้าง)
+type EmployeeChecklist struct {
+	CreatedAt       time.Time       `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time       `bson:"updated_at" json:"updated_at"`
+	DeletedAt       *time.Time      `bson:"deleted_at" json:"deleted_at"`
+	StartDate       time.Time       `bson:"start_date" json:"start_date"` // วันเริ่มงาน (onboarding) หรือวันทำงานวันสุดท้าย (offboarding)
+	CompletedAt     *time.Time      `bson:"completed_at,omitempty" json:"completed_at"`
+	AccessRevokedAt *time.Time      `bson:"access_revoked_at,omitempty" json:"access_revoked_at"` // (offboarding) ยกเลิกสิทธิ์เมื่อ
+	ChecklistID     string          `bson:"checklist_id" json:"checklist_id"`                     // รหัส checklist (UUID)
+	Kind            string          `bson:"kind" json:"kind"`                                     // onboarding|offboarding
+	UserID          string          `bson:"user_id" json:"user_id"`
+	TemplateID      string          `bson:"template_id" json:"template_id"` // ว่าง = ใช้รายการมาตรฐานของระบบ
+	PositionID      string          `bson:"position_id" json:"position_id"`
+	DepartmentID    string          `bson:"department_id" json:"department_id"`
+	Status          string          `bson:"status" json:"status"` // open|completed
+	Items           []ChecklistItem `bson:"items" json:"items"`
+	ReassignTo      string          `bson:"reassign_to,omitempty" json:"reassign_to"` // (offboarding) ผู้รับงานที่ยังไม่ปิด
+	ReassignedTasks int             `bson:"reassigned_tasks" json:"reassigned_tasks"` // (offboarding) จำนวนงานที่โอนแล้ว
+	Note            string          `bson:"note,omitempty" json:"note"`
+	CreatedBy       string          `bson:"created_by" json:"created_by"` // ว่าง = ระบบ (สมัครสมาชิก)
+}
+
+// ChecklistItem รายการของพนักงาน
+type ChecklistItem struct {
+	DueDate     time.Time  `bson:"due_date" json:"due_date"`
+	CompletedAt *time.Time `bson:"completed_at,omitempty" json:"completed_at"`
+	ItemID      string     `bson:"item_id" json:"item_id"`
+	Title       string     `bson:"title" json:"title"`
+	Description string     `bson:"description,omitempty" json:"description"`
+	Owner       string     `bson:"owner" json:"owner"`
+	OwnerUserID string     `bson:"owner_user_id,omitempty" json:"owner_user_id"` // ผู้รับผิดชอบ (manager = ผู้จัดการแผนก)
+	Mandatory   bool       `bson:"mandatory" json:"mandatory"`
+	AutoCheck   string     `bson:"auto_check,omitempty" json:"auto_check"`
+	Status      string     `bson:"status" json:"status"`                       // pending|done
+	CompletedBy string     `bson:"completed_by,omitempty" json:"completed_by"` // "system" = ระบบตรวจพบข้อมูลครบ
+	Note        string     `bson:"note,omitempty" json:"note"`
+}
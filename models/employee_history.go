@@ -4,15 +4,35 @@ import "time"
 
 const CollectionEmploymentHistories = "employment_histories"
 
+// สถานะรายการประวัติการจ้างงาน
+const (
+	EmploymentApplied   = "applied"   // มีผลแล้ว (ข้อมูลพนักงานตรงกับรายการล่าสุดที่ถึงวันมีผล)
+	EmploymentScheduled = "scheduled" // มีผลในอนาคต รอ cron ปรับข้อมูลพนักงานเมื่อถึงวัน
+)
+
+// ประเภทการเปลี่ยนแปลง
+const (
+	EmploymentChangeHire   = "hire"   // เริ่มงาน (รายการแรก)
+	EmploymentChangeUpdate = "change" // เปลี่ยนตำแหน่ง/แผนก/ประเภทการจ้าง
+)
+
+// EmploymentHistory ช่วงเวลาที่พนักงานอยู่ในตำแหน่ง/แผนก/ประเภทการจ้างหนึ่ง ๆ
+// รายการต่อกันเป็นลำดับ ToDate ของรายการก่อน = FromDate ของรายการถัดไป (ไม่รวมวันนั้น)
+// สำเนาล่าสุดเก็บไว้ที่ User.EmploymentHistory ด้วย
 type EmploymentHistory struct {
 	FromDate       time.Time  `bson:"from_date" json:"from_date"` // วันที่เริ่มต้น
 	CreatedAt      time.Time  `bson:"created_at" json:"created_at"`
 	UpdatedAt      time.Time  `bson:"updated_at" json:"updated_at"`
 	ToDate         *time.Time `bson:"to_date,omitempty" json:"to_date"`       // วันที่สิ้นสุด (nullable ถ้ายังทำอยู่)
 	DeletedAt      *time.Time `bson:"deleted_at,omitempty" json:"deleted_at"` // soft delete
+	AppliedAt      *time.Time `bson:"applied_at,omitempty" json:"applied_at"` // วันที่ปรับข้อมูลพนักงานตามรายการนี้
+	HistoryID      string     `bson:"history_id" json:"history_id"`           // รหัสรายการ (UUID)
 	UserID         string     `bson:"user_id" json:"user_id"`                 // รหัสผู้ใช้ที่เกี่ยวข้อง
 	PositionID     string     `bson:"position_id" json:"position_id"`         // ตำแหน่งในช่วงเวลานั้น
 	DepartmentID   string     `bson:"department_id" json:"department_id"`     // แผนกในช่วงเวลานั้น
 	EmploymentType string     `bson:"employment_type" json:"employment_type"` // ประเภทการจ้าง (เช่น full-time, intern)
+	ChangeType     string     `bson:"change_type" json:"change_type"`         // hire|change
+	Status         string     `bson:"status" json:"status"`                   // applied|scheduled
+	ChangedBy      string     `bson:"changed_by,omitempty" json:"changed_by"` // ผู้บันทึก (ว่าง = ระบบ)
 	Note           string     `bson:"note,omitempty" json:"note,omitempty"`   // หมายเหตุ (ถ้ามี)
 }
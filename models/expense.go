@@ -17,6 +17,7 @@ type Expense struct {
 	Currency              string     `bson:"currency" json:"currency"`                                 // เช่น "THB"
 	PaymentMethod         string     `bson:"payment_method,omitempty" json:"payment_method,omitempty"` //เช่น "cash", "transfer", "credit_card"
 	ReferenceNo           string     `bson:"reference_no,omitempty" json:"reference_no,omitempty"`     // เช่น เลขใบเสร็จ / หมายเลขธุรกรรมธนาคาร
+	JobID                 string     `bson:"job_id,omitempty" json:"job_id,omitempty"`                 // ผูกกับงานป้าย (ใช้คำนวณต้นทุนงาน)
 	CreatedBy             string     `bson:"created_by" json:"created_by"`
 	Amount                float64    `bson:"amount" json:"amount"` // จำนวนเงิน
 }
@@ -17,6 +17,8 @@ type Income struct {
 	Currency              string     `bson:"currency" json:"currency"`                                 // เช่น "THB"
 	PaymentMethod         string     `bson:"payment_method,omitempty" json:"payment_method,omitempty"` //เช่น "cash", "transfer", "credit_card"
 	ReferenceNo           string     `bson:"reference_no,omitempty" json:"reference_no,omitempty"`     // เช่น เลขใบเสร็จ / หมายเลขธุรกรรมธนาคาร
+	ReversalOf            string     `bson:"reversal_of,omitempty" json:"reversal_of,omitempty"`       // income_id ที่ถูกกลับรายการ (รายการนี้จะมียอดติดลบ)
+	JobID                 string     `bson:"job_id,omitempty" json:"job_id,omitempty"`                 // งานป้ายที่รายได้นี้ผูกอยู่ (ใช้กลับรายการตอนยกเลิกงาน)
 	CreatedBy             string     `bson:"created_by" json:"created_by"`
 	Amount                float64    `bson:"amount" json:"amount"` // จำนวนเงิน
 }
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+const CollectionJobMaterials = "job_materials"
+const CollectionLaborRates = "labor_rates"
+
+// JobMaterial วัสดุที่เบิกใช้จริงในงานป้าย
+type JobMaterial struct {
+	UsedAt     time.Time  `bson:"used_at" json:"used_at"`                           // วันที่เบิกใช้
+	CreatedAt  time.Time  `bson:"created_at" json:"created_at"`                     // วันที่สร้าง
+	UpdatedAt  time.Time  `bson:"updated_at" json:"updated_at"`                     // วันที่แก้ไขล่าสุด
+	DeletedAt  *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"` // วันที่ลบ
+	MaterialID string     `bson:"material_id" json:"material_id"`                   // รหัสรายการวัสดุ
+	JobID      string     `bson:"job_id" json:"job_id"`                             // รหัสงานป้าย
+	Name       string     `bson:"name" json:"name"`                                 // ชื่อวัสดุ
+	Unit       string     `bson:"unit" json:"unit"`                                 // หน่วยนับ เช่น แผ่น, เมตร
+	Note       string     `bson:"note" json:"note"`                                 // หมายเหตุ
+	CreatedBy  string     `bson:"created_by" json:"created_by"`                     // ผู้บันทึก
+	Quantity   float64    `bson:"quantity" json:"quantity"`                         // จำนวนที่ใช้
+	UnitCost   float64    `bson:"unit_cost" json:"unit_cost"`                       // ต้นทุนต่อหน่วย
+	Total      float64    `bson:"total" json:"total"`                               // ต้นทุนรวม (quantity * unit_cost)
+}
+
+// LaborRate อัตราค่าแรงต่อชั่วโมง กำหนดได้ทั้งรายบุคคล (user_id) หรือรายตำแหน่ง (position_id)
+// ถ้ามีทั้งสองแบบ จะใช้อัตรารายบุคคลก่อน
+type LaborRate struct {
+	CreatedAt  time.Time  `bson:"created_at" json:"created_at"`                       // วันที่สร้าง
+	UpdatedAt  time.Time  `bson:"updated_at" json:"updated_at"`                       // วันที่แก้ไขล่าสุด
+	DeletedAt  *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`   // วันที่ลบ
+	RateID     string     `bson:"rate_id" json:"rate_id"`                             // รหัสอัตราค่าแรง
+	UserID     string     `bson:"user_id,omitempty" json:"user_id,omitempty"`         // ผู้ใช้ (กรณีกำหนดรายบุคคล)
+	PositionID string     `bson:"position_id,omitempty" json:"position_id,omitempty"` // ตำแหน่ง (กรณีกำหนดรายตำแหน่ง)
+	CreatedBy  string     `bson:"created_by" json:"created_by"`                       // ผู้สร้าง
+	HourlyRate float64    `bson:"hourly_rate" json:"hourly_rate"`                     // ค่าแรงต่อชั่วโมง (บาท)
+}
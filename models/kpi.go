@@ -7,17 +7,36 @@ import (
 const CollectionKPITemplates = "kpi_templates"
 
 type KPITemplate struct {
-	CreatedAt   time.Time         `bson:"created_at" json:"created_at"`
-	UpdatedAt   time.Time         `bson:"updated_at" json:"updated_at"`
-	DeletedAt   *time.Time        `bson:"deleted_at" json:"deleted_at"`
-	KPIID       string            `bson:"kpi_id" json:"kpi_id"`
-	KPIName     string            `bson:"kpi_name" json:"kpi_name"`
-	Department  string            `bson:"department_id" json:"department_id"`
-	CreatedBy   string            `bson:"created_by" json:"created_by"`
-	Items       []KPITemplateItem `bson:"items" json:"items"`
-	TotalWeight int               `bson:"total_weight" json:"total_weight"`
-	Version     int               `bson:"version" json:"version"`
-	IsActive    bool              `bson:"is_active" json:"is_active"`
+	CreatedAt     time.Time         `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time         `bson:"updated_at" json:"updated_at"`
+	DeletedAt     *time.Time        `bson:"deleted_at" json:"deleted_at"`
+	KPIID         string            `bson:"kpi_id" json:"kpi_id"`
+	KPIName       string            `bson:"kpi_name" json:"kpi_name"`
+	Department    string            `bson:"department_id" json:"department_id"`
+	CreatedBy     string            `bson:"created_by" json:"created_by"`
+	Items         []KPITemplateItem `bson:"items" json:"items"`
+	TotalWeight   int               `bson:"total_weight" json:"total_weight"`
+	ScoringMethod string            `bson:"scoring_method,omitempty" json:"scoring_method,omitempty"` // weighted|average|bands (ว่าง = weighted)
+	Bands         []KPIRatingBand   `bson:"bands,omitempty" json:"bands,omitempty"`                   // ช่วงเกรด (ใช้เมื่อ scoring_method = bands)
+	Version       int               `bson:"version" json:"version"`
+	IsActive      bool              `bson:"is_active" json:"is_active"`
+
+	Evaluator KPIEvaluatorPolicy `bson:"evaluator,omitempty" json:"evaluator"` // กฎเลือกผู้ประเมินและการติดตามแบบประเมินที่สร้างอัตโนมัติ
+}
+
+// กฎเลือกผู้ประเมินของแบบประเมินที่สร้างอัตโนมัติเมื่องานเสร็จ
+const (
+	KPIEvaluatorDepartmentManager = "department_manager" // ผู้จัดการแผนกของผู้ถูกประเมิน (ค่าเริ่มต้น)
+	KPIEvaluatorTaskCreator       = "task_creator"       // ผู้สร้างงาน
+	KPIEvaluatorReviewer          = "reviewer"           // ผู้ประเมินที่ระบุไว้ใน template
+)
+
+// KPIEvaluatorPolicy ถ้าผู้ประเมินตามกฎว่างหรือเป็นคนเดียวกับผู้ถูกประเมิน จะลองผู้จัดการแผนก แล้วผู้สร้างงานตามลำดับ
+type KPIEvaluatorPolicy struct {
+	Rule          string `bson:"rule" json:"rule"`                                   // department_manager|task_creator|reviewer (ว่าง = department_manager)
+	ReviewerID    string `bson:"reviewer_id,omitempty" json:"reviewer_id,omitempty"` // ผู้ประเมินที่ระบุ (ใช้กับ rule = reviewer)
+	ReminderDays  int    `bson:"reminder_days" json:"reminder_days"`                 // เตือนผู้ประเมินทุกกี่วันที่ยังค้าง (0 = ไม่เตือน)
+	AutoCloseDays int    `bson:"auto_close_days" json:"auto_close_days"`             // ปิดแบบประเมินที่ค้างเกินกี่วันนับจากวันสร้าง (0 = ไม่ปิด)
 }
 
 type KPITemplateItem struct {
@@ -31,3 +50,10 @@ type KPITemplateItem struct {
 	MaxScore    int        `bson:"max_score" json:"max_score"`
 	Weight      int        `bson:"weight" json:"weight"`
 }
+
+// KPIRatingBand ช่วงเกรดของคะแนน เช่น A >= 90% ได้ 100 คะแนน
+type KPIRatingBand struct {
+	Grade      string  `bson:"grade" json:"grade"`             // เกรด เช่น A, B, C, D
+	MinPercent float64 `bson:"min_percent" json:"min_percent"` // เปอร์เซ็นต์ขั้นต่ำของช่วง (0..100)
+	Score      float64 `bson:"score" json:"score"`             // คะแนนที่ได้เมื่ออยู่ในช่วงนี้ (0..100)
+}
@@ -5,22 +5,96 @@ import "time"
 const CollectionKPIEvaluations = "kpi_evaluations"
 
 type KPIEvaluation struct {
-	CreatedAt    time.Time  `bson:"created_at" json:"created_at"`
-	UpdatedAt    time.Time  `bson:"updated_at" json:"updated_at"`
-	DeletedAt    *time.Time `json:"deleted_at" bson:"deleted_at"`               // วันที่ลบ (ถ้ามี)
-	EvaluationID string     `bson:"evaluation_id" json:"evaluation_id"`         // UUID
-	ProjectID    string     `bson:"project_id" json:"project_id"`               // อ้างถึง Project
-	JobID        string     `bson:"job_id" json:"job_id"`                       // อ้างถึง SignJob
-	TaskID       string     `bson:"task_id,omitempty" json:"task_id,omitempty"` // ถ้ามีงานย่อย
-	KPIID        string     `bson:"kpi_id" json:"kpi_id"`                       // อ้างถึง KPITemplate
-	EvaluatorID  string     `bson:"evaluator_id" json:"evaluator_id"`           // ใครประเมิน
-	EvaluateeID  string     `bson:"evaluatee_id" json:"evaluatee_id"`           // ใครถูกประเมิน (เช่น assignee)
-	Department   string     `bson:"department_id" json:"department_id"`         // แผนก
-	Feedback     string     `bson:"feedback" json:"feedback"`                   // คอมเมนต์รวม
-	Scores       []KPIScore `bson:"scores" json:"scores"`                       // รายการคะแนนแต่ละ item
-	Version      int        `bson:"version" json:"version"`                     // ใช้ version ของ KPI template ตอนนั้น
-	TotalScore   float64    `bson:"total_score" json:"total_score"`             // รวมคะแนน
-	IsEvaluated  bool       `bson:"is_evaluated" json:"is_evaluated"`           // ประเมินแล้วหรือยัง
+	CreatedAt     time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time  `bson:"updated_at" json:"updated_at"`
+	DeletedAt     *time.Time `json:"deleted_at" bson:"deleted_at"`                             // วันที่ลบ (ถ้ามี)
+	EvaluationID  string     `bson:"evaluation_id" json:"evaluation_id"`                       // UUID
+	ProjectID     string     `bson:"project_id" json:"project_id"`                             // อ้างถึง Project
+	JobID         string     `bson:"job_id" json:"job_id"`                                     // อ้างถึง SignJob
+	TaskID        string     `bson:"task_id,omitempty" json:"task_id,omitempty"`               // ถ้ามีงานย่อย
+	StepIDs       []string   `bson:"step_ids,omitempty" json:"step_ids,omitempty"`             // step ที่ผู้ถูกประเมินรับผิดชอบในงานนี้
+	KPIID         string     `bson:"kpi_id" json:"kpi_id"`                                     // อ้างถึง KPITemplate
+	EvaluatorID   string     `bson:"evaluator_id" json:"evaluator_id"`                         // ใครประเมิน
+	EvaluateeID   string     `bson:"evaluatee_id" json:"evaluatee_id"`                         // ใครถูกประเมิน (เช่น assignee)
+	Department    string     `bson:"department_id" json:"department_id"`                       // แผนก
+	Feedback      string     `bson:"feedback" json:"feedback"`                                 // คอมเมนต์รวม
+	Scores        []KPIScore `bson:"scores" json:"scores"`                                     // รายการคะแนนแต่ละ item
+	Version       int        `bson:"version" json:"version"`                                   // ใช้ version ของ KPI template ตอนนั้น
+	TotalScore    float64    `bson:"total_score" json:"total_score"`                           // คะแนนรวม 0..100 ตามวิธีคิดของ template
+	Percent       float64    `bson:"percent,omitempty" json:"percent,omitempty"`               // เปอร์เซ็นต์ที่ทำได้จริง (ก่อนตัดเกรด)
+	Grade         string     `bson:"grade,omitempty" json:"grade,omitempty"`                   // เกรด (วิธี bands)
+	ScoringMethod string     `bson:"scoring_method,omitempty" json:"scoring_method,omitempty"` // วิธีคิดคะแนนที่ใช้ครั้งล่าสุด
+	IsEvaluated   bool       `bson:"is_evaluated" json:"is_evaluated"`                         // ประเมินแล้วหรือยัง
+
+	// คะแนนด้านบน (scores/total_score) เป็นผลของผู้ประเมินหลัก (manager) ใช้คิด KPI
+	// ส่วนการประเมินตนเองและเพื่อนร่วมงานเก็บแยกไว้ประกอบ ไม่นำไปคิด KPI
+	SelfReview  *KPIRoleReview   `bson:"self_review,omitempty" json:"self_review,omitempty"`   // ผู้ถูกประเมินประเมินตนเอง
+	PeerIDs     []string         `bson:"peer_ids,omitempty" json:"peer_ids,omitempty"`         // เพื่อนร่วมงานที่ถูกเชิญให้ประเมิน
+	PeerReviews []KPIRoleReview  `bson:"peer_reviews,omitempty" json:"peer_reviews,omitempty"` // ผลประเมินจากเพื่อนร่วมงาน (คนละหนึ่งชุด)
+	Revisions   []KPIRevision    `bson:"revisions,omitempty" json:"revisions,omitempty"`       // ประวัติคะแนนทุกครั้งที่ส่ง (ไม่ถูกเขียนทับ)
+	AckStatus   string           `bson:"ack_status,omitempty" json:"ack_status,omitempty"`     // pending|acknowledged|disputed|resolved
+	AckComment  string           `bson:"ack_comment,omitempty" json:"ack_comment,omitempty"`   // ความเห็นตอนรับทราบผล
+	AckAt       *time.Time       `bson:"ack_at,omitempty" json:"ack_at,omitempty"`             // เวลารับทราบผล
+	Disputes    []KPIEvalDispute `bson:"disputes,omitempty" json:"disputes,omitempty"`         // การโต้แย้งคะแนน (รายการล่าสุดคือรายการปัจจุบัน)
+
+	// การมอบหมายผู้ประเมินหลักและการติดตามแบบประเมินที่ค้าง
+	EvaluatorRule string     `bson:"evaluator_rule,omitempty" json:"evaluator_rule,omitempty"` // กฎที่ใช้เลือกผู้ประเมิน (manual = กำหนดเอง)
+	AssignedAt    *time.Time `bson:"assigned_at,omitempty" json:"assigned_at,omitempty"`       // เวลามอบหมายผู้ประเมิน
+	RemindedAt    *time.Time `bson:"reminded_at,omitempty" json:"reminded_at,omitempty"`       // ส่งเตือนผู้ประเมินล่าสุด
+	ReminderCount int        `bson:"reminder_count,omitempty" json:"reminder_count,omitempty"` // จำนวนครั้งที่เตือน
+	ClosedAt      *time.Time `bson:"closed_at,omitempty" json:"closed_at,omitempty"`           // ปิดโดยไม่ประเมิน (ไม่นำไปคิด KPI)
+	CloseReason   string     `bson:"close_reason,omitempty" json:"close_reason,omitempty"`     // เหตุผลที่ปิด
+}
+
+// บทบาทผู้ประเมิน
+const (
+	KPIRoleSelf    = "self"
+	KPIRoleManager = "manager"
+	KPIRolePeer    = "peer"
+)
+
+// สถานะการรับทราบผลของผู้ถูกประเมิน
+const (
+	KPIAckPending      = "pending"      // ประเมินแล้ว รอผู้ถูกประเมินรับทราบ
+	KPIAckAcknowledged = "acknowledged" // รับทราบผลแล้ว
+	KPIAckDisputed     = "disputed"     // โต้แย้งคะแนน รอผู้จัดการ/HR พิจารณา
+	KPIAckResolved     = "resolved"     // พิจารณาข้อโต้แย้งแล้ว
+)
+
+// KPIRoleReview ชุดคะแนนของผู้ประเมินแต่ละบทบาท (self/peer)
+type KPIRoleReview struct {
+	EvaluatorID string     `bson:"evaluator_id" json:"evaluator_id"`
+	Scores      []KPIScore `bson:"scores" json:"scores"`
+	Feedback    string     `bson:"feedback" json:"feedback"`
+	TotalScore  float64    `bson:"total_score" json:"total_score"` // คิดด้วยวิธีเดียวกับ template
+	Percent     float64    `bson:"percent" json:"percent"`
+	Grade       string     `bson:"grade,omitempty" json:"grade,omitempty"`
+	SubmittedAt time.Time  `bson:"submitted_at" json:"submitted_at"`
+}
+
+// KPIRevision สำเนาคะแนนทุกครั้งที่มีการส่ง/แก้ไข
+type KPIRevision struct {
+	Version     int        `bson:"version" json:"version"` // version ของการประเมินหลังแก้ไขครั้งนี้
+	Role        string     `bson:"role" json:"role"`       // self|manager|peer
+	EvaluatorID string     `bson:"evaluator_id" json:"evaluator_id"`
+	Scores      []KPIScore `bson:"scores" json:"scores"`
+	Feedback    string     `bson:"feedback" json:"feedback"`
+	TotalScore  float64    `bson:"total_score" json:"total_score"`
+	Percent     float64    `bson:"percent" json:"percent"`
+	Grade       string     `bson:"grade,omitempty" json:"grade,omitempty"`
+	Reason      string     `bson:"reason,omitempty" json:"reason,omitempty"` // เช่น แก้ตามผลพิจารณาข้อโต้แย้ง
+	RevisedAt   time.Time  `bson:"revised_at" json:"revised_at"`
+}
+
+// KPIEvalDispute ข้อโต้แย้งคะแนนของผู้ถูกประเมิน และผลพิจารณาของผู้จัดการ/HR
+type KPIEvalDispute struct {
+	Reason     string     `bson:"reason" json:"reason"`                               // เหตุผลที่โต้แย้ง
+	RaisedAt   time.Time  `bson:"raised_at" json:"raised_at"`                         // เวลาโต้แย้ง
+	Version    int        `bson:"version" json:"version"`                             // version ของคะแนนที่ถูกโต้แย้ง
+	Decision   string     `bson:"decision,omitempty" json:"decision,omitempty"`       // upheld (คงคะแนน) | revised (แก้คะแนน)
+	Resolution string     `bson:"resolution,omitempty" json:"resolution,omitempty"`   // ความเห็นผู้พิจารณา
+	ResolvedBy string     `bson:"resolved_by,omitempty" json:"resolved_by,omitempty"` // ผู้จัดการ/HR ที่พิจารณา
+	ResolvedAt *time.Time `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
 }
 
 type KPIScore struct {
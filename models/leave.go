@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+const (
+	CollectionLeavePolicies = "leave_policies"
+	CollectionLeaveRequests = "leave_requests"
+)
+
+// ประเภทการลาตามพระราชบัญญัติคุ้มครองแรงงาน
+const (
+	LeaveSick       = "sick"       // ลาป่วย (ได้รับค่าจ้างไม่เกิน 30 วันทำงาน/ปี)
+	LeavePersonal   = "personal"   // ลากิจธุระอันจำเป็น (ได้รับค่าจ้างไม่เกิน 3 วันทำงาน/ปี)
+	LeaveAnnual     = "annual"     // ลาพักผ่อนประจำปี (ไม่น้อยกว่า 6 วันทำงาน เมื่อทำงานครบ 1 ปี)
+	LeaveMaternity  = "maternity"  // ลาคลอด (ไม่เกิน 98 วัน/ครรภ์ นับรวมวันหยุด ได้รับค่าจ้าง 45 วัน)
+	LeaveOrdination = "ordination" // ลาอุปสมบท (ตามระเบียบบริษัท)
+	LeaveMilitary   = "military"   // ลาเพื่อรับราชการทหาร (ได้รับค่าจ้างไม่เกิน 60 วัน/ปี)
+)
+
+// สถานะใบลา
+const (
+	LeavePending   = "pending"   // รอผู้จัดการแผนกอนุมัติ
+	LeaveApproved  = "approved"  // อนุมัติแล้ว รอ HR บันทึก (หักสิทธิ์และกำลังการผลิตแล้ว)
+	LeaveRecorded  = "recorded"  // HR บันทึกเข้าระบบบุคคลแล้ว
+	LeaveRejected  = "rejected"  // ไม่อนุมัติ
+	LeaveCancelled = "cancelled" // ยกเลิก
+)
+
+// ช่วงของการลาครึ่งวัน
+const (
+	LeaveHalfMorning   = "morning"
+	LeaveHalfAfternoon = "afternoon"
+)
+
+// LeavePolicy สิทธิ์การลาต่อปีของประเภทการลา (ว่าง position_id = ทุกตำแหน่ง ถ้ามีนโยบายเฉพาะตำแหน่งจะใช้ก่อน)
+// ประเภทที่ยังไม่ได้ตั้งนโยบายใช้ค่าเริ่มต้นตามกฎหมายแรงงาน
+type LeavePolicy struct {
+	CreatedAt     time.Time   `bson:"created_at" json:"created_at"`           // วันที่สร้าง
+	UpdatedAt     time.Time   `bson:"updated_at" json:"updated_at"`           // วันที่แก้ไขล่าสุด
+	DeletedAt     *time.Time  `bson:"deleted_at" json:"deleted_at"`           // วันที่ลบ (soft delete)
+	PolicyID      string      `bson:"policy_id" json:"policy_id"`             // รหัสนโยบาย (UUID)
+	LeaveType     string      `bson:"leave_type" json:"leave_type"`           // sick|personal|annual|maternity|ordination|military
+	PositionID    string      `bson:"position_id" json:"position_id"`         // ตำแหน่งที่ใช้นโยบายนี้ (ว่าง = ทุกตำแหน่ง)
+	Tiers         []LeaveTier `bson:"tiers" json:"tiers"`                     // สิทธิ์ต่อปีตามอายุงาน
+	PaidDays      float64     `bson:"paid_days" json:"paid_days"`             // วันลาที่ได้รับค่าจ้างต่อปี
+	MaxCarryOver  float64     `bson:"max_carry_over" json:"max_carry_over"`   // ยกวันที่เหลือไปปีถัดไปได้สูงสุด (0 = ไม่ยกยอด)
+	AllowExceed   bool        `bson:"allow_exceed" json:"allow_exceed"`       // ลาเกินสิทธิ์ได้ (ส่วนเกินไม่ได้รับค่าจ้าง) เช่น ลาป่วย
+	AllowHalfDay  bool        `bson:"allow_half_day" json:"allow_half_day"`   // ลาครึ่งวันได้
+	CalendarDays  bool        `bson:"calendar_days" json:"calendar_days"`     // นับวันปฏิทินรวมวันหยุด (เช่น ลาคลอด)
+	MinNoticeDays int         `bson:"min_notice_days" json:"min_notice_days"` // ต้องยื่นล่วงหน้ากี่วัน (0 = ยื่นย้อนหลังได้)
+	UpdatedBy     string      `bson:"updated_by" json:"updated_by"`           // ผู้แก้ไขล่าสุด
+}
+
+// LeaveTier สิทธิ์ลาต่อปีเมื่ออายุงานถึงเกณฑ์ (ใช้ขั้นสูงสุดที่ถึง)
+type LeaveTier struct {
+	MinServiceMonths int     `bson:"min_service_months" json:"min_service_months"` // อายุงานขั้นต่ำ (เดือน)
+	Days             float64 `bson:"days" json:"days"`                             // วันลาต่อปี
+}
+
+// LeaveRequest ใบลาของพนักงาน: ยื่น -> ผู้จัดการแผนกอนุมัติ -> HR บันทึก
+type LeaveRequest struct {
+	CreatedAt    time.Time  `bson:"created_at" json:"created_at"`       // วันที่ยื่น
+	UpdatedAt    time.Time  `bson:"updated_at" json:"updated_at"`       // วันที่แก้ไขล่าสุด
+	DeletedAt    *time.Time `bson:"deleted_at" json:"deleted_at"`       // วันที่ลบ (soft delete)
+	RequestID    string     `bson:"request_id" json:"request_id"`       // รหัสใบลา (UUID)
+	UserID       string     `bson:"user_id" json:"user_id"`             // ผู้ลา
+	DepartmentID string     `bson:"department_id" json:"department_id"` // แผนกตอนยื่น (ใช้หักกำลังการผลิต)
+	LeaveType    string     `bson:"leave_type" json:"leave_type"`       // ประเภทการลา
+	StartDate    time.Time  `bson:"start_date" json:"start_date"`       // วันเริ่มลา (00:00 UTC)
+	EndDate      time.Time  `bson:"end_date" json:"end_date"`           // วันสุดท้ายที่ลา (รวมวันนั้น)
+	HalfDay      string     `bson:"half_day,omitempty" json:"half_day"` // morning|afternoon เฉพาะลาวันเดียว
+	Days         float64    `bson:"days" json:"days"`                   // จำนวนวันที่หักสิทธิ์ (ไม่รวมวันหยุด ยกเว้นนับวันปฏิทิน)
+	Year         int        `bson:"year" json:"year"`                   // ปีของสิทธิ์ (ปีของวันเริ่มลา)
+	Reason       string     `bson:"reason" json:"reason"`               // เหตุผลการลา
+	Attachment   string     `bson:"attachment,omitempty" json:"attachment"`
+	Status       string     `bson:"status" json:"status"`           // pending|approved|recorded|rejected|cancelled
+	ApproverID   string     `bson:"approver_id" json:"approver_id"` // ผู้จัดการแผนกที่ต้องอนุมัติ (ว่าง = HR อนุมัติ)
+	CreatedBy    string     `bson:"created_by" json:"created_by"`   // ผู้ยื่น (HR ยื่นแทนได้)
+
+	DecidedBy       string     `bson:"decided_by,omitempty" json:"decided_by"`             // ผู้อนุมัติ/ไม่อนุมัติ
+	DecidedAt       *time.Time `bson:"decided_at,omitempty" json:"decided_at"`             // เวลาอนุมัติ/ไม่อนุมัติ
+	DecisionComment string     `bson:"decision_comment,omitempty" json:"decision_comment"` // ความเห็นผู้อนุมัติ
+	RecordedBy      string     `bson:"recorded_by,omitempty" json:"recorded_by"`           // HR ที่บันทึก
+	RecordedAt      *time.Time `bson:"recorded_at,omitempty" json:"recorded_at"`           // เวลาที่ HR บันทึก
+	HRNote          string     `bson:"hr_note,omitempty" json:"hr_note"`                   // หมายเหตุ HR
+	CancelledBy     string     `bson:"cancelled_by,omitempty" json:"cancelled_by"`         // ผู้ยกเลิก
+	CancelledAt     *time.Time `bson:"cancelled_at,omitempty" json:"cancelled_at"`         // เวลายกเลิก
+	CancelReason    string     `bson:"cancel_reason,omitempty" json:"cancel_reason"`       // เหตุผลที่ยกเลิก
+}
@@ -21,6 +21,7 @@ type Payable struct {
 	Phone       string             `json:"phone" bson:"phone"`                               // เบอร์โทรศัพท์ผู้ขาย / เจ้าหนี้
 	Address     string             `json:"address" bson:"address"`                           // ที่อยู่ผู้ขาย / เจ้าหนี้
 	Note        string             `json:"note" bson:"note"`                                 // หมายเหตุเพิ่มเติม
+	JobID       string             `json:"job_id,omitempty" bson:"job_id,omitempty"`         // ผูกกับงานป้าย (ใช้คำนวณต้นทุนงาน)
 	CreatedBy   string             `json:"created_by" bson:"created_by"`                     // ผู้สร้างข้อมูล
 	Items       []ReceiptItem      `json:"items" bson:"items"`                               // รายการสินค้า/บริการ
 	Amount      float64            `json:"amount" bson:"amount"`                             // จำนวนเงินทั้งหมดในใบแจ้งหนี้
@@ -0,0 +1,151 @@
+package models
+
+import "time"
+
+const (
+	CollectionSalaryStructures = "salary_structures"
+	CollectionPayrollRuns      = "payroll_runs"
+	CollectionPayslips         = "payslips"
+)
+
+// ฐานการจ่ายค่าจ้าง
+const (
+	PayBasisMonthly = "monthly" // รายเดือน (หักขาดงาน/ลาไม่รับค่าจ้างตามวัน)
+	PayBasisDaily   = "daily"   // รายวัน (จ่ายตามวันที่มาทำงาน + วันลาที่ได้รับค่าจ้าง)
+)
+
+// สถานะรอบเงินเดือน
+const (
+	PayrollDraft     = "draft"     // คำนวณแล้ว แก้ไข/คำนวณใหม่ได้
+	PayrollApproved  = "approved"  // อนุมัติแล้ว รอโอนเงิน
+	PayrollPaid      = "paid"      // โอนเงินแล้ว บันทึกรายจ่ายแล้ว
+	PayrollCancelled = "cancelled" // ยกเลิก
+)
+
+// รหัสรายการในสลิปที่ระบบคำนวณให้
+const (
+	PayCodeBase        = "base"
+	PayCodeOT          = "ot"
+	PayCodeHolidayOT   = "holiday_ot"
+	PayCodeAbsence     = "absence"
+	PayCodeSSF         = "ssf"
+	PayCodeTax         = "tax"
+	PayCodeAllowance   = "allowance"
+	PayCodeDeduction   = "deduction"
+	PayCodeAdjustment  = "adjustment"
+	PayCodeUnpaidLeave = "unpaid_leave"
+)
+
+// SalaryStructure โครงสร้างเงินเดือนปัจจุบันของพนักงาน (หนึ่งรายการต่อคน)
+type SalaryStructure struct {
+	CreatedAt         time.Time  `bson:"created_at" json:"created_at"`                 // วันที่สร้าง
+	UpdatedAt         time.Time  `bson:"updated_at" json:"updated_at"`                 // วันที่แก้ไขล่าสุด
+	DeletedAt         *time.Time `bson:"deleted_at" json:"deleted_at"`                 // วันที่ลบ (soft delete)
+	StructureID       string     `bson:"structure_id" json:"structure_id"`             // รหัสโครงสร้าง (UUID)
+	UserID            string     `bson:"user_id" json:"user_id"`                       // พนักงาน
+	PayBasis          string     `bson:"pay_basis" json:"pay_basis"`                   // monthly|daily
+	BaseSalary        float64    `bson:"base_salary" json:"base_salary"`               // เงินเดือน (รายเดือน)
+	DailyRate         float64    `bson:"daily_rate" json:"daily_rate"`                 // ค่าจ้างต่อวัน (รายวัน)
+	HoursPerDay       float64    `bson:"hours_per_day" json:"hours_per_day"`           // ชั่วโมงทำงานปกติต่อวัน ใช้คิดค่าจ้างต่อชั่วโมง
+	OTMultiplier      float64    `bson:"ot_multiplier" json:"ot_multiplier"`           // อัตรา OT วันทำงาน (เท่าของค่าจ้างต่อชั่วโมง)
+	HolidayMultiplier float64    `bson:"holiday_multiplier" json:"holiday_multiplier"` // อัตราทำงานวันหยุด
+	Allowances        []PayItem  `bson:"allowances" json:"allowances"`                 // เงินเพิ่มประจำ เช่น ค่าตำแหน่ง ค่าเดินทาง
+	Deductions        []PayItem  `bson:"deductions" json:"deductions"`                 // รายการหักประจำ เช่น เงินกู้ยืม
+	SSFEnabled        bool       `bson:"ssf_enabled" json:"ssf_enabled"`               // หักประกันสังคม
+	TaxDeductions     float64    `bson:"tax_deductions" json:"tax_deductions"`         // ค่าลดหย่อนภาษีอื่นทั้งปีที่พนักงานแจ้ง
+	UpdatedBy         string     `bson:"updated_by" json:"updated_by"`                 // ผู้แก้ไขล่าสุด
+}
+
+// PayItem รายการเงินได้/เงินหักประจำ
+type PayItem struct {
+	Name    string  `bson:"name" json:"name"`
+	Amount  float64 `bson:"amount" json:"amount"`
+	Taxable bool    `bson:"taxable" json:"taxable"` // เงินได้ที่ต้องเสียภาษี (เฉพาะรายการเงินได้)
+	IsWage  bool    `bson:"is_wage" json:"is_wage"` // นับเป็นค่าจ้างสำหรับฐานประกันสังคม (เฉพาะรายการเงินได้)
+}
+
+// PayrollRun รอบเงินเดือนประจำเดือน
+type PayrollRun struct {
+	CreatedAt        time.Time  `bson:"created_at" json:"created_at"`                 // วันที่สร้าง
+	UpdatedAt        time.Time  `bson:"updated_at" json:"updated_at"`                 // วันที่แก้ไขล่าสุด
+	DeletedAt        *time.Time `bson:"deleted_at" json:"deleted_at"`                 // วันที่ลบ (soft delete)
+	PayDate          time.Time  `bson:"pay_date" json:"pay_date"`                     // วันที่จ่าย
+	RunID            string     `bson:"run_id" json:"run_id"`                         // รหัสรอบ (UUID)
+	Month            string     `bson:"month" json:"month"`                           // YYYY-MM
+	Status           string     `bson:"status" json:"status"`                         // draft|approved|paid|cancelled
+	BankID           string     `bson:"bank_id" json:"bank_id"`                       // บัญชีบริษัทที่ใช้จ่าย
+	EmployeeCount    int        `bson:"employee_count" json:"employee_count"`         // จำนวนพนักงาน
+	TotalGross       float64    `bson:"total_gross" json:"total_gross"`               // รวมเงินได้
+	TotalSSFEmployee float64    `bson:"total_ssf_employee" json:"total_ssf_employee"` // ประกันสังคมส่วนลูกจ้าง
+	TotalSSFEmployer float64    `bson:"total_ssf_employer" json:"total_ssf_employer"` // ประกันสังคมส่วนนายจ้าง
+	TotalTax         float64    `bson:"total_tax" json:"total_tax"`                   // ภาษีหัก ณ ที่จ่าย (ภ.ง.ด.1)
+	TotalDeductions  float64    `bson:"total_deductions" json:"total_deductions"`     // รวมเงินหักทั้งหมด
+	TotalNet         float64    `bson:"total_net" json:"total_net"`                   // รวมเงินโอน
+	ExpenseID        string     `bson:"expense_id" json:"expense_id"`                 // รายจ่ายที่บันทึกเมื่อจ่ายแล้ว
+	Note             string     `bson:"note" json:"note"`
+	CreatedBy        string     `bson:"created_by" json:"created_by"`
+	CalculatedAt     time.Time  `bson:"calculated_at" json:"calculated_at"` // คำนวณล่าสุดเมื่อ
+	ApprovedBy       string     `bson:"approved_by,omitempty" json:"approved_by"`
+	ApprovedAt       *time.Time `bson:"approved_at,omitempty" json:"approved_at"`
+	PaidBy           string     `bson:"paid_by,omitempty" json:"paid_by"`
+	PaidAt           *time.Time `bson:"paid_at,omitempty" json:"paid_at"`
+	CancelledBy      string     `bson:"cancelled_by,omitempty" json:"cancelled_by"`
+	CancelledAt      *time.Time `bson:"cancelled_at,omitempty" json:"cancelled_at"`
+	CancelReason     string     `bson:"cancel_reason,omitempty" json:"cancel_reason"`
+}
+
+// Payslip สลิปเงินเดือนของพนักงานหนึ่งคนในรอบ (สำเนาข้อมูล ณ วันที่คำนวณ)
+type Payslip struct {
+	CreatedAt    time.Time  `bson:"created_at" json:"created_at"`
+	DeletedAt    *time.Time `bson:"deleted_at" json:"deleted_at"`
+	PayslipID    string     `bson:"payslip_id" json:"payslip_id"`
+	RunID        string     `bson:"run_id" json:"run_id"`
+	Month        string     `bson:"month" json:"month"` // YYYY-MM
+	Year         int        `bson:"year" json:"year"`
+	UserID       string     `bson:"user_id" json:"user_id"`
+	EmployeeCode string     `bson:"employee_code" json:"employee_code"`
+	NameTH       string     `bson:"name_th" json:"name_th"`
+	NameEN       string     `bson:"name_en" json:"name_en"`
+	IDCard       string     `bson:"id_card" json:"id_card"`
+	DepartmentID string     `bson:"department_id" json:"department_id"`
+	PositionID   string     `bson:"position_id" json:"position_id"`
+	BankName     string     `bson:"bank_name" json:"bank_name"`
+	AccountNo    string     `bson:"account_no" json:"account_no"`
+	AccountName  string     `bson:"account_name" json:"account_name"`
+	PayBasis     string     `bson:"pay_basis" json:"pay_basis"`
+
+	// ข้อมูลเวลาทำงานจากสรุปการลงเวลา
+	ScheduledDays   int     `bson:"scheduled_days" json:"scheduled_days"`
+	PresentDays     int     `bson:"present_days" json:"present_days"`
+	AbsentDays      float64 `bson:"absent_days" json:"absent_days"`
+	LeaveDays       float64 `bson:"leave_days" json:"leave_days"`
+	UnpaidLeaveDays float64 `bson:"unpaid_leave_days" json:"unpaid_leave_days"`
+	OTHours         float64 `bson:"ot_hours" json:"ot_hours"`
+	HolidayOTHours  float64 `bson:"holiday_ot_hours" json:"holiday_ot_hours"`
+
+	Earnings    []PayLine `bson:"earnings" json:"earnings"`       // เงินได้ (ขาดงาน/ลาไม่รับค่าจ้างเป็นยอดติดลบ)
+	Deductions  []PayLine `bson:"deductions" json:"deductions"`   // เงินหัก
+	Adjustments []PayItem `bson:"adjustments" json:"adjustments"` // รายการปรับเฉพาะเดือน (คงไว้เมื่อคำนวณใหม่)
+
+	Gross          float64 `bson:"gross" json:"gross"`                     // รวมเงินได้
+	TaxableIncome  float64 `bson:"taxable_income" json:"taxable_income"`   // เงินได้ที่ต้องเสียภาษี
+	SSFWage        float64 `bson:"ssf_wage" json:"ssf_wage"`               // ค่าจ้างที่ใช้คิดประกันสังคม
+	SSFEmployee    float64 `bson:"ssf_employee" json:"ssf_employee"`       // หักประกันสังคม
+	SSFEmployer    float64 `bson:"ssf_employer" json:"ssf_employer"`       // นายจ้างสมทบ
+	WithholdingTax float64 `bson:"withholding_tax" json:"withholding_tax"` // ภาษีหัก ณ ที่จ่าย
+	TotalDeduction float64 `bson:"total_deduction" json:"total_deduction"` // รวมเงินหัก
+	Net            float64 `bson:"net" json:"net"`                         // เงินได้สุทธิ (ยอดโอน)
+
+	// ยอดสะสมทั้งปีรวมเดือนนี้
+	YTDIncome float64 `bson:"ytd_income" json:"ytd_income"`
+	YTDTax    float64 `bson:"ytd_tax" json:"ytd_tax"`
+	YTDSSF    float64 `bson:"ytd_ssf" json:"ytd_ssf"`
+}
+
+// PayLine รายการหนึ่งบรรทัดในสลิป
+type PayLine struct {
+	Code    string  `bson:"code" json:"code"`
+	Name    string  `bson:"name" json:"name"`
+	Amount  float64 `bson:"amount" json:"amount"`
+	Taxable bool    `bson:"taxable" json:"taxable"`
+}
@@ -0,0 +1,107 @@
+package models
+
+import "time"
+
+const (
+	CollectionReviewCycles       = "review_cycles"
+	CollectionPerformanceReviews = "performance_reviews"
+)
+
+// สถานะรอบประเมิน
+const (
+	ReviewCycleScheduled = "scheduled" // สร้างแล้ว รอถึงวันเปิด (แก้ไขได้ทุกอย่าง)
+	ReviewCycleOpen      = "open"      // เปิดให้ประเมินตนเอง/ผู้จัดการประเมิน
+	ReviewCycleClosed    = "closed"    // ปิดรับการประเมิน เหลือเฉพาะ HR ปรับเทียบและยืนยันผล
+)
+
+// สถานะการประเมินรายคน
+const (
+	ReviewSelfPending    = "self_pending"    // รอพนักงานประเมินตนเอง
+	ReviewManagerPending = "manager_pending" // ประเมินตนเองแล้ว รอผู้จัดการ
+	ReviewCalibration    = "calibration"     // ผู้จัดการประเมินแล้ว รอ HR ปรับเทียบ
+	ReviewFinalized      = "finalized"       // ยืนยันผลแล้ว (ล็อก แก้ไขไม่ได้)
+)
+
+// ReviewCycle รอบประเมินผลงานรายไตรมาส/รายปี รวมคะแนน KPI ของงานในช่วงเวลา กับหัวข้อประเมินระดับผู้จัดการ
+type ReviewCycle struct {
+	CreatedAt time.Time  `bson:"created_at" json:"created_at"` // วันที่สร้าง
+	UpdatedAt time.Time  `bson:"updated_at" json:"updated_at"` // วันที่แก้ไขล่าสุด
+	DeletedAt *time.Time `bson:"deleted_at" json:"deleted_at"` // วันที่ลบ (soft delete)
+	CycleID   string     `bson:"cycle_id" json:"cycle_id"`     // รหัสรอบประเมิน (UUID)
+	Name      string     `bson:"name" json:"name"`             // ชื่อรอบ เช่น "Q1/2025"
+	CycleType string     `bson:"cycle_type" json:"cycle_type"` // quarterly|annual
+
+	PeriodStart time.Time `bson:"period_start" json:"period_start"` // ช่วงผลงานที่นำมาคิด (รวมวันแรก)
+	PeriodEnd   time.Time `bson:"period_end" json:"period_end"`     // ช่วงผลงานที่นำมาคิด (รวมวันสุดท้าย)
+	OpenAt      time.Time `bson:"open_at" json:"open_at"`           // วันเปิดให้ประเมิน
+	CloseAt     time.Time `bson:"close_at" json:"close_at"`         // วันปิดรับการประเมิน
+
+	Departments  []string        `bson:"departments" json:"departments"`           // แผนกที่เข้าร่วม (ว่าง = ทุกแผนก)
+	Items        []ReviewItem    `bson:"items" json:"items"`                       // หัวข้อประเมิน (เช่น การเข้างาน พฤติกรรม)
+	TaskWeight   int             `bson:"task_weight" json:"task_weight"`           // สัดส่วนคะแนน KPI ของงาน (0..100) ที่เหลือเป็นหัวข้อประเมินของผู้จัดการ
+	Bands        []KPIRatingBand `bson:"bands" json:"bands"`                       // ช่วงเกรดของผลสุดท้าย
+	ReminderDays int             `bson:"reminder_days" json:"reminder_days"`       // เริ่มส่งอีเมลเตือนก่อนปิดรอบกี่วัน (0 = ไม่เตือนอัตโนมัติ)
+	Status       string          `bson:"status" json:"status"`                     // scheduled|open|closed
+	Participants int             `bson:"participants" json:"participants"`         // จำนวนผู้ถูกประเมิน
+	OpenedAt     *time.Time      `bson:"opened_at,omitempty" json:"opened_at"`     // เวลาเปิดจริง
+	ClosedAt     *time.Time      `bson:"closed_at,omitempty" json:"closed_at"`     // เวลาปิดจริง
+	RemindedAt   *time.Time      `bson:"reminded_at,omitempty" json:"reminded_at"` // ส่งเตือนล่าสุด (กันส่งซ้ำวันเดียวกัน)
+	CreatedBy    string          `bson:"created_by" json:"created_by"`             // ผู้สร้าง
+}
+
+// ReviewItem หัวข้อประเมินของรอบ ให้คะแนนทั้งพนักงาน (ตนเอง) และผู้จัดการ
+type ReviewItem struct {
+	ItemID      string `bson:"item_id" json:"item_id"`
+	Name        string `bson:"name" json:"name"`
+	Description string `bson:"description" json:"description"`
+	Category    string `bson:"category" json:"category"` // เช่น attendance, behavior
+	MaxScore    int    `bson:"max_score" json:"max_score"`
+	Weight      int    `bson:"weight" json:"weight"`
+}
+
+// PerformanceReview ผลประเมินของพนักงานหนึ่งคนในรอบประเมิน (หนึ่งคนต่อหนึ่งรอบ)
+type PerformanceReview struct {
+	CreatedAt    time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `bson:"updated_at" json:"updated_at"`
+	ReviewID     string    `bson:"review_id" json:"review_id"`         // UUID
+	CycleID      string    `bson:"cycle_id" json:"cycle_id"`           // อ้างถึง ReviewCycle
+	UserID       string    `bson:"user_id" json:"user_id"`             // ผู้ถูกประเมิน
+	DepartmentID string    `bson:"department_id" json:"department_id"` // แผนกตอนเปิดรอบ
+	ManagerID    string    `bson:"manager_id" json:"manager_id"`       // ผู้จัดการแผนกตอนเปิดรอบ (ว่าง = HR ประเมินแทน)
+	Status       string    `bson:"status" json:"status"`               // self_pending|manager_pending|calibration|finalized
+
+	TaskKPI ReviewTaskKPI `bson:"task_kpi" json:"task_kpi"` // คะแนนเฉลี่ยจากการประเมิน KPI ของงานในช่วงรอบ
+
+	SelfScores      []KPIScore `bson:"self_scores" json:"self_scores"`                       // คะแนนที่พนักงานประเมินตนเอง
+	SelfComment     string     `bson:"self_comment" json:"self_comment"`                     // ความเห็นของพนักงาน
+	SelfPercent     float64    `bson:"self_percent" json:"self_percent"`                     // เปอร์เซ็นต์ถ่วงน้ำหนักของคะแนนตนเอง
+	SelfSubmittedAt *time.Time `bson:"self_submitted_at,omitempty" json:"self_submitted_at"` // เวลาส่งแบบประเมินตนเอง
+
+	ManagerScores      []KPIScore `bson:"manager_scores" json:"manager_scores"`                       // คะแนนที่ผู้จัดการให้
+	ManagerComment     string     `bson:"manager_comment" json:"manager_comment"`                     // ความเห็นผู้จัดการ
+	ManagerPercent     float64    `bson:"manager_percent" json:"manager_percent"`                     // เปอร์เซ็นต์ถ่วงน้ำหนักของคะแนนผู้จัดการ
+	ManagerSubmittedAt *time.Time `bson:"manager_submitted_at,omitempty" json:"manager_submitted_at"` // เวลาผู้จัดการส่งผล
+	ManagerSubmittedBy string     `bson:"manager_submitted_by,omitempty" json:"manager_submitted_by"` // ผู้ส่งผลแทนผู้จัดการ (เช่น HR)
+
+	SuggestedScore  float64    `bson:"suggested_score" json:"suggested_score"`             // task_weight × KPI งาน + ที่เหลือ × คะแนนผู้จัดการ
+	FinalScore      *float64   `bson:"final_score,omitempty" json:"final_score"`           // คะแนนสุดท้ายหลังปรับเทียบ (0..100)
+	FinalGrade      string     `bson:"final_grade,omitempty" json:"final_grade"`           // เกรดสุดท้ายตาม bands ของรอบ
+	CalibrationNote string     `bson:"calibration_note,omitempty" json:"calibration_note"` // เหตุผลการปรับเทียบ
+	FinalizedBy     string     `bson:"finalized_by,omitempty" json:"finalized_by"`         // HR ผู้ยืนยันผล
+	FinalizedAt     *time.Time `bson:"finalized_at,omitempty" json:"finalized_at"`         // เวลายืนยันผล (ล็อกผล)
+}
+
+// ReviewTaskKPI สรุปการประเมิน KPI รายงานที่ประเมินเสร็จในช่วงของรอบ
+type ReviewTaskKPI struct {
+	Evaluations  int       `bson:"evaluations" json:"evaluations"`     // จำนวนการประเมิน
+	Score        float64   `bson:"score" json:"score"`                 // คะแนนเฉลี่ย 0..100
+	CalculatedAt time.Time `bson:"calculated_at" json:"calculated_at"` // เวลาคำนวณล่าสุด
+}
+
+/*
+แนะนำดัชนีใน MongoDB:
+
+db.review_cycles.createIndex({ cycle_id: 1 }, { unique: true });
+db.performance_reviews.createIndex({ cycle_id: 1, user_id: 1 }, { unique: true });
+db.performance_reviews.createIndex({ manager_id: 1, status: 1 });
+*/
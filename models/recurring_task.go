@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+const CollectionRecurringTasks = "recurring_tasks"
+
+// RecurringTask งานประจำ (เช่น บำรุงรักษาเครื่องรายสัปดาห์ นับสต็อกรายเดือน)
+// สร้าง task จาก workflow template ตามตารางเวลาแบบ cron หรือ RRULE และวนผู้รับผิดชอบตามคิว
+type RecurringTask struct {
+	CreatedAt   time.Time  `bson:"created_at" json:"created_at"`                       // วันที่สร้าง
+	UpdatedAt   time.Time  `bson:"updated_at" json:"updated_at"`                       // วันที่แก้ไขล่าสุด
+	DeletedAt   *time.Time `bson:"deleted_at" json:"deleted_at"`                       // วันที่ลบ (soft delete)
+	RecurringID string     `bson:"recurring_id" json:"recurring_id"`                   // รหัสงานประจำ (UUID)
+	Name        string     `bson:"name" json:"name"`                                   // ชื่องาน (task ที่สร้างจะต่อท้ายด้วยวันที่)
+	Description string     `bson:"description,omitempty" json:"description,omitempty"` // รายละเอียดงาน
+
+	WorkFlowID  string `bson:"workflow_id" json:"workflow_id"`                       // workflow template ที่ใช้สร้างงาน (ใช้เวอร์ชันล่าสุดทุกครั้ง)
+	ProjectID   string `bson:"project_id,omitempty" json:"project_id,omitempty"`     // โปรเจกต์
+	ProjectName string `bson:"project_name,omitempty" json:"project_name,omitempty"` // ชื่อโปรเจกต์
+	Department  string `bson:"department_id" json:"department_id"`                   // แผนกเจ้าของงาน
+	Importance  string `bson:"importance" json:"importance"`                         // low|medium|high
+	KPIID       string `bson:"kpi_id,omitempty" json:"kpi_id,omitempty"`             // KPI ที่ใช้ประเมิน
+
+	Schedule     string    `bson:"schedule" json:"schedule"`           // cron 5 ช่อง เช่น "0 8 * * 1" หรือ RRULE เช่น "FREQ=MONTHLY;BYMONTHDAY=-1"
+	StartAt      time.Time `bson:"start_at" json:"start_at"`           // วันเวลาเริ่มนับรอบ (DTSTART ตามเวลาไทย)
+	DurationDays int       `bson:"duration_days" json:"duration_days"` // จำนวนวันของงานแต่ละรอบ (start_date..end_date)
+
+	Assignees       []string `bson:"assignees" json:"assignees"`                 // ผู้รับผิดชอบแบบวนคิว (คนเดียว = ประจำ)
+	NextAssigneeIdx int      `bson:"next_assignee_idx" json:"next_assignee_idx"` // ตำแหน่งคิวถัดไปใน assignees
+
+	IsActive   bool       `bson:"is_active" json:"is_active"`                           // เปิดใช้งาน
+	NextRunAt  *time.Time `bson:"next_run_at" json:"next_run_at"`                       // รอบถัดไป (nil = ไม่มีรอบแล้ว)
+	LastRunAt  *time.Time `bson:"last_run_at,omitempty" json:"last_run_at,omitempty"`   // รอบล่าสุดที่สร้างงาน
+	LastTaskID string     `bson:"last_task_id,omitempty" json:"last_task_id,omitempty"` // งานล่าสุดที่สร้าง
+	LastError  string     `bson:"last_error,omitempty" json:"last_error,omitempty"`     // ข้อผิดพลาดของรอบล่าสุด
+	RunCount   int        `bson:"run_count" json:"run_count"`                           // จำนวนรอบที่สร้างงานแล้ว (ใช้กับ COUNT ของ RRULE)
+	CreatedBy  string     `bson:"created_by" json:"created_by"`                         // ผู้สร้าง
+}
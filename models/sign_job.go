@@ -44,7 +44,7 @@ type SignJob struct {
 	// ---------- Notes ----------
 	Notes string `bson:"notes" json:"notes"` // หมายเหตุ
 	// ---------- Meta ----------
-	Status            string  `bson:"status" json:"status"`                         // อยู่ในขั้นตอนไหนแล้ว
+	Status            string  `bson:"status" json:"status"`                         // อยู่ในขั้นตอนไหนแล้ว: in_progress|done|cancelled
 	CreatedBy         string  `bson:"created_by" json:"created_by"`                 // ใครสร้างงานนี้
 	Width             float64 `bson:"width" json:"width"`                           // ซม.
 	Height            float64 `bson:"height" json:"height"`                         // ซม.
@@ -56,4 +56,9 @@ type SignJob struct {
 
 	WaitPrice   bool `bson:"waitprice" json:"waitprice"`     // รอราคา
 	WaitConfirm bool `bson:"waitconfirm" json:"waitconfirm"` // รอยืนยัน
+
+	// ---------- Cancel ----------
+	CancelledAt  *time.Time `bson:"cancelled_at,omitempty" json:"cancelled_at,omitempty"`   // วันที่ยกเลิกงาน
+	CancelledBy  string     `bson:"cancelled_by,omitempty" json:"cancelled_by,omitempty"`   // ผู้ยกเลิกงาน
+	CancelReason string     `bson:"cancel_reason,omitempty" json:"cancel_reason,omitempty"` // เหตุผลการยกเลิก
 }
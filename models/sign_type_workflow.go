@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+const CollectionSignTypeWorkflows = "sign_type_workflows"
+
+// SignTypeWorkflow กำหนดว่าประเภทป้าย (และตัวเลือกติดตั้ง) ใช้ workflow ใดบ้างในการผลิต
+// ใช้สร้าง task อัตโนมัติเมื่อยืนยันงานป้าย
+type SignTypeWorkflow struct {
+	CreatedAt     time.Time              `bson:"created_at" json:"created_at"`                             // วันที่สร้าง
+	UpdatedAt     time.Time              `bson:"updated_at" json:"updated_at"`                             // วันที่แก้ไขล่าสุด
+	DeletedAt     *time.Time             `bson:"deleted_at" json:"deleted_at"`                             // วันที่ลบ (soft delete)
+	MappingID     string                 `bson:"mapping_id" json:"mapping_id"`                             // รหัส mapping (UUID)
+	SignTypeID    string                 `bson:"sign_type_id" json:"sign_type_id"`                         // ประเภทป้าย
+	InstallOption string                 `bson:"install_option,omitempty" json:"install_option,omitempty"` // none|self|shop (ว่าง = ใช้กับทุกตัวเลือก)
+	CreatedBy     string                 `bson:"created_by" json:"created_by"`                             // ผู้สร้าง
+	Items         []SignTypeWorkflowItem `bson:"items" json:"items"`                                       // workflow ที่ต้องทำ เรียงตามลำดับการผลิต
+	BaseAreaSqm   float64                `bson:"base_area_sqm" json:"base_area_sqm"`                       // พื้นที่ป้ายอ้างอิงของชั่วโมงใน workflow (ตร.ม.) ใหญ่กว่านี้คูณชั่วโมงตามสัดส่วน (0 = ไม่คิดตามขนาด)
+	IsActive      bool                   `bson:"is_active" json:"is_active"`                               // สถานะใช้งาน
+}
+
+type SignTypeWorkflowItem struct {
+	WorkFlowID   string   `bson:"workflow_id" json:"workflow_id"`                         // workflow template ที่ใช้
+	DepartmentID string   `bson:"department_id,omitempty" json:"department_id,omitempty"` // แผนกเริ่มต้น (ว่าง = ใช้แผนกของ workflow)
+	AssigneeID   string   `bson:"assignee_id,omitempty" json:"assignee_id,omitempty"`     // ผู้รับผิดชอบเริ่มต้น
+	AssigneePool []string `bson:"assignee_pool,omitempty" json:"assignee_pool,omitempty"` // กลุ่มผู้รับผิดชอบแบบวนคิว (round-robin) ใช้เมื่อไม่ระบุ assignee_id
+	KPIID        string   `bson:"kpi_id,omitempty" json:"kpi_id,omitempty"`               // KPI ที่ใช้ประเมิน
+	Importance   string   `bson:"importance,omitempty" json:"importance,omitempty"`       // low|mid|high (ค่าเริ่มต้น mid)
+	Order        int      `bson:"order" json:"order"`                                     // ลำดับการผลิต (1..N)
+	NextPoolIdx  int      `bson:"next_pool_idx" json:"next_pool_idx"`                     // ตำแหน่งคิวถัดไปใน assignee_pool
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+const (
+	CollectionSLARules    = "sla_rules"    // กฎ SLA ต่อ step ของ workflow หรือระดับความสำคัญของงาน
+	CollectionSLABreaches = "sla_breaches" // บันทึกการเตือน/เกินกำหนด (ใช้ทำรายงานและประกอบ KPI)
+)
+
+type SLARule struct {
+	CreatedAt   time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time  `bson:"updated_at" json:"updated_at"`
+	DeletedAt   *time.Time `bson:"deleted_at" json:"deleted_at"`
+	RuleID      string     `bson:"rule_id" json:"rule_id"`
+	Name        string     `bson:"name" json:"name"`
+	Scope       string     `bson:"scope" json:"scope"`                                   // step|importance
+	WorkFlowID  string     `bson:"workflow_id,omitempty" json:"workflow_id,omitempty"`   // scope=step: workflow template
+	StepName    string     `bson:"step_name,omitempty" json:"step_name,omitempty"`       // scope=step: ชื่อ step ใน template
+	Importance  string     `bson:"importance,omitempty" json:"importance,omitempty"`     // scope=importance: low|medium|high
+	TargetHours float64    `bson:"target_hours" json:"target_hours"`                     // scope=step: ชั่วโมงที่ยอมให้นับจากเริ่ม step (0 = ใช้ชั่วโมงประมาณการ)
+	HoursFactor float64    `bson:"hours_factor,omitempty" json:"hours_factor,omitempty"` // scope=importance: ตัวคูณชั่วโมงประมาณการของ step (0 = ไม่คุม step)
+	WarnPercent float64    `bson:"warn_percent" json:"warn_percent"`                     // แจ้งเตือนเมื่อใช้เวลาไปถึง % นี้
+	Active      bool       `bson:"active" json:"active"`
+	CreatedBy   string     `bson:"created_by" json:"created_by"`
+}
+
+type SLABreach struct {
+	DueAt        time.Time  `bson:"due_at" json:"due_at"`                                 // กำหนดเสร็จตาม SLA
+	DetectedAt   time.Time  `bson:"detected_at" json:"detected_at"`                       // เวลาที่ระบบตรวจพบ
+	EscalatedAt  *time.Time `bson:"escalated_at,omitempty" json:"escalated_at,omitempty"` // เวลาที่แจ้งผู้จัดการแผนก
+	ResolvedAt   *time.Time `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`   // เวลาที่งาน/step ปิด
+	BreachID     string     `bson:"breach_id" json:"breach_id"`
+	RuleID       string     `bson:"rule_id,omitempty" json:"rule_id,omitempty"` // ว่าง = ใช้ end_date ของงาน
+	Kind         string     `bson:"kind" json:"kind"`                           // task|step
+	Level        string     `bson:"level" json:"level"`                         // warning|overdue
+	Status       string     `bson:"status" json:"status"`                       // open|resolved
+	TaskID       string     `bson:"task_id" json:"task_id"`
+	JobName      string     `bson:"job_name" json:"job_name"`
+	StepID       string     `bson:"step_id,omitempty" json:"step_id,omitempty"`
+	StepName     string     `bson:"step_name,omitempty" json:"step_name,omitempty"`
+	WorkFlowID   string     `bson:"workflow_id" json:"workflow_id"`
+	UserID       string     `bson:"user_id" json:"user_id"`             // ผู้รับผิดชอบ (เจ้าของ step หรือผู้รับผิดชอบหลัก)
+	DepartmentID string     `bson:"department_id" json:"department_id"` // แผนกที่รับผิดชอบ
+	EscalatedTo  string     `bson:"escalated_to,omitempty" json:"escalated_to,omitempty"`
+	OverdueHours float64    `bson:"overdue_hours" json:"overdue_hours"` // ชั่วโมงที่เกินกำหนด (อัปเดตทุกรอบจนกว่าจะปิด)
+}
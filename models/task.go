@@ -29,9 +29,16 @@ type Tasks struct {
 	KPIID      string `bson:"kpi_id" json:"kpi_id"`           // รหัส KPI ที่เกี่ยวข้อง
 	WorkFlowID string `bson:"workflow_id" json:"workflow_id"` // รหัส Workflow (อ้างอิง template/ค้นสถิติ)
 
-	Status    string `bson:"status" json:"status"`         // สถานะปัจจุบันของงาน (todos|in_progress|skip|done)
-	StepName  string `bson:"step_name" json:"step_name"`   // ชื่อขั้นตอนปัจจุบัน
-	CreatedBy string `bson:"created_by" json:"created_by"` // ผู้สร้างงาน
+	Status     string   `bson:"status" json:"status"`                               // สถานะปัจจุบันของงาน (todos|in_progress|skip|done)
+	StepName   string   `bson:"step_name" json:"step_name"`                         // ชื่อขั้นตอนปัจจุบัน
+	ReadySteps []string `bson:"ready_steps,omitempty" json:"ready_steps,omitempty"` // ชื่อขั้นตอนที่เริ่มได้ทันที (รองรับงานคู่ขนาน)
+	CreatedBy  string   `bson:"created_by" json:"created_by"`                       // ผู้สร้างงาน
+	SLAStatus  string   `bson:"sla_status,omitempty" json:"sla_status,omitempty"`   // สถานะ SLA ของงาน (on_track|warning|overdue)
+
+	AutoGenerated bool   `bson:"auto_generated,omitempty" json:"auto_generated,omitempty"` // สร้างอัตโนมัติจาก workflow ของประเภทป้าย
+	RecurringID   string `bson:"recurring_id,omitempty" json:"recurring_id,omitempty"`     // สร้างจากงานประจำ (recurring task definition)
+
+	ReassignHistory []TaskReassignment `bson:"reassign_history,omitempty" json:"reassign_history,omitempty"` // ประวัติการเปลี่ยนผู้รับผิดชอบหลัก
 
 	AppliedWorkflow TaskAppliedWorkflow `bson:"applied_workflow" json:"applied_workflow"` // Snapshot workflow ที่ใช้ในงานนี้
 
@@ -45,18 +52,50 @@ type TaskAppliedWorkflow struct {
 	Steps        []TaskWorkflowStep `bson:"steps" json:"steps"`                 // ลำดับขั้นตอนทั้งหมด
 	TotalHours   float64            `bson:"total_hours" json:"total_hours"`     // ชั่วโมงรวม (แคชจากผลรวม step)
 	Version      int                `bson:"version" json:"version"`             // เวอร์ชันของ template
+
+	TemplateVersion int `bson:"template_version,omitempty" json:"template_version,omitempty"` // เวอร์ชันของ template ที่ snapshot มา (0 = ก่อนมีระบบเวอร์ชัน ถือเป็น 1)
 }
 
 type TaskWorkflowStep struct {
-	CreatedAt   time.Time  `bson:"created_at" json:"created_at"`                         // วันที่สร้างขั้นตอนนี้
-	UpdatedAt   time.Time  `bson:"updated_at" json:"updated_at"`                         // วันที่อัปเดตขั้นตอนล่าสุด
-	StartedAt   *time.Time `bson:"started_at,omitempty" json:"started_at,omitempty"`     // เวลาที่เริ่ม (optional)
-	CompletedAt *time.Time `bson:"completed_at,omitempty" json:"completed_at,omitempty"` // เวลาที่เสร็จ (optional)
-	StepID      string     `bson:"step_id" json:"step_id"`                               // รหัส Step (UUID)
-	StepName    string     `bson:"step_name" json:"step_name"`                           // ชื่อ Step
-	Description string     `bson:"description,omitempty" json:"description,omitempty"`   // รายละเอียด (ไม่บังคับ)
-	Status      string     `bson:"status" json:"status"`                                 // สถานะ (todo|in_progress|skip|done)
-	Notes       string     `bson:"notes,omitempty" json:"notes,omitempty"`               // บันทึก/หมายเหตุ
-	Hours       float64    `bson:"hours" json:"hours"`                                   // ชั่วโมงที่ใช้ (รองรับทศนิยม เช่น 0.5)
-	Order       int        `bson:"order" json:"order"`                                   // ลำดับขั้นตอน (1..N)
+	CreatedAt    time.Time  `bson:"created_at" json:"created_at"`                           // วันที่สร้างขั้นตอนนี้
+	UpdatedAt    time.Time  `bson:"updated_at" json:"updated_at"`                           // วันที่อัปเดตขั้นตอนล่าสุด
+	StartedAt    *time.Time `bson:"started_at,omitempty" json:"started_at,omitempty"`       // เวลาที่เริ่ม (optional)
+	CompletedAt  *time.Time `bson:"completed_at,omitempty" json:"completed_at,omitempty"`   // เวลาที่เสร็จ (optional)
+	StepID       string     `bson:"step_id" json:"step_id"`                                 // รหัส Step (UUID)
+	StepName     string     `bson:"step_name" json:"step_name"`                             // ชื่อ Step
+	Description  string     `bson:"description,omitempty" json:"description,omitempty"`     // รายละเอียด (ไม่บังคับ)
+	Status       string     `bson:"status" json:"status"`                                   // สถานะ (todo|in_progress|skip|done)
+	Notes        string     `bson:"notes,omitempty" json:"notes,omitempty"`                 // บันทึก/หมายเหตุ
+	Hours        float64    `bson:"hours" json:"hours"`                                     // ชั่วโมงที่ใช้ (รองรับทศนิยม เช่น 0.5)
+	Order        int        `bson:"order" json:"order"`                                     // ลำดับขั้นตอน (1..N)
+	DependsOn    []string   `bson:"depends_on,omitempty" json:"depends_on,omitempty"`       // step_id ที่ต้องเสร็จ (done/skip) ก่อนเริ่ม step นี้
+	Department   string     `bson:"department_id,omitempty" json:"department_id,omitempty"` // แผนกที่ทำ step นี้
+	Assignee     string     `bson:"assignee,omitempty" json:"assignee,omitempty"`           // ผู้รับผิดชอบ step (ว่าง = ผู้รับผิดชอบหลักของงาน)
+	AssigneeName string     `bson:"assignee_name,omitempty" json:"assignee_name,omitempty"` // ชื่อผู้รับผิดชอบ step
+	SLADueAt     *time.Time `bson:"sla_due_at,omitempty" json:"sla_due_at,omitempty"`       // กำหนดเสร็จตาม SLA (คำนวณเมื่อเริ่ม step)
+	SLAStatus    string     `bson:"sla_status,omitempty" json:"sla_status,omitempty"`       // on_track|warning|overdue
+
+	TemplateStepID string `bson:"template_step_id,omitempty" json:"template_step_id,omitempty"` // step_id ใน template ที่ step นี้สร้างมา (ใช้ย้ายงานไปเวอร์ชันใหม่)
+
+	Checklist []TaskChecklistItem `bson:"checklist,omitempty" json:"checklist,omitempty"` // รายการตรวจสอบ ต้องติ๊กครบก่อนปิด step เป็น done
+}
+
+type TaskChecklistItem struct {
+	ItemID    string     `bson:"item_id" json:"item_id"`                           // รหัสรายการ (UUID)
+	Text      string     `bson:"text" json:"text"`                                 // ข้อความรายการ
+	Checked   bool       `bson:"checked" json:"checked"`                           // ติ๊กแล้วหรือยัง
+	CheckedBy string     `bson:"checked_by,omitempty" json:"checked_by,omitempty"` // ผู้ติ๊ก
+	CheckedAt *time.Time `bson:"checked_at,omitempty" json:"checked_at,omitempty"` // เวลาที่ติ๊ก
+}
+
+// TaskReassignment ประวัติการเปลี่ยนผู้รับผิดชอบหลักของงาน
+type TaskReassignment struct {
+	ReassignedAt time.Time `bson:"reassigned_at" json:"reassigned_at"`             // เวลาที่เปลี่ยน
+	FromAssignee string    `bson:"from_assignee" json:"from_assignee"`             // ผู้รับผิดชอบเดิม
+	FromName     string    `bson:"from_name,omitempty" json:"from_name,omitempty"` // ชื่อผู้รับผิดชอบเดิม
+	ToAssignee   string    `bson:"to_assignee" json:"to_assignee"`                 // ผู้รับผิดชอบใหม่
+	ToName       string    `bson:"to_name,omitempty" json:"to_name,omitempty"`     // ชื่อผู้รับผิดชอบใหม่
+	Reason       string    `bson:"reason,omitempty" json:"reason,omitempty"`       // เหตุผล
+	StepIDs      []string  `bson:"step_ids,omitempty" json:"step_ids,omitempty"`   // step ที่โอนไปพร้อมกัน (step ที่ระบุผู้รับผิดชอบเดิมไว้ตรงๆ)
+	ReassignedBy string    `bson:"reassigned_by" json:"reassigned_by"`             // ผู้ทำรายการ
 }
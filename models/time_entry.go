@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+const (
+	CollectionTimeEntries = "time_entries"
+	CollectionTimesheets  = "timesheets"
+)
+
+// TimeEntry เวลาที่ใช้จริงต่อ step ต่อคน (จับเวลา start/stop หรือบันทึกย้อนหลัง)
+type TimeEntry struct {
+	StartedAt    time.Time       `bson:"started_at" json:"started_at"`                     // เวลาเริ่ม
+	EndedAt      *time.Time      `bson:"ended_at" json:"ended_at"`                         // เวลาสิ้นสุด (nil = กำลังจับเวลา)
+	WorkDate     time.Time       `bson:"work_date" json:"work_date"`                       // วันที่ทำงาน (00:00 UTC) ใช้จัดลง timesheet
+	CreatedAt    time.Time       `bson:"created_at" json:"created_at"`                     // วันที่สร้าง
+	UpdatedAt    time.Time       `bson:"updated_at" json:"updated_at"`                     // วันที่แก้ไขล่าสุด
+	DeletedAt    *time.Time      `bson:"deleted_at" json:"deleted_at"`                     // วันที่ลบ (soft delete)
+	EntryID      string          `bson:"entry_id" json:"entry_id"`                         // รหัสรายการ (UUID)
+	TaskID       string          `bson:"task_id" json:"task_id"`                           // งาน
+	StepID       string          `bson:"step_id" json:"step_id"`                           // ขั้นตอน
+	StepName     string          `bson:"step_name" json:"step_name"`                       // สำเนาชื่อขั้นตอน
+	WorkFlowID   string          `bson:"workflow_id" json:"workflow_id"`                   // template ของงาน (ใช้ทำรายงาน)
+	JobID        string          `bson:"job_id,omitempty" json:"job_id,omitempty"`         // งานป้าย
+	UserID       string          `bson:"user_id" json:"user_id"`                           // ผู้ทำงาน
+	DepartmentID string          `bson:"department_id" json:"department_id"`               // แผนกของผู้ทำงาน
+	Source       string          `bson:"source" json:"source"`                             // timer|manual
+	Note         string          `bson:"note,omitempty" json:"note,omitempty"`             // หมายเหตุ
+	Edits        []TimeEntryEdit `bson:"edits,omitempty" json:"edits,omitempty"`           // ประวัติการแก้ไข
+	Hours        float64         `bson:"hours" json:"hours"`                               // ชั่วโมงที่ใช้ (คำนวณจากเวลาเริ่ม-สิ้นสุด)
+	CreatedBy    string          `bson:"created_by" json:"created_by"`                     // ผู้บันทึก
+	DeletedBy    string          `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"` // ผู้ลบ
+}
+
+// TimeEntryEdit ประวัติการแก้ไขรายการเวลา (ต้องระบุเหตุผลทุกครั้ง)
+type TimeEntryEdit struct {
+	EditedAt      time.Time  `bson:"edited_at" json:"edited_at"`             // เวลาแก้ไข
+	PrevStartedAt time.Time  `bson:"prev_started_at" json:"prev_started_at"` // เวลาเริ่มเดิม
+	PrevEndedAt   *time.Time `bson:"prev_ended_at" json:"prev_ended_at"`     // เวลาสิ้นสุดเดิม
+	EditedBy      string     `bson:"edited_by" json:"edited_by"`             // ผู้แก้ไข
+	Reason        string     `bson:"reason" json:"reason"`                   // เหตุผล
+	PrevNote      string     `bson:"prev_note,omitempty" json:"prev_note,omitempty"`
+	PrevHours     float64    `bson:"prev_hours" json:"prev_hours"` // ชั่วโมงเดิม
+}
+
+// Timesheet ใบลงเวลารายสัปดาห์ (จันทร์-อาทิตย์) ที่ส่งให้ผู้จัดการอนุมัติ
+type Timesheet struct {
+	WeekStart    time.Time  `bson:"week_start" json:"week_start"`                       // วันจันทร์ของสัปดาห์ (00:00 UTC)
+	CreatedAt    time.Time  `bson:"created_at" json:"created_at"`                       // วันที่สร้าง
+	UpdatedAt    time.Time  `bson:"updated_at" json:"updated_at"`                       // วันที่แก้ไขล่าสุด
+	SubmittedAt  *time.Time `bson:"submitted_at" json:"submitted_at"`                   // วันที่ส่ง
+	ReviewedAt   *time.Time `bson:"reviewed_at" json:"reviewed_at"`                     // วันที่อนุมัติ/ตีกลับ
+	TimesheetID  string     `bson:"timesheet_id" json:"timesheet_id"`                   // รหัสใบลงเวลา (UUID)
+	UserID       string     `bson:"user_id" json:"user_id"`                             // เจ้าของ
+	DepartmentID string     `bson:"department_id" json:"department_id"`                 // แผนก
+	Status       string     `bson:"status" json:"status"`                               // submitted|approved|rejected
+	ReviewedBy   string     `bson:"reviewed_by,omitempty" json:"reviewed_by,omitempty"` // ผู้อนุมัติ
+	ReviewNote   string     `bson:"review_note,omitempty" json:"review_note,omitempty"` // หมายเหตุผู้อนุมัติ
+	TotalHours   float64    `bson:"total_hours" json:"total_hours"`                     // ชั่วโมงรวม ณ ตอนส่ง
+}
@@ -6,11 +6,12 @@ import (
 
 // User ใช้เก็บข้อมูลพนักงานในระบบ HR พร้อมเชื่อมโยงตำแหน่งงาน (PositionID) และแผนก (DepartmentID) ผ่าน FK
 type User struct {
-	BirthDate         time.Time           `bson:"birth_date" json:"birth_date"` // วันเดือนปีเกิดของพนักงาน (รูปแบบ string)
-	HireDate          time.Time           `bson:"hire_date" json:"hire_date"`   // วันที่เริ่มงาน
-	CreatedAt         time.Time           `bson:"created_at" json:"created_at"` // วันที่สร้างข้อมูลนี้
-	UpdatedAt         time.Time           `bson:"updated_at" json:"updated_at"` // วันที่แก้ไขข้อมูลล่าสุด
-	DeletedAt         *time.Time          `bson:"deleted_at" json:"deleted_at"` // วันที่ลบข้อมูล (soft delete)
+	BirthDate         time.Time           `bson:"birth_date" json:"birth_date"`                         // วันเดือนปีเกิดของพนักงาน (รูปแบบ string)
+	HireDate          time.Time           `bson:"hire_date" json:"hire_date"`                           // วันที่เริ่มงาน
+	CreatedAt         time.Time           `bson:"created_at" json:"created_at"`                         // วันที่สร้างข้อมูลนี้
+	UpdatedAt         time.Time           `bson:"updated_at" json:"updated_at"`                         // วันที่แก้ไขข้อมูลล่าสุด
+	DeletedAt         *time.Time          `bson:"deleted_at" json:"deleted_at"`                         // วันที่ลบข้อมูล (soft delete)
+	AccessRevokedAt   *time.Time          `bson:"access_revoked_at,omitempty" json:"access_revoked_at"` // วันที่ยกเลิกสิทธิ์เข้าระบบ (offboarding)
 	Note              *string             `bson:"note" json:"note"`
 	Address           Address             `bson:"address" json:"address"`                       // ที่อยู่ของพนักงาน
 	BankInfo          BankInfo            `bson:"bank_info" json:"bank_info"`                   // ข้อมูลบัญชีธนาคารของพนักงาน
@@ -22,12 +22,32 @@ type WorkFlowTemplate struct {
 }
 
 type WorkFlowStep struct {
-	CreatedAt   time.Time  `bson:"created_at" json:"created_at"`                       // วันเวลาสร้าง
-	UpdatedAt   time.Time  `bson:"updated_at" json:"updated_at"`                       // วันเวลาอัปเดตล่าสุด
-	DeletedAt   *time.Time `bson:"deleted_at" json:"deleted_at"`                       // วันที่ลบ (soft delete)
-	StepID      string     `bson:"step_id" json:"step_id"`                             // รหัส Step (UUID)
-	StepName    string     `bson:"step_name" json:"step_name"`                         // ชื่อ Step
-	Description string     `bson:"description,omitempty" json:"description,omitempty"` // รายละเอียด (ไม่บังคับ)
-	Hours       float64    `bson:"hours" json:"hours"`                                 // ชั่วโมงที่ใช้ (รองรับทศนิยม เช่น 0.5)
-	Order       int        `bson:"order" json:"order"`                                 // ลำดับขั้น (1..N)
+	CreatedAt   time.Time  `bson:"created_at" json:"created_at"`                           // วันเวลาสร้าง
+	UpdatedAt   time.Time  `bson:"updated_at" json:"updated_at"`                           // วันเวลาอัปเดตล่าสุด
+	DeletedAt   *time.Time `bson:"deleted_at" json:"deleted_at"`                           // วันที่ลบ (soft delete)
+	StepID      string     `bson:"step_id" json:"step_id"`                                 // รหัส Step (UUID)
+	StepName    string     `bson:"step_name" json:"step_name"`                             // ชื่อ Step
+	Description string     `bson:"description,omitempty" json:"description,omitempty"`     // รายละเอียด (ไม่บังคับ)
+	Hours       float64    `bson:"hours" json:"hours"`                                     // ชั่วโมงที่ใช้ (รองรับทศนิยม เช่น 0.5)
+	Order       int        `bson:"order" json:"order"`                                     // ลำดับขั้น (1..N)
+	DependsOn   []string   `bson:"depends_on,omitempty" json:"depends_on,omitempty"`       // step_id ที่ต้องเสร็จก่อน (ว่าง = เริ่มได้เลย/ลำดับเส้นตรงเดิม)
+	Department  string     `bson:"department_id,omitempty" json:"department_id,omitempty"` // แผนกที่ทำ step นี้ (ว่าง = แผนกของ template)
+	Checklist   []string   `bson:"checklist,omitempty" json:"checklist,omitempty"`         // รายการตรวจสอบที่คัดลอกไปยัง step ของงาน
+}
+
+const CollectionWorkflowTemplateVersions = "workflow_template_versions" // ประวัติเวอร์ชันของ template (แก้ไขไม่ได้)
+
+// WorkFlowTemplateVersion snapshot ของ template ณ เวอร์ชันหนึ่ง สร้างทุกครั้งที่สร้าง/แก้ไข template
+type WorkFlowTemplateVersion struct {
+	CreatedAt    time.Time      `bson:"created_at" json:"created_at"`                       // วันเวลาที่ออกเวอร์ชันนี้
+	VersionID    string         `bson:"version_id" json:"version_id"`                       // รหัสเวอร์ชัน (UUID)
+	WorkFlowID   string         `bson:"workflow_id" json:"workflow_id"`                     // รหัส Workflow
+	Version      int            `bson:"version" json:"version"`                             // เลขเวอร์ชัน (1..N)
+	WorkFlowName string         `bson:"workflow_name" json:"workflow_name"`                 // ชื่อ Workflow ณ เวอร์ชันนี้
+	Department   string         `bson:"department_id" json:"department_id"`                 // แผนก
+	Description  string         `bson:"description" json:"description"`                     // รายละเอียด
+	Steps        []WorkFlowStep `bson:"steps" json:"steps"`                                 // ขั้นตอน ณ เวอร์ชันนี้
+	TotalHours   float64        `bson:"total_hours" json:"total_hours"`                     // ชั่วโมงรวม
+	ChangeNote   string         `bson:"change_note,omitempty" json:"change_note,omitempty"` // หมายเหตุการเปลี่ยนแปลง
+	CreatedBy    string         `bson:"created_by" json:"created_by"`                       // ผู้ออกเวอร์ชัน
 }
@@ -0,0 +1,68 @@
+package helpers
+
+import (
+	"math"
+	"time"
+)
+
+const earthRadiusMeters = 6371000
+
+// HaversineMeters ระยะทางบนผิวโลกระหว่างสองพิกัด (เมตร)
+func HaversineMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(d float64) float64 { return d * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return earthRadiusMeters * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// AttendanceRule เงื่อนไขกะที่ใช้คิดเวลาเข้างาน (นาที)
+type AttendanceRule struct {
+	ShiftStart        time.Time
+	ShiftEnd          time.Time
+	BreakMinutes      int
+	LateGraceMinutes  int  // มาสายไม่เกินนี้ไม่นับสาย
+	EarlyGraceMinutes int  // ออกก่อนไม่เกินนี้ไม่นับออกก่อน
+	OTMinMinutes      int  // ทำเกินเวลาตั้งแต่นี้ขึ้นไปจึงนับ OT
+	Scheduled         bool // เป็นวันทำงานตามกะ (false = วันหยุด ทั้งหมดนับเป็น OT)
+}
+
+// AttendanceResult ผลคำนวณเวลาเข้างานของหนึ่งวัน (นาที)
+type AttendanceResult struct {
+	Worked     int
+	Late       int
+	EarlyLeave int
+	Overtime   int
+}
+
+// ComputeAttendance คำนวณเวลาทำงาน สาย ออกก่อน และ OT จากเวลาเข้า-ออก
+// หักเวลาพักเมื่อทำงานเกินเวลาพัก, สาย/ออกก่อนนับเต็มเมื่อเกินช่วงผ่อนผัน, OT นับหลังเลิกกะเท่านั้น
+func ComputeAttendance(rule AttendanceRule, checkIn, checkOut time.Time) AttendanceResult {
+	var res AttendanceResult
+	if !checkOut.After(checkIn) {
+		return res
+	}
+	span := int(checkOut.Sub(checkIn).Minutes())
+	res.Worked = span
+	if rule.BreakMinutes > 0 && span > rule.BreakMinutes {
+		res.Worked = span - rule.BreakMinutes
+	}
+
+	if !rule.Scheduled {
+		if res.Worked >= rule.OTMinMinutes {
+			res.Overtime = res.Worked
+		}
+		return res
+	}
+
+	if late := int(checkIn.Sub(rule.ShiftStart).Minutes()); late > rule.LateGraceMinutes {
+		res.Late = late
+	}
+	if early := int(rule.ShiftEnd.Sub(checkOut).Minutes()); early > rule.EarlyGraceMinutes {
+		res.EarlyLeave = early
+	}
+	if ot := int(checkOut.Sub(rule.ShiftEnd).Minutes()); ot > 0 && ot >= rule.OTMinMinutes {
+		res.Overtime = ot
+	}
+	return res
+}
@@ -0,0 +1,54 @@
+package helpers
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHaversineMeters(t *testing.T) {
+	// อนุสาวรีย์ชัยฯ -> สนามเป้า ประมาณ 1.3 กม.
+	d := HaversineMeters(13.7649, 100.5383, 13.7765, 100.5433)
+	if math.Abs(d-1400) > 100 {
+		t.Fatalf("distance = %.0f", d)
+	}
+	if d := HaversineMeters(13.7, 100.5, 13.7, 100.5); d != 0 {
+		t.Fatalf("same point = %v", d)
+	}
+}
+
+func TestComputeAttendance(t *testing.T) {
+	day := func(h, m int) time.Time { return time.Date(2025, 3, 3, h, m, 0, 0, time.UTC) }
+	rule := AttendanceRule{
+		ShiftStart:        day(8, 30),
+		ShiftEnd:          day(17, 30),
+		BreakMinutes:      60,
+		LateGraceMinutes:  5,
+		EarlyGraceMinutes: 0,
+		OTMinMinutes:      30,
+		Scheduled:         true,
+	}
+
+	got := ComputeAttendance(rule, day(8, 34), day(17, 30))
+	if got != (AttendanceResult{Worked: 476}) {
+		t.Fatalf("within grace = %+v", got)
+	}
+	got = ComputeAttendance(rule, day(8, 50), day(17, 10))
+	if got.Late != 20 || got.EarlyLeave != 20 || got.Overtime != 0 {
+		t.Fatalf("late and early = %+v", got)
+	}
+	got = ComputeAttendance(rule, day(8, 30), day(17, 50))
+	if got.Overtime != 0 {
+		t.Fatalf("ot below minimum = %+v", got)
+	}
+	got = ComputeAttendance(rule, day(8, 30), day(19, 0))
+	if got.Overtime != 90 || got.Worked != 570 {
+		t.Fatalf("overtime = %+v", got)
+	}
+
+	rule.Scheduled = false
+	got = ComputeAttendance(rule, day(9, 0), day(13, 0))
+	if got.Overtime != 180 || got.Late != 0 {
+		t.Fatalf("holiday work = %+v", got)
+	}
+}
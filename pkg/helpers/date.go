@@ -0,0 +1,49 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// bangkok timezone ไทย (Asia/Bangkok, GMT+7) โหลดครั้งเดียว ถ้าเครื่องไม่มีฐานข้อมูล timezone ใช้ offset คงที่
+var bangkok = func() *time.Location {
+	loc, err := time.LoadLocation("Asia/Bangkok")
+	if err != nil {
+		loc = time.FixedZone("Asia/Bangkok", 7*60*60)
+	}
+	return loc
+}()
+
+// BangkokLocation timezone ที่ใช้ตัดวันทั้งระบบ (cron, วันนี้, วันที่ในรายงาน)
+func BangkokLocation() *time.Location {
+	return bangkok
+}
+
+// DateOnly วันที่ของ t (ตาม timezone ของ t) เป็นเวลา 00:00 UTC เหมือนวันที่ที่เก็บในฐานข้อมูล
+func DateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Today วันนี้ตามเวลาไทย (00:00 UTC)
+func Today() time.Time {
+	return DateOnly(time.Now().In(bangkok))
+}
+
+// DayKey วันที่รูปแบบ YYYY-MM-DD ใช้เป็น key ของแผนที่รายวัน
+func DayKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// ParseDate วันที่ YYYY-MM-DD ใน timezone loc (เวลา 00:00) field ใช้ในข้อความผิดพลาด
+func ParseDate(field, v string, loc *time.Location) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return time.Time{}, fmt.Errorf("%s is required", field)
+	}
+	t, err := time.ParseInLocation("2006-01-02", v, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: %s (use YYYY-MM-DD)", field, v)
+	}
+	return t, nil
+}
@@ -0,0 +1,30 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDateOnlyUsesLocalCalendarDay(t *testing.T) {
+	// 2025-01-01 01:30 เวลาไทย = 2024-12-31 18:30 UTC ต้องได้วันที่ 1 ม.ค.
+	at := time.Date(2024, 12, 31, 18, 30, 0, 0, time.UTC).In(BangkokLocation())
+	if got := DayKey(DateOnly(at)); got != "2025-01-01" {
+		t.Fatalf("DateOnly(%s) = %s, want 2025-01-01", at, got)
+	}
+	if got := DateOnly(at); got.Location() != time.UTC || got.Hour() != 0 {
+		t.Fatalf("DateOnly() = %s, want midnight UTC", got)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	got, err := ParseDate("date", " 2025-02-28 ", time.UTC)
+	if err != nil || !got.Equal(time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("ParseDate() = %s, %v", got, err)
+	}
+	if _, err := ParseDate("date", "", time.UTC); err == nil {
+		t.Fatal("ParseDate(empty) should fail")
+	}
+	if _, err := ParseDate("date", "28/02/2025", time.UTC); err == nil {
+		t.Fatal("ParseDate(wrong layout) should fail")
+	}
+}
@@ -64,20 +64,14 @@ func DateToISO(s string) (time.Time, error) {
 	return t, nil
 }
 
-func KPIFromScores(scores []float64, maxScore float64) float64 {
-	if len(scores) == 0 || maxScore <= 0 {
+// KPIFromScores KPI รวมของผู้ใช้ = ค่าเฉลี่ยคะแนนที่แต่ละการประเมินคิดตามวิธีของ template ตัวเอง (0..100)
+func KPIFromScores(results []KPIResult) float64 {
+	if len(results) == 0 {
 		return 0
 	}
 	var sum float64
-	for _, s := range scores {
-		if s < 0 {
-			s = 0
-		}
-		if s > maxScore {
-			s = maxScore
-		}
-		sum += s
+	for _, r := range results {
+		sum += math.Max(0, math.Min(r.Total, 100))
 	}
-	percent := (sum / (maxScore * float64(len(scores)))) * 100.0
-	return math.Round(percent*100) / 100
+	return round2(sum / float64(len(results)))
 }
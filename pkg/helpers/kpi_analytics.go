@@ -0,0 +1,100 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// ช่วงเวลาที่ใช้จัดกลุ่มกราฟแนวโน้ม KPI
+const (
+	KPIIntervalMonth   = "month"
+	KPIIntervalQuarter = "quarter"
+)
+
+// KPIPeriodKey คีย์ช่วงเวลาของวันที่ เช่น 2025-03 (month) หรือ 2025-Q1 (quarter)
+func KPIPeriodKey(t time.Time, interval string) string {
+	if interval == KPIIntervalQuarter {
+		return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())-1)/3+1)
+	}
+	return t.Format("2006-01")
+}
+
+// KPIPeriodKeys คีย์ทุกช่วงตั้งแต่ from ถึง to (รวมช่วงที่ว่าง เพื่อให้กราฟต่อเนื่อง)
+func KPIPeriodKeys(from, to time.Time, interval string) []string {
+	keys := []string{}
+	if to.Before(from) {
+		return keys
+	}
+	step := 1
+	if interval == KPIIntervalQuarter {
+		step = 3
+	}
+	cur := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location())
+	if interval == KPIIntervalQuarter {
+		cur = time.Date(from.Year(), time.Month((int(from.Month())-1)/3*3+1), 1, 0, 0, 0, 0, from.Location())
+	}
+	for !cur.After(to) {
+		keys = append(keys, KPIPeriodKey(cur, interval))
+		cur = cur.AddDate(0, step, 0)
+	}
+	return keys
+}
+
+// KPIHistogram นับจำนวนค่าในแต่ละช่วงคะแนน 0..100 ตามขนาดช่วง (ช่วงสุดท้ายรวม 100)
+func KPIHistogram(values []float64, bucketSize int) []int {
+	if bucketSize <= 0 || bucketSize > 100 {
+		bucketSize = 10
+	}
+	n := (100 + bucketSize - 1) / bucketSize
+	counts := make([]int, n)
+	for _, v := range values {
+		v = math.Max(0, math.Min(v, 100))
+		i := int(v) / bucketSize
+		if i >= n {
+			i = n - 1
+		}
+		counts[i]++
+	}
+	return counts
+}
+
+// PearsonCorrelation สหสัมพันธ์ของสองชุดค่า (-1..1) คืน false ถ้าข้อมูลไม่พอ (น้อยกว่า 3 คู่) หรือค่าชุดใดไม่แปรผัน
+func PearsonCorrelation(xs, ys []float64) (float64, bool) {
+	n := len(xs)
+	if n != len(ys) || n < 3 {
+		return 0, false
+	}
+	var sx, sy float64
+	for i := range xs {
+		sx += xs[i]
+		sy += ys[i]
+	}
+	mx, my := sx/float64(n), sy/float64(n)
+	var cov, vx, vy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		cov += dx * dy
+		vx += dx * dx
+		vy += dy * dy
+	}
+	if vx == 0 || vy == 0 {
+		return 0, false
+	}
+	return math.Round(cov/math.Sqrt(vx*vy)*1000) / 1000, true
+}
+
+// Median ค่ามัธยฐาน (ชุดว่าง = 0)
+func Median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return round2((sorted[mid-1] + sorted[mid]) / 2)
+	}
+	return round2(sorted[mid])
+}
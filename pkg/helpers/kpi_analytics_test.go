@@ -0,0 +1,41 @@
+package helpers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestKPIPeriodKeys(t *testing.T) {
+	from := time.Date(2025, 2, 15, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	if got := KPIPeriodKeys(from, to, KPIIntervalMonth); !reflect.DeepEqual(got, []string{"2025-02", "2025-03", "2025-04", "2025-05", "2025-06", "2025-07"}) {
+		t.Fatalf("month keys = %v", got)
+	}
+	if got := KPIPeriodKeys(from, to, KPIIntervalQuarter); !reflect.DeepEqual(got, []string{"2025-Q1", "2025-Q2", "2025-Q3"}) {
+		t.Fatalf("quarter keys = %v", got)
+	}
+}
+
+func TestKPIHistogram(t *testing.T) {
+	got := KPIHistogram([]float64{0, 9.99, 10, 55, 100, 120}, 25)
+	if !reflect.DeepEqual(got, []int{3, 0, 1, 2}) {
+		t.Fatalf("histogram = %v", got)
+	}
+}
+
+func TestPearsonCorrelation(t *testing.T) {
+	if r, ok := PearsonCorrelation([]float64{1, 2, 3, 4}, []float64{2, 4, 6, 8}); !ok || r != 1 {
+		t.Fatalf("positive = %v %v", r, ok)
+	}
+	if r, ok := PearsonCorrelation([]float64{1, 2, 3}, []float64{3, 2, 1}); !ok || r != -1 {
+		t.Fatalf("negative = %v %v", r, ok)
+	}
+	if _, ok := PearsonCorrelation([]float64{1, 2, 3}, []float64{5, 5, 5}); ok {
+		t.Fatal("constant series should not correlate")
+	}
+	if _, ok := PearsonCorrelation([]float64{1, 2}, []float64{1, 2}); ok {
+		t.Fatal("two points should not be enough")
+	}
+}
@@ -0,0 +1,197 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/Be2Bag/erp-demo/models"
+)
+
+// วิธีคิดคะแนน KPI ของ template
+const (
+	KPIScoringWeighted = "weighted" // ถ่วงน้ำหนักตาม weight ของแต่ละ item (คะแนน/คะแนนเต็ม) ค่าเริ่มต้น
+	KPIScoringAverage  = "average"  // เฉลี่ยเปอร์เซ็นต์ของแต่ละ item เท่ากันทุกข้อ (ไม่สน weight)
+	KPIScoringBands    = "bands"    // คิดแบบถ่วงน้ำหนักแล้วตัดเกรดตามช่วง (A/B/C/D) ใช้คะแนนของเกรด
+)
+
+// KPIResult ผลคิดคะแนนของการประเมินหนึ่งครั้ง
+type KPIResult struct {
+	Total   float64 // คะแนนที่ใช้จริง 0..100 (แบบ bands = คะแนนของเกรด)
+	Percent float64 // เปอร์เซ็นต์ที่ทำได้จริงก่อนตัดเกรด 0..100
+	Grade   string  // เกรด (เฉพาะแบบ bands)
+}
+
+// KPIScoringStrategy วิธีคิดคะแนนจากคะแนนราย item
+type KPIScoringStrategy interface {
+	Method() string
+	Score(items []models.KPIScore) KPIResult
+}
+
+// DefaultKPIBands ช่วงเกรดเริ่มต้นเมื่อเลือกแบบ bands แต่ไม่ได้กำหนดเอง
+func DefaultKPIBands() []models.KPIRatingBand {
+	return []models.KPIRatingBand{
+		{Grade: "A", MinPercent: 90, Score: 100},
+		{Grade: "B", MinPercent: 75, Score: 80},
+		{Grade: "C", MinPercent: 60, Score: 60},
+		{Grade: "D", MinPercent: 0, Score: 40},
+	}
+}
+
+// NormalizeKPIScoringMethod ตรวจชื่อวิธีคิดคะแนน (ว่าง = weighted)
+func NormalizeKPIScoringMethod(method string) (string, error) {
+	m := strings.ToLower(strings.TrimSpace(method))
+	switch m {
+	case "":
+		return KPIScoringWeighted, nil
+	case KPIScoringWeighted, KPIScoringAverage, KPIScoringBands:
+		return m, nil
+	}
+	return "", fmt.Errorf("invalid scoring_method: %s (allow: weighted|average|bands)", method)
+}
+
+// NormalizeKPIBands ตรวจช่วงเกรดและเรียงจากช่วงสูงไปต่ำ ต้องมีช่วงที่เริ่มที่ 0 เพื่อให้ทุกคะแนนได้เกรด
+func NormalizeKPIBands(bands []models.KPIRatingBand) ([]models.KPIRatingBand, error) {
+	if len(bands) == 0 {
+		return DefaultKPIBands(), nil
+	}
+	out := make([]models.KPIRatingBand, 0, len(bands))
+	seenGrade := make(map[string]bool, len(bands))
+	seenMin := make(map[float64]bool, len(bands))
+	hasZero := false
+	for i, b := range bands {
+		grade := strings.TrimSpace(b.Grade)
+		if grade == "" {
+			return nil, fmt.Errorf("bands[%d].grade is required", i)
+		}
+		key := strings.ToUpper(grade)
+		if seenGrade[key] {
+			return nil, fmt.Errorf("bands[%d].grade duplicated: %s", i, grade)
+		}
+		seenGrade[key] = true
+		if b.MinPercent < 0 || b.MinPercent > 100 {
+			return nil, fmt.Errorf("bands[%d].min_percent must be between 0 and 100", i)
+		}
+		if seenMin[b.MinPercent] {
+			return nil, fmt.Errorf("bands[%d].min_percent duplicated: %v", i, b.MinPercent)
+		}
+		seenMin[b.MinPercent] = true
+		if b.Score < 0 || b.Score > 100 {
+			return nil, fmt.Errorf("bands[%d].score must be between 0 and 100", i)
+		}
+		if b.MinPercent == 0 {
+			hasZero = true
+		}
+		out = append(out, models.KPIRatingBand{Grade: grade, MinPercent: b.MinPercent, Score: b.Score})
+	}
+	if !hasZero {
+		return nil, fmt.Errorf("bands must include a band with min_percent 0")
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].MinPercent > out[j].MinPercent })
+	return out, nil
+}
+
+// NewKPIScoringStrategy สร้างวิธีคิดคะแนนตาม template (ว่าง = weighted)
+func NewKPIScoringStrategy(method string, bands []models.KPIRatingBand) (KPIScoringStrategy, error) {
+	m, err := NormalizeKPIScoringMethod(method)
+	if err != nil {
+		return nil, err
+	}
+	switch m {
+	case KPIScoringAverage:
+		return averageKPIStrategy{}, nil
+	case KPIScoringBands:
+		normalized, err := NormalizeKPIBands(bands)
+		if err != nil {
+			return nil, err
+		}
+		return bandKPIStrategy{bands: normalized}, nil
+	}
+	return weightedKPIStrategy{}, nil
+}
+
+type weightedKPIStrategy struct{}
+
+func (weightedKPIStrategy) Method() string { return KPIScoringWeighted }
+
+func (weightedKPIStrategy) Score(items []models.KPIScore) KPIResult {
+	p := WeightedKPIPercent(items)
+	return KPIResult{Total: p, Percent: p}
+}
+
+type averageKPIStrategy struct{}
+
+func (averageKPIStrategy) Method() string { return KPIScoringAverage }
+
+func (averageKPIStrategy) Score(items []models.KPIScore) KPIResult {
+	var sum float64
+	n := 0
+	for _, it := range items {
+		if it.MaxScore <= 0 {
+			continue
+		}
+		sum += kpiItemRatio(it)
+		n++
+	}
+	if n == 0 {
+		return KPIResult{}
+	}
+	p := round2(sum / float64(n) * 100)
+	return KPIResult{Total: p, Percent: p}
+}
+
+type bandKPIStrategy struct {
+	bands []models.KPIRatingBand // เรียงจาก min_percent มากไปน้อย
+}
+
+func (bandKPIStrategy) Method() string { return KPIScoringBands }
+
+func (b bandKPIStrategy) Score(items []models.KPIScore) KPIResult {
+	p := WeightedKPIPercent(items)
+	if band, ok := KPIBandFor(b.bands, p); ok {
+		return KPIResult{Total: band.Score, Percent: p, Grade: band.Grade}
+	}
+	return KPIResult{Percent: p}
+}
+
+// KPIBandFor ช่วงเกรดของเปอร์เซ็นต์ (bands ต้องผ่าน NormalizeKPIBands แล้ว)
+func KPIBandFor(bands []models.KPIRatingBand, percent float64) (models.KPIRatingBand, bool) {
+	for _, band := range bands {
+		if percent >= band.MinPercent {
+			return band, true
+		}
+	}
+	return models.KPIRatingBand{}, false
+}
+
+// WeightedKPIPercent Σ(weight × คะแนน/คะแนนเต็ม) / Σweight × 100 (weight รวมเป็น 0 = ถือว่าเท่ากันทุกข้อ)
+func WeightedKPIPercent(items []models.KPIScore) float64 {
+	var sum, weights float64
+	for _, it := range items {
+		if it.MaxScore <= 0 || it.Weight <= 0 {
+			continue
+		}
+		sum += float64(it.Weight) * kpiItemRatio(it)
+		weights += float64(it.Weight)
+	}
+	if weights == 0 {
+		return averageKPIStrategy{}.Score(items).Percent
+	}
+	return round2(sum / weights * 100)
+}
+
+func kpiItemRatio(it models.KPIScore) float64 {
+	s := float64(it.Score)
+	if s < 0 {
+		s = 0
+	}
+	if s > float64(it.MaxScore) {
+		s = float64(it.MaxScore)
+	}
+	return s / float64(it.MaxScore)
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
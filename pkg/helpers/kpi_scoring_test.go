@@ -0,0 +1,47 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/Be2Bag/erp-demo/models"
+)
+
+func TestKPIScoringStrategies(t *testing.T) {
+	items := []models.KPIScore{
+		{Weight: 70, MaxScore: 10, Score: 10},
+		{Weight: 30, MaxScore: 5, Score: 1},
+	}
+
+	weighted, err := NewKPIScoringStrategy("", nil)
+	if err != nil {
+		t.Fatalf("weighted: %v", err)
+	}
+	if got := weighted.Score(items); got.Total != 76 || weighted.Method() != KPIScoringWeighted {
+		t.Fatalf("weighted = %+v, want total 76", got)
+	}
+
+	average, _ := NewKPIScoringStrategy(KPIScoringAverage, nil)
+	if got := average.Score(items); got.Total != 60 {
+		t.Fatalf("average = %+v, want total 60", got)
+	}
+
+	bands, _ := NewKPIScoringStrategy(KPIScoringBands, nil)
+	if got := bands.Score(items); got.Grade != "B" || got.Total != 80 || got.Percent != 76 {
+		t.Fatalf("bands = %+v, want grade B total 80 percent 76", got)
+	}
+}
+
+func TestKPIScoringValidation(t *testing.T) {
+	if _, err := NewKPIScoringStrategy("median", nil); err == nil {
+		t.Fatal("expected error for unknown scoring method")
+	}
+	if _, err := NormalizeKPIBands([]models.KPIRatingBand{{Grade: "A", MinPercent: 50, Score: 100}}); err == nil {
+		t.Fatal("expected error when no band starts at 0")
+	}
+	if _, err := NormalizeKPIBands([]models.KPIRatingBand{
+		{Grade: "A", MinPercent: 0, Score: 100},
+		{Grade: "a", MinPercent: 50, Score: 90},
+	}); err == nil {
+		t.Fatal("expected error for duplicated grade")
+	}
+}
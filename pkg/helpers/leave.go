@@ -0,0 +1,44 @@
+package helpers
+
+import (
+	"math"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/models"
+)
+
+// ServiceMonths อายุงานเป็นเดือนเต็ม ณ วันที่ asOf (ยังไม่เริ่มงาน = 0)
+func ServiceMonths(hireDate, asOf time.Time) int {
+	if hireDate.IsZero() || asOf.Before(hireDate) {
+		return 0
+	}
+	months := (asOf.Year()-hireDate.Year())*12 + int(asOf.Month()-hireDate.Month())
+	if asOf.Day() < hireDate.Day() {
+		months--
+	}
+	if months < 0 {
+		return 0
+	}
+	return months
+}
+
+// LeaveEntitlement สิทธิ์ลาต่อปีตามขั้นอายุงานสูงสุดที่ถึง (ไม่ถึงขั้นใดเลย = 0)
+func LeaveEntitlement(tiers []models.LeaveTier, serviceMonths int) float64 {
+	best := -1
+	days := 0.0
+	for _, t := range tiers {
+		if serviceMonths >= t.MinServiceMonths && t.MinServiceMonths > best {
+			best = t.MinServiceMonths
+			days = t.Days
+		}
+	}
+	return days
+}
+
+// LeaveCarryOver วันที่ยกไปปีถัดไป = วันคงเหลือ ไม่เกินเพดาน (ติดลบ/ไม่มีเพดาน = 0)
+func LeaveCarryOver(remaining, maxCarryOver float64) float64 {
+	if remaining <= 0 || maxCarryOver <= 0 {
+		return 0
+	}
+	return math.Min(remaining, maxCarryOver)
+}
@@ -0,0 +1,51 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/models"
+)
+
+func TestServiceMonths(t *testing.T) {
+	hire := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		asOf time.Time
+		want int
+	}{
+		{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 0},
+		{time.Date(2024, 4, 14, 0, 0, 0, 0, time.UTC), 0},
+		{time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC), 12},
+		{time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC), 21},
+	}
+	for _, c := range cases {
+		if got := ServiceMonths(hire, c.asOf); got != c.want {
+			t.Fatalf("ServiceMonths(%s) = %d, want %d", c.asOf.Format("2006-01-02"), got, c.want)
+		}
+	}
+}
+
+func TestLeaveEntitlement(t *testing.T) {
+	tiers := []models.LeaveTier{{MinServiceMonths: 60, Days: 10}, {MinServiceMonths: 12, Days: 6}, {MinServiceMonths: 36, Days: 8}}
+	for months, want := range map[int]float64{0: 0, 11: 0, 12: 6, 40: 8, 120: 10} {
+		if got := LeaveEntitlement(tiers, months); got != want {
+			t.Fatalf("LeaveEntitlement(%d) = %v, want %v", months, got, want)
+		}
+	}
+}
+
+func TestLeaveCarryOver(t *testing.T) {
+	if got := LeaveCarryOver(4.5, 3); got != 3 {
+		t.Fatalf("capped carry = %v", got)
+	}
+	if got := LeaveCarryOver(1.5, 3); got != 1.5 {
+		t.Fatalf("carry = %v", got)
+	}
+	if got := LeaveCarryOver(-2, 3); got != 0 {
+		t.Fatalf("negative carry = %v", got)
+	}
+	if got := LeaveCarryOver(5, 0); got != 0 {
+		t.Fatalf("no carry policy = %v", got)
+	}
+}
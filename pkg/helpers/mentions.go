@@ -0,0 +1,34 @@
+package helpers
+
+import (
+	"regexp"
+	"strings"
+)
+
+// mentionPattern รูปแบบ @[ชื่อที่แสดง](user_id) ที่ FE แทรกเมื่อเลือกผู้ใช้
+var mentionPattern = regexp.MustCompile(`@\[([^\]]*)\]\(([^)\s]+)\)`)
+
+// ParseMentions คืน user_id ที่ถูก @mention ในข้อความ (ไม่ซ้ำ เรียงตามลำดับที่พบ)
+func ParseMentions(body string) []string {
+	matches := mentionPattern.FindAllStringSubmatch(body, -1)
+	ids := make([]string, 0, len(matches))
+	for _, m := range matches {
+		ids = append(ids, m[2])
+	}
+	return UniqueStrings(ids...)
+}
+
+// UniqueStrings ตัดค่าว่างและค่าซ้ำ โดยคงลำดับเดิม
+func UniqueStrings(values ...string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
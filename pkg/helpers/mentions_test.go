@@ -0,0 +1,19 @@
+package helpers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	body := "@[สมชาย](u-1) ช่วยดูแบบ @[Ann](u-2) และ @[สมชาย](u-1) อีกครั้ง, @someone ไม่ใช่ mention"
+	got := ParseMentions(body)
+	want := []string{"u-1", "u-2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseMentions() = %v, want %v", got, want)
+	}
+
+	if got := ParseMentions("ไม่มี mention"); len(got) != 0 {
+		t.Fatalf("expected no mentions, got %v", got)
+	}
+}
@@ -0,0 +1,114 @@
+package helpers
+
+import (
+	"math"
+	"time"
+)
+
+// อัตราประกันสังคมและค่าลดหย่อนภาษีที่ใช้คิดภาษีหัก ณ ที่จ่าย (ภ.ง.ด.1)
+const (
+	SSFRate             = 0.05
+	SSFMinWage          = 1650
+	TaxPersonalAllow    = 60000  // ลดหย่อนส่วนตัว
+	TaxExpenseRate      = 0.5    // หักค่าใช้จ่ายเงินเดือน 50%
+	TaxExpenseMax       = 100000 // ไม่เกิน 100,000 บาท
+	payrollMonthsInYear = 12
+)
+
+// SSFWageCap เพดานค่าจ้างที่ใช้คิดเงินสมทบประกันสังคม มีผลตั้งแต่งวดเดือน From
+type SSFWageCap struct {
+	From    time.Time
+	MaxWage float64
+}
+
+// ssfWageCaps เพดานค่าจ้างตามกฎกระทรวง เรียงตามวันที่มีผล (คำนวณรอบเดือนเก่าใหม่ใช้เพดานของเดือนนั้น)
+var ssfWageCaps = []SSFWageCap{
+	{From: time.Time{}, MaxWage: 15000},
+	{From: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), MaxWage: 17500},
+	{From: time.Date(2029, 1, 1, 0, 0, 0, 0, time.UTC), MaxWage: 20000},
+	{From: time.Date(2032, 1, 1, 0, 0, 0, 0, time.UTC), MaxWage: 23000},
+}
+
+// SSFMaxWage เพดานค่าจ้างประกันสังคมของงวดเดือน period
+func SSFMaxWage(period time.Time) float64 {
+	maxWage := ssfWageCaps[0].MaxWage
+	for _, c := range ssfWageCaps {
+		if period.Before(c.From) {
+			break
+		}
+		maxWage = c.MaxWage
+	}
+	return maxWage
+}
+
+// TaxSSFDeductionMax ลดหย่อนเงินสมทบประกันสังคมได้ไม่เกินเงินสมทบสูงสุดทั้งปีของปีนั้น
+func TaxSSFDeductionMax(year int) float64 {
+	return SSFMaxWage(time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)) * SSFRate * payrollMonthsInYear
+}
+
+// taxBrackets อัตราภาษีเงินได้บุคคลธรรมดาแบบขั้นบันได (เพดานเงินได้สุทธิ, อัตรา)
+var taxBrackets = []struct {
+	upTo float64
+	rate float64
+}{
+	{150000, 0},
+	{300000, 0.05},
+	{500000, 0.10},
+	{750000, 0.15},
+	{1000000, 0.20},
+	{2000000, 0.25},
+	{5000000, 0.30},
+	{math.Inf(1), 0.35},
+}
+
+// SocialSecurity เงินสมทบประกันสังคมรายเดือน ฐานค่าจ้างตั้งแต่ 1,650 บาทถึงเพดานของงวด ปัดเป็นบาท (ไม่มีค่าจ้าง = 0)
+func SocialSecurity(wage float64, period time.Time) float64 {
+	if wage <= 0 {
+		return 0
+	}
+	base := math.Min(math.Max(wage, SSFMinWage), SSFMaxWage(period))
+	return math.Round(base * SSFRate)
+}
+
+// PersonalIncomeTax ภาษีเงินได้บุคคลธรรมดาทั้งปีจากเงินได้สุทธิ ตามอัตราก้าวหน้า
+func PersonalIncomeTax(netIncome float64) float64 {
+	tax, lower := 0.0, 0.0
+	for _, b := range taxBrackets {
+		if netIncome <= lower {
+			break
+		}
+		tax += (math.Min(netIncome, b.upTo) - lower) * b.rate
+		lower = b.upTo
+	}
+	return math.Round(tax*100) / 100
+}
+
+// WithholdingInput ยอดสะสมก่อนเดือนนี้และยอดของเดือนนี้ สำหรับคิดภาษีหัก ณ ที่จ่าย
+type WithholdingInput struct {
+	Year           int     // ปีภาษี (ใช้หาเพดานลดหย่อนประกันสังคม)
+	Month          int     // เดือนที่จ่าย 1-12
+	YTDIncome      float64 // เงินได้พึงประเมินสะสมก่อนเดือนนี้
+	YTDTax         float64 // ภาษีที่หักไปแล้ว
+	YTDSSF         float64 // ประกันสังคมที่หักไปแล้ว
+	Income         float64 // เงินได้พึงประเมินเดือนนี้
+	SSF            float64 // ประกันสังคมเดือนนี้
+	OtherDeduction float64 // ค่าลดหย่อนอื่นทั้งปีที่พนักงานแจ้ง (คู่สมรส บุตร ประกัน ฯลฯ)
+}
+
+// MonthlyWithholding ภาษีหัก ณ ที่จ่ายของเดือน: ประมาณเงินได้ทั้งปีจากยอดสะสม + เดือนนี้ x เดือนที่เหลือ
+// แล้วเฉลี่ยภาษีที่ยังไม่ได้หักลงเดือนที่เหลือ (เงินได้เปลี่ยนกลางปีจะปรับยอดให้เอง)
+func MonthlyWithholding(in WithholdingInput) float64 {
+	if in.Month < 1 || in.Month > payrollMonthsInYear || in.Income <= 0 {
+		return 0
+	}
+	remaining := float64(payrollMonthsInYear - in.Month + 1)
+	income := in.YTDIncome + in.Income*remaining
+	ssf := math.Min(in.YTDSSF+in.SSF*remaining, TaxSSFDeductionMax(in.Year))
+	expense := math.Min(income*TaxExpenseRate, TaxExpenseMax)
+	net := income - expense - TaxPersonalAllow - ssf - in.OtherDeduction
+	tax := PersonalIncomeTax(net) - in.YTDTax
+	if tax <= 0 {
+		return 0
+	}
+	return math.Round(tax/remaining*100) / 100
+}
@@ -0,0 +1,74 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSocialSecurity(t *testing.T) {
+	period := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	cases := map[float64]float64{0: 0, 1000: 83, 12000: 600, 15000: 750, 50000: 750}
+	for wage, want := range cases {
+		if got := SocialSecurity(wage, period); got != want {
+			t.Fatalf("SocialSecurity(%v) = %v, want %v", wage, got, want)
+		}
+	}
+}
+
+func TestSocialSecurityWageCapByPeriod(t *testing.T) {
+	cases := []struct {
+		period time.Time
+		want   float64
+	}{
+		{time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), 750},
+		{time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 875},
+		{time.Date(2029, 3, 1, 0, 0, 0, 0, time.UTC), 1000},
+		{time.Date(2033, 1, 1, 0, 0, 0, 0, time.UTC), 1150},
+	}
+	for _, c := range cases {
+		if got := SocialSecurity(50000, c.period); got != c.want {
+			t.Fatalf("SocialSecurity(50000, %s) = %v, want %v", c.period.Format("2006-01"), got, c.want)
+		}
+	}
+	if got := TaxSSFDeductionMax(2025); got != 9000 {
+		t.Fatalf("TaxSSFDeductionMax(2025) = %v, want 9000", got)
+	}
+	if got := TaxSSFDeductionMax(2026); got != 10500 {
+		t.Fatalf("TaxSSFDeductionMax(2026) = %v, want 10500", got)
+	}
+}
+
+func TestPersonalIncomeTax(t *testing.T) {
+	cases := map[float64]float64{
+		-5000:   0,
+		150000:  0,
+		300000:  7500,
+		500000:  27500,
+		1000000: 115000,
+		6000000: 1615000,
+	}
+	for net, want := range cases {
+		if got := PersonalIncomeTax(net); got != want {
+			t.Fatalf("PersonalIncomeTax(%v) = %v, want %v", net, got, want)
+		}
+	}
+}
+
+func TestMonthlyWithholding(t *testing.T) {
+	// เงินเดือน 50,000 ทั้งปี: เงินได้ 600,000 หักค่าใช้จ่าย 100,000 ลดหย่อน 60,000 + 9,000 = สุทธิ 431,000 ภาษี 20,600
+	in := WithholdingInput{Month: 1, Income: 50000, SSF: 750}
+	jan := MonthlyWithholding(in)
+	if jan != 1716.67 {
+		t.Fatalf("january = %v", jan)
+	}
+
+	// ขึ้นเงินเดือนเดือน 7 ภาษีที่เหลือเฉลี่ยลง 6 เดือนหลัง
+	in = WithholdingInput{Month: 7, YTDIncome: 300000, YTDTax: 10300, YTDSSF: 4500, Income: 60000, SSF: 750}
+	if got := MonthlyWithholding(in); got != 2716.67 {
+		t.Fatalf("after raise = %v", got)
+	}
+
+	if got := MonthlyWithholding(WithholdingInput{Month: 3, Income: 15000, SSF: 750}); got != 0 {
+		t.Fatalf("below threshold = %v", got)
+	}
+}
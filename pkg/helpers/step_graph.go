@@ -0,0 +1,170 @@
+package helpers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Be2Bag/erp-demo/models"
+)
+
+// ValidateStepGraph ตรวจกราฟ prerequisite ของ step (key = step, value = step ที่ต้องเสร็จก่อน)
+// ห้ามอ้าง step ที่ไม่มีอยู่ ห้ามอ้างตัวเอง และห้ามมีวงวน
+func ValidateStepGraph(deps map[string][]string) error {
+	keys := make([]string, 0, len(deps))
+	for k := range deps {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys) // ให้ข้อความ error คงที่
+
+	for _, k := range keys {
+		for _, d := range deps[k] {
+			if d == k {
+				return fmt.Errorf("step %s cannot depend on itself", k)
+			}
+			if _, ok := deps[d]; !ok {
+				return fmt.Errorf("step %s depends on unknown step %s", k, d)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(deps))
+	var path []string
+	var visit func(k string) error
+	visit = func(k string) error {
+		switch state[k] {
+		case visiting:
+			// ตัด path ให้เหลือเฉพาะส่วนที่เป็นวง
+			for i, p := range path {
+				if p == k {
+					return fmt.Errorf("step dependencies contain a cycle: %s -> %s", strings.Join(path[i:], " -> "), k)
+				}
+			}
+			return fmt.Errorf("step dependencies contain a cycle at step %s", k)
+		case visited:
+			return nil
+		}
+		state[k] = visiting
+		path = append(path, k)
+		for _, d := range deps[k] {
+			if err := visit(d); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[k] = visited
+		return nil
+	}
+	for _, k := range keys {
+		if err := visit(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasStepDependencies คืน true ถ้า workflow ของงานกำหนด prerequisite ไว้ (ไม่ใช่ลำดับเส้นตรงแบบเดิม)
+func HasStepDependencies(steps []models.TaskWorkflowStep) bool {
+	for _, st := range steps {
+		if len(st.DependsOn) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func stepClosed(status string) bool {
+	return status == "done" || status == "skip"
+}
+
+// PendingPrerequisites คืน step ที่ต้องเสร็จ (done/skip) ก่อนเริ่ม stepID แต่ยังไม่เสร็จ
+// workflow แบบเส้นตรงเดิม (ไม่มี depends_on) จะไม่บังคับลำดับ
+func PendingPrerequisites(steps []models.TaskWorkflowStep, stepID string) []models.TaskWorkflowStep {
+	byID := make(map[string]models.TaskWorkflowStep, len(steps))
+	var target *models.TaskWorkflowStep
+	for i := range steps {
+		byID[steps[i].StepID] = steps[i]
+		if steps[i].StepID == stepID {
+			target = &steps[i]
+		}
+	}
+	if target == nil {
+		return nil
+	}
+
+	pending := make([]models.TaskWorkflowStep, 0)
+	for _, id := range target.DependsOn {
+		if pre, ok := byID[id]; ok && !stepClosed(pre.Status) {
+			pending = append(pending, pre)
+		}
+	}
+	return pending
+}
+
+// ReadySteps คืน step สถานะ todo ที่เริ่มได้ทันที (prerequisite เสร็จครบแล้ว) เรียงตาม order
+// workflow แบบเส้นตรงเดิมถือว่า step ก่อนหน้าทั้งหมดเป็น prerequisite จึงได้ step todo ตัวแรกตัวเดียว
+func ReadySteps(steps []models.TaskWorkflowStep) []models.TaskWorkflowStep {
+	ordered := make([]models.TaskWorkflowStep, len(steps))
+	copy(ordered, steps)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Order < ordered[j].Order })
+
+	ready := make([]models.TaskWorkflowStep, 0)
+	if !HasStepDependencies(steps) {
+		for _, st := range ordered {
+			if st.Status == "todo" {
+				ready = append(ready, st)
+				break
+			}
+			if !stepClosed(st.Status) {
+				break
+			}
+		}
+		return ready
+	}
+
+	for _, st := range ordered {
+		if st.Status != "todo" {
+			continue
+		}
+		if len(PendingPrerequisites(steps, st.StepID)) == 0 {
+			ready = append(ready, st)
+		}
+	}
+	return ready
+}
+
+// ReadyStepNames คืนชื่อ step ที่เริ่มได้ทันที (ใช้เก็บใน task.ready_steps)
+func ReadyStepNames(steps []models.TaskWorkflowStep) []string {
+	ready := ReadySteps(steps)
+	names := make([]string, 0, len(ready))
+	for _, st := range ready {
+		names = append(names, st.StepName)
+	}
+	return names
+}
+
+// CurrentStepName เลือกชื่อ step ปัจจุบันของงาน (in_progress > step ที่เริ่มได้ > todo > สุดท้าย)
+func CurrentStepName(steps []models.TaskWorkflowStep) string {
+	for _, st := range steps {
+		if st.Status == "in_progress" {
+			return st.StepName
+		}
+	}
+	if ready := ReadySteps(steps); len(ready) > 0 {
+		return ready[0].StepName
+	}
+	for _, st := range steps {
+		if st.Status == "todo" {
+			return st.StepName
+		}
+	}
+	if len(steps) > 0 {
+		return steps[len(steps)-1].StepName
+	}
+	return ""
+}
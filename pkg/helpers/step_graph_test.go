@@ -0,0 +1,72 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Be2Bag/erp-demo/models"
+)
+
+func TestValidateStepGraph(t *testing.T) {
+	ok := map[string][]string{
+		"design":  nil,
+		"print":   {"design"},
+		"frame":   {"design"},
+		"install": {"print", "frame"},
+	}
+	if err := ValidateStepGraph(ok); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cycle := map[string][]string{
+		"a": {"c"},
+		"b": {"a"},
+		"c": {"b"},
+	}
+	err := ValidateStepGraph(cycle)
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+
+	if err := ValidateStepGraph(map[string][]string{"a": {"a"}}); err == nil {
+		t.Fatal("expected self dependency error")
+	}
+	if err := ValidateStepGraph(map[string][]string{"a": {"x"}}); err == nil {
+		t.Fatal("expected unknown step error")
+	}
+}
+
+func TestReadySteps(t *testing.T) {
+	steps := []models.TaskWorkflowStep{
+		{StepID: "1", StepName: "design", Order: 1, Status: "done"},
+		{StepID: "2", StepName: "print", Order: 2, Status: "todo", DependsOn: []string{"1"}},
+		{StepID: "3", StepName: "frame", Order: 3, Status: "todo", DependsOn: []string{"1"}},
+		{StepID: "4", StepName: "install", Order: 4, Status: "todo", DependsOn: []string{"2", "3"}},
+	}
+	got := ReadyStepNames(steps)
+	if strings.Join(got, ",") != "print,frame" {
+		t.Fatalf("ready = %v, want [print frame]", got)
+	}
+	if len(PendingPrerequisites(steps, "4")) != 2 {
+		t.Fatal("install should wait for print and frame")
+	}
+
+	steps[1].Status = "done"
+	steps[2].Status = "skip"
+	if got := ReadyStepNames(steps); strings.Join(got, ",") != "install" {
+		t.Fatalf("ready = %v, want [install]", got)
+	}
+
+	// workflow เส้นตรงเดิม: เริ่มได้ทีละ step ตามลำดับ
+	linear := []models.TaskWorkflowStep{
+		{StepID: "1", StepName: "a", Order: 1, Status: "done"},
+		{StepID: "2", StepName: "b", Order: 2, Status: "todo"},
+		{StepID: "3", StepName: "c", Order: 3, Status: "todo"},
+	}
+	if got := ReadyStepNames(linear); strings.Join(got, ",") != "b" {
+		t.Fatalf("ready = %v, want [b]", got)
+	}
+	if len(PendingPrerequisites(linear, "3")) != 0 {
+		t.Fatal("linear workflow must not block steps")
+	}
+}
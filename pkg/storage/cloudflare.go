@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
@@ -84,6 +85,25 @@ func (c *CloudflareStorage) UploadFile(filePath, key string) error {
 	return nil
 }
 
+// UploadBytes uploads in-memory content (e.g. a generated thumbnail) to the bucket under the given key.
+func (c *CloudflareStorage) UploadBytes(data []byte, key, contentType string) error {
+	if contentType == "" {
+		contentType = determineContentType(key)
+	}
+	_, err := c.Client.PutObject(&s3.PutObjectInput{
+		Bucket:        aws.String(c.Bucket),
+		Key:           aws.String(key),
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+		ContentType:   aws.String(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	return nil
+}
+
 // ListFileMetas lists metadata of files under the given prefix.
 func (c *CloudflareStorage) ListFileMetas(prefix string) ([]dto.FileMeta, error) {
 	input := &s3.ListObjectsV2Input{
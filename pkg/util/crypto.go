@@ -70,3 +70,12 @@ func DecryptGCM(ciphertextB64 string, base64Key string) (string, error) {
 	}
 	return string(ptBytes), nil
 }
+
+// GenerateToken สุ่ม token แบบ URL-safe ขนาด n ไบต์ (ใช้เป็นรหัสลับของลิงก์)
+func GenerateToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,116 @@
+package util
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ICalEvent เหตุการณ์หนึ่งรายการในไฟล์ iCalendar (RFC 5545)
+type ICalEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Categories  []string
+	Start       time.Time
+	End         time.Time // all-day: วันสุดท้าย (รวม) จะแปลงเป็น DTEND แบบไม่รวมให้เอง
+	AllDay      bool
+	Cancelled   bool
+	LastUpdate  time.Time
+}
+
+const icalDateTime = "20060102T150405Z"
+
+// BuildICalendar สร้างเนื้อหา text/calendar (VCALENDAR + VEVENT) ตาม RFC 5545
+// บรรทัดจบด้วย CRLF และพับบรรทัดที่ยาวเกิน 75 octets โดยไม่ตัดกลางตัวอักษร UTF-8
+func BuildICalendar(name string, events []ICalEvent, now time.Time) string {
+	var b strings.Builder
+	write := func(line string) {
+		b.WriteString(foldICalLine(line))
+		b.WriteString("\r\n")
+	}
+
+	write("BEGIN:VCALENDAR")
+	write("VERSION:2.0")
+	write("PRODID:-//erp-demo//Task Calendar//TH")
+	write("CALSCALE:GREGORIAN")
+	write("METHOD:PUBLISH")
+	write("X-WR-CALNAME:" + EscapeICalText(name))
+	write("X-WR-TIMEZONE:Asia/Bangkok")
+
+	stamp := now.UTC().Format(icalDateTime)
+	for _, e := range events {
+		write("BEGIN:VEVENT")
+		write("UID:" + e.UID)
+		write("DTSTAMP:" + stamp)
+		if e.AllDay {
+			end := e.End
+			if end.Before(e.Start) {
+				end = e.Start
+			}
+			write("DTSTART;VALUE=DATE:" + e.Start.Format("20060102"))
+			write("DTEND;VALUE=DATE:" + end.AddDate(0, 0, 1).Format("20060102"))
+		} else {
+			write("DTSTART:" + e.Start.UTC().Format(icalDateTime))
+			write("DTEND:" + e.End.UTC().Format(icalDateTime))
+		}
+		write("SUMMARY:" + EscapeICalText(e.Summary))
+		if e.Description != "" {
+			write("DESCRIPTION:" + EscapeICalText(e.Description))
+		}
+		if e.Location != "" {
+			write("LOCATION:" + EscapeICalText(e.Location))
+		}
+		if len(e.Categories) > 0 {
+			cats := make([]string, 0, len(e.Categories))
+			for _, c := range e.Categories {
+				cats = append(cats, EscapeICalText(c))
+			}
+			write("CATEGORIES:" + strings.Join(cats, ","))
+		}
+		if !e.LastUpdate.IsZero() {
+			write("LAST-MODIFIED:" + e.LastUpdate.UTC().Format(icalDateTime))
+		}
+		if e.Cancelled {
+			write("STATUS:CANCELLED")
+		} else {
+			write("STATUS:CONFIRMED")
+		}
+		write("TRANSP:TRANSPARENT")
+		write("END:VEVENT")
+	}
+
+	write("END:VCALENDAR")
+	return b.String()
+}
+
+// EscapeICalText escape ค่า TEXT ตาม RFC 5545 (\\ ; , และขึ้นบรรทัดใหม่)
+func EscapeICalText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", `\n`)
+	return replacer.Replace(s)
+}
+
+// foldICalLine พับบรรทัดที่ยาวเกิน 75 octets (บรรทัดต่อขึ้นต้นด้วยช่องว่าง)
+func foldICalLine(line string) string {
+	const limit = 75
+	if len(line) <= limit {
+		return line
+	}
+	var b strings.Builder
+	size := 0
+	width := limit
+	for len(line) > 0 {
+		_, n := utf8.DecodeRuneInString(line)
+		if size+n > width {
+			b.WriteString("\r\n ")
+			size = 0
+			width = limit - 1 // ช่องว่างนำหน้านับเป็น 1 octet
+		}
+		b.WriteString(line[:n])
+		size += n
+		line = line[n:]
+	}
+	return b.String()
+}
@@ -0,0 +1,57 @@
+package util
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEscapeICalText(t *testing.T) {
+	got := EscapeICalText("a,b;c\\d\r\ne")
+	want := `a\,b\;c\\d\ne`
+	if got != want {
+		t.Fatalf("EscapeICalText = %q, want %q", got, want)
+	}
+}
+
+func TestFoldICalLine(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("งาน", 40)
+	folded := foldICalLine(line)
+	for i, part := range strings.Split(folded, "\r\n") {
+		if len(part) > 75 {
+			t.Fatalf("line %d has %d octets", i, len(part))
+		}
+		if i > 0 && !strings.HasPrefix(part, " ") {
+			t.Fatalf("continuation line %d must start with a space", i)
+		}
+	}
+	if strings.ReplaceAll(folded, "\r\n ", "") != line {
+		t.Fatalf("unfolded line does not match the original")
+	}
+}
+
+func TestBuildICalendar(t *testing.T) {
+	now := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	out := BuildICalendar("งานของฉัน", []ICalEvent{
+		{UID: "task-1@erp", Summary: "ติดตั้งป้าย", Start: day, End: day.AddDate(0, 0, 2), AllDay: true},
+		{UID: "step-1@erp", Summary: "ออกแบบ", Start: now, End: now.Add(time.Hour)},
+	}, now)
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"DTSTART;VALUE=DATE:20250310\r\n",
+		"DTEND;VALUE=DATE:20250313\r\n",
+		"DTSTART:20250301T080000Z\r\n",
+		"DTEND:20250301T090000Z\r\n",
+		"DTSTAMP:20250301T080000Z\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("calendar missing %q", want)
+		}
+	}
+	if strings.Count(out, "BEGIN:VEVENT") != 2 {
+		t.Errorf("expected 2 events")
+	}
+}
@@ -0,0 +1,41 @@
+package util
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPDFDocumentBytes(t *testing.T) {
+	doc := NewPDFDocument()
+	doc.Text(40, 40, 12, true, "Payslip (2025-01)")
+	doc.Text(40, 60, 10, false, "ชื่อ Somchai")
+	doc.Line(40, 70, 550, 70)
+	out := doc.Bytes()
+
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatalf("missing PDF header or trailer")
+	}
+	if !bytes.Contains(out, []byte(`(Payslip \(2025-01\))`)) {
+		t.Fatalf("parentheses must be escaped")
+	}
+	if !bytes.Contains(out, []byte("(???? Somchai)")) {
+		t.Fatalf("non-latin text must be replaced")
+	}
+
+	// startxref ต้องชี้ไปที่ตาราง xref และ offset ของแต่ละ object ต้องตรง
+	s := string(out)
+	idx := strings.LastIndex(s, "startxref\n")
+	xref, err := strconv.Atoi(strings.Fields(s[idx+len("startxref\n"):])[0])
+	if err != nil || !strings.HasPrefix(s[xref:], "xref") {
+		t.Fatalf("startxref does not point to xref table")
+	}
+	lines := strings.Split(s[xref:], "\n")
+	for i := 1; i <= 6; i++ {
+		off, _ := strconv.Atoi(lines[2+i][:10])
+		if !strings.HasPrefix(s[off:], strconv.Itoa(i)+" 0 obj") {
+			t.Fatalf("xref offset of object %d is wrong", i)
+		}
+	}
+}
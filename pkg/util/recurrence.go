@@ -0,0 +1,359 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Recurrence ตารางเวลาที่เกิดซ้ำ ใช้ได้ทั้ง cron (5 ช่อง) และ RRULE (RFC 5545 บางส่วน)
+type Recurrence interface {
+	// Next เวลาครั้งถัดไปหลัง after (zero time = ไม่มีครั้งถัดไปแล้ว)
+	Next(after time.Time) time.Time
+}
+
+// ParseRecurrence แปลง expression เป็นตารางเวลา
+// ขึ้นต้นด้วย "RRULE:" หรือมี "FREQ=" จะถือเป็น RRULE นอกนั้นเป็น cron มาตรฐาน เช่น "0 8 * * 1"
+// start คือวัน/เวลาเริ่ม (DTSTART) ใช้ timezone ของ start ในการคำนวณ
+func ParseRecurrence(expr string, start time.Time) (Recurrence, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("schedule is required")
+	}
+	upper := strings.ToUpper(expr)
+	if strings.HasPrefix(upper, "RRULE:") || strings.Contains(upper, "FREQ=") {
+		return ParseRRule(expr, start)
+	}
+
+	sched, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression: %w", err)
+	}
+	return &cronRecurrence{sched: sched, start: start}, nil
+}
+
+type cronRecurrence struct {
+	sched cron.Schedule
+	start time.Time
+}
+
+func (c *cronRecurrence) Next(after time.Time) time.Time {
+	if after.Before(c.start) {
+		after = c.start.Add(-time.Second)
+	}
+	return c.sched.Next(after.In(c.start.Location()))
+}
+
+// RRuleDay วันในสัปดาห์ของ BYDAY (N = ลำดับในเดือน เช่น 1MO, -1FR; 0 = ทุกสัปดาห์)
+type RRuleDay struct {
+	N       int
+	Weekday time.Weekday
+}
+
+// RRule กฎการเกิดซ้ำตาม RFC 5545 รองรับ FREQ=DAILY|WEEKLY|MONTHLY|YEARLY,
+// INTERVAL, BYDAY, BYMONTHDAY, BYHOUR, BYMINUTE, COUNT และ UNTIL
+// COUNT ไม่ได้ตรวจใน Next ผู้เรียกต้องนับจำนวนครั้งที่เกิดขึ้นเอง
+type RRule struct {
+	Freq       string
+	Interval   int
+	ByDay      []RRuleDay
+	ByMonthDay []int
+	ByHour     []int
+	ByMinute   []int
+	Count      int
+	Until      time.Time
+
+	start time.Time
+}
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// rruleSearchDays ระยะค้นหาครั้งถัดไปสูงสุด (กันวนไม่จบกับกฎที่ไม่มีวันตรง เช่น BYMONTHDAY=31;FREQ=YEARLY)
+const rruleSearchDays = 366 * 8
+
+func ParseRRule(expr string, start time.Time) (*RRule, error) {
+	expr = strings.TrimSpace(expr)
+	if len(expr) >= 6 && strings.EqualFold(expr[:6], "RRULE:") {
+		expr = expr[6:]
+	}
+
+	r := &RRule{Interval: 1, start: start}
+	for _, part := range strings.Split(expr, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid RRULE part: %s", part)
+		}
+		key, val := strings.ToUpper(strings.TrimSpace(kv[0])), strings.ToUpper(strings.TrimSpace(kv[1]))
+		switch key {
+		case "FREQ":
+			switch val {
+			case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+				r.Freq = val
+			default:
+				return nil, fmt.Errorf("unsupported FREQ: %s (allow: DAILY|WEEKLY|MONTHLY|YEARLY)", val)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid INTERVAL: %s", val)
+			}
+			r.Interval = n
+		case "BYDAY":
+			for _, d := range strings.Split(val, ",") {
+				day, err := parseRRuleDay(d)
+				if err != nil {
+					return nil, err
+				}
+				r.ByDay = append(r.ByDay, day)
+			}
+		case "BYMONTHDAY":
+			nums, err := parseRRuleInts(val, -31, 31)
+			if err != nil {
+				return nil, fmt.Errorf("invalid BYMONTHDAY: %w", err)
+			}
+			for _, n := range nums {
+				if n == 0 {
+					return nil, fmt.Errorf("invalid BYMONTHDAY: 0")
+				}
+			}
+			r.ByMonthDay = nums
+		case "BYHOUR":
+			nums, err := parseRRuleInts(val, 0, 23)
+			if err != nil {
+				return nil, fmt.Errorf("invalid BYHOUR: %w", err)
+			}
+			r.ByHour = nums
+		case "BYMINUTE":
+			nums, err := parseRRuleInts(val, 0, 59)
+			if err != nil {
+				return nil, fmt.Errorf("invalid BYMINUTE: %w", err)
+			}
+			r.ByMinute = nums
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid COUNT: %s", val)
+			}
+			r.Count = n
+		case "UNTIL":
+			until, err := parseRRuleUntil(val, start.Location())
+			if err != nil {
+				return nil, err
+			}
+			r.Until = until
+		case "WKST":
+			// สัปดาห์เริ่มวันจันทร์เสมอ
+		default:
+			return nil, fmt.Errorf("unsupported RRULE part: %s", key)
+		}
+	}
+	if r.Freq == "" {
+		return nil, fmt.Errorf("FREQ is required")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return nil, fmt.Errorf("COUNT and UNTIL cannot be used together")
+	}
+	return r, nil
+}
+
+func (r *RRule) Next(after time.Time) time.Time {
+	loc := r.start.Location()
+	t := after.In(loc)
+	if t.Before(r.start) {
+		t = r.start.Add(-time.Nanosecond)
+	}
+
+	hours := r.ByHour
+	if len(hours) == 0 {
+		hours = []int{r.start.Hour()}
+	}
+	minutes := r.ByMinute
+	if len(minutes) == 0 {
+		minutes = []int{r.start.Minute()}
+	}
+	times := make([]int, 0, len(hours)*len(minutes))
+	for _, h := range hours {
+		for _, m := range minutes {
+			times = append(times, h*60+m)
+		}
+	}
+	sort.Ints(times)
+
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	for i := 0; i < rruleSearchDays; i++ {
+		d := day.AddDate(0, 0, i)
+		if !r.Until.IsZero() && d.After(r.Until) {
+			return time.Time{}
+		}
+		if !r.matchDay(d) {
+			continue
+		}
+		for _, hm := range times {
+			cand := time.Date(d.Year(), d.Month(), d.Day(), hm/60, hm%60, 0, 0, loc)
+			if !cand.After(t) {
+				continue
+			}
+			if !r.Until.IsZero() && cand.After(r.Until) {
+				return time.Time{}
+			}
+			return cand
+		}
+	}
+	return time.Time{}
+}
+
+func (r *RRule) matchDay(d time.Time) bool {
+	start := civilDate(r.start)
+	cur := civilDate(d)
+	switch r.Freq {
+	case "DAILY":
+		days := int(cur.Sub(start).Hours() / 24)
+		if days%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByDay) > 0 && !r.matchWeekday(d) {
+			return false
+		}
+		if len(r.ByMonthDay) > 0 && !r.matchMonthDay(d) {
+			return false
+		}
+		return true
+	case "WEEKLY":
+		weeks := int(mondayOf(cur).Sub(mondayOf(start)).Hours() / (24 * 7))
+		if weeks%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByDay) == 0 {
+			return d.Weekday() == r.start.Weekday()
+		}
+		return r.matchWeekday(d)
+	case "MONTHLY":
+		months := (d.Year()-r.start.Year())*12 + int(d.Month()) - int(r.start.Month())
+		if months%r.Interval != 0 {
+			return false
+		}
+		switch {
+		case len(r.ByMonthDay) > 0:
+			if !r.matchMonthDay(d) {
+				return false
+			}
+			return len(r.ByDay) == 0 || r.matchWeekday(d)
+		case len(r.ByDay) > 0:
+			return r.matchWeekday(d)
+		default:
+			return d.Day() == r.start.Day()
+		}
+	case "YEARLY":
+		years := d.Year() - r.start.Year()
+		if years%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByMonthDay) > 0 {
+			return d.Month() == r.start.Month() && r.matchMonthDay(d)
+		}
+		return d.Month() == r.start.Month() && d.Day() == r.start.Day()
+	}
+	return false
+}
+
+func (r *RRule) matchWeekday(d time.Time) bool {
+	last := daysIn(d)
+	for _, bd := range r.ByDay {
+		if bd.Weekday != d.Weekday() {
+			continue
+		}
+		switch {
+		case bd.N == 0:
+			return true
+		case bd.N > 0 && (d.Day()-1)/7+1 == bd.N:
+			return true
+		case bd.N < 0 && (last-d.Day())/7+1 == -bd.N:
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RRule) matchMonthDay(d time.Time) bool {
+	last := daysIn(d)
+	for _, md := range r.ByMonthDay {
+		if md > 0 && d.Day() == md {
+			return true
+		}
+		if md < 0 && d.Day() == last+md+1 {
+			return true
+		}
+	}
+	return false
+}
+
+func parseRRuleDay(s string) (RRuleDay, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 {
+		return RRuleDay{}, fmt.Errorf("invalid BYDAY: %s", s)
+	}
+	wd, ok := rruleWeekdays[s[len(s)-2:]]
+	if !ok {
+		return RRuleDay{}, fmt.Errorf("invalid BYDAY: %s", s)
+	}
+	day := RRuleDay{Weekday: wd}
+	if prefix := s[:len(s)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return RRuleDay{}, fmt.Errorf("invalid BYDAY: %s", s)
+		}
+		day.N = n
+	}
+	return day, nil
+}
+
+func parseRRuleInts(s string, lo, hi int) ([]int, error) {
+	parts := strings.Split(s, ",")
+	out := make([]int, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || n < lo || n > hi {
+			return nil, fmt.Errorf("%s out of range %d..%d", p, lo, hi)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func parseRRuleUntil(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102T150405", s, loc); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102", s, loc); err == nil {
+		// ทั้งวันสุดท้ายยังนับอยู่
+		return t.AddDate(0, 0, 1).Add(-time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL: %s", s)
+}
+
+func civilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func mondayOf(d time.Time) time.Time {
+	offset := (int(d.Weekday()) + 6) % 7
+	return d.AddDate(0, 0, -offset)
+}
+
+func daysIn(d time.Time) int {
+	return time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
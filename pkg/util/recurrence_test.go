@@ -0,0 +1,85 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+var testLoc = time.FixedZone("Asia/Bangkok", 7*60*60)
+
+func nextN(t *testing.T, r Recurrence, after time.Time, n int) []string {
+	t.Helper()
+	out := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		after = r.Next(after)
+		if after.IsZero() {
+			break
+		}
+		out = append(out, after.Format("2006-01-02 15:04 Mon"))
+	}
+	return out
+}
+
+func assertRuns(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("run %d = %s, want %s (all: %v)", i, got[i], want[i], got)
+		}
+	}
+}
+
+func TestRRuleWeeklyInterval(t *testing.T) {
+	start := time.Date(2025, 1, 6, 8, 0, 0, 0, testLoc) // จันทร์
+	r, err := ParseRecurrence("RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertRuns(t, nextN(t, r, start.Add(-time.Minute), 4),
+		"2025-01-06 08:00 Mon", "2025-01-09 08:00 Thu", "2025-01-20 08:00 Mon", "2025-01-23 08:00 Thu")
+}
+
+func TestRRuleMonthlyLastAndOrdinal(t *testing.T) {
+	start := time.Date(2025, 1, 1, 9, 30, 0, 0, testLoc)
+	r, err := ParseRecurrence("FREQ=MONTHLY;BYMONTHDAY=-1", start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertRuns(t, nextN(t, r, start, 3), "2025-01-31 09:30 Fri", "2025-02-28 09:30 Fri", "2025-03-31 09:30 Mon")
+
+	r, err = ParseRecurrence("FREQ=MONTHLY;BYDAY=1MO;BYHOUR=7;BYMINUTE=0", start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertRuns(t, nextN(t, r, start, 2), "2025-01-06 07:00 Mon", "2025-02-03 07:00 Mon")
+}
+
+func TestRRuleDailyUntil(t *testing.T) {
+	start := time.Date(2025, 3, 1, 8, 0, 0, 0, testLoc)
+	r, err := ParseRecurrence("FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;UNTIL=20250305", start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertRuns(t, nextN(t, r, start, 10), "2025-03-03 08:00 Mon", "2025-03-04 08:00 Tue", "2025-03-05 08:00 Wed")
+}
+
+func TestCronRecurrence(t *testing.T) {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, testLoc)
+	r, err := ParseRecurrence("30 8 * * 1", start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertRuns(t, nextN(t, r, start.AddDate(0, 0, -30), 2), "2025-03-03 08:30 Mon", "2025-03-10 08:30 Mon")
+}
+
+func TestParseRecurrenceErrors(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, testLoc)
+	for _, expr := range []string{"", "FREQ=HOURLY", "FREQ=DAILY;BYDAY=XX", "FREQ=DAILY;COUNT=2;UNTIL=20250101", "61 * * * *"} {
+		if _, err := ParseRecurrence(expr, start); err == nil {
+			t.Errorf("expected error for %q", expr)
+		}
+	}
+}
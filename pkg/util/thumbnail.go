@@ -0,0 +1,97 @@
+package util
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	_ "image/gif" // ลงทะเบียน decoder สำหรับ gif
+	"image/jpeg"
+	_ "image/png" // ลงทะเบียน decoder สำหรับ png
+)
+
+// MaxThumbnailPixels จำนวนพิกเซลสูงสุดของรูปต้นฉบับที่ยอมถอดรหัส (40 ล้านพิกเซล)
+// กันไฟล์ขนาดเล็กที่ประกาศขนาดภาพใหญ่มาก (decompression bomb) จองหน่วยความจำหลาย GB
+const MaxThumbnailPixels = 40_000_000
+
+// MakeThumbnail ย่อรูปให้ด้านที่ยาวที่สุดไม่เกิน maxSide พิกเซล แล้วเข้ารหัสเป็น JPEG
+// คืนค่า ok=false ถ้าข้อมูลไม่ใช่รูปภาพที่รองรับ (jpeg/png/gif) หรือรูปใหญ่เกิน MaxThumbnailPixels
+func MakeThumbnail(data []byte, maxSide int) (thumb []byte, ok bool, err error) {
+	// อ่านเฉพาะ header ก่อน เพื่อตรวจขนาดภาพโดยไม่ต้องถอดรหัสทั้งไฟล์
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, false, nil
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > MaxThumbnailPixels {
+		return nil, false, nil
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, false, nil
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return nil, false, nil
+	}
+
+	dw, dh := w, h
+	if w > maxSide || h > maxSide {
+		if w >= h {
+			dw = maxSide
+			dh = h * maxSide / w
+		} else {
+			dh = maxSide
+			dw = w * maxSide / h
+		}
+	}
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+
+	// ย่อแบบเฉลี่ยพิกเซลในกรอบ (box filter) เพื่อลดรอยหยักเมื่อย่อมาก
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		sy0 := b.Min.Y + y*h/dh
+		sy1 := b.Min.Y + (y+1)*h/dh
+		if sy1 <= sy0 {
+			sy1 = sy0 + 1
+		}
+		for x := 0; x < dw; x++ {
+			sx0 := b.Min.X + x*w/dw
+			sx1 := b.Min.X + (x+1)*w/dw
+			if sx1 <= sx0 {
+				sx1 = sx0 + 1
+			}
+			var r, g, bl, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					bl += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+			// พื้นหลังโปร่งใสให้เป็นสีขาว (JPEG ไม่มี alpha)
+			white := n*0xffff - a
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(((r + white) / n) >> 8),
+				G: uint8(((g + white) / n) >> 8),
+				B: uint8(((bl + white) / n) >> 8),
+				A: 0xff,
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, false, err
+	}
+	return buf.Bytes(), true, nil
+}
@@ -0,0 +1,68 @@
+package util
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func TestMakeThumbnail(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 800, 400))
+	for y := 0; y < 400; y++ {
+		for x := 0; x < 800; x++ {
+			src.Set(x, y, color.RGBA{R: 200, G: 10, B: 10, A: 0xff})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+
+	thumb, ok, err := MakeThumbnail(buf.Bytes(), 200)
+	if err != nil || !ok {
+		t.Fatalf("MakeThumbnail() ok=%v err=%v", ok, err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(thumb))
+	if err != nil {
+		t.Fatalf("thumbnail is not a valid jpeg: %v", err)
+	}
+	if got := img.Bounds(); got.Dx() != 200 || got.Dy() != 100 {
+		t.Errorf("thumbnail size = %dx%d, want 200x100", got.Dx(), got.Dy())
+	}
+	r, _, _, _ := img.At(100, 50).RGBA()
+	if r>>8 < 180 {
+		t.Errorf("thumbnail colour not preserved, red = %d", r>>8)
+	}
+}
+
+func TestMakeThumbnailNotImage(t *testing.T) {
+	thumb, ok, err := MakeThumbnail([]byte("%PDF-1.4 not an image"), 200)
+	if err != nil || ok || thumb != nil {
+		t.Errorf("MakeThumbnail(pdf) = (%v, %v, %v), want (nil, false, nil)", thumb, ok, err)
+	}
+}
+
+func TestMakeThumbnailRejectsHugeCanvas(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	// แก้ IHDR ให้ประกาศขนาด 100000x100000 (ไฟล์ยังเล็กอยู่) แล้วคำนวณ CRC ใหม่
+	data := buf.Bytes()
+	binary.BigEndian.PutUint32(data[16:20], 100000)
+	binary.BigEndian.PutUint32(data[20:24], 100000)
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(data)); err != nil || cfg.Width != 100000 {
+		t.Fatalf("crafted header not readable: cfg=%+v err=%v", cfg, err)
+	}
+
+	thumb, ok, err := MakeThumbnail(data, 200)
+	if err != nil || ok || thumb != nil {
+		t.Errorf("MakeThumbnail(huge canvas) = (%v, %v, %v), want (nil, false, nil)", thumb, ok, err)
+	}
+}
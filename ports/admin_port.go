@@ -8,7 +8,7 @@ import (
 
 type AdminService interface {
 	UpdateUserStatus(ctx context.Context, req dto.RequestUpdateUserStatus) error
-	UpdateUserPosition(ctx context.Context, req dto.RequestUpdateUserPosition) error
+	UpdateUserPosition(ctx context.Context, req dto.RequestUpdateUserPosition, claims *dto.JWTClaims) error
 }
 
 type AdminRepository interface {
@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+)
+
+// ErrAttachmentForbidden ผู้ใช้ไม่มีสิทธิ์เข้าถึงเอกสารที่แนบไฟล์
+var ErrAttachmentForbidden = errors.New("no permission on the parent entity")
+
+type AttachmentService interface {
+	UploadAttachment(ctx context.Context, req dto.CreateAttachmentDTO, file dto.AttachmentFile, claims *dto.JWTClaims) (*dto.AttachmentDTO, error)
+	ListAttachments(ctx context.Context, req dto.RequestListAttachments, claims *dto.JWTClaims) ([]dto.AttachmentDTO, error)
+	DeleteAttachment(ctx context.Context, attachmentID string, claims *dto.JWTClaims) error
+}
+
+type AttachmentRepository interface {
+	CreateAttachment(ctx context.Context, attachment models.Attachment) error
+	SoftDeleteAttachmentByID(ctx context.Context, attachmentID, deletedBy string) error
+	GetAllAttachmentsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.Attachment, error)
+	GetOneAttachmentByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.Attachment, error)
+}
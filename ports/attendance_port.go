@@ -0,0 +1,67 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ErrAttendanceForbidden ไม่มีสิทธิ์ดู/แก้ไขการลงเวลารายการนี้
+var ErrAttendanceForbidden = errors.New("no permission to access this attendance record")
+
+// ErrAttendanceConflict ลงเวลาซ้ำ หรือรายการถูกแก้ไขไปก่อนแล้ว
+var ErrAttendanceConflict = errors.New("attendance conflict")
+
+// ErrAttendanceOutsideSite พิกัดไม่อยู่ในรัศมีของสถานที่ลงเวลาใดเลย
+var ErrAttendanceOutsideSite = errors.New("location is outside every allowed attendance site")
+
+type AttendanceService interface {
+	ListAttendanceSites(ctx context.Context, claims *dto.JWTClaims) ([]dto.AttendanceSiteDTO, error)
+	UpsertAttendanceSite(ctx context.Context, req dto.UpsertAttendanceSiteDTO, claims *dto.JWTClaims) (*dto.AttendanceSiteDTO, error)
+	DeleteAttendanceSite(ctx context.Context, siteID string, claims *dto.JWTClaims) error
+
+	ListWorkShifts(ctx context.Context, claims *dto.JWTClaims) ([]dto.WorkShiftDTO, error)
+	UpsertWorkShift(ctx context.Context, req dto.UpsertWorkShiftDTO, claims *dto.JWTClaims) (*dto.WorkShiftDTO, error)
+	DeleteWorkShift(ctx context.Context, shiftID string, claims *dto.JWTClaims) error
+
+	CheckIn(ctx context.Context, req dto.AttendancePunchDTO, claims *dto.JWTClaims) (*dto.AttendanceRecordDTO, error)
+	CheckOut(ctx context.Context, req dto.AttendancePunchDTO, claims *dto.JWTClaims) (*dto.AttendanceRecordDTO, error)
+	GetMyAttendanceToday(ctx context.Context, claims *dto.JWTClaims) (*dto.AttendanceTodayDTO, error)
+	ListAttendance(ctx context.Context, req dto.RequestListAttendance, claims *dto.JWTClaims) (dto.Pagination, error)
+	// DecideOvertime ผู้จัดการแผนก/admin อนุมัติหรือไม่อนุมัติ OT ของรายการ
+	DecideOvertime(ctx context.Context, recordID string, req dto.AttendanceOvertimeDecisionDTO, claims *dto.JWTClaims) (*dto.AttendanceRecordDTO, error)
+	// CorrectAttendance ผู้จัดการแผนก/admin แก้ไขหรือบันทึกเวลาแทน พร้อมเหตุผล
+	CorrectAttendance(ctx context.Context, req dto.AttendanceCorrectionDTO, claims *dto.JWTClaims) (*dto.AttendanceRecordDTO, error)
+	// GetAttendanceSummary สรุปรายเดือนต่อพนักงานตามสิทธิ์ผู้เรียก
+	GetAttendanceSummary(ctx context.Context, req dto.RequestAttendanceSummary, claims *dto.JWTClaims) ([]dto.AttendanceMonthSummaryDTO, error)
+	// SummarizeAttendanceMonth สรุปรายเดือนโดยไม่ตรวจสิทธิ์ สำหรับระบบอื่น (เงินเดือน, KPI)
+	SummarizeAttendanceMonth(ctx context.Context, month time.Time, users []*models.User) ([]dto.AttendanceMonthSummaryDTO, error)
+}
+
+type AttendanceRepository interface {
+	CreateAttendanceSite(ctx context.Context, site models.AttendanceSite) error
+	UpdateAttendanceSiteByID(ctx context.Context, siteID string, update models.AttendanceSite) (*models.AttendanceSite, error)
+	SoftDeleteAttendanceSiteByID(ctx context.Context, siteID string) error
+	GetAllAttendanceSitesByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.AttendanceSite, error)
+	GetOneAttendanceSiteByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.AttendanceSite, error)
+
+	CreateWorkShift(ctx context.Context, shift models.WorkShift) error
+	UpdateWorkShiftByID(ctx context.Context, shiftID string, update models.WorkShift) (*models.WorkShift, error)
+	SoftDeleteWorkShiftByID(ctx context.Context, shiftID string) error
+	// UnsetDefaultWorkShifts ยกเลิกกะเริ่มต้นเดิมทั้งหมด ยกเว้น shiftID
+	UnsetDefaultWorkShifts(ctx context.Context, exceptShiftID string) error
+	GetAllWorkShiftsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.WorkShift, error)
+	GetOneWorkShiftByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.WorkShift, error)
+
+	// InsertAttendanceRecordIfAbsent สร้างรายการของวันถ้ายังไม่มี (user_id + work_date) คืน inserted=false ถ้ามีอยู่แล้ว
+	InsertAttendanceRecordIfAbsent(ctx context.Context, record models.AttendanceRecord) (bool, error)
+	// UpdateAttendanceRecord อัปเดตแบบมีเงื่อนไข คืน nil ถ้าเงื่อนไขไม่ตรง (มีคนแก้ไปก่อน)
+	UpdateAttendanceRecord(ctx context.Context, filter bson.M, update bson.M) (*models.AttendanceRecord, error)
+	GetAllAttendanceRecordsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.AttendanceRecord, error)
+	GetOneAttendanceRecordByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.AttendanceRecord, error)
+	GetListAttendanceRecordsByFilter(ctx context.Context, filter interface{}, projection interface{}, sort bson.D, skip, limit int64) ([]models.AttendanceRecord, int64, error)
+}
@@ -0,0 +1,31 @@
+package ports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+)
+
+// ErrBoardForbidden ไม่มีสิทธิ์ตั้งค่าบอร์ด
+var ErrBoardForbidden = errors.New("no permission to configure this board")
+
+// ErrBoardWIPLimit คอลัมน์ปลายทางเต็มตาม WIP limit
+var ErrBoardWIPLimit = errors.New("target column has reached its WIP limit")
+
+type BoardService interface {
+	GetBoard(ctx context.Context, req dto.RequestTaskBoard, claims *dto.JWTClaims) (*dto.TaskBoardDTO, error)
+	MoveCard(ctx context.Context, req dto.BoardMoveDTO, claims *dto.JWTClaims) (*dto.TaskCardDTO, error)
+	UpsertWIPLimit(ctx context.Context, req dto.UpsertBoardWIPLimitDTO, claims *dto.JWTClaims) error
+	ListWIPLimits(ctx context.Context, req dto.RequestListBoardWIPLimits, claims *dto.JWTClaims) ([]dto.BoardWIPLimitDTO, error)
+	GetCalendar(ctx context.Context, req dto.RequestTaskCalendar, claims *dto.JWTClaims) (*dto.TaskCalendarDTO, error)
+}
+
+type BoardRepository interface {
+	CreateWIPLimit(ctx context.Context, limit models.BoardWIPLimit) error
+	UpdateWIPLimitByID(ctx context.Context, limitID string, update models.BoardWIPLimit) (*models.BoardWIPLimit, error)
+	SoftDeleteWIPLimitByID(ctx context.Context, limitID string) error
+	GetAllWIPLimitsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.BoardWIPLimit, error)
+	GetOneWIPLimitByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.BoardWIPLimit, error)
+}
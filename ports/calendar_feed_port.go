@@ -0,0 +1,28 @@
+package ports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+)
+
+// ErrCalendarFeedForbidden ไม่มีสิทธิ์ในลิงก์ปฏิทินหรือแผนกที่ระบุ
+var ErrCalendarFeedForbidden = errors.New("no permission on this calendar feed")
+
+type CalendarFeedService interface {
+	CreateFeed(ctx context.Context, req dto.CreateCalendarFeedDTO, claims *dto.JWTClaims) (*dto.CalendarFeedTokenDTO, error)
+	ListFeeds(ctx context.Context, claims *dto.JWTClaims) ([]dto.CalendarFeedDTO, error)
+	RotateFeed(ctx context.Context, feedID string, claims *dto.JWTClaims) (*dto.CalendarFeedTokenDTO, error)
+	RevokeFeed(ctx context.Context, feedID string, claims *dto.JWTClaims) error
+	// RenderFeed สร้างเนื้อหา iCal จาก token (ไม่ต้อง login) คืน mongo.ErrNoDocuments ถ้า token ไม่ถูกต้อง/ถูกยกเลิก
+	RenderFeed(ctx context.Context, token string) (string, error)
+}
+
+type CalendarFeedRepository interface {
+	CreateCalendarFeed(ctx context.Context, feed models.CalendarFeed) error
+	UpdateCalendarFeedByID(ctx context.Context, feedID string, update models.CalendarFeed) (*models.CalendarFeed, error)
+	GetAllCalendarFeedsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.CalendarFeed, error)
+	GetOneCalendarFeedByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.CalendarFeed, error)
+}
@@ -0,0 +1,29 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+)
+
+type CapacityService interface {
+	UpsertDepartmentCapacity(ctx context.Context, req dto.UpsertDepartmentCapacityDTO, claims *dto.JWTClaims) error
+	ListDepartmentCapacities(ctx context.Context, claims *dto.JWTClaims) ([]dto.DepartmentCapacityDTO, error)
+	CreateHoliday(ctx context.Context, req dto.CreateHolidayDTO, claims *dto.JWTClaims) error
+	ListHolidays(ctx context.Context, req dto.RequestListHolidays, claims *dto.JWTClaims) ([]dto.HolidayDTO, error)
+	DeleteHoliday(ctx context.Context, holidayID string, claims *dto.JWTClaims) error
+	EarliestCompletion(ctx context.Context, req dto.RequestEarliestCompletion, claims *dto.JWTClaims) (*dto.EarliestCompletionDTO, error)
+	DepartmentLoad(ctx context.Context, req dto.RequestCapacityLoad, claims *dto.JWTClaims) ([]dto.CapacityLoadDTO, error)
+}
+
+type CapacityRepository interface {
+	CreateDepartmentCapacity(ctx context.Context, capacity models.DepartmentCapacity) error
+	UpdateDepartmentCapacityByID(ctx context.Context, capacityID string, update models.DepartmentCapacity) (*models.DepartmentCapacity, error)
+	GetAllDepartmentCapacitiesByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.DepartmentCapacity, error)
+	GetOneDepartmentCapacityByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.DepartmentCapacity, error)
+	CreateHoliday(ctx context.Context, holiday models.Holiday) error
+	SoftDeleteHolidayByID(ctx context.Context, holidayID string) error
+	GetAllHolidaysByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.Holiday, error)
+	GetOneHolidayByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.Holiday, error)
+}
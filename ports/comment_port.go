@@ -0,0 +1,32 @@
+package ports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+)
+
+// ErrCommentForbidden ไม่มีสิทธิ์ในงาน/ความคิดเห็นนั้น
+var ErrCommentForbidden = errors.New("no permission on this comment or its entity")
+
+type CommentService interface {
+	CreateComment(ctx context.Context, req dto.CreateCommentDTO, claims *dto.JWTClaims) (*dto.CommentDTO, error)
+	UpdateComment(ctx context.Context, commentID string, req dto.UpdateCommentDTO, claims *dto.JWTClaims) (*dto.CommentDTO, error)
+	DeleteComment(ctx context.Context, commentID string, claims *dto.JWTClaims) error
+	ListComments(ctx context.Context, req dto.RequestListComments, claims *dto.JWTClaims) ([]dto.CommentDTO, error)
+	ActivityFeed(ctx context.Context, req dto.RequestActivityFeed, claims *dto.JWTClaims) ([]dto.ActivityFeedItemDTO, error)
+}
+
+type CommentRepository interface {
+	CreateComment(ctx context.Context, comment models.Comment) error
+	UpdateCommentByID(ctx context.Context, commentID string, update models.Comment) (*models.Comment, error)
+	GetAllCommentsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.Comment, error)
+	GetOneCommentByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.Comment, error)
+}
+
+type ActivityRepository interface {
+	CreateActivities(ctx context.Context, activities []models.Activity) error
+	GetAllActivitiesByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.Activity, error)
+}
@@ -0,0 +1,59 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ErrChecklistForbidden ไม่มีสิทธิ์ดูหรือแก้ไข checklist
+var ErrChecklistForbidden = errors.New("no permission to access this checklist")
+
+// ErrChecklistConflict รายการไม่อยู่ในสถานะที่ทำรายการได้ (เช่น ข้อมูลที่ต้องตรวจยังไม่ครบ หรือเริ่ม offboarding ซ้ำ)
+var ErrChecklistConflict = errors.New("checklist conflict")
+
+// ErrChecklistIncomplete รายการบังคับของ onboarding ยังไม่ครบ อนุมัติผู้ใช้ไม่ได้
+var ErrChecklistIncomplete = errors.New("mandatory onboarding items are not complete")
+
+type EmployeeChecklistService interface {
+	UpsertChecklistTemplate(ctx context.Context, req dto.UpsertChecklistTemplateDTO, claims *dto.JWTClaims) (*dto.ChecklistTemplateDTO, error)
+	ListChecklistTemplates(ctx context.Context, req dto.RequestListChecklistTemplates, claims *dto.JWTClaims) ([]dto.ChecklistTemplateDTO, error)
+	DeleteChecklistTemplate(ctx context.Context, templateID string, claims *dto.JWTClaims) error
+
+	// StartOnboarding สร้าง checklist รับพนักงานเข้าจากแม่แบบของตำแหน่ง (ถ้ายังไม่มี)
+	StartOnboarding(ctx context.Context, user *models.User, actorID string) (*models.EmployeeChecklist, error)
+	// EnsureOnboardingComplete ตรวจรายการบังคับก่อนอนุมัติผู้ใช้ คืน ErrChecklistIncomplete ถ้ายังค้าง
+	EnsureOnboardingComplete(ctx context.Context, user *models.User) error
+	// StartOffboarding เริ่ม checklist พนักงานออก โอนงานที่ยังไม่ปิดทันที และยกเลิกสิทธิ์หลังวันทำงานวันสุดท้าย
+	StartOffboarding(ctx context.Context, req dto.StartOffboardingDTO, claims *dto.JWTClaims) (*dto.EmployeeChecklistDTO, error)
+	// CancelOffboarding ยกเลิก offboarding/รับกลับเข้าทำงาน ล้าง access_revoked_at (ต้องอนุมัติผู้ใช้ใหม่ถ้าถูกยกเลิกสิทธิ์แล้ว)
+	CancelOffboarding(ctx context.Context, checklistID string, req dto.CancelOffboardingDTO, claims *dto.JWTClaims) error
+	// RunDueOffboarding ยกเลิกสิทธิ์พนักงานที่พ้นวันทำงานวันสุดท้ายแล้ว (เรียกจาก cron)
+	RunDueOffboarding(ctx context.Context, now time.Time) (*dto.ChecklistRunResult, error)
+	// IsAccessRevoked ใช้ใน middleware ตรวจ token ของผู้ใช้ที่ถูกยกเลิกสิทธิ์ (cache ในหน่วยความจำ)
+	IsAccessRevoked(userID string) bool
+
+	GetUserChecklists(ctx context.Context, userID string, claims *dto.JWTClaims) ([]dto.EmployeeChecklistDTO, error)
+	ListChecklists(ctx context.Context, req dto.RequestListChecklists, claims *dto.JWTClaims) (dto.Pagination, error)
+	ListMyChecklistItems(ctx context.Context, claims *dto.JWTClaims) ([]dto.MyChecklistItemDTO, error)
+	UpdateChecklistItem(ctx context.Context, checklistID, itemID string, req dto.UpdateChecklistItemDTO, claims *dto.JWTClaims) (*dto.EmployeeChecklistDTO, error)
+}
+
+type EmployeeChecklistRepository interface {
+	CreateChecklistTemplate(ctx context.Context, template models.ChecklistTemplate) error
+	GetAllChecklistTemplatesByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.ChecklistTemplate, error)
+	GetOneChecklistTemplateByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.ChecklistTemplate, error)
+	// UpdateChecklistTemplate คืน nil ถ้าไม่พบรายการตาม filter
+	UpdateChecklistTemplate(ctx context.Context, filter interface{}, update interface{}) (*models.ChecklistTemplate, error)
+
+	CreateEmployeeChecklist(ctx context.Context, checklist models.EmployeeChecklist) error
+	GetAllEmployeeChecklistsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.EmployeeChecklist, error)
+	GetOneEmployeeChecklistByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.EmployeeChecklist, error)
+	GetListEmployeeChecklistsByFilter(ctx context.Context, filter interface{}, projection interface{}, sort bson.D, skip, limit int64) ([]models.EmployeeChecklist, int64, error)
+	// UpdateEmployeeChecklist คืน nil ถ้าไม่พบรายการตาม filter
+	UpdateEmployeeChecklist(ctx context.Context, filter interface{}, update interface{}) (*models.EmployeeChecklist, error)
+}
@@ -0,0 +1,40 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+)
+
+// ErrEmploymentHistoryForbidden ไม่มีสิทธิ์ดูหรือแก้ไขประวัติการจ้างงาน
+var ErrEmploymentHistoryForbidden = errors.New("no permission to access employment history")
+
+// ErrEmploymentHistoryConflict รายการไม่อยู่ในสถานะที่ทำรายการได้ หรือวันที่มีผลไม่ถูกต้อง
+var ErrEmploymentHistoryConflict = errors.New("employment history conflict")
+
+type EmploymentHistoryService interface {
+	// StartEmploymentHistory สร้างรายการเริ่มงานจากข้อมูลปัจจุบันของพนักงาน (ถ้ายังไม่มีประวัติ)
+	StartEmploymentHistory(ctx context.Context, user *models.User, actorID string) error
+	// RecordEmploymentChange ปิดช่วงเดิมและเปิดช่วงใหม่ตามวันที่มีผลของ change (ว่าง = วันนี้)
+	// แล้วปรับตำแหน่ง/แผนก/ประเภทการจ้างและประวัติใน user ให้ตรงกับรายการที่มีผล ณ วันนี้ (ผู้เรียกบันทึก user เอง)
+	RecordEmploymentChange(ctx context.Context, user *models.User, change models.EmploymentHistory) error
+	// ApplyDueEmploymentChanges ปรับข้อมูลพนักงานตามรายการที่ตั้งเวลาไว้และถึงวันมีผลแล้ว (เรียกจาก cron)
+	ApplyDueEmploymentChanges(ctx context.Context, now time.Time) (*dto.EmploymentHistoryRunResult, error)
+
+	GetEmploymentTimeline(ctx context.Context, userID string, claims *dto.JWTClaims) (*dto.EmploymentTimelineDTO, error)
+	// GetHeadcountAsOf จำนวนพนักงานแยกตามแผนก ณ วันที่กำหนด (ใช้ประวัติการจ้างงาน)
+	GetHeadcountAsOf(ctx context.Context, req dto.RequestEmploymentHeadcount, claims *dto.JWTClaims) (*dto.EmploymentHeadcountDTO, error)
+	// CancelScheduledEmploymentChange ยกเลิกการเปลี่ยนแปลงที่ยังไม่ถึงวันมีผล
+	CancelScheduledEmploymentChange(ctx context.Context, historyID string, claims *dto.JWTClaims) error
+}
+
+type EmploymentHistoryRepository interface {
+	CreateEmploymentHistory(ctx context.Context, history models.EmploymentHistory) error
+	GetAllEmploymentHistoriesByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.EmploymentHistory, error)
+	GetOneEmploymentHistoryByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.EmploymentHistory, error)
+	// UpdateEmploymentHistory คืน nil ถ้าไม่พบรายการตาม filter
+	UpdateEmploymentHistory(ctx context.Context, filter interface{}, update interface{}) (*models.EmploymentHistory, error)
+}
@@ -0,0 +1,32 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+)
+
+type JobCostService interface {
+	CreateJobMaterial(ctx context.Context, req dto.CreateJobMaterialDTO, claims *dto.JWTClaims) error
+	DeleteJobMaterial(ctx context.Context, materialID string, claims *dto.JWTClaims) error
+	UpsertLaborRate(ctx context.Context, req dto.UpsertLaborRateDTO, claims *dto.JWTClaims) error
+	ListLaborRates(ctx context.Context, claims *dto.JWTClaims) ([]dto.LaborRateDTO, error)
+	DeleteLaborRate(ctx context.Context, rateID string, claims *dto.JWTClaims) error
+	GetJobCost(ctx context.Context, jobID string, claims *dto.JWTClaims) (*dto.JobCostDTO, error)
+	SummaryMarginBySignType(ctx context.Context, req dto.RequestJobMarginSummary, claims *dto.JWTClaims) ([]dto.JobMarginGroupDTO, error)
+	SummaryMarginByCustomerType(ctx context.Context, req dto.RequestJobMarginSummary, claims *dto.JWTClaims) ([]dto.JobMarginGroupDTO, error)
+	LeastProfitableJobs(ctx context.Context, req dto.RequestLeastProfitableJobs, claims *dto.JWTClaims) ([]dto.JobCostDTO, error)
+}
+
+type JobCostRepository interface {
+	CreateJobMaterial(ctx context.Context, material models.JobMaterial) error
+	SoftDeleteJobMaterialByID(ctx context.Context, materialID string) error
+	GetAllJobMaterialsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.JobMaterial, error)
+	GetOneJobMaterialByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.JobMaterial, error)
+	CreateLaborRate(ctx context.Context, rate models.LaborRate) error
+	UpdateLaborRateByID(ctx context.Context, rateID string, update models.LaborRate) (*models.LaborRate, error)
+	SoftDeleteLaborRateByID(ctx context.Context, rateID string) error
+	GetAllLaborRatesByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.LaborRate, error)
+	GetOneLaborRateByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.LaborRate, error)
+}
@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+)
+
+// ErrKPIAnalyticsForbidden ดูสถิติของแผนก/พนักงานนี้ไม่ได้
+var ErrKPIAnalyticsForbidden = errors.New("no permission to view kpi analytics")
+
+// KPIAnalyticsService สถิติ KPI จาก kpi_evaluations (ที่ประเมินแล้ว) และ tasks
+type KPIAnalyticsService interface {
+	// KPITrends แนวโน้มคะแนนรายช่วงเวลา แยกตามพนักงาน/หมวดหัวข้อ/แผนก เทียบค่าเฉลี่ยบริษัท
+	KPITrends(ctx context.Context, req dto.RequestKPIAnalytics, claims *dto.JWTClaims) (*dto.KPITrendDTO, error)
+	KPIDistribution(ctx context.Context, req dto.RequestKPIAnalytics, claims *dto.JWTClaims) (*dto.KPIDistributionDTO, error)
+	KPIPerformers(ctx context.Context, req dto.RequestKPIAnalytics, claims *dto.JWTClaims) (*dto.KPIPerformersDTO, error)
+	// KPICorrelation สหสัมพันธ์ระหว่างคะแนนกับจำนวนงานที่เสร็จ/ล่าช้า รายพนักงาน
+	KPICorrelation(ctx context.Context, req dto.RequestKPIAnalytics, claims *dto.JWTClaims) (*dto.KPICorrelationDTO, error)
+}
@@ -2,22 +2,53 @@ package ports
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/Be2Bag/erp-demo/dto"
 	"github.com/Be2Bag/erp-demo/models"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
+// ErrKPIEvaluationForbidden ไม่มีบทบาทที่ทำรายการนี้ได้ในการประเมิน
+var ErrKPIEvaluationForbidden = errors.New("no permission for this kpi evaluation")
+
+// ErrKPIEvaluationDisputed มีข้อโต้แย้งค้างอยู่ ต้องพิจารณาข้อโต้แย้งก่อนแก้คะแนน
+var ErrKPIEvaluationDisputed = errors.New("kpi evaluation has an open dispute")
+
+// ErrKPIEvaluationConflict สถานะไม่ถูกต้องสำหรับรายการนี้ หรือมีผู้อื่นแก้ไขไปก่อน
+var ErrKPIEvaluationConflict = errors.New("kpi evaluation state conflict")
+
 type KPIEvaluationService interface {
 	ListKPIEvaluation(ctx context.Context, claims *dto.JWTClaims, page, size int, search string, department string, sortBy string, sortOrder string) (dto.Pagination, error)
+	// UpdateKPIEvaluation ผู้ประเมินหลัก (manager) ให้คะแนน คะแนนนี้ใช้คิด KPI
 	UpdateKPIEvaluation(ctx context.Context, evaluationID string, req dto.UpdateKPIEvaluationRequest, claims *dto.JWTClaims) error
 	GetKPIEvaluationByID(ctx context.Context, evaluationID string, claims *dto.JWTClaims) (*dto.KPIEvaluationResponse, error)
+	SubmitSelfKPIEvaluation(ctx context.Context, evaluationID string, req dto.UpdateKPIEvaluationRequest, claims *dto.JWTClaims) (*dto.KPIEvaluationResponse, error)
+	InviteKPIPeers(ctx context.Context, evaluationID string, req dto.InviteKPIPeersRequest, claims *dto.JWTClaims) (*dto.KPIEvaluationResponse, error)
+	SubmitPeerKPIEvaluation(ctx context.Context, evaluationID string, req dto.UpdateKPIEvaluationRequest, claims *dto.JWTClaims) (*dto.KPIEvaluationResponse, error)
+	AcknowledgeKPIEvaluation(ctx context.Context, evaluationID string, req dto.AcknowledgeKPIEvaluationRequest, claims *dto.JWTClaims) (*dto.KPIEvaluationResponse, error)
+	DisputeKPIEvaluation(ctx context.Context, evaluationID string, req dto.DisputeKPIEvaluationRequest, claims *dto.JWTClaims) (*dto.KPIEvaluationResponse, error)
+	// ResolveKPIDispute ผู้จัดการแผนก/HR พิจารณาข้อโต้แย้ง คงคะแนนหรือแก้คะแนน
+	ResolveKPIDispute(ctx context.Context, evaluationID string, req dto.ResolveKPIDisputeRequest, claims *dto.JWTClaims) (*dto.KPIEvaluationResponse, error)
+	GetKPIEvaluationHistory(ctx context.Context, evaluationID string, claims *dto.JWTClaims) ([]dto.KPIRevisionResponse, error)
+	// ListKPIEvaluationInbox งานประเมินที่รอฉัน (ผู้ประเมินหลัก, เพื่อนร่วมงานที่ถูกเชิญ, ข้อโต้แย้งที่ต้องพิจารณา)
+	ListKPIEvaluationInbox(ctx context.Context, claims *dto.JWTClaims) ([]dto.KPIInboxItemDTO, error)
+	// AssignKPIEvaluator admin/ผู้จัดการแผนกเปลี่ยนผู้ประเมินหลัก (ว่าง = เลือกใหม่ตามกฎ) และเปิดแบบประเมินที่ถูกปิดอัตโนมัติ
+	AssignKPIEvaluator(ctx context.Context, evaluationID string, req dto.AssignKPIEvaluatorRequest, claims *dto.JWTClaims) (*dto.KPIEvaluationResponse, error)
+	// RunKPIEvaluationDue มอบหมายผู้ประเมินที่ยังว่าง เตือนผู้ประเมิน และปิดแบบประเมินที่ค้างเกินกำหนด (เรียกจาก cron)
+	RunKPIEvaluationDue(ctx context.Context, now time.Time) (*dto.KPIEvaluationRunResult, error)
+	RecomputeKPIEvaluations(ctx context.Context, kpiID string) (*dto.KPIRecomputeResult, error)
 }
 type KPIEvaluationRepository interface {
 	CreateKPIEvaluations(ctx context.Context, kpi models.KPIEvaluation) error
 	UpdateKPIEvaluationByID(ctx context.Context, evaluationID string, update models.KPIEvaluation) (*models.KPIEvaluation, error)
+	// UpdateKPIEvaluationIfUnchanged อัปเดตเฉพาะเมื่อ updated_at ยังเท่าค่าที่อ่านมา คืน nil ถ้ามีผู้อื่นแก้ไขไปก่อน
+	UpdateKPIEvaluationIfUnchanged(ctx context.Context, evaluationID string, lastUpdatedAt time.Time, update models.KPIEvaluation) (*models.KPIEvaluation, error)
 	SoftDeleteKPIEvaluationByID(ctx context.Context, evaluationID string) error
 	GetAllKPIEvaluationByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.KPIEvaluation, error)
 	GetOneKPIEvaluationByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.KPIEvaluation, error)
 	GetListKPIEvaluationByFilter(ctx context.Context, filter interface{}, projection interface{}, sort bson.D, skip, limit int64) ([]models.KPIEvaluation, int64, error)
+	// UpdateManyKPIEvaluationFields $set/$inc เฉพาะฟิลด์ คืนจำนวนที่แก้ไข
+	UpdateManyKPIEvaluationFields(ctx context.Context, filter interface{}, set bson.M, inc bson.M) (int64, error)
 }
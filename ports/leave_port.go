@@ -0,0 +1,52 @@
+package ports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ErrLeaveForbidden ไม่มีสิทธิ์ดู/อนุมัติใบลารายการนี้
+var ErrLeaveForbidden = errors.New("no permission to access this leave request")
+
+// ErrLeaveConflict ใบลาเปลี่ยนสถานะไปแล้ว หรือช่วงวันลาซ้อนกับใบลาอื่น
+var ErrLeaveConflict = errors.New("leave request conflict")
+
+type LeaveService interface {
+	// ListLeavePolicies นโยบายที่ตั้งไว้ทั้งหมด พร้อมค่าเริ่มต้นของประเภทที่ยังไม่ได้ตั้ง
+	ListLeavePolicies(ctx context.Context, claims *dto.JWTClaims) ([]dto.LeavePolicyDTO, error)
+	UpsertLeavePolicy(ctx context.Context, req dto.UpsertLeavePolicyDTO, claims *dto.JWTClaims) (*dto.LeavePolicyDTO, error)
+	DeleteLeavePolicy(ctx context.Context, policyID string, claims *dto.JWTClaims) error
+
+	GetLeaveBalances(ctx context.Context, req dto.RequestLeaveBalance, claims *dto.JWTClaims) (*dto.LeaveBalanceSummaryDTO, error)
+	CreateLeaveRequest(ctx context.Context, req dto.CreateLeaveRequestDTO, claims *dto.JWTClaims) (*dto.LeaveRequestDTO, error)
+	ListLeaveRequests(ctx context.Context, req dto.RequestListLeaveRequests, claims *dto.JWTClaims) (dto.Pagination, error)
+	// ListLeaveApprovals ใบลาที่รอผู้เรียกดำเนินการ (ผู้จัดการ: รออนุมัติ, HR: รออนุมัติที่ไม่มีผู้จัดการ + รอบันทึก)
+	ListLeaveApprovals(ctx context.Context, claims *dto.JWTClaims) ([]dto.LeaveRequestDTO, error)
+	GetLeaveRequest(ctx context.Context, requestID string, claims *dto.JWTClaims) (*dto.LeaveRequestDTO, error)
+	ApproveLeaveRequest(ctx context.Context, requestID string, req dto.LeaveDecisionDTO, claims *dto.JWTClaims) (*dto.LeaveRequestDTO, error)
+	RejectLeaveRequest(ctx context.Context, requestID string, req dto.LeaveDecisionDTO, claims *dto.JWTClaims) (*dto.LeaveRequestDTO, error)
+	// RecordLeaveRequest HR บันทึกใบลาที่อนุมัติแล้วเข้าระบบบุคคล
+	RecordLeaveRequest(ctx context.Context, requestID string, req dto.LeaveDecisionDTO, claims *dto.JWTClaims) (*dto.LeaveRequestDTO, error)
+	CancelLeaveRequest(ctx context.Context, requestID string, req dto.LeaveDecisionDTO, claims *dto.JWTClaims) (*dto.LeaveRequestDTO, error)
+	// GetLeaveCalendar ปฏิทินการลาของทีมรายวัน พร้อมวันหยุด
+	GetLeaveCalendar(ctx context.Context, req dto.RequestLeaveCalendar, claims *dto.JWTClaims) (*dto.LeaveCalendarDTO, error)
+}
+
+type LeaveRepository interface {
+	CreateLeavePolicy(ctx context.Context, policy models.LeavePolicy) error
+	UpdateLeavePolicyByID(ctx context.Context, policyID string, update models.LeavePolicy) (*models.LeavePolicy, error)
+	SoftDeleteLeavePolicyByID(ctx context.Context, policyID string) error
+	GetAllLeavePoliciesByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.LeavePolicy, error)
+	GetOneLeavePolicyByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.LeavePolicy, error)
+
+	CreateLeaveRequest(ctx context.Context, request models.LeaveRequest) error
+	GetAllLeaveRequestsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.LeaveRequest, error)
+	GetOneLeaveRequestByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.LeaveRequest, error)
+	GetListLeaveRequestsByFilter(ctx context.Context, filter interface{}, projection interface{}, sort bson.D, skip, limit int64) ([]models.LeaveRequest, int64, error)
+	// TransitionLeaveRequest เปลี่ยนสถานะแบบมีเงื่อนไขสถานะเดิม คืน nil ถ้าสถานะไม่ตรง (มีคนเปลี่ยนไปก่อน)
+	TransitionLeaveRequest(ctx context.Context, requestID string, from []string, set bson.M) (*models.LeaveRequest, error)
+}
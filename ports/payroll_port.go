@@ -0,0 +1,58 @@
+package ports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ErrPayrollForbidden ไม่มีสิทธิ์เข้าถึงข้อมูลเงินเดือน
+var ErrPayrollForbidden = errors.New("no permission to access payroll")
+
+// ErrPayrollConflict รอบเงินเดือนซ้ำ หรือสถานะรอบไม่อนุญาตให้ทำรายการ
+var ErrPayrollConflict = errors.New("payroll conflict")
+
+type PayrollService interface {
+	ListSalaryStructures(ctx context.Context, claims *dto.JWTClaims) ([]dto.SalaryStructureDTO, error)
+	GetSalaryStructure(ctx context.Context, userID string, claims *dto.JWTClaims) (*dto.SalaryStructureDTO, error)
+	UpsertSalaryStructure(ctx context.Context, req dto.UpsertSalaryStructureDTO, claims *dto.JWTClaims) (*dto.SalaryStructureDTO, error)
+
+	// CreatePayrollRun สร้างรอบเงินเดือน (draft) และคำนวณสลิปจากโครงสร้างเงินเดือนกับสรุปการลงเวลา
+	CreatePayrollRun(ctx context.Context, req dto.CreatePayrollRunDTO, claims *dto.JWTClaims) (*dto.PayrollRunDTO, error)
+	ListPayrollRuns(ctx context.Context, req dto.RequestListPayrollRuns, claims *dto.JWTClaims) (dto.Pagination, error)
+	GetPayrollRun(ctx context.Context, runID string, claims *dto.JWTClaims) (*dto.PayrollRunDTO, error)
+	RecalculatePayrollRun(ctx context.Context, runID string, claims *dto.JWTClaims) (*dto.PayrollRunDTO, error)
+	AddPayrollAdjustment(ctx context.Context, runID string, req dto.PayrollAdjustmentDTO, claims *dto.JWTClaims) (*dto.PayrollRunDTO, error)
+	ApprovePayrollRun(ctx context.Context, runID string, req dto.PayrollDecisionDTO, claims *dto.JWTClaims) (*dto.PayrollRunDTO, error)
+	// PayPayrollRun บันทึกว่าโอนเงินแล้ว และสร้างรายจ่ายหนึ่งรายการต่อรอบ
+	PayPayrollRun(ctx context.Context, runID string, req dto.PayrollPaymentDTO, claims *dto.JWTClaims) (*dto.PayrollRunDTO, error)
+	CancelPayrollRun(ctx context.Context, runID string, req dto.PayrollDecisionDTO, claims *dto.JWTClaims) (*dto.PayrollRunDTO, error)
+	// BuildPayrollBankFile ไฟล์ CSV สำหรับโอนเงินเดือนแบบกลุ่ม คืนเนื้อหาและชื่อไฟล์
+	BuildPayrollBankFile(ctx context.Context, runID string, claims *dto.JWTClaims) ([]byte, string, error)
+
+	ListMyPayslips(ctx context.Context, req dto.RequestMyPayslips, claims *dto.JWTClaims) ([]dto.PayslipDTO, error)
+	// RenderPayslipPDF สลิปเงินเดือน PDF (เจ้าของเห็นเฉพาะรอบที่อนุมัติแล้ว)
+	RenderPayslipPDF(ctx context.Context, payslipID string, claims *dto.JWTClaims) ([]byte, string, error)
+}
+
+type PayrollRepository interface {
+	// UpsertSalaryStructure สร้างหรือแทนที่โครงสร้างเงินเดือนของพนักงาน (หนึ่งรายการต่อคน)
+	UpsertSalaryStructure(ctx context.Context, structure models.SalaryStructure) (*models.SalaryStructure, error)
+	GetAllSalaryStructuresByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.SalaryStructure, error)
+	GetOneSalaryStructureByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.SalaryStructure, error)
+
+	CreatePayrollRun(ctx context.Context, run models.PayrollRun) error
+	GetOnePayrollRunByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.PayrollRun, error)
+	GetAllPayrollRunsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.PayrollRun, error)
+	GetListPayrollRunsByFilter(ctx context.Context, filter interface{}, projection interface{}, sort bson.D, skip, limit int64) ([]models.PayrollRun, int64, error)
+	// TransitionPayrollRun อัปเดตแบบมีเงื่อนไขสถานะเดิม คืน nil ถ้าสถานะไม่ตรง
+	TransitionPayrollRun(ctx context.Context, runID string, from []string, set bson.M) (*models.PayrollRun, error)
+
+	// ReplacePayslips แทนที่สลิปทั้งหมดของรอบ (คำนวณใหม่)
+	ReplacePayslips(ctx context.Context, runID string, payslips []models.Payslip) error
+	GetAllPayslipsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.Payslip, error)
+	GetOnePayslipByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.Payslip, error)
+}
@@ -0,0 +1,40 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ErrRecurringTaskForbidden ไม่มีสิทธิ์จัดการงานประจำ (admin หรือผู้จัดการแผนกเท่านั้น)
+var ErrRecurringTaskForbidden = errors.New("no permission to manage this recurring task")
+
+type RecurringTaskService interface {
+	CreateRecurringTask(ctx context.Context, req dto.CreateRecurringTaskDTO, claims *dto.JWTClaims) (*dto.RecurringTaskDTO, error)
+	ListRecurringTasks(ctx context.Context, req dto.RequestListRecurringTask, claims *dto.JWTClaims) (dto.Pagination, error)
+	GetRecurringTask(ctx context.Context, recurringID string, claims *dto.JWTClaims) (*dto.RecurringTaskDTO, error)
+	UpdateRecurringTask(ctx context.Context, recurringID string, req dto.UpdateRecurringTaskDTO, claims *dto.JWTClaims) (*dto.RecurringTaskDTO, error)
+	DeleteRecurringTask(ctx context.Context, recurringID string, claims *dto.JWTClaims) error
+	// RunRecurringTaskNow สร้างงานรอบพิเศษทันที (ไม่เลื่อนรอบตามตาราง แต่นับคิวผู้รับผิดชอบ)
+	RunRecurringTaskNow(ctx context.Context, recurringID string, claims *dto.JWTClaims) (*dto.RecurringSpawnDTO, error)
+	// RunDue สร้างงานของทุกรายการที่ถึงรอบ (เรียกจาก cron)
+	RunDue(ctx context.Context, now time.Time) (*dto.RecurringRunResult, error)
+}
+
+type RecurringTaskRepository interface {
+	CreateRecurringTask(ctx context.Context, def models.RecurringTask) error
+	UpdateRecurringTaskByID(ctx context.Context, recurringID string, update models.RecurringTask) (*models.RecurringTask, error)
+	SoftDeleteRecurringTaskByID(ctx context.Context, recurringID string) error
+	GetAllRecurringTasksByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.RecurringTask, error)
+	GetOneRecurringTaskByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.RecurringTask, error)
+	GetListRecurringTasksByFilter(ctx context.Context, filter interface{}, projection interface{}, sort bson.D, skip, limit int64) ([]models.RecurringTask, int64, error)
+	// ClaimRecurringRun จองรอบ run_at แบบ atomic (เลื่อน next_run_at และนับรอบ) คืนเอกสารก่อนเลื่อน หรือ nil ถ้ามีคนจองไปแล้ว
+	ClaimRecurringRun(ctx context.Context, recurringID string, runAt time.Time, nextRunAt *time.Time, now time.Time) (*models.RecurringTask, error)
+	// IncrementAssigneeIndex เลื่อนคิวผู้รับผิดชอบแบบ atomic และคืนค่าตำแหน่งก่อนเลื่อน
+	IncrementAssigneeIndex(ctx context.Context, recurringID string) (int, error)
+	SetRecurringRunResult(ctx context.Context, recurringID, taskID, lastError string, now time.Time) error
+}
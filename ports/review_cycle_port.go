@@ -0,0 +1,67 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ErrReviewForbidden ไม่มีสิทธิ์ดู/ประเมินผลงานรายการนี้
+var ErrReviewForbidden = errors.New("no permission to access this performance review")
+
+// ErrReviewLocked รอบประเมินไม่อยู่ในสถานะที่แก้ไขได้ หรือผลถูกยืนยัน (ล็อก) แล้ว
+var ErrReviewLocked = errors.New("performance review is locked")
+
+type ReviewCycleService interface {
+	CreateReviewCycle(ctx context.Context, req dto.CreateReviewCycleDTO, claims *dto.JWTClaims) (*dto.ReviewCycleDTO, error)
+	ListReviewCycles(ctx context.Context, req dto.RequestListReviewCycle, claims *dto.JWTClaims) (dto.Pagination, error)
+	GetReviewCycle(ctx context.Context, cycleID string, claims *dto.JWTClaims) (*dto.ReviewCycleDTO, error)
+	UpdateReviewCycle(ctx context.Context, cycleID string, req dto.UpdateReviewCycleDTO, claims *dto.JWTClaims) (*dto.ReviewCycleDTO, error)
+	DeleteReviewCycle(ctx context.Context, cycleID string, claims *dto.JWTClaims) error
+	// OpenReviewCycle เปิดรอบทันทีและสร้างแบบประเมินของผู้เข้าร่วม
+	OpenReviewCycle(ctx context.Context, cycleID string, claims *dto.JWTClaims) (*dto.ReviewCycleDTO, error)
+	CloseReviewCycle(ctx context.Context, cycleID string, claims *dto.JWTClaims) (*dto.ReviewCycleDTO, error)
+	// SyncReviewParticipants เพิ่มพนักงานใหม่ในแผนกที่เข้าร่วมระหว่างรอบเปิด
+	SyncReviewParticipants(ctx context.Context, cycleID string, claims *dto.JWTClaims) (*dto.ReviewSyncResult, error)
+	GetReviewCycleProgress(ctx context.Context, cycleID string, claims *dto.JWTClaims) (*dto.ReviewCycleProgressDTO, error)
+	SendReviewReminders(ctx context.Context, cycleID string, claims *dto.JWTClaims) (*dto.ReviewReminderResult, error)
+
+	ListPerformanceReviews(ctx context.Context, cycleID string, req dto.RequestListPerformanceReview, claims *dto.JWTClaims) (dto.Pagination, error)
+	ListMyPerformanceReviews(ctx context.Context, req dto.RequestMyPerformanceReview, claims *dto.JWTClaims) ([]dto.PerformanceReviewDTO, error)
+	GetPerformanceReview(ctx context.Context, reviewID string, claims *dto.JWTClaims) (*dto.PerformanceReviewDTO, error)
+	SubmitSelfReview(ctx context.Context, reviewID string, req dto.SubmitReviewScoresDTO, claims *dto.JWTClaims) (*dto.PerformanceReviewDTO, error)
+	SubmitManagerReview(ctx context.Context, reviewID string, req dto.SubmitReviewScoresDTO, claims *dto.JWTClaims) (*dto.PerformanceReviewDTO, error)
+	// FinalizeReview HR ปรับเทียบคะแนนและล็อกผลสุดท้าย
+	FinalizeReview(ctx context.Context, reviewID string, req dto.FinalizeReviewDTO, claims *dto.JWTClaims) (*dto.PerformanceReviewDTO, error)
+	// ListReviewResults ผลสุดท้ายที่ล็อกแล้วของพนักงานทุกรอบ
+	ListReviewResults(ctx context.Context, req dto.RequestReviewResults, claims *dto.JWTClaims) ([]dto.PerformanceReviewDTO, error)
+
+	// RunDue เปิด/ปิดรอบตามวันที่ และส่งเตือนก่อนปิดรอบ (เรียกจาก cron)
+	RunDue(ctx context.Context, now time.Time) (*dto.ReviewCycleRunResult, error)
+}
+
+type ReviewCycleRepository interface {
+	CreateReviewCycle(ctx context.Context, cycle models.ReviewCycle) error
+	UpdateReviewCycleByID(ctx context.Context, cycleID string, update models.ReviewCycle) (*models.ReviewCycle, error)
+	SoftDeleteReviewCycleByID(ctx context.Context, cycleID string) error
+	GetAllReviewCyclesByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.ReviewCycle, error)
+	GetOneReviewCycleByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.ReviewCycle, error)
+	GetListReviewCyclesByFilter(ctx context.Context, filter interface{}, projection interface{}, sort bson.D, skip, limit int64) ([]models.ReviewCycle, int64, error)
+	// TransitionReviewCycle เปลี่ยนสถานะแบบมีเงื่อนไขสถานะเดิม คืน nil ถ้าสถานะไม่ตรง (มีคนเปลี่ยนไปก่อน)
+	TransitionReviewCycle(ctx context.Context, cycleID, from, to string, now time.Time) (*models.ReviewCycle, error)
+	SetReviewCycleParticipants(ctx context.Context, cycleID string, participants int, now time.Time) error
+	SetReviewCycleReminded(ctx context.Context, cycleID string, now time.Time) error
+
+	// UpsertPerformanceReview สร้างแบบประเมินถ้ายังไม่มี (cycle_id + user_id) คืน true เมื่อสร้างใหม่
+	UpsertPerformanceReview(ctx context.Context, review models.PerformanceReview) (bool, error)
+	// UpdatePerformanceReviewByID อัปเดตเฉพาะแบบประเมินที่ยังไม่ถูกยืนยันผล คืน nil ถ้าถูกล็อกแล้ว
+	UpdatePerformanceReviewByID(ctx context.Context, reviewID string, update models.PerformanceReview) (*models.PerformanceReview, error)
+	GetAllPerformanceReviewsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.PerformanceReview, error)
+	GetOnePerformanceReviewByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.PerformanceReview, error)
+	GetListPerformanceReviewsByFilter(ctx context.Context, filter interface{}, projection interface{}, sort bson.D, skip, limit int64) ([]models.PerformanceReview, int64, error)
+	CountPerformanceReviews(ctx context.Context, filter interface{}) (int64, error)
+}
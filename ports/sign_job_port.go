@@ -28,4 +28,6 @@ type SignJobService interface {
 	DeleteSignJobByJobID(ctx context.Context, jobID string, claims *dto.JWTClaims) error
 	VerifySignJob(ctx context.Context, jobID string, claims *dto.JWTClaims) error
 	ConfirmSignJob(ctx context.Context, jobID string, claims *dto.JWTClaims) error // ยืนยันงาน (เปลี่ยน WaitConfirm จาก true เป็น false)
+	CancelSignJob(ctx context.Context, jobID string, req dto.CancelSignJobDTO, claims *dto.JWTClaims) (*dto.CancelSignJobResultDTO, error)
+	GenerateProductionTasks(ctx context.Context, jobID string, claims *dto.JWTClaims) ([]string, error) // สร้าง task การผลิตจาก workflow ของประเภทป้าย
 }
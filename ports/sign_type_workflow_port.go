@@ -0,0 +1,27 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type SignTypeWorkflowService interface {
+	CreateSignTypeWorkflow(ctx context.Context, req dto.CreateSignTypeWorkflowDTO, claims *dto.JWTClaims) error
+	ListSignTypeWorkflows(ctx context.Context, claims *dto.JWTClaims, page, size int, signTypeID string) (dto.Pagination, error)
+	GetSignTypeWorkflowByID(ctx context.Context, mappingID string, claims *dto.JWTClaims) (*dto.SignTypeWorkflowDTO, error)
+	UpdateSignTypeWorkflow(ctx context.Context, mappingID string, req dto.UpdateSignTypeWorkflowDTO, claims *dto.JWTClaims) error
+	DeleteSignTypeWorkflow(ctx context.Context, mappingID string, claims *dto.JWTClaims) error
+}
+
+type SignTypeWorkflowRepository interface {
+	CreateSignTypeWorkflow(ctx context.Context, mapping models.SignTypeWorkflow) error
+	UpdateSignTypeWorkflowByID(ctx context.Context, mappingID string, update models.SignTypeWorkflow) (*models.SignTypeWorkflow, error)
+	SoftDeleteSignTypeWorkflowByID(ctx context.Context, mappingID string) error
+	GetAllSignTypeWorkflowsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.SignTypeWorkflow, error)
+	GetOneSignTypeWorkflowByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.SignTypeWorkflow, error)
+	GetListSignTypeWorkflowsByFilter(ctx context.Context, filter interface{}, projection interface{}, sort bson.D, skip, limit int64) ([]models.SignTypeWorkflow, int64, error)
+	IncrementPoolIndex(ctx context.Context, mappingID string, itemIndex int) (int, error)
+}
@@ -0,0 +1,36 @@
+package ports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+)
+
+// ErrSLAForbidden ไม่มีสิทธิ์ดูหรือจัดการข้อมูล SLA
+var ErrSLAForbidden = errors.New("no permission to access sla data")
+
+type SLAService interface {
+	CreateRule(ctx context.Context, req dto.CreateSLARuleDTO, claims *dto.JWTClaims) (*dto.SLARuleDTO, error)
+	UpdateRule(ctx context.Context, ruleID string, req dto.UpdateSLARuleDTO, claims *dto.JWTClaims) (*dto.SLARuleDTO, error)
+	DeleteRule(ctx context.Context, ruleID string, claims *dto.JWTClaims) error
+	ListRules(ctx context.Context, req dto.RequestListSLARules, claims *dto.JWTClaims) ([]dto.SLARuleDTO, error)
+	ListBreaches(ctx context.Context, req dto.RequestListSLABreaches, claims *dto.JWTClaims) (dto.Pagination, error)
+	BreachSummary(ctx context.Context, req dto.RequestSLABreachSummary, claims *dto.JWTClaims) ([]dto.SLABreachSummaryDTO, error)
+	RunCheck(ctx context.Context) (*dto.SLACheckResult, error)
+}
+
+type SLARepository interface {
+	CreateRule(ctx context.Context, rule models.SLARule) error
+	UpdateRuleByID(ctx context.Context, ruleID string, update models.SLARule) (*models.SLARule, error)
+	SoftDeleteRuleByID(ctx context.Context, ruleID string) error
+	GetAllRulesByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.SLARule, error)
+	GetOneRuleByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.SLARule, error)
+
+	CreateBreach(ctx context.Context, breach models.SLABreach) error
+	UpdateBreachByID(ctx context.Context, breachID string, update models.SLABreach) (*models.SLABreach, error)
+	GetAllBreachesByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.SLABreach, error)
+	GetOneBreachByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.SLABreach, error)
+	GetListBreachesByFilter(ctx context.Context, filter interface{}, skip, limit int64) ([]*models.SLABreach, int64, error)
+}
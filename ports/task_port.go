@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/Be2Bag/erp-demo/dto"
@@ -9,6 +10,21 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 )
 
+// ErrStepForbidden ไม่มีสิทธิ์มอบหมายผู้รับผิดชอบ step
+var ErrStepForbidden = errors.New("no permission to assign this step")
+
+// ErrStepPrerequisitesPending เริ่ม step ไม่ได้เพราะ prerequisite ยังไม่ done/skip
+var ErrStepPrerequisitesPending = errors.New("prerequisite steps are not completed")
+
+// ErrStepChecklistIncomplete ปิด step เป็น done ไม่ได้เพราะยังติ๊ก checklist ไม่ครบ
+var ErrStepChecklistIncomplete = errors.New("checklist items are not completed")
+
+// ErrTaskReassignForbidden ไม่มีสิทธิ์เปลี่ยนผู้รับผิดชอบหลักของงาน
+var ErrTaskReassignForbidden = errors.New("no permission to reassign this task")
+
+// ErrTaskReassignConflict งานถูกเปลี่ยนผู้รับผิดชอบโดยรายการอื่นระหว่างทำรายการ
+var ErrTaskReassignConflict = errors.New("task assignee was changed by another request")
+
 type TaskService interface {
 	GetListTasks(ctx context.Context, claims *dto.JWTClaims, page, size int, search string, department string, sortBy string, sortOrder string, status string) (dto.Pagination, error)
 	CreateTask(ctx context.Context, createTask dto.CreateTaskRequest, claims *dto.JWTClaims) error
@@ -16,6 +32,15 @@ type TaskService interface {
 	// UpdateTask(ctx context.Context, taskID string, req dto.UpdateTaskRequest, updatedBy string) error
 	DeleteTask(ctx context.Context, taskID string, claims *dto.JWTClaims) error
 	UpdateStepStatus(ctx context.Context, taskID, stepID string, req dto.UpdateStepStatusNoteRequest, claims *dto.JWTClaims) error
+	AssignStep(ctx context.Context, taskID, stepID string, req dto.AssignStepRequest, claims *dto.JWTClaims) error
+	AddChecklistItem(ctx context.Context, taskID, stepID string, req dto.AddChecklistItemRequest, claims *dto.JWTClaims) (*dto.TaskChecklistItem, error)
+	CheckChecklistItem(ctx context.Context, taskID, stepID, itemID string, req dto.CheckChecklistItemRequest, claims *dto.JWTClaims) error
+	RemoveChecklistItem(ctx context.Context, taskID, stepID, itemID string, claims *dto.JWTClaims) error
+	ReassignTask(ctx context.Context, taskID string, req dto.ReassignTaskRequest, claims *dto.JWTClaims) (*dto.TaskReassignmentDTO, error)
+	// CheckTaskStats เทียบ user_task_stats กับงานจริง (userID ว่าง = ทุกคน) ไม่แก้ข้อมูล
+	CheckTaskStats(ctx context.Context, userID string) (*dto.TaskStatsCheckResult, error)
+	// RebuildTaskStats นับ totals ใหม่จาก tasks แล้วเขียนทับ (ไม่แตะ KPI)
+	RebuildTaskStats(ctx context.Context, userID string) (*dto.TaskStatsCheckResult, error)
 
 	ReplaceTask(ctx context.Context, taskID string, req dto.UpdateTaskPutRequest, updatedBy string) error
 }
@@ -30,11 +55,21 @@ type TaskRepository interface {
 	//<------------------------------------------------------------------------------->
 	GetAllStepSteps(ctx context.Context, taskID string) ([]models.TaskWorkflowStep, error)
 	UpdateOneStepFields(ctx context.Context, taskID, stepID string, status *string, notes *string, now time.Time) error
-	UpdateTaskStatus(ctx context.Context, taskID, status, stepName string, now time.Time) error
+	UpdateTaskStatus(ctx context.Context, taskID, status, stepName string, readySteps []string, now time.Time) error
+	UpdateStepAssignee(ctx context.Context, taskID, stepID, assignee, assigneeName string, now time.Time) error
+	AddStepChecklistItem(ctx context.Context, taskID, stepID string, item models.TaskChecklistItem, now time.Time) error
+	UpdateStepChecklistItem(ctx context.Context, taskID, stepID string, item models.TaskChecklistItem, now time.Time) error
+	RemoveStepChecklistItem(ctx context.Context, taskID, stepID, itemID string, now time.Time) error
 
 	GetOneUserTaskStatsByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.UserTaskStats, error)
 	GetAllUserTaskStatsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.UserTaskStats, error)
 	UpsertUserTaskStats(ctx context.Context, stats *models.UserTaskStats) error
+	// IncUserTaskStats บวก/ลบ totals แบบ atomic ($inc) สร้างเอกสารใหม่ถ้ายังไม่มี
+	IncUserTaskStats(ctx context.Context, userID, departmentID string, delta models.UserTaskTotals, now time.Time) error
+	SetUserTaskTotals(ctx context.Context, userID, departmentID string, totals models.UserTaskTotals, now time.Time) error
+	SetUserTaskKPI(ctx context.Context, userID string, kpi models.UserTaskKPI, now time.Time) error
+	// ReassignTask เปลี่ยนผู้รับผิดชอบหลักเฉพาะเมื่อผู้รับผิดชอบยังเป็น fromAssignee (คืน nil ถ้าไม่ตรง)
+	ReassignTask(ctx context.Context, taskID, fromAssignee string, doc *models.Tasks, entry models.TaskReassignment) (*models.Tasks, error)
 
 	ReplaceTaskByID(ctx context.Context, taskID string, doc *models.Tasks) (*models.Tasks, error)
 	UpdateManyTaskFields(ctx context.Context, filter interface{}, update bson.M) (int64, error)
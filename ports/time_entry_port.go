@@ -0,0 +1,44 @@
+package ports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+)
+
+// ErrTimeEntryForbidden ไม่มีสิทธิ์ลงเวลา/แก้ไข/อนุมัติรายการนี้
+var ErrTimeEntryForbidden = errors.New("no permission on this time entry")
+
+// ErrTimeEntryOverlap ช่วงเวลาทับรายการอื่นของผู้ใช้ (ลงชั่วโมงซ้ำ)
+var ErrTimeEntryOverlap = errors.New("time entry overlaps another entry")
+
+// ErrTimerNeedsConfirmation จับเวลานานเกินกำหนด ต้องระบุเวลาหยุดจริง
+var ErrTimerNeedsConfirmation = errors.New("timer needs a confirmed stop time")
+
+type TimeEntryService interface {
+	StartTimer(ctx context.Context, req dto.StartTimerDTO, claims *dto.JWTClaims) (*dto.TimeEntryDTO, error)
+	StopTimer(ctx context.Context, req dto.StopTimerDTO, claims *dto.JWTClaims) (*dto.TimeEntryDTO, error)
+	GetRunningTimer(ctx context.Context, claims *dto.JWTClaims) (*dto.TimeEntryDTO, error)
+	CreateManualEntry(ctx context.Context, req dto.CreateManualTimeEntryDTO, claims *dto.JWTClaims) (*dto.TimeEntryDTO, error)
+	UpdateTimeEntry(ctx context.Context, entryID string, req dto.UpdateTimeEntryDTO, claims *dto.JWTClaims) (*dto.TimeEntryDTO, error)
+	ListTimeEntries(ctx context.Context, req dto.RequestListTimeEntries, claims *dto.JWTClaims) ([]dto.TimeEntryDTO, error)
+	EstimateVsActual(ctx context.Context, req dto.RequestTimeReport, claims *dto.JWTClaims) ([]dto.EstimateVsActualDTO, error)
+	GetTimesheet(ctx context.Context, req dto.RequestTimesheet, claims *dto.JWTClaims) (*dto.TimesheetDTO, error)
+	SubmitTimesheet(ctx context.Context, req dto.SubmitTimesheetDTO, claims *dto.JWTClaims) (*dto.TimesheetDTO, error)
+	ReviewTimesheet(ctx context.Context, timesheetID string, req dto.ReviewTimesheetDTO, claims *dto.JWTClaims) (*dto.TimesheetDTO, error)
+	ListTimesheets(ctx context.Context, req dto.RequestListTimesheets, claims *dto.JWTClaims) ([]dto.TimesheetDTO, error)
+}
+
+type TimeEntryRepository interface {
+	CreateTimeEntry(ctx context.Context, entry models.TimeEntry) error
+	UpdateTimeEntryByID(ctx context.Context, entryID string, update models.TimeEntry) (*models.TimeEntry, error)
+	GetAllTimeEntriesByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.TimeEntry, error)
+	GetOneTimeEntryByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.TimeEntry, error)
+
+	CreateTimesheet(ctx context.Context, timesheet models.Timesheet) error
+	UpdateTimesheetByID(ctx context.Context, timesheetID string, update models.Timesheet) (*models.Timesheet, error)
+	GetAllTimesheetsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.Timesheet, error)
+	GetOneTimesheetByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.Timesheet, error)
+}
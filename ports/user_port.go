@@ -12,7 +12,7 @@ type UserService interface {
 	Create(ctx context.Context, u dto.RequestCreateUser) error
 	GetByID(ctx context.Context, id string) (*dto.ResponseGetUserByID, error)
 	GetAll(ctx context.Context, req dto.RequestGetUserAll) (dto.Pagination, error)
-	UpdateUserByID(ctx context.Context, id string, req dto.RequestUpdateUser) (*models.User, error)
+	UpdateUserByID(ctx context.Context, id string, req dto.RequestUpdateUser, claims *dto.JWTClaims) (*models.User, error)
 	DeleteUserByID(ctx context.Context, id string) error
 	UpdateDocuments(ctx context.Context, req dto.RequestUpdateDocuments) (*models.User, error)
 	CountUsers(ctx context.Context) (dto.ResponseGetCountUsers, error)
@@ -2,18 +2,27 @@ package ports
 
 import (
 	"context"
+	"errors"
 
 	"github.com/Be2Bag/erp-demo/dto"
 	"github.com/Be2Bag/erp-demo/models"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
+// ErrWorkflowForbidden ไม่มีสิทธิ์ย้ายงานระหว่างเวอร์ชันของ workflow
+var ErrWorkflowForbidden = errors.New("no permission to migrate workflow tasks")
+
 type WorkFlowService interface {
 	CreateWorkflowTemplate(ctx context.Context, req dto.CreateWorkflowTemplateDTO, claims *dto.JWTClaims) error
 	GetWorkflowTemplateByID(ctx context.Context, workflowID string) (*dto.WorkflowTemplateDTO, error)
 	ListWorkflowTemplates(ctx context.Context, claims *dto.JWTClaims, page, size int, search string, department_id string, sortBy string, sortOrder string) (dto.Pagination, error)
 	UpdateWorkflowTemplate(ctx context.Context, workflowID string, req dto.UpdateWorkflowTemplateDTO, updatedBy string) error
 	DeleteWorkflowTemplate(ctx context.Context, workflowID string) error
+
+	ListWorkflowVersions(ctx context.Context, workflowID string) ([]dto.WorkflowVersionDTO, error)
+	GetWorkflowVersion(ctx context.Context, workflowID string, version int) (*dto.WorkflowVersionDTO, error)
+	DiffWorkflowVersions(ctx context.Context, workflowID string, req dto.RequestWorkflowDiff) (*dto.WorkflowVersionDiffDTO, error)
+	MigrateWorkflowTasks(ctx context.Context, workflowID string, req dto.MigrateWorkflowTasksDTO, claims *dto.JWTClaims) (*dto.WorkflowMigrationResultDTO, error)
 }
 
 type WorkFlowRepository interface {
@@ -24,3 +33,9 @@ type WorkFlowRepository interface {
 	GetOneWorkFlowTemplateByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.WorkFlowTemplate, error)
 	GetListWorkFlowTemplatesByFilter(ctx context.Context, filter interface{}, projection interface{}, sort bson.D, skip, limit int64) ([]models.WorkFlowTemplate, int64, error)
 }
+
+type WorkFlowVersionRepository interface {
+	CreateWorkFlowVersion(ctx context.Context, version models.WorkFlowTemplateVersion) error
+	GetAllWorkFlowVersionsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.WorkFlowTemplateVersion, error)
+	GetOneWorkFlowVersionByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.WorkFlowTemplateVersion, error)
+}
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type activityRepo struct {
+	coll *mongo.Collection
+}
+
+func NewActivityRepository(db *mongo.Database) ports.ActivityRepository {
+	return &activityRepo{
+		coll: db.Collection(models.CollectionActivities),
+	}
+}
+
+func (r *activityRepo) CreateActivities(ctx context.Context, activities []models.Activity) error {
+	if len(activities) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, 0, len(activities))
+	for _, a := range activities {
+		docs = append(docs, a)
+	}
+	_, err := r.coll.InsertMany(ctx, docs)
+	return err
+}
+
+func (r *activityRepo) GetAllActivitiesByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.Activity, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var activities []*models.Activity
+	for cursor.Next(ctx) {
+		var activity models.Activity
+		if err := cursor.Decode(&activity); err != nil {
+			return nil, err
+		}
+		activities = append(activities, &activity)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return activities, nil
+}
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type attachmentRepo struct {
+	coll *mongo.Collection
+}
+
+func NewAttachmentRepository(db *mongo.Database) ports.AttachmentRepository {
+	return &attachmentRepo{coll: db.Collection(models.CollectionAttachments)}
+}
+
+func (r *attachmentRepo) CreateAttachment(ctx context.Context, attachment models.Attachment) error {
+	_, err := r.coll.InsertOne(ctx, attachment)
+	return err
+}
+
+func (r *attachmentRepo) SoftDeleteAttachmentByID(ctx context.Context, attachmentID, deletedBy string) error {
+	_, err := r.coll.UpdateOne(ctx, bson.M{"attachment_id": attachmentID}, bson.M{"$set": bson.M{"deleted_at": time.Now(), "deleted_by": deletedBy}})
+	return err
+}
+
+func (r *attachmentRepo) GetAllAttachmentsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.Attachment, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var attachments []*models.Attachment
+	for cursor.Next(ctx) {
+		var attachment models.Attachment
+		if err := cursor.Decode(&attachment); err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, &attachment)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+func (r *attachmentRepo) GetOneAttachmentByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.Attachment, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var attachment models.Attachment
+	if err := r.coll.FindOne(ctx, filter, opts).Decode(&attachment); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &attachment, nil
+}
@@ -0,0 +1,283 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type attendanceRepo struct {
+	collSites   *mongo.Collection
+	collShifts  *mongo.Collection
+	collRecords *mongo.Collection
+}
+
+func NewAttendanceRepository(db *mongo.Database) ports.AttendanceRepository {
+	return &attendanceRepo{
+		collSites:   db.Collection(models.CollectionAttendanceSites),
+		collShifts:  db.Collection(models.CollectionWorkShifts),
+		collRecords: db.Collection(models.CollectionAttendanceRecords),
+	}
+}
+
+func (r *attendanceRepo) CreateAttendanceSite(ctx context.Context, site models.AttendanceSite) error {
+	_, err := r.collSites.InsertOne(ctx, site)
+	return err
+}
+
+func (r *attendanceRepo) UpdateAttendanceSiteByID(ctx context.Context, siteID string, update models.AttendanceSite) (*models.AttendanceSite, error) {
+	filter := bson.M{"site_id": siteID, "deleted_at": nil}
+	set := bson.M{
+		"name":        update.Name,
+		"site_type":   update.SiteType,
+		"job_id":      update.JobID,
+		"latitude":    update.Latitude,
+		"longitude":   update.Longitude,
+		"radius_m":    update.RadiusM,
+		"departments": update.Departments,
+		"is_active":   update.IsActive,
+		"updated_at":  update.UpdatedAt,
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.AttendanceSite
+	if err := r.collSites.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *attendanceRepo) SoftDeleteAttendanceSiteByID(ctx context.Context, siteID string) error {
+	_, err := r.collSites.UpdateOne(ctx, bson.M{"site_id": siteID}, bson.M{"$set": bson.M{"deleted_at": time.Now()}})
+	return err
+}
+
+func (r *attendanceRepo) GetAllAttendanceSitesByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.AttendanceSite, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "site_type", Value: 1}, {Key: "name", Value: 1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.collSites.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var sites []*models.AttendanceSite
+	for cursor.Next(ctx) {
+		var site models.AttendanceSite
+		if err := cursor.Decode(&site); err != nil {
+			return nil, err
+		}
+		sites = append(sites, &site)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return sites, nil
+}
+
+func (r *attendanceRepo) GetOneAttendanceSiteByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.AttendanceSite, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var site models.AttendanceSite
+	if err := r.collSites.FindOne(ctx, filter, opts).Decode(&site); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &site, nil
+}
+
+func (r *attendanceRepo) CreateWorkShift(ctx context.Context, shift models.WorkShift) error {
+	_, err := r.collShifts.InsertOne(ctx, shift)
+	return err
+}
+
+func (r *attendanceRepo) UpdateWorkShiftByID(ctx context.Context, shiftID string, update models.WorkShift) (*models.WorkShift, error) {
+	filter := bson.M{"shift_id": shiftID, "deleted_at": nil}
+	set := bson.M{
+		"name":                update.Name,
+		"start_time":          update.StartTime,
+		"end_time":            update.EndTime,
+		"break_minutes":       update.BreakMinutes,
+		"late_grace_minutes":  update.LateGraceMinutes,
+		"early_grace_minutes": update.EarlyGraceMinutes,
+		"ot_min_minutes":      update.OTMinMinutes,
+		"work_days":           update.WorkDays,
+		"departments":         update.Departments,
+		"users":               update.Users,
+		"is_default":          update.IsDefault,
+		"updated_by":          update.UpdatedBy,
+		"updated_at":          update.UpdatedAt,
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.WorkShift
+	if err := r.collShifts.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *attendanceRepo) SoftDeleteWorkShiftByID(ctx context.Context, shiftID string) error {
+	_, err := r.collShifts.UpdateOne(ctx, bson.M{"shift_id": shiftID}, bson.M{"$set": bson.M{"deleted_at": time.Now()}})
+	return err
+}
+
+func (r *attendanceRepo) UnsetDefaultWorkShifts(ctx context.Context, exceptShiftID string) error {
+	filter := bson.M{"is_default": true, "shift_id": bson.M{"$ne": exceptShiftID}}
+	_, err := r.collShifts.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"is_default": false, "updated_at": time.Now()}})
+	return err
+}
+
+func (r *attendanceRepo) GetAllWorkShiftsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.WorkShift, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "start_time", Value: 1}, {Key: "name", Value: 1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.collShifts.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var shifts []*models.WorkShift
+	for cursor.Next(ctx) {
+		var shift models.WorkShift
+		if err := cursor.Decode(&shift); err != nil {
+			return nil, err
+		}
+		shifts = append(shifts, &shift)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return shifts, nil
+}
+
+func (r *attendanceRepo) GetOneWorkShiftByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.WorkShift, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var shift models.WorkShift
+	if err := r.collShifts.FindOne(ctx, filter, opts).Decode(&shift); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &shift, nil
+}
+
+// InsertAttendanceRecordIfAbsent ใช้ upsert + $setOnInsert กันกดลงเวลาเข้าซ้ำพร้อมกัน
+func (r *attendanceRepo) InsertAttendanceRecordIfAbsent(ctx context.Context, record models.AttendanceRecord) (bool, error) {
+	filter := bson.M{"user_id": record.UserID, "work_date": record.WorkDate, "deleted_at": nil}
+	res, err := r.collRecords.UpdateOne(ctx, filter, bson.M{"$setOnInsert": record}, options.Update().SetUpsert(true))
+	if err != nil {
+		return false, err
+	}
+	return res.UpsertedCount > 0, nil
+}
+
+func (r *attendanceRepo) UpdateAttendanceRecord(ctx context.Context, filter bson.M, update bson.M) (*models.AttendanceRecord, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.AttendanceRecord
+	if err := r.collRecords.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *attendanceRepo) GetAllAttendanceRecordsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.AttendanceRecord, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "work_date", Value: 1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.collRecords.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []*models.AttendanceRecord
+	for cursor.Next(ctx) {
+		var record models.AttendanceRecord
+		if err := cursor.Decode(&record); err != nil {
+			return nil, err
+		}
+		records = append(records, &record)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (r *attendanceRepo) GetOneAttendanceRecordByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.AttendanceRecord, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "work_date", Value: -1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var record models.AttendanceRecord
+	if err := r.collRecords.FindOne(ctx, filter, opts).Decode(&record); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r *attendanceRepo) GetListAttendanceRecordsByFilter(ctx context.Context, filter interface{}, projection interface{}, sort bson.D, skip, limit int64) ([]models.AttendanceRecord, int64, error) {
+
+	findOpts := options.Find().
+		SetSort(sort).
+		SetSkip(skip).
+		SetLimit(limit)
+
+	if projection != nil {
+		findOpts.SetProjection(projection)
+	}
+
+	cur, err := r.collRecords.Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("find: %w", err)
+	}
+	defer cur.Close(ctx)
+
+	var results []models.AttendanceRecord
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, 0, fmt.Errorf("decode: %w", err)
+	}
+
+	total, err := r.collRecords.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("count: %w", err)
+	}
+
+	return results, total, nil
+}
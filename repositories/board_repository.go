@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type boardRepo struct {
+	collWIPLimits *mongo.Collection
+}
+
+func NewBoardRepository(db *mongo.Database) ports.BoardRepository {
+	return &boardRepo{
+		collWIPLimits: db.Collection(models.CollectionBoardWIPLimits),
+	}
+}
+
+func (r *boardRepo) CreateWIPLimit(ctx context.Context, limit models.BoardWIPLimit) error {
+	_, err := r.collWIPLimits.InsertOne(ctx, limit)
+	return err
+}
+
+func (r *boardRepo) UpdateWIPLimitByID(ctx context.Context, limitID string, update models.BoardWIPLimit) (*models.BoardWIPLimit, error) {
+	filter := bson.M{"limit_id": limitID}
+	set := bson.M{
+		"limit":      update.Limit,
+		"updated_by": update.UpdatedBy,
+		"updated_at": update.UpdatedAt,
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.BoardWIPLimit
+	if err := r.collWIPLimits.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *boardRepo) SoftDeleteWIPLimitByID(ctx context.Context, limitID string) error {
+	_, err := r.collWIPLimits.UpdateOne(ctx, bson.M{"limit_id": limitID}, bson.M{"$set": bson.M{"deleted_at": time.Now()}})
+	return err
+}
+
+func (r *boardRepo) GetAllWIPLimitsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.BoardWIPLimit, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "column_key", Value: 1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.collWIPLimits.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var limits []*models.BoardWIPLimit
+	for cursor.Next(ctx) {
+		var limit models.BoardWIPLimit
+		if err := cursor.Decode(&limit); err != nil {
+			return nil, err
+		}
+		limits = append(limits, &limit)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return limits, nil
+}
+
+func (r *boardRepo) GetOneWIPLimitByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.BoardWIPLimit, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var limit models.BoardWIPLimit
+	if err := r.collWIPLimits.FindOne(ctx, filter, opts).Decode(&limit); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &limit, nil
+}
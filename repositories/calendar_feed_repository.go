@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type calendarFeedRepo struct {
+	coll *mongo.Collection
+}
+
+func NewCalendarFeedRepository(db *mongo.Database) ports.CalendarFeedRepository {
+	return &calendarFeedRepo{
+		coll: db.Collection(models.CollectionCalendarFeeds),
+	}
+}
+
+func (r *calendarFeedRepo) CreateCalendarFeed(ctx context.Context, feed models.CalendarFeed) error {
+	_, err := r.coll.InsertOne(ctx, feed)
+	return err
+}
+
+func (r *calendarFeedRepo) UpdateCalendarFeedByID(ctx context.Context, feedID string, update models.CalendarFeed) (*models.CalendarFeed, error) {
+	filter := bson.M{"feed_id": feedID}
+	set := bson.M{
+		"token_hash":     update.TokenHash,
+		"token_hint":     update.TokenHint,
+		"revoked_at":     update.RevokedAt,
+		"last_access_at": update.LastAccessAt,
+		"updated_at":     update.UpdatedAt,
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.CalendarFeed
+	if err := r.coll.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *calendarFeedRepo) GetAllCalendarFeedsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.CalendarFeed, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var feeds []*models.CalendarFeed
+	for cursor.Next(ctx) {
+		var feed models.CalendarFeed
+		if err := cursor.Decode(&feed); err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, &feed)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return feeds, nil
+}
+
+func (r *calendarFeedRepo) GetOneCalendarFeedByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.CalendarFeed, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var feed models.CalendarFeed
+	if err := r.coll.FindOne(ctx, filter, opts).Decode(&feed); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &feed, nil
+}
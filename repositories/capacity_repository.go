@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type capacityRepo struct {
+	collCapacities *mongo.Collection
+	collHolidays   *mongo.Collection
+}
+
+func NewCapacityRepository(db *mongo.Database) ports.CapacityRepository {
+	return &capacityRepo{
+		collCapacities: db.Collection(models.CollectionDepartmentCapacities),
+		collHolidays:   db.Collection(models.CollectionHolidays),
+	}
+}
+
+func (r *capacityRepo) CreateDepartmentCapacity(ctx context.Context, capacity models.DepartmentCapacity) error {
+	_, err := r.collCapacities.InsertOne(ctx, capacity)
+	return err
+}
+
+func (r *capacityRepo) UpdateDepartmentCapacityByID(ctx context.Context, capacityID string, update models.DepartmentCapacity) (*models.DepartmentCapacity, error) {
+	filter := bson.M{"capacity_id": capacityID}
+	set := bson.M{
+		"work_days":     update.WorkDays,
+		"hours_per_day": update.HoursPerDay,
+		"efficiency":    update.Efficiency,
+		"updated_by":    update.UpdatedBy,
+		"updated_at":    time.Now(),
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.DepartmentCapacity
+	if err := r.collCapacities.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *capacityRepo) GetAllDepartmentCapacitiesByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.DepartmentCapacity, error) {
+	opts := options.Find()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.collCapacities.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var capacities []*models.DepartmentCapacity
+	for cursor.Next(ctx) {
+		var capacity models.DepartmentCapacity
+		if err := cursor.Decode(&capacity); err != nil {
+			return nil, err
+		}
+		capacities = append(capacities, &capacity)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return capacities, nil
+}
+
+func (r *capacityRepo) GetOneDepartmentCapacityByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.DepartmentCapacity, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var capacity models.DepartmentCapacity
+	if err := r.collCapacities.FindOne(ctx, filter, opts).Decode(&capacity); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &capacity, nil
+}
+
+func (r *capacityRepo) CreateHoliday(ctx context.Context, holiday models.Holiday) error {
+	_, err := r.collHolidays.InsertOne(ctx, holiday)
+	return err
+}
+
+func (r *capacityRepo) SoftDeleteHolidayByID(ctx context.Context, holidayID string) error {
+	_, err := r.collHolidays.UpdateOne(ctx, bson.M{"holiday_id": holidayID}, bson.M{"$set": bson.M{"deleted_at": time.Now()}})
+	return err
+}
+
+func (r *capacityRepo) GetAllHolidaysByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.Holiday, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "date", Value: 1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.collHolidays.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var holidays []*models.Holiday
+	for cursor.Next(ctx) {
+		var holiday models.Holiday
+		if err := cursor.Decode(&holiday); err != nil {
+			return nil, err
+		}
+		holidays = append(holidays, &holiday)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return holidays, nil
+}
+
+func (r *capacityRepo) GetOneHolidayByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.Holiday, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var holiday models.Holiday
+	if err := r.collHolidays.FindOne(ctx, filter, opts).Decode(&holiday); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &holiday, nil
+}
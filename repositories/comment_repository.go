@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type commentRepo struct {
+	coll *mongo.Collection
+}
+
+func NewCommentRepository(db *mongo.Database) ports.CommentRepository {
+	return &commentRepo{
+		coll: db.Collection(models.CollectionComments),
+	}
+}
+
+func (r *commentRepo) CreateComment(ctx context.Context, comment models.Comment) error {
+	_, err := r.coll.InsertOne(ctx, comment)
+	return err
+}
+
+func (r *commentRepo) UpdateCommentByID(ctx context.Context, commentID string, update models.Comment) (*models.Comment, error) {
+	filter := bson.M{"comment_id": commentID}
+	set := bson.M{
+		"body":           update.Body,
+		"mentions":       update.Mentions,
+		"attachment_ids": update.AttachmentIDs,
+		"edits":          update.Edits,
+		"deleted_at":     update.DeletedAt,
+		"deleted_by":     update.DeletedBy,
+		"updated_at":     update.UpdatedAt,
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.Comment
+	if err := r.coll.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *commentRepo) GetAllCommentsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.Comment, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var comments []*models.Comment
+	for cursor.Next(ctx) {
+		var comment models.Comment
+		if err := cursor.Decode(&comment); err != nil {
+			return nil, err
+		}
+		comments = append(comments, &comment)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+func (r *commentRepo) GetOneCommentByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.Comment, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var comment models.Comment
+	if err := r.coll.FindOne(ctx, filter, opts).Decode(&comment); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type employeeChecklistRepo struct {
+	collTemplates  *mongo.Collection
+	collChecklists *mongo.Collection
+}
+
+func NewEmployeeChecklistRepository(db *mongo.Database) ports.EmployeeChecklistRepository {
+	return &employeeChecklistRepo{
+		collTemplates:  db.Collection(models.CollectionChecklistTemplates),
+		collChecklists: db.Collection(models.CollectionEmployeeChecklists),
+	}
+}
+
+func (r *employeeChecklistRepo) CreateChecklistTemplate(ctx context.Context, template models.ChecklistTemplate) error {
+	_, err := r.collTemplates.InsertOne(ctx, template)
+	return err
+}
+
+func (r *employeeChecklistRepo) GetAllChecklistTemplatesByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.ChecklistTemplate, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "kind", Value: 1}, {Key: "position_id", Value: 1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.collTemplates.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var templates []*models.ChecklistTemplate
+	for cursor.Next(ctx) {
+		var template models.ChecklistTemplate
+		if err := cursor.Decode(&template); err != nil {
+			return nil, err
+		}
+		templates = append(templates, &template)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+func (r *employeeChecklistRepo) GetOneChecklistTemplateByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.ChecklistTemplate, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var template models.ChecklistTemplate
+	if err := r.collTemplates.FindOne(ctx, filter, opts).Decode(&template); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *employeeChecklistRepo) UpdateChecklistTemplate(ctx context.Context, filter interface{}, update interface{}) (*models.ChecklistTemplate, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.ChecklistTemplate
+	if err := r.collTemplates.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *employeeChecklistRepo) CreateEmployeeChecklist(ctx context.Context, checklist models.EmployeeChecklist) error {
+	_, err := r.collChecklists.InsertOne(ctx, checklist)
+	return err
+}
+
+func (r *employeeChecklistRepo) GetAllEmployeeChecklistsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.EmployeeChecklist, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.collChecklists.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var checklists []*models.EmployeeChecklist
+	for cursor.Next(ctx) {
+		var checklist models.EmployeeChecklist
+		if err := cursor.Decode(&checklist); err != nil {
+			return nil, err
+		}
+		checklists = append(checklists, &checklist)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return checklists, nil
+}
+
+func (r *employeeChecklistRepo) GetOneEmployeeChecklistByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.EmployeeChecklist, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var checklist models.EmployeeChecklist
+	if err := r.collChecklists.FindOne(ctx, filter, opts).Decode(&checklist); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &checklist, nil
+}
+
+func (r *employeeChecklistRepo) GetListEmployeeChecklistsByFilter(ctx context.Context, filter interface{}, projection interface{}, sort bson.D, skip, limit int64) ([]models.EmployeeChecklist, int64, error) {
+
+	findOpts := options.Find().
+		SetSort(sort).
+		SetSkip(skip).
+		SetLimit(limit)
+
+	if projection != nil {
+		findOpts.SetProjection(projection)
+	}
+
+	cur, err := r.collChecklists.Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("find: %w", err)
+	}
+	defer cur.Close(ctx)
+
+	var results []models.EmployeeChecklist
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, 0, fmt.Errorf("decode: %w", err)
+	}
+
+	total, err := r.collChecklists.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("count: %w", err)
+	}
+
+	return results, total, nil
+}
+
+func (r *employeeChecklistRepo) UpdateEmployeeChecklist(ctx context.Context, filter interface{}, update interface{}) (*models.EmployeeChecklist, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.EmployeeChecklist
+	if err := r.collChecklists.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type employmentHistoryRepo struct {
+	coll *mongo.Collection
+}
+
+func NewEmploymentHistoryRepository(db *mongo.Database) ports.EmploymentHistoryRepository {
+	return &employmentHistoryRepo{coll: db.Collection(models.CollectionEmploymentHistories)}
+}
+
+func (r *employmentHistoryRepo) CreateEmploymentHistory(ctx context.Context, history models.EmploymentHistory) error {
+	_, err := r.coll.InsertOne(ctx, history)
+	return err
+}
+
+func (r *employmentHistoryRepo) GetAllEmploymentHistoriesByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.EmploymentHistory, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "user_id", Value: 1}, {Key: "from_date", Value: 1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var histories []*models.EmploymentHistory
+	for cursor.Next(ctx) {
+		var history models.EmploymentHistory
+		if err := cursor.Decode(&history); err != nil {
+			return nil, err
+		}
+		histories = append(histories, &history)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return histories, nil
+}
+
+func (r *employmentHistoryRepo) GetOneEmploymentHistoryByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.EmploymentHistory, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var history models.EmploymentHistory
+	if err := r.coll.FindOne(ctx, filter, opts).Decode(&history); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &history, nil
+}
+
+func (r *employmentHistoryRepo) UpdateEmploymentHistory(ctx context.Context, filter interface{}, update interface{}) (*models.EmploymentHistory, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.EmploymentHistory
+	if err := r.coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
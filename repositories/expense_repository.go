@@ -36,6 +36,7 @@ func (r *expenseRepo) UpdateExpenseByID(ctx context.Context, expenseID string, u
 		"txn_date":                update.TxnDate,
 		"payment_method":          update.PaymentMethod,
 		"reference_no":            update.ReferenceNo,
+		"job_id":                  update.JobID,
 		"note":                    update.Note,
 		"updated_at":              time.Now(),
 	}
@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type jobCostRepo struct {
+	collMaterials  *mongo.Collection
+	collLaborRates *mongo.Collection
+}
+
+func NewJobCostRepository(db *mongo.Database) ports.JobCostRepository {
+	return &jobCostRepo{
+		collMaterials:  db.Collection(models.CollectionJobMaterials),
+		collLaborRates: db.Collection(models.CollectionLaborRates),
+	}
+}
+
+func (r *jobCostRepo) CreateJobMaterial(ctx context.Context, material models.JobMaterial) error {
+	_, err := r.collMaterials.InsertOne(ctx, material)
+	return err
+}
+
+func (r *jobCostRepo) SoftDeleteJobMaterialByID(ctx context.Context, materialID string) error {
+	_, err := r.collMaterials.UpdateOne(ctx, bson.M{"material_id": materialID}, bson.M{"$set": bson.M{"deleted_at": time.Now()}})
+	return err
+}
+
+func (r *jobCostRepo) GetAllJobMaterialsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.JobMaterial, error) {
+	opts := options.Find()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.collMaterials.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var materials []*models.JobMaterial
+	for cursor.Next(ctx) {
+		var material models.JobMaterial
+		if err := cursor.Decode(&material); err != nil {
+			return nil, err
+		}
+		materials = append(materials, &material)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return materials, nil
+}
+
+func (r *jobCostRepo) GetOneJobMaterialByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.JobMaterial, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var material models.JobMaterial
+	if err := r.collMaterials.FindOne(ctx, filter, opts).Decode(&material); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &material, nil
+}
+
+func (r *jobCostRepo) CreateLaborRate(ctx context.Context, rate models.LaborRate) error {
+	_, err := r.collLaborRates.InsertOne(ctx, rate)
+	return err
+}
+
+func (r *jobCostRepo) UpdateLaborRateByID(ctx context.Context, rateID string, update models.LaborRate) (*models.LaborRate, error) {
+	filter := bson.M{"rate_id": rateID}
+	set := bson.M{
+		"hourly_rate": update.HourlyRate,
+		"updated_at":  time.Now(),
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.LaborRate
+	if err := r.collLaborRates.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *jobCostRepo) SoftDeleteLaborRateByID(ctx context.Context, rateID string) error {
+	_, err := r.collLaborRates.UpdateOne(ctx, bson.M{"rate_id": rateID}, bson.M{"$set": bson.M{"deleted_at": time.Now()}})
+	return err
+}
+
+func (r *jobCostRepo) GetAllLaborRatesByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.LaborRate, error) {
+	opts := options.Find()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.collLaborRates.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rates []*models.LaborRate
+	for cursor.Next(ctx) {
+		var rate models.LaborRate
+		if err := cursor.Decode(&rate); err != nil {
+			return nil, err
+		}
+		rates = append(rates, &rate)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+func (r *jobCostRepo) GetOneLaborRateByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.LaborRate, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var rate models.LaborRate
+	if err := r.collLaborRates.FindOne(ctx, filter, opts).Decode(&rate); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rate, nil
+}
@@ -29,21 +29,41 @@ func (r *kpiEvaluationRepo) CreateKPIEvaluations(ctx context.Context, kpi models
 
 func (r *kpiEvaluationRepo) UpdateKPIEvaluationByID(ctx context.Context, evaluationID string, update models.KPIEvaluation) (*models.KPIEvaluation, error) {
 	filter := bson.M{"evaluation_id": evaluationID, "deleted_at": nil}
+	return r.updateKPIEvaluation(ctx, filter, update)
+}
+
+// UpdateKPIEvaluationIfUnchanged ใช้ updated_at ที่อ่านมาเป็นเงื่อนไข กันคะแนน/ประวัติของอีกคนถูกเขียนทับ
+func (r *kpiEvaluationRepo) UpdateKPIEvaluationIfUnchanged(ctx context.Context, evaluationID string, lastUpdatedAt time.Time, update models.KPIEvaluation) (*models.KPIEvaluation, error) {
+	filter := bson.M{"evaluation_id": evaluationID, "deleted_at": nil, "updated_at": lastUpdatedAt}
+	return r.updateKPIEvaluation(ctx, filter, update)
+}
 
+func (r *kpiEvaluationRepo) updateKPIEvaluation(ctx context.Context, filter bson.M, update models.KPIEvaluation) (*models.KPIEvaluation, error) {
 	set := bson.M{
-		"project_id":    update.ProjectID,
-		"job_id":        update.JobID,
-		"task_id":       update.TaskID,
-		"kpi_id":        update.KPIID,
-		"version":       update.Version,
-		"evaluator_id":  update.EvaluatorID,
-		"evaluatee_id":  update.EvaluateeID,
-		"department_id": update.Department,
-		"scores":        update.Scores,
-		"total_score":   update.TotalScore,
-		"feedback":      update.Feedback,
-		"is_evaluated":  update.IsEvaluated,
-		"updated_at":    update.UpdatedAt,
+		"project_id":     update.ProjectID,
+		"job_id":         update.JobID,
+		"task_id":        update.TaskID,
+		"kpi_id":         update.KPIID,
+		"version":        update.Version,
+		"evaluator_id":   update.EvaluatorID,
+		"evaluatee_id":   update.EvaluateeID,
+		"department_id":  update.Department,
+		"scores":         update.Scores,
+		"total_score":    update.TotalScore,
+		"percent":        update.Percent,
+		"grade":          update.Grade,
+		"scoring_method": update.ScoringMethod,
+		"feedback":       update.Feedback,
+		"is_evaluated":   update.IsEvaluated,
+		"self_review":    update.SelfReview,
+		"peer_ids":       update.PeerIDs,
+		"peer_reviews":   update.PeerReviews,
+		"revisions":      update.Revisions,
+		"ack_status":     update.AckStatus,
+		"ack_comment":    update.AckComment,
+		"ack_at":         update.AckAt,
+		"disputes":       update.Disputes,
+		"updated_at":     update.UpdatedAt,
 	}
 
 	if update.TaskID == "" {
@@ -137,3 +157,22 @@ func (r *kpiEvaluationRepo) GetListKPIEvaluationByFilter(ctx context.Context, fi
 
 	return results, total, nil
 }
+
+// UpdateManyKPIEvaluationFields ไม่แตะ updated_at ให้อัตโนมัติ (updated_at ใช้เป็นวันที่ประเมินเสร็จ) ผู้เรียกใส่เองถ้าต้องการ
+func (r *kpiEvaluationRepo) UpdateManyKPIEvaluationFields(ctx context.Context, filter interface{}, set bson.M, inc bson.M) (int64, error) {
+	update := bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(inc) > 0 {
+		update["$inc"] = inc
+	}
+	if len(update) == 0 {
+		return 0, nil
+	}
+	result, err := r.coll.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
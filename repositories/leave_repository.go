@@ -0,0 +1,192 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type leaveRepo struct {
+	collPolicies *mongo.Collection
+	collRequests *mongo.Collection
+}
+
+func NewLeaveRepository(db *mongo.Database) ports.LeaveRepository {
+	return &leaveRepo{
+		collPolicies: db.Collection(models.CollectionLeavePolicies),
+		collRequests: db.Collection(models.CollectionLeaveRequests),
+	}
+}
+
+func (r *leaveRepo) CreateLeavePolicy(ctx context.Context, policy models.LeavePolicy) error {
+	_, err := r.collPolicies.InsertOne(ctx, policy)
+	return err
+}
+
+func (r *leaveRepo) UpdateLeavePolicyByID(ctx context.Context, policyID string, update models.LeavePolicy) (*models.LeavePolicy, error) {
+	filter := bson.M{"policy_id": policyID, "deleted_at": nil}
+	set := bson.M{
+		"tiers":           update.Tiers,
+		"paid_days":       update.PaidDays,
+		"max_carry_over":  update.MaxCarryOver,
+		"allow_exceed":    update.AllowExceed,
+		"allow_half_day":  update.AllowHalfDay,
+		"calendar_days":   update.CalendarDays,
+		"min_notice_days": update.MinNoticeDays,
+		"updated_by":      update.UpdatedBy,
+		"updated_at":      update.UpdatedAt,
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.LeavePolicy
+	if err := r.collPolicies.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *leaveRepo) SoftDeleteLeavePolicyByID(ctx context.Context, policyID string) error {
+	_, err := r.collPolicies.UpdateOne(ctx, bson.M{"policy_id": policyID}, bson.M{"$set": bson.M{"deleted_at": time.Now()}})
+	return err
+}
+
+func (r *leaveRepo) GetAllLeavePoliciesByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.LeavePolicy, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "leave_type", Value: 1}, {Key: "position_id", Value: 1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.collPolicies.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var policies []*models.LeavePolicy
+	for cursor.Next(ctx) {
+		var policy models.LeavePolicy
+		if err := cursor.Decode(&policy); err != nil {
+			return nil, err
+		}
+		policies = append(policies, &policy)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+func (r *leaveRepo) GetOneLeavePolicyByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.LeavePolicy, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var policy models.LeavePolicy
+	if err := r.collPolicies.FindOne(ctx, filter, opts).Decode(&policy); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *leaveRepo) CreateLeaveRequest(ctx context.Context, request models.LeaveRequest) error {
+	_, err := r.collRequests.InsertOne(ctx, request)
+	return err
+}
+
+func (r *leaveRepo) GetAllLeaveRequestsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.LeaveRequest, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "start_date", Value: 1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.collRequests.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var requests []*models.LeaveRequest
+	for cursor.Next(ctx) {
+		var request models.LeaveRequest
+		if err := cursor.Decode(&request); err != nil {
+			return nil, err
+		}
+		requests = append(requests, &request)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+func (r *leaveRepo) GetOneLeaveRequestByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.LeaveRequest, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var request models.LeaveRequest
+	if err := r.collRequests.FindOne(ctx, filter, opts).Decode(&request); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *leaveRepo) GetListLeaveRequestsByFilter(ctx context.Context, filter interface{}, projection interface{}, sort bson.D, skip, limit int64) ([]models.LeaveRequest, int64, error) {
+
+	findOpts := options.Find().
+		SetSort(sort).
+		SetSkip(skip).
+		SetLimit(limit)
+
+	if projection != nil {
+		findOpts.SetProjection(projection)
+	}
+
+	cur, err := r.collRequests.Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("find: %w", err)
+	}
+	defer cur.Close(ctx)
+
+	var results []models.LeaveRequest
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, 0, fmt.Errorf("decode: %w", err)
+	}
+
+	total, err := r.collRequests.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("count: %w", err)
+	}
+
+	return results, total, nil
+}
+
+// TransitionLeaveRequest เปลี่ยนสถานะด้วยเงื่อนไขสถานะเดิม กันอนุมัติ/ยกเลิกซ้อนกัน
+func (r *leaveRepo) TransitionLeaveRequest(ctx context.Context, requestID string, from []string, set bson.M) (*models.LeaveRequest, error) {
+	filter := bson.M{"request_id": requestID, "status": bson.M{"$in": from}, "deleted_at": nil}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.LeaveRequest
+	if err := r.collRequests.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
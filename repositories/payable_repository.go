@@ -42,6 +42,7 @@ func (r *payableRepo) UpdatePayableByID(ctx context.Context, payableID string, u
 		"status":      update.Status,
 		"payment_ref": update.PaymentRef,
 		"note":        update.Note,
+		"job_id":      update.JobID,
 		"updated_at":  time.Now(),
 	}
 	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
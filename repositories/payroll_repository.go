@@ -0,0 +1,243 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type payrollRepo struct {
+	collStructures *mongo.Collection
+	collRuns       *mongo.Collection
+	collPayslips   *mongo.Collection
+}
+
+func NewPayrollRepository(db *mongo.Database) ports.PayrollRepository {
+	return &payrollRepo{
+		collStructures: db.Collection(models.CollectionSalaryStructures),
+		collRuns:       db.Collection(models.CollectionPayrollRuns),
+		collPayslips:   db.Collection(models.CollectionPayslips),
+	}
+}
+
+func (r *payrollRepo) UpsertSalaryStructure(ctx context.Context, structure models.SalaryStructure) (*models.SalaryStructure, error) {
+	filter := bson.M{"user_id": structure.UserID, "deleted_at": nil}
+	set := bson.M{
+		"pay_basis":          structure.PayBasis,
+		"base_salary":        structure.BaseSalary,
+		"daily_rate":         structure.DailyRate,
+		"hours_per_day":      structure.HoursPerDay,
+		"ot_multiplier":      structure.OTMultiplier,
+		"holiday_multiplier": structure.HolidayMultiplier,
+		"allowances":         structure.Allowances,
+		"deductions":         structure.Deductions,
+		"ssf_enabled":        structure.SSFEnabled,
+		"tax_deductions":     structure.TaxDeductions,
+		"updated_by":         structure.UpdatedBy,
+		"updated_at":         structure.UpdatedAt,
+	}
+	update := bson.M{
+		"$set":         set,
+		"$setOnInsert": bson.M{"structure_id": structure.StructureID, "created_at": structure.CreatedAt},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var updated models.SalaryStructure
+	if err := r.collStructures.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *payrollRepo) GetAllSalaryStructuresByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.SalaryStructure, error) {
+	opts := options.Find()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.collStructures.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var structures []*models.SalaryStructure
+	for cursor.Next(ctx) {
+		var structure models.SalaryStructure
+		if err := cursor.Decode(&structure); err != nil {
+			return nil, err
+		}
+		structures = append(structures, &structure)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return structures, nil
+}
+
+func (r *payrollRepo) GetOneSalaryStructureByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.SalaryStructure, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var structure models.SalaryStructure
+	if err := r.collStructures.FindOne(ctx, filter, opts).Decode(&structure); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &structure, nil
+}
+
+func (r *payrollRepo) CreatePayrollRun(ctx context.Context, run models.PayrollRun) error {
+	_, err := r.collRuns.InsertOne(ctx, run)
+	return err
+}
+
+func (r *payrollRepo) GetOnePayrollRunByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.PayrollRun, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var run models.PayrollRun
+	if err := r.collRuns.FindOne(ctx, filter, opts).Decode(&run); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (r *payrollRepo) GetAllPayrollRunsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.PayrollRun, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "month", Value: 1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.collRuns.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var runs []*models.PayrollRun
+	for cursor.Next(ctx) {
+		var run models.PayrollRun
+		if err := cursor.Decode(&run); err != nil {
+			return nil, err
+		}
+		runs = append(runs, &run)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+func (r *payrollRepo) GetListPayrollRunsByFilter(ctx context.Context, filter interface{}, projection interface{}, sort bson.D, skip, limit int64) ([]models.PayrollRun, int64, error) {
+
+	findOpts := options.Find().
+		SetSort(sort).
+		SetSkip(skip).
+		SetLimit(limit)
+
+	if projection != nil {
+		findOpts.SetProjection(projection)
+	}
+
+	cur, err := r.collRuns.Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("find: %w", err)
+	}
+	defer cur.Close(ctx)
+
+	var results []models.PayrollRun
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, 0, fmt.Errorf("decode: %w", err)
+	}
+
+	total, err := r.collRuns.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("count: %w", err)
+	}
+
+	return results, total, nil
+}
+
+func (r *payrollRepo) TransitionPayrollRun(ctx context.Context, runID string, from []string, set bson.M) (*models.PayrollRun, error) {
+	filter := bson.M{"run_id": runID, "status": bson.M{"$in": from}, "deleted_at": nil}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.PayrollRun
+	if err := r.collRuns.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *payrollRepo) ReplacePayslips(ctx context.Context, runID string, payslips []models.Payslip) error {
+	if _, err := r.collPayslips.DeleteMany(ctx, bson.M{"run_id": runID}); err != nil {
+		return err
+	}
+	if len(payslips) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, 0, len(payslips))
+	for _, p := range payslips {
+		docs = append(docs, p)
+	}
+	_, err := r.collPayslips.InsertMany(ctx, docs)
+	return err
+}
+
+func (r *payrollRepo) GetAllPayslipsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.Payslip, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "month", Value: 1}, {Key: "employee_code", Value: 1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.collPayslips.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var payslips []*models.Payslip
+	for cursor.Next(ctx) {
+		var payslip models.Payslip
+		if err := cursor.Decode(&payslip); err != nil {
+			return nil, err
+		}
+		payslips = append(payslips, &payslip)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return payslips, nil
+}
+
+func (r *payrollRepo) GetOnePayslipByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.Payslip, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var payslip models.Payslip
+	if err := r.collPayslips.FindOne(ctx, filter, opts).Decode(&payslip); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &payslip, nil
+}
@@ -0,0 +1,184 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type recurringTaskRepo struct {
+	coll *mongo.Collection
+}
+
+func NewRecurringTaskRepository(db *mongo.Database) ports.RecurringTaskRepository {
+	return &recurringTaskRepo{
+		coll: db.Collection(models.CollectionRecurringTasks),
+	}
+}
+
+func (r *recurringTaskRepo) CreateRecurringTask(ctx context.Context, def models.RecurringTask) error {
+	_, err := r.coll.InsertOne(ctx, def)
+	return err
+}
+
+func (r *recurringTaskRepo) UpdateRecurringTaskByID(ctx context.Context, recurringID string, update models.RecurringTask) (*models.RecurringTask, error) {
+	filter := bson.M{"recurring_id": recurringID}
+	set := bson.M{
+		"name":              update.Name,
+		"description":       update.Description,
+		"workflow_id":       update.WorkFlowID,
+		"project_id":        update.ProjectID,
+		"project_name":      update.ProjectName,
+		"department_id":     update.Department,
+		"importance":        update.Importance,
+		"kpi_id":            update.KPIID,
+		"schedule":          update.Schedule,
+		"start_at":          update.StartAt,
+		"duration_days":     update.DurationDays,
+		"assignees":         update.Assignees,
+		"next_assignee_idx": update.NextAssigneeIdx,
+		"is_active":         update.IsActive,
+		"next_run_at":       update.NextRunAt,
+		"updated_at":        update.UpdatedAt,
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.RecurringTask
+	if err := r.coll.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *recurringTaskRepo) SoftDeleteRecurringTaskByID(ctx context.Context, recurringID string) error {
+	_, err := r.coll.UpdateOne(ctx, bson.M{"recurring_id": recurringID}, bson.M{"$set": bson.M{"deleted_at": time.Now()}})
+	return err
+}
+
+func (r *recurringTaskRepo) GetAllRecurringTasksByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.RecurringTask, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "next_run_at", Value: 1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var defs []*models.RecurringTask
+	for cursor.Next(ctx) {
+		var def models.RecurringTask
+		if err := cursor.Decode(&def); err != nil {
+			return nil, err
+		}
+		defs = append(defs, &def)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return defs, nil
+}
+
+func (r *recurringTaskRepo) GetOneRecurringTaskByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.RecurringTask, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var def models.RecurringTask
+	if err := r.coll.FindOne(ctx, filter, opts).Decode(&def); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &def, nil
+}
+
+func (r *recurringTaskRepo) GetListRecurringTasksByFilter(ctx context.Context, filter interface{}, projection interface{}, sort bson.D, skip, limit int64) ([]models.RecurringTask, int64, error) {
+
+	findOpts := options.Find().
+		SetSort(sort).
+		SetSkip(skip).
+		SetLimit(limit)
+
+	if projection != nil {
+		findOpts.SetProjection(projection)
+	}
+
+	cur, err := r.coll.Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("find: %w", err)
+	}
+	defer cur.Close(ctx)
+
+	var results []models.RecurringTask
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, 0, fmt.Errorf("decode: %w", err)
+	}
+
+	total, err := r.coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("count: %w", err)
+	}
+
+	return results, total, nil
+}
+
+// ClaimRecurringRun จองรอบด้วยเงื่อนไข next_run_at เดิม กันสร้างงานซ้ำเมื่อรันหลาย instance
+func (r *recurringTaskRepo) ClaimRecurringRun(ctx context.Context, recurringID string, runAt time.Time, nextRunAt *time.Time, now time.Time) (*models.RecurringTask, error) {
+	filter := bson.M{
+		"recurring_id": recurringID,
+		"next_run_at":  runAt,
+		"is_active":    true,
+		"deleted_at":   nil,
+	}
+	set := bson.M{
+		"next_run_at": nextRunAt,
+		"last_run_at": now,
+		"updated_at":  now,
+	}
+	if nextRunAt == nil {
+		// ครบรอบแล้ว ปิดงานประจำ
+		set["is_active"] = false
+	}
+	update := bson.M{"$set": set, "$inc": bson.M{"run_count": 1}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+	var before models.RecurringTask
+	if err := r.coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&before); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &before, nil
+}
+
+// IncrementAssigneeIndex เลื่อนคิว round-robin แบบ atomic และคืนค่าตำแหน่งก่อนเลื่อน
+func (r *recurringTaskRepo) IncrementAssigneeIndex(ctx context.Context, recurringID string) (int, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+	var before models.RecurringTask
+	if err := r.coll.FindOneAndUpdate(ctx, bson.M{"recurring_id": recurringID}, bson.M{"$inc": bson.M{"next_assignee_idx": 1}}, opts).Decode(&before); err != nil {
+		return 0, err
+	}
+	return before.NextAssigneeIdx, nil
+}
+
+func (r *recurringTaskRepo) SetRecurringRunResult(ctx context.Context, recurringID, taskID, lastError string, now time.Time) error {
+	set := bson.M{"last_error": lastError, "updated_at": now}
+	if taskID != "" {
+		set["last_task_id"] = taskID
+	}
+	_, err := r.coll.UpdateOne(ctx, bson.M{"recurring_id": recurringID}, bson.M{"$set": set})
+	return err
+}
@@ -0,0 +1,284 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type reviewCycleRepo struct {
+	collCycles  *mongo.Collection
+	collReviews *mongo.Collection
+}
+
+func NewReviewCycleRepository(db *mongo.Database) ports.ReviewCycleRepository {
+	return &reviewCycleRepo{
+		collCycles:  db.Collection(models.CollectionReviewCycles),
+		collReviews: db.Collection(models.CollectionPerformanceReviews),
+	}
+}
+
+func (r *reviewCycleRepo) CreateReviewCycle(ctx context.Context, cycle models.ReviewCycle) error {
+	_, err := r.collCycles.InsertOne(ctx, cycle)
+	return err
+}
+
+func (r *reviewCycleRepo) UpdateReviewCycleByID(ctx context.Context, cycleID string, update models.ReviewCycle) (*models.ReviewCycle, error) {
+	filter := bson.M{"cycle_id": cycleID, "deleted_at": nil}
+	set := bson.M{
+		"name":          update.Name,
+		"cycle_type":    update.CycleType,
+		"period_start":  update.PeriodStart,
+		"period_end":    update.PeriodEnd,
+		"open_at":       update.OpenAt,
+		"close_at":      update.CloseAt,
+		"departments":   update.Departments,
+		"items":         update.Items,
+		"task_weight":   update.TaskWeight,
+		"bands":         update.Bands,
+		"reminder_days": update.ReminderDays,
+		"updated_at":    update.UpdatedAt,
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.ReviewCycle
+	if err := r.collCycles.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *reviewCycleRepo) SoftDeleteReviewCycleByID(ctx context.Context, cycleID string) error {
+	_, err := r.collCycles.UpdateOne(ctx, bson.M{"cycle_id": cycleID}, bson.M{"$set": bson.M{"deleted_at": time.Now()}})
+	return err
+}
+
+func (r *reviewCycleRepo) GetAllReviewCyclesByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.ReviewCycle, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "open_at", Value: 1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.collCycles.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var cycles []*models.ReviewCycle
+	for cursor.Next(ctx) {
+		var cycle models.ReviewCycle
+		if err := cursor.Decode(&cycle); err != nil {
+			return nil, err
+		}
+		cycles = append(cycles, &cycle)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return cycles, nil
+}
+
+func (r *reviewCycleRepo) GetOneReviewCycleByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.ReviewCycle, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var cycle models.ReviewCycle
+	if err := r.collCycles.FindOne(ctx, filter, opts).Decode(&cycle); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cycle, nil
+}
+
+func (r *reviewCycleRepo) GetListReviewCyclesByFilter(ctx context.Context, filter interface{}, projection interface{}, sort bson.D, skip, limit int64) ([]models.ReviewCycle, int64, error) {
+
+	findOpts := options.Find().
+		SetSort(sort).
+		SetSkip(skip).
+		SetLimit(limit)
+
+	if projection != nil {
+		findOpts.SetProjection(projection)
+	}
+
+	cur, err := r.collCycles.Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("find: %w", err)
+	}
+	defer cur.Close(ctx)
+
+	var results []models.ReviewCycle
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, 0, fmt.Errorf("decode: %w", err)
+	}
+
+	total, err := r.collCycles.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("count: %w", err)
+	}
+
+	return results, total, nil
+}
+
+// TransitionReviewCycle เปลี่ยนสถานะด้วยเงื่อนไขสถานะเดิม กันเปิด/ปิดซ้ำเมื่อรันหลาย instance
+func (r *reviewCycleRepo) TransitionReviewCycle(ctx context.Context, cycleID, from, to string, now time.Time) (*models.ReviewCycle, error) {
+	filter := bson.M{"cycle_id": cycleID, "status": from, "deleted_at": nil}
+	set := bson.M{"status": to, "updated_at": now}
+	switch to {
+	case models.ReviewCycleOpen:
+		set["opened_at"] = now
+	case models.ReviewCycleClosed:
+		set["closed_at"] = now
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.ReviewCycle
+	if err := r.collCycles.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *reviewCycleRepo) SetReviewCycleParticipants(ctx context.Context, cycleID string, participants int, now time.Time) error {
+	_, err := r.collCycles.UpdateOne(ctx, bson.M{"cycle_id": cycleID}, bson.M{"$set": bson.M{"participants": participants, "updated_at": now}})
+	return err
+}
+
+func (r *reviewCycleRepo) SetReviewCycleReminded(ctx context.Context, cycleID string, now time.Time) error {
+	_, err := r.collCycles.UpdateOne(ctx, bson.M{"cycle_id": cycleID}, bson.M{"$set": bson.M{"reminded_at": now}})
+	return err
+}
+
+// UpsertPerformanceReview ใช้ $setOnInsert กันสร้างแบบประเมินซ้ำของคนเดิมในรอบเดียวกัน
+func (r *reviewCycleRepo) UpsertPerformanceReview(ctx context.Context, review models.PerformanceReview) (bool, error) {
+	filter := bson.M{"cycle_id": review.CycleID, "user_id": review.UserID}
+	res, err := r.collReviews.UpdateOne(ctx, filter, bson.M{"$setOnInsert": review}, options.Update().SetUpsert(true))
+	if err != nil {
+		return false, err
+	}
+	return res.UpsertedCount > 0, nil
+}
+
+func (r *reviewCycleRepo) UpdatePerformanceReviewByID(ctx context.Context, reviewID string, update models.PerformanceReview) (*models.PerformanceReview, error) {
+	filter := bson.M{"review_id": reviewID, "finalized_at": nil}
+	set := bson.M{
+		"status":               update.Status,
+		"task_kpi":             update.TaskKPI,
+		"self_scores":          update.SelfScores,
+		"self_comment":         update.SelfComment,
+		"self_percent":         update.SelfPercent,
+		"self_submitted_at":    update.SelfSubmittedAt,
+		"manager_scores":       update.ManagerScores,
+		"manager_comment":      update.ManagerComment,
+		"manager_percent":      update.ManagerPercent,
+		"manager_submitted_at": update.ManagerSubmittedAt,
+		"manager_submitted_by": update.ManagerSubmittedBy,
+		"suggested_score":      update.SuggestedScore,
+		"final_score":          update.FinalScore,
+		"final_grade":          update.FinalGrade,
+		"calibration_note":     update.CalibrationNote,
+		"finalized_by":         update.FinalizedBy,
+		"finalized_at":         update.FinalizedAt,
+		"updated_at":           update.UpdatedAt,
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.PerformanceReview
+	if err := r.collReviews.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *reviewCycleRepo) GetAllPerformanceReviewsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.PerformanceReview, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.collReviews.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var reviews []*models.PerformanceReview
+	for cursor.Next(ctx) {
+		var review models.PerformanceReview
+		if err := cursor.Decode(&review); err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, &review)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+func (r *reviewCycleRepo) GetOnePerformanceReviewByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.PerformanceReview, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var review models.PerformanceReview
+	if err := r.collReviews.FindOne(ctx, filter, opts).Decode(&review); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *reviewCycleRepo) GetListPerformanceReviewsByFilter(ctx context.Context, filter interface{}, projection interface{}, sort bson.D, skip, limit int64) ([]models.PerformanceReview, int64, error) {
+
+	findOpts := options.Find().
+		SetSort(sort).
+		SetSkip(skip).
+		SetLimit(limit)
+
+	if projection != nil {
+		findOpts.SetProjection(projection)
+	}
+
+	cur, err := r.collReviews.Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("find: %w", err)
+	}
+	defer cur.Close(ctx)
+
+	var results []models.PerformanceReview
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, 0, fmt.Errorf("decode: %w", err)
+	}
+
+	total, err := r.collReviews.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("count: %w", err)
+	}
+
+	return results, total, nil
+}
+
+func (r *reviewCycleRepo) CountPerformanceReviews(ctx context.Context, filter interface{}) (int64, error) {
+	return r.collReviews.CountDocuments(ctx, filter)
+}
@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type signTypeWorkflowRepo struct {
+	coll *mongo.Collection
+}
+
+func NewSignTypeWorkflowRepository(db *mongo.Database) ports.SignTypeWorkflowRepository {
+	return &signTypeWorkflowRepo{coll: db.Collection(models.CollectionSignTypeWorkflows)}
+}
+
+func (r *signTypeWorkflowRepo) CreateSignTypeWorkflow(ctx context.Context, mapping models.SignTypeWorkflow) error {
+	_, err := r.coll.InsertOne(ctx, mapping)
+	return err
+}
+
+func (r *signTypeWorkflowRepo) UpdateSignTypeWorkflowByID(ctx context.Context, mappingID string, update models.SignTypeWorkflow) (*models.SignTypeWorkflow, error) {
+	filter := bson.M{"mapping_id": mappingID}
+	set := bson.M{
+		"install_option": update.InstallOption,
+		"items":          update.Items,
+		"is_active":      update.IsActive,
+		"base_area_sqm":  update.BaseAreaSqm,
+		"updated_at":     time.Now(),
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.SignTypeWorkflow
+	if err := r.coll.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *signTypeWorkflowRepo) SoftDeleteSignTypeWorkflowByID(ctx context.Context, mappingID string) error {
+	_, err := r.coll.UpdateOne(ctx, bson.M{"mapping_id": mappingID}, bson.M{"$set": bson.M{"deleted_at": time.Now()}})
+	return err
+}
+
+func (r *signTypeWorkflowRepo) GetAllSignTypeWorkflowsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.SignTypeWorkflow, error) {
+	opts := options.Find()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var mappings []*models.SignTypeWorkflow
+	for cursor.Next(ctx) {
+		var mapping models.SignTypeWorkflow
+		if err := cursor.Decode(&mapping); err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, &mapping)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return mappings, nil
+}
+
+func (r *signTypeWorkflowRepo) GetOneSignTypeWorkflowByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.SignTypeWorkflow, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var mapping models.SignTypeWorkflow
+	if err := r.coll.FindOne(ctx, filter, opts).Decode(&mapping); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &mapping, nil
+}
+
+func (r *signTypeWorkflowRepo) GetListSignTypeWorkflowsByFilter(ctx context.Context, filter interface{}, projection interface{}, sort bson.D, skip, limit int64) ([]models.SignTypeWorkflow, int64, error) {
+
+	findOpts := options.Find().
+		SetSort(sort).
+		SetSkip(skip).
+		SetLimit(limit)
+
+	if projection != nil {
+		findOpts.SetProjection(projection)
+	}
+
+	cur, err := r.coll.Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("find: %w", err)
+	}
+	defer cur.Close(ctx)
+
+	var results []models.SignTypeWorkflow
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, 0, fmt.Errorf("decode: %w", err)
+	}
+
+	total, err := r.coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("count: %w", err)
+	}
+
+	return results, total, nil
+}
+
+// IncrementPoolIndex เลื่อนคิว round-robin ของ item แบบ atomic และคืนค่าตำแหน่งก่อนเลื่อน
+func (r *signTypeWorkflowRepo) IncrementPoolIndex(ctx context.Context, mappingID string, itemIndex int) (int, error) {
+	field := fmt.Sprintf("items.%d.next_pool_idx", itemIndex)
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+	var before models.SignTypeWorkflow
+	if err := r.coll.FindOneAndUpdate(ctx, bson.M{"mapping_id": mappingID}, bson.M{"$inc": bson.M{field: 1}}, opts).Decode(&before); err != nil {
+		return 0, err
+	}
+	if itemIndex < 0 || itemIndex >= len(before.Items) {
+		return 0, fmt.Errorf("item index %d out of range", itemIndex)
+	}
+	return before.Items[itemIndex].NextPoolIdx, nil
+}
@@ -0,0 +1,186 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type slaRepo struct {
+	collRules    *mongo.Collection
+	collBreaches *mongo.Collection
+}
+
+func NewSLARepository(db *mongo.Database) ports.SLARepository {
+	return &slaRepo{
+		collRules:    db.Collection(models.CollectionSLARules),
+		collBreaches: db.Collection(models.CollectionSLABreaches),
+	}
+}
+
+func (r *slaRepo) CreateRule(ctx context.Context, rule models.SLARule) error {
+	_, err := r.collRules.InsertOne(ctx, rule)
+	return err
+}
+
+func (r *slaRepo) UpdateRuleByID(ctx context.Context, ruleID string, update models.SLARule) (*models.SLARule, error) {
+	filter := bson.M{"rule_id": ruleID}
+	set := bson.M{
+		"name":         update.Name,
+		"target_hours": update.TargetHours,
+		"hours_factor": update.HoursFactor,
+		"warn_percent": update.WarnPercent,
+		"active":       update.Active,
+		"updated_at":   update.UpdatedAt,
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.SLARule
+	if err := r.collRules.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *slaRepo) SoftDeleteRuleByID(ctx context.Context, ruleID string) error {
+	_, err := r.collRules.UpdateOne(ctx, bson.M{"rule_id": ruleID}, bson.M{"$set": bson.M{"deleted_at": time.Now()}})
+	return err
+}
+
+func (r *slaRepo) GetAllRulesByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.SLARule, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.collRules.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rules []*models.SLARule
+	for cursor.Next(ctx) {
+		var rule models.SLARule
+		if err := cursor.Decode(&rule); err != nil {
+			return nil, err
+		}
+		rules = append(rules, &rule)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (r *slaRepo) GetOneRuleByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.SLARule, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var rule models.SLARule
+	if err := r.collRules.FindOne(ctx, filter, opts).Decode(&rule); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *slaRepo) CreateBreach(ctx context.Context, breach models.SLABreach) error {
+	_, err := r.collBreaches.InsertOne(ctx, breach)
+	return err
+}
+
+func (r *slaRepo) UpdateBreachByID(ctx context.Context, breachID string, update models.SLABreach) (*models.SLABreach, error) {
+	filter := bson.M{"breach_id": breachID}
+	set := bson.M{
+		"level":         update.Level,
+		"status":        update.Status,
+		"due_at":        update.DueAt,
+		"escalated_to":  update.EscalatedTo,
+		"escalated_at":  update.EscalatedAt,
+		"resolved_at":   update.ResolvedAt,
+		"overdue_hours": update.OverdueHours,
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.SLABreach
+	if err := r.collBreaches.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *slaRepo) GetAllBreachesByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.SLABreach, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "detected_at", Value: -1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.collBreaches.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var breaches []*models.SLABreach
+	for cursor.Next(ctx) {
+		var breach models.SLABreach
+		if err := cursor.Decode(&breach); err != nil {
+			return nil, err
+		}
+		breaches = append(breaches, &breach)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return breaches, nil
+}
+
+func (r *slaRepo) GetOneBreachByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.SLABreach, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var breach models.SLABreach
+	if err := r.collBreaches.FindOne(ctx, filter, opts).Decode(&breach); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &breach, nil
+}
+
+func (r *slaRepo) GetListBreachesByFilter(ctx context.Context, filter interface{}, skip, limit int64) ([]*models.SLABreach, int64, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "detected_at", Value: -1}}).SetSkip(skip).SetLimit(limit)
+	cursor, err := r.collBreaches.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var breaches []*models.SLABreach
+	if err := cursor.All(ctx, &breaches); err != nil {
+		return nil, 0, err
+	}
+
+	total, err := r.collBreaches.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return breaches, total, nil
+}
@@ -158,9 +158,9 @@ func (r *taskRepo) GetAllStepSteps(ctx context.Context, taskID string) ([]models
 	return doc.AppliedWorkflow.Steps, nil
 }
 
-func (r *taskRepo) UpdateTaskStatus(ctx context.Context, taskID, status, stepName string, now time.Time) error {
+func (r *taskRepo) UpdateTaskStatus(ctx context.Context, taskID, status, stepName string, readySteps []string, now time.Time) error {
 	filter := bson.M{"task_id": taskID, "deleted_at": nil}
-	update := bson.M{"$set": bson.M{"status": status, "step_name": stepName, "updated_at": now}}
+	update := bson.M{"$set": bson.M{"status": status, "step_name": stepName, "ready_steps": readySteps, "updated_at": now}}
 	res, err := r.coll.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
@@ -227,6 +227,79 @@ func (r *taskRepo) UpsertUserTaskStats(ctx context.Context, stats *models.UserTa
 	return nil
 }
 
+// IncUserTaskStats ปรับ totals แบบ atomic ไม่อ่านค่าเดิมมาคำนวณ (กันค่าคลาดเมื่อมีการอัปเดตพร้อมกัน)
+func (r *taskRepo) IncUserTaskStats(ctx context.Context, userID, departmentID string, delta models.UserTaskTotals, now time.Time) error {
+	filter := bson.M{"user_id": userID}
+	set := bson.M{"updated_at": now}
+	if departmentID != "" {
+		set["department_id"] = departmentID
+	}
+	update := bson.M{
+		"$inc": bson.M{
+			"totals.assigned":    delta.Assigned,
+			"totals.open":        delta.Open,
+			"totals.in_progress": delta.InProgress,
+			"totals.completed":   delta.Completed,
+		},
+		"$set":         set,
+		"$setOnInsert": bson.M{"created_at": now, "deleted_at": nil},
+	}
+	_, err := r.collUserTaskStats.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// SetUserTaskTotals เขียนทับ totals (ใช้ตอน rebuild) ไม่แตะ KPI
+func (r *taskRepo) SetUserTaskTotals(ctx context.Context, userID, departmentID string, totals models.UserTaskTotals, now time.Time) error {
+	filter := bson.M{"user_id": userID}
+	set := bson.M{"totals": totals, "updated_at": now}
+	if departmentID != "" {
+		set["department_id"] = departmentID
+	}
+	update := bson.M{
+		"$set":         set,
+		"$setOnInsert": bson.M{"created_at": now, "deleted_at": nil},
+	}
+	_, err := r.collUserTaskStats.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// SetUserTaskKPI อัปเดตเฉพาะ KPI ไม่แตะ totals
+func (r *taskRepo) SetUserTaskKPI(ctx context.Context, userID string, kpi models.UserTaskKPI, now time.Time) error {
+	filter := bson.M{"user_id": userID}
+	update := bson.M{
+		"$set":         bson.M{"kpi": kpi, "updated_at": now},
+		"$setOnInsert": bson.M{"created_at": now, "deleted_at": nil, "totals": models.UserTaskTotals{}},
+	}
+	_, err := r.collUserTaskStats.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// ReassignTask เปลี่ยนผู้รับผิดชอบหลัก + steps และต่อท้ายประวัติ
+// ใช้ผู้รับผิดชอบเดิมเป็นเงื่อนไข ถ้ามีรายการอื่นเปลี่ยนไปก่อนจะคืน nil
+func (r *taskRepo) ReassignTask(ctx context.Context, taskID, fromAssignee string, doc *models.Tasks, entry models.TaskReassignment) (*models.Tasks, error) {
+	filter := bson.M{"task_id": taskID, "deleted_at": nil, "assignee": fromAssignee}
+	update := bson.M{
+		"$set": bson.M{
+			"assignee":               doc.Assignee,
+			"assignee_name":          doc.AssigneeName,
+			"assignee_nickname":      doc.AssigneeNickName,
+			"applied_workflow.steps": doc.AppliedWorkflow.Steps,
+			"updated_at":             doc.UpdatedAt,
+		},
+		"$push": bson.M{"reassign_history": entry},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var updated models.Tasks
+	if err := r.coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
 func (r *taskRepo) UpdateOneStepFields(ctx context.Context, taskID, stepID string, status *string, notes *string, now time.Time) error {
 	filter := bson.M{
 		"task_id":                        taskID,
@@ -317,6 +390,114 @@ func (r *taskRepo) UpdateOneStepFields(ctx context.Context, taskID, stepID strin
 	return nil
 }
 
+func (r *taskRepo) UpdateStepAssignee(ctx context.Context, taskID, stepID, assignee, assigneeName string, now time.Time) error {
+	filter := bson.M{
+		"task_id":                        taskID,
+		"deleted_at":                     nil,
+		"applied_workflow.steps.step_id": stepID,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"applied_workflow.steps.$.assignee":      assignee,
+			"applied_workflow.steps.$.assignee_name": assigneeName,
+			"applied_workflow.steps.$.updated_at":    now,
+			"updated_at":                             now,
+		},
+	}
+	res, err := r.coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// AddStepChecklistItem เพิ่มรายการตรวจสอบท้าย checklist ของ step
+func (r *taskRepo) AddStepChecklistItem(ctx context.Context, taskID, stepID string, item models.TaskChecklistItem, now time.Time) error {
+	filter := bson.M{
+		"task_id":                        taskID,
+		"deleted_at":                     nil,
+		"applied_workflow.steps.step_id": stepID,
+	}
+	update := bson.M{
+		"$push": bson.M{"applied_workflow.steps.$.checklist": item},
+		"$set": bson.M{
+			"applied_workflow.steps.$.updated_at": now,
+			"updated_at":                          now,
+		},
+	}
+	res, err := r.coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// UpdateStepChecklistItem ติ๊ก/ยกเลิกติ๊กรายการเดียว (ไม่ทับรายการอื่นที่คนอื่นติ๊กพร้อมกัน)
+func (r *taskRepo) UpdateStepChecklistItem(ctx context.Context, taskID, stepID string, item models.TaskChecklistItem, now time.Time) error {
+	filter := bson.M{
+		"task_id":    taskID,
+		"deleted_at": nil,
+		"applied_workflow.steps": bson.M{"$elemMatch": bson.M{
+			"step_id":           stepID,
+			"checklist.item_id": item.ItemID,
+		}},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"applied_workflow.steps.$[s].checklist.$[i].checked":    item.Checked,
+			"applied_workflow.steps.$[s].checklist.$[i].checked_by": item.CheckedBy,
+			"applied_workflow.steps.$[s].checklist.$[i].checked_at": item.CheckedAt,
+			"applied_workflow.steps.$[s].updated_at":                now,
+			"updated_at":                                            now,
+		},
+	}
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{
+		bson.M{"s.step_id": stepID},
+		bson.M{"i.item_id": item.ItemID},
+	}})
+	res, err := r.coll.UpdateOne(ctx, filter, update, opts)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// RemoveStepChecklistItem ลบรายการตรวจสอบออกจาก step
+func (r *taskRepo) RemoveStepChecklistItem(ctx context.Context, taskID, stepID, itemID string, now time.Time) error {
+	filter := bson.M{
+		"task_id":    taskID,
+		"deleted_at": nil,
+		"applied_workflow.steps": bson.M{"$elemMatch": bson.M{
+			"step_id":           stepID,
+			"checklist.item_id": itemID,
+		}},
+	}
+	update := bson.M{
+		"$pull": bson.M{"applied_workflow.steps.$.checklist": bson.M{"item_id": itemID}},
+		"$set": bson.M{
+			"applied_workflow.steps.$.updated_at": now,
+			"updated_at":                          now,
+		},
+	}
+	res, err := r.coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
 //<===============================================================================================>
 
 // package repositories
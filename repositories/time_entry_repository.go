@@ -0,0 +1,178 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type timeEntryRepo struct {
+	collEntries    *mongo.Collection
+	collTimesheets *mongo.Collection
+}
+
+func NewTimeEntryRepository(db *mongo.Database) ports.TimeEntryRepository {
+	collEntries := db.Collection(models.CollectionTimeEntries)
+
+	// จับเวลาได้ครั้งละหนึ่งรายการต่อคน (ended_at เป็น null = กำลังจับเวลา)
+	indexModels := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetName("uniq_running_timer").SetUnique(true).SetPartialFilterExpression(bson.M{
+				"ended_at":   bson.M{"$type": "null"},
+				"deleted_at": bson.M{"$type": "null"},
+			}),
+		},
+	}
+
+	// Create indexes in the background (ignore errors if already exist)
+	_, _ = collEntries.Indexes().CreateMany(context.Background(), indexModels)
+
+	return &timeEntryRepo{
+		collEntries:    collEntries,
+		collTimesheets: db.Collection(models.CollectionTimesheets),
+	}
+}
+
+func (r *timeEntryRepo) CreateTimeEntry(ctx context.Context, entry models.TimeEntry) error {
+	_, err := r.collEntries.InsertOne(ctx, entry)
+	return err
+}
+
+func (r *timeEntryRepo) UpdateTimeEntryByID(ctx context.Context, entryID string, update models.TimeEntry) (*models.TimeEntry, error) {
+	filter := bson.M{"entry_id": entryID}
+	set := bson.M{
+		"started_at": update.StartedAt,
+		"ended_at":   update.EndedAt,
+		"work_date":  update.WorkDate,
+		"hours":      update.Hours,
+		"note":       update.Note,
+		"edits":      update.Edits,
+		"updated_at": time.Now(),
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.TimeEntry
+	if err := r.collEntries.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *timeEntryRepo) GetAllTimeEntriesByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.TimeEntry, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "started_at", Value: 1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.collEntries.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*models.TimeEntry
+	for cursor.Next(ctx) {
+		var entry models.TimeEntry
+		if err := cursor.Decode(&entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &entry)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *timeEntryRepo) GetOneTimeEntryByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.TimeEntry, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var entry models.TimeEntry
+	if err := r.collEntries.FindOne(ctx, filter, opts).Decode(&entry); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *timeEntryRepo) CreateTimesheet(ctx context.Context, timesheet models.Timesheet) error {
+	_, err := r.collTimesheets.InsertOne(ctx, timesheet)
+	return err
+}
+
+func (r *timeEntryRepo) UpdateTimesheetByID(ctx context.Context, timesheetID string, update models.Timesheet) (*models.Timesheet, error) {
+	filter := bson.M{"timesheet_id": timesheetID}
+	set := bson.M{
+		"status":       update.Status,
+		"total_hours":  update.TotalHours,
+		"submitted_at": update.SubmittedAt,
+		"reviewed_by":  update.ReviewedBy,
+		"reviewed_at":  update.ReviewedAt,
+		"review_note":  update.ReviewNote,
+		"updated_at":   time.Now(),
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.Timesheet
+	if err := r.collTimesheets.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *timeEntryRepo) GetAllTimesheetsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.Timesheet, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "week_start", Value: -1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.collTimesheets.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var timesheets []*models.Timesheet
+	for cursor.Next(ctx) {
+		var timesheet models.Timesheet
+		if err := cursor.Decode(&timesheet); err != nil {
+			return nil, err
+		}
+		timesheets = append(timesheets, &timesheet)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return timesheets, nil
+}
+
+func (r *timeEntryRepo) GetOneTimesheetByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.Timesheet, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var timesheet models.Timesheet
+	if err := r.collTimesheets.FindOne(ctx, filter, opts).Decode(&timesheet); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &timesheet, nil
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// workFlowVersionRepo เก็บ snapshot ของ template แบบ insert-only (ไม่มี update/delete)
+type workFlowVersionRepo struct {
+	coll *mongo.Collection
+}
+
+func NewWorkFlowVersionRepository(db *mongo.Database) ports.WorkFlowVersionRepository {
+	return &workFlowVersionRepo{
+		coll: db.Collection(models.CollectionWorkflowTemplateVersions),
+	}
+}
+
+func (r *workFlowVersionRepo) CreateWorkFlowVersion(ctx context.Context, version models.WorkFlowTemplateVersion) error {
+	_, err := r.coll.InsertOne(ctx, version)
+	return err
+}
+
+func (r *workFlowVersionRepo) GetAllWorkFlowVersionsByFilter(ctx context.Context, filter interface{}, projection interface{}) ([]*models.WorkFlowTemplateVersion, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "version", Value: 1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var versions []*models.WorkFlowTemplateVersion
+	for cursor.Next(ctx) {
+		var version models.WorkFlowTemplateVersion
+		if err := cursor.Decode(&version); err != nil {
+			return nil, err
+		}
+		versions = append(versions, &version)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+func (r *workFlowVersionRepo) GetOneWorkFlowVersionByFilter(ctx context.Context, filter interface{}, projection interface{}) (*models.WorkFlowTemplateVersion, error) {
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	var version models.WorkFlowTemplateVersion
+	if err := r.coll.FindOne(ctx, filter, opts).Decode(&version); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &version, nil
+}
@@ -2,24 +2,28 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/Be2Bag/erp-demo/config"
 	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
 	"github.com/Be2Bag/erp-demo/ports"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type adminService struct {
-	adminRepo ports.AdminRepository
-	authRepo  ports.AuthRepository
-	userRepo  ports.UserRepository
-	config    config.Config
+	adminRepo    ports.AdminRepository
+	authRepo     ports.AuthRepository
+	userRepo     ports.UserRepository
+	historySvc   ports.EmploymentHistoryService
+	checklistSvc ports.EmployeeChecklistService
+	config       config.Config
 }
 
-func NewAdminService(cfg config.Config, adminRepo ports.AdminRepository, authRepo ports.AuthRepository, userRepo ports.UserRepository) ports.AdminService {
-	return &adminService{config: cfg, adminRepo: adminRepo, authRepo: authRepo, userRepo: userRepo}
+func NewAdminService(cfg config.Config, adminRepo ports.AdminRepository, authRepo ports.AuthRepository, userRepo ports.UserRepository, historySvc ports.EmploymentHistoryService, checklistSvc ports.EmployeeChecklistService) ports.AdminService {
+	return &adminService{config: cfg, adminRepo: adminRepo, authRepo: authRepo, userRepo: userRepo, historySvc: historySvc, checklistSvc: checklistSvc}
 }
 
 func (s *adminService) UpdateUserStatus(ctx context.Context, req dto.RequestUpdateUserStatus) error {
@@ -40,6 +44,16 @@ func (s *adminService) UpdateUserStatus(ctx context.Context, req dto.RequestUpda
 	// 	return fmt.Errorf("user status is not pending, current status: %s", users[0].Status)
 	// }
 
+	// อนุมัติได้เมื่อรายการบังคับของ onboarding ครบ (บัตรประชาชน สัญญาจ้าง บัญชีธนาคาร ฯลฯ)
+	if req.Status == "approved" && users[0].Status != "approved" {
+		if users[0].AccessRevokedAt != nil {
+			return fmt.Errorf("%w: access has been revoked by offboarding, cancel the offboarding first", ports.ErrChecklistConflict)
+		}
+		if err := s.checklistSvc.EnsureOnboardingComplete(ctx, users[0]); err != nil {
+			return err
+		}
+	}
+
 	update := bson.M{"$set": bson.M{"status": req.Status, "updated_at": time.Now()}}
 
 	if req.Status == "deleted" {
@@ -54,7 +68,7 @@ func (s *adminService) UpdateUserStatus(ctx context.Context, req dto.RequestUpda
 	return nil
 }
 
-func (s *adminService) UpdateUserPosition(ctx context.Context, req dto.RequestUpdateUserPosition) error {
+func (s *adminService) UpdateUserPosition(ctx context.Context, req dto.RequestUpdateUserPosition, claims *dto.JWTClaims) error {
 
 	filter := bson.M{"user_id": req.UserID, "deleted_at": nil}
 	projection := bson.M{}
@@ -68,7 +82,29 @@ func (s *adminService) UpdateUserPosition(ctx context.Context, req dto.RequestUp
 		return mongo.ErrNoDocuments
 	}
 
-	update := bson.M{"$set": bson.M{"role": req.Role, "department_id": req.DepartmentID, "position_id": req.PositionID, "note": req.Note, "updated_at": time.Now()}}
+	// ตำแหน่ง/แผนกเปลี่ยนผ่านประวัติการจ้างงาน ถ้าวันที่มีผลเป็นอนาคต cron จะปรับให้เมื่อถึงวัน
+	user := users[0]
+	change := models.EmploymentHistory{PositionID: req.PositionID, DepartmentID: req.DepartmentID, ChangedBy: claims.UserID, Note: req.Note}
+	if req.EffectiveDate != "" {
+		effectiveDate, err := time.Parse("2006-01-02", req.EffectiveDate)
+		if err != nil {
+			return fmt.Errorf("invalid date format for effective_date: %w", err)
+		}
+		change.FromDate = effectiveDate
+	}
+	if err := s.historySvc.RecordEmploymentChange(ctx, user, change); err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{
+		"role":               req.Role,
+		"department_id":      user.DepartmentID,
+		"position_id":        user.PositionID,
+		"employment_type":    user.EmploymentType,
+		"employment_history": user.EmploymentHistory,
+		"note":               req.Note,
+		"updated_at":         time.Now(),
+	}}
 
 	_, errOnUpdatePosition := s.userRepo.UpdateUserByFilter(ctx, filter, update)
 	if errOnUpdatePosition != nil {
@@ -0,0 +1,398 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/config"
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/pkg/storage"
+	"github.com/Be2Bag/erp-demo/pkg/util"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	maxAttachmentSize  = 20 << 20 // 20 MB
+	thumbnailMaxSide   = 320
+	attachmentsFolder  = "attachments"
+	thumbnailSubFolder = "thumbs"
+)
+
+var attachmentEntityTypes = map[string]bool{
+	"sign_job": true,
+	"task":     true,
+	"step":     true,
+	"receipt":  true,
+	"payable":  true,
+	"expense":  true,
+}
+
+var attachmentCategories = map[string]bool{
+	"photo":    true, // รูปหน้างาน/ผลงาน
+	"design":   true, // ไฟล์แบบ
+	"proof":    true, // หลักฐานส่งมอบ/ติดตั้ง
+	"document": true, // เอกสารทั่วไป
+	"invoice":  true, // ใบแจ้งหนี้/ใบเสร็จ
+	"other":    true,
+}
+
+type attachmentService struct {
+	attachmentRepo           ports.AttachmentRepository
+	signJobRepo              ports.SignJobRepository
+	taskRepo                 ports.TaskRepository
+	departmentRepo           ports.DepartmentRepository
+	receiptRepo              ports.ReceiptRepository
+	payableRepo              ports.PayableRepository
+	expenseRepo              ports.ExpenseRepository
+	storageCloudflareService *storage.CloudflareStorage
+	config                   config.Config
+}
+
+func NewAttachmentService(cfg config.Config, attachmentRepo ports.AttachmentRepository, signJobRepo ports.SignJobRepository, taskRepo ports.TaskRepository, departmentRepo ports.DepartmentRepository, receiptRepo ports.ReceiptRepository, payableRepo ports.PayableRepository, expenseRepo ports.ExpenseRepository, storageCloudflareService *storage.CloudflareStorage) ports.AttachmentService {
+	return &attachmentService{config: cfg, attachmentRepo: attachmentRepo, signJobRepo: signJobRepo, taskRepo: taskRepo, departmentRepo: departmentRepo, receiptRepo: receiptRepo, payableRepo: payableRepo, expenseRepo: expenseRepo, storageCloudflareService: storageCloudflareService}
+}
+
+func (s *attachmentService) UploadAttachment(ctx context.Context, req dto.CreateAttachmentDTO, file dto.AttachmentFile, claims *dto.JWTClaims) (*dto.AttachmentDTO, error) {
+	entityType := strings.ToLower(strings.TrimSpace(req.EntityType))
+	entityID := strings.TrimSpace(req.EntityID)
+	if !attachmentEntityTypes[entityType] {
+		return nil, errors.New("entity_type must be one of: sign_job|task|step|receipt|payable|expense")
+	}
+	if entityID == "" {
+		return nil, errors.New("entity_id is required")
+	}
+	category := strings.ToLower(strings.TrimSpace(req.Category))
+	if category == "" {
+		category = "other"
+	}
+	if !attachmentCategories[category] {
+		return nil, errors.New("category must be one of: photo|design|proof|document|invoice|other")
+	}
+	if file.Size > maxAttachmentSize {
+		return nil, fmt.Errorf("file is too large (max %d MB)", maxAttachmentSize>>20)
+	}
+
+	parentID := strings.TrimSpace(req.TaskID)
+	jobID, err := s.checkAccess(ctx, entityType, entityID, parentID, claims)
+	if err != nil {
+		return nil, err
+	}
+	if entityType != "step" {
+		parentID = ""
+	}
+
+	data, err := os.ReadFile(file.Path)
+	if err != nil {
+		return nil, fmt.Errorf("read uploaded file: %w", err)
+	}
+
+	attachmentID := uuid.NewString()
+	ext := strings.ToLower(filepath.Ext(file.Name))
+	folder := path.Join(attachmentsFolder, entityType, entityID)
+	fileKey := path.Join(folder, attachmentID+ext)
+
+	// สร้าง thumbnail ก่อนอัปโหลด ถ้าไฟล์เสียจะไม่มีอะไรค้างใน storage
+	thumb, isImage, err := util.MakeThumbnail(data, thumbnailMaxSide)
+	if err != nil {
+		return nil, fmt.Errorf("generate thumbnail: %w", err)
+	}
+
+	// ขั้นตอนหลังอัปโหลดล้มเหลว ให้ลบไฟล์ที่อัปโหลดไปแล้ว (ไม่มีรายการใดอ้างถึง)
+	uploaded := []string{}
+	fail := func(err error) (*dto.AttachmentDTO, error) {
+		for _, key := range uploaded {
+			if errOnDelete := s.storageCloudflareService.DeleteFile(path.Dir(key), path.Base(key)); errOnDelete != nil {
+				log.Println("Error deleting orphaned attachment file:", key, errOnDelete)
+			}
+		}
+		return nil, err
+	}
+
+	if err := s.storageCloudflareService.UploadFile(file.Path, fileKey); err != nil {
+		return nil, err
+	}
+	uploaded = append(uploaded, fileKey)
+	fileURL, err := s.storageCloudflareService.GetFileURLByName(fileKey)
+	if err != nil {
+		return fail(err)
+	}
+
+	var thumbKey, thumbURL string
+	if isImage {
+		thumbKey = path.Join(folder, thumbnailSubFolder, attachmentID+".jpg")
+		if err := s.storageCloudflareService.UploadBytes(thumb, thumbKey, "image/jpeg"); err != nil {
+			return fail(err)
+		}
+		uploaded = append(uploaded, thumbKey)
+		if thumbURL, err = s.storageCloudflareService.GetFileURLByName(thumbKey); err != nil {
+			return fail(err)
+		}
+	}
+
+	contentType := file.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	uploaderName := strings.TrimSpace(fmt.Sprintf("%s %s %s", claims.TitleTH, claims.FirstNameTH, claims.LastNameTH))
+
+	attachment := models.Attachment{
+		AttachmentID:   attachmentID,
+		EntityType:     entityType,
+		EntityID:       entityID,
+		ParentID:       parentID,
+		JobID:          jobID,
+		Category:       category,
+		Caption:        strings.TrimSpace(req.Caption),
+		FileName:       file.Name,
+		FileKey:        fileKey,
+		FileURL:        fileURL,
+		ThumbnailKey:   thumbKey,
+		ThumbnailURL:   thumbURL,
+		ContentType:    contentType,
+		UploadedBy:     claims.UserID,
+		UploadedByName: uploaderName,
+		Size:           file.Size,
+		CreatedAt:      time.Now(),
+	}
+	if err := s.attachmentRepo.CreateAttachment(ctx, attachment); err != nil {
+		return fail(err)
+	}
+
+	out := toAttachmentDTO(&attachment)
+	return &out, nil
+}
+
+func (s *attachmentService) ListAttachments(ctx context.Context, req dto.RequestListAttachments, claims *dto.JWTClaims) ([]dto.AttachmentDTO, error) {
+	entityType := strings.ToLower(strings.TrimSpace(req.EntityType))
+	entityID := strings.TrimSpace(req.EntityID)
+	if !attachmentEntityTypes[entityType] {
+		return nil, errors.New("entity_type must be one of: sign_job|task|step|receipt|payable|expense")
+	}
+	if entityID == "" {
+		return nil, errors.New("entity_id is required")
+	}
+
+	if _, err := s.checkAccess(ctx, entityType, entityID, strings.TrimSpace(req.TaskID), claims); err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"entity_type": entityType, "entity_id": entityID, "deleted_at": nil}
+	switch {
+	case entityType == "sign_job" && req.IncludeChildren:
+		// gallery ของงาน: รวมไฟล์ที่แนบกับ task/step ของงานนี้ (ไม่รวมเอกสารการเงิน เช่น เจ้าหนี้/รายจ่ายที่ผูกกับงาน)
+		filter = bson.M{
+			"$or": []bson.M{
+				{"entity_type": "sign_job", "entity_id": entityID},
+				{"entity_type": bson.M{"$in": []string{"task", "step"}}, "job_id": entityID},
+			},
+			"deleted_at": nil,
+		}
+	case entityType == "task" && req.IncludeChildren:
+		filter = bson.M{
+			"$or": []bson.M{
+				{"entity_type": "task", "entity_id": entityID},
+				{"entity_type": "step", "parent_id": entityID},
+			},
+			"deleted_at": nil,
+		}
+	}
+	if category := strings.ToLower(strings.TrimSpace(req.Category)); category != "" {
+		filter["category"] = category
+	}
+
+	items, err := s.attachmentRepo.GetAllAttachmentsByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]dto.AttachmentDTO, 0, len(items))
+	for _, a := range items {
+		out = append(out, toAttachmentDTO(a))
+	}
+	return out, nil
+}
+
+func (s *attachmentService) DeleteAttachment(ctx context.Context, attachmentID string, claims *dto.JWTClaims) error {
+	existing, err := s.attachmentRepo.GetOneAttachmentByFilter(ctx, bson.M{"attachment_id": attachmentID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return mongo.ErrNoDocuments
+	}
+
+	if claims.Role != "admin" {
+		if existing.UploadedBy != claims.UserID {
+			return ports.ErrAttachmentForbidden
+		}
+		if _, err := s.checkAccess(ctx, existing.EntityType, existing.EntityID, existing.ParentID, claims); err != nil {
+			return err
+		}
+	}
+
+	if err := s.attachmentRepo.SoftDeleteAttachmentByID(ctx, attachmentID, claims.UserID); err != nil {
+		return err
+	}
+
+	// ลบไฟล์ใน storage (ถ้าลบไม่สำเร็จ ข้อมูลถูก soft delete แล้ว จึงบันทึก log ไว้)
+	for _, key := range []string{existing.FileKey, existing.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := s.storageCloudflareService.DeleteFile(path.Dir(key), path.Base(key)); err != nil {
+			log.Println("Error deleting attachment file:", key, err)
+		}
+	}
+	return nil
+}
+
+// checkAccess ตรวจสิทธิ์ผู้ใช้ต่อเอกสารที่แนบไฟล์ และคืนค่า job_id ที่เกี่ยวข้อง (ถ้ามี)
+//   - admin เข้าถึงได้ทั้งหมด
+//   - งานป้าย: ผู้สร้างงาน หรือผู้ที่มี task ในงานนั้น
+//   - task/step: ผู้รับผิดชอบ ผู้สร้าง task เจ้าของ step หรือผู้จัดการแผนกของ task
+//   - ใบเสร็จ/เจ้าหนี้/รายจ่าย: ผู้สร้างหรือผู้รับเงินของเอกสารนั้น
+func (s *attachmentService) checkAccess(ctx context.Context, entityType, entityID, taskID string, claims *dto.JWTClaims) (string, error) {
+	isAdmin := claims.Role == "admin"
+
+	switch entityType {
+	case "sign_job":
+		job, err := s.signJobRepo.GetOneSignJobByFilter(ctx, bson.M{"job_id": entityID, "deleted_at": nil}, bson.M{"job_id": 1, "created_by": 1})
+		if err != nil {
+			return "", err
+		}
+		if job == nil {
+			return "", mongo.ErrNoDocuments
+		}
+		if isAdmin || job.CreatedBy == claims.UserID {
+			return job.JobID, nil
+		}
+		task, err := s.taskRepo.GetOneTasksByFilter(ctx, bson.M{
+			"job_id":     entityID,
+			"deleted_at": nil,
+			"$or": []bson.M{
+				{"assignee": claims.UserID},
+				{"created_by": claims.UserID},
+				{"applied_workflow.steps.assignee": claims.UserID},
+			},
+		}, bson.M{"task_id": 1})
+		if err != nil && err != mongo.ErrNoDocuments {
+			return "", err
+		}
+		if task == nil {
+			return "", ports.ErrAttachmentForbidden
+		}
+		return job.JobID, nil
+
+	case "task", "step":
+		id := entityID
+		if entityType == "step" {
+			if taskID == "" {
+				return "", errors.New("task_id is required for step attachments")
+			}
+			id = taskID
+		}
+		task, err := s.taskRepo.GetOneTasksByFilter(ctx, bson.M{"task_id": id, "deleted_at": nil}, bson.M{})
+		if err != nil && err != mongo.ErrNoDocuments {
+			return "", err
+		}
+		if task == nil {
+			return "", mongo.ErrNoDocuments
+		}
+		if entityType == "step" {
+			found := false
+			for _, st := range task.AppliedWorkflow.Steps {
+				if st.StepID == entityID {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return "", mongo.ErrNoDocuments
+			}
+		}
+		if isAdmin || isTaskParticipant(task, claims.UserID) {
+			return task.JobID, nil
+		}
+		dept, err := s.departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{"department_id": task.Department, "deleted_at": nil}, bson.M{"manager_id": 1})
+		if err != nil && err != mongo.ErrNoDocuments {
+			return "", err
+		}
+		if dept != nil && dept.ManagerID == claims.UserID {
+			return task.JobID, nil
+		}
+		return "", ports.ErrAttachmentForbidden
+
+	case "receipt":
+		receipt, err := s.receiptRepo.GetOneReceiptsByFilter(ctx, bson.M{"id_receipt": entityID, "deleted_at": nil}, bson.M{})
+		if err != nil && err != mongo.ErrNoDocuments {
+			return "", err
+		}
+		if receipt == nil {
+			return "", mongo.ErrNoDocuments
+		}
+		if isAdmin || receipt.ReceivedBy == claims.UserID || receipt.Issuer.PreparedBy == claims.UserID {
+			return "", nil
+		}
+		return "", ports.ErrAttachmentForbidden
+
+	case "payable":
+		payable, err := s.payableRepo.GetOnePayableByFilter(ctx, bson.M{"id_payable": entityID, "deleted_at": nil}, bson.M{})
+		if err != nil && err != mongo.ErrNoDocuments {
+			return "", err
+		}
+		if payable == nil {
+			return "", mongo.ErrNoDocuments
+		}
+		if isAdmin || payable.CreatedBy == claims.UserID {
+			return payable.JobID, nil
+		}
+		return "", ports.ErrAttachmentForbidden
+
+	case "expense":
+		expense, err := s.expenseRepo.GetOneExpenseByFilter(ctx, bson.M{"expense_id": entityID, "deleted_at": nil}, bson.M{})
+		if err != nil && err != mongo.ErrNoDocuments {
+			return "", err
+		}
+		if expense == nil {
+			return "", mongo.ErrNoDocuments
+		}
+		if isAdmin || expense.CreatedBy == claims.UserID {
+			return expense.JobID, nil
+		}
+		return "", ports.ErrAttachmentForbidden
+	}
+
+	return "", fmt.Errorf("unsupported entity_type %s", entityType)
+}
+
+func toAttachmentDTO(a *models.Attachment) dto.AttachmentDTO {
+	return dto.AttachmentDTO{
+		CreatedAt:      a.CreatedAt,
+		AttachmentID:   a.AttachmentID,
+		EntityType:     a.EntityType,
+		EntityID:       a.EntityID,
+		ParentID:       a.ParentID,
+		JobID:          a.JobID,
+		Category:       a.Category,
+		Caption:        a.Caption,
+		FileName:       a.FileName,
+		FileURL:        a.FileURL,
+		ThumbnailURL:   a.ThumbnailURL,
+		ContentType:    a.ContentType,
+		UploadedBy:     a.UploadedBy,
+		UploadedByName: a.UploadedByName,
+		Size:           a.Size,
+	}
+}
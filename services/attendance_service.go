@@ -0,0 +1,1396 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/config"
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/pkg/util"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	attendanceDefaultRadiusM   = 100.0
+	attendanceMaxRadiusM       = 5000.0
+	attendanceMaxAccuracyM     = 150.0          // GPS คลาดเคลื่อนเกินนี้ไม่รับ
+	attendanceOpenWindow       = 24 * time.Hour // ลงเวลาออกได้ภายในกี่ชั่วโมงหลังลงเวลาเข้า (รองรับกะข้ามวัน)
+	attendanceMaxGraceMinutes  = 120
+	attendanceMaxBreakMinutes  = 240
+	attendanceDefaultListLimit = 10
+)
+
+type attendanceService struct {
+	config         config.Config
+	attendanceRepo ports.AttendanceRepository
+	userRepo       ports.UserRepository
+	departmentRepo ports.DepartmentRepository
+	signJobRepo    ports.SignJobRepository
+	capacityRepo   ports.CapacityRepository
+	leaveRepo      ports.LeaveRepository
+}
+
+func NewAttendanceService(cfg config.Config, attendanceRepo ports.AttendanceRepository, userRepo ports.UserRepository, departmentRepo ports.DepartmentRepository, signJobRepo ports.SignJobRepository, capacityRepo ports.CapacityRepository, leaveRepo ports.LeaveRepository) ports.AttendanceService {
+	return &attendanceService{config: cfg, attendanceRepo: attendanceRepo, userRepo: userRepo, departmentRepo: departmentRepo, signJobRepo: signJobRepo, capacityRepo: capacityRepo, leaveRepo: leaveRepo}
+}
+
+// ---------- สถานที่ลงเวลา ----------
+
+func (s *attendanceService) ListAttendanceSites(ctx context.Context, claims *dto.JWTClaims) ([]dto.AttendanceSiteDTO, error) {
+	filter := bson.M{"deleted_at": nil}
+	if claims.Role != "admin" {
+		filter["is_active"] = true
+	}
+	sites, err := s.attendanceRepo.GetAllAttendanceSitesByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]dto.AttendanceSiteDTO, 0, len(sites))
+	for _, site := range sites {
+		out = append(out, toAttendanceSiteDTO(site))
+	}
+	return out, nil
+}
+
+func (s *attendanceService) UpsertAttendanceSite(ctx context.Context, req dto.UpsertAttendanceSiteDTO, claims *dto.JWTClaims) (*dto.AttendanceSiteDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrAttendanceForbidden
+	}
+	siteType := strings.TrimSpace(req.SiteType)
+	if !helpers.InSet(siteType, models.SiteShop, models.SiteOffice, models.SiteInstall) {
+		return nil, fmt.Errorf("invalid site_type: %s (allow: shop|office|install)", req.SiteType)
+	}
+	if err := validateCoordinates(req.Latitude, req.Longitude); err != nil {
+		return nil, err
+	}
+	radius := req.RadiusM
+	if radius == 0 {
+		radius = attendanceDefaultRadiusM
+	}
+	if radius < 0 || radius > attendanceMaxRadiusM {
+		return nil, fmt.Errorf("radius_m must be between 1 and %.0f", attendanceMaxRadiusM)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	jobID := ""
+	if siteType == models.SiteInstall {
+		jobID = strings.TrimSpace(req.JobID)
+		if jobID == "" {
+			return nil, errors.New("job_id is required for install site")
+		}
+		job, err := s.signJobRepo.GetOneSignJobByFilter(ctx, bson.M{"job_id": jobID, "deleted_at": nil}, bson.M{"_id": 0, "job_name": 1, "status": 1})
+		if err != nil {
+			return nil, err
+		}
+		if job == nil {
+			return nil, errors.New("sign job not found")
+		}
+		if helpers.InSet(job.Status, "done", "cancelled") {
+			return nil, fmt.Errorf("%w: sign job is already %s", ports.ErrAttendanceConflict, job.Status)
+		}
+		if name == "" {
+			name = "หน้างาน " + job.JobName
+		}
+	}
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+
+	active := true
+	if req.IsActive != nil {
+		active = *req.IsActive
+	}
+	now := time.Now()
+	site := models.AttendanceSite{
+		UpdatedAt:   now,
+		Name:        name,
+		SiteType:    siteType,
+		JobID:       jobID,
+		Latitude:    req.Latitude,
+		Longitude:   req.Longitude,
+		RadiusM:     radius,
+		Departments: uniqueStrings(req.Departments),
+		IsActive:    active,
+	}
+
+	if id := strings.TrimSpace(req.SiteID); id != "" {
+		updated, err := s.attendanceRepo.UpdateAttendanceSiteByID(ctx, id, site)
+		if err != nil {
+			return nil, err
+		}
+		if updated == nil {
+			return nil, mongo.ErrNoDocuments
+		}
+		out := toAttendanceSiteDTO(updated)
+		return &out, nil
+	}
+
+	site.SiteID = uuid.NewString()
+	site.CreatedAt = now
+	site.CreatedBy = claims.UserID
+	if err := s.attendanceRepo.CreateAttendanceSite(ctx, site); err != nil {
+		return nil, err
+	}
+	out := toAttendanceSiteDTO(&site)
+	return &out, nil
+}
+
+func (s *attendanceService) DeleteAttendanceSite(ctx context.Context, siteID string, claims *dto.JWTClaims) error {
+	if claims.Role != "admin" {
+		return ports.ErrAttendanceForbidden
+	}
+	site, err := s.attendanceRepo.GetOneAttendanceSiteByFilter(ctx, bson.M{"site_id": siteID, "deleted_at": nil}, bson.M{"_id": 0, "site_id": 1})
+	if err != nil {
+		return err
+	}
+	if site == nil {
+		return mongo.ErrNoDocuments
+	}
+	return s.attendanceRepo.SoftDeleteAttendanceSiteByID(ctx, siteID)
+}
+
+// ---------- กะการทำงาน ----------
+
+func (s *attendanceService) ListWorkShifts(ctx context.Context, claims *dto.JWTClaims) ([]dto.WorkShiftDTO, error) {
+	shifts, err := s.loadWorkShifts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]dto.WorkShiftDTO, 0, len(shifts)+1)
+	hasDefault := false
+	for _, sh := range shifts {
+		hasDefault = hasDefault || sh.IsDefault
+		out = append(out, toWorkShiftDTO(sh))
+	}
+	if !hasDefault {
+		def := builtinWorkShift()
+		out = append(out, toWorkShiftDTO(&def))
+	}
+	return out, nil
+}
+
+func (s *attendanceService) UpsertWorkShift(ctx context.Context, req dto.UpsertWorkShiftDTO, claims *dto.JWTClaims) (*dto.WorkShiftDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrAttendanceForbidden
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	startMin, err := parseClock("start_time", req.StartTime)
+	if err != nil {
+		return nil, err
+	}
+	endMin, err := parseClock("end_time", req.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	length := endMin - startMin
+	if length <= 0 {
+		length += 24 * 60
+	}
+	if req.BreakMinutes < 0 || req.BreakMinutes > attendanceMaxBreakMinutes || req.BreakMinutes >= length {
+		return nil, fmt.Errorf("break_minutes must be between 0 and %d and shorter than the shift", attendanceMaxBreakMinutes)
+	}
+	for field, v := range map[string]int{"late_grace_minutes": req.LateGraceMinutes, "early_grace_minutes": req.EarlyGraceMinutes, "ot_min_minutes": req.OTMinMinutes} {
+		if v < 0 || v > attendanceMaxGraceMinutes {
+			return nil, fmt.Errorf("%s must be between 0 and %d", field, attendanceMaxGraceMinutes)
+		}
+	}
+	workDays := defaultWorkDays
+	if len(req.WorkDays) > 0 {
+		seen := make(map[int]bool)
+		workDays = make([]int, 0, len(req.WorkDays))
+		for _, d := range req.WorkDays {
+			if d < 0 || d > 6 {
+				return nil, fmt.Errorf("invalid work day: %d (0=Sunday .. 6=Saturday)", d)
+			}
+			if !seen[d] {
+				seen[d] = true
+				workDays = append(workDays, d)
+			}
+		}
+		sort.Ints(workDays)
+	}
+
+	now := time.Now()
+	shift := models.WorkShift{
+		UpdatedAt:         now,
+		Name:              name,
+		StartTime:         formatClock(startMin),
+		EndTime:           formatClock(endMin),
+		BreakMinutes:      req.BreakMinutes,
+		LateGraceMinutes:  req.LateGraceMinutes,
+		EarlyGraceMinutes: req.EarlyGraceMinutes,
+		OTMinMinutes:      req.OTMinMinutes,
+		WorkDays:          workDays,
+		Departments:       uniqueStrings(req.Departments),
+		Users:             uniqueStrings(req.Users),
+		IsDefault:         req.IsDefault,
+		UpdatedBy:         claims.UserID,
+	}
+
+	var saved *models.WorkShift
+	if id := strings.TrimSpace(req.ShiftID); id != "" {
+		saved, err = s.attendanceRepo.UpdateWorkShiftByID(ctx, id, shift)
+		if err != nil {
+			return nil, err
+		}
+		if saved == nil {
+			return nil, mongo.ErrNoDocuments
+		}
+	} else {
+		shift.ShiftID = uuid.NewString()
+		shift.CreatedAt = now
+		if err := s.attendanceRepo.CreateWorkShift(ctx, shift); err != nil {
+			return nil, err
+		}
+		saved = &shift
+	}
+	if saved.IsDefault {
+		if err := s.attendanceRepo.UnsetDefaultWorkShifts(ctx, saved.ShiftID); err != nil {
+			return nil, err
+		}
+	}
+	out := toWorkShiftDTO(saved)
+	return &out, nil
+}
+
+func (s *attendanceService) DeleteWorkShift(ctx context.Context, shiftID string, claims *dto.JWTClaims) error {
+	if claims.Role != "admin" {
+		return ports.ErrAttendanceForbidden
+	}
+	shift, err := s.attendanceRepo.GetOneWorkShiftByFilter(ctx, bson.M{"shift_id": shiftID, "deleted_at": nil}, bson.M{"_id": 0, "shift_id": 1})
+	if err != nil {
+		return err
+	}
+	if shift == nil {
+		return mongo.ErrNoDocuments
+	}
+	return s.attendanceRepo.SoftDeleteWorkShiftByID(ctx, shiftID)
+}
+
+// ---------- ลงเวลาเข้า-ออก ----------
+
+// CheckIn ลงเวลาเข้า วันทำงานคือวันตามเวลาไทยที่ลงเวลาเข้า (กะข้ามวันนับเป็นวันที่เข้ากะ)
+func (s *attendanceService) CheckIn(ctx context.Context, req dto.AttendancePunchDTO, claims *dto.JWTClaims) (*dto.AttendanceRecordDTO, error) {
+	user, err := s.getAttendanceUser(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	punch, err := s.locatePunch(ctx, user, req)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	punch.At = now
+
+	open, err := s.findOpenRecord(ctx, user.UserID, now)
+	if err != nil {
+		return nil, err
+	}
+	if open != nil {
+		return nil, fmt.Errorf("%w: please check out of %s first", ports.ErrAttendanceConflict, helpers.DayKey(open.WorkDate))
+	}
+
+	workDate := helpers.DateOnly(now.In(helpers.BangkokLocation()))
+	record, err := s.newAttendanceRecord(ctx, user, workDate)
+	if err != nil {
+		return nil, err
+	}
+	record.CheckIn = punch
+	if record.Scheduled {
+		if late := int(now.Sub(record.ShiftStart).Minutes()); late > record.LateGraceMinutes {
+			record.LateMinutes = late
+		}
+	}
+	inserted, err := s.attendanceRepo.InsertAttendanceRecordIfAbsent(ctx, *record)
+	if err != nil {
+		return nil, err
+	}
+	if !inserted {
+		return nil, fmt.Errorf("%w: already checked in on %s", ports.ErrAttendanceConflict, helpers.DayKey(workDate))
+	}
+	return s.toAttendanceRecordDTO(ctx, record, newLeaveNameCache()), nil
+}
+
+func (s *attendanceService) CheckOut(ctx context.Context, req dto.AttendancePunchDTO, claims *dto.JWTClaims) (*dto.AttendanceRecordDTO, error) {
+	user, err := s.getAttendanceUser(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	record, err := s.findOpenRecord(ctx, user.UserID, now)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, fmt.Errorf("no check-in found in the last %.0f hours", attendanceOpenWindow.Hours())
+	}
+	punch, err := s.locatePunch(ctx, user, req)
+	if err != nil {
+		return nil, err
+	}
+	punch.At = now
+
+	set := attendanceMetricsSet(record, record.CheckIn.At, now)
+	set["check_out"] = punch
+	set["updated_at"] = now
+	if set["ot_status"] == models.OTPending {
+		set["ot_reason"] = strings.TrimSpace(req.Note)
+	}
+	updated, err := s.attendanceRepo.UpdateAttendanceRecord(ctx,
+		bson.M{"record_id": record.RecordID, "check_out": nil, "deleted_at": nil},
+		bson.M{"$set": set})
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("%w: already checked out", ports.ErrAttendanceConflict)
+	}
+	return s.toAttendanceRecordDTO(ctx, updated, newLeaveNameCache()), nil
+}
+
+func (s *attendanceService) GetMyAttendanceToday(ctx context.Context, claims *dto.JWTClaims) (*dto.AttendanceTodayDTO, error) {
+	user, err := s.getAttendanceUser(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	today := helpers.Today()
+	shifts, err := s.loadWorkShifts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shift := resolveWorkShift(shifts, user)
+	out := &dto.AttendanceTodayDTO{Date: helpers.DayKey(today), Shift: toWorkShiftDTO(&shift)}
+
+	record, err := s.findOpenRecord(ctx, user.UserID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		record, err = s.attendanceRepo.GetOneAttendanceRecordByFilter(ctx, bson.M{"user_id": user.UserID, "work_date": today, "deleted_at": nil}, bson.M{})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if record != nil {
+		out.Record = s.toAttendanceRecordDTO(ctx, record, newLeaveNameCache())
+	}
+
+	holidays, err := s.holidayNames(ctx, today, today)
+	if err != nil {
+		return nil, err
+	}
+	out.Holiday = holidayFor(holidays, user.DepartmentID, today)
+
+	leaves, err := s.leaveRepo.GetAllLeaveRequestsByFilter(ctx, bson.M{
+		"user_id":    user.UserID,
+		"status":     bson.M{"$in": leaveTakenStatuses},
+		"start_date": bson.M{"$lte": today},
+		"end_date":   bson.M{"$gte": today},
+		"deleted_at": nil,
+	}, bson.M{"_id": 0, "request_id": 1})
+	if err != nil {
+		return nil, err
+	}
+	out.OnLeave = len(leaves) > 0
+	return out, nil
+}
+
+func (s *attendanceService) ListAttendance(ctx context.Context, req dto.RequestListAttendance, claims *dto.JWTClaims) (dto.Pagination, error) {
+	page, size := req.Page, req.Limit
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 100 {
+		size = attendanceDefaultListLimit
+	}
+	skip := int64((page - 1) * size)
+	limit := int64(size)
+
+	filter := bson.M{"deleted_at": nil}
+	if claims.Role != "admin" {
+		managed, err := s.managedAttendanceDepartments(ctx, claims.UserID)
+		if err != nil {
+			return dto.Pagination{}, err
+		}
+		filter["$or"] = []bson.M{{"user_id": claims.UserID}, {"department_id": bson.M{"$in": managed}}}
+	}
+	if v := strings.TrimSpace(req.UserID); v != "" {
+		filter["user_id"] = v
+	}
+	if v := strings.TrimSpace(req.DepartmentID); v != "" {
+		filter["department_id"] = v
+	}
+	if v := strings.TrimSpace(req.OTStatus); v != "" {
+		filter["ot_status"] = v
+	}
+	loc := helpers.BangkokLocation()
+	dateRange := bson.M{}
+	if strings.TrimSpace(req.StartDate) != "" {
+		start, err := helpers.ParseDate("start_date", req.StartDate, loc)
+		if err != nil {
+			return dto.Pagination{}, err
+		}
+		dateRange["$gte"] = helpers.DateOnly(start)
+	}
+	if strings.TrimSpace(req.EndDate) != "" {
+		end, err := helpers.ParseDate("end_date", req.EndDate, loc)
+		if err != nil {
+			return dto.Pagination{}, err
+		}
+		dateRange["$lte"] = helpers.DateOnly(end)
+	}
+	if len(dateRange) > 0 {
+		filter["work_date"] = dateRange
+	}
+
+	sortBy := bson.D{
+		{Key: "work_date", Value: -1},
+		{Key: "_id", Value: -1},
+	}
+	items, total, err := s.attendanceRepo.GetListAttendanceRecordsByFilter(ctx, filter, bson.M{}, sortBy, skip, limit)
+	if err != nil {
+		return dto.Pagination{}, fmt.Errorf("list attendance records: %w", err)
+	}
+
+	names := newLeaveNameCache()
+	list := make([]interface{}, 0, len(items))
+	for i := range items {
+		list = append(list, *s.toAttendanceRecordDTO(ctx, &items[i], names))
+	}
+
+	totalPages := 0
+	if total > 0 && size > 0 {
+		totalPages = int((total + int64(size) - 1) / int64(size))
+	}
+
+	return dto.Pagination{
+		Page:       page,
+		Size:       size,
+		TotalCount: int(total),
+		TotalPages: totalPages,
+		List:       list,
+	}, nil
+}
+
+// ---------- OT และการแก้ไขเวลา ----------
+
+func (s *attendanceService) DecideOvertime(ctx context.Context, recordID string, req dto.AttendanceOvertimeDecisionDTO, claims *dto.JWTClaims) (*dto.AttendanceRecordDTO, error) {
+	record, err := s.attendanceRepo.GetOneAttendanceRecordByFilter(ctx, bson.M{"record_id": recordID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	ok, err := s.canManageAttendance(ctx, record.DepartmentID, record.UserID, claims)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ports.ErrAttendanceForbidden
+	}
+	if record.OvertimeMinutes == 0 || record.OTStatus == models.OTNone {
+		return nil, fmt.Errorf("%w: record has no overtime to review", ports.ErrAttendanceConflict)
+	}
+
+	note := strings.TrimSpace(req.Note)
+	status, minutes := models.OTRejected, 0
+	if req.Approve {
+		status, minutes = models.OTApproved, record.OvertimeMinutes
+		if req.ApprovedMinutes != nil {
+			minutes = *req.ApprovedMinutes
+		}
+		if minutes <= 0 || minutes > record.OvertimeMinutes {
+			return nil, fmt.Errorf("approved_minutes must be between 1 and %d", record.OvertimeMinutes)
+		}
+	} else if note == "" {
+		return nil, errors.New("note is required when rejecting overtime")
+	}
+
+	updated, err := s.attendanceRepo.UpdateAttendanceRecord(ctx,
+		bson.M{"record_id": record.RecordID, "updated_at": record.UpdatedAt, "deleted_at": nil},
+		bson.M{"$set": bson.M{
+			"ot_status":           status,
+			"ot_approved_minutes": minutes,
+			"ot_reviewed_by":      claims.UserID,
+			"ot_review_note":      note,
+			"updated_at":          time.Now(),
+		}})
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("%w: record was changed by someone else, please reload", ports.ErrAttendanceConflict)
+	}
+
+	result := "ไม่อนุมัติ"
+	if status == models.OTApproved {
+		result = fmt.Sprintf("อนุมัติ %d นาที", minutes)
+	}
+	body := fmt.Sprintf("OT วันที่ %s (%d นาที) ได้รับการพิจารณาแล้ว: %s", updated.WorkDate.Format("02/01/2006"), updated.OvertimeMinutes, result)
+	if note != "" {
+		body += "\nหมายเหตุ: " + note
+	}
+	s.mailAttendanceUser(ctx, updated.UserID, "ผลการพิจารณา OT", body)
+	return s.toAttendanceRecordDTO(ctx, updated, newLeaveNameCache()), nil
+}
+
+// CorrectAttendance แก้ไขเวลาเข้า-ออก (หรือบันทึกแทนกรณีลืมลงเวลา) เก็บประวัติทุกครั้ง
+func (s *attendanceService) CorrectAttendance(ctx context.Context, req dto.AttendanceCorrectionDTO, claims *dto.JWTClaims) (*dto.AttendanceRecordDTO, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, errors.New("reason is required")
+	}
+	if strings.TrimSpace(req.CheckIn) == "" && strings.TrimSpace(req.CheckOut) == "" {
+		return nil, errors.New("check_in or check_out is required")
+	}
+	user, err := s.getAttendanceUser(ctx, strings.TrimSpace(req.UserID))
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.canManageAttendance(ctx, user.DepartmentID, user.UserID, claims)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ports.ErrAttendanceForbidden
+	}
+	loc := helpers.BangkokLocation()
+	day, err := helpers.ParseDate("work_date", req.WorkDate, loc)
+	if err != nil {
+		return nil, err
+	}
+	if day.After(time.Now()) {
+		return nil, errors.New("work_date cannot be in the future")
+	}
+	workDate := helpers.DateOnly(day)
+
+	record, err := s.attendanceRepo.GetOneAttendanceRecordByFilter(ctx, bson.M{"user_id": user.UserID, "work_date": workDate, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		fresh, err := s.newAttendanceRecord(ctx, user, workDate)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.attendanceRepo.InsertAttendanceRecordIfAbsent(ctx, *fresh); err != nil {
+			return nil, err
+		}
+		record, err = s.attendanceRepo.GetOneAttendanceRecordByFilter(ctx, bson.M{"user_id": user.UserID, "work_date": workDate, "deleted_at": nil}, bson.M{})
+		if err != nil {
+			return nil, err
+		}
+		if record == nil {
+			return nil, mongo.ErrNoDocuments
+		}
+	}
+
+	now := time.Now()
+	checkIn, checkOut := record.CheckIn, record.CheckOut
+	if v := strings.TrimSpace(req.CheckIn); v != "" {
+		at, err := clockOnDate("check_in", v, day)
+		if err != nil {
+			return nil, err
+		}
+		checkIn = &models.AttendancePunch{At: at, Source: models.PunchCorrection, Note: reason}
+	}
+	if checkIn == nil {
+		return nil, errors.New("check_in is required because the employee has not checked in")
+	}
+	if v := strings.TrimSpace(req.CheckOut); v != "" {
+		at, err := clockOnDate("check_out", v, day)
+		if err != nil {
+			return nil, err
+		}
+		if !at.After(checkIn.At) {
+			at = at.AddDate(0, 0, 1)
+		}
+		checkOut = &models.AttendancePunch{At: at, Source: models.PunchCorrection, Note: reason}
+	}
+	if checkOut != nil {
+		if !checkOut.At.After(checkIn.At) || checkOut.At.Sub(checkIn.At) > attendanceOpenWindow {
+			return nil, fmt.Errorf("check_out must be after check_in and within %.0f hours", attendanceOpenWindow.Hours())
+		}
+		if checkOut.At.After(now) {
+			return nil, errors.New("check_out cannot be in the future")
+		}
+	}
+
+	var set bson.M
+	if checkOut != nil {
+		set = attendanceMetricsSet(record, checkIn.At, checkOut.At)
+		// OT เท่าเดิมที่พิจารณาแล้วไม่ต้องพิจารณาใหม่
+		if set["overtime_minutes"] == record.OvertimeMinutes && helpers.InSet(record.OTStatus, models.OTApproved, models.OTRejected) {
+			set["ot_status"] = record.OTStatus
+			set["ot_approved_minutes"] = record.OTApprovedMinutes
+		}
+	} else {
+		set = bson.M{"late_minutes": 0}
+		if record.Scheduled {
+			if late := int(checkIn.At.Sub(record.ShiftStart).Minutes()); late > record.LateGraceMinutes {
+				set["late_minutes"] = late
+			}
+		}
+	}
+	set["check_in"] = checkIn
+	if checkOut != nil {
+		set["check_out"] = checkOut
+	}
+	set["updated_at"] = now
+
+	correction := models.AttendanceCorrection{
+		CorrectedAt: now,
+		CorrectedBy: claims.UserID,
+		Reason:      reason,
+		CheckIn:     &checkIn.At,
+	}
+	if record.CheckIn != nil {
+		correction.PrevCheckIn = &record.CheckIn.At
+	}
+	if record.CheckOut != nil {
+		correction.PrevCheckOut = &record.CheckOut.At
+	}
+	if checkOut != nil {
+		correction.CheckOut = &checkOut.At
+	}
+
+	updated, err := s.attendanceRepo.UpdateAttendanceRecord(ctx,
+		bson.M{"record_id": record.RecordID, "updated_at": record.UpdatedAt, "deleted_at": nil},
+		bson.M{"$set": set, "$push": bson.M{"corrections": correction}})
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("%w: record was changed by someone else, please reload", ports.ErrAttendanceConflict)
+	}
+
+	body := fmt.Sprintf("เวลาทำงานวันที่ %s ของคุณถูกแก้ไขเป็น เข้า %s", day.Format("02/01/2006"), checkIn.At.In(loc).Format("15:04"))
+	if checkOut != nil {
+		body += " ออก " + checkOut.At.In(loc).Format("15:04")
+	}
+	body += "\nเหตุผล: " + reason
+	s.mailAttendanceUser(ctx, user.UserID, "แจ้งแก้ไขเวลาทำงาน", body)
+	return s.toAttendanceRecordDTO(ctx, updated, newLeaveNameCache()), nil
+}
+
+// ---------- สรุปรายเดือน ----------
+
+func (s *attendanceService) GetAttendanceSummary(ctx context.Context, req dto.RequestAttendanceSummary, claims *dto.JWTClaims) ([]dto.AttendanceMonthSummaryDTO, error) {
+	month := time.Date(helpers.Today().Year(), helpers.Today().Month(), 1, 0, 0, 0, 0, time.UTC)
+	if v := strings.TrimSpace(req.Month); v != "" {
+		m, err := time.Parse("2006-01", v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid month: %s (use YYYY-MM)", v)
+		}
+		month = m
+	}
+
+	filter := bson.M{"status": "approved", "deleted_at": nil}
+	if v := strings.TrimSpace(req.DepartmentID); v != "" {
+		filter["department_id"] = v
+	}
+	if v := strings.TrimSpace(req.UserID); v != "" {
+		filter["user_id"] = v
+	}
+	if claims.Role != "admin" {
+		managed, err := s.managedAttendanceDepartments(ctx, claims.UserID)
+		if err != nil {
+			return nil, err
+		}
+		filter["$or"] = []bson.M{{"user_id": claims.UserID}, {"department_id": bson.M{"$in": managed}}}
+	}
+	users, err := s.userRepo.GetUserByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 && strings.TrimSpace(req.UserID) != "" {
+		if strings.TrimSpace(req.UserID) != claims.UserID && claims.Role != "admin" {
+			return nil, ports.ErrAttendanceForbidden
+		}
+		return nil, mongo.ErrNoDocuments
+	}
+	return s.SummarizeAttendanceMonth(ctx, month, users)
+}
+
+// SummarizeAttendanceMonth วันทำงานคิดจากกะของพนักงาน หักวันหยุดและวันก่อนเริ่มงาน
+// ขาดงาน = วันทำงานที่ผ่านไปแล้วที่ไม่ได้ลงเวลาและไม่ได้ลา (ลาครึ่งวันนับขาดครึ่งวัน)
+func (s *attendanceService) SummarizeAttendanceMonth(ctx context.Context, month time.Time, users []*models.User) ([]dto.AttendanceMonthSummaryDTO, error) {
+	monthStart := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	monthEnd := monthStart.AddDate(0, 1, -1)
+	today := helpers.Today()
+	if len(users) == 0 {
+		return []dto.AttendanceMonthSummaryDTO{}, nil
+	}
+
+	userIDs := make([]string, 0, len(users))
+	for _, u := range users {
+		userIDs = append(userIDs, u.UserID)
+	}
+
+	shifts, err := s.loadWorkShifts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	holidays, err := s.holidayNames(ctx, monthStart, monthEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := s.attendanceRepo.GetAllAttendanceRecordsByFilter(ctx, bson.M{
+		"user_id":    bson.M{"$in": userIDs},
+		"work_date":  bson.M{"$gte": monthStart, "$lte": monthEnd},
+		"deleted_at": nil,
+	}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	recordsByUser := make(map[string]map[string]*models.AttendanceRecord)
+	for _, r := range records {
+		if recordsByUser[r.UserID] == nil {
+			recordsByUser[r.UserID] = make(map[string]*models.AttendanceRecord)
+		}
+		recordsByUser[r.UserID][helpers.DayKey(r.WorkDate)] = r
+	}
+
+	// ใบลาที่อนุมัติแล้วย้อนหลังเท่ารอบยกยอด เพื่อคิดวันลาที่เกินวันได้รับค่าจ้าง
+	leaves, err := s.leaveRepo.GetAllLeaveRequestsByFilter(ctx, bson.M{
+		"user_id":    bson.M{"$in": userIDs},
+		"year":       bson.M{"$gte": monthStart.Year() - leaveCarryYears, "$lte": monthStart.Year()},
+		"status":     bson.M{"$in": leaveTakenStatuses},
+		"deleted_at": nil,
+	}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	leavesByUser := make(map[string][]*models.LeaveRequest)
+	for _, r := range leaves {
+		leavesByUser[r.UserID] = append(leavesByUser[r.UserID], r)
+	}
+	policyList, err := s.leaveRepo.GetAllLeavePoliciesByFilter(ctx, bson.M{"deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	policies := leavePolicyMap(policyList)
+
+	names := newLeaveNameCache()
+	out := make([]dto.AttendanceMonthSummaryDTO, 0, len(users))
+	for _, user := range users {
+		shift := resolveWorkShift(shifts, user)
+		row := dto.AttendanceMonthSummaryDTO{
+			Month:          monthStart.Format("2006-01"),
+			UserID:         user.UserID,
+			UserName:       strings.TrimSpace(user.FirstNameTH + " " + user.LastNameTH),
+			EmployeeCode:   user.EmployeeCode,
+			DepartmentID:   user.DepartmentID,
+			DepartmentName: s.attendanceDepartmentName(ctx, names, user.DepartmentID),
+			ShiftName:      shift.Name,
+		}
+		userRecords := recordsByUser[user.UserID]
+		hired := helpers.DateOnly(user.HireDate)
+
+		absent := 0.0
+		for day := monthStart; !day.After(monthEnd); day = day.AddDate(0, 0, 1) {
+			if !user.HireDate.IsZero() && day.Before(hired) {
+				continue
+			}
+			if !isLeaveWorkDay(shift.WorkDays, day) || holidayFor(holidays, user.DepartmentID, day) != "" {
+				continue
+			}
+			row.ScheduledDays++
+			onLeave := 0.0
+			for _, r := range leavesByUser[user.UserID] {
+				if !day.Before(r.StartDate) && !day.After(r.EndDate) {
+					onLeave += leaveDayFraction(r)
+				}
+			}
+			onLeave = math.Min(onLeave, 1)
+			row.LeaveDays += onLeave
+			if !day.Before(today) {
+				continue
+			}
+			row.ElapsedDays++
+			if rec := userRecords[helpers.DayKey(day)]; rec != nil && rec.CheckIn != nil {
+				row.PresentDays++
+				continue
+			}
+			absent += 1 - onLeave
+		}
+		row.AbsentDays = absent
+
+		onTime := 0
+		otApproved, otHoliday, otPending, worked := 0, 0, 0, 0
+		for _, rec := range userRecords {
+			if rec.CheckIn == nil {
+				continue
+			}
+			if !rec.Scheduled {
+				row.HolidayWorkDays++
+			}
+			if rec.LateMinutes > 0 {
+				row.LateCount++
+				row.LateMinutes += rec.LateMinutes
+			} else if rec.Scheduled {
+				onTime++
+			}
+			if rec.EarlyLeaveMinutes > 0 {
+				row.EarlyLeaveCount++
+				row.EarlyLeaveMinutes += rec.EarlyLeaveMinutes
+			}
+			if rec.CheckOut == nil && rec.WorkDate.Before(today) {
+				row.IncompleteDays++
+			}
+			worked += rec.WorkedMinutes
+			switch rec.OTStatus {
+			case models.OTApproved:
+				otApproved += rec.OTApprovedMinutes
+				if !rec.Scheduled {
+					otHoliday += rec.OTApprovedMinutes
+				}
+			case models.OTPending:
+				otPending += rec.OvertimeMinutes
+			}
+			row.Corrections += len(rec.Corrections)
+		}
+		row.WorkedHours = util.Round2(float64(worked) / 60)
+		row.OTApprovedHours = util.Round2(float64(otApproved) / 60)
+		row.OTHolidayHours = util.Round2(float64(otHoliday) / 60)
+		row.OTPendingHours = util.Round2(float64(otPending) / 60)
+		row.UnpaidLeaveDays = util.Round2(unpaidLeaveInMonth(leavesByUser[user.UserID], policies, user, monthStart, monthEnd))
+		if row.ElapsedDays > 0 {
+			row.AttendanceRate = util.Round2((float64(row.ElapsedDays) - absent) / float64(row.ElapsedDays) * 100)
+		}
+		if scheduledPresent := onTime + row.LateCount; scheduledPresent > 0 {
+			row.PunctualityRate = util.Round2(float64(onTime) / float64(scheduledPresent) * 100)
+		}
+		out = append(out, row)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].DepartmentName != out[j].DepartmentName {
+			return out[i].DepartmentName < out[j].DepartmentName
+		}
+		return out[i].EmployeeCode < out[j].EmployeeCode
+	})
+	return out, nil
+}
+
+// unpaidLeaveInMonth วันลาที่เกินวันได้รับค่าจ้าง (รวมยอดยกมา) ที่ตกอยู่ในเดือน
+// ใบลาคร่อมเดือนแบ่งส่วนเกินตามสัดส่วนวันปฏิทินของใบลาในเดือน
+func unpaidLeaveInMonth(requests []*models.LeaveRequest, policies map[string]*models.LeavePolicy, user *models.User, monthStart, monthEnd time.Time) float64 {
+	year := monthStart.Year()
+	startYear := year - leaveCarryYears
+	if !user.HireDate.IsZero() && user.HireDate.Year() > startYear {
+		startYear = user.HireDate.Year()
+	}
+	if startYear > year {
+		startYear = year
+	}
+
+	used := make(map[string]map[int]float64)
+	var current []*models.LeaveRequest
+	for _, r := range requests {
+		if r.Year == year {
+			current = append(current, r)
+			continue
+		}
+		if used[r.LeaveType] == nil {
+			used[r.LeaveType] = make(map[int]float64)
+		}
+		used[r.LeaveType][r.Year] += r.Days
+	}
+	sort.Slice(current, func(i, j int) bool { return current[i].StartDate.Before(current[j].StartDate) })
+
+	unpaid := 0.0
+	taken := make(map[string]float64)
+	allowance := make(map[string]float64)
+	for _, r := range current {
+		allow, ok := allowance[r.LeaveType]
+		if !ok {
+			policy := effectiveLeavePolicy(policies, r.LeaveType, user.PositionID)
+			allow = policy.PaidDays + leaveCarriedOver(policy, user.HireDate, used[r.LeaveType], startYear, year)
+			allowance[r.LeaveType] = allow
+		}
+		before := taken[r.LeaveType]
+		taken[r.LeaveType] += r.Days
+		excess := math.Max(0, taken[r.LeaveType]-allow) - math.Max(0, before-allow)
+		if excess <= 0 || r.EndDate.Before(monthStart) || r.StartDate.After(monthEnd) {
+			continue
+		}
+		from, to := r.StartDate, r.EndDate
+		if from.Before(monthStart) {
+			from = monthStart
+		}
+		if to.After(monthEnd) {
+			to = monthEnd
+		}
+		total := r.EndDate.Sub(r.StartDate).Hours()/24 + 1
+		inMonth := to.Sub(from).Hours()/24 + 1
+		unpaid += excess * inMonth / total
+	}
+	return unpaid
+}
+
+// ---------- helpers ----------
+
+func (s *attendanceService) getAttendanceUser(ctx context.Context, userID string) (*models.User, error) {
+	if userID == "" {
+		return nil, errors.New("user_id is required")
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.DeletedAt != nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	return user, nil
+}
+
+// findOpenRecord รายการที่ลงเวลาเข้าแล้วแต่ยังไม่ลงเวลาออกภายในช่วงที่ยอมรับ
+func (s *attendanceService) findOpenRecord(ctx context.Context, userID string, now time.Time) (*models.AttendanceRecord, error) {
+	return s.attendanceRepo.GetOneAttendanceRecordByFilter(ctx, bson.M{
+		"user_id":     userID,
+		"check_out":   nil,
+		"check_in.at": bson.M{"$gte": now.Add(-attendanceOpenWindow)},
+		"deleted_at":  nil,
+	}, bson.M{})
+}
+
+// newAttendanceRecord รายการใหม่ของวัน พร้อมสำเนากะที่ใช้ ณ วันนั้น
+func (s *attendanceService) newAttendanceRecord(ctx context.Context, user *models.User, workDate time.Time) (*models.AttendanceRecord, error) {
+	shifts, err := s.loadWorkShifts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shift := resolveWorkShift(shifts, user)
+	holidays, err := s.holidayNames(ctx, workDate, workDate)
+	if err != nil {
+		return nil, err
+	}
+	start, end := shiftBounds(shift, workDate)
+	now := time.Now()
+	return &models.AttendanceRecord{
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		WorkDate:          workDate,
+		RecordID:          uuid.NewString(),
+		UserID:            user.UserID,
+		DepartmentID:      user.DepartmentID,
+		ShiftID:           shift.ShiftID,
+		ShiftName:         shift.Name,
+		ShiftStart:        start,
+		ShiftEnd:          end,
+		Scheduled:         isLeaveWorkDay(shift.WorkDays, workDate) && holidayFor(holidays, user.DepartmentID, workDate) == "",
+		BreakMinutes:      shift.BreakMinutes,
+		LateGraceMinutes:  shift.LateGraceMinutes,
+		EarlyGraceMinutes: shift.EarlyGraceMinutes,
+		OTMinMinutes:      shift.OTMinMinutes,
+		Corrections:       []models.AttendanceCorrection{},
+	}, nil
+}
+
+// locatePunch ตรวจพิกัดกับสถานที่ที่ใช้ได้ เลือกสถานที่ใกล้ที่สุดที่อยู่ในรัศมี
+// หน้างานติดตั้งใช้ได้เฉพาะใบงานที่ยังไม่เสร็จ/ยกเลิก
+func (s *attendanceService) locatePunch(ctx context.Context, user *models.User, req dto.AttendancePunchDTO) (*models.AttendancePunch, error) {
+	if err := validateCoordinates(req.Latitude, req.Longitude); err != nil {
+		return nil, err
+	}
+	if req.AccuracyM < 0 || req.AccuracyM > attendanceMaxAccuracyM {
+		return nil, fmt.Errorf("gps accuracy must be within %.0f meters, please retry outdoors", attendanceMaxAccuracyM)
+	}
+	sites, err := s.attendanceRepo.GetAllAttendanceSitesByFilter(ctx, bson.M{"is_active": true, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	jobIDs := make([]string, 0)
+	for _, site := range sites {
+		if site.SiteType == models.SiteInstall && site.JobID != "" {
+			jobIDs = append(jobIDs, site.JobID)
+		}
+	}
+	openJobs := make(map[string]bool, len(jobIDs))
+	if len(jobIDs) > 0 {
+		jobs, err := s.signJobRepo.GetAllSignJobByFilter(ctx, bson.M{
+			"job_id":     bson.M{"$in": jobIDs},
+			"status":     bson.M{"$nin": []string{"done", "cancelled"}},
+			"deleted_at": nil,
+		}, bson.M{"_id": 0, "job_id": 1})
+		if err != nil {
+			return nil, err
+		}
+		for _, j := range jobs {
+			openJobs[j.JobID] = true
+		}
+	}
+
+	var best *models.AttendanceSite
+	bestDist, nearest := 0.0, math.Inf(1)
+	for _, site := range sites {
+		if len(site.Departments) > 0 && !helpers.InSet(user.DepartmentID, site.Departments...) {
+			continue
+		}
+		if site.SiteType == models.SiteInstall && !openJobs[site.JobID] {
+			continue
+		}
+		d := helpers.HaversineMeters(req.Latitude, req.Longitude, site.Latitude, site.Longitude)
+		nearest = math.Min(nearest, d)
+		if d <= site.RadiusM && (best == nil || d < bestDist) {
+			best, bestDist = site, d
+		}
+	}
+	if best == nil {
+		if math.IsInf(nearest, 1) {
+			return nil, fmt.Errorf("%w: no attendance site is configured for you", ports.ErrAttendanceOutsideSite)
+		}
+		return nil, fmt.Errorf("%w: nearest site is %.0f meters away", ports.ErrAttendanceOutsideSite, nearest)
+	}
+	return &models.AttendancePunch{
+		Source:    models.PunchGPS,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		AccuracyM: req.AccuracyM,
+		SiteID:    best.SiteID,
+		SiteName:  best.Name,
+		DistanceM: math.Round(bestDist),
+		Note:      strings.TrimSpace(req.Note),
+	}, nil
+}
+
+func (s *attendanceService) loadWorkShifts(ctx context.Context) ([]*models.WorkShift, error) {
+	return s.attendanceRepo.GetAllWorkShiftsByFilter(ctx, bson.M{"deleted_at": nil}, bson.M{})
+}
+
+// holidayNames วันหยุดในช่วง key = "YYYY-MM-DD|department_id" (แผนกว่าง = ทั้งบริษัท)
+func (s *attendanceService) holidayNames(ctx context.Context, start, end time.Time) (map[string]string, error) {
+	holidays, err := s.capacityRepo.GetAllHolidaysByFilter(ctx, bson.M{
+		"date":       bson.M{"$gte": start, "$lte": end},
+		"deleted_at": nil,
+	}, bson.M{"_id": 0, "date": 1, "name": 1, "department_id": 1})
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]string, len(holidays))
+	for _, h := range holidays {
+		out[helpers.DayKey(h.Date)+"|"+h.DepartmentID] = h.Name
+	}
+	return out, nil
+}
+
+func holidayFor(holidays map[string]string, departmentID string, day time.Time) string {
+	if name, ok := holidays[helpers.DayKey(day)+"|"]; ok {
+		return name
+	}
+	if departmentID != "" {
+		return holidays[helpers.DayKey(day)+"|"+departmentID]
+	}
+	return ""
+}
+
+func (s *attendanceService) managedAttendanceDepartments(ctx context.Context, userID string) ([]string, error) {
+	departments, err := s.departmentRepo.GetAllDepartmentByFilter(ctx, bson.M{"manager_id": userID, "deleted_at": nil}, bson.M{"department_id": 1})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(departments))
+	for _, d := range departments {
+		out = append(out, d.DepartmentID)
+	}
+	return out, nil
+}
+
+// canManageAttendance admin หรือผู้จัดการแผนกของพนักงาน (ไม่แก้ไข/อนุมัติของตนเอง)
+func (s *attendanceService) canManageAttendance(ctx context.Context, departmentID, userID string, claims *dto.JWTClaims) (bool, error) {
+	if claims.Role == "admin" {
+		return true, nil
+	}
+	if userID == claims.UserID || departmentID == "" {
+		return false, nil
+	}
+	dept, err := s.departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{"department_id": departmentID, "deleted_at": nil}, bson.M{"_id": 0, "manager_id": 1})
+	if err != nil {
+		return false, err
+	}
+	return dept != nil && dept.ManagerID == claims.UserID, nil
+}
+
+func (s *attendanceService) mailAttendanceUser(ctx context.Context, userID, subject, body string) {
+	if s.config.Email.Host == "" || userID == "" {
+		return
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil || strings.TrimSpace(user.Email) == "" {
+		return
+	}
+	emailCfg := util.EmailConfig{
+		Host:     s.config.Email.Host,
+		Port:     s.config.Email.Port,
+		Username: s.config.Email.Username,
+		Password: s.config.Email.Password,
+		From:     s.config.Email.From,
+	}
+	if err := util.SendMail(emailCfg, user.Email, subject, fmt.Sprintf("เรียนคุณ %s\n\n%s", user.FirstNameTH, body)); err != nil {
+		log.Println("Error sending attendance email:", err)
+	}
+}
+
+func (s *attendanceService) attendanceUserName(ctx context.Context, cache *leaveNameCache, userID string) string {
+	if userID == "" {
+		return ""
+	}
+	if name, ok := cache.users[userID]; ok {
+		return name
+	}
+	name := "ไม่พบผู้ใช้"
+	if user, _ := s.userRepo.GetByID(ctx, userID); user != nil {
+		name = strings.TrimSpace(user.FirstNameTH + " " + user.LastNameTH)
+	}
+	cache.users[userID] = name
+	return name
+}
+
+func (s *attendanceService) attendanceDepartmentName(ctx context.Context, cache *leaveNameCache, departmentID string) string {
+	if departmentID == "" {
+		return ""
+	}
+	if name, ok := cache.departments[departmentID]; ok {
+		return name
+	}
+	name := "ไม่พบแผนก"
+	if dept, _ := s.departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{"department_id": departmentID, "deleted_at": nil}, bson.M{"_id": 0, "department_name": 1}); dept != nil {
+		name = dept.DepartmentName
+	}
+	cache.departments[departmentID] = name
+	return name
+}
+
+func (s *attendanceService) toAttendanceRecordDTO(ctx context.Context, r *models.AttendanceRecord, names *leaveNameCache) *dto.AttendanceRecordDTO {
+	out := &dto.AttendanceRecordDTO{
+		RecordID:          r.RecordID,
+		UserID:            r.UserID,
+		UserName:          s.attendanceUserName(ctx, names, r.UserID),
+		DepartmentID:      r.DepartmentID,
+		DepartmentName:    s.attendanceDepartmentName(ctx, names, r.DepartmentID),
+		WorkDate:          helpers.DayKey(r.WorkDate),
+		ShiftName:         r.ShiftName,
+		ShiftStart:        r.ShiftStart,
+		ShiftEnd:          r.ShiftEnd,
+		Scheduled:         r.Scheduled,
+		CheckIn:           toAttendancePunchInfo(r.CheckIn),
+		CheckOut:          toAttendancePunchInfo(r.CheckOut),
+		WorkedMinutes:     r.WorkedMinutes,
+		LateMinutes:       r.LateMinutes,
+		EarlyLeaveMinutes: r.EarlyLeaveMinutes,
+		OvertimeMinutes:   r.OvertimeMinutes,
+		OTStatus:          r.OTStatus,
+		OTApprovedMinutes: r.OTApprovedMinutes,
+		OTReason:          r.OTReason,
+		OTReviewedBy:      r.OTReviewedBy,
+		OTReviewNote:      r.OTReviewNote,
+		Corrections:       make([]dto.AttendanceCorrection, 0, len(r.Corrections)),
+	}
+	for _, c := range r.Corrections {
+		out.Corrections = append(out.Corrections, dto.AttendanceCorrection{
+			CorrectedAt:     c.CorrectedAt,
+			CorrectedBy:     c.CorrectedBy,
+			CorrectedByName: s.attendanceUserName(ctx, names, c.CorrectedBy),
+			Reason:          c.Reason,
+			PrevCheckIn:     c.PrevCheckIn,
+			PrevCheckOut:    c.PrevCheckOut,
+			CheckIn:         c.CheckIn,
+			CheckOut:        c.CheckOut,
+		})
+	}
+	return out
+}
+
+func toAttendancePunchInfo(p *models.AttendancePunch) *dto.AttendancePunchInfo {
+	if p == nil {
+		return nil
+	}
+	return &dto.AttendancePunchInfo{
+		At:        p.At,
+		Source:    p.Source,
+		SiteID:    p.SiteID,
+		SiteName:  p.SiteName,
+		DistanceM: p.DistanceM,
+		AccuracyM: p.AccuracyM,
+		Note:      p.Note,
+	}
+}
+
+func toAttendanceSiteDTO(site *models.AttendanceSite) dto.AttendanceSiteDTO {
+	return dto.AttendanceSiteDTO{
+		SiteID:      site.SiteID,
+		Name:        site.Name,
+		SiteType:    site.SiteType,
+		JobID:       site.JobID,
+		Latitude:    site.Latitude,
+		Longitude:   site.Longitude,
+		RadiusM:     site.RadiusM,
+		Departments: site.Departments,
+		IsActive:    site.IsActive,
+		UpdatedAt:   site.UpdatedAt,
+	}
+}
+
+func toWorkShiftDTO(shift *models.WorkShift) dto.WorkShiftDTO {
+	out := dto.WorkShiftDTO{
+		ShiftID:           shift.ShiftID,
+		Name:              shift.Name,
+		StartTime:         shift.StartTime,
+		EndTime:           shift.EndTime,
+		BreakMinutes:      shift.BreakMinutes,
+		LateGraceMinutes:  shift.LateGraceMinutes,
+		EarlyGraceMinutes: shift.EarlyGraceMinutes,
+		OTMinMinutes:      shift.OTMinMinutes,
+		WorkDays:          shift.WorkDays,
+		Departments:       shift.Departments,
+		Users:             shift.Users,
+		IsDefault:         shift.IsDefault,
+	}
+	if !shift.UpdatedAt.IsZero() {
+		updatedAt := shift.UpdatedAt
+		out.UpdatedAt = &updatedAt
+	}
+	return out
+}
+
+// attendanceMetricsSet ค่าที่คำนวณใหม่จากเวลาเข้า-ออก ตามกะที่บันทึกไว้ในรายการ
+func attendanceMetricsSet(r *models.AttendanceRecord, checkIn, checkOut time.Time) bson.M {
+	res := helpers.ComputeAttendance(helpers.AttendanceRule{
+		ShiftStart:        r.ShiftStart,
+		ShiftEnd:          r.ShiftEnd,
+		BreakMinutes:      r.BreakMinutes,
+		LateGraceMinutes:  r.LateGraceMinutes,
+		EarlyGraceMinutes: r.EarlyGraceMinutes,
+		OTMinMinutes:      r.OTMinMinutes,
+		Scheduled:         r.Scheduled,
+	}, checkIn, checkOut)
+	status := models.OTNone
+	if res.Overtime > 0 {
+		status = models.OTPending
+	}
+	return bson.M{
+		"worked_minutes":      res.Worked,
+		"late_minutes":        res.Late,
+		"early_leave_minutes": res.EarlyLeave,
+		"overtime_minutes":    res.Overtime,
+		"ot_status":           status,
+		"ot_approved_minutes": 0,
+	}
+}
+
+// resolveWorkShift กะรายคนก่อน แล้วจึงกะของแผนก กะเริ่มต้นของบริษัท และกะมาตรฐานของระบบ
+func resolveWorkShift(shifts []*models.WorkShift, user *models.User) models.WorkShift {
+	var byDept, byDefault *models.WorkShift
+	for _, sh := range shifts {
+		if helpers.InSet(user.UserID, sh.Users...) {
+			return *sh
+		}
+		if byDept == nil && user.DepartmentID != "" && helpers.InSet(user.DepartmentID, sh.Departments...) {
+			byDept = sh
+		}
+		if byDefault == nil && sh.IsDefault {
+			byDefault = sh
+		}
+	}
+	if byDept != nil {
+		return *byDept
+	}
+	if byDefault != nil {
+		return *byDefault
+	}
+	return builtinWorkShift()
+}
+
+func builtinWorkShift() models.WorkShift {
+	return models.WorkShift{
+		Name:             "กะมาตรฐาน",
+		StartTime:        "08:30",
+		EndTime:          "17:30",
+		BreakMinutes:     60,
+		LateGraceMinutes: 5,
+		OTMinMinutes:     30,
+		WorkDays:         defaultWorkDays,
+		IsDefault:        true,
+	}
+}
+
+// shiftBounds เวลาเริ่ม-เลิกกะของวันทำงาน (เวลาเลิกน้อยกว่าเวลาเข้า = เลิกวันถัดไป)
+func shiftBounds(shift models.WorkShift, workDate time.Time) (time.Time, time.Time) {
+	loc := helpers.BangkokLocation()
+	startMin, _ := parseClock("start_time", shift.StartTime)
+	endMin, _ := parseClock("end_time", shift.EndTime)
+	base := time.Date(workDate.Year(), workDate.Month(), workDate.Day(), 0, 0, 0, 0, loc)
+	start := base.Add(time.Duration(startMin) * time.Minute)
+	end := base.Add(time.Duration(endMin) * time.Minute)
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end
+}
+
+// clockOnDate เวลา HH:MM ของวันที่ (เวลาไทย)
+func clockOnDate(field, v string, day time.Time) (time.Time, error) {
+	minutes, err := parseClock(field, v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	base := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, helpers.BangkokLocation())
+	return base.Add(time.Duration(minutes) * time.Minute), nil
+}
+
+// parseClock แปลง HH:MM เป็นนาทีนับจากเที่ยงคืน
+func parseClock(field, v string) (int, error) {
+	parts := strings.Split(strings.TrimSpace(v), ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid %s: %s (use HH:MM)", field, v)
+	}
+	h, errH := strconv.Atoi(parts[0])
+	m, errM := strconv.Atoi(parts[1])
+	if errH != nil || errM != nil || h < 0 || h > 23 || m < 0 || m > 59 {
+		return 0, fmt.Errorf("invalid %s: %s (use HH:MM)", field, v)
+	}
+	return h*60 + m, nil
+}
+
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+func validateCoordinates(lat, lng float64) error {
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 || (lat == 0 && lng == 0) {
+		return errors.New("invalid latitude/longitude")
+	}
+	return nil
+}
+
+func uniqueStrings(values []string) []string {
+	out := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v != "" && !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
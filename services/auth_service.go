@@ -31,16 +31,17 @@ func (s *authService) Login(ctx context.Context, user dto.RequestLogin) (string,
 	}
 
 	projection := bson.M{
-		"user_id":       1,
-		"email":         1,
-		"password":      1,
-		"role":          1,
-		"status":        1,
-		"employee_code": 1,
-		"title_th":      1,
-		"first_name_th": 1,
-		"last_name_th":  1,
-		"avatar":        1,
+		"user_id":           1,
+		"email":             1,
+		"password":          1,
+		"role":              1,
+		"status":            1,
+		"employee_code":     1,
+		"title_th":          1,
+		"first_name_th":     1,
+		"last_name_th":      1,
+		"avatar":            1,
+		"access_revoked_at": 1,
 	}
 
 	userData, errOnGetUserData := s.userRepo.GetUserByFilter(ctx, filter, projection)
@@ -58,6 +59,11 @@ func (s *authService) Login(ctx context.Context, user dto.RequestLogin) (string,
 		return "", fmt.Errorf("invalid password")
 	}
 
+	// พนักงานที่ offboarding แล้วเข้าระบบไม่ได้
+	if userData[0].AccessRevokedAt != nil {
+		return "", fmt.Errorf("access has been revoked")
+	}
+
 	claims := dto.JWTClaims{
 		UserID:       userData[0].UserID,
 		Email:        userData[0].Email,
@@ -0,0 +1,805 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/config"
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/pkg/util"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	defaultBoardDoneDays  = 14
+	defaultBoardCardLimit = 50
+	maxCalendarDays       = 93
+)
+
+// คอลัมน์ของบอร์ดแบบจัดกลุ่มตามสถานะ (เรียงตามลำดับการทำงาน)
+var boardStatusColumns = []struct{ Key, Title string }{
+	{"todo", "รอดำเนินการ"},
+	{"in_progress", "กำลังดำเนินการ"},
+	{"done", "เสร็จแล้ว"},
+}
+
+type boardService struct {
+	config         config.Config
+	boardRepo      ports.BoardRepository
+	taskRepo       ports.TaskRepository
+	taskSvc        ports.TaskService
+	departmentRepo ports.DepartmentRepository
+	capacityRepo   ports.CapacityRepository
+}
+
+func NewBoardService(cfg config.Config, boardRepo ports.BoardRepository, taskRepo ports.TaskRepository, taskSvc ports.TaskService, departmentRepo ports.DepartmentRepository, capacityRepo ports.CapacityRepository) ports.BoardService {
+	return &boardService{config: cfg, boardRepo: boardRepo, taskRepo: taskRepo, taskSvc: taskSvc, departmentRepo: departmentRepo, capacityRepo: capacityRepo}
+}
+
+func (s *boardService) GetBoard(ctx context.Context, req dto.RequestTaskBoard, claims *dto.JWTClaims) (*dto.TaskBoardDTO, error) {
+	groupBy, err := normalizeBoardGroupBy(req.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+	doneDays := req.DoneDays
+	if doneDays <= 0 {
+		doneDays = defaultBoardDoneDays
+	}
+	cardLimit := req.CardLimit
+	if cardLimit <= 0 {
+		cardLimit = defaultBoardCardLimit
+	}
+
+	conds := bson.A{bson.M{"deleted_at": nil}}
+	if v := strings.TrimSpace(req.DepartmentID); v != "" {
+		conds = append(conds, bson.M{"department_id": v})
+	}
+	if v := strings.TrimSpace(req.WorkFlowID); v != "" {
+		conds = append(conds, bson.M{"workflow_id": v})
+	}
+	if v := strings.TrimSpace(req.Assignee); v != "" {
+		conds = append(conds, bson.M{"assignee": v})
+	}
+	if v := strings.TrimSpace(req.Search); v != "" {
+		re := primitive.Regex{Pattern: regexp.QuoteMeta(v), Options: "i"}
+		conds = append(conds, bson.M{"$or": bson.A{
+			bson.M{"project_name": re},
+			bson.M{"job_name": re},
+			bson.M{"assignee_name": re},
+			bson.M{"assignee_nickname": re},
+		}})
+	}
+	if groupBy == "status" {
+		// คอลัมน์ done แสดงเฉพาะงานที่เพิ่งปิด
+		conds = append(conds, bson.M{"$or": bson.A{
+			bson.M{"status": bson.M{"$in": bson.A{"todo", "in_progress"}}},
+			bson.M{"status": "done", "updated_at": bson.M{"$gte": time.Now().AddDate(0, 0, -doneDays)}},
+		}})
+	} else {
+		conds = append(conds, bson.M{"status": bson.M{"$in": bson.A{"todo", "in_progress"}}})
+	}
+
+	tasks, err := s.taskRepo.GetAllTaskByFilter(ctx, bson.M{"$and": conds}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	sortBoardTasks(tasks)
+
+	columns := make([]dto.BoardColumnDTO, 0)
+	index := make(map[string]int)
+	addColumn := func(key, title string) {
+		if _, ok := index[key]; ok {
+			return
+		}
+		index[key] = len(columns)
+		columns = append(columns, dto.BoardColumnDTO{Key: key, Title: title, Tasks: []dto.TaskCardDTO{}})
+	}
+
+	switch groupBy {
+	case "status":
+		for _, c := range boardStatusColumns {
+			addColumn(c.Key, c.Title)
+		}
+	case "step":
+		// คอลัมน์ตามชื่อ step เรียงตามลำดับใน workflow (รวมคอลัมน์ที่ยังไม่มีงาน)
+		orders := make(map[string]int)
+		for _, t := range tasks {
+			for _, st := range t.AppliedWorkflow.Steps {
+				if o, ok := orders[st.StepName]; !ok || st.Order < o {
+					orders[st.StepName] = st.Order
+				}
+			}
+		}
+		names := make([]string, 0, len(orders))
+		for name := range orders {
+			names = append(names, name)
+		}
+		sort.SliceStable(names, func(i, j int) bool {
+			if orders[names[i]] != orders[names[j]] {
+				return orders[names[i]] < orders[names[j]]
+			}
+			return names[i] < names[j]
+		})
+		for _, name := range names {
+			addColumn(name, name)
+		}
+	}
+
+	for _, t := range tasks {
+		key, title := boardColumnOf(t, groupBy)
+		addColumn(key, title)
+		col := &columns[index[key]]
+		col.Count++
+		if len(col.Tasks) < cardLimit {
+			col.Tasks = append(col.Tasks, toTaskCard(t))
+		}
+	}
+	if groupBy == "assignee" {
+		sort.SliceStable(columns, func(i, j int) bool { return columns[i].Title < columns[j].Title })
+	}
+
+	limits, err := s.boardRepo.GetAllWIPLimitsByFilter(ctx, bson.M{"group_by": groupBy, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range columns {
+		if l := matchWIPLimit(limits, columns[i].Key, strings.TrimSpace(req.WorkFlowID), strings.TrimSpace(req.DepartmentID)); l != nil {
+			columns[i].WIPLimit = l.Limit
+			columns[i].OverLimit = columns[i].Count > l.Limit
+		}
+	}
+
+	return &dto.TaskBoardDTO{GroupBy: groupBy, Total: len(tasks), Columns: columns}, nil
+}
+
+// MoveCard ย้ายการ์ดไปคอลัมน์ปลายทางโดยเปลี่ยนสถานะ/ผู้รับผิดชอบ step ผ่าน task service
+// (ตรวจสิทธิ์, prerequisite, บันทึก activity, สถิติ และแจ้งเตือนตามปกติ)
+//   - status: todo/in_progress/done ของ step ปัจจุบัน
+//   - step: ปิด step ที่กำลังทำซึ่งขวางอยู่แล้วเริ่ม step ปลายทาง (ย้อนกลับไม่ได้)
+//   - assignee: มอบ step ปัจจุบันให้ผู้ใช้ปลายทาง
+func (s *boardService) MoveCard(ctx context.Context, req dto.BoardMoveDTO, claims *dto.JWTClaims) (*dto.TaskCardDTO, error) {
+	groupBy, err := normalizeBoardGroupBy(req.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+	toColumn := strings.TrimSpace(req.ToColumn)
+	if toColumn == "" {
+		return nil, errors.New("to_column is required")
+	}
+
+	task, err := s.taskRepo.GetOneTasksByFilter(ctx, bson.M{"task_id": req.TaskID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	if task.Status == "cancelled" {
+		return nil, errors.New("task has been cancelled")
+	}
+	if key, _ := boardColumnOf(task, groupBy); key == toColumn {
+		return nil, errors.New("task is already in this column")
+	}
+
+	if err := s.checkWIPLimit(ctx, task, groupBy, toColumn, req.Force && claims.Role == "admin"); err != nil {
+		return nil, err
+	}
+
+	switch groupBy {
+	case "status":
+		err = s.moveByStatus(ctx, task, toColumn, strings.TrimSpace(req.StepID), claims)
+	case "step":
+		err = s.moveByStep(ctx, task, toColumn, strings.TrimSpace(req.StepID), claims)
+	case "assignee":
+		step := boardCurrentStep(task, strings.TrimSpace(req.StepID))
+		if step == nil {
+			return nil, errors.New("task has no open step to assign")
+		}
+		err = s.taskSvc.AssignStep(ctx, task.TaskID, step.StepID, dto.AssignStepRequest{Assignee: toColumn}, claims)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.taskRepo.GetOneTasksByFilter(ctx, bson.M{"task_id": task.TaskID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	card := toTaskCard(updated)
+	return &card, nil
+}
+
+func (s *boardService) moveByStatus(ctx context.Context, task *models.Tasks, toStatus, stepID string, claims *dto.JWTClaims) error {
+	steps := task.AppliedWorkflow.Steps
+	var target *models.TaskWorkflowStep
+
+	switch toStatus {
+	case "in_progress":
+		target = findTaskStep(steps, stepID)
+		if target == nil {
+			if ready := helpers.ReadySteps(steps); len(ready) > 0 {
+				target = &ready[0]
+			} else if task.Status == "done" {
+				target = lastClosedStep(steps)
+			}
+		}
+	case "todo":
+		target = findTaskStep(steps, stepID)
+		if target == nil {
+			running := stepsWithStatus(steps, "in_progress")
+			switch {
+			case len(running) == 1:
+				target = &running[0]
+			case len(running) > 1:
+				return errors.New("task has several steps in progress, step_id is required")
+			case task.Status == "done":
+				target = lastClosedStep(steps)
+			}
+		}
+	case "done":
+		open := append(stepsWithStatus(steps, "in_progress"), stepsWithStatus(steps, "todo")...)
+		if len(open) > 1 {
+			names := make([]string, 0, len(open))
+			for _, st := range open {
+				names = append(names, st.StepName)
+			}
+			return fmt.Errorf("task still has %d open steps: %s", len(open), strings.Join(names, ", "))
+		}
+		if len(open) == 1 {
+			target = &open[0]
+		}
+	default:
+		return fmt.Errorf("invalid column: %s (allow: todo|in_progress|done)", toStatus)
+	}
+	if target == nil {
+		return errors.New("no step can be moved to this column")
+	}
+
+	status := toStatus
+	return s.taskSvc.UpdateStepStatus(ctx, task.TaskID, target.StepID, dto.UpdateStepStatusNoteRequest{Status: &status}, claims)
+}
+
+func (s *boardService) moveByStep(ctx context.Context, task *models.Tasks, stepName, stepID string, claims *dto.JWTClaims) error {
+	steps := task.AppliedWorkflow.Steps
+	target := findTaskStep(steps, stepID)
+	if target == nil {
+		for i := range steps {
+			if steps[i].StepName == stepName {
+				target = &steps[i]
+				break
+			}
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("step %s not found in this task", stepName)
+	}
+	switch target.Status {
+	case "done", "skip":
+		return errors.New("cannot move a task back to a closed step")
+	case "in_progress":
+		return nil
+	}
+
+	// step ที่ขวางอยู่ต้องกำลังทำ จึงปิดให้ได้ (step ที่ยังไม่เริ่มต้องทำก่อน)
+	blocking := blockingSteps(steps, *target)
+	for _, st := range blocking {
+		if st.Status != "in_progress" {
+			return fmt.Errorf("step %s has not been started yet", st.StepName)
+		}
+	}
+	done := "done"
+	for _, st := range blocking {
+		if err := s.taskSvc.UpdateStepStatus(ctx, task.TaskID, st.StepID, dto.UpdateStepStatusNoteRequest{Status: &done}, claims); err != nil {
+			return err
+		}
+	}
+	start := "in_progress"
+	return s.taskSvc.UpdateStepStatus(ctx, task.TaskID, target.StepID, dto.UpdateStepStatusNoteRequest{Status: &start}, claims)
+}
+
+// checkWIPLimit ตรวจว่าคอลัมน์ปลายทางยังรับงานได้ (ไม่ใช้กับคอลัมน์ done)
+func (s *boardService) checkWIPLimit(ctx context.Context, task *models.Tasks, groupBy, toColumn string, force bool) error {
+	if force || (groupBy == "status" && toColumn == "done") {
+		return nil
+	}
+	limits, err := s.boardRepo.GetAllWIPLimitsByFilter(ctx, bson.M{"group_by": groupBy, "column_key": toColumn, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return err
+	}
+	limit := matchWIPLimit(limits, toColumn, task.WorkFlowID, task.Department)
+	if limit == nil {
+		return nil
+	}
+
+	filter := bson.M{
+		"deleted_at": nil,
+		"task_id":    bson.M{"$ne": task.TaskID},
+		"status":     bson.M{"$in": bson.A{"todo", "in_progress"}},
+	}
+	if limit.WorkFlowID != "" {
+		filter["workflow_id"] = limit.WorkFlowID
+	}
+	if limit.DepartmentID != "" {
+		filter["department_id"] = limit.DepartmentID
+	}
+	tasks, err := s.taskRepo.GetAllTaskByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return err
+	}
+	count := 0
+	for _, t := range tasks {
+		if key, _ := boardColumnOf(t, groupBy); key == toColumn {
+			count++
+		}
+	}
+	if count >= limit.Limit {
+		return fmt.Errorf("%w: %s (%d/%d)", ports.ErrBoardWIPLimit, toColumn, count, limit.Limit)
+	}
+	return nil
+}
+
+// UpsertWIPLimit ตั้ง/ยกเลิก WIP limit ของคอลัมน์ (admin หรือผู้จัดการแผนกที่ระบุ)
+func (s *boardService) UpsertWIPLimit(ctx context.Context, req dto.UpsertBoardWIPLimitDTO, claims *dto.JWTClaims) error {
+	groupBy, err := normalizeBoardGroupBy(req.GroupBy)
+	if err != nil {
+		return err
+	}
+	columnKey := strings.TrimSpace(req.ColumnKey)
+	if columnKey == "" {
+		return errors.New("column_key is required")
+	}
+	if req.Limit < 0 {
+		return errors.New("limit must be >= 0")
+	}
+	departmentID := strings.TrimSpace(req.DepartmentID)
+	workflowID := strings.TrimSpace(req.WorkFlowID)
+
+	if claims.Role != "admin" {
+		if departmentID == "" {
+			return ports.ErrBoardForbidden
+		}
+		dept, err := s.departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{"department_id": departmentID, "manager_id": claims.UserID, "deleted_at": nil}, bson.M{"department_id": 1})
+		if err != nil && err != mongo.ErrNoDocuments {
+			return err
+		}
+		if dept == nil {
+			return ports.ErrBoardForbidden
+		}
+	}
+
+	existing, err := s.boardRepo.GetOneWIPLimitByFilter(ctx, bson.M{
+		"group_by":      groupBy,
+		"column_key":    columnKey,
+		"workflow_id":   optionalScope(workflowID),
+		"department_id": optionalScope(departmentID),
+		"deleted_at":    nil,
+	}, bson.M{})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if existing != nil {
+		if req.Limit == 0 {
+			return s.boardRepo.SoftDeleteWIPLimitByID(ctx, existing.LimitID)
+		}
+		existing.Limit = req.Limit
+		existing.UpdatedBy = claims.UserID
+		existing.UpdatedAt = now
+		updated, err := s.boardRepo.UpdateWIPLimitByID(ctx, existing.LimitID, *existing)
+		if err != nil {
+			return err
+		}
+		if updated == nil {
+			return mongo.ErrNoDocuments
+		}
+		return nil
+	}
+	if req.Limit == 0 {
+		return nil
+	}
+
+	return s.boardRepo.CreateWIPLimit(ctx, models.BoardWIPLimit{
+		LimitID:      uuid.NewString(),
+		GroupBy:      groupBy,
+		ColumnKey:    columnKey,
+		WorkFlowID:   workflowID,
+		DepartmentID: departmentID,
+		Limit:        req.Limit,
+		UpdatedBy:    claims.UserID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	})
+}
+
+func (s *boardService) ListWIPLimits(ctx context.Context, req dto.RequestListBoardWIPLimits, claims *dto.JWTClaims) ([]dto.BoardWIPLimitDTO, error) {
+	filter := bson.M{"deleted_at": nil}
+	if v := strings.TrimSpace(req.GroupBy); v != "" {
+		filter["group_by"] = strings.ToLower(v)
+	}
+	if v := strings.TrimSpace(req.WorkFlowID); v != "" {
+		filter["workflow_id"] = bson.M{"$in": bson.A{"", nil, v}}
+	}
+	if v := strings.TrimSpace(req.DepartmentID); v != "" {
+		filter["department_id"] = bson.M{"$in": bson.A{"", nil, v}}
+	}
+	limits, err := s.boardRepo.GetAllWIPLimitsByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]dto.BoardWIPLimitDTO, 0, len(limits))
+	for _, l := range limits {
+		out = append(out, dto.BoardWIPLimitDTO{
+			UpdatedAt:    l.UpdatedAt,
+			LimitID:      l.LimitID,
+			GroupBy:      l.GroupBy,
+			ColumnKey:    l.ColumnKey,
+			WorkFlowID:   l.WorkFlowID,
+			DepartmentID: l.DepartmentID,
+			Limit:        l.Limit,
+			UpdatedBy:    l.UpdatedBy,
+		})
+	}
+	return out, nil
+}
+
+// GetCalendar งานและ step ในช่วงวันที่ สำหรับมุมมองรายสัปดาห์/รายเดือน พร้อมวันหยุดและจำนวนต่อวัน
+func (s *boardService) GetCalendar(ctx context.Context, req dto.RequestTaskCalendar, claims *dto.JWTClaims) (*dto.TaskCalendarDTO, error) {
+	today := helpers.Today()
+	from := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, -1)
+	var err error
+	if v := strings.TrimSpace(req.From); v != "" {
+		if from, err = time.Parse("2006-01-02", v); err != nil {
+			return nil, errors.New("invalid from date, use YYYY-MM-DD")
+		}
+	}
+	if v := strings.TrimSpace(req.To); v != "" {
+		if to, err = time.Parse("2006-01-02", v); err != nil {
+			return nil, errors.New("invalid to date, use YYYY-MM-DD")
+		}
+	}
+	if to.Before(from) {
+		return nil, errors.New("to must be on or after from")
+	}
+	if int(to.Sub(from).Hours()/24)+1 > maxCalendarDays {
+		return nil, fmt.Errorf("date range must not exceed %d days", maxCalendarDays)
+	}
+	rangeEnd := to.AddDate(0, 0, 1) // ไม่รวม
+
+	filter := bson.M{
+		"deleted_at": nil,
+		"status":     bson.M{"$ne": "cancelled"},
+		"start_date": bson.M{"$lt": rangeEnd},
+		"end_date":   bson.M{"$gte": from},
+	}
+	if v := strings.TrimSpace(req.DepartmentID); v != "" {
+		filter["department_id"] = v
+	}
+	if v := strings.TrimSpace(req.WorkFlowID); v != "" {
+		filter["workflow_id"] = v
+	}
+	assignee := strings.TrimSpace(req.Assignee)
+	if assignee != "" {
+		filter["$or"] = bson.A{
+			bson.M{"assignee": assignee},
+			bson.M{"applied_workflow.steps.assignee": assignee},
+		}
+	}
+	tasks, err := s.taskRepo.GetAllTaskByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	sortBoardTasks(tasks)
+
+	includeSteps := req.IncludeSteps == nil || *req.IncludeSteps
+	now := time.Now()
+	events := make([]dto.CalendarEventDTO, 0, len(tasks))
+	for _, t := range tasks {
+		events = append(events, dto.CalendarEventDTO{
+			Start:        helpers.DateOnly(t.StartDate),
+			End:          helpers.DateOnly(t.EndDate),
+			Type:         "task",
+			AllDay:       true,
+			Title:        t.JobName,
+			TaskID:       t.TaskID,
+			JobID:        t.JobID,
+			Status:       t.Status,
+			Assignee:     t.Assignee,
+			AssigneeName: t.AssigneeName,
+			Department:   t.Department,
+			SLAStatus:    t.SLAStatus,
+		})
+		if !includeSteps {
+			continue
+		}
+		for _, st := range t.AppliedWorkflow.Steps {
+			if st.StartedAt == nil {
+				continue
+			}
+			owner := stepOwner(t, st)
+			if assignee != "" && owner != assignee {
+				continue
+			}
+			end := now
+			switch {
+			case st.CompletedAt != nil:
+				end = *st.CompletedAt
+			case st.SLADueAt != nil && st.SLADueAt.After(now):
+				end = *st.SLADueAt
+			}
+			if !st.StartedAt.Before(rangeEnd) || end.Before(from) {
+				continue
+			}
+			events = append(events, dto.CalendarEventDTO{
+				Start:        *st.StartedAt,
+				End:          end,
+				Type:         "step",
+				Title:        fmt.Sprintf("%s: %s", t.JobName, st.StepName),
+				TaskID:       t.TaskID,
+				StepID:       st.StepID,
+				JobID:        t.JobID,
+				Status:       st.Status,
+				Assignee:     owner,
+				AssigneeName: stepAssigneeName(t, st),
+				Department:   st.Department,
+				SLAStatus:    st.SLAStatus,
+			})
+		}
+	}
+
+	holidayFilter := bson.M{"deleted_at": nil, "date": bson.M{"$gte": from, "$lt": rangeEnd}}
+	if v := strings.TrimSpace(req.DepartmentID); v != "" {
+		holidayFilter["department_id"] = bson.M{"$in": bson.A{"", nil, v}}
+	}
+	holidays, err := s.capacityRepo.GetAllHolidaysByFilter(ctx, holidayFilter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	holidayDays := make(map[string]bool, len(holidays))
+	for _, h := range holidays {
+		holidayDays[helpers.DayKey(h.Date)] = true
+		events = append(events, dto.CalendarEventDTO{
+			Start:      helpers.DateOnly(h.Date),
+			End:        helpers.DateOnly(h.Date),
+			Type:       "holiday",
+			AllDay:     true,
+			Title:      h.Name,
+			Department: h.DepartmentID,
+		})
+	}
+
+	days := make([]dto.CalendarDayDTO, 0, maxCalendarDays)
+	for d := from; d.Before(rangeEnd); d = d.AddDate(0, 0, 1) {
+		day := dto.CalendarDayDTO{Date: helpers.DayKey(d), IsHoliday: holidayDays[helpers.DayKey(d)]}
+		next := d.AddDate(0, 0, 1)
+		for _, e := range events {
+			if e.Start.Before(next) && !e.End.Before(d) {
+				switch e.Type {
+				case "task":
+					day.Tasks++
+				case "step":
+					day.Steps++
+				}
+			}
+		}
+		days = append(days, day)
+	}
+
+	return &dto.TaskCalendarDTO{From: helpers.DayKey(from), To: helpers.DayKey(to), Events: events, Days: days}, nil
+}
+
+func normalizeBoardGroupBy(v string) (string, error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	if v == "" {
+		return "status", nil
+	}
+	if !helpers.InSet(v, "status", "step", "assignee") {
+		return "", fmt.Errorf("invalid group_by: %s (allow: status|step|assignee)", v)
+	}
+	return v, nil
+}
+
+// boardColumnOf คอลัมน์ของงานตามมิติที่เลือก (assignee = เจ้าของ step ปัจจุบัน)
+func boardColumnOf(t *models.Tasks, groupBy string) (string, string) {
+	switch groupBy {
+	case "step":
+		if t.StepName == "" {
+			return "", "ไม่มีขั้นตอน"
+		}
+		return t.StepName, t.StepName
+	case "assignee":
+		if st := boardCurrentStep(t, ""); st != nil {
+			owner := stepOwner(t, *st)
+			name := stepAssigneeName(t, *st)
+			if name == "" {
+				name = owner
+			}
+			return owner, name
+		}
+		name := t.AssigneeName
+		if name == "" {
+			name = t.Assignee
+		}
+		return t.Assignee, name
+	}
+	for _, c := range boardStatusColumns {
+		if c.Key == t.Status {
+			return c.Key, c.Title
+		}
+	}
+	return t.Status, t.Status
+}
+
+// boardCurrentStep step ที่การ์ดอ้างถึง: step ที่ระบุ > step ที่กำลังทำ > step แรกที่เริ่มได้
+func boardCurrentStep(t *models.Tasks, stepID string) *models.TaskWorkflowStep {
+	if st := findTaskStep(t.AppliedWorkflow.Steps, stepID); st != nil {
+		return st
+	}
+	for i := range t.AppliedWorkflow.Steps {
+		if t.AppliedWorkflow.Steps[i].Status == "in_progress" {
+			return &t.AppliedWorkflow.Steps[i]
+		}
+	}
+	if ready := helpers.ReadySteps(t.AppliedWorkflow.Steps); len(ready) > 0 {
+		return findTaskStep(t.AppliedWorkflow.Steps, ready[0].StepID)
+	}
+	return nil
+}
+
+func findTaskStep(steps []models.TaskWorkflowStep, stepID string) *models.TaskWorkflowStep {
+	if stepID == "" {
+		return nil
+	}
+	for i := range steps {
+		if steps[i].StepID == stepID {
+			return &steps[i]
+		}
+	}
+	return nil
+}
+
+func stepsWithStatus(steps []models.TaskWorkflowStep, status string) []models.TaskWorkflowStep {
+	out := make([]models.TaskWorkflowStep, 0)
+	for _, st := range steps {
+		if st.Status == status {
+			out = append(out, st)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Order < out[j].Order })
+	return out
+}
+
+// lastClosedStep step ที่ปิดล่าสุดตามลำดับ (ใช้เปิดงานที่เสร็จแล้วกลับมาทำต่อ)
+func lastClosedStep(steps []models.TaskWorkflowStep) *models.TaskWorkflowStep {
+	var last *models.TaskWorkflowStep
+	for i := range steps {
+		if steps[i].Status == "done" && (last == nil || steps[i].Order > last.Order) {
+			last = &steps[i]
+		}
+	}
+	return last
+}
+
+// blockingSteps step ที่ต้องปิดก่อนเริ่ม target (prerequisite หรือ step ก่อนหน้าในลำดับเส้นตรง)
+func blockingSteps(steps []models.TaskWorkflowStep, target models.TaskWorkflowStep) []models.TaskWorkflowStep {
+	if helpers.HasStepDependencies(steps) {
+		return helpers.PendingPrerequisites(steps, target.StepID)
+	}
+	out := make([]models.TaskWorkflowStep, 0)
+	for _, st := range steps {
+		if st.Order < target.Order && st.Status != "done" && st.Status != "skip" {
+			out = append(out, st)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Order < out[j].Order })
+	return out
+}
+
+// matchWIPLimit เลือก limit ที่เจาะจงที่สุด (workflow > แผนก > ทั้งหมด)
+func matchWIPLimit(limits []*models.BoardWIPLimit, columnKey, workflowID, departmentID string) *models.BoardWIPLimit {
+	var best *models.BoardWIPLimit
+	bestScore := -1
+	for _, l := range limits {
+		if l.ColumnKey != columnKey {
+			continue
+		}
+		if l.WorkFlowID != "" && l.WorkFlowID != workflowID {
+			continue
+		}
+		if l.DepartmentID != "" && l.DepartmentID != departmentID {
+			continue
+		}
+		score := 0
+		if l.WorkFlowID != "" {
+			score += 2
+		}
+		if l.DepartmentID != "" {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = l, score
+		}
+	}
+	return best
+}
+
+// optionalScope ค่าว่างใน filter ต้องจับทั้ง "" และฟิลด์ที่ไม่มี (omitempty)
+func optionalScope(v string) interface{} {
+	if v == "" {
+		return bson.M{"$in": bson.A{"", nil}}
+	}
+	return v
+}
+
+func sortBoardTasks(tasks []*models.Tasks) {
+	rank := map[string]int{"high": 0, "medium": 1, "low": 2}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if !tasks[i].EndDate.Equal(tasks[j].EndDate) {
+			return tasks[i].EndDate.Before(tasks[j].EndDate)
+		}
+		ri, ok := rank[tasks[i].Importance]
+		if !ok {
+			ri = len(rank)
+		}
+		rj, ok := rank[tasks[j].Importance]
+		if !ok {
+			rj = len(rank)
+		}
+		return ri < rj
+	})
+}
+
+func toTaskCard(t *models.Tasks) dto.TaskCardDTO {
+	done := 0
+	for _, st := range t.AppliedWorkflow.Steps {
+		if st.Status == "done" || st.Status == "skip" {
+			done++
+		}
+	}
+	total := len(t.AppliedWorkflow.Steps)
+	progress := 0.0
+	if total > 0 {
+		progress = util.Round2(float64(done) * 100 / float64(total))
+	}
+	card := dto.TaskCardDTO{
+		StartDate:    t.StartDate,
+		EndDate:      t.EndDate,
+		UpdatedAt:    t.UpdatedAt,
+		TaskID:       t.TaskID,
+		ProjectName:  t.ProjectName,
+		JobID:        t.JobID,
+		JobName:      t.JobName,
+		Department:   t.Department,
+		Assignee:     t.Assignee,
+		AssigneeName: t.AssigneeName,
+		Importance:   t.Importance,
+		Status:       t.Status,
+		StepName:     t.StepName,
+		ReadySteps:   t.ReadySteps,
+		StepsDone:    done,
+		StepsTotal:   total,
+		Progress:     progress,
+		SLAStatus:    t.SLAStatus,
+		Overdue:      t.Status != "done" && helpers.DateOnly(t.EndDate).Before(helpers.Today()),
+	}
+	if st := boardCurrentStep(t, ""); st != nil {
+		card.CurrentStepID = st.StepID
+	}
+	return card
+}
@@ -0,0 +1,365 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/config"
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/pkg/util"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	calendarFeedTokenBytes  = 24
+	maxCalendarFeedsPerUser = 5
+	calendarFeedPastDays    = 30  // แสดงงานย้อนหลัง
+	calendarFeedFutureDays  = 365 // แสดงงานล่วงหน้า
+	calendarFeedPath        = "/service/api/v1/calendar-feed/ical/"
+)
+
+type calendarFeedService struct {
+	config         config.Config
+	feedRepo       ports.CalendarFeedRepository
+	taskRepo       ports.TaskRepository
+	signJobRepo    ports.SignJobRepository
+	userRepo       ports.UserRepository
+	departmentRepo ports.DepartmentRepository
+}
+
+func NewCalendarFeedService(cfg config.Config, feedRepo ports.CalendarFeedRepository, taskRepo ports.TaskRepository, signJobRepo ports.SignJobRepository, userRepo ports.UserRepository, departmentRepo ports.DepartmentRepository) ports.CalendarFeedService {
+	return &calendarFeedService{config: cfg, feedRepo: feedRepo, taskRepo: taskRepo, signJobRepo: signJobRepo, userRepo: userRepo, departmentRepo: departmentRepo}
+}
+
+func (s *calendarFeedService) CreateFeed(ctx context.Context, req dto.CreateCalendarFeedDTO, claims *dto.JWTClaims) (*dto.CalendarFeedTokenDTO, error) {
+	departmentID := strings.TrimSpace(req.DepartmentID)
+	name := strings.TrimSpace(req.Name)
+	if departmentID != "" {
+		dept, err := s.departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{"department_id": departmentID, "deleted_at": nil}, bson.M{"department_id": 1, "department_name": 1, "manager_id": 1})
+		if err != nil && err != mongo.ErrNoDocuments {
+			return nil, err
+		}
+		if dept == nil {
+			return nil, errors.New("department not found")
+		}
+		// ปฏิทินของทั้งแผนกเฉพาะผู้จัดการแผนกนั้นหรือ admin
+		if claims.Role != "admin" && dept.ManagerID != claims.UserID {
+			return nil, ports.ErrCalendarFeedForbidden
+		}
+		if name == "" {
+			name = "งานแผนก " + dept.DepartmentName
+		}
+	}
+	if name == "" {
+		name = "งานของฉัน"
+	}
+
+	active, err := s.feedRepo.GetAllCalendarFeedsByFilter(ctx, bson.M{"user_id": claims.UserID, "revoked_at": nil}, bson.M{"feed_id": 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(active) >= maxCalendarFeedsPerUser {
+		return nil, fmt.Errorf("cannot have more than %d active calendar feeds, revoke one first", maxCalendarFeedsPerUser)
+	}
+
+	token, err := util.GenerateToken(calendarFeedTokenBytes)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	feed := models.CalendarFeed{
+		FeedID:       uuid.NewString(),
+		UserID:       claims.UserID,
+		Name:         name,
+		TokenHash:    s.hashToken(token),
+		TokenHint:    tokenHint(token),
+		DepartmentID: departmentID,
+		IncludeSteps: req.IncludeSteps == nil || *req.IncludeSteps,
+		IncludeJobs:  req.IncludeJobs == nil || *req.IncludeJobs,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.feedRepo.CreateCalendarFeed(ctx, feed); err != nil {
+		return nil, err
+	}
+
+	return &dto.CalendarFeedTokenDTO{Feed: toCalendarFeedDTO(&feed), Token: token, FeedURL: calendarFeedPath + token + ".ics"}, nil
+}
+
+func (s *calendarFeedService) ListFeeds(ctx context.Context, claims *dto.JWTClaims) ([]dto.CalendarFeedDTO, error) {
+	feeds, err := s.feedRepo.GetAllCalendarFeedsByFilter(ctx, bson.M{"user_id": claims.UserID}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]dto.CalendarFeedDTO, 0, len(feeds))
+	for _, f := range feeds {
+		out = append(out, toCalendarFeedDTO(f))
+	}
+	return out, nil
+}
+
+// RotateFeed ออก token ใหม่ให้ลิงก์เดิม (ลิงก์เก่าใช้ไม่ได้ทันที)
+func (s *calendarFeedService) RotateFeed(ctx context.Context, feedID string, claims *dto.JWTClaims) (*dto.CalendarFeedTokenDTO, error) {
+	feed, err := s.ownedFeed(ctx, feedID, claims)
+	if err != nil {
+		return nil, err
+	}
+	if feed.RevokedAt != nil {
+		return nil, errors.New("calendar feed has been revoked")
+	}
+
+	token, err := util.GenerateToken(calendarFeedTokenBytes)
+	if err != nil {
+		return nil, err
+	}
+	feed.TokenHash = s.hashToken(token)
+	feed.TokenHint = tokenHint(token)
+	feed.UpdatedAt = time.Now()
+	updated, err := s.feedRepo.UpdateCalendarFeedByID(ctx, feed.FeedID, *feed)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	return &dto.CalendarFeedTokenDTO{Feed: toCalendarFeedDTO(updated), Token: token, FeedURL: calendarFeedPath + token + ".ics"}, nil
+}
+
+func (s *calendarFeedService) RevokeFeed(ctx context.Context, feedID string, claims *dto.JWTClaims) error {
+	feed, err := s.ownedFeed(ctx, feedID, claims)
+	if err != nil {
+		return err
+	}
+	if feed.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	feed.RevokedAt = &now
+	feed.UpdatedAt = now
+	_, err = s.feedRepo.UpdateCalendarFeedByID(ctx, feed.FeedID, *feed)
+	return err
+}
+
+// RenderFeed รายการงาน (start/end), กำหนดส่งของ step และวันกำหนดส่ง/ติดตั้งงานป้าย ในรูปแบบ iCal
+func (s *calendarFeedService) RenderFeed(ctx context.Context, token string) (string, error) {
+	token = strings.TrimSuffix(strings.TrimSpace(token), ".ics")
+	if token == "" {
+		return "", mongo.ErrNoDocuments
+	}
+	feed, err := s.feedRepo.GetOneCalendarFeedByFilter(ctx, bson.M{"token_hash": s.hashToken(token), "revoked_at": nil}, bson.M{})
+	if err != nil {
+		return "", err
+	}
+	if feed == nil {
+		return "", mongo.ErrNoDocuments
+	}
+	user, _ := s.userRepo.GetByID(ctx, feed.UserID)
+	if user == nil || user.DeletedAt != nil || user.AccessRevokedAt != nil || user.Status != "approved" {
+		// ผู้ใช้ถูกลบ พ้นสภาพ หรือถูกระงับ = ลิงก์ใช้ไม่ได้
+		return "", mongo.ErrNoDocuments
+	}
+	departmentID := feed.DepartmentID
+	if departmentID != "" && user.Role != "admin" {
+		// ไม่ได้เป็นผู้จัดการแผนกแล้ว = ลิงก์ใช้ไม่ได้
+		dept, err := s.departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{"department_id": departmentID, "manager_id": feed.UserID, "deleted_at": nil}, bson.M{"department_id": 1})
+		if err != nil && err != mongo.ErrNoDocuments {
+			return "", err
+		}
+		if dept == nil {
+			return "", mongo.ErrNoDocuments
+		}
+	}
+
+	now := time.Now()
+	today := helpers.Today()
+	from := today.AddDate(0, 0, -calendarFeedPastDays)
+	to := today.AddDate(0, 0, calendarFeedFutureDays)
+
+	filter := bson.M{
+		"deleted_at": nil,
+		"status":     bson.M{"$ne": "cancelled"},
+		"end_date":   bson.M{"$gte": from},
+		"start_date": bson.M{"$lte": to},
+	}
+	if departmentID != "" {
+		filter["$or"] = bson.A{
+			bson.M{"department_id": departmentID},
+			bson.M{"applied_workflow.steps.department_id": departmentID},
+		}
+	} else {
+		filter["$or"] = bson.A{
+			bson.M{"assignee": feed.UserID},
+			bson.M{"applied_workflow.steps.assignee": feed.UserID},
+		}
+	}
+	tasks, err := s.taskRepo.GetAllTaskByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return "", err
+	}
+	sortBoardTasks(tasks)
+
+	events := make([]util.ICalEvent, 0, len(tasks))
+	jobIDs := make([]string, 0)
+	seenJobs := make(map[string]bool)
+	for _, t := range tasks {
+		events = append(events, util.ICalEvent{
+			UID:         fmt.Sprintf("task-%s@erp-demo", t.TaskID),
+			Summary:     fmt.Sprintf("งาน: %s", t.JobName),
+			Description: taskFeedDescription(t),
+			Categories:  []string{"task", t.Importance},
+			Start:       helpers.DateOnly(t.StartDate),
+			End:         helpers.DateOnly(t.EndDate),
+			AllDay:      true,
+			LastUpdate:  t.UpdatedAt,
+		})
+		if t.JobID != "" && !seenJobs[t.JobID] {
+			seenJobs[t.JobID] = true
+			jobIDs = append(jobIDs, t.JobID)
+		}
+
+		if !feed.IncludeSteps {
+			continue
+		}
+		for _, st := range t.AppliedWorkflow.Steps {
+			if st.SLADueAt == nil || (st.Status != "todo" && st.Status != "in_progress") {
+				continue
+			}
+			if departmentID != "" {
+				stepDept := st.Department
+				if stepDept == "" {
+					stepDept = t.Department
+				}
+				if stepDept != departmentID {
+					continue
+				}
+			} else if stepOwner(t, st) != feed.UserID {
+				continue
+			}
+			due := *st.SLADueAt
+			events = append(events, util.ICalEvent{
+				UID:         fmt.Sprintf("step-%s@erp-demo", st.StepID),
+				Summary:     fmt.Sprintf("กำหนดส่ง: %s (%s)", st.StepName, t.JobName),
+				Description: fmt.Sprintf("ขั้นตอน: %s\nสถานะ: %s\nผู้รับผิดชอบ: %s", st.StepName, st.Status, stepAssigneeName(t, st)),
+				Categories:  []string{"step"},
+				Start:       due.Add(-30 * time.Minute),
+				End:         due,
+				LastUpdate:  st.UpdatedAt,
+			})
+		}
+	}
+
+	if feed.IncludeJobs {
+		jobFilter := bson.M{
+			"deleted_at": nil,
+			"status":     bson.M{"$ne": "cancelled"},
+			"due_date":   bson.M{"$gte": from, "$lte": to},
+		}
+		if departmentID != "" {
+			jobFilter["job_id"] = bson.M{"$in": jobIDs}
+		} else {
+			jobFilter["$or"] = bson.A{
+				bson.M{"job_id": bson.M{"$in": jobIDs}},
+				bson.M{"created_by": feed.UserID},
+			}
+		}
+		jobs, err := s.signJobRepo.GetAllSignJobByFilter(ctx, jobFilter, bson.M{})
+		if err != nil {
+			return "", err
+		}
+		for _, j := range jobs {
+			event := util.ICalEvent{
+				UID:         fmt.Sprintf("signjob-%s@erp-demo", j.JobID),
+				Summary:     fmt.Sprintf("กำหนดส่งงานป้าย: %s", j.JobName),
+				Description: fmt.Sprintf("โปรเจกต์: %s\nลูกค้า: %s\nจำนวน: %d", j.ProjectName, j.CompanyName, j.Quantity),
+				Categories:  []string{"sign_job"},
+				Start:       helpers.DateOnly(j.DueDate),
+				End:         helpers.DateOnly(j.DueDate),
+				AllDay:      true,
+				LastUpdate:  j.UpdatedAt,
+			}
+			if j.InstallOption == "self" || j.InstallOption == "shop" {
+				event.Summary = fmt.Sprintf("ติดตั้งป้าย: %s", j.JobName)
+				event.Location = j.Address
+				event.Categories = append(event.Categories, "install")
+			}
+			events = append(events, event)
+		}
+	}
+
+	feed.LastAccessAt = &now
+	if _, err := s.feedRepo.UpdateCalendarFeedByID(ctx, feed.FeedID, *feed); err != nil {
+		log.Println("Error updating calendar feed access time:", err)
+	}
+
+	return util.BuildICalendar(feed.Name, events, now), nil
+}
+
+// ownedFeed ลิงก์ของผู้ใช้เอง (admin ยกเลิก/เปลี่ยนลิงก์ของทุกคนได้)
+func (s *calendarFeedService) ownedFeed(ctx context.Context, feedID string, claims *dto.JWTClaims) (*models.CalendarFeed, error) {
+	feed, err := s.feedRepo.GetOneCalendarFeedByFilter(ctx, bson.M{"feed_id": feedID}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if feed == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	if feed.UserID != claims.UserID && claims.Role != "admin" {
+		return nil, ports.ErrCalendarFeedForbidden
+	}
+	return feed, nil
+}
+
+func (s *calendarFeedService) hashToken(token string) string {
+	return util.HashPassword(token, s.config.Hash.Salt)
+}
+
+func tokenHint(token string) string {
+	if len(token) <= 4 {
+		return token
+	}
+	return token[len(token)-4:]
+}
+
+func taskFeedDescription(t *models.Tasks) string {
+	lines := []string{
+		"โปรเจกต์: " + t.ProjectName,
+		"สถานะ: " + t.Status,
+	}
+	if t.StepName != "" {
+		lines = append(lines, "ขั้นตอนปัจจุบัน: "+t.StepName)
+	}
+	if t.AssigneeName != "" {
+		lines = append(lines, "ผู้รับผิดชอบ: "+t.AssigneeName)
+	}
+	if t.Description != "" {
+		lines = append(lines, "", t.Description)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func toCalendarFeedDTO(f *models.CalendarFeed) dto.CalendarFeedDTO {
+	return dto.CalendarFeedDTO{
+		CreatedAt:    f.CreatedAt,
+		UpdatedAt:    f.UpdatedAt,
+		RevokedAt:    f.RevokedAt,
+		LastAccessAt: f.LastAccessAt,
+		FeedID:       f.FeedID,
+		UserID:       f.UserID,
+		Name:         f.Name,
+		TokenHint:    f.TokenHint,
+		DepartmentID: f.DepartmentID,
+		IncludeSteps: f.IncludeSteps,
+		IncludeJobs:  f.IncludeJobs,
+		Active:       f.RevokedAt == nil,
+	}
+}
@@ -0,0 +1,685 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/config"
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/pkg/util"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	defaultHoursPerDay   = 8
+	capacityHorizonDays  = 365
+	defaultCapacityWeeks = 4
+)
+
+var defaultWorkDays = []int{1, 2, 3, 4, 5}
+
+type capacityService struct {
+	capacityRepo         ports.CapacityRepository
+	taskRepo             ports.TaskRepository
+	userRepo             ports.UserRepository
+	workflowRepo         ports.WorkFlowRepository
+	signTypeWorkflowRepo ports.SignTypeWorkflowRepository
+	dropDownRepo         ports.DropDownRepository
+	leaveRepo            ports.LeaveRepository
+	config               config.Config
+}
+
+func NewCapacityService(cfg config.Config, capacityRepo ports.CapacityRepository, taskRepo ports.TaskRepository, userRepo ports.UserRepository, workflowRepo ports.WorkFlowRepository, signTypeWorkflowRepo ports.SignTypeWorkflowRepository, dropDownRepo ports.DropDownRepository, leaveRepo ports.LeaveRepository) ports.CapacityService {
+	return &capacityService{config: cfg, capacityRepo: capacityRepo, taskRepo: taskRepo, userRepo: userRepo, workflowRepo: workflowRepo, signTypeWorkflowRepo: signTypeWorkflowRepo, dropDownRepo: dropDownRepo, leaveRepo: leaveRepo}
+}
+
+func (s *capacityService) UpsertDepartmentCapacity(ctx context.Context, req dto.UpsertDepartmentCapacityDTO, claims *dto.JWTClaims) error {
+	departmentID := strings.TrimSpace(req.DepartmentID)
+	if departmentID == "" {
+		return errors.New("department_id is required")
+	}
+	if req.HoursPerDay <= 0 || req.HoursPerDay > 24 {
+		return errors.New("hours_per_day must be between 0 and 24")
+	}
+	efficiency := req.Efficiency
+	if efficiency == 0 {
+		efficiency = 1
+	}
+	if efficiency < 0 || efficiency > 1 {
+		return errors.New("efficiency must be between 0 and 1")
+	}
+	workDays, err := normalizeWorkDays(req.WorkDays)
+	if err != nil {
+		return err
+	}
+
+	departments, err := s.dropDownRepo.GetDepartments(ctx, bson.M{"department_id": departmentID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return err
+	}
+	if len(departments) == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	existing, err := s.capacityRepo.GetOneDepartmentCapacityByFilter(ctx, bson.M{"department_id": departmentID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return err
+	}
+
+	if existing != nil {
+		existing.WorkDays = workDays
+		existing.HoursPerDay = req.HoursPerDay
+		existing.Efficiency = efficiency
+		existing.UpdatedBy = claims.UserID
+		updated, err := s.capacityRepo.UpdateDepartmentCapacityByID(ctx, existing.CapacityID, *existing)
+		if err != nil {
+			return err
+		}
+		if updated == nil {
+			return mongo.ErrNoDocuments
+		}
+		return nil
+	}
+
+	now := time.Now()
+	return s.capacityRepo.CreateDepartmentCapacity(ctx, models.DepartmentCapacity{
+		CapacityID:   uuid.NewString(),
+		DepartmentID: departmentID,
+		WorkDays:     workDays,
+		HoursPerDay:  req.HoursPerDay,
+		Efficiency:   efficiency,
+		UpdatedBy:    claims.UserID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	})
+}
+
+func (s *capacityService) ListDepartmentCapacities(ctx context.Context, claims *dto.JWTClaims) ([]dto.DepartmentCapacityDTO, error) {
+	today := helpers.Today()
+	cal, err := s.loadCalendar(ctx, today, today)
+	if err != nil {
+		return nil, err
+	}
+	openHours, err := s.openTaskHoursByDepartment(ctx, cal)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]dto.DepartmentCapacityDTO, 0, len(cal.departments))
+	for _, d := range cal.departments {
+		setting := cal.setting(d.DepartmentID)
+		out = append(out, dto.DepartmentCapacityDTO{
+			UpdatedAt:      setting.UpdatedAt,
+			CapacityID:     setting.CapacityID,
+			DepartmentID:   d.DepartmentID,
+			DepartmentName: d.DepartmentName,
+			WorkDays:       setting.WorkDays,
+			HoursPerDay:    setting.HoursPerDay,
+			Efficiency:     setting.Efficiency,
+			People:         cal.people[d.DepartmentID],
+			DailyHours:     util.Round2(setting.HoursPerDay * setting.Efficiency * float64(cal.people[d.DepartmentID])),
+			OpenTaskHours:  util.Round2(openHours[d.DepartmentID]),
+		})
+	}
+	return out, nil
+}
+
+func (s *capacityService) CreateHoliday(ctx context.Context, req dto.CreateHolidayDTO, claims *dto.JWTClaims) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return errors.New("name is required")
+	}
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return errors.New("invalid date format, expected YYYY-MM-DD")
+	}
+	departmentID := strings.TrimSpace(req.DepartmentID)
+
+	dupFilter := bson.M{"date": date, "department_id": departmentID, "deleted_at": nil}
+	if departmentID == "" {
+		dupFilter["department_id"] = bson.M{"$in": []interface{}{nil, ""}}
+	}
+	dup, err := s.capacityRepo.GetOneHolidayByFilter(ctx, dupFilter, bson.M{})
+	if err != nil {
+		return err
+	}
+	if dup != nil {
+		return errors.New("holiday already exists on this date")
+	}
+
+	return s.capacityRepo.CreateHoliday(ctx, models.Holiday{
+		HolidayID:    uuid.NewString(),
+		Date:         date,
+		Name:         strings.TrimSpace(req.Name),
+		DepartmentID: departmentID,
+		CreatedBy:    claims.UserID,
+		CreatedAt:    time.Now(),
+	})
+}
+
+func (s *capacityService) ListHolidays(ctx context.Context, req dto.RequestListHolidays, claims *dto.JWTClaims) ([]dto.HolidayDTO, error) {
+	year := req.Year
+	if year == 0 {
+		year = time.Now().Year()
+	}
+	filter := bson.M{
+		"date": bson.M{
+			"$gte": time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC),
+			"$lt":  time.Date(year+1, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"deleted_at": nil,
+	}
+	if departmentID := strings.TrimSpace(req.DepartmentID); departmentID != "" {
+		filter["department_id"] = bson.M{"$in": []interface{}{nil, "", departmentID}}
+	}
+
+	holidays, err := s.capacityRepo.GetAllHolidaysByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]dto.HolidayDTO, 0, len(holidays))
+	for _, h := range holidays {
+		out = append(out, dto.HolidayDTO{
+			Date:         h.Date,
+			HolidayID:    h.HolidayID,
+			Name:         h.Name,
+			DepartmentID: h.DepartmentID,
+		})
+	}
+	return out, nil
+}
+
+func (s *capacityService) DeleteHoliday(ctx context.Context, holidayID string, claims *dto.JWTClaims) error {
+	existing, err := s.capacityRepo.GetOneHolidayByFilter(ctx, bson.M{"holiday_id": holidayID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return mongo.ErrNoDocuments
+	}
+	return s.capacityRepo.SoftDeleteHolidayByID(ctx, holidayID)
+}
+
+// EarliestCompletion หาวันที่เสร็จเร็วที่สุดของงานใหม่ โดยวางแต่ละ workflow ตามลำดับลงในชั่วโมงว่างของแผนก
+// หลังหักงานค้างที่วางแผนไว้แล้ว
+func (s *capacityService) EarliestCompletion(ctx context.Context, req dto.RequestEarliestCompletion, claims *dto.JWTClaims) (*dto.EarliestCompletionDTO, error) {
+	signTypeID := strings.TrimSpace(req.SignTypeID)
+	if signTypeID == "" {
+		return nil, errors.New("sign_type_id is required")
+	}
+	if req.Width < 0 || req.Height < 0 || req.Quantity < 0 {
+		return nil, errors.New("width, height and quantity must be >= 0")
+	}
+
+	today := helpers.Today()
+	start := today
+	if req.StartDate != "" {
+		parsed, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return nil, errors.New("invalid start_date format, expected YYYY-MM-DD")
+		}
+		if parsed.After(start) {
+			start = parsed
+		}
+	}
+
+	mappings, err := s.signTypeWorkflowRepo.GetAllSignTypeWorkflowsByFilter(ctx, bson.M{
+		"sign_type_id": signTypeID,
+		"is_active":    true,
+		"deleted_at":   nil,
+	}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	installOption := strings.ToLower(strings.TrimSpace(req.InstallOption))
+	mapping := pickSignTypeWorkflow(mappings, installOption)
+	if mapping == nil || len(mapping.Items) == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	quantity := req.Quantity
+	if quantity < 1 {
+		quantity = 1
+	}
+	area := req.Width * req.Height / 10000
+	factor := float64(quantity)
+	if mapping.BaseAreaSqm > 0 && area > mapping.BaseAreaSqm {
+		factor *= area / mapping.BaseAreaSqm
+	}
+
+	horizonEnd := start.AddDate(0, 0, capacityHorizonDays)
+	cal, err := s.loadCalendar(ctx, today, horizonEnd)
+	if err != nil {
+		return nil, err
+	}
+	load, _, err := s.plannedLoad(ctx, cal, today)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.EarliestCompletionDTO{
+		SignTypeID:    signTypeID,
+		InstallOption: mapping.InstallOption,
+		AreaSqm:       util.Round2(area),
+		SizeFactor:    util.Round2(factor),
+		Stages:        make([]dto.CompletionStageDTO, 0, len(mapping.Items)),
+	}
+
+	cursor := start
+	for _, it := range mapping.Items {
+		wf, err := s.workflowRepo.GetOneWorkFlowTemplateByFilter(ctx, bson.M{"workflow_id": it.WorkFlowID, "deleted_at": nil}, bson.M{})
+		if err != nil {
+			return nil, err
+		}
+		if wf == nil {
+			return nil, fmt.Errorf("workflow %s not found", it.WorkFlowID)
+		}
+		departmentID := it.DepartmentID
+		if departmentID == "" {
+			departmentID = wf.Department
+		}
+		if cal.people[departmentID] == 0 {
+			return nil, fmt.Errorf("department %s has no production capacity", cal.departmentName(departmentID))
+		}
+
+		hours := wf.TotalHours * factor
+		stageStart, stageEnd, ok := cal.allocate(load, departmentID, cursor, horizonEnd, hours)
+		if !ok {
+			return nil, fmt.Errorf("cannot fit %s within %d days", wf.WorkFlowName, capacityHorizonDays)
+		}
+
+		result.Stages = append(result.Stages, dto.CompletionStageDTO{
+			StartDate:      stageStart,
+			EndDate:        stageEnd,
+			WorkFlowID:     wf.WorkFlowID,
+			WorkFlowName:   wf.WorkFlowName,
+			DepartmentID:   departmentID,
+			DepartmentName: cal.departmentName(departmentID),
+			Hours:          util.Round2(hours),
+		})
+		result.TotalHours += hours
+		result.CompletionDate = stageEnd
+		cursor = stageEnd.AddDate(0, 0, 1)
+	}
+
+	result.TotalHours = util.Round2(result.TotalHours)
+	result.LeadDays = int(result.CompletionDate.Sub(start).Hours()/24) + 1
+	return result, nil
+}
+
+// DepartmentLoad เปรียบเทียบชั่วโมงงานที่วางแผนไว้กับกำลังการผลิตรายสัปดาห์ของแต่ละแผนก
+func (s *capacityService) DepartmentLoad(ctx context.Context, req dto.RequestCapacityLoad, claims *dto.JWTClaims) ([]dto.CapacityLoadDTO, error) {
+	weeks := req.Weeks
+	if weeks <= 0 || weeks > 26 {
+		weeks = defaultCapacityWeeks
+	}
+
+	today := helpers.Today()
+	// สัปดาห์เริ่มวันจันทร์
+	weekStart := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	horizonEnd := weekStart.AddDate(0, 0, weeks*7-1)
+
+	cal, err := s.loadCalendar(ctx, today, horizonEnd)
+	if err != nil {
+		return nil, err
+	}
+	load, overdue, err := s.plannedLoad(ctx, cal, today)
+	if err != nil {
+		return nil, err
+	}
+
+	filterDept := strings.TrimSpace(req.DepartmentID)
+	out := make([]dto.CapacityLoadDTO, 0, len(cal.departments))
+	for _, d := range cal.departments {
+		if filterDept != "" && d.DepartmentID != filterDept {
+			continue
+		}
+		row := dto.CapacityLoadDTO{
+			DepartmentID:   d.DepartmentID,
+			DepartmentName: d.DepartmentName,
+			People:         cal.people[d.DepartmentID],
+			OverdueHours:   util.Round2(overdue[d.DepartmentID]),
+			Weeks:          make([]dto.CapacityLoadWeekDTO, 0, weeks),
+		}
+		for w := 0; w < weeks; w++ {
+			ws := weekStart.AddDate(0, 0, w*7)
+			we := ws.AddDate(0, 0, 6)
+			var capHours, loadHours float64
+			for day := ws; !day.After(we); day = day.AddDate(0, 0, 1) {
+				if day.Before(today) {
+					continue
+				}
+				capHours += cal.capacity(d.DepartmentID, day)
+				loadHours += load[d.DepartmentID][helpers.DayKey(day)]
+			}
+			utilization := 0.0
+			if capHours > 0 {
+				utilization = loadHours / capHours * 100
+			}
+			row.Weeks = append(row.Weeks, dto.CapacityLoadWeekDTO{
+				WeekStart:     ws,
+				WeekEnd:       we,
+				CapacityHours: util.Round2(capHours),
+				LoadHours:     util.Round2(loadHours),
+				FreeHours:     util.Round2(capHours - loadHours),
+				UtilizationPc: util.Round2(utilization),
+			})
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
+
+// ---------- ปฏิทินกำลังการผลิต ----------
+
+type capacityCalendar struct {
+	departments []*models.Department
+	settings    map[string]*models.DepartmentCapacity
+	people      map[string]int
+	userDept    map[string]string             // user_id -> department_id ใช้หาแผนกของเจ้าของ step
+	holidays    map[string]map[string]bool    // department_id ("" = ทุกแผนก) -> day key
+	onLeave     map[string]map[string]float64 // department_id -> day key -> จำนวนคนที่ลา (ครึ่งวัน = 0.5)
+}
+
+func (s *capacityService) loadCalendar(ctx context.Context, from, to time.Time) (*capacityCalendar, error) {
+	cal, err := loadWorkingCalendar(ctx, s.capacityRepo, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	departments, err := s.dropDownRepo.GetDepartments(ctx, bson.M{"deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(departments, func(i, j int) bool { return departments[i].DepartmentName < departments[j].DepartmentName })
+	cal.departments = departments
+
+	users, err := s.userRepo.GetUserByFilter(ctx, bson.M{"status": "approved", "deleted_at": nil}, bson.M{"user_id": 1, "department_id": 1})
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		if u.DepartmentID != "" {
+			cal.people[u.DepartmentID]++
+			cal.userDept[u.UserID] = u.DepartmentID
+		}
+	}
+
+	// ใบลาที่อนุมัติแล้วลดจำนวนคนที่ทำงานได้ในวันนั้น
+	leaves, err := s.leaveRepo.GetAllLeaveRequestsByFilter(ctx, bson.M{
+		"status":     bson.M{"$in": leaveTakenStatuses},
+		"start_date": bson.M{"$lte": to},
+		"end_date":   bson.M{"$gte": from},
+		"deleted_at": nil,
+	}, bson.M{"_id": 0, "department_id": 1, "start_date": 1, "end_date": 1, "half_day": 1})
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range leaves {
+		if l.DepartmentID == "" {
+			continue
+		}
+		if cal.onLeave[l.DepartmentID] == nil {
+			cal.onLeave[l.DepartmentID] = map[string]float64{}
+		}
+		for day := l.StartDate; !day.After(l.EndDate); day = day.AddDate(0, 0, 1) {
+			cal.onLeave[l.DepartmentID][helpers.DayKey(day)] += leaveDayFraction(l)
+		}
+	}
+
+	return cal, nil
+}
+
+// loadWorkingCalendar โหลดเฉพาะวันทำงานของแผนกและวันหยุดในช่วง from..to (ไม่รวมจำนวนคน/ใบลา)
+// ใช้วางแผนวันเริ่ม/วันส่งงานให้ไม่ตกวันหยุด
+func loadWorkingCalendar(ctx context.Context, capacityRepo ports.CapacityRepository, from, to time.Time) (*capacityCalendar, error) {
+	cal := &capacityCalendar{
+		settings: map[string]*models.DepartmentCapacity{},
+		people:   map[string]int{},
+		userDept: map[string]string{},
+		holidays: map[string]map[string]bool{},
+		onLeave:  map[string]map[string]float64{},
+	}
+
+	settings, err := capacityRepo.GetAllDepartmentCapacitiesByFilter(ctx, bson.M{"deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range settings {
+		cal.settings[st.DepartmentID] = st
+	}
+
+	holidays, err := capacityRepo.GetAllHolidaysByFilter(ctx, bson.M{
+		"date":       bson.M{"$gte": from, "$lte": to},
+		"deleted_at": nil,
+	}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range holidays {
+		if cal.holidays[h.DepartmentID] == nil {
+			cal.holidays[h.DepartmentID] = map[string]bool{}
+		}
+		cal.holidays[h.DepartmentID][helpers.DayKey(h.Date)] = true
+	}
+	return cal, nil
+}
+
+// setting คืนค่าการตั้งค่าของแผนก หรือค่าเริ่มต้น (8 ชม./วัน จันทร์-ศุกร์)
+func (c *capacityCalendar) setting(departmentID string) *models.DepartmentCapacity {
+	if st, ok := c.settings[departmentID]; ok {
+		return st
+	}
+	return &models.DepartmentCapacity{
+		DepartmentID: departmentID,
+		WorkDays:     defaultWorkDays,
+		HoursPerDay:  defaultHoursPerDay,
+		Efficiency:   1,
+	}
+}
+
+func (c *capacityCalendar) departmentName(departmentID string) string {
+	for _, d := range c.departments {
+		if d.DepartmentID == departmentID {
+			return d.DepartmentName
+		}
+	}
+	return departmentID
+}
+
+func (c *capacityCalendar) isWorkingDay(departmentID string, day time.Time) bool {
+	key := helpers.DayKey(day)
+	if c.holidays[""][key] || (departmentID != "" && c.holidays[departmentID][key]) {
+		return false
+	}
+	for _, wd := range c.setting(departmentID).WorkDays {
+		if int(day.Weekday()) == wd {
+			return true
+		}
+	}
+	return false
+}
+
+// shiftWorkingDays เลื่อนจาก day ไปยังวันทำงานถัดไป (step = 1) หรือก่อนหน้า (step = -1) โดยนับ day ด้วยถ้าเป็นวันทำงาน
+// ค้นหาไม่เกิน 1 ปี กันวนไม่รู้จบเมื่อแผนกไม่มีวันทำงาน
+func (c *capacityCalendar) shiftWorkingDays(departmentID string, day time.Time, step int) time.Time {
+	for i := 0; i < 366; i++ {
+		if c.isWorkingDay(departmentID, day) {
+			return day
+		}
+		day = day.AddDate(0, 0, step)
+	}
+	return day
+}
+
+// capacity ชั่วโมงผลิตของแผนกในวันนั้น (หักคนที่ลา)
+func (c *capacityCalendar) capacity(departmentID string, day time.Time) float64 {
+	if !c.isWorkingDay(departmentID, day) {
+		return 0
+	}
+	st := c.setting(departmentID)
+	people := float64(c.people[departmentID]) - c.onLeave[departmentID][helpers.DayKey(day)]
+	if people <= 0 {
+		return 0
+	}
+	return st.HoursPerDay * st.Efficiency * people
+}
+
+// allocate ใช้ชั่วโมงว่างของแผนกตั้งแต่ from จนครบ hours แล้วบันทึกลง load
+func (c *capacityCalendar) allocate(load map[string]map[string]float64, departmentID string, from, until time.Time, hours float64) (time.Time, time.Time, bool) {
+	if load[departmentID] == nil {
+		load[departmentID] = map[string]float64{}
+	}
+	var start time.Time
+	remaining := hours
+	for day := from; !day.After(until); day = day.AddDate(0, 0, 1) {
+		free := c.capacity(departmentID, day) - load[departmentID][helpers.DayKey(day)]
+		if free <= 0 {
+			continue
+		}
+		if start.IsZero() {
+			start = day
+		}
+		used := free
+		if remaining < used {
+			used = remaining
+		}
+		load[departmentID][helpers.DayKey(day)] += used
+		remaining -= used
+		if remaining <= 1e-9 {
+			return start, day, true
+		}
+	}
+	if hours <= 0 && !start.IsZero() {
+		return start, start, true
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// plannedLoad กระจายชั่วโมงที่เหลือของ task ที่ยังไม่เสร็จลงวันทำงานในช่วง start_date..end_date
+// งานที่เลยกำหนดแล้วจะถูกรวมไว้ที่วันทำงานแรกนับจากวันนี้
+func (s *capacityService) plannedLoad(ctx context.Context, cal *capacityCalendar, today time.Time) (map[string]map[string]float64, map[string]float64, error) {
+	tasks, err := s.taskRepo.GetAllTaskByFilter(ctx, bson.M{
+		"status":     bson.M{"$in": []string{"todo", "in_progress"}},
+		"deleted_at": nil,
+	}, bson.M{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	load := map[string]map[string]float64{}
+	overdue := map[string]float64{}
+	for _, t := range tasks {
+		from := helpers.DateOnly(t.StartDate)
+		if from.Before(today) {
+			from = today
+		}
+		to := helpers.DateOnly(t.EndDate)
+		late := !t.EndDate.IsZero() && to.Before(today)
+		if t.EndDate.IsZero() || late {
+			to = from
+		}
+
+		// step ที่ทำโดยแผนกอื่นลงภาระของแผนกนั้น
+		for dept, hours := range remainingTaskHours(t, cal.userDept) {
+			if hours <= 0 {
+				continue
+			}
+			if load[dept] == nil {
+				load[dept] = map[string]float64{}
+			}
+			if late {
+				overdue[dept] += hours
+			}
+
+			days := make([]time.Time, 0)
+			for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+				if cal.isWorkingDay(dept, day) {
+					days = append(days, day)
+				}
+			}
+			if len(days) == 0 {
+				// ไม่มีวันทำงานในช่วงนั้น ให้ลงวันทำงานถัดไป
+				day := to
+				for i := 0; i < 31 && !cal.isWorkingDay(dept, day); i++ {
+					day = day.AddDate(0, 0, 1)
+				}
+				days = append(days, day)
+			}
+			per := hours / float64(len(days))
+			for _, day := range days {
+				load[dept][helpers.DayKey(day)] += per
+			}
+		}
+	}
+	return load, overdue, nil
+}
+
+func (s *capacityService) openTaskHoursByDepartment(ctx context.Context, cal *capacityCalendar) (map[string]float64, error) {
+	tasks, err := s.taskRepo.GetAllTaskByFilter(ctx, bson.M{
+		"status":     bson.M{"$in": []string{"todo", "in_progress"}},
+		"deleted_at": nil,
+	}, bson.M{"department_id": 1, "assignee": 1, "applied_workflow.steps": 1})
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]float64{}
+	for _, t := range tasks {
+		for dept, hours := range remainingTaskHours(t, cal.userDept) {
+			out[dept] += hours
+		}
+	}
+	return out, nil
+}
+
+// remainingTaskHours ชั่วโมงของ step ที่ยังไม่เสร็จและไม่ได้ข้าม แยกตามแผนกที่ทำ step
+func remainingTaskHours(t *models.Tasks, userDept map[string]string) map[string]float64 {
+	out := map[string]float64{}
+	for _, st := range t.AppliedWorkflow.Steps {
+		if st.Status == "todo" || st.Status == "in_progress" {
+			out[stepDepartment(t, st, userDept)] += st.Hours
+		}
+	}
+	return out
+}
+
+// stepDepartment แผนกที่รับภาระของ step: แผนกที่กำหนดใน step > แผนกของเจ้าของ step > แผนกของงาน
+func stepDepartment(t *models.Tasks, st models.TaskWorkflowStep, userDept map[string]string) string {
+	if st.Department != "" {
+		return st.Department
+	}
+	if st.Assignee != "" && st.Assignee != t.Assignee {
+		if dept, ok := userDept[st.Assignee]; ok {
+			return dept
+		}
+	}
+	return t.Department
+}
+
+func normalizeWorkDays(days []int) ([]int, error) {
+	if len(days) == 0 {
+		return defaultWorkDays, nil
+	}
+	seen := map[int]bool{}
+	out := make([]int, 0, len(days))
+	for _, d := range days {
+		if d < 0 || d > 6 {
+			return nil, errors.New("work_days must be between 0 (Sunday) and 6 (Saturday)")
+		}
+		if !seen[d] {
+			seen[d] = true
+			out = append(out, d)
+		}
+	}
+	sort.Ints(out)
+	return out, nil
+}
@@ -0,0 +1,607 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/config"
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/pkg/util"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	maxCommentLength   = 5000 // ความยาวข้อความสูงสุด (ตัวอักษร)
+	defaultFeedLimit   = 100
+	deletedCommentBody = "ความคิดเห็นนี้ถูกลบแล้ว"
+)
+
+type commentService struct {
+	config         config.Config
+	commentRepo    ports.CommentRepository
+	activityRepo   ports.ActivityRepository
+	attachmentRepo ports.AttachmentRepository
+	taskRepo       ports.TaskRepository
+	signJobRepo    ports.SignJobRepository
+	userRepo       ports.UserRepository
+	departmentRepo ports.DepartmentRepository
+}
+
+func NewCommentService(cfg config.Config, commentRepo ports.CommentRepository, activityRepo ports.ActivityRepository, attachmentRepo ports.AttachmentRepository, taskRepo ports.TaskRepository, signJobRepo ports.SignJobRepository, userRepo ports.UserRepository, departmentRepo ports.DepartmentRepository) ports.CommentService {
+	return &commentService{config: cfg, commentRepo: commentRepo, activityRepo: activityRepo, attachmentRepo: attachmentRepo, taskRepo: taskRepo, signJobRepo: signJobRepo, userRepo: userRepo, departmentRepo: departmentRepo}
+}
+
+func (s *commentService) CreateComment(ctx context.Context, req dto.CreateCommentDTO, claims *dto.JWTClaims) (*dto.CommentDTO, error) {
+	entityType := strings.ToLower(strings.TrimSpace(req.EntityType))
+	entityID := strings.TrimSpace(req.EntityID)
+	body := strings.TrimSpace(req.Body)
+	if body == "" && len(req.AttachmentIDs) == 0 {
+		return nil, errors.New("body or attachments is required")
+	}
+	if len([]rune(body)) > maxCommentLength {
+		return nil, fmt.Errorf("body must not exceed %d characters", maxCommentLength)
+	}
+
+	target, err := s.checkAccess(ctx, entityType, entityID, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	stepID := strings.TrimSpace(req.StepID)
+	if stepID != "" {
+		if target.task == nil || !hasStep(target.task, stepID) {
+			return nil, errors.New("step not found in this task")
+		}
+	}
+
+	parentID := strings.TrimSpace(req.ParentID)
+	var parent *models.Comment
+	if parentID != "" {
+		parent, err = s.commentRepo.GetOneCommentByFilter(ctx, bson.M{"comment_id": parentID, "entity_type": entityType, "entity_id": entityID}, bson.M{})
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			return nil, errors.New("parent comment not found")
+		}
+		// ตอบกลับใน thread เดียวกับ step ของความคิดเห็นต้นทาง
+		stepID = parent.StepID
+	}
+
+	mentions, err := s.resolveMentions(ctx, body, req.Mentions)
+	if err != nil {
+		return nil, err
+	}
+	attachmentIDs, err := s.validateAttachments(ctx, target, req.AttachmentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	comment := models.Comment{
+		CommentID:     uuid.NewString(),
+		EntityType:    entityType,
+		EntityID:      entityID,
+		StepID:        stepID,
+		JobID:         target.jobID,
+		ParentID:      parentID,
+		Body:          body,
+		Mentions:      mentions,
+		AttachmentIDs: attachmentIDs,
+		AuthorID:      claims.UserID,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := s.commentRepo.CreateComment(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	notify := mentions
+	if parent != nil && parent.DeletedAt == nil {
+		notify = helpers.UniqueStrings(append(append([]string{}, mentions...), parent.AuthorID)...)
+	}
+	s.notifyMentions(target, &comment, notify, claims.UserID)
+
+	out := s.toCommentDTO(ctx, &comment, map[string]string{})
+	return &out, nil
+}
+
+func (s *commentService) UpdateComment(ctx context.Context, commentID string, req dto.UpdateCommentDTO, claims *dto.JWTClaims) (*dto.CommentDTO, error) {
+	comment, err := s.commentRepo.GetOneCommentByFilter(ctx, bson.M{"comment_id": commentID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if comment == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	// แก้ไขได้เฉพาะผู้เขียน
+	if comment.AuthorID != claims.UserID {
+		return nil, ports.ErrCommentForbidden
+	}
+
+	body := strings.TrimSpace(req.Body)
+	if body == "" && len(req.AttachmentIDs) == 0 && len(comment.AttachmentIDs) == 0 {
+		return nil, errors.New("body or attachments is required")
+	}
+	if len([]rune(body)) > maxCommentLength {
+		return nil, fmt.Errorf("body must not exceed %d characters", maxCommentLength)
+	}
+
+	target, err := s.checkAccess(ctx, comment.EntityType, comment.EntityID, claims)
+	if err != nil {
+		return nil, err
+	}
+	mentions, err := s.resolveMentions(ctx, body, req.Mentions)
+	if err != nil {
+		return nil, err
+	}
+	if req.AttachmentIDs != nil {
+		ids, err := s.validateAttachments(ctx, target, req.AttachmentIDs)
+		if err != nil {
+			return nil, err
+		}
+		comment.AttachmentIDs = ids
+	}
+
+	// เก็บประวัติก่อนแก้
+	now := time.Now()
+	comment.Edits = append(comment.Edits, models.CommentEdit{
+		EditedAt:     now,
+		EditedBy:     claims.UserID,
+		PrevBody:     comment.Body,
+		PrevMentions: comment.Mentions,
+	})
+
+	prevMentions := comment.Mentions
+	comment.Body = body
+	comment.Mentions = mentions
+	comment.UpdatedAt = now
+
+	updated, err := s.commentRepo.UpdateCommentByID(ctx, commentID, *comment)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	// แจ้งเฉพาะคนที่เพิ่งถูก mention ในการแก้ไขนี้
+	added := make([]string, 0)
+	for _, id := range mentions {
+		if !helpers.InSet(id, prevMentions...) {
+			added = append(added, id)
+		}
+	}
+	s.notifyMentions(target, updated, added, claims.UserID)
+
+	out := s.toCommentDTO(ctx, updated, map[string]string{})
+	return &out, nil
+}
+
+func (s *commentService) DeleteComment(ctx context.Context, commentID string, claims *dto.JWTClaims) error {
+	comment, err := s.commentRepo.GetOneCommentByFilter(ctx, bson.M{"comment_id": commentID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return err
+	}
+	if comment == nil {
+		return mongo.ErrNoDocuments
+	}
+	if claims.Role != "admin" && comment.AuthorID != claims.UserID {
+		return ports.ErrCommentForbidden
+	}
+
+	// soft delete: คงข้อความเดิมไว้ในประวัติ แต่ไม่แสดงใน thread
+	now := time.Now()
+	comment.DeletedAt = &now
+	comment.DeletedBy = claims.UserID
+	comment.UpdatedAt = now
+	_, err = s.commentRepo.UpdateCommentByID(ctx, commentID, *comment)
+	return err
+}
+
+func (s *commentService) ListComments(ctx context.Context, req dto.RequestListComments, claims *dto.JWTClaims) ([]dto.CommentDTO, error) {
+	entityType := strings.ToLower(strings.TrimSpace(req.EntityType))
+	entityID := strings.TrimSpace(req.EntityID)
+	if _, err := s.checkReadAccess(ctx, entityType, entityID, claims); err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"entity_type": entityType, "entity_id": entityID}
+	if v := strings.TrimSpace(req.StepID); v != "" {
+		filter["step_id"] = v
+	}
+	comments, err := s.commentRepo.GetAllCommentsByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	return s.buildThreads(ctx, comments), nil
+}
+
+// ActivityFeed รวมความคิดเห็นกับเหตุการณ์ของงาน (สถานะ step/งาน, มอบหมาย, แก้ไข) เรียงใหม่ไปเก่า
+// งานป้ายจะรวม feed ของทุก task ในงานนั้นด้วย
+func (s *commentService) ActivityFeed(ctx context.Context, req dto.RequestActivityFeed, claims *dto.JWTClaims) ([]dto.ActivityFeedItemDTO, error) {
+	entityType := strings.ToLower(strings.TrimSpace(req.EntityType))
+	entityID := strings.TrimSpace(req.EntityID)
+	if _, err := s.checkReadAccess(ctx, entityType, entityID, claims); err != nil {
+		return nil, err
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultFeedLimit
+	}
+
+	commentFilter := bson.M{"entity_type": entityType, "entity_id": entityID}
+	activityFilter := bson.M{"entity_type": "task", "entity_id": entityID}
+	if entityType == "sign_job" {
+		commentFilter = bson.M{"$or": bson.A{
+			bson.M{"entity_type": "sign_job", "entity_id": entityID},
+			bson.M{"entity_type": "task", "job_id": entityID},
+		}}
+		activityFilter = bson.M{"job_id": entityID}
+	}
+
+	comments, err := s.commentRepo.GetAllCommentsByFilter(ctx, commentFilter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	activities, err := s.activityRepo.GetAllActivitiesByFilter(ctx, activityFilter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]string{}
+	items := make([]dto.ActivityFeedItemDTO, 0, len(comments)+len(activities))
+	for _, c := range comments {
+		cd := s.toCommentDTO(ctx, c, names)
+		item := dto.ActivityFeedItemDTO{
+			At:        c.CreatedAt,
+			Type:      "comment",
+			StepID:    c.StepID,
+			ActorID:   c.AuthorID,
+			ActorName: cd.AuthorName,
+			Comment:   &cd,
+		}
+		if c.EntityType == "task" {
+			item.TaskID = c.EntityID
+		}
+		items = append(items, item)
+	}
+	for _, a := range activities {
+		items = append(items, dto.ActivityFeedItemDTO{
+			At:        a.CreatedAt,
+			Type:      a.Type,
+			TaskID:    a.EntityID,
+			StepID:    a.StepID,
+			StepName:  a.StepName,
+			FromValue: a.FromValue,
+			ToValue:   a.ToValue,
+			Message:   a.Message,
+			ActorID:   a.ActorID,
+			ActorName: s.userName(ctx, a.ActorID, names),
+		})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].At.After(items[j].At) })
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+// commentTarget เอกสารที่ความคิดเห็นผูกอยู่
+type commentTarget struct {
+	entityType string
+	entityID   string
+	jobID      string
+	jobName    string
+	task       *models.Tasks
+}
+
+// checkAccess สิทธิ์เขียน/อ่านความคิดเห็น:
+//   - admin เข้าถึงได้ทั้งหมด
+//   - task: ผู้สร้าง ผู้รับผิดชอบหลัก เจ้าของ step หรือผู้จัดการแผนกของงาน/step
+//   - งานป้าย: ผู้สร้างงาน หรือผู้ที่มีสิทธิ์ใน task ใดของงานนั้น
+func (s *commentService) checkAccess(ctx context.Context, entityType, entityID string, claims *dto.JWTClaims) (*commentTarget, error) {
+	if entityID == "" {
+		return nil, errors.New("entity_id is required")
+	}
+	isAdmin := claims.Role == "admin"
+
+	switch entityType {
+	case "task":
+		task, err := s.taskRepo.GetOneTasksByFilter(ctx, bson.M{"task_id": entityID, "deleted_at": nil}, bson.M{})
+		if err != nil {
+			return nil, err
+		}
+		if task == nil {
+			return nil, mongo.ErrNoDocuments
+		}
+		target := &commentTarget{entityType: entityType, entityID: entityID, jobID: task.JobID, jobName: task.JobName, task: task}
+		if isAdmin || isTaskParticipant(task, claims.UserID) {
+			return target, nil
+		}
+		ok, err := s.managesTask(ctx, task, claims.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ports.ErrCommentForbidden
+		}
+		return target, nil
+
+	case "sign_job":
+		job, err := s.signJobRepo.GetOneSignJobByFilter(ctx, bson.M{"job_id": entityID, "deleted_at": nil}, bson.M{"job_id": 1, "job_name": 1, "created_by": 1})
+		if err != nil {
+			return nil, err
+		}
+		if job == nil {
+			return nil, mongo.ErrNoDocuments
+		}
+		target := &commentTarget{entityType: entityType, entityID: entityID, jobID: job.JobID, jobName: job.JobName}
+		if isAdmin || job.CreatedBy == claims.UserID {
+			return target, nil
+		}
+		tasks, err := s.taskRepo.GetAllTaskByFilter(ctx, bson.M{"job_id": entityID, "deleted_at": nil}, bson.M{})
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tasks {
+			if isTaskParticipant(t, claims.UserID) {
+				return target, nil
+			}
+			ok, err := s.managesTask(ctx, t, claims.UserID)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				return target, nil
+			}
+		}
+		return nil, ports.ErrCommentForbidden
+
+	default:
+		return nil, errors.New("entity_type must be task or sign_job")
+	}
+}
+
+// checkReadAccess เหมือน checkAccess แต่ผู้ที่ถูก @mention ในเอกสารนั้นอ่านได้ด้วย
+func (s *commentService) checkReadAccess(ctx context.Context, entityType, entityID string, claims *dto.JWTClaims) (*commentTarget, error) {
+	target, err := s.checkAccess(ctx, entityType, entityID, claims)
+	if !errors.Is(err, ports.ErrCommentForbidden) {
+		return target, err
+	}
+	mentioned, errOnGet := s.commentRepo.GetOneCommentByFilter(ctx, bson.M{
+		"entity_type": entityType,
+		"entity_id":   entityID,
+		"mentions":    claims.UserID,
+		"deleted_at":  nil,
+	}, bson.M{"comment_id": 1})
+	if errOnGet != nil {
+		return nil, errOnGet
+	}
+	if mentioned == nil {
+		return nil, err
+	}
+	return &commentTarget{entityType: entityType, entityID: entityID}, nil
+}
+
+// managesTask ผู้จัดการแผนกของงานหรือของ step ใดในงาน
+func (s *commentService) managesTask(ctx context.Context, task *models.Tasks, userID string) (bool, error) {
+	departments := []string{task.Department}
+	for _, st := range task.AppliedWorkflow.Steps {
+		if st.Department != "" {
+			departments = append(departments, st.Department)
+		}
+	}
+	dept, err := s.departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{
+		"department_id": bson.M{"$in": helpers.UniqueStrings(departments...)},
+		"manager_id":    userID,
+		"deleted_at":    nil,
+	}, bson.M{"department_id": 1})
+	if err != nil && err != mongo.ErrNoDocuments {
+		return false, err
+	}
+	return dept != nil, nil
+}
+
+// resolveMentions รวม @[ชื่อ](user_id) ในข้อความกับ mentions ที่ส่งมา และตรวจว่าผู้ใช้มีอยู่จริง
+func (s *commentService) resolveMentions(ctx context.Context, body string, extra []string) ([]string, error) {
+	ids := helpers.UniqueStrings(append(helpers.ParseMentions(body), extra...)...)
+	for _, id := range ids {
+		user, err := s.userRepo.GetByID(ctx, id)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return nil, err
+		}
+		if user == nil {
+			return nil, fmt.Errorf("mentioned user not found: %s", id)
+		}
+	}
+	return ids, nil
+}
+
+// validateAttachments ไฟล์แนบต้องอัปโหลดไว้กับเอกสารเดียวกัน (task/step ของ task หรืองานป้าย)
+func (s *commentService) validateAttachments(ctx context.Context, target *commentTarget, ids []string) ([]string, error) {
+	ids = helpers.UniqueStrings(ids...)
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	attachments, err := s.attachmentRepo.GetAllAttachmentsByFilter(ctx, bson.M{"attachment_id": bson.M{"$in": ids}, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*models.Attachment, len(attachments))
+	for _, a := range attachments {
+		byID[a.AttachmentID] = a
+	}
+	for _, id := range ids {
+		a := byID[id]
+		if a == nil {
+			return nil, fmt.Errorf("attachment not found: %s", id)
+		}
+		ok := false
+		switch target.entityType {
+		case "task":
+			ok = (a.EntityType == "task" && a.EntityID == target.entityID) || (a.EntityType == "step" && a.ParentID == target.entityID)
+		case "sign_job":
+			ok = (a.EntityType == "sign_job" && a.EntityID == target.entityID) || a.JobID == target.entityID
+		}
+		if !ok {
+			return nil, fmt.Errorf("attachment %s does not belong to this %s", id, target.entityType)
+		}
+	}
+	return ids, nil
+}
+
+// buildThreads จัดความคิดเห็นเป็น thread (ความคิดเห็นที่ถูกลบยังคงไว้ถ้ามีคำตอบ)
+func (s *commentService) buildThreads(ctx context.Context, comments []*models.Comment) []dto.CommentDTO {
+	names := map[string]string{}
+	children := make(map[string][]*models.Comment)
+	roots := make([]*models.Comment, 0)
+	exists := make(map[string]bool, len(comments))
+	for _, c := range comments {
+		exists[c.CommentID] = true
+	}
+	for _, c := range comments {
+		if c.ParentID != "" && exists[c.ParentID] {
+			children[c.ParentID] = append(children[c.ParentID], c)
+			continue
+		}
+		roots = append(roots, c)
+	}
+
+	var build func(c *models.Comment) (dto.CommentDTO, bool)
+	build = func(c *models.Comment) (dto.CommentDTO, bool) {
+		out := s.toCommentDTO(ctx, c, names)
+		for _, child := range children[c.CommentID] {
+			if reply, ok := build(child); ok {
+				out.Replies = append(out.Replies, reply)
+			}
+		}
+		// ซ่อนความคิดเห็นที่ถูกลบและไม่มีคำตอบ
+		return out, c.DeletedAt == nil || len(out.Replies) > 0
+	}
+
+	out := make([]dto.CommentDTO, 0, len(roots))
+	for _, c := range roots {
+		if item, ok := build(c); ok {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func (s *commentService) toCommentDTO(ctx context.Context, c *models.Comment, names map[string]string) dto.CommentDTO {
+	out := dto.CommentDTO{
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+		DeletedAt:   c.DeletedAt,
+		CommentID:   c.CommentID,
+		EntityType:  c.EntityType,
+		EntityID:    c.EntityID,
+		StepID:      c.StepID,
+		ParentID:    c.ParentID,
+		Body:        c.Body,
+		Mentions:    []dto.MentionDTO{},
+		Attachments: []dto.AttachmentDTO{},
+		AuthorID:    c.AuthorID,
+		AuthorName:  s.userName(ctx, c.AuthorID, names),
+		Edited:      len(c.Edits) > 0,
+		Deleted:     c.DeletedAt != nil,
+		Replies:     []dto.CommentDTO{},
+	}
+	if c.DeletedAt != nil {
+		out.Body = deletedCommentBody
+		return out
+	}
+
+	for _, id := range c.Mentions {
+		out.Mentions = append(out.Mentions, dto.MentionDTO{UserID: id, UserName: s.userName(ctx, id, names)})
+	}
+	for _, e := range c.Edits {
+		out.Edits = append(out.Edits, dto.CommentEditDTO{EditedAt: e.EditedAt, EditedBy: e.EditedBy, PrevBody: e.PrevBody})
+	}
+	if len(c.AttachmentIDs) > 0 {
+		attachments, err := s.attachmentRepo.GetAllAttachmentsByFilter(ctx, bson.M{"attachment_id": bson.M{"$in": c.AttachmentIDs}, "deleted_at": nil}, bson.M{})
+		if err == nil {
+			for _, a := range attachments {
+				out.Attachments = append(out.Attachments, toAttachmentDTO(a))
+			}
+		}
+	}
+	return out
+}
+
+func (s *commentService) userName(ctx context.Context, userID string, cache map[string]string) string {
+	if name, ok := cache[userID]; ok {
+		return name
+	}
+	name := "ไม่พบชื่อผู้ใช้"
+	if user, _ := s.userRepo.GetByID(ctx, userID); user != nil {
+		name = fmt.Sprintf("%s %s %s", user.TitleTH, user.FirstNameTH, user.LastNameTH)
+	}
+	cache[userID] = name
+	return name
+}
+
+// notifyMentions ส่งอีเมลแจ้งผู้ที่ถูก @mention (และผู้เขียนความคิดเห็นที่ถูกตอบกลับ)
+func (s *commentService) notifyMentions(target *commentTarget, comment *models.Comment, userIDs []string, actorID string) {
+	recipients := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		if id != actorID {
+			recipients = append(recipients, id)
+		}
+	}
+	if len(recipients) == 0 || strings.TrimSpace(s.config.Email.Host) == "" {
+		return
+	}
+
+	emailCfg := util.EmailConfig{
+		Host:     s.config.Email.Host,
+		Port:     s.config.Email.Port,
+		Username: s.config.Email.Username,
+		Password: s.config.Email.Password,
+		From:     s.config.Email.From,
+	}
+	jobName := target.jobName
+	if jobName == "" {
+		jobName = target.entityID
+	}
+	body := comment.Body
+
+	// ส่งแบบ async ไม่ให้การโพสต์ความคิดเห็นต้องรอ SMTP
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		author := s.userName(ctx, actorID, map[string]string{})
+		for _, id := range recipients {
+			user, err := s.userRepo.GetByID(ctx, id)
+			if err != nil || user == nil || strings.TrimSpace(user.Email) == "" {
+				continue
+			}
+			subject := fmt.Sprintf("คุณถูกกล่าวถึงใน \"%s\"", jobName)
+			text := fmt.Sprintf("เรียนคุณ %s\n\n%s แสดงความคิดเห็นในงาน \"%s\":\n\n%s\n", user.FirstNameTH, author, jobName, body)
+			if err := util.SendMail(emailCfg, user.Email, subject, text); err != nil {
+				log.Println("Error sending mention email:", err)
+			}
+		}
+	}()
+}
+
+func hasStep(task *models.Tasks, stepID string) bool {
+	for _, st := range task.AppliedWorkflow.Steps {
+		if st.StepID == stepID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,1109 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/config"
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	checklistDefaultListLimit = 20
+	checklistRevokedCacheTTL  = time.Minute // middleware เห็นการยกเลิกสิทธิ์ภายใน 1 นาที
+	checklistSystemActor      = "system"
+)
+
+type employeeChecklistService struct {
+	config         config.Config
+	checklistRepo  ports.EmployeeChecklistRepository
+	userRepo       ports.UserRepository
+	departmentRepo ports.DepartmentRepository
+	positionRepo   ports.PositionRepository
+	taskRepo       ports.TaskRepository
+	taskSvc        ports.TaskService
+	feedRepo       ports.CalendarFeedRepository
+
+	revokedMu       sync.RWMutex
+	revokedUsers    map[string]struct{}
+	revokedLoadedAt time.Time
+}
+
+func NewEmployeeChecklistService(cfg config.Config, checklistRepo ports.EmployeeChecklistRepository, userRepo ports.UserRepository, departmentRepo ports.DepartmentRepository, positionRepo ports.PositionRepository, taskRepo ports.TaskRepository, taskSvc ports.TaskService, feedRepo ports.CalendarFeedRepository) ports.EmployeeChecklistService {
+	return &employeeChecklistService{
+		config:         cfg,
+		checklistRepo:  checklistRepo,
+		userRepo:       userRepo,
+		departmentRepo: departmentRepo,
+		positionRepo:   positionRepo,
+		taskRepo:       taskRepo,
+		taskSvc:        taskSvc,
+		feedRepo:       feedRepo,
+		revokedUsers:   map[string]struct{}{},
+	}
+}
+
+// ---------- แม่แบบ ----------
+
+func (s *employeeChecklistService) UpsertChecklistTemplate(ctx context.Context, req dto.UpsertChecklistTemplateDTO, claims *dto.JWTClaims) (*dto.ChecklistTemplateDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrChecklistForbidden
+	}
+	kind := strings.TrimSpace(req.Kind)
+	if kind != models.ChecklistOnboarding && kind != models.ChecklistOffboarding {
+		return nil, fmt.Errorf("kind must be onboarding or offboarding")
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	positionID := strings.TrimSpace(req.PositionID)
+	if positionID != "" {
+		pos, err := s.positionRepo.GetOnePositionByFilter(ctx, bson.M{"position_id": positionID, "deleted_at": nil}, bson.M{"_id": 0, "position_id": 1})
+		if err != nil {
+			return nil, err
+		}
+		if pos == nil {
+			return nil, fmt.Errorf("position not found")
+		}
+	}
+	items, err := normalizeChecklistItems(kind, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	// หนึ่งแม่แบบต่อ (kind, position_id)
+	existing, err := s.checklistRepo.GetOneChecklistTemplateByFilter(ctx, bson.M{"kind": kind, "position_id": positionID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	templateID := strings.TrimSpace(req.TemplateID)
+	if existing != nil && existing.TemplateID != templateID {
+		return nil, fmt.Errorf("%w: template for this kind and position already exists (%s)", ports.ErrChecklistConflict, existing.TemplateID)
+	}
+
+	now := time.Now()
+	var saved *models.ChecklistTemplate
+	if templateID == "" {
+		template := models.ChecklistTemplate{
+			CreatedAt:  now,
+			UpdatedAt:  now,
+			TemplateID: uuid.NewString(),
+			Kind:       kind,
+			PositionID: positionID,
+			Name:       name,
+			Items:      items,
+			UpdatedBy:  claims.UserID,
+		}
+		if err := s.checklistRepo.CreateChecklistTemplate(ctx, template); err != nil {
+			return nil, err
+		}
+		saved = &template
+	} else {
+		saved, err = s.checklistRepo.UpdateChecklistTemplate(ctx, bson.M{"template_id": templateID, "deleted_at": nil}, bson.M{"$set": bson.M{
+			"kind":        kind,
+			"position_id": positionID,
+			"name":        name,
+			"items":       items,
+			"updated_by":  claims.UserID,
+			"updated_at":  now,
+		}})
+		if err != nil {
+			return nil, err
+		}
+		if saved == nil {
+			return nil, mongo.ErrNoDocuments
+		}
+	}
+
+	out := s.toChecklistTemplateDTO(ctx, saved, map[string]string{})
+	return &out, nil
+}
+
+func (s *employeeChecklistService) ListChecklistTemplates(ctx context.Context, req dto.RequestListChecklistTemplates, claims *dto.JWTClaims) ([]dto.ChecklistTemplateDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrChecklistForbidden
+	}
+	filter := bson.M{"deleted_at": nil}
+	if v := strings.TrimSpace(req.Kind); v != "" {
+		filter["kind"] = v
+	}
+	if v := strings.TrimSpace(req.PositionID); v != "" {
+		filter["position_id"] = v
+	}
+	templates, err := s.checklistRepo.GetAllChecklistTemplatesByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	positions := map[string]string{}
+	out := make([]dto.ChecklistTemplateDTO, 0, len(templates))
+	for _, t := range templates {
+		out = append(out, s.toChecklistTemplateDTO(ctx, t, positions))
+	}
+	return out, nil
+}
+
+func (s *employeeChecklistService) DeleteChecklistTemplate(ctx context.Context, templateID string, claims *dto.JWTClaims) error {
+	if claims.Role != "admin" {
+		return ports.ErrChecklistForbidden
+	}
+	now := time.Now()
+	deleted, err := s.checklistRepo.UpdateChecklistTemplate(ctx, bson.M{"template_id": strings.TrimSpace(templateID), "deleted_at": nil}, bson.M{"$set": bson.M{"deleted_at": now, "updated_at": now, "updated_by": claims.UserID}})
+	if err != nil {
+		return err
+	}
+	if deleted == nil {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// ---------- Onboarding ----------
+
+func (s *employeeChecklistService) StartOnboarding(ctx context.Context, user *models.User, actorID string) (*models.EmployeeChecklist, error) {
+	existing, err := s.checklistRepo.GetOneEmployeeChecklistByFilter(ctx, bson.M{"user_id": user.UserID, "kind": models.ChecklistOnboarding, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	checklist, err := s.newChecklist(ctx, models.ChecklistOnboarding, user, employmentStartDate(user), actorID)
+	if err != nil {
+		return nil, err
+	}
+	refreshChecklistAutoItems(checklist, user, time.Now())
+	if err := s.checklistRepo.CreateEmployeeChecklist(ctx, *checklist); err != nil {
+		return nil, err
+	}
+	return checklist, nil
+}
+
+func (s *employeeChecklistService) EnsureOnboardingComplete(ctx context.Context, user *models.User) error {
+	existing, err := s.checklistRepo.GetOneEmployeeChecklistByFilter(ctx, bson.M{"user_id": user.UserID, "kind": models.ChecklistOnboarding, "deleted_at": nil}, bson.M{"_id": 0, "checklist_id": 1})
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		legacy, err := s.createdBeforeChecklistGate(ctx, user)
+		if err != nil {
+			return err
+		}
+		if legacy {
+			// พนักงานเดิมก่อนเปิดใช้ checklist ไม่ต้องผ่านการตรวจ บันทึก checklist ที่ครบแล้วไว้เป็นหลักฐาน
+			return s.seedCompletedOnboarding(ctx, user)
+		}
+	}
+
+	checklist, err := s.StartOnboarding(ctx, user, "")
+	if err != nil {
+		return err
+	}
+	if refreshChecklistAutoItems(checklist, user, time.Now()) {
+		if err := s.saveChecklist(ctx, checklist); err != nil {
+			return err
+		}
+	}
+
+	pending := []string{}
+	for _, item := range checklist.Items {
+		if item.Mandatory && item.Status != models.ChecklistItemDone {
+			pending = append(pending, item.Title)
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %s", ports.ErrChecklistIncomplete, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// ---------- Offboarding ----------
+
+func (s *employeeChecklistService) StartOffboarding(ctx context.Context, req dto.StartOffboardingDTO, claims *dto.JWTClaims) (*dto.EmployeeChecklistDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrChecklistForbidden
+	}
+	userID := strings.TrimSpace(req.UserID)
+	if userID == "" {
+		return nil, fmt.Errorf("user_id is required")
+	}
+	if userID == claims.UserID {
+		return nil, fmt.Errorf("%w: cannot offboard yourself", ports.ErrChecklistConflict)
+	}
+	lastDay, err := helpers.ParseDate("last_working_date", req.LastWorkingDate, time.UTC)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.DeletedAt != nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	if user.AccessRevokedAt != nil {
+		return nil, fmt.Errorf("%w: access has already been revoked", ports.ErrChecklistConflict)
+	}
+	existing, err := s.checklistRepo.GetOneEmployeeChecklistByFilter(ctx, bson.M{"user_id": userID, "kind": models.ChecklistOffboarding, "deleted_at": nil}, bson.M{"_id": 0, "checklist_id": 1})
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w: offboarding already started (%s)", ports.ErrChecklistConflict, existing.ChecklistID)
+	}
+
+	reassignTo := strings.TrimSpace(req.ReassignTo)
+	if reassignTo == "" {
+		reassignTo = s.departmentManager(ctx, user.DepartmentID)
+	}
+	if reassignTo == "" || reassignTo == userID {
+		return nil, fmt.Errorf("reassign_to is required (department has no other manager)")
+	}
+	assignee, err := s.userRepo.GetByID(ctx, reassignTo)
+	if err != nil {
+		return nil, err
+	}
+	if assignee == nil || assignee.DeletedAt != nil || assignee.Status != "approved" {
+		return nil, fmt.Errorf("reassign_to not found or not approved")
+	}
+
+	checklist, err := s.newChecklist(ctx, models.ChecklistOffboarding, user, lastDay, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	checklist.ReassignTo = reassignTo
+	checklist.Note = strings.TrimSpace(req.Note)
+	if err := s.checklistRepo.CreateEmployeeChecklist(ctx, *checklist); err != nil {
+		return nil, err
+	}
+
+	// โอนงานทันทีเพื่อไม่ให้งานค้างกับคนที่กำลังจะออก ส่วนสิทธิ์เข้าระบบยกเลิกหลังสิ้นวันทำงานวันสุดท้าย
+	if _, errs := s.reassignOpenTasks(ctx, checklist, claims); len(errs) > 0 {
+		log.Printf("offboarding %s: reassign tasks: %s", checklist.ChecklistID, strings.Join(errs, "; "))
+	}
+	if lastDay.Before(helpers.Today()) {
+		if err := s.revokeAccess(ctx, checklist, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.saveChecklist(ctx, checklist); err != nil {
+		return nil, err
+	}
+
+	out := s.toEmployeeChecklistDTO(ctx, checklist, newChecklistNames())
+	return &out, nil
+}
+
+// CancelOffboarding ยกเลิก offboarding (ไม่ออกแล้ว/รับกลับเข้าทำงาน) ล้าง access_revoked_at ให้ admin อนุมัติผู้ใช้ใหม่ได้
+func (s *employeeChecklistService) CancelOffboarding(ctx context.Context, checklistID string, req dto.CancelOffboardingDTO, claims *dto.JWTClaims) error {
+	if claims.Role != "admin" {
+		return ports.ErrChecklistForbidden
+	}
+	checklist, err := s.checklistRepo.GetOneEmployeeChecklistByFilter(ctx, bson.M{"checklist_id": strings.TrimSpace(checklistID), "kind": models.ChecklistOffboarding, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return err
+	}
+	if checklist == nil {
+		return mongo.ErrNoDocuments
+	}
+
+	now := time.Now()
+	note := checklist.Note
+	if reason := strings.TrimSpace(req.Note); reason != "" {
+		note = strings.TrimSpace(note + "\nยกเลิก: " + reason)
+	}
+	cancelled, err := s.checklistRepo.UpdateEmployeeChecklist(ctx, bson.M{"checklist_id": checklist.ChecklistID, "deleted_at": nil}, bson.M{"$set": bson.M{
+		"deleted_at": now,
+		"note":       note,
+		"updated_at": now,
+	}})
+	if err != nil {
+		return err
+	}
+	if cancelled == nil {
+		return mongo.ErrNoDocuments
+	}
+
+	// สถานะผู้ใช้ยังเป็น inactive ถ้าถูกยกเลิกสิทธิ์ไปแล้ว admin ต้องอนุมัติใหม่ (ผ่านการตรวจ onboarding ตามปกติ)
+	if _, err := s.userRepo.UpdateUserByFilter(ctx, bson.M{"user_id": checklist.UserID}, bson.M{
+		"$set":   bson.M{"updated_at": now},
+		"$unset": bson.M{"access_revoked_at": ""},
+	}); err != nil {
+		return err
+	}
+	s.revokedMu.Lock()
+	delete(s.revokedUsers, checklist.UserID)
+	s.revokedMu.Unlock()
+	return nil
+}
+
+func (s *employeeChecklistService) RunDueOffboarding(ctx context.Context, now time.Time) (*dto.ChecklistRunResult, error) {
+	result := &dto.ChecklistRunResult{RunAt: now, Errors: []string{}}
+	today := helpers.DateOnly(now.In(helpers.BangkokLocation()))
+
+	due, err := s.checklistRepo.GetAllEmployeeChecklistsByFilter(ctx, bson.M{
+		"kind":              models.ChecklistOffboarding,
+		"access_revoked_at": nil,
+		"start_date":        bson.M{"$lt": today},
+		"deleted_at":        nil,
+	}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	result.Due = len(due)
+
+	system := &dto.JWTClaims{UserID: checklistSystemActor, Role: "admin"}
+	for _, checklist := range due {
+		// งานที่ถูกมอบหมายเพิ่มหลังเริ่ม offboarding
+		moved, errs := s.reassignOpenTasks(ctx, checklist, system)
+		result.ReassignedTasks += moved
+		for _, e := range errs {
+			result.Errors = append(result.Errors, fmt.Sprintf("checklist %s: %s", checklist.ChecklistID, e))
+		}
+		if err := s.revokeAccess(ctx, checklist, now); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("checklist %s: %v", checklist.ChecklistID, err))
+			continue
+		}
+		if err := s.saveChecklist(ctx, checklist); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("checklist %s: %v", checklist.ChecklistID, err))
+			continue
+		}
+		result.Revoked++
+	}
+	return result, nil
+}
+
+func (s *employeeChecklistService) IsAccessRevoked(userID string) bool {
+	s.revokedMu.RLock()
+	fresh := time.Since(s.revokedLoadedAt) < checklistRevokedCacheTTL
+	_, revoked := s.revokedUsers[userID]
+	s.revokedMu.RUnlock()
+	if fresh {
+		return revoked
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	users, err := s.userRepo.GetUserByFilter(ctx, bson.M{"access_revoked_at": bson.M{"$ne": nil}}, bson.M{"_id": 0, "user_id": 1})
+	if err != nil {
+		// ใช้ข้อมูลเดิมไปก่อน ไม่ให้ฐานข้อมูลขัดข้องแล้วผู้ใช้ทุกคนเข้าไม่ได้
+		log.Printf("load revoked users: %v", err)
+		return revoked
+	}
+	set := make(map[string]struct{}, len(users))
+	for _, u := range users {
+		set[u.UserID] = struct{}{}
+	}
+	s.revokedMu.Lock()
+	s.revokedUsers, s.revokedLoadedAt = set, time.Now()
+	s.revokedMu.Unlock()
+
+	_, revoked = set[userID]
+	return revoked
+}
+
+// reassignOpenTasks โอนงานและ step ที่ยังไม่ปิดของพนักงานให้ checklist.ReassignTo แล้วติ๊กรายการ tasks_reassigned เมื่อโอนครบ
+func (s *employeeChecklistService) reassignOpenTasks(ctx context.Context, checklist *models.EmployeeChecklist, claims *dto.JWTClaims) (int, []string) {
+	tasks, _, err := s.taskRepo.GetListTasksByFilter(ctx, bson.M{
+		"assignee":   checklist.UserID,
+		"status":     bson.M{"$nin": []string{"done", "cancelled"}},
+		"deleted_at": nil,
+	}, bson.M{"_id": 0, "task_id": 1}, bson.D{{Key: "created_at", Value: 1}}, 0, 0)
+	if err != nil {
+		return 0, []string{err.Error()}
+	}
+
+	moved := 0
+	errs := []string{}
+	for _, t := range tasks {
+		_, err := s.taskSvc.ReassignTask(ctx, t.TaskID, dto.ReassignTaskRequest{
+			Assignee:      checklist.ReassignTo,
+			Reason:        "พนักงานพ้นสภาพ โอนงานอัตโนมัติจาก offboarding",
+			TransferSteps: true,
+		}, claims)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("task %s: %v", t.TaskID, err))
+			continue
+		}
+		moved++
+	}
+
+	// step ที่มอบให้พนักงานคนนี้ในงานของคนอื่น ย้ายผ่าน AssignStep เพื่อให้สถิติของเจ้าของ step ถูกต้อง
+	stepTasks, _, err := s.taskRepo.GetListTasksByFilter(ctx, bson.M{
+		"applied_workflow.steps.assignee": checklist.UserID,
+		"status":                          bson.M{"$nin": []string{"done", "cancelled"}},
+		"deleted_at":                      nil,
+	}, bson.M{"_id": 0, "task_id": 1, "applied_workflow.steps": 1}, bson.D{{Key: "created_at", Value: 1}}, 0, 0)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	for _, t := range stepTasks {
+		movedSteps := 0
+		for _, st := range t.AppliedWorkflow.Steps {
+			if st.Assignee != checklist.UserID || st.Status == "done" || st.Status == "skip" {
+				continue
+			}
+			if err := s.taskSvc.AssignStep(ctx, t.TaskID, st.StepID, dto.AssignStepRequest{Assignee: checklist.ReassignTo}, claims); err != nil {
+				errs = append(errs, fmt.Sprintf("task %s step %s: %v", t.TaskID, st.StepID, err))
+				continue
+			}
+			movedSteps++
+		}
+		if movedSteps > 0 {
+			moved++
+		}
+	}
+	checklist.ReassignedTasks += moved
+
+	if len(errs) == 0 {
+		now := time.Now()
+		for i := range checklist.Items {
+			item := &checklist.Items[i]
+			if item.AutoCheck == models.ChecklistCheckTasksReassign {
+				markChecklistItem(item, true, checklistSystemActor, now)
+				item.Note = fmt.Sprintf("โอนงานแล้ว %d งาน", checklist.ReassignedTasks)
+			}
+		}
+		updateChecklistStatus(checklist, now)
+	}
+	return moved, errs
+}
+
+// revokeAccess ปิดการเข้าระบบของพนักงาน: เปลี่ยนสถานะเป็น inactive บันทึก access_revoked_at (middleware ปฏิเสธ token เดิม) และยกเลิกลิงก์ปฏิทิน
+func (s *employeeChecklistService) revokeAccess(ctx context.Context, checklist *models.EmployeeChecklist, now time.Time) error {
+	if _, err := s.userRepo.UpdateUserByFilter(ctx, bson.M{"user_id": checklist.UserID}, bson.M{"$set": bson.M{
+		"status":            "inactive",
+		"access_revoked_at": now,
+		"updated_at":        now,
+	}}); err != nil {
+		return err
+	}
+	s.revokedMu.Lock()
+	s.revokedUsers[checklist.UserID] = struct{}{}
+	s.revokedMu.Unlock()
+
+	// ลิงก์ปฏิทิน (iCal) ไม่ผ่าน middleware ต้องยกเลิก token ด้วย
+	feeds, err := s.feedRepo.GetAllCalendarFeedsByFilter(ctx, bson.M{"user_id": checklist.UserID, "revoked_at": nil}, bson.M{})
+	if err != nil {
+		return err
+	}
+	for _, feed := range feeds {
+		feed.RevokedAt = &now
+		feed.UpdatedAt = now
+		if _, err := s.feedRepo.UpdateCalendarFeedByID(ctx, feed.FeedID, *feed); err != nil {
+			return err
+		}
+	}
+
+	checklist.AccessRevokedAt = &now
+	for i := range checklist.Items {
+		if checklist.Items[i].AutoCheck == models.ChecklistCheckAccessRevoked {
+			markChecklistItem(&checklist.Items[i], true, checklistSystemActor, now)
+		}
+	}
+	updateChecklistStatus(checklist, now)
+	return nil
+}
+
+// ---------- ดู / ติ๊กรายการ ----------
+
+func (s *employeeChecklistService) GetUserChecklists(ctx context.Context, userID string, claims *dto.JWTClaims) ([]dto.EmployeeChecklistDTO, error) {
+	userID = strings.TrimSpace(userID)
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	if claims.Role != "admin" && claims.UserID != userID && s.departmentManager(ctx, user.DepartmentID) != claims.UserID {
+		return nil, ports.ErrChecklistForbidden
+	}
+
+	checklists, err := s.checklistRepo.GetAllEmployeeChecklistsByFilter(ctx, bson.M{"user_id": userID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	names := newChecklistNames()
+	names.users[user.UserID] = user
+	out := make([]dto.EmployeeChecklistDTO, 0, len(checklists))
+	for _, c := range checklists {
+		if c.Kind == models.ChecklistOnboarding && refreshChecklistAutoItems(c, user, time.Now()) {
+			if err := s.saveChecklist(ctx, c); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, s.toEmployeeChecklistDTO(ctx, c, names))
+	}
+	return out, nil
+}
+
+func (s *employeeChecklistService) ListChecklists(ctx context.Context, req dto.RequestListChecklists, claims *dto.JWTClaims) (dto.Pagination, error) {
+	if claims.Role != "admin" {
+		return dto.Pagination{}, ports.ErrChecklistForbidden
+	}
+	page, size := req.Page, req.Limit
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 100 {
+		size = checklistDefaultListLimit
+	}
+	skip := int64((page - 1) * size)
+	limit := int64(size)
+
+	filter := bson.M{"deleted_at": nil}
+	if v := strings.TrimSpace(req.Kind); v != "" {
+		filter["kind"] = v
+	}
+	if v := strings.TrimSpace(req.Status); v != "" {
+		filter["status"] = v
+	}
+	if v := strings.TrimSpace(req.DepartmentID); v != "" {
+		filter["department_id"] = v
+	}
+
+	sortBy := bson.D{
+		{Key: "start_date", Value: -1},
+		{Key: "created_at", Value: -1},
+	}
+	items, total, err := s.checklistRepo.GetListEmployeeChecklistsByFilter(ctx, filter, bson.M{}, sortBy, skip, limit)
+	if err != nil {
+		return dto.Pagination{}, fmt.Errorf("list checklists: %w", err)
+	}
+
+	names := newChecklistNames()
+	list := make([]interface{}, 0, len(items))
+	for i := range items {
+		list = append(list, s.toEmployeeChecklistDTO(ctx, &items[i], names))
+	}
+
+	totalPages := 0
+	if total > 0 && size > 0 {
+		totalPages = int((total + int64(size) - 1) / int64(size))
+	}
+
+	return dto.Pagination{
+		Page:       page,
+		Size:       size,
+		TotalCount: int(total),
+		TotalPages: totalPages,
+		List:       list,
+	}, nil
+}
+
+func (s *employeeChecklistService) ListMyChecklistItems(ctx context.Context, claims *dto.JWTClaims) ([]dto.MyChecklistItemDTO, error) {
+	checklists, err := s.checklistRepo.GetAllEmployeeChecklistsByFilter(ctx, bson.M{"status": models.ChecklistOpen, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	today := helpers.Today()
+	names := newChecklistNames()
+	out := []dto.MyChecklistItemDTO{}
+	for _, c := range checklists {
+		for _, item := range c.Items {
+			if item.Status == models.ChecklistItemDone || !checklistItemOwnedBy(item, claims) {
+				continue
+			}
+			out = append(out, dto.MyChecklistItemDTO{
+				ChecklistID: c.ChecklistID,
+				Kind:        c.Kind,
+				UserID:      c.UserID,
+				UserName:    s.checklistUserName(ctx, names, c.UserID),
+				Item:        s.toChecklistItemDTO(ctx, item, names, today),
+			})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Item.DueDate.Before(out[j].Item.DueDate)
+	})
+	return out, nil
+}
+
+func (s *employeeChecklistService) UpdateChecklistItem(ctx context.Context, checklistID, itemID string, req dto.UpdateChecklistItemDTO, claims *dto.JWTClaims) (*dto.EmployeeChecklistDTO, error) {
+	checklist, err := s.checklistRepo.GetOneEmployeeChecklistByFilter(ctx, bson.M{"checklist_id": strings.TrimSpace(checklistID), "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if checklist == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	var item *models.ChecklistItem
+	for i := range checklist.Items {
+		if checklist.Items[i].ItemID == strings.TrimSpace(itemID) {
+			item = &checklist.Items[i]
+		}
+	}
+	if item == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	if claims.Role != "admin" && item.OwnerUserID != claims.UserID {
+		return nil, ports.ErrChecklistForbidden
+	}
+	if item.Owner == models.ChecklistOwnerSystem {
+		return nil, fmt.Errorf("%w: this item is completed automatically by the system", ports.ErrChecklistConflict)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, checklist.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	if item.AutoCheck != "" {
+		// รายการที่ตรวจจากข้อมูลจริง ติ๊กได้เมื่อข้อมูลครบ และเปิดกลับไม่ได้ถ้าข้อมูลยังครบอยู่
+		satisfied := checklistCheckSatisfied(user, item.AutoCheck)
+		if req.Done && !satisfied {
+			return nil, fmt.Errorf("%w: %s is not provided yet", ports.ErrChecklistConflict, item.AutoCheck)
+		}
+		if !req.Done && satisfied {
+			return nil, fmt.Errorf("%w: %s is already provided", ports.ErrChecklistConflict, item.AutoCheck)
+		}
+	}
+
+	now := time.Now()
+	markChecklistItem(item, req.Done, claims.UserID, now)
+	if note := strings.TrimSpace(req.Note); note != "" {
+		item.Note = note
+	}
+	if checklist.Kind == models.ChecklistOnboarding {
+		refreshChecklistAutoItems(checklist, user, now)
+	}
+	updateChecklistStatus(checklist, now)
+	if err := s.saveChecklist(ctx, checklist); err != nil {
+		return nil, err
+	}
+
+	names := newChecklistNames()
+	names.users[user.UserID] = user
+	out := s.toEmployeeChecklistDTO(ctx, checklist, names)
+	return &out, nil
+}
+
+// ---------- ตัวช่วย ----------
+
+// newChecklist สร้าง checklist จากแม่แบบของตำแหน่ง > แม่แบบค่าเริ่มต้น > รายการมาตรฐานของระบบ
+func (s *employeeChecklistService) newChecklist(ctx context.Context, kind string, user *models.User, start time.Time, actorID string) (*models.EmployeeChecklist, error) {
+	templateID := ""
+	items := defaultChecklistItems(kind)
+	candidates := []string{""}
+	if user.PositionID != "" {
+		candidates = []string{user.PositionID, ""}
+	}
+	for _, positionID := range candidates {
+		t, err := s.checklistRepo.GetOneChecklistTemplateByFilter(ctx, bson.M{"kind": kind, "position_id": positionID, "deleted_at": nil}, bson.M{})
+		if err != nil {
+			return nil, err
+		}
+		if t != nil {
+			templateID, items = t.TemplateID, t.Items
+			break
+		}
+	}
+
+	manager := s.departmentManager(ctx, user.DepartmentID)
+	now := time.Now()
+	checklist := &models.EmployeeChecklist{
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		StartDate:    start,
+		ChecklistID:  uuid.NewString(),
+		Kind:         kind,
+		UserID:       user.UserID,
+		TemplateID:   templateID,
+		PositionID:   user.PositionID,
+		DepartmentID: user.DepartmentID,
+		Status:       models.ChecklistOpen,
+		Items:        make([]models.ChecklistItem, 0, len(items)),
+		CreatedBy:    actorID,
+	}
+	for _, ti := range items {
+		item := models.ChecklistItem{
+			DueDate:     start.AddDate(0, 0, ti.DueDays),
+			ItemID:      ti.ItemID,
+			Title:       ti.Title,
+			Description: ti.Description,
+			Owner:       ti.Owner,
+			Mandatory:   ti.Mandatory,
+			AutoCheck:   ti.AutoCheck,
+			Status:      models.ChecklistItemPending,
+		}
+		if ti.Owner == models.ChecklistOwnerManager && manager != user.UserID {
+			item.OwnerUserID = manager
+		}
+		checklist.Items = append(checklist.Items, item)
+	}
+	updateChecklistStatus(checklist, now)
+	return checklist, nil
+}
+
+// createdBeforeChecklistGate ผู้ใช้ที่สร้างก่อน checklist onboarding รายการแรกของระบบ (ยังไม่มีเลย = ทุกคนเป็นพนักงานเดิม)
+func (s *employeeChecklistService) createdBeforeChecklistGate(ctx context.Context, user *models.User) (bool, error) {
+	first, _, err := s.checklistRepo.GetListEmployeeChecklistsByFilter(ctx, bson.M{"kind": models.ChecklistOnboarding}, bson.M{"_id": 0, "created_at": 1}, bson.D{{Key: "created_at", Value: 1}}, 0, 1)
+	if err != nil {
+		return false, err
+	}
+	if len(first) == 0 {
+		return true, nil
+	}
+	return user.CreatedAt.Before(first[0].CreatedAt), nil
+}
+
+func (s *employeeChecklistService) seedCompletedOnboarding(ctx context.Context, user *models.User) error {
+	checklist, err := s.newChecklist(ctx, models.ChecklistOnboarding, user, employmentStartDate(user), checklistSystemActor)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for i := range checklist.Items {
+		markChecklistItem(&checklist.Items[i], true, checklistSystemActor, now)
+	}
+	checklist.Note = "พนักงานเดิมก่อนเปิดใช้ checklist"
+	updateChecklistStatus(checklist, now)
+	return s.checklistRepo.CreateEmployeeChecklist(ctx, *checklist)
+}
+
+func (s *employeeChecklistService) saveChecklist(ctx context.Context, checklist *models.EmployeeChecklist) error {
+	checklist.UpdatedAt = time.Now()
+	updated, err := s.checklistRepo.UpdateEmployeeChecklist(ctx, bson.M{"checklist_id": checklist.ChecklistID, "deleted_at": nil}, bson.M{"$set": bson.M{
+		"items":             checklist.Items,
+		"status":            checklist.Status,
+		"completed_at":      checklist.CompletedAt,
+		"access_revoked_at": checklist.AccessRevokedAt,
+		"reassigned_tasks":  checklist.ReassignedTasks,
+		"updated_at":        checklist.UpdatedAt,
+	}})
+	if err != nil {
+		return err
+	}
+	if updated == nil {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (s *employeeChecklistService) departmentManager(ctx context.Context, departmentID string) string {
+	if departmentID == "" {
+		return ""
+	}
+	dept, err := s.departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{"department_id": departmentID, "deleted_at": nil}, bson.M{"_id": 0, "manager_id": 1})
+	if err != nil || dept == nil {
+		return ""
+	}
+	return dept.ManagerID
+}
+
+type checklistNames struct {
+	users       map[string]*models.User
+	departments map[string]string
+	positions   map[string]string
+}
+
+func newChecklistNames() *checklistNames {
+	return &checklistNames{users: map[string]*models.User{}, departments: map[string]string{}, positions: map[string]string{}}
+}
+
+func (s *employeeChecklistService) checklistUser(ctx context.Context, names *checklistNames, userID string) *models.User {
+	if userID == "" || userID == checklistSystemActor {
+		return nil
+	}
+	if u, ok := names.users[userID]; ok {
+		return u
+	}
+	u, _ := s.userRepo.GetByID(ctx, userID)
+	names.users[userID] = u
+	return u
+}
+
+func (s *employeeChecklistService) checklistUserName(ctx context.Context, names *checklistNames, userID string) string {
+	switch userID {
+	case "":
+		return ""
+	case checklistSystemActor:
+		return "ระบบ"
+	}
+	if u := s.checklistUser(ctx, names, userID); u != nil {
+		return strings.TrimSpace(u.FirstNameTH + " " + u.LastNameTH)
+	}
+	return "ไม่พบผู้ใช้"
+}
+
+func (s *employeeChecklistService) positionName(ctx context.Context, cache map[string]string, positionID string) string {
+	if positionID == "" {
+		return ""
+	}
+	if name, ok := cache[positionID]; ok {
+		return name
+	}
+	name := "ไม่พบตำแหน่ง"
+	if pos, _ := s.positionRepo.GetOnePositionByFilter(ctx, bson.M{"position_id": positionID}, bson.M{"_id": 0, "position_name": 1}); pos != nil {
+		name = pos.PositionName
+	}
+	cache[positionID] = name
+	return name
+}
+
+func (s *employeeChecklistService) departmentName(ctx context.Context, cache map[string]string, departmentID string) string {
+	if departmentID == "" {
+		return ""
+	}
+	if name, ok := cache[departmentID]; ok {
+		return name
+	}
+	name := "ไม่พบแผนก"
+	if dept, _ := s.departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{"department_id": departmentID}, bson.M{"_id": 0, "department_name": 1}); dept != nil {
+		name = dept.DepartmentName
+	}
+	cache[departmentID] = name
+	return name
+}
+
+func (s *employeeChecklistService) toChecklistTemplateDTO(ctx context.Context, t *models.ChecklistTemplate, positions map[string]string) dto.ChecklistTemplateDTO {
+	items := make([]dto.ChecklistTemplateItemDTO, 0, len(t.Items))
+	for _, it := range t.Items {
+		items = append(items, dto.ChecklistTemplateItemDTO{
+			ItemID:      it.ItemID,
+			Title:       it.Title,
+			Description: it.Description,
+			Owner:       it.Owner,
+			Mandatory:   it.Mandatory,
+			DueDays:     it.DueDays,
+			AutoCheck:   it.AutoCheck,
+		})
+	}
+	return dto.ChecklistTemplateDTO{
+		TemplateID:   t.TemplateID,
+		Kind:         t.Kind,
+		PositionID:   t.PositionID,
+		PositionName: s.positionName(ctx, positions, t.PositionID),
+		Name:         t.Name,
+		Items:        items,
+		UpdatedBy:    t.UpdatedBy,
+		UpdatedAt:    t.UpdatedAt,
+	}
+}
+
+func (s *employeeChecklistService) toEmployeeChecklistDTO(ctx context.Context, c *models.EmployeeChecklist, names *checklistNames) dto.EmployeeChecklistDTO {
+	today := helpers.Today()
+	out := dto.EmployeeChecklistDTO{
+		ChecklistID:     c.ChecklistID,
+		Kind:            c.Kind,
+		UserID:          c.UserID,
+		UserName:        s.checklistUserName(ctx, names, c.UserID),
+		PositionID:      c.PositionID,
+		PositionName:    s.positionName(ctx, names.positions, c.PositionID),
+		DepartmentID:    c.DepartmentID,
+		DepartmentName:  s.departmentName(ctx, names.departments, c.DepartmentID),
+		TemplateID:      c.TemplateID,
+		Status:          c.Status,
+		StartDate:       c.StartDate,
+		Total:           len(c.Items),
+		Items:           make([]dto.ChecklistItemDTO, 0, len(c.Items)),
+		ReassignTo:      c.ReassignTo,
+		ReassignToName:  s.checklistUserName(ctx, names, c.ReassignTo),
+		ReassignedTasks: c.ReassignedTasks,
+		AccessRevokedAt: c.AccessRevokedAt,
+		CompletedAt:     c.CompletedAt,
+		Note:            c.Note,
+		CreatedAt:       c.CreatedAt,
+	}
+	if u := s.checklistUser(ctx, names, c.UserID); u != nil {
+		out.EmployeeCode = u.EmployeeCode
+	}
+	for _, item := range c.Items {
+		if item.Status == models.ChecklistItemDone {
+			out.Done++
+		} else if item.Mandatory {
+			out.MandatoryPending++
+		}
+		out.Items = append(out.Items, s.toChecklistItemDTO(ctx, item, names, today))
+	}
+	return out
+}
+
+func (s *employeeChecklistService) toChecklistItemDTO(ctx context.Context, item models.ChecklistItem, names *checklistNames, today time.Time) dto.ChecklistItemDTO {
+	return dto.ChecklistItemDTO{
+		ItemID:          item.ItemID,
+		Title:           item.Title,
+		Description:     item.Description,
+		Owner:           item.Owner,
+		OwnerUserID:     item.OwnerUserID,
+		OwnerName:       s.checklistUserName(ctx, names, item.OwnerUserID),
+		Mandatory:       item.Mandatory,
+		AutoCheck:       item.AutoCheck,
+		Status:          item.Status,
+		DueDate:         item.DueDate,
+		Overdue:         item.Status != models.ChecklistItemDone && item.DueDate.Before(today),
+		CompletedBy:     item.CompletedBy,
+		CompletedByName: s.checklistUserName(ctx, names, item.CompletedBy),
+		CompletedAt:     item.CompletedAt,
+		Note:            item.Note,
+	}
+}
+
+// checklistItemOwnedBy ผู้จัดการเห็นรายการที่ระบุตัวไว้ admin เห็นรายการ HR/IT และรายการผู้จัดการที่แผนกไม่มีผู้จัดการ
+func checklistItemOwnedBy(item models.ChecklistItem, claims *dto.JWTClaims) bool {
+	if item.Owner == models.ChecklistOwnerSystem {
+		return false
+	}
+	if item.OwnerUserID != "" {
+		return item.OwnerUserID == claims.UserID
+	}
+	return claims.Role == "admin"
+}
+
+func markChecklistItem(item *models.ChecklistItem, done bool, actorID string, now time.Time) {
+	if done {
+		item.Status = models.ChecklistItemDone
+		item.CompletedBy = actorID
+		item.CompletedAt = &now
+		return
+	}
+	item.Status = models.ChecklistItemPending
+	item.CompletedBy = ""
+	item.CompletedAt = nil
+}
+
+// refreshChecklistAutoItems ติ๊กรายการที่ระบบตรวจพบว่าข้อมูลครบแล้ว คืน true ถ้ามีการเปลี่ยนแปลง
+func refreshChecklistAutoItems(checklist *models.EmployeeChecklist, user *models.User, now time.Time) bool {
+	changed := false
+	for i := range checklist.Items {
+		item := &checklist.Items[i]
+		if item.Status == models.ChecklistItemDone || item.AutoCheck == "" {
+			continue
+		}
+		if checklistCheckSatisfied(user, item.AutoCheck) {
+			markChecklistItem(item, true, checklistSystemActor, now)
+			changed = true
+		}
+	}
+	if changed {
+		updateChecklistStatus(checklist, now)
+	}
+	return changed
+}
+
+func updateChecklistStatus(checklist *models.EmployeeChecklist, now time.Time) {
+	for _, item := range checklist.Items {
+		if item.Mandatory && item.Status != models.ChecklistItemDone {
+			checklist.Status = models.ChecklistOpen
+			checklist.CompletedAt = nil
+			return
+		}
+	}
+	if checklist.Status != models.ChecklistCompleted || checklist.CompletedAt == nil {
+		checklist.Status = models.ChecklistCompleted
+		checklist.CompletedAt = &now
+	}
+}
+
+// checklistCheckSatisfied ตรวจข้อมูลจริงของพนักงานตาม auto_check (tasks_reassigned/access_revoked ระบบติ๊กเองจากขั้นตอน offboarding)
+func checklistCheckSatisfied(user *models.User, key string) bool {
+	switch {
+	case key == models.ChecklistCheckIDCard:
+		return strings.TrimSpace(user.IDCard) != "" && userHasDocument(user, "idcards")
+	case key == models.ChecklistCheckBankInfo:
+		b := user.BankInfo
+		return strings.TrimSpace(b.BankName) != "" && strings.TrimSpace(b.AccountNo) != "" && strings.TrimSpace(b.AccountName) != ""
+	case strings.HasPrefix(key, models.ChecklistCheckDocumentPrefix):
+		return userHasDocument(user, strings.TrimPrefix(key, models.ChecklistCheckDocumentPrefix))
+	}
+	return false
+}
+
+func userHasDocument(user *models.User, docType string) bool {
+	for _, doc := range user.Documents {
+		if doc.Type == docType && doc.DeletedAt == nil && strings.TrimSpace(doc.FileURL) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeChecklistItems(kind string, in []dto.ChecklistTemplateItemDTO) ([]models.ChecklistTemplateItem, error) {
+	if len(in) == 0 {
+		return nil, fmt.Errorf("items must not be empty")
+	}
+	seen := map[string]bool{}
+	out := make([]models.ChecklistTemplateItem, 0, len(in))
+	for i, it := range in {
+		title := strings.TrimSpace(it.Title)
+		if title == "" {
+			return nil, fmt.Errorf("items[%d].title is required", i)
+		}
+		owner := strings.TrimSpace(it.Owner)
+		autoCheck := strings.TrimSpace(it.AutoCheck)
+		switch autoCheck {
+		case "", models.ChecklistCheckIDCard, models.ChecklistCheckBankInfo:
+		case models.ChecklistCheckTasksReassign, models.ChecklistCheckAccessRevoked:
+			if kind != models.ChecklistOffboarding {
+				return nil, fmt.Errorf("items[%d].auto_check %s is only for offboarding", i, autoCheck)
+			}
+			owner = models.ChecklistOwnerSystem
+		default:
+			if !strings.HasPrefix(autoCheck, models.ChecklistCheckDocumentPrefix) || autoCheck == models.ChecklistCheckDocumentPrefix {
+				return nil, fmt.Errorf("items[%d].auto_check is invalid", i)
+			}
+		}
+		switch owner {
+		case models.ChecklistOwnerHR, models.ChecklistOwnerIT, models.ChecklistOwnerManager:
+		case models.ChecklistOwnerSystem:
+			if autoCheck != models.ChecklistCheckTasksReassign && autoCheck != models.ChecklistCheckAccessRevoked {
+				return nil, fmt.Errorf("items[%d].owner system requires auto_check tasks_reassigned or access_revoked", i)
+			}
+		default:
+			return nil, fmt.Errorf("items[%d].owner must be hr, it or manager", i)
+		}
+		itemID := strings.TrimSpace(it.ItemID)
+		if itemID == "" {
+			itemID = uuid.NewString()
+		}
+		if seen[itemID] {
+			return nil, fmt.Errorf("items[%d].item_id is duplicated", i)
+		}
+		seen[itemID] = true
+		out = append(out, models.ChecklistTemplateItem{
+			ItemID:      itemID,
+			Title:       title,
+			Description: strings.TrimSpace(it.Description),
+			Owner:       owner,
+			Mandatory:   it.Mandatory,
+			DueDays:     it.DueDays,
+			AutoCheck:   autoCheck,
+		})
+	}
+	return out, nil
+}
+
+// defaultChecklistItems รายการมาตรฐานเมื่อยังไม่ได้ตั้งแม่แบบ
+func defaultChecklistItems(kind string) []models.ChecklistTemplateItem {
+	if kind == models.ChecklistOffboarding {
+		return []models.ChecklistTemplateItem{
+			{ItemID: "tasks_reassigned", Title: "โอนงานที่ยังไม่ปิดให้ผู้รับงาน", Owner: models.ChecklistOwnerSystem, Mandatory: true, AutoCheck: models.ChecklistCheckTasksReassign},
+			{ItemID: "handover", Title: "ส่งมอบงานและเอกสาร", Owner: models.ChecklistOwnerManager, Mandatory: true},
+			{ItemID: "equipment", Title: "คืนอุปกรณ์ (โน้ตบุ๊ก บัตรพนักงาน กุญแจ)", Owner: models.ChecklistOwnerIT, Mandatory: true},
+			{ItemID: "final_pay", Title: "สรุปเงินเดือนงวดสุดท้ายและออกหนังสือรับรองการทำงาน", Owner: models.ChecklistOwnerHR, Mandatory: true, DueDays: 7},
+			{ItemID: "access_revoked", Title: "ยกเลิกสิทธิ์เข้าระบบ", Owner: models.ChecklistOwnerSystem, Mandatory: true, DueDays: 1, AutoCheck: models.ChecklistCheckAccessRevoked},
+		}
+	}
+	return []models.ChecklistTemplateItem{
+		{ItemID: "id_card", Title: "สำเนาบัตรประชาชน", Owner: models.ChecklistOwnerHR, Mandatory: true, AutoCheck: models.ChecklistCheckIDCard},
+		{ItemID: "contract", Title: "สัญญาจ้างที่ลงนามแล้ว", Owner: models.ChecklistOwnerHR, Mandatory: true, AutoCheck: models.ChecklistCheckDocumentPrefix + "contract"},
+		{ItemID: "bank_info", Title: "ข้อมูลบัญชีธนาคารสำหรับรับเงินเดือน", Owner: models.ChecklistOwnerHR, Mandatory: true, AutoCheck: models.ChecklistCheckBankInfo},
+		{ItemID: "it_account", Title: "เตรียมอุปกรณ์และบัญชีอีเมล", Owner: models.ChecklistOwnerIT},
+		{ItemID: "orientation", Title: "ปฐมนิเทศและแนะนำงาน", Owner: models.ChecklistOwnerManager, DueDays: 7},
+	}
+}
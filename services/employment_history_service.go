@@ -0,0 +1,589 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/config"
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type employmentHistoryService struct {
+	config         config.Config
+	historyRepo    ports.EmploymentHistoryRepository
+	userRepo       ports.UserRepository
+	departmentRepo ports.DepartmentRepository
+	positionRepo   ports.PositionRepository
+}
+
+func NewEmploymentHistoryService(cfg config.Config, historyRepo ports.EmploymentHistoryRepository, userRepo ports.UserRepository, departmentRepo ports.DepartmentRepository, positionRepo ports.PositionRepository) ports.EmploymentHistoryService {
+	return &employmentHistoryService{config: cfg, historyRepo: historyRepo, userRepo: userRepo, departmentRepo: departmentRepo, positionRepo: positionRepo}
+}
+
+// ---------- บันทึกการเปลี่ยนแปลง ----------
+
+func (s *employmentHistoryService) StartEmploymentHistory(ctx context.Context, user *models.User, actorID string) error {
+	entries, err := s.userHistories(ctx, user.UserID)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		now := time.Now()
+		start := employmentStartDate(user)
+		first := models.EmploymentHistory{
+			FromDate:       start,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+			HistoryID:      uuid.NewString(),
+			UserID:         user.UserID,
+			PositionID:     user.PositionID,
+			DepartmentID:   user.DepartmentID,
+			EmploymentType: user.EmploymentType,
+			ChangeType:     models.EmploymentChangeHire,
+			Status:         models.EmploymentApplied,
+			ChangedBy:      actorID,
+		}
+		// รายการแรกตรงกับข้อมูลพนักงานอยู่แล้ว ถือว่ามีผลทันทีแม้เริ่มงานในอนาคต
+		first.AppliedAt = &now
+		if err := s.historyRepo.CreateEmploymentHistory(ctx, first); err != nil {
+			return err
+		}
+		entries = []*models.EmploymentHistory{&first}
+	}
+	user.EmploymentHistory = flattenEmploymentHistory(entries)
+	return nil
+}
+
+func (s *employmentHistoryService) RecordEmploymentChange(ctx context.Context, user *models.User, change models.EmploymentHistory) error {
+	if err := s.StartEmploymentHistory(ctx, user, change.ChangedBy); err != nil {
+		return err
+	}
+	entries, err := s.userHistories(ctx, user.UserID)
+	if err != nil {
+		return err
+	}
+
+	today := helpers.Today()
+	from := today
+	if !change.FromDate.IsZero() {
+		from = helpers.DateOnly(change.FromDate)
+	}
+	if from.Before(entries[0].FromDate) {
+		return fmt.Errorf("%w: effective date %s is before the first employment record (%s)", ports.ErrEmploymentHistoryConflict, helpers.DayKey(from), helpers.DayKey(entries[0].FromDate))
+	}
+
+	// ค่าตั้งต้นมาจากรายการที่มีผล ณ วันนั้น แล้วทับด้วยค่าที่เปลี่ยน
+	base := employmentEntryAt(entries, from)
+	next := *base
+	if v := strings.TrimSpace(change.PositionID); v != "" {
+		next.PositionID = v
+	}
+	if v := strings.TrimSpace(change.DepartmentID); v != "" {
+		next.DepartmentID = v
+	}
+	if v := strings.TrimSpace(change.EmploymentType); v != "" {
+		next.EmploymentType = v
+	}
+
+	now := time.Now()
+	switch {
+	case len(employmentChanges(base, &next)) == 0:
+		// ไม่มีอะไรเปลี่ยน
+	case base.FromDate.Equal(from):
+		// เปลี่ยนซ้ำในวันที่มีผลเดียวกัน แก้รายการเดิมแทนการเปิดช่วงใหม่
+		set := bson.M{
+			"position_id":     next.PositionID,
+			"department_id":   next.DepartmentID,
+			"employment_type": next.EmploymentType,
+			"changed_by":      change.ChangedBy,
+			"updated_at":      now,
+		}
+		if note := strings.TrimSpace(change.Note); note != "" {
+			set["note"] = note
+		}
+		if _, err := s.historyRepo.UpdateEmploymentHistory(ctx, bson.M{"history_id": base.HistoryID, "deleted_at": nil}, bson.M{"$set": set}); err != nil {
+			return err
+		}
+	default:
+		entry := models.EmploymentHistory{
+			FromDate:       from,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+			HistoryID:      uuid.NewString(),
+			UserID:         user.UserID,
+			PositionID:     next.PositionID,
+			DepartmentID:   next.DepartmentID,
+			EmploymentType: next.EmploymentType,
+			ChangeType:     models.EmploymentChangeUpdate,
+			Status:         models.EmploymentScheduled,
+			ChangedBy:      change.ChangedBy,
+			Note:           strings.TrimSpace(change.Note),
+		}
+		if !from.After(today) {
+			entry.Status = models.EmploymentApplied
+			entry.AppliedAt = &now
+		}
+		if following := employmentEntryAfter(entries, from); following != nil {
+			toDate := following.FromDate
+			entry.ToDate = &toDate
+		}
+		if err := s.historyRepo.CreateEmploymentHistory(ctx, entry); err != nil {
+			return err
+		}
+		if _, err := s.historyRepo.UpdateEmploymentHistory(ctx, bson.M{"history_id": base.HistoryID, "deleted_at": nil}, bson.M{"$set": bson.M{"to_date": from, "updated_at": now}}); err != nil {
+			return err
+		}
+	}
+
+	// รายการที่ตั้งเวลาไว้หลังวันนี้ยังถือค่าเดิม ต้องพาค่าที่เปลี่ยนไปด้วย
+	for _, carry := range carryEmploymentChange(entries, base, &next, from) {
+		if _, err := s.historyRepo.UpdateEmploymentHistory(ctx, bson.M{"history_id": carry.historyID, "deleted_at": nil}, bson.M{"$set": carry.set}); err != nil {
+			return err
+		}
+	}
+
+	entries, err = s.userHistories(ctx, user.UserID)
+	if err != nil {
+		return err
+	}
+	applyCurrentEmployment(user, entries, today)
+	return nil
+}
+
+func (s *employmentHistoryService) ApplyDueEmploymentChanges(ctx context.Context, now time.Time) (*dto.EmploymentHistoryRunResult, error) {
+	result := &dto.EmploymentHistoryRunResult{RunAt: now, Errors: []string{}}
+	today := helpers.DateOnly(now.In(helpers.BangkokLocation()))
+
+	due, err := s.historyRepo.GetAllEmploymentHistoriesByFilter(ctx, bson.M{
+		"status":     models.EmploymentScheduled,
+		"from_date":  bson.M{"$lte": today},
+		"deleted_at": nil,
+	}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	result.Due = len(due)
+
+	byUser := map[string][]*models.EmploymentHistory{}
+	var userIDs []string
+	for _, h := range due {
+		if _, ok := byUser[h.UserID]; !ok {
+			userIDs = append(userIDs, h.UserID)
+		}
+		byUser[h.UserID] = append(byUser[h.UserID], h)
+	}
+
+	for _, userID := range userIDs {
+		if err := s.applyUserEmployment(ctx, userID, today); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("user %s: %v", userID, err))
+			continue
+		}
+		appliedAt := time.Now()
+		for _, h := range byUser[userID] {
+			if _, err := s.historyRepo.UpdateEmploymentHistory(ctx, bson.M{"history_id": h.HistoryID, "status": models.EmploymentScheduled}, bson.M{"$set": bson.M{"status": models.EmploymentApplied, "applied_at": appliedAt, "updated_at": appliedAt}}); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("history %s: %v", h.HistoryID, err))
+			}
+		}
+		result.Applied++
+	}
+	return result, nil
+}
+
+func (s *employmentHistoryService) CancelScheduledEmploymentChange(ctx context.Context, historyID string, claims *dto.JWTClaims) error {
+	if claims.Role != "admin" {
+		return ports.ErrEmploymentHistoryForbidden
+	}
+	entry, err := s.historyRepo.GetOneEmploymentHistoryByFilter(ctx, bson.M{"history_id": strings.TrimSpace(historyID), "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return mongo.ErrNoDocuments
+	}
+	if entry.Status != models.EmploymentScheduled {
+		return fmt.Errorf("%w: only scheduled changes can be cancelled", ports.ErrEmploymentHistoryConflict)
+	}
+
+	now := time.Now()
+	cancelled, err := s.historyRepo.UpdateEmploymentHistory(ctx, bson.M{"history_id": entry.HistoryID, "status": models.EmploymentScheduled, "deleted_at": nil}, bson.M{"$set": bson.M{"deleted_at": now, "updated_at": now}})
+	if err != nil {
+		return err
+	}
+	if cancelled == nil {
+		return fmt.Errorf("%w: change has already been applied", ports.ErrEmploymentHistoryConflict)
+	}
+
+	// ต่อช่วงก่อนหน้าให้ยาวถึงรายการถัดไป (หรือเปิดไว้ถ้าไม่มี)
+	entries, err := s.userHistories(ctx, entry.UserID)
+	if err != nil {
+		return err
+	}
+	var prev *models.EmploymentHistory
+	for _, h := range entries {
+		if h.FromDate.Before(entry.FromDate) {
+			prev = h
+		}
+	}
+	if prev != nil {
+		update := bson.M{"$set": bson.M{"to_date": entry.ToDate, "updated_at": now}}
+		if entry.ToDate == nil {
+			update = bson.M{"$set": bson.M{"updated_at": now}, "$unset": bson.M{"to_date": ""}}
+		}
+		if _, err := s.historyRepo.UpdateEmploymentHistory(ctx, bson.M{"history_id": prev.HistoryID}, update); err != nil {
+			return err
+		}
+	}
+	return s.applyUserEmployment(ctx, entry.UserID, helpers.Today())
+}
+
+// ---------- ดูประวัติ ----------
+
+func (s *employmentHistoryService) GetEmploymentTimeline(ctx context.Context, userID string, claims *dto.JWTClaims) (*dto.EmploymentTimelineDTO, error) {
+	userID = strings.TrimSpace(userID)
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	if claims.Role != "admin" && claims.UserID != userID {
+		dept, err := s.departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{"department_id": user.DepartmentID, "manager_id": claims.UserID, "deleted_at": nil}, bson.M{"_id": 0, "department_id": 1})
+		if err != nil {
+			return nil, err
+		}
+		if dept == nil {
+			return nil, ports.ErrEmploymentHistoryForbidden
+		}
+	}
+
+	entries, err := s.userHistories(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		// พนักงานเดิมที่ยังไม่เคยบันทึกประวัติ แสดงข้อมูลปัจจุบันตั้งแต่วันเริ่มงาน
+		entries = []*models.EmploymentHistory{{
+			FromDate:       employmentStartDate(user),
+			UserID:         user.UserID,
+			PositionID:     user.PositionID,
+			DepartmentID:   user.DepartmentID,
+			EmploymentType: user.EmploymentType,
+			ChangeType:     models.EmploymentChangeHire,
+			Status:         models.EmploymentApplied,
+		}}
+	}
+
+	today := helpers.Today()
+	current := employmentEntryAt(entries, today)
+	names := newLeaveNameCache()
+	positions := map[string]string{}
+	out := &dto.EmploymentTimelineDTO{
+		UserID:       user.UserID,
+		UserName:     strings.TrimSpace(user.FirstNameTH + " " + user.LastNameTH),
+		EmployeeCode: user.EmployeeCode,
+		HireDate:     user.HireDate,
+		Entries:      make([]dto.EmploymentTimelineEntryDTO, 0, len(entries)),
+	}
+	for i, h := range entries {
+		state := "past"
+		switch {
+		case h == current:
+			state = "current"
+		case h.FromDate.After(today):
+			state = "upcoming"
+		}
+		changes := []string{}
+		if i > 0 {
+			changes = employmentChanges(entries[i-1], h)
+		}
+		out.Entries = append(out.Entries, dto.EmploymentTimelineEntryDTO{
+			HistoryID:      h.HistoryID,
+			FromDate:       h.FromDate,
+			ToDate:         h.ToDate,
+			PositionID:     h.PositionID,
+			PositionName:   s.positionName(ctx, positions, h.PositionID),
+			DepartmentID:   h.DepartmentID,
+			DepartmentName: s.departmentName(ctx, names, h.DepartmentID),
+			EmploymentType: h.EmploymentType,
+			ChangeType:     h.ChangeType,
+			Status:         h.Status,
+			State:          state,
+			Changes:        changes,
+			ChangedBy:      h.ChangedBy,
+			ChangedByName:  s.userName(ctx, names, h.ChangedBy),
+			Note:           h.Note,
+		})
+	}
+	return out, nil
+}
+
+func (s *employmentHistoryService) GetHeadcountAsOf(ctx context.Context, req dto.RequestEmploymentHeadcount, claims *dto.JWTClaims) (*dto.EmploymentHeadcountDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrEmploymentHistoryForbidden
+	}
+	date := helpers.Today()
+	if strings.TrimSpace(req.Date) != "" {
+		d, err := helpers.ParseDate("date", req.Date, time.UTC)
+		if err != nil {
+			return nil, err
+		}
+		date = d
+	}
+	nextDay := date.AddDate(0, 0, 1)
+
+	// พนักงานที่เริ่มงานแล้วและยังไม่ถูกลบ ณ วันนั้น
+	users, err := s.userRepo.GetUserByFilter(ctx, bson.M{
+		"status":    "approved",
+		"hire_date": bson.M{"$lt": nextDay},
+		"$or":       []bson.M{{"deleted_at": nil}, {"deleted_at": bson.M{"$gte": nextDay}}},
+	}, bson.M{"_id": 0, "user_id": 1, "hire_date": 1, "created_at": 1, "position_id": 1, "department_id": 1, "employment_type": 1})
+	if err != nil {
+		return nil, err
+	}
+	userIDs := make([]string, 0, len(users))
+	for _, u := range users {
+		userIDs = append(userIDs, u.UserID)
+	}
+	histories, err := s.historyRepo.GetAllEmploymentHistoriesByFilter(ctx, bson.M{"user_id": bson.M{"$in": userIDs}, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	byUser := map[string][]*models.EmploymentHistory{}
+	for _, h := range histories {
+		byUser[h.UserID] = append(byUser[h.UserID], h)
+	}
+
+	rows := map[string]*dto.EmploymentHeadcountRow{}
+	out := &dto.EmploymentHeadcountDTO{Date: helpers.DayKey(date), Departments: []dto.EmploymentHeadcountRow{}}
+	for _, u := range users {
+		departmentID, employmentType := u.DepartmentID, u.EmploymentType
+		if entries := byUser[u.UserID]; len(entries) > 0 {
+			h := employmentEntryAt(entries, date)
+			if h.FromDate.After(date) {
+				continue // ยังไม่เริ่มงานตามประวัติ
+			}
+			departmentID, employmentType = h.DepartmentID, h.EmploymentType
+		}
+		if v := strings.TrimSpace(req.DepartmentID); v != "" && departmentID != v {
+			continue
+		}
+		row, ok := rows[departmentID]
+		if !ok {
+			row = &dto.EmploymentHeadcountRow{DepartmentID: departmentID, ByEmploymentType: map[string]int{}}
+			rows[departmentID] = row
+		}
+		row.Headcount++
+		row.ByEmploymentType[employmentType]++
+		out.Total++
+	}
+
+	names := newLeaveNameCache()
+	for _, row := range rows {
+		row.DepartmentName = s.departmentName(ctx, names, row.DepartmentID)
+		out.Departments = append(out.Departments, *row)
+	}
+	sort.SliceStable(out.Departments, func(i, j int) bool {
+		if out.Departments[i].Headcount != out.Departments[j].Headcount {
+			return out.Departments[i].Headcount > out.Departments[j].Headcount
+		}
+		return out.Departments[i].DepartmentName < out.Departments[j].DepartmentName
+	})
+	return out, nil
+}
+
+// ---------- ตัวช่วย ----------
+
+func (s *employmentHistoryService) userHistories(ctx context.Context, userID string) ([]*models.EmploymentHistory, error) {
+	if userID == "" {
+		return nil, errors.New("user_id is required")
+	}
+	return s.historyRepo.GetAllEmploymentHistoriesByFilter(ctx, bson.M{"user_id": userID, "deleted_at": nil}, bson.M{})
+}
+
+// applyUserEmployment ปรับข้อมูลพนักงานให้ตรงกับรายการที่มีผล ณ วันนั้น และเก็บสำเนาประวัติ
+func (s *employmentHistoryService) applyUserEmployment(ctx context.Context, userID string, today time.Time) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+	entries, err := s.userHistories(ctx, userID)
+	if err != nil {
+		return err
+	}
+	applyCurrentEmployment(user, entries, today)
+	_, err = s.userRepo.UpdateUserByFilter(ctx, bson.M{"user_id": userID}, bson.M{"$set": bson.M{
+		"position_id":        user.PositionID,
+		"department_id":      user.DepartmentID,
+		"employment_type":    user.EmploymentType,
+		"employment_history": user.EmploymentHistory,
+		"updated_at":         time.Now(),
+	}})
+	return err
+}
+
+func (s *employmentHistoryService) positionName(ctx context.Context, cache map[string]string, positionID string) string {
+	if positionID == "" {
+		return ""
+	}
+	if name, ok := cache[positionID]; ok {
+		return name
+	}
+	name := "ไม่พบตำแหน่ง"
+	if pos, _ := s.positionRepo.GetOnePositionByFilter(ctx, bson.M{"position_id": positionID}, bson.M{"_id": 0, "position_name": 1}); pos != nil {
+		name = pos.PositionName
+	}
+	cache[positionID] = name
+	return name
+}
+
+func (s *employmentHistoryService) departmentName(ctx context.Context, cache *leaveNameCache, departmentID string) string {
+	if departmentID == "" {
+		return ""
+	}
+	if name, ok := cache.departments[departmentID]; ok {
+		return name
+	}
+	name := "ไม่พบแผนก"
+	if dept, _ := s.departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{"department_id": departmentID}, bson.M{"_id": 0, "department_name": 1}); dept != nil {
+		name = dept.DepartmentName
+	}
+	cache.departments[departmentID] = name
+	return name
+}
+
+func (s *employmentHistoryService) userName(ctx context.Context, cache *leaveNameCache, userID string) string {
+	if userID == "" {
+		return ""
+	}
+	if name, ok := cache.users[userID]; ok {
+		return name
+	}
+	name := "ไม่พบผู้ใช้"
+	if user, _ := s.userRepo.GetByID(ctx, userID); user != nil {
+		name = strings.TrimSpace(user.FirstNameTH + " " + user.LastNameTH)
+	}
+	cache.users[userID] = name
+	return name
+}
+
+// employmentStartDate วันเริ่มงาน (ไม่มี = วันที่สร้างผู้ใช้) เป็นวันตามเวลาไทย
+func employmentStartDate(user *models.User) time.Time {
+	start := user.HireDate
+	if start.IsZero() {
+		start = user.CreatedAt
+	}
+	if start.IsZero() {
+		start = time.Now()
+	}
+	return helpers.DateOnly(start.In(helpers.BangkokLocation()))
+}
+
+// employmentEntryAt รายการที่มีผล ณ วันนั้น (entries เรียงตาม from_date) ถ้าวันนั้นก่อนรายการแรกคืนรายการแรก
+func employmentEntryAt(entries []*models.EmploymentHistory, day time.Time) *models.EmploymentHistory {
+	current := entries[0]
+	for _, h := range entries {
+		if h.FromDate.After(day) {
+			break
+		}
+		current = h
+	}
+	return current
+}
+
+func employmentEntryAfter(entries []*models.EmploymentHistory, day time.Time) *models.EmploymentHistory {
+	for _, h := range entries {
+		if h.FromDate.After(day) {
+			return h
+		}
+	}
+	return nil
+}
+
+type employmentCarry struct {
+	historyID string
+	set       bson.M
+}
+
+// carryEmploymentChange ค่าที่ต้องแก้ในรายการหลังวัน from: ฟิลด์ที่เปลี่ยนจะถูกพาไปจนถึงรายการที่ตั้งค่าฟิลด์นั้นเองไว้
+func carryEmploymentChange(entries []*models.EmploymentHistory, base, next *models.EmploymentHistory, from time.Time) []employmentCarry {
+	type field struct {
+		key       string
+		old, next string
+		get       func(*models.EmploymentHistory) string
+	}
+	fields := []*field{
+		{key: "position_id", old: base.PositionID, next: next.PositionID, get: func(h *models.EmploymentHistory) string { return h.PositionID }},
+		{key: "department_id", old: base.DepartmentID, next: next.DepartmentID, get: func(h *models.EmploymentHistory) string { return h.DepartmentID }},
+		{key: "employment_type", old: base.EmploymentType, next: next.EmploymentType, get: func(h *models.EmploymentHistory) string { return h.EmploymentType }},
+	}
+	out := []employmentCarry{}
+	for _, h := range entries {
+		if !h.FromDate.After(from) {
+			continue
+		}
+		set := bson.M{}
+		for _, f := range fields {
+			if f.old == f.next {
+				continue
+			}
+			if f.get(h) != f.old {
+				// รายการนี้เปลี่ยนฟิลด์นี้เอง หยุดพาค่าต่อ
+				f.next = f.old
+				continue
+			}
+			set[f.key] = f.next
+		}
+		if len(set) > 0 {
+			set["updated_at"] = time.Now()
+			out = append(out, employmentCarry{historyID: h.HistoryID, set: set})
+		}
+	}
+	return out
+}
+
+func employmentChanges(prev, next *models.EmploymentHistory) []string {
+	changes := []string{}
+	if prev.PositionID != next.PositionID {
+		changes = append(changes, "position")
+	}
+	if prev.DepartmentID != next.DepartmentID {
+		changes = append(changes, "department")
+	}
+	if prev.EmploymentType != next.EmploymentType {
+		changes = append(changes, "employment_type")
+	}
+	return changes
+}
+
+func applyCurrentEmployment(user *models.User, entries []*models.EmploymentHistory, today time.Time) {
+	user.EmploymentHistory = flattenEmploymentHistory(entries)
+	if len(entries) == 0 {
+		return
+	}
+	current := employmentEntryAt(entries, today)
+	user.PositionID = current.PositionID
+	user.DepartmentID = current.DepartmentID
+	user.EmploymentType = current.EmploymentType
+}
+
+func flattenEmploymentHistory(entries []*models.EmploymentHistory) []models.EmploymentHistory {
+	out := make([]models.EmploymentHistory, 0, len(entries))
+	for _, h := range entries {
+		out = append(out, *h)
+	}
+	return out
+}
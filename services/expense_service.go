@@ -10,6 +10,7 @@ import (
 	"github.com/Be2Bag/erp-demo/config"
 	"github.com/Be2Bag/erp-demo/dto"
 	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
 	"github.com/Be2Bag/erp-demo/pkg/util"
 	"github.com/Be2Bag/erp-demo/ports"
 	"github.com/google/uuid"
@@ -50,6 +51,7 @@ func (s *expenseService) CreateExpense(ctx context.Context, expense dto.CreateEx
 		TxnDate:               due,
 		PaymentMethod:         expense.PaymentMethod,
 		ReferenceNo:           expense.ReferenceNo,
+		JobID:                 expense.JobID,
 		Note:                  expense.Note,
 		CreatedBy:             claims.UserID,
 		CreatedAt:             now,
@@ -164,6 +166,7 @@ func (s *expenseService) ListExpenses(ctx context.Context, claims *dto.JWTClaims
 			TxnDate:                   m.TxnDate,
 			PaymentMethod:             m.PaymentMethod,
 			ReferenceNo:               m.ReferenceNo,
+			JobID:                     m.JobID,
 			Note:                      m.Note,
 			CreatedBy:                 m.CreatedBy,
 			CreatedAt:                 m.CreatedAt,
@@ -226,6 +229,7 @@ func (s *expenseService) GetExpenseByID(ctx context.Context, expenseID string, c
 		TxnDate:                   m.TxnDate,
 		PaymentMethod:             m.PaymentMethod,
 		ReferenceNo:               m.ReferenceNo,
+		JobID:                     m.JobID,
 		Note:                      m.Note,
 		CreatedBy:                 m.CreatedBy,
 		CreatedAt:                 m.CreatedAt,
@@ -277,6 +281,9 @@ func (s *expenseService) UpdateExpenseByID(ctx context.Context, expenseID string
 	if update.ReferenceNo != "" {
 		existing.ReferenceNo = update.ReferenceNo
 	}
+	if update.JobID != "" {
+		existing.JobID = update.JobID
+	}
 	if update.Note != nil {
 		existing.Note = update.Note
 	}
@@ -297,8 +304,7 @@ func (s *expenseService) DeleteExpenseByID(ctx context.Context, expenseID string
 
 func (s *expenseService) SummaryExpenseByFilter(ctx context.Context, claims *dto.JWTClaims, report dto.RequestExpenseSummary) (dto.ExpenseSummaryDTO, error) {
 	// หาวันที่ "วันนี้" ตาม timezone ไทย แต่ filter เป็น UTC ให้ตรงกับที่เก็บ
-	thailandLoc, _ := time.LoadLocation("Asia/Bangkok")
-	nowThai := time.Now().In(thailandLoc)
+	nowThai := time.Now().In(helpers.BangkokLocation())
 
 	// Today (ใช้วันที่ไทย แต่ filter เป็น UTC)
 	startToday := time.Date(nowThai.Year(), nowThai.Month(), nowThai.Day(), 0, 0, 0, 0, time.UTC)
@@ -10,6 +10,7 @@ import (
 	"github.com/Be2Bag/erp-demo/config"
 	"github.com/Be2Bag/erp-demo/dto"
 	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
 	"github.com/Be2Bag/erp-demo/pkg/util"
 	"github.com/Be2Bag/erp-demo/ports"
 	"github.com/google/uuid"
@@ -299,8 +300,7 @@ func (s *inComeService) DeleteInComeByInComeID(ctx context.Context, incomeID str
 
 func (s *inComeService) SummaryInComeByFilter(ctx context.Context, claims *dto.JWTClaims, report dto.RequestIncomeSummary) (dto.IncomeSummaryDTO, error) {
 	// หาวันที่ "วันนี้" ตาม timezone ไทย แต่ filter เป็น UTC ให้ตรงกับที่เก็บ
-	thailandLoc, _ := time.LoadLocation("Asia/Bangkok")
-	nowThai := time.Now().In(thailandLoc)
+	nowThai := time.Now().In(helpers.BangkokLocation())
 
 	// Today (ใช้วันที่ไทย แต่ filter เป็น UTC)
 	startToday := time.Date(nowThai.Year(), nowThai.Month(), nowThai.Day(), 0, 0, 0, 0, time.UTC)
@@ -0,0 +1,606 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/config"
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/pkg/util"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type jobCostService struct {
+	jobCostRepo  ports.JobCostRepository
+	signJobRepo  ports.SignJobRepository
+	taskRepo     ports.TaskRepository
+	userRepo     ports.UserRepository
+	positionRepo ports.PositionRepository
+	expenseRepo  ports.ExpenseRepository
+	payableRepo  ports.PayableRepository
+	dropDownRepo ports.DropDownRepository
+	config       config.Config
+}
+
+func NewJobCostService(cfg config.Config, jobCostRepo ports.JobCostRepository, signJobRepo ports.SignJobRepository, taskRepo ports.TaskRepository, userRepo ports.UserRepository, positionRepo ports.PositionRepository, expenseRepo ports.ExpenseRepository, payableRepo ports.PayableRepository, dropDownRepo ports.DropDownRepository) ports.JobCostService {
+	return &jobCostService{
+		config:       cfg,
+		jobCostRepo:  jobCostRepo,
+		signJobRepo:  signJobRepo,
+		taskRepo:     taskRepo,
+		userRepo:     userRepo,
+		positionRepo: positionRepo,
+		expenseRepo:  expenseRepo,
+		payableRepo:  payableRepo,
+		dropDownRepo: dropDownRepo,
+	}
+}
+
+// laborRateLookup เก็บอัตราค่าแรงและตำแหน่งของผู้ใช้ไว้ใช้ซ้ำ ระหว่างคำนวณหลายงานในรายงานเดียว
+type laborRateLookup struct {
+	byUser       map[string]float64
+	byPosition   map[string]float64
+	userPosition map[string]string
+}
+
+func (s *jobCostService) CreateJobMaterial(ctx context.Context, req dto.CreateJobMaterialDTO, claims *dto.JWTClaims) error {
+	jobID := strings.TrimSpace(req.JobID)
+	name := strings.TrimSpace(req.Name)
+	if jobID == "" || name == "" {
+		return errors.New("job_id and name are required")
+	}
+	if req.Quantity <= 0 {
+		return errors.New("quantity must be greater than 0")
+	}
+	if req.UnitCost < 0 {
+		return errors.New("unit_cost must be >= 0")
+	}
+
+	job, err := s.signJobRepo.GetOneSignJobByFilter(ctx, bson.M{"job_id": jobID, "deleted_at": nil}, bson.M{"_id": 0, "job_id": 1})
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		return mongo.ErrNoDocuments
+	}
+
+	now := time.Now()
+	usedAt := now
+	if strings.TrimSpace(req.UsedAt) != "" {
+		t, err := time.Parse("2006-01-02", req.UsedAt)
+		if err != nil {
+			return fmt.Errorf("invalid used_at, want YYYY-MM-DD: %w", err)
+		}
+		usedAt = t
+	}
+
+	model := models.JobMaterial{
+		MaterialID: uuid.NewString(),
+		JobID:      jobID,
+		Name:       name,
+		Unit:       strings.TrimSpace(req.Unit),
+		Note:       strings.TrimSpace(req.Note),
+		Quantity:   req.Quantity,
+		UnitCost:   util.Round2(req.UnitCost),
+		Total:      util.Round2(req.Quantity * req.UnitCost),
+		UsedAt:     usedAt,
+		CreatedBy:  claims.UserID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	return s.jobCostRepo.CreateJobMaterial(ctx, model)
+}
+
+func (s *jobCostService) DeleteJobMaterial(ctx context.Context, materialID string, claims *dto.JWTClaims) error {
+	existing, err := s.jobCostRepo.GetOneJobMaterialByFilter(ctx, bson.M{"material_id": materialID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return mongo.ErrNoDocuments
+	}
+	return s.jobCostRepo.SoftDeleteJobMaterialByID(ctx, materialID)
+}
+
+func (s *jobCostService) UpsertLaborRate(ctx context.Context, req dto.UpsertLaborRateDTO, claims *dto.JWTClaims) error {
+	userID := strings.TrimSpace(req.UserID)
+	positionID := strings.TrimSpace(req.PositionID)
+	if (userID == "") == (positionID == "") {
+		return errors.New("either user_id or position_id is required")
+	}
+	if req.HourlyRate < 0 {
+		return errors.New("hourly_rate must be >= 0")
+	}
+
+	filter := bson.M{"deleted_at": nil}
+	if userID != "" {
+		filter["user_id"] = userID
+	} else {
+		filter["position_id"] = positionID
+	}
+
+	existing, err := s.jobCostRepo.GetOneLaborRateByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return err
+	}
+
+	if existing != nil {
+		existing.HourlyRate = util.Round2(req.HourlyRate)
+		_, err := s.jobCostRepo.UpdateLaborRateByID(ctx, existing.RateID, *existing)
+		return err
+	}
+
+	now := time.Now()
+	model := models.LaborRate{
+		RateID:     uuid.NewString(),
+		UserID:     userID,
+		PositionID: positionID,
+		HourlyRate: util.Round2(req.HourlyRate),
+		CreatedBy:  claims.UserID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	return s.jobCostRepo.CreateLaborRate(ctx, model)
+}
+
+func (s *jobCostService) ListLaborRates(ctx context.Context, claims *dto.JWTClaims) ([]dto.LaborRateDTO, error) {
+	rates, err := s.jobCostRepo.GetAllLaborRatesByFilter(ctx, bson.M{"deleted_at": nil}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list labor rates: %w", err)
+	}
+
+	result := make([]dto.LaborRateDTO, 0, len(rates))
+	for _, r := range rates {
+		item := dto.LaborRateDTO{
+			RateID:     r.RateID,
+			UserID:     r.UserID,
+			PositionID: r.PositionID,
+			HourlyRate: r.HourlyRate,
+			UpdatedAt:  r.UpdatedAt,
+		}
+		if r.UserID != "" {
+			users, err := s.userRepo.GetUserByFilter(ctx, bson.M{"user_id": r.UserID}, bson.M{"title_th": 1, "first_name_th": 1, "last_name_th": 1})
+			if err != nil {
+				return nil, fmt.Errorf("get user: %w", err)
+			}
+			if len(users) > 0 {
+				item.UserName = fmt.Sprintf("%s %s %s", users[0].TitleTH, users[0].FirstNameTH, users[0].LastNameTH)
+			}
+		}
+		if r.PositionID != "" {
+			position, err := s.positionRepo.GetOnePositionByFilter(ctx, bson.M{"position_id": r.PositionID, "deleted_at": nil}, bson.M{})
+			if err != nil {
+				return nil, fmt.Errorf("get position: %w", err)
+			}
+			if position != nil {
+				item.PositionName = position.PositionName
+			}
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+func (s *jobCostService) DeleteLaborRate(ctx context.Context, rateID string, claims *dto.JWTClaims) error {
+	existing, err := s.jobCostRepo.GetOneLaborRateByFilter(ctx, bson.M{"rate_id": rateID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return mongo.ErrNoDocuments
+	}
+	return s.jobCostRepo.SoftDeleteLaborRateByID(ctx, rateID)
+}
+
+func (s *jobCostService) GetJobCost(ctx context.Context, jobID string, claims *dto.JWTClaims) (*dto.JobCostDTO, error) {
+	job, err := s.signJobRepo.GetOneSignJobByFilter(ctx, bson.M{"job_id": jobID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	lookup, err := s.loadLaborRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	inputs, err := s.loadJobCostInputs(ctx, []string{job.JobID})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.computeJobCost(ctx, job, lookup, inputs, true)
+	if err != nil {
+		return nil, err
+	}
+
+	signTypeNames, customerTypeNames, err := s.loadTypeNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.SignTypeName = signTypeNames[job.SignTypeID]
+	result.CustomerTypeName = customerTypeNames[job.CustomerTypeID]
+
+	return result, nil
+}
+
+func (s *jobCostService) SummaryMarginBySignType(ctx context.Context, req dto.RequestJobMarginSummary, claims *dto.JWTClaims) ([]dto.JobMarginGroupDTO, error) {
+	costs, err := s.computeJobCostsInRange(ctx, req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	signTypeNames, _, err := s.loadTypeNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return groupJobMargins(costs, func(c dto.JobCostDTO) string { return c.SignTypeID }, signTypeNames), nil
+}
+
+func (s *jobCostService) SummaryMarginByCustomerType(ctx context.Context, req dto.RequestJobMarginSummary, claims *dto.JWTClaims) ([]dto.JobMarginGroupDTO, error) {
+	costs, err := s.computeJobCostsInRange(ctx, req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	_, customerTypeNames, err := s.loadTypeNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return groupJobMargins(costs, func(c dto.JobCostDTO) string { return c.CustomerTypeID }, customerTypeNames), nil
+}
+
+func (s *jobCostService) LeastProfitableJobs(ctx context.Context, req dto.RequestLeastProfitableJobs, claims *dto.JWTClaims) ([]dto.JobCostDTO, error) {
+	loc := helpers.BangkokLocation()
+
+	month := time.Now().In(loc)
+	if strings.TrimSpace(req.Month) != "" {
+		t, err := time.ParseInLocation("2006-01", req.Month, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid month, want YYYY-MM: %w", err)
+		}
+		month = t
+	}
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, loc)
+	end := start.AddDate(0, 1, 0)
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+
+	filter := bson.M{
+		"deleted_at": nil,
+		"created_at": bson.M{"$gte": start, "$lt": end},
+	}
+	costs, err := s.computeJobCosts(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// เรียงตามอัตรากำไรขั้นต้นจากน้อยไปมาก ถ้าเท่ากันให้ดูกำไรขั้นต้น (บาท)
+	sort.SliceStable(costs, func(i, j int) bool {
+		if costs[i].GrossMarginPercent != costs[j].GrossMarginPercent {
+			return costs[i].GrossMarginPercent < costs[j].GrossMarginPercent
+		}
+		return costs[i].GrossProfit < costs[j].GrossProfit
+	})
+	if len(costs) > limit {
+		costs = costs[:limit]
+	}
+
+	signTypeNames, customerTypeNames, err := s.loadTypeNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range costs {
+		costs[i].SignTypeName = signTypeNames[costs[i].SignTypeID]
+		costs[i].CustomerTypeName = customerTypeNames[costs[i].CustomerTypeID]
+	}
+	return costs, nil
+}
+
+func (s *jobCostService) computeJobCostsInRange(ctx context.Context, startDate, endDate string) ([]dto.JobCostDTO, error) {
+	filter := bson.M{"deleted_at": nil}
+	createdAt := bson.M{}
+	if strings.TrimSpace(startDate) != "" {
+		t, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start_date, want YYYY-MM-DD: %w", err)
+		}
+		createdAt["$gte"] = t
+	}
+	if strings.TrimSpace(endDate) != "" {
+		t, err := time.Parse("2006-01-02", endDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end_date, want YYYY-MM-DD: %w", err)
+		}
+		createdAt["$lt"] = t.AddDate(0, 0, 1)
+	}
+	if len(createdAt) > 0 {
+		filter["created_at"] = createdAt
+	}
+	return s.computeJobCosts(ctx, filter)
+}
+
+func (s *jobCostService) computeJobCosts(ctx context.Context, filter bson.M) ([]dto.JobCostDTO, error) {
+	jobs, err := s.signJobRepo.GetAllSignJobByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("get sign jobs: %w", err)
+	}
+
+	lookup, err := s.loadLaborRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	jobIDs := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		jobIDs = append(jobIDs, job.JobID)
+	}
+	inputs, err := s.loadJobCostInputs(ctx, jobIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	costs := make([]dto.JobCostDTO, 0, len(jobs))
+	for _, job := range jobs {
+		c, err := s.computeJobCost(ctx, job, lookup, inputs, false)
+		if err != nil {
+			return nil, err
+		}
+		costs = append(costs, *c)
+	}
+	return costs, nil
+}
+
+// computeJobCost รวมต้นทุนจริงของงานป้าย 1 งาน
+//   - วัสดุ: job_materials ที่ผูกกับ job_id
+//   - ค่าแรง: ชั่วโมงของขั้นตอนที่ทำเสร็จแล้ว (done) x อัตราค่าแรงของผู้รับผิดชอบงาน
+//   - รายจ่าย: expenses ที่ผูก job_id
+//   - เจ้าหนี้: payables ที่ผูก job_id (นับยอดเต็ม ไม่ใช่ยอดที่ชำระ เพราะรายจ่ายจากการชำระหนี้ไม่ได้ผูก job_id)
+func (s *jobCostService) computeJobCost(ctx context.Context, job *models.SignJob, lookup *laborRateLookup, inputs *jobCostInputs, withDetail bool) (*dto.JobCostDTO, error) {
+	result := &dto.JobCostDTO{
+		CreatedAt:      job.CreatedAt,
+		JobID:          job.JobID,
+		JobName:        job.JobName,
+		CompanyName:    job.CompanyName,
+		SignTypeID:     job.SignTypeID,
+		CustomerTypeID: job.CustomerTypeID,
+		Status:         job.Status,
+		QuotedPrice:    util.Round2(job.PriceTHB),
+	}
+
+	// ---------- วัสดุ ----------
+	for _, m := range inputs.materials[job.JobID] {
+		result.MaterialCost += m.Total
+		if withDetail {
+			result.Materials = append(result.Materials, dto.JobMaterialDTO{
+				UsedAt:     m.UsedAt,
+				CreatedAt:  m.CreatedAt,
+				MaterialID: m.MaterialID,
+				JobID:      m.JobID,
+				Name:       m.Name,
+				Unit:       m.Unit,
+				Note:       m.Note,
+				CreatedBy:  m.CreatedBy,
+				Quantity:   m.Quantity,
+				UnitCost:   m.UnitCost,
+				Total:      m.Total,
+			})
+		}
+	}
+
+	// ---------- ค่าแรง ----------
+	for _, t := range inputs.tasks[job.JobID] {
+		for _, st := range t.AppliedWorkflow.Steps {
+			if st.Status != "done" || st.Hours <= 0 {
+				continue
+			}
+			// คิดค่าแรงตามอัตราของเจ้าของ step (ถ้าไม่ได้ระบุใช้ผู้รับผิดชอบงาน)
+			owner := stepOwner(t, st)
+			rate, err := s.hourlyRateFor(ctx, lookup, owner)
+			if err != nil {
+				return nil, err
+			}
+			cost := util.Round2(st.Hours * rate)
+			result.LaborCost += cost
+			if withDetail {
+				result.LaborLines = append(result.LaborLines, dto.JobLaborLineDTO{
+					TaskID:       t.TaskID,
+					StepID:       st.StepID,
+					StepName:     st.StepName,
+					AssigneeID:   owner,
+					AssigneeName: stepAssigneeName(t, st),
+					Hours:        st.Hours,
+					HourlyRate:   rate,
+					Cost:         cost,
+				})
+			}
+		}
+	}
+
+	// ---------- รายจ่าย / เจ้าหนี้ ----------
+	result.ExpenseCost = inputs.expenses[job.JobID]
+	result.PayableCost = inputs.payables[job.JobID]
+
+	result.MaterialCost = util.Round2(result.MaterialCost)
+	result.LaborCost = util.Round2(result.LaborCost)
+	result.ExpenseCost = util.Round2(result.ExpenseCost)
+	result.PayableCost = util.Round2(result.PayableCost)
+	result.TotalCost = util.Round2(result.MaterialCost + result.LaborCost + result.ExpenseCost + result.PayableCost)
+	result.GrossProfit = util.Round2(result.QuotedPrice - result.TotalCost)
+	result.GrossMarginPercent = grossMarginPercent(result.QuotedPrice, result.GrossProfit)
+
+	return result, nil
+}
+
+// jobCostInputs ข้อมูลต้นทุนของหลายงานที่โหลดครั้งเดียวด้วย $in แยกตาม job_id
+type jobCostInputs struct {
+	materials map[string][]*models.JobMaterial
+	tasks     map[string][]*models.Tasks
+	expenses  map[string]float64
+	payables  map[string]float64
+}
+
+func (s *jobCostService) loadJobCostInputs(ctx context.Context, jobIDs []string) (*jobCostInputs, error) {
+	inputs := &jobCostInputs{
+		materials: make(map[string][]*models.JobMaterial),
+		tasks:     make(map[string][]*models.Tasks),
+		expenses:  make(map[string]float64),
+		payables:  make(map[string]float64),
+	}
+	if len(jobIDs) == 0 {
+		return inputs, nil
+	}
+	filter := bson.M{"job_id": bson.M{"$in": jobIDs}, "deleted_at": nil}
+
+	materials, err := s.jobCostRepo.GetAllJobMaterialsByFilter(ctx, filter, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get job materials: %w", err)
+	}
+	for _, m := range materials {
+		inputs.materials[m.JobID] = append(inputs.materials[m.JobID], m)
+	}
+
+	tasks, err := s.taskRepo.GetAllTaskByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("get tasks: %w", err)
+	}
+	for _, t := range tasks {
+		inputs.tasks[t.JobID] = append(inputs.tasks[t.JobID], t)
+	}
+
+	expenses, err := s.expenseRepo.GetAllExpenseByFilter(ctx, filter, bson.M{"job_id": 1, "amount": 1})
+	if err != nil {
+		return nil, fmt.Errorf("get expenses: %w", err)
+	}
+	for _, e := range expenses {
+		inputs.expenses[e.JobID] += e.Amount
+	}
+
+	payables, err := s.payableRepo.GetAllPayablesByFilter(ctx, filter, bson.M{"job_id": 1, "amount": 1})
+	if err != nil {
+		return nil, fmt.Errorf("get payables: %w", err)
+	}
+	for _, p := range payables {
+		inputs.payables[p.JobID] += p.Amount
+	}
+	return inputs, nil
+}
+
+func (s *jobCostService) loadLaborRates(ctx context.Context) (*laborRateLookup, error) {
+	rates, err := s.jobCostRepo.GetAllLaborRatesByFilter(ctx, bson.M{"deleted_at": nil}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get labor rates: %w", err)
+	}
+	lookup := &laborRateLookup{
+		byUser:       make(map[string]float64),
+		byPosition:   make(map[string]float64),
+		userPosition: make(map[string]string),
+	}
+	for _, r := range rates {
+		if r.UserID != "" {
+			lookup.byUser[r.UserID] = r.HourlyRate
+		} else if r.PositionID != "" {
+			lookup.byPosition[r.PositionID] = r.HourlyRate
+		}
+	}
+	return lookup, nil
+}
+
+// hourlyRateFor อัตรารายบุคคลก่อน ถ้าไม่มีใช้อัตราตามตำแหน่ง ถ้าไม่มีทั้งคู่ถือว่า 0
+func (s *jobCostService) hourlyRateFor(ctx context.Context, lookup *laborRateLookup, userID string) (float64, error) {
+	if userID == "" {
+		return 0, nil
+	}
+	if rate, ok := lookup.byUser[userID]; ok {
+		return rate, nil
+	}
+
+	positionID, ok := lookup.userPosition[userID]
+	if !ok {
+		users, err := s.userRepo.GetUserByFilter(ctx, bson.M{"user_id": userID}, bson.M{"position_id": 1})
+		if err != nil {
+			return 0, fmt.Errorf("get user: %w", err)
+		}
+		if len(users) > 0 {
+			positionID = users[0].PositionID
+		}
+		lookup.userPosition[userID] = positionID
+	}
+	return lookup.byPosition[positionID], nil
+}
+
+func (s *jobCostService) loadTypeNames(ctx context.Context) (map[string]string, map[string]string, error) {
+	signTypes, err := s.dropDownRepo.GetSignTypes(ctx, bson.M{"deleted_at": nil}, bson.M{"type_id": 1, "name_th": 1})
+	if err != nil {
+		return nil, nil, fmt.Errorf("get sign types: %w", err)
+	}
+	customerTypes, err := s.dropDownRepo.GetCustomerTypes(ctx, bson.M{"deleted_at": nil}, bson.M{"type_id": 1, "name_th": 1})
+	if err != nil {
+		return nil, nil, fmt.Errorf("get customer types: %w", err)
+	}
+
+	signTypeNames := make(map[string]string, len(signTypes))
+	for _, st := range signTypes {
+		signTypeNames[st.TypeID] = st.NameTH
+	}
+	customerTypeNames := make(map[string]string, len(customerTypes))
+	for _, ct := range customerTypes {
+		customerTypeNames[ct.TypeID] = ct.NameTH
+	}
+	return signTypeNames, customerTypeNames, nil
+}
+
+func groupJobMargins(costs []dto.JobCostDTO, keyOf func(dto.JobCostDTO) string, names map[string]string) []dto.JobMarginGroupDTO {
+	groups := make(map[string]*dto.JobMarginGroupDTO)
+	order := make([]string, 0)
+	for _, c := range costs {
+		key := keyOf(c)
+		g, ok := groups[key]
+		if !ok {
+			name := names[key]
+			if name == "" {
+				name = "ไม่ระบุ"
+			}
+			g = &dto.JobMarginGroupDTO{GroupID: key, GroupName: name}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.JobCount++
+		g.QuotedPrice += c.QuotedPrice
+		g.TotalCost += c.TotalCost
+	}
+
+	result := make([]dto.JobMarginGroupDTO, 0, len(order))
+	for _, key := range order {
+		g := groups[key]
+		g.QuotedPrice = util.Round2(g.QuotedPrice)
+		g.TotalCost = util.Round2(g.TotalCost)
+		g.GrossProfit = util.Round2(g.QuotedPrice - g.TotalCost)
+		g.GrossMarginPercent = grossMarginPercent(g.QuotedPrice, g.GrossProfit)
+		result = append(result, *g)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].GrossMarginPercent < result[j].GrossMarginPercent
+	})
+	return result
+}
+
+func grossMarginPercent(revenue, profit float64) float64 {
+	if revenue <= 0 {
+		return 0
+	}
+	return util.Round2(profit / revenue * 100)
+}
@@ -0,0 +1,598 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/config"
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/pkg/util"
+	"github.com/Be2Bag/erp-demo/ports"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	kpiAnalyticsDefaultMonths = 12 // ช่วงเริ่มต้นย้อนหลัง 12 เดือน (รวมเดือนปัจจุบัน)
+	kpiAnalyticsMaxLimit      = 50
+	kpiUncategorized          = "uncategorized"
+)
+
+type kpiAnalyticsService struct {
+	config            config.Config
+	kpiEvaluationRepo ports.KPIEvaluationRepository
+	taskRepo          ports.TaskRepository
+	userRepo          ports.UserRepository
+	departmentRepo    ports.DepartmentRepository
+}
+
+func NewKPIAnalyticsService(cfg config.Config, kpiEvaluationRepo ports.KPIEvaluationRepository, taskRepo ports.TaskRepository, userRepo ports.UserRepository, departmentRepo ports.DepartmentRepository) ports.KPIAnalyticsService {
+	return &kpiAnalyticsService{config: cfg, kpiEvaluationRepo: kpiEvaluationRepo, taskRepo: taskRepo, userRepo: userRepo, departmentRepo: departmentRepo}
+}
+
+// kpiAnalyticsScope ขอบเขตข้อมูลที่ผู้เรียกดูได้ (ค่าเฉลี่ยบริษัทคิดจากทุกแผนกเสมอ)
+type kpiAnalyticsScope struct {
+	departments []string // ว่าง = ทุกแผนก
+	userID      string   // ไม่ว่าง = ดูได้เฉพาะของตนเอง
+}
+
+func (sc kpiAnalyticsScope) allows(ev *models.KPIEvaluation) bool {
+	if sc.userID != "" {
+		return ev.EvaluateeID == sc.userID
+	}
+	return len(sc.departments) == 0 || helpers.InSet(ev.Department, sc.departments...)
+}
+
+type kpiAnalyticsRange struct {
+	start    time.Time
+	end      time.Time
+	interval string
+	periods  []string
+}
+
+func (s *kpiAnalyticsService) KPITrends(ctx context.Context, req dto.RequestKPIAnalytics, claims *dto.JWTClaims) (*dto.KPITrendDTO, error) {
+	groupBy := strings.ToLower(strings.TrimSpace(req.GroupBy))
+	if groupBy == "" {
+		groupBy = "department"
+	}
+	if !helpers.InSet(groupBy, "employee", "category", "department") {
+		return nil, fmt.Errorf("group_by must be employee, category or department")
+	}
+	rng, err := kpiAnalyticsPeriod(req)
+	if err != nil {
+		return nil, err
+	}
+	scope, err := s.scope(ctx, req, claims)
+	if err != nil {
+		return nil, err
+	}
+	// พนักงานทั่วไปดูแนวโน้มของตนเองได้เท่านั้น
+	if scope.userID != "" && groupBy == "department" {
+		return nil, ports.ErrKPIAnalyticsForbidden
+	}
+	userID := strings.TrimSpace(req.UserID)
+
+	evaluations, err := s.loadEvaluations(ctx, rng)
+	if err != nil {
+		return nil, err
+	}
+
+	company := newKPITrendAcc()
+	groups := make(map[string]*kpiTrendAcc)
+	add := func(key, period string, v float64) {
+		acc, ok := groups[key]
+		if !ok {
+			acc = newKPITrendAcc()
+			groups[key] = acc
+		}
+		acc.add(period, v)
+	}
+	for _, ev := range evaluations {
+		period := helpers.KPIPeriodKey(ev.UpdatedAt.In(rng.start.Location()), rng.interval)
+		company.add(period, kpiEvaluationScore(ev))
+		if !scope.allows(ev) || (userID != "" && ev.EvaluateeID != userID) {
+			continue
+		}
+		switch groupBy {
+		case "employee":
+			add(ev.EvaluateeID, period, kpiEvaluationScore(ev))
+		case "department":
+			add(ev.Department, period, kpiEvaluationScore(ev))
+		case "category":
+			for category, items := range kpiScoresByCategory(ev.Scores) {
+				add(category, period, helpers.WeightedKPIPercent(items))
+			}
+		}
+	}
+
+	companyScore := company.score()
+	out := &dto.KPITrendDTO{
+		GroupBy:   groupBy,
+		Interval:  rng.interval,
+		StartDate: rng.start,
+		EndDate:   rng.end,
+		Periods:   rng.periods,
+		Company:   company.series("company", "ค่าเฉลี่ยทั้งบริษัท", rng.periods, companyScore),
+		Series:    make([]dto.KPITrendSeriesDTO, 0, len(groups)),
+	}
+
+	names := s.groupNames(ctx, groupBy, groups)
+	for key, acc := range groups {
+		out.Series = append(out.Series, acc.series(key, names[key], rng.periods, companyScore))
+	}
+	sort.SliceStable(out.Series, func(i, j int) bool {
+		if out.Series[i].Score != out.Series[j].Score {
+			return out.Series[i].Score > out.Series[j].Score
+		}
+		return out.Series[i].Key < out.Series[j].Key
+	})
+	return out, nil
+}
+
+func (s *kpiAnalyticsService) KPIDistribution(ctx context.Context, req dto.RequestKPIAnalytics, claims *dto.JWTClaims) (*dto.KPIDistributionDTO, error) {
+	rng, err := kpiAnalyticsPeriod(req)
+	if err != nil {
+		return nil, err
+	}
+	scope, err := s.managerScope(ctx, req, claims)
+	if err != nil {
+		return nil, err
+	}
+	evaluations, err := s.loadEvaluations(ctx, rng)
+	if err != nil {
+		return nil, err
+	}
+
+	bucketSize := req.BucketSize
+	if bucketSize <= 0 || bucketSize > 100 {
+		bucketSize = 10
+	}
+	stats := s.employeeStats(ctx, evaluations, scope, 1)
+	scores := make([]float64, 0, len(stats))
+	sum := 0.0
+	for _, st := range stats {
+		scores = append(scores, st.Score)
+		sum += st.Score
+	}
+
+	out := &dto.KPIDistributionDTO{
+		StartDate:  rng.start,
+		EndDate:    rng.end,
+		BucketSize: bucketSize,
+		Employees:  len(stats),
+		Median:     helpers.Median(scores),
+	}
+	if len(scores) > 0 {
+		out.Mean = util.Round2(sum / float64(len(scores)))
+	}
+	for i, count := range helpers.KPIHistogram(scores, bucketSize) {
+		from := float64(i * bucketSize)
+		out.Buckets = append(out.Buckets, dto.KPIHistogramBucketDTO{From: from, To: math.Min(from+float64(bucketSize), 100), Count: count})
+	}
+	return out, nil
+}
+
+func (s *kpiAnalyticsService) KPIPerformers(ctx context.Context, req dto.RequestKPIAnalytics, claims *dto.JWTClaims) (*dto.KPIPerformersDTO, error) {
+	rng, err := kpiAnalyticsPeriod(req)
+	if err != nil {
+		return nil, err
+	}
+	scope, err := s.managerScope(ctx, req, claims)
+	if err != nil {
+		return nil, err
+	}
+	evaluations, err := s.loadEvaluations(ctx, rng)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 5
+	}
+	if limit > kpiAnalyticsMaxLimit {
+		limit = kpiAnalyticsMaxLimit
+	}
+	stats := s.employeeStats(ctx, evaluations, scope, req.MinEvaluations)
+	if err := s.attachTaskCounts(ctx, stats, rng); err != nil {
+		return nil, err
+	}
+
+	company := newKPITrendAcc()
+	for _, ev := range evaluations {
+		company.add("", kpiEvaluationScore(ev))
+	}
+	out := &dto.KPIPerformersDTO{
+		StartDate:    rng.start,
+		EndDate:      rng.end,
+		CompanyScore: company.score(),
+		Top:          []dto.KPIEmployeeStatDTO{},
+		Bottom:       []dto.KPIEmployeeStatDTO{},
+	}
+	// stats เรียงคะแนนมาก → น้อยแล้ว
+	for i := 0; i < len(stats) && i < limit; i++ {
+		out.Top = append(out.Top, stats[i])
+	}
+	for i := len(stats) - 1; i >= 0 && len(out.Bottom) < limit; i-- {
+		out.Bottom = append(out.Bottom, stats[i])
+	}
+	return out, nil
+}
+
+func (s *kpiAnalyticsService) KPICorrelation(ctx context.Context, req dto.RequestKPIAnalytics, claims *dto.JWTClaims) (*dto.KPICorrelationDTO, error) {
+	rng, err := kpiAnalyticsPeriod(req)
+	if err != nil {
+		return nil, err
+	}
+	scope, err := s.managerScope(ctx, req, claims)
+	if err != nil {
+		return nil, err
+	}
+	evaluations, err := s.loadEvaluations(ctx, rng)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := s.employeeStats(ctx, evaluations, scope, req.MinEvaluations)
+	if err := s.attachTaskCounts(ctx, stats, rng); err != nil {
+		return nil, err
+	}
+
+	scores := make([]float64, 0, len(stats))
+	completed := make([]float64, 0, len(stats))
+	overdue := make([]float64, 0, len(stats))
+	for _, st := range stats {
+		scores = append(scores, st.Score)
+		completed = append(completed, float64(st.CompletedTasks))
+		overdue = append(overdue, float64(st.OverdueTasks))
+	}
+
+	out := &dto.KPICorrelationDTO{StartDate: rng.start, EndDate: rng.end, Employees: len(stats), Rows: stats}
+	if r, ok := helpers.PearsonCorrelation(scores, completed); ok {
+		out.ThroughputR = &r
+	}
+	if r, ok := helpers.PearsonCorrelation(scores, overdue); ok {
+		out.OverdueR = &r
+	}
+	return out, nil
+}
+
+// scope admin เห็นทั้งหมด, ผู้จัดการเห็นแผนกที่ดูแล, พนักงานเห็นเฉพาะของตนเอง
+func (s *kpiAnalyticsService) scope(ctx context.Context, req dto.RequestKPIAnalytics, claims *dto.JWTClaims) (kpiAnalyticsScope, error) {
+	departmentID := strings.TrimSpace(req.DepartmentID)
+	if claims.Role == "admin" {
+		if departmentID != "" {
+			return kpiAnalyticsScope{departments: []string{departmentID}}, nil
+		}
+		return kpiAnalyticsScope{}, nil
+	}
+
+	departments, err := s.departmentRepo.GetAllDepartmentByFilter(ctx, bson.M{"manager_id": claims.UserID, "deleted_at": nil}, bson.M{"department_id": 1})
+	if err != nil {
+		return kpiAnalyticsScope{}, err
+	}
+	managed := make([]string, 0, len(departments))
+	for _, d := range departments {
+		managed = append(managed, d.DepartmentID)
+	}
+
+	switch {
+	case departmentID != "":
+		if !helpers.InSet(departmentID, managed...) {
+			return kpiAnalyticsScope{}, ports.ErrKPIAnalyticsForbidden
+		}
+		return kpiAnalyticsScope{departments: []string{departmentID}}, nil
+	case len(managed) > 0:
+		return kpiAnalyticsScope{departments: managed}, nil
+	}
+	if userID := strings.TrimSpace(req.UserID); userID != "" && userID != claims.UserID {
+		return kpiAnalyticsScope{}, ports.ErrKPIAnalyticsForbidden
+	}
+	return kpiAnalyticsScope{userID: claims.UserID}, nil
+}
+
+// managerScope สถิติเปรียบเทียบระหว่างพนักงาน ดูได้เฉพาะ admin/ผู้จัดการแผนก
+func (s *kpiAnalyticsService) managerScope(ctx context.Context, req dto.RequestKPIAnalytics, claims *dto.JWTClaims) (kpiAnalyticsScope, error) {
+	scope, err := s.scope(ctx, req, claims)
+	if err != nil {
+		return scope, err
+	}
+	if scope.userID != "" {
+		return scope, ports.ErrKPIAnalyticsForbidden
+	}
+	return scope, nil
+}
+
+// loadEvaluations การประเมินที่ประเมินแล้วในช่วง (ใช้ updated_at เป็นวันที่ประเมินเสร็จ)
+func (s *kpiAnalyticsService) loadEvaluations(ctx context.Context, rng kpiAnalyticsRange) ([]*models.KPIEvaluation, error) {
+	filter := bson.M{
+		"deleted_at":   nil,
+		"is_evaluated": true,
+		"updated_at":   bson.M{"$gte": rng.start, "$lte": rng.end},
+	}
+	projection := bson.M{"_id": 0, "evaluatee_id": 1, "department_id": 1, "total_score": 1, "scores": 1, "updated_at": 1}
+	return s.kpiEvaluationRepo.GetAllKPIEvaluationByFilter(ctx, filter, projection)
+}
+
+// employeeStats คะแนนเฉลี่ยรายพนักงานในขอบเขต เรียงคะแนนมาก → น้อย
+func (s *kpiAnalyticsService) employeeStats(ctx context.Context, evaluations []*models.KPIEvaluation, scope kpiAnalyticsScope, minEvaluations int) []dto.KPIEmployeeStatDTO {
+	if minEvaluations <= 0 {
+		minEvaluations = 1
+	}
+	type agg struct {
+		acc        *kpiTrendAcc
+		department string
+		latest     time.Time
+	}
+	byUser := make(map[string]*agg)
+	for _, ev := range evaluations {
+		if ev.EvaluateeID == "" || !scope.allows(ev) {
+			continue
+		}
+		a, ok := byUser[ev.EvaluateeID]
+		if !ok {
+			a = &agg{acc: newKPITrendAcc()}
+			byUser[ev.EvaluateeID] = a
+		}
+		a.acc.add("", kpiEvaluationScore(ev))
+		// แผนกล่าสุดที่ถูกประเมิน
+		if !ev.UpdatedAt.Before(a.latest) {
+			a.latest = ev.UpdatedAt
+			a.department = ev.Department
+		}
+	}
+
+	deptNames := s.departmentNames(ctx)
+	users := make(map[string]string)
+	stats := make([]dto.KPIEmployeeStatDTO, 0, len(byUser))
+	for userID, a := range byUser {
+		if a.acc.count < minEvaluations {
+			continue
+		}
+		stats = append(stats, dto.KPIEmployeeStatDTO{
+			UserID:         userID,
+			Name:           s.userName(ctx, users, userID),
+			DepartmentID:   a.department,
+			DepartmentName: deptNames[a.department],
+			Evaluations:    a.acc.count,
+			Score:          a.acc.score(),
+		})
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].Score != stats[j].Score {
+			return stats[i].Score > stats[j].Score
+		}
+		if stats[i].Evaluations != stats[j].Evaluations {
+			return stats[i].Evaluations > stats[j].Evaluations
+		}
+		return stats[i].UserID < stats[j].UserID
+	})
+	return stats
+}
+
+// attachTaskCounts งานที่เสร็จในช่วง (throughput) และงานที่ครบกำหนดในช่วงแต่เสร็จช้า/ยังค้าง ของผู้รับผิดชอบหลัก
+func (s *kpiAnalyticsService) attachTaskCounts(ctx context.Context, stats []dto.KPIEmployeeStatDTO, rng kpiAnalyticsRange) error {
+	if len(stats) == 0 {
+		return nil
+	}
+	userIDs := make([]string, 0, len(stats))
+	for _, st := range stats {
+		userIDs = append(userIDs, st.UserID)
+	}
+	filter := bson.M{
+		"deleted_at": nil,
+		"assignee":   bson.M{"$in": userIDs},
+		"$or": bson.A{
+			bson.M{"end_date": bson.M{"$gte": rng.start, "$lte": rng.end}},
+			bson.M{"status": "done", "updated_at": bson.M{"$gte": rng.start}},
+		},
+	}
+	projection := bson.M{"_id": 0, "assignee": 1, "status": 1, "end_date": 1, "updated_at": 1, "applied_workflow.steps.completed_at": 1}
+	tasks, err := s.taskRepo.GetAllTaskByFilter(ctx, filter, projection)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	loc := rng.start.Location()
+	completed := make(map[string]int)
+	overdue := make(map[string]int)
+	for _, t := range tasks {
+		done := t.Status == "done"
+		finishedAt := taskFinishedAt(t)
+		if done && !finishedAt.Before(rng.start) && !finishedAt.After(rng.end) {
+			completed[t.Assignee]++
+		}
+		if t.EndDate.IsZero() || t.EndDate.Before(rng.start) || t.EndDate.After(rng.end) {
+			continue
+		}
+		// ครบกำหนดได้ทั้งวันของ end_date
+		due := t.EndDate.In(loc)
+		due = time.Date(due.Year(), due.Month(), due.Day(), 23, 59, 59, 0, loc)
+		switch {
+		case done && finishedAt.After(due):
+			overdue[t.Assignee]++
+		case !done && t.Status != "skip" && now.After(due):
+			overdue[t.Assignee]++
+		}
+	}
+	for i := range stats {
+		stats[i].CompletedTasks = completed[stats[i].UserID]
+		stats[i].OverdueTasks = overdue[stats[i].UserID]
+	}
+	return nil
+}
+
+func (s *kpiAnalyticsService) groupNames(ctx context.Context, groupBy string, groups map[string]*kpiTrendAcc) map[string]string {
+	names := make(map[string]string, len(groups))
+	switch groupBy {
+	case "department":
+		deptNames := s.departmentNames(ctx)
+		for key := range groups {
+			names[key] = deptNames[key]
+			if names[key] == "" {
+				names[key] = "ไม่พบแผนก"
+			}
+		}
+	case "employee":
+		users := make(map[string]string)
+		for key := range groups {
+			names[key] = s.userName(ctx, users, key)
+		}
+	default:
+		for key := range groups {
+			names[key] = key
+			if key == kpiUncategorized {
+				names[key] = "ไม่ระบุหมวด"
+			}
+		}
+	}
+	return names
+}
+
+func (s *kpiAnalyticsService) departmentNames(ctx context.Context) map[string]string {
+	names := make(map[string]string)
+	departments, _ := s.departmentRepo.GetAllDepartmentByFilter(ctx, bson.M{}, bson.M{"department_id": 1, "department_name": 1})
+	for _, d := range departments {
+		names[d.DepartmentID] = d.DepartmentName
+	}
+	return names
+}
+
+func (s *kpiAnalyticsService) userName(ctx context.Context, cache map[string]string, userID string) string {
+	if name, ok := cache[userID]; ok {
+		return name
+	}
+	name := "ไม่พบชื่อผู้ใช้"
+	if user, _ := s.userRepo.GetByID(ctx, userID); user != nil {
+		name = fmt.Sprintf("%s %s %s", user.TitleTH, user.FirstNameTH, user.LastNameTH)
+	}
+	cache[userID] = name
+	return name
+}
+
+// kpiAnalyticsPeriod ช่วงวันที่และช่วงกราฟตามคำขอ (เวลาไทย)
+func kpiAnalyticsPeriod(req dto.RequestKPIAnalytics) (kpiAnalyticsRange, error) {
+	loc := helpers.BangkokLocation()
+	interval := strings.ToLower(strings.TrimSpace(req.Interval))
+	if interval == "" {
+		interval = helpers.KPIIntervalMonth
+	}
+	if !helpers.InSet(interval, helpers.KPIIntervalMonth, helpers.KPIIntervalQuarter) {
+		return kpiAnalyticsRange{}, fmt.Errorf("interval must be month or quarter")
+	}
+
+	now := time.Now().In(loc)
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if strings.TrimSpace(req.EndDate) != "" {
+		t, err := helpers.ParseDate("end_date", req.EndDate, loc)
+		if err != nil {
+			return kpiAnalyticsRange{}, err
+		}
+		end = t
+	}
+	start := time.Date(end.Year(), end.Month()-(kpiAnalyticsDefaultMonths-1), 1, 0, 0, 0, 0, loc)
+	if strings.TrimSpace(req.StartDate) != "" {
+		t, err := helpers.ParseDate("start_date", req.StartDate, loc)
+		if err != nil {
+			return kpiAnalyticsRange{}, err
+		}
+		start = t
+	}
+	if end.Before(start) {
+		return kpiAnalyticsRange{}, fmt.Errorf("end_date must not be before start_date")
+	}
+	end = reviewEndOfDay(end)
+	return kpiAnalyticsRange{start: start, end: end, interval: interval, periods: helpers.KPIPeriodKeys(start, end, interval)}, nil
+}
+
+// kpiEvaluationScore คะแนนที่ใช้คิด KPI ของการประเมิน (0..100) เหมือน KPI รวมของผู้ใช้
+func kpiEvaluationScore(ev *models.KPIEvaluation) float64 {
+	return math.Max(0, math.Min(ev.TotalScore, 100))
+}
+
+func kpiScoresByCategory(scores []models.KPIScore) map[string][]models.KPIScore {
+	out := make(map[string][]models.KPIScore)
+	for _, sc := range scores {
+		category := strings.TrimSpace(sc.Category)
+		if category == "" {
+			category = kpiUncategorized
+		}
+		out[category] = append(out[category], sc)
+	}
+	return out
+}
+
+// taskFinishedAt เวลาเสร็จของงาน = step ที่เสร็จล่าสุด (ไม่มี = updated_at)
+func taskFinishedAt(t *models.Tasks) time.Time {
+	var latest time.Time
+	for _, st := range t.AppliedWorkflow.Steps {
+		if st.CompletedAt != nil && st.CompletedAt.After(latest) {
+			latest = *st.CompletedAt
+		}
+	}
+	if latest.IsZero() {
+		return t.UpdatedAt
+	}
+	return latest
+}
+
+// kpiTrendAcc ตัวสะสมคะแนนเฉลี่ยรวมและรายช่วง
+type kpiTrendAcc struct {
+	count    int
+	sum      float64
+	byPeriod map[string]*kpiTrendBucket
+}
+
+type kpiTrendBucket struct {
+	count int
+	sum   float64
+}
+
+func newKPITrendAcc() *kpiTrendAcc {
+	return &kpiTrendAcc{byPeriod: make(map[string]*kpiTrendBucket)}
+}
+
+func (a *kpiTrendAcc) add(period string, v float64) {
+	a.count++
+	a.sum += v
+	b, ok := a.byPeriod[period]
+	if !ok {
+		b = &kpiTrendBucket{}
+		a.byPeriod[period] = b
+	}
+	b.count++
+	b.sum += v
+}
+
+func (a *kpiTrendAcc) score() float64 {
+	if a.count == 0 {
+		return 0
+	}
+	return util.Round2(a.sum / float64(a.count))
+}
+
+func (a *kpiTrendAcc) series(key, name string, periods []string, companyScore float64) dto.KPITrendSeriesDTO {
+	out := dto.KPITrendSeriesDTO{
+		Key:         key,
+		Name:        name,
+		Evaluations: a.count,
+		Score:       a.score(),
+		Points:      make([]dto.KPITrendPointDTO, 0, len(periods)),
+	}
+	out.VsCompany = util.Round2(out.Score - companyScore)
+	for _, p := range periods {
+		point := dto.KPITrendPointDTO{Period: p}
+		if b, ok := a.byPeriod[p]; ok && b.count > 0 {
+			point.Evaluations = b.count
+			point.Score = util.Round2(b.sum / float64(b.count))
+		}
+		out.Points = append(out.Points, point)
+	}
+	return out
+}
@@ -3,14 +3,18 @@ package services
 import (
 	"context"
 	"fmt"
+	"log"
 	"math"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/Be2Bag/erp-demo/config"
 	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
 	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/pkg/util"
 	"github.com/Be2Bag/erp-demo/ports"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -25,11 +29,12 @@ type kpiEvaluationRepoService struct {
 	departmentRepo    ports.DepartmentRepository
 	projectRepo       ports.ProjectRepository
 	signJobRepo       ports.SignJobRepository
+	slaRepo           ports.SLARepository
 	config            config.Config
 }
 
-func NewKPIEvaluationService(cfg config.Config, kpiRepo ports.KPIRepository, userRepo ports.UserRepository, kpiEvaluationRepo ports.KPIEvaluationRepository, taskRepo ports.TaskRepository, departmentRepo ports.DepartmentRepository, projectRepo ports.ProjectRepository, signJobRepo ports.SignJobRepository) ports.KPIEvaluationService {
-	return &kpiEvaluationRepoService{config: cfg, kpiRepo: kpiRepo, userRepo: userRepo, kpiEvaluationRepo: kpiEvaluationRepo, taskRepo: taskRepo, departmentRepo: departmentRepo, projectRepo: projectRepo, signJobRepo: signJobRepo}
+func NewKPIEvaluationService(cfg config.Config, kpiRepo ports.KPIRepository, userRepo ports.UserRepository, kpiEvaluationRepo ports.KPIEvaluationRepository, taskRepo ports.TaskRepository, departmentRepo ports.DepartmentRepository, projectRepo ports.ProjectRepository, signJobRepo ports.SignJobRepository, slaRepo ports.SLARepository) ports.KPIEvaluationService {
+	return &kpiEvaluationRepoService{config: cfg, kpiRepo: kpiRepo, userRepo: userRepo, kpiEvaluationRepo: kpiEvaluationRepo, taskRepo: taskRepo, departmentRepo: departmentRepo, projectRepo: projectRepo, signJobRepo: signJobRepo, slaRepo: slaRepo}
 }
 
 func (s *kpiEvaluationRepoService) GetKPIEvaluationByID(ctx context.Context, evaluationID string, claims *dto.JWTClaims) (*dto.KPIEvaluationResponse, error) {
@@ -73,6 +78,17 @@ func (s *kpiEvaluationRepoService) GetKPIEvaluationByID(ctx context.Context, eva
 		}
 	}
 
+	// SLA ที่ผู้ถูกประเมินทำเกินกำหนดในงานนี้ (ข้อมูลประกอบการให้คะแนน)
+	slaBreaches := 0
+	slaOverdueHours := 0.0
+	if m.TaskID != "" {
+		breaches, _ := s.slaRepo.GetAllBreachesByFilter(ctx, bson.M{"task_id": m.TaskID, "user_id": m.EvaluateeID, "level": "overdue"}, bson.M{"overdue_hours": 1})
+		for _, b := range breaches {
+			slaBreaches++
+			slaOverdueHours += b.OverdueHours
+		}
+	}
+
 	scores := make([]dto.KPIScoreResponse, 0, len(m.Scores))
 	for _, score := range m.Scores {
 		scores = append(scores, dto.KPIScoreResponse{
@@ -87,28 +103,92 @@ func (s *kpiEvaluationRepoService) GetKPIEvaluationByID(ctx context.Context, eva
 	}
 
 	dtoObj := &dto.KPIEvaluationResponse{
-		EvaluationID:   m.EvaluationID,
-		JobID:          m.JobID,
-		JobName:        jobName,
-		ProjectID:      m.ProjectID,
-		ProjectName:    projectName,
-		TaskID:         m.TaskID,
-		KPIID:          m.KPIID,
-		KPIName:        kpiName,
-		Version:        1,
-		EvaluatorID:    m.EvaluatorID,
-		EvaluatorName:  evaluatorName,
-		EvaluateeID:    m.EvaluateeID,
-		EvaluateeName:  assigneeName,
-		Department:     m.Department,
-		DepartmentName: departmentsName,
-		Scores:         scores,
-		TotalScore:     m.TotalScore,
-		IsEvaluated:    m.IsEvaluated,
-		Feedback:       m.Feedback,
-		FinishedAt:     m.UpdatedAt,
-		CreatedAt:      m.CreatedAt,
-		UpdatedAt:      m.UpdatedAt,
+		EvaluationID:    m.EvaluationID,
+		JobID:           m.JobID,
+		JobName:         jobName,
+		ProjectID:       m.ProjectID,
+		ProjectName:     projectName,
+		TaskID:          m.TaskID,
+		StepIDs:         m.StepIDs,
+		KPIID:           m.KPIID,
+		KPIName:         kpiName,
+		Version:         m.Version,
+		EvaluatorID:     m.EvaluatorID,
+		EvaluatorName:   evaluatorName,
+		EvaluateeID:     m.EvaluateeID,
+		EvaluateeName:   assigneeName,
+		Department:      m.Department,
+		DepartmentName:  departmentsName,
+		Scores:          scores,
+		TotalScore:      m.TotalScore,
+		Percent:         m.Percent,
+		Grade:           m.Grade,
+		ScoringMethod:   m.ScoringMethod,
+		IsEvaluated:     m.IsEvaluated,
+		Feedback:        m.Feedback,
+		SLABreaches:     slaBreaches,
+		SLAOverdueHours: util.Round2(slaOverdueHours),
+		FinishedAt:      m.UpdatedAt,
+		CreatedAt:       m.CreatedAt,
+		UpdatedAt:       m.UpdatedAt,
+		AckStatus:       m.AckStatus,
+		AckComment:      m.AckComment,
+		AckAt:           m.AckAt,
+		PeerCount:       len(m.PeerReviews),
+		EvaluatorRule:   m.EvaluatorRule,
+		AssignedAt:      m.AssignedAt,
+		ClosedAt:        m.ClosedAt,
+		CloseReason:     m.CloseReason,
+	}
+
+	access, err := s.kpiEvaluationAccess(ctx, m, claims)
+	if err != nil {
+		return nil, err
+	}
+	dtoObj.ViewerRoles = access.roles()
+
+	names := make(map[string]string)
+	toRoleReview := func(r models.KPIRoleReview) dto.KPIRoleReviewResponse {
+		return dto.KPIRoleReviewResponse{
+			EvaluatorID:   r.EvaluatorID,
+			EvaluatorName: s.kpiUserName(ctx, names, r.EvaluatorID),
+			Scores:        toKPIScoreResponses(r.Scores),
+			Feedback:      r.Feedback,
+			TotalScore:    r.TotalScore,
+			Percent:       r.Percent,
+			Grade:         r.Grade,
+			SubmittedAt:   r.SubmittedAt,
+		}
+	}
+	if m.SelfReview != nil {
+		self := toRoleReview(*m.SelfReview)
+		dtoObj.SelfReview = &self
+	}
+	// peer: ผู้ประเมินหลัก/admin เห็นรายคน, peer เห็นของตน, ผู้ถูกประเมินเห็นเฉพาะค่าเฉลี่ย
+	if len(m.PeerReviews) > 0 {
+		sum := 0.0
+		for _, pr := range m.PeerReviews {
+			sum += pr.Percent
+			if access.admin || access.manager || (access.peer && pr.EvaluatorID == claims.UserID) {
+				dtoObj.PeerReviews = append(dtoObj.PeerReviews, toRoleReview(pr))
+			}
+		}
+		avg := util.Round2(sum / float64(len(m.PeerReviews)))
+		dtoObj.PeerPercent = &avg
+	}
+	if access.admin || access.manager {
+		dtoObj.PeerIDs = m.PeerIDs
+	}
+	for _, d := range m.Disputes {
+		dtoObj.Disputes = append(dtoObj.Disputes, dto.KPIDisputeResponse{
+			Reason:     d.Reason,
+			RaisedAt:   d.RaisedAt,
+			Version:    d.Version,
+			Decision:   d.Decision,
+			Resolution: d.Resolution,
+			ResolvedBy: d.ResolvedBy,
+			ResolvedAt: d.ResolvedAt,
+		})
 	}
 	return dtoObj, nil
 }
@@ -117,100 +197,959 @@ func (s *kpiEvaluationRepoService) UpdateKPIEvaluation(ctx context.Context, eval
 
 	now := time.Now()
 	// ดึงข้อมูลเดิม
-	filter := bson.M{"evaluation_id": evaluationID, "deleted_at": nil}
-	existing, err := s.kpiEvaluationRepo.GetOneKPIEvaluationByFilter(ctx, filter, bson.M{})
+	existing, err := s.getKPIEvaluation(ctx, evaluationID)
 	if err != nil {
 		return err
 	}
 
-	if existing == nil {
-		return mongo.ErrNoDocuments
+	access, err := s.kpiEvaluationAccess(ctx, existing, claims)
+	if err != nil {
+		return err
+	}
+	if !access.manager {
+		return ports.ErrKPIEvaluationForbidden
+	}
+	if existing.ClosedAt != nil {
+		return fmt.Errorf("%w: evaluation is closed", ports.ErrKPIEvaluationConflict)
+	}
+	// มีข้อโต้แย้งค้างอยู่ ต้องแก้คะแนนผ่านการพิจารณาข้อโต้แย้งเท่านั้น
+	if existing.AckStatus == models.KPIAckDisputed {
+		return ports.ErrKPIEvaluationDisputed
 	}
 
+	lastUpdatedAt := existing.UpdatedAt
 	if req.Scores != nil {
+		scores, updatedAny := applyKPIScoreRequests(existing.Scores, req.Scores)
+		if updatedAny {
+			existing.Scores = scores
+			// คิดคะแนนรวมตามวิธีของ template (weight/คะแนนเต็มของแต่ละ item)
+			strategy, err := s.scoringStrategy(ctx, existing.KPIID)
+			if err != nil {
+				return err
+			}
+			applyKPIResult(existing, strategy)
+			existing.IsEvaluated = true
+		}
+	}
+
+	// ผู้ประเมินหลักถูกกำหนดครั้งแรกที่ให้คะแนน (admin แก้แทนไม่เปลี่ยนผู้ประเมิน ดูผู้แก้จากประวัติ)
+	if existing.EvaluatorID == "" {
+		existing.EvaluatorID = claims.UserID
+	}
+	existing.Feedback = strings.TrimSpace(req.Feedback)
+	existing.Version += 1    // เพิ่มเวอร์ชัน
+	existing.UpdatedAt = now // อัปเดตเวลา
+	appendKPIRevision(existing, models.KPIRoleManager, claims.UserID, existing.Scores, existing.Feedback, kpiResultOf(existing), "", now)
+	if existing.IsEvaluated {
+		// คะแนนเปลี่ยน ผู้ถูกประเมินต้องรับทราบผลใหม่
+		existing.AckStatus = models.KPIAckPending
+		existing.AckComment = ""
+		existing.AckAt = nil
+	}
+
+	if err := s.saveKPIEvaluation(ctx, existing, lastUpdatedAt); err != nil {
+		return err
+	}
+	return s.refreshUserKPI(ctx, existing.EvaluateeID, now)
+}
+
+// SubmitSelfKPIEvaluation ผู้ถูกประเมินให้คะแนนตนเองตามหัวข้อเดียวกับผู้ประเมินหลัก (ไม่นำไปคิด KPI)
+func (s *kpiEvaluationRepoService) SubmitSelfKPIEvaluation(ctx context.Context, evaluationID string, req dto.UpdateKPIEvaluationRequest, claims *dto.JWTClaims) (*dto.KPIEvaluationResponse, error) {
+	existing, err := s.getKPIEvaluation(ctx, evaluationID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.EvaluateeID != claims.UserID {
+		return nil, ports.ErrKPIEvaluationForbidden
+	}
+	if existing.AckStatus == models.KPIAckAcknowledged || existing.ClosedAt != nil {
+		return nil, fmt.Errorf("%w: result already acknowledged or evaluation closed", ports.ErrKPIEvaluationConflict)
+	}
+
+	base := blankKPIScores(existing.Scores)
+	if existing.SelfReview != nil {
+		base = existing.SelfReview.Scores
+	}
+	review, err := s.buildRoleReview(ctx, existing, base, req, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	lastUpdatedAt := existing.UpdatedAt
+	existing.SelfReview = review
+	existing.Version += 1
+	existing.UpdatedAt = review.SubmittedAt
+	appendKPIRevision(existing, models.KPIRoleSelf, claims.UserID, review.Scores, review.Feedback, helpers.KPIResult{Total: review.TotalScore, Percent: review.Percent, Grade: review.Grade}, "", review.SubmittedAt)
+
+	if err := s.saveKPIEvaluation(ctx, existing, lastUpdatedAt); err != nil {
+		return nil, err
+	}
+	return s.GetKPIEvaluationByID(ctx, evaluationID, claims)
+}
+
+// InviteKPIPeers ผู้ประเมินหลัก/admin กำหนดเพื่อนร่วมงานที่ให้ประเมิน
+func (s *kpiEvaluationRepoService) InviteKPIPeers(ctx context.Context, evaluationID string, req dto.InviteKPIPeersRequest, claims *dto.JWTClaims) (*dto.KPIEvaluationResponse, error) {
+	existing, err := s.getKPIEvaluation(ctx, evaluationID)
+	if err != nil {
+		return nil, err
+	}
+	access, err := s.kpiEvaluationAccess(ctx, existing, claims)
+	if err != nil {
+		return nil, err
+	}
+	if !access.manager {
+		return nil, ports.ErrKPIEvaluationForbidden
+	}
+
+	peerIDs := make([]string, 0, len(req.PeerIDs))
+	for _, id := range req.PeerIDs {
+		id = strings.TrimSpace(id)
+		if id == "" || helpers.InSet(id, peerIDs...) {
+			continue
+		}
+		if id == existing.EvaluateeID {
+			return nil, fmt.Errorf("evaluatee cannot be a peer reviewer")
+		}
+		user, err := s.userRepo.GetByID(ctx, id)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return nil, err
+		}
+		if user == nil || user.DeletedAt != nil {
+			return nil, fmt.Errorf("peer %s not found", id)
+		}
+		peerIDs = append(peerIDs, id)
+	}
+	// คนที่ส่งคะแนนแล้วคงอยู่ในรายชื่อ (ผลประเมินไม่ถูกลบ)
+	for _, pr := range existing.PeerReviews {
+		if !helpers.InSet(pr.EvaluatorID, peerIDs...) {
+			peerIDs = append(peerIDs, pr.EvaluatorID)
+		}
+	}
+
+	lastUpdatedAt := existing.UpdatedAt
+	existing.PeerIDs = peerIDs
+	existing.UpdatedAt = time.Now()
+	if err := s.saveKPIEvaluation(ctx, existing, lastUpdatedAt); err != nil {
+		return nil, err
+	}
+	return s.GetKPIEvaluationByID(ctx, evaluationID, claims)
+}
+
+// SubmitPeerKPIEvaluation เพื่อนร่วมงานที่ได้รับเชิญให้คะแนน (คนละหนึ่งชุด ส่งซ้ำ = แก้ไขของตน)
+func (s *kpiEvaluationRepoService) SubmitPeerKPIEvaluation(ctx context.Context, evaluationID string, req dto.UpdateKPIEvaluationRequest, claims *dto.JWTClaims) (*dto.KPIEvaluationResponse, error) {
+	existing, err := s.getKPIEvaluation(ctx, evaluationID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.EvaluateeID == claims.UserID || !helpers.InSet(claims.UserID, existing.PeerIDs...) {
+		return nil, ports.ErrKPIEvaluationForbidden
+	}
+	if existing.ClosedAt != nil {
+		return nil, fmt.Errorf("%w: evaluation is closed", ports.ErrKPIEvaluationConflict)
+	}
+
+	idx := -1
+	base := blankKPIScores(existing.Scores)
+	for i, pr := range existing.PeerReviews {
+		if pr.EvaluatorID == claims.UserID {
+			idx = i
+			base = pr.Scores
+			break
+		}
+	}
+	review, err := s.buildRoleReview(ctx, existing, base, req, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	lastUpdatedAt := existing.UpdatedAt
+	if idx >= 0 {
+		existing.PeerReviews[idx] = *review
+	} else {
+		existing.PeerReviews = append(existing.PeerReviews, *review)
+	}
+	existing.Version += 1
+	existing.UpdatedAt = review.SubmittedAt
+	appendKPIRevision(existing, models.KPIRolePeer, claims.UserID, review.Scores, review.Feedback, helpers.KPIResult{Total: review.TotalScore, Percent: review.Percent, Grade: review.Grade}, "", review.SubmittedAt)
+
+	if err := s.saveKPIEvaluation(ctx, existing, lastUpdatedAt); err != nil {
+		return nil, err
+	}
+	return s.GetKPIEvaluationByID(ctx, evaluationID, claims)
+}
+
+// AcknowledgeKPIEvaluation ผู้ถูกประเมินรับทราบผล (หลังผู้ประเมินหลักให้คะแนน หรือหลังพิจารณาข้อโต้แย้ง)
+func (s *kpiEvaluationRepoService) AcknowledgeKPIEvaluation(ctx context.Context, evaluationID string, req dto.AcknowledgeKPIEvaluationRequest, claims *dto.JWTClaims) (*dto.KPIEvaluationResponse, error) {
+	existing, err := s.getKPIEvaluation(ctx, evaluationID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.EvaluateeID != claims.UserID {
+		return nil, ports.ErrKPIEvaluationForbidden
+	}
+	if !existing.IsEvaluated || !helpers.InSet(existing.AckStatus, models.KPIAckPending, models.KPIAckResolved) {
+		return nil, fmt.Errorf("%w: evaluation is not waiting for acknowledgement", ports.ErrKPIEvaluationConflict)
+	}
+
+	now := time.Now()
+	lastUpdatedAt := existing.UpdatedAt
+	existing.AckStatus = models.KPIAckAcknowledged
+	existing.AckComment = strings.TrimSpace(req.Comment)
+	existing.AckAt = &now
+	existing.UpdatedAt = now
+	if err := s.saveKPIEvaluation(ctx, existing, lastUpdatedAt); err != nil {
+		return nil, err
+	}
+	return s.GetKPIEvaluationByID(ctx, evaluationID, claims)
+}
+
+// DisputeKPIEvaluation ผู้ถูกประเมินโต้แย้งคะแนน (โต้แย้งได้หนึ่งครั้งต่อคะแนนแต่ละชุดของผู้ประเมินหลัก)
+func (s *kpiEvaluationRepoService) DisputeKPIEvaluation(ctx context.Context, evaluationID string, req dto.DisputeKPIEvaluationRequest, claims *dto.JWTClaims) (*dto.KPIEvaluationResponse, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("reason is required")
+	}
+	existing, err := s.getKPIEvaluation(ctx, evaluationID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.EvaluateeID != claims.UserID {
+		return nil, ports.ErrKPIEvaluationForbidden
+	}
+	if !existing.IsEvaluated || existing.AckStatus != models.KPIAckPending {
+		return nil, fmt.Errorf("%w: only a pending result can be disputed", ports.ErrKPIEvaluationConflict)
+	}
+
+	now := time.Now()
+	lastUpdatedAt := existing.UpdatedAt
+	existing.Disputes = append(existing.Disputes, models.KPIEvalDispute{Reason: reason, RaisedAt: now, Version: existing.Version})
+	existing.AckStatus = models.KPIAckDisputed
+	existing.AckComment = ""
+	existing.AckAt = nil
+	existing.UpdatedAt = now
+	if err := s.saveKPIEvaluation(ctx, existing, lastUpdatedAt); err != nil {
+		return nil, err
+	}
+	s.notifyKPIDispute(ctx, existing, reason)
+	return s.GetKPIEvaluationByID(ctx, evaluationID, claims)
+}
+
+// ResolveKPIDispute ผู้จัดการแผนก/HR พิจารณาข้อโต้แย้ง upheld = คงคะแนน, revised = แก้คะแนน (เก็บประวัติ)
+func (s *kpiEvaluationRepoService) ResolveKPIDispute(ctx context.Context, evaluationID string, req dto.ResolveKPIDisputeRequest, claims *dto.JWTClaims) (*dto.KPIEvaluationResponse, error) {
+	decision := strings.ToLower(strings.TrimSpace(req.Decision))
+	if !helpers.InSet(decision, "upheld", "revised") {
+		return nil, fmt.Errorf("decision must be upheld or revised")
+	}
+	resolution := strings.TrimSpace(req.Resolution)
+	if resolution == "" {
+		return nil, fmt.Errorf("resolution is required")
+	}
+
+	existing, err := s.getKPIEvaluation(ctx, evaluationID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.EvaluateeID == claims.UserID {
+		return nil, ports.ErrKPIEvaluationForbidden
+	}
+	if claims.Role != "admin" {
+		isManager, err := s.isDepartmentManager(ctx, existing.Department, claims.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !isManager {
+			return nil, ports.ErrKPIEvaluationForbidden
+		}
+	}
+	if existing.AckStatus != models.KPIAckDisputed || len(existing.Disputes) == 0 {
+		return nil, fmt.Errorf("%w: no open dispute", ports.ErrKPIEvaluationConflict)
+	}
+
+	now := time.Now()
+	lastUpdatedAt := existing.UpdatedAt
+	if decision == "revised" {
+		scores, updatedAny := applyKPIScoreRequests(existing.Scores, req.Scores)
+		if !updatedAny {
+			return nil, fmt.Errorf("scores are required for a revised decision")
+		}
+		strategy, err := s.scoringStrategy(ctx, existing.KPIID)
+		if err != nil {
+			return nil, err
+		}
+		existing.Scores = scores
+		applyKPIResult(existing, strategy)
+		if feedback := strings.TrimSpace(req.Feedback); feedback != "" {
+			existing.Feedback = feedback
+		}
+		existing.Version += 1
+		appendKPIRevision(existing, models.KPIRoleManager, claims.UserID, existing.Scores, existing.Feedback, kpiResultOf(existing), "dispute resolution: "+resolution, now)
+	}
+
+	d := &existing.Disputes[len(existing.Disputes)-1]
+	d.Decision = decision
+	d.Resolution = resolution
+	d.ResolvedBy = claims.UserID
+	d.ResolvedAt = &now
+	existing.AckStatus = models.KPIAckResolved
+	existing.UpdatedAt = now
+
+	if err := s.saveKPIEvaluation(ctx, existing, lastUpdatedAt); err != nil {
+		return nil, err
+	}
+	if decision == "revised" {
+		if err := s.refreshUserKPI(ctx, existing.EvaluateeID, now); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetKPIEvaluationByID(ctx, evaluationID, claims)
+}
+
+// GetKPIEvaluationHistory ประวัติคะแนนทุกครั้ง (ผู้ถูกประเมินไม่เห็นตัวตนของ peer และไม่เห็นคะแนนรายคนของ peer)
+func (s *kpiEvaluationRepoService) GetKPIEvaluationHistory(ctx context.Context, evaluationID string, claims *dto.JWTClaims) ([]dto.KPIRevisionResponse, error) {
+	existing, err := s.getKPIEvaluation(ctx, evaluationID)
+	if err != nil {
+		return nil, err
+	}
+	access, err := s.kpiEvaluationAccess(ctx, existing, claims)
+	if err != nil {
+		return nil, err
+	}
+	if !access.admin && !access.manager && !access.self {
+		return nil, ports.ErrKPIEvaluationForbidden
+	}
+	seeAll := access.admin || access.manager
+
+	names := make(map[string]string)
+	list := make([]dto.KPIRevisionResponse, 0, len(existing.Revisions))
+	for i := len(existing.Revisions) - 1; i >= 0; i-- {
+		rev := existing.Revisions[i]
+		if rev.Role == models.KPIRolePeer && !seeAll {
+			continue
+		}
+		list = append(list, dto.KPIRevisionResponse{
+			Version:       rev.Version,
+			Role:          rev.Role,
+			EvaluatorID:   rev.EvaluatorID,
+			EvaluatorName: s.kpiUserName(ctx, names, rev.EvaluatorID),
+			Scores:        toKPIScoreResponses(rev.Scores),
+			Feedback:      rev.Feedback,
+			TotalScore:    rev.TotalScore,
+			Percent:       rev.Percent,
+			Grade:         rev.Grade,
+			Reason:        rev.Reason,
+			RevisedAt:     rev.RevisedAt,
+		})
+	}
+	return list, nil
+}
+
+// ListKPIEvaluationInbox งานประเมินที่รอผู้เรียก เรียงจากค้างนานสุด
+func (s *kpiEvaluationRepoService) ListKPIEvaluationInbox(ctx context.Context, claims *dto.JWTClaims) ([]dto.KPIInboxItemDTO, error) {
+	now := time.Now()
+	type pending struct {
+		action string
+		ev     *models.KPIEvaluation
+		since  time.Time
+	}
+	items := []pending{}
+
+	toEvaluate, err := s.kpiEvaluationRepo.GetAllKPIEvaluationByFilter(ctx, bson.M{"deleted_at": nil, "is_evaluated": false, "closed_at": nil, "evaluator_id": claims.UserID}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	for _, ev := range toEvaluate {
+		since := ev.CreatedAt
+		if ev.AssignedAt != nil {
+			since = *ev.AssignedAt
+		}
+		items = append(items, pending{action: "evaluate", ev: ev, since: since})
+	}
+
+	peerFilter := bson.M{"deleted_at": nil, "closed_at": nil, "peer_ids": claims.UserID, "peer_reviews.evaluator_id": bson.M{"$ne": claims.UserID}}
+	toPeer, err := s.kpiEvaluationRepo.GetAllKPIEvaluationByFilter(ctx, peerFilter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	for _, ev := range toPeer {
+		items = append(items, pending{action: "peer_review", ev: ev, since: ev.UpdatedAt})
+	}
+
+	disputeFilter := bson.M{"deleted_at": nil, "ack_status": models.KPIAckDisputed, "evaluatee_id": bson.M{"$ne": claims.UserID}}
+	if claims.Role != "admin" {
+		departments, err := s.departmentRepo.GetAllDepartmentByFilter(ctx, bson.M{"manager_id": claims.UserID, "deleted_at": nil}, bson.M{"department_id": 1})
+		if err != nil {
+			return nil, err
+		}
+		managed := make([]string, 0, len(departments))
+		for _, d := range departments {
+			managed = append(managed, d.DepartmentID)
+		}
+		disputeFilter["department_id"] = bson.M{"$in": managed}
+	}
+	toResolve, err := s.kpiEvaluationRepo.GetAllKPIEvaluationByFilter(ctx, disputeFilter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	for _, ev := range toResolve {
+		since := ev.UpdatedAt
+		if n := len(ev.Disputes); n > 0 {
+			since = ev.Disputes[n-1].RaisedAt
+		}
+		items = append(items, pending{action: "resolve_dispute", ev: ev, since: since})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].since.Before(items[j].since) })
+
+	users := make(map[string]string)
+	jobs := make(map[string]string)
+	templates := make(map[string]*models.KPITemplate)
+	departments := make(map[string]string)
+	out := make([]dto.KPIInboxItemDTO, 0, len(items))
+	for _, it := range items {
+		ev := it.ev
+		tpl, ok := templates[ev.KPIID]
+		if !ok {
+			tpl, _ = s.kpiRepo.GetOneKPIByFilter(ctx, bson.M{"kpi_id": ev.KPIID}, bson.M{"_id": 0, "kpi_name": 1, "evaluator": 1})
+			templates[ev.KPIID] = tpl
+		}
+		jobName, ok := jobs[ev.JobID]
+		if !ok {
+			jobName = "ไม่พบใบงาน"
+			if job, _ := s.signJobRepo.GetOneSignJobByFilter(ctx, bson.M{"job_id": ev.JobID, "deleted_at": nil}, bson.M{"_id": 0, "job_name": 1}); job != nil {
+				jobName = job.JobName
+			}
+			jobs[ev.JobID] = jobName
+		}
+		deptName, ok := departments[ev.Department]
+		if !ok {
+			deptName = "ไม่พบแผนก"
+			if dept, _ := s.departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{"department_id": ev.Department, "deleted_at": nil}, bson.M{"_id": 0, "department_name": 1}); dept != nil {
+				deptName = dept.DepartmentName
+			}
+			departments[ev.Department] = deptName
+		}
 
-		reqMap := make(map[string]dto.KPIScoreRequest, len(req.Scores))
-		for _, r := range req.Scores {
-			id := strings.TrimSpace(r.ItemID)
-			if id == "" {
+		item := dto.KPIInboxItemDTO{
+			Action:         it.action,
+			EvaluationID:   ev.EvaluationID,
+			TaskID:         ev.TaskID,
+			JobID:          ev.JobID,
+			JobName:        jobName,
+			KPIID:          ev.KPIID,
+			KPIName:        "ไม่พบ KPI",
+			EvaluateeID:    ev.EvaluateeID,
+			EvaluateeName:  s.kpiUserName(ctx, users, ev.EvaluateeID),
+			DepartmentID:   ev.Department,
+			DepartmentName: deptName,
+			CreatedAt:      ev.CreatedAt,
+			WaitingDays:    int(now.Sub(it.since).Hours() / 24),
+		}
+		if tpl != nil {
+			item.KPIName = tpl.KPIName
+		}
+		if policy := effectiveKPIEvaluatorPolicy(tpl); it.action == "evaluate" && policy.AutoCloseDays > 0 {
+			closeAt := ev.CreatedAt.AddDate(0, 0, policy.AutoCloseDays)
+			item.AutoCloseAt = &closeAt
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+// AssignKPIEvaluator เปลี่ยนผู้ประเมินหลักของแบบประเมินที่ยังไม่ประเมิน (รวมที่ถูกปิดอัตโนมัติ จะถูกเปิดใหม่)
+func (s *kpiEvaluationRepoService) AssignKPIEvaluator(ctx context.Context, evaluationID string, req dto.AssignKPIEvaluatorRequest, claims *dto.JWTClaims) (*dto.KPIEvaluationResponse, error) {
+	existing, err := s.getKPIEvaluation(ctx, evaluationID)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Role != "admin" {
+		isManager, err := s.isDepartmentManager(ctx, existing.Department, claims.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !isManager {
+			return nil, ports.ErrKPIEvaluationForbidden
+		}
+	}
+	if existing.IsEvaluated {
+		return nil, fmt.Errorf("%w: evaluation is already scored", ports.ErrKPIEvaluationConflict)
+	}
+
+	evaluatorID := strings.TrimSpace(req.EvaluatorID)
+	rule := "manual"
+	if evaluatorID != "" {
+		if evaluatorID == existing.EvaluateeID {
+			return nil, fmt.Errorf("evaluatee cannot evaluate their own work")
+		}
+		user, err := s.userRepo.GetByID(ctx, evaluatorID)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return nil, err
+		}
+		if user == nil || user.DeletedAt != nil {
+			return nil, fmt.Errorf("evaluator not found")
+		}
+	} else {
+		if evaluatorID, rule, err = s.evaluatorByRules(ctx, existing, nil); err != nil {
+			return nil, err
+		}
+		if evaluatorID == "" {
+			return nil, fmt.Errorf("no evaluator found by the template rules, please choose one")
+		}
+	}
+
+	now := time.Now()
+	filter := bson.M{"evaluation_id": evaluationID, "deleted_at": nil, "is_evaluated": false, "updated_at": existing.UpdatedAt}
+	set := bson.M{
+		"evaluator_id":   evaluatorID,
+		"evaluator_rule": rule,
+		"assigned_at":    now,
+		"reminded_at":    nil,
+		"reminder_count": 0,
+		"closed_at":      nil,
+		"close_reason":   "",
+		"updated_at":     now,
+	}
+	n, err := s.kpiEvaluationRepo.UpdateManyKPIEvaluationFields(ctx, filter, set, nil)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("%w: evaluation was modified by someone else, please reload", ports.ErrKPIEvaluationConflict)
+	}
+
+	evaluatee := s.kpiUserName(ctx, map[string]string{}, existing.EvaluateeID)
+	s.mailKPIUser(ctx, evaluatorID, "คุณได้รับมอบหมายให้ประเมิน KPI", fmt.Sprintf("คุณได้รับมอบหมายให้ประเมิน KPI ของ %s (รหัส %s)\nกรุณาเข้าระบบเพื่อให้คะแนน", evaluatee, existing.EvaluationID))
+	return s.GetKPIEvaluationByID(ctx, evaluationID, claims)
+}
+
+// RunKPIEvaluationDue ตรวจแบบประเมินที่ยังไม่ประเมิน: ปิดที่ค้างเกินกำหนด, มอบหมายผู้ประเมินที่ยังว่าง, เตือนผู้ประเมิน (วันละครั้งหลัง 09:00 น.)
+func (s *kpiEvaluationRepoService) RunKPIEvaluationDue(ctx context.Context, now time.Time) (*dto.KPIEvaluationRunResult, error) {
+	start := time.Now()
+	result := &dto.KPIEvaluationRunResult{RunAt: now, Errors: []string{}}
+
+	filter := bson.M{"deleted_at": nil, "is_evaluated": false, "closed_at": nil}
+	projection := bson.M{"_id": 0, "evaluation_id": 1, "kpi_id": 1, "task_id": 1, "department_id": 1, "evaluatee_id": 1, "evaluator_id": 1, "created_at": 1, "assigned_at": 1, "reminded_at": 1}
+	evaluations, err := s.kpiEvaluationRepo.GetAllKPIEvaluationByFilter(ctx, filter, projection)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make(map[string]models.KPIEvaluatorPolicy)
+	creators := make(map[string]string)
+	due := make(map[string][]*models.KPIEvaluation) // ผู้ประเมิน -> แบบประเมินที่ถึงรอบเตือน
+	remindHour := now.In(helpers.BangkokLocation()).Hour() >= reviewReminderHour
+
+	for _, ev := range evaluations {
+		policy, ok := policies[ev.KPIID]
+		if !ok {
+			tpl, err := s.kpiRepo.GetOneKPIByFilter(ctx, bson.M{"kpi_id": ev.KPIID}, bson.M{"_id": 0, "evaluator": 1})
+			if err != nil && err != mongo.ErrNoDocuments {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", ev.EvaluationID, err))
 				continue
 			}
-			reqMap[id] = r
-		}
-
-		updatedAny := false
-		total := 0
-		for i, sc := range existing.Scores {
-			if r, ok := reqMap[sc.ItemID]; ok {
-				score := int(math.Round(r.Score))
-				if score < 0 {
-					score = 0
-				}
-				if score > sc.MaxScore {
-					score = sc.MaxScore
-				}
-				existing.Scores[i].Score = score
-				existing.Scores[i].Notes = strings.TrimSpace(r.Notes)
-				updatedAny = true
+			policy = effectiveKPIEvaluatorPolicy(tpl)
+			policies[ev.KPIID] = policy
+		}
+
+		// ค้างเกินกำหนด -> ปิด (ไม่นำไปคิด KPI)
+		if policy.AutoCloseDays > 0 && !now.Before(ev.CreatedAt.AddDate(0, 0, policy.AutoCloseDays)) {
+			set := bson.M{
+				"closed_at":    now,
+				"close_reason": fmt.Sprintf("ไม่ได้ประเมินภายใน %d วัน", policy.AutoCloseDays),
+				"updated_at":   now,
+			}
+			n, err := s.kpiEvaluationRepo.UpdateManyKPIEvaluationFields(ctx, bson.M{"evaluation_id": ev.EvaluationID, "is_evaluated": false, "closed_at": nil}, set, nil)
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", ev.EvaluationID, err))
+				continue
 			}
-			total += existing.Scores[i].Score
+			result.Closed += int(n)
+			continue
 		}
+		result.Open++
 
-		if updatedAny {
-			if len(existing.Scores) > 0 {
-				avg := float64(total) / float64(len(existing.Scores))
-				existing.TotalScore = float64(math.Round(avg*100) / 100) // ปัดเป็นทศนิยม 2 ตำแหน่ง
-			} else {
-				existing.TotalScore = 0
+		// ยังไม่มีผู้ประเมิน (เช่น แผนกเพิ่งตั้งผู้จัดการ) -> มอบหมายตามกฎ
+		if ev.EvaluatorID == "" {
+			evaluatorID, rule, err := s.evaluatorByRules(ctx, ev, creators)
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", ev.EvaluationID, err))
+				continue
 			}
-			existing.IsEvaluated = true
+			if evaluatorID == "" {
+				continue
+			}
+			set := bson.M{"evaluator_id": evaluatorID, "evaluator_rule": rule, "assigned_at": now}
+			n, err := s.kpiEvaluationRepo.UpdateManyKPIEvaluationFields(ctx, bson.M{"evaluation_id": ev.EvaluationID, "evaluator_id": "", "is_evaluated": false}, set, nil)
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", ev.EvaluationID, err))
+				continue
+			}
+			if n == 0 {
+				continue
+			}
+			result.Assigned++
+			ev.EvaluatorID = evaluatorID
+			ev.AssignedAt = &now
+		}
+
+		if policy.ReminderDays <= 0 || !remindHour {
+			continue
+		}
+		last := ev.CreatedAt
+		if ev.RemindedAt != nil {
+			last = *ev.RemindedAt
+		} else if ev.AssignedAt != nil {
+			last = *ev.AssignedAt
+		}
+		if !now.Before(last.AddDate(0, 0, policy.ReminderDays)) {
+			due[ev.EvaluatorID] = append(due[ev.EvaluatorID], ev)
 		}
 	}
 
-	existing.EvaluatorID = claims.UserID
-	existing.Feedback = strings.TrimSpace(req.Feedback)
-	existing.Version += 1    // เพิ่มเวอร์ชัน
-	existing.UpdatedAt = now // อัปเดตเวลา
+	// อีเมลหนึ่งฉบับต่อผู้ประเมิน
+	for evaluatorID, list := range due {
+		oldest := list[0].CreatedAt
+		ids := make([]string, 0, len(list))
+		for _, ev := range list {
+			ids = append(ids, ev.EvaluationID)
+			if ev.CreatedAt.Before(oldest) {
+				oldest = ev.CreatedAt
+			}
+		}
+		body := fmt.Sprintf("มีแบบประเมิน KPI รอคุณประเมิน %d รายการ (ค้างนานสุด %d วัน)\nกรุณาเข้าระบบเพื่อให้คะแนน", len(list), int(now.Sub(oldest).Hours()/24))
+		if !s.mailKPIUser(ctx, evaluatorID, "แบบประเมิน KPI รอการประเมิน", body) {
+			result.Failed++
+			continue
+		}
+		result.Emails++
+		n, err := s.kpiEvaluationRepo.UpdateManyKPIEvaluationFields(ctx, bson.M{"evaluation_id": bson.M{"$in": ids}}, bson.M{"reminded_at": now}, bson.M{"reminder_count": 1})
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("remind %s: %v", evaluatorID, err))
+			continue
+		}
+		result.Reminded += int(n)
+	}
+
+	result.DurationMS = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+// evaluatorByRules ผู้ประเมินตามกฎของ template ของแบบประเมิน (creators = แคชผู้สร้างงาน)
+func (s *kpiEvaluationRepoService) evaluatorByRules(ctx context.Context, ev *models.KPIEvaluation, creators map[string]string) (string, string, error) {
+	tpl, err := s.kpiRepo.GetOneKPIByFilter(ctx, bson.M{"kpi_id": ev.KPIID}, bson.M{"_id": 0, "evaluator": 1})
+	if err != nil && err != mongo.ErrNoDocuments {
+		return "", "", err
+	}
+	creator, ok := creators[ev.TaskID]
+	if !ok && ev.TaskID != "" {
+		task, err := s.taskRepo.GetOneTasksByFilter(ctx, bson.M{"task_id": ev.TaskID}, bson.M{"_id": 0, "created_by": 1})
+		if err != nil && err != mongo.ErrNoDocuments {
+			return "", "", err
+		}
+		if task != nil {
+			creator = task.CreatedBy
+		}
+		if creators != nil {
+			creators[ev.TaskID] = creator
+		}
+	}
+	return resolveKPIEvaluator(ctx, s.departmentRepo, effectiveKPIEvaluatorPolicy(tpl), ev.Department, creator, ev.EvaluateeID)
+}
+
+// resolveKPIEvaluator เลือกผู้ประเมินหลักตามกฎของ template ถ้าได้คนว่างหรือเป็นผู้ถูกประเมินเอง
+// จะลองผู้จัดการแผนก แล้วผู้สร้างงานตามลำดับ (ไม่พบใครเลย = ว่าง ให้ admin/ผู้จัดการกำหนดเอง)
+func resolveKPIEvaluator(ctx context.Context, departmentRepo ports.DepartmentRepository, policy models.KPIEvaluatorPolicy, departmentID, taskCreator, evaluateeID string) (string, string, error) {
+	rules := []string{policy.Rule, models.KPIEvaluatorDepartmentManager, models.KPIEvaluatorTaskCreator}
+	tried := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		if rule == "" || tried[rule] {
+			continue
+		}
+		tried[rule] = true
 
-	updated, err := s.kpiEvaluationRepo.UpdateKPIEvaluationByID(ctx, evaluationID, *existing)
+		candidate := ""
+		switch rule {
+		case models.KPIEvaluatorReviewer:
+			candidate = policy.ReviewerID
+		case models.KPIEvaluatorTaskCreator:
+			candidate = taskCreator
+		case models.KPIEvaluatorDepartmentManager:
+			if departmentID == "" {
+				continue
+			}
+			dept, err := departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{"department_id": departmentID, "deleted_at": nil}, bson.M{"_id": 0, "manager_id": 1})
+			if err != nil && err != mongo.ErrNoDocuments {
+				return "", "", err
+			}
+			if dept != nil {
+				candidate = dept.ManagerID
+			}
+		}
+		if candidate != "" && candidate != evaluateeID {
+			return candidate, rule, nil
+		}
+	}
+	return "", "", nil
+}
+
+// kpiEvalAccess บทบาทของผู้ใช้ต่อการประเมินหนึ่งรายการ
+type kpiEvalAccess struct {
+	admin   bool
+	manager bool // ผู้ประเมินหลัก: ผู้ประเมินที่กำหนดไว้ ผู้จัดการแผนก ผู้สร้างงาน หรือ admin
+	self    bool
+	peer    bool
+}
+
+func (a kpiEvalAccess) roles() []string {
+	roles := []string{}
+	if a.self {
+		roles = append(roles, models.KPIRoleSelf)
+	}
+	if a.manager {
+		roles = append(roles, models.KPIRoleManager)
+	}
+	if a.peer {
+		roles = append(roles, models.KPIRolePeer)
+	}
+	return roles
+}
+
+func (s *kpiEvaluationRepoService) kpiEvaluationAccess(ctx context.Context, ev *models.KPIEvaluation, claims *dto.JWTClaims) (kpiEvalAccess, error) {
+	a := kpiEvalAccess{
+		admin: claims.Role == "admin",
+		self:  ev.EvaluateeID == claims.UserID,
+		peer:  helpers.InSet(claims.UserID, ev.PeerIDs...),
+	}
+	// ผู้ถูกประเมินให้คะแนนหลักของตนเองไม่ได้ แม้เป็น admin/ผู้จัดการ
+	if a.self {
+		return a, nil
+	}
+	if a.admin || (ev.EvaluatorID != "" && ev.EvaluatorID == claims.UserID) {
+		a.manager = true
+		return a, nil
+	}
+	isManager, err := s.isDepartmentManager(ctx, ev.Department, claims.UserID)
+	if err != nil {
+		return a, err
+	}
+	if isManager {
+		a.manager = true
+		return a, nil
+	}
+	if ev.TaskID != "" {
+		task, err := s.taskRepo.GetOneTasksByFilter(ctx, bson.M{"task_id": ev.TaskID}, bson.M{"_id": 0, "created_by": 1})
+		if err != nil && err != mongo.ErrNoDocuments {
+			return a, err
+		}
+		a.manager = task != nil && task.CreatedBy == claims.UserID
+	}
+	return a, nil
+}
+
+func (s *kpiEvaluationRepoService) isDepartmentManager(ctx context.Context, departmentID, userID string) (bool, error) {
+	if departmentID == "" {
+		return false, nil
+	}
+	dept, err := s.departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{"department_id": departmentID, "deleted_at": nil}, bson.M{"_id": 0, "manager_id": 1})
+	if err != nil && err != mongo.ErrNoDocuments {
+		return false, err
+	}
+	return dept != nil && dept.ManagerID != "" && dept.ManagerID == userID, nil
+}
+
+func (s *kpiEvaluationRepoService) getKPIEvaluation(ctx context.Context, evaluationID string) (*models.KPIEvaluation, error) {
+	existing, err := s.kpiEvaluationRepo.GetOneKPIEvaluationByFilter(ctx, bson.M{"evaluation_id": evaluationID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	return existing, nil
+}
+
+// saveKPIEvaluation บันทึกโดยเช็คว่าไม่มีใครแก้ไขระหว่างอ่าน-เขียน
+func (s *kpiEvaluationRepoService) saveKPIEvaluation(ctx context.Context, ev *models.KPIEvaluation, lastUpdatedAt time.Time) error {
+	updated, err := s.kpiEvaluationRepo.UpdateKPIEvaluationIfUnchanged(ctx, ev.EvaluationID, lastUpdatedAt, *ev)
 	if err != nil {
 		return err
 	}
 	if updated == nil {
-		return mongo.ErrNoDocuments
+		return fmt.Errorf("%w: evaluation was modified by someone else, please reload", ports.ErrKPIEvaluationConflict)
 	}
-	scores, errOnGetScores := s.kpiEvaluationRepo.GetAllKPIEvaluationByFilter(
-		ctx,
-		bson.M{"evaluatee_id": existing.EvaluateeID, "deleted_at": nil},
-		bson.M{"_id": 0, "total_score": 1},
-	)
-	if errOnGetScores != nil {
-		return errOnGetScores
+	return nil
+}
+
+// buildRoleReview คิดคะแนนชุดของ self/peer ด้วยวิธีเดียวกับ template
+func (s *kpiEvaluationRepoService) buildRoleReview(ctx context.Context, ev *models.KPIEvaluation, base []models.KPIScore, req dto.UpdateKPIEvaluationRequest, evaluatorID string) (*models.KPIRoleReview, error) {
+	scores, updatedAny := applyKPIScoreRequests(base, req.Scores)
+	if !updatedAny {
+		return nil, fmt.Errorf("scores are required")
 	}
+	strategy, err := s.scoringStrategy(ctx, ev.KPIID)
+	if err != nil {
+		return nil, err
+	}
+	res := strategy.Score(scores)
+	return &models.KPIRoleReview{
+		EvaluatorID: evaluatorID,
+		Scores:      scores,
+		Feedback:    strings.TrimSpace(req.Feedback),
+		TotalScore:  res.Total,
+		Percent:     res.Percent,
+		Grade:       res.Grade,
+		SubmittedAt: time.Now(),
+	}, nil
+}
 
-	rawScores := make([]float64, 0, len(scores))
-	for _, ev := range scores {
-		rawScores = append(rawScores, float64(ev.TotalScore))
+// notifyKPIDispute แจ้งผู้จัดการแผนกทางอีเมลเมื่อมีการโต้แย้ง (ส่งไม่สำเร็จไม่กระทบการโต้แย้ง)
+func (s *kpiEvaluationRepoService) notifyKPIDispute(ctx context.Context, ev *models.KPIEvaluation, reason string) {
+	if ev.Department == "" {
+		return
+	}
+	dept, _ := s.departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{"department_id": ev.Department, "deleted_at": nil}, bson.M{"_id": 0, "manager_id": 1})
+	if dept == nil || dept.ManagerID == "" {
+		return
 	}
-	finalScores := helpers.KPIFromScores(rawScores, 5)
+	evaluatee := s.kpiUserName(ctx, map[string]string{}, ev.EvaluateeID)
+	subject := "มีการโต้แย้งผลประเมิน KPI"
+	body := fmt.Sprintf("%s โต้แย้งผลประเมิน KPI (รหัส %s)\nเหตุผล: %s\nกรุณาพิจารณาข้อโต้แย้งในระบบ", evaluatee, ev.EvaluationID, reason)
+	s.mailKPIUser(ctx, dept.ManagerID, subject, body)
+}
 
-	userTaskStats, errOnGetOneUserTaskStats := s.taskRepo.GetOneUserTaskStatsByFilter(
-		ctx,
-		bson.M{"user_id": existing.EvaluateeID},
-		bson.M{},
-	)
-	if errOnGetOneUserTaskStats != nil {
-		return errOnGetOneUserTaskStats
+// mailKPIUser ส่งอีเมลถึงผู้ใช้ (ไม่ได้ตั้งค่า Email/ไม่มีอีเมล/ส่งไม่สำเร็จ = false)
+func (s *kpiEvaluationRepoService) mailKPIUser(ctx context.Context, userID, subject, body string) bool {
+	if s.config.Email.Host == "" || userID == "" {
+		return false
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil || strings.TrimSpace(user.Email) == "" {
+		return false
+	}
+	emailCfg := util.EmailConfig{
+		Host:     s.config.Email.Host,
+		Port:     s.config.Email.Port,
+		Username: s.config.Email.Username,
+		Password: s.config.Email.Password,
+		From:     s.config.Email.From,
+	}
+	if err := util.SendMail(emailCfg, user.Email, subject, fmt.Sprintf("เรียนคุณ %s\n\n%s", user.FirstNameTH, body)); err != nil {
+		log.Println("Error sending KPI evaluation email:", err)
+		return false
 	}
+	return true
+}
 
-	userTaskStats.KPI.Score = &finalScores
-	userTaskStats.KPI.LastCalculatedAt = &now
+func (s *kpiEvaluationRepoService) kpiUserName(ctx context.Context, cache map[string]string, userID string) string {
+	if userID == "" {
+		return ""
+	}
+	if name, ok := cache[userID]; ok {
+		return name
+	}
+	name := ""
+	if user, _ := s.userRepo.GetByID(ctx, userID); user != nil {
+		name = fmt.Sprintf("%s %s %s", user.TitleTH, user.FirstNameTH, user.LastNameTH)
+	}
+	cache[userID] = name
+	return name
+}
 
-	if err := s.taskRepo.UpsertUserTaskStats(ctx, userTaskStats); err != nil {
+// RecomputeKPIEvaluations คิดคะแนนการประเมินที่ประเมินแล้วใหม่ตามวิธีปัจจุบันของ template (kpiID ว่าง = ทุก template)
+// แล้วคำนวณ KPI รวมของผู้ถูกประเมินที่เกี่ยวข้องใหม่
+func (s *kpiEvaluationRepoService) RecomputeKPIEvaluations(ctx context.Context, kpiID string) (*dto.KPIRecomputeResult, error) {
+	start := time.Now()
+	filter := bson.M{"deleted_at": nil, "is_evaluated": true}
+	if kpiID = strings.TrimSpace(kpiID); kpiID != "" {
+		filter["kpi_id"] = kpiID
+	}
+	evaluations, err := s.kpiEvaluationRepo.GetAllKPIEvaluationByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.KPIRecomputeResult{RunAt: start, Evaluations: len(evaluations)}
+	strategies := make(map[string]helpers.KPIScoringStrategy)
+	users := make(map[string]bool)
+	for _, ev := range evaluations {
+		strategy, ok := strategies[ev.KPIID]
+		if !ok {
+			if strategy, err = s.scoringStrategy(ctx, ev.KPIID); err != nil {
+				return nil, err
+			}
+			strategies[ev.KPIID] = strategy
+		}
+
+		before := *ev
+		applyKPIResult(ev, strategy)
+		if ev.TotalScore == before.TotalScore && ev.Percent == before.Percent && ev.Grade == before.Grade && ev.ScoringMethod == before.ScoringMethod {
+			continue
+		}
+		// คงเวลาแก้ไขเดิม (updated_at ใช้เป็นวันที่ประเมินเสร็จ)
+		updated, err := s.kpiEvaluationRepo.UpdateKPIEvaluationByID(ctx, ev.EvaluationID, *ev)
+		if err != nil {
+			return nil, err
+		}
+		if updated == nil {
+			continue
+		}
+		result.Updated++
+		users[ev.EvaluateeID] = true
+	}
+
+	// kpi ทั้งหมด: คำนวณ KPI รวมใหม่ทุกคนที่มีการประเมิน (รวมคนที่คะแนนเดิมคิดด้วยสูตรเก่า)
+	if kpiID == "" {
+		for _, ev := range evaluations {
+			users[ev.EvaluateeID] = true
+		}
+	}
+	now := time.Now()
+	for userID := range users {
+		if userID == "" {
+			continue
+		}
+		if err := s.refreshUserKPI(ctx, userID, now); err != nil {
+			return nil, err
+		}
+		result.Users++
+	}
+
+	result.DurationMS = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+// scoringStrategy วิธีคิดคะแนนของ template (ไม่พบ template/ถูกลบ = weighted)
+func (s *kpiEvaluationRepoService) scoringStrategy(ctx context.Context, kpiID string) (helpers.KPIScoringStrategy, error) {
+	tpl, err := s.kpiRepo.GetOneKPIByFilter(ctx, bson.M{"kpi_id": kpiID}, bson.M{"scoring_method": 1, "bands": 1})
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+	if tpl == nil {
+		return helpers.NewKPIScoringStrategy("", nil)
+	}
+	return helpers.NewKPIScoringStrategy(tpl.ScoringMethod, tpl.Bands)
+}
+
+// refreshUserKPI KPI รวมของผู้ใช้จากการประเมินที่ประเมินแล้วทั้งหมด
+func (s *kpiEvaluationRepoService) refreshUserKPI(ctx context.Context, userID string, now time.Time) error {
+	evaluations, err := s.kpiEvaluationRepo.GetAllKPIEvaluationByFilter(
+		ctx,
+		bson.M{"evaluatee_id": userID, "deleted_at": nil, "is_evaluated": true},
+		bson.M{"_id": 0, "total_score": 1, "percent": 1, "grade": 1},
+	)
+	if err != nil {
 		return err
 	}
 
-	return nil
+	results := make([]helpers.KPIResult, 0, len(evaluations))
+	for _, ev := range evaluations {
+		results = append(results, helpers.KPIResult{Total: ev.TotalScore, Percent: ev.Percent, Grade: ev.Grade})
+	}
+	finalScores := helpers.KPIFromScores(results)
+
+	// อัปเดตเฉพาะ KPI ไม่เขียน totals ที่อ่านมาทับ (กันตัวเลขงานคลาด)
+	kpi := models.UserTaskKPI{Score: &finalScores, LastCalculatedAt: &now}
+	return s.taskRepo.SetUserTaskKPI(ctx, userID, kpi, now)
 }
 
 func (s *kpiEvaluationRepoService) ListKPIEvaluation(ctx context.Context, claims *dto.JWTClaims, page, size int, search string, department string, sortBy string, sortOrder string) (dto.Pagination, error) {
@@ -320,10 +1259,11 @@ func (s *kpiEvaluationRepoService) ListKPIEvaluation(ctx context.Context, claims
 			ProjectID:      m.ProjectID,
 			ProjectName:    projectName,
 			TaskID:         m.TaskID,
+			StepIDs:        m.StepIDs,
 			Description:    Description,
 			KPIID:          m.KPIID,
 			KPIName:        kpiName,
-			Version:        1,
+			Version:        m.Version,
 			EvaluatorID:    m.EvaluatorID,
 			EvaluatorName:  evaluatorName,
 			EvaluateeID:    m.EvaluateeID,
@@ -332,6 +1272,9 @@ func (s *kpiEvaluationRepoService) ListKPIEvaluation(ctx context.Context, claims
 			DepartmentName: departmentsName,
 			Scores:         scores,
 			TotalScore:     roundedTotal,
+			Percent:        m.Percent,
+			Grade:          m.Grade,
+			ScoringMethod:  m.ScoringMethod,
 			IsEvaluated:    m.IsEvaluated,
 			Feedback:       m.Feedback,
 			FinishedAt:     m.UpdatedAt,
@@ -353,3 +1296,73 @@ func (s *kpiEvaluationRepoService) ListKPIEvaluation(ctx context.Context, claims
 		List:       list,
 	}, nil
 }
+
+// applyKPIScoreRequests คืนสำเนาคะแนนที่แก้ตามคำขอ (ตัดคะแนนให้อยู่ใน 0..คะแนนเต็ม) ไม่แก้ slice เดิมที่ใช้เก็บประวัติ
+func applyKPIScoreRequests(base []models.KPIScore, req []dto.KPIScoreRequest) ([]models.KPIScore, bool) {
+	reqMap := make(map[string]dto.KPIScoreRequest, len(req))
+	for _, r := range req {
+		id := strings.TrimSpace(r.ItemID)
+		if id == "" {
+			continue
+		}
+		reqMap[id] = r
+	}
+
+	scores := append([]models.KPIScore(nil), base...)
+	updatedAny := false
+	for i, sc := range scores {
+		if r, ok := reqMap[sc.ItemID]; ok {
+			score := int(math.Round(r.Score))
+			if score < 0 {
+				score = 0
+			}
+			if score > sc.MaxScore {
+				score = sc.MaxScore
+			}
+			scores[i].Score = score
+			scores[i].Notes = strings.TrimSpace(r.Notes)
+			updatedAny = true
+		}
+	}
+	return scores, updatedAny
+}
+
+// blankKPIScores หัวข้อเดียวกับผู้ประเมินหลักแต่ยังไม่มีคะแนน (ตั้งต้นให้ self/peer)
+func blankKPIScores(items []models.KPIScore) []models.KPIScore {
+	scores := make([]models.KPIScore, len(items))
+	for i, it := range items {
+		it.Score = 0
+		it.Notes = ""
+		scores[i] = it
+	}
+	return scores
+}
+
+func kpiResultOf(ev *models.KPIEvaluation) helpers.KPIResult {
+	return helpers.KPIResult{Total: ev.TotalScore, Percent: ev.Percent, Grade: ev.Grade}
+}
+
+// appendKPIRevision เก็บสำเนาคะแนนทุกครั้งที่ส่ง (ใช้ version ล่าสุดของการประเมิน)
+func appendKPIRevision(ev *models.KPIEvaluation, role, evaluatorID string, scores []models.KPIScore, feedback string, res helpers.KPIResult, reason string, now time.Time) {
+	ev.Revisions = append(ev.Revisions, models.KPIRevision{
+		Version:     ev.Version,
+		Role:        role,
+		EvaluatorID: evaluatorID,
+		Scores:      append([]models.KPIScore(nil), scores...),
+		Feedback:    feedback,
+		TotalScore:  res.Total,
+		Percent:     res.Percent,
+		Grade:       res.Grade,
+		Reason:      reason,
+		RevisedAt:   now,
+	})
+}
+
+// applyKPIResult เขียนผลคิดคะแนนลงการประเมิน
+func applyKPIResult(ev *models.KPIEvaluation, strategy helpers.KPIScoringStrategy) {
+	res := strategy.Score(ev.Scores)
+	ev.TotalScore = res.Total
+	ev.Percent = res.Percent
+	ev.Grade = res.Grade
+	ev.ScoringMethod = strategy.Method()
+}
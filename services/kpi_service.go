@@ -10,6 +10,7 @@ import (
 	"github.com/Be2Bag/erp-demo/config"
 	"github.com/Be2Bag/erp-demo/dto"
 	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
 	"github.com/Be2Bag/erp-demo/ports"
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
@@ -67,6 +68,14 @@ func (s *kpiService) CreateKPITemplate(ctx context.Context, req dto.CreateKPITem
 	if sumWeight != 100 {
 		return fmt.Errorf("sum of weights must be 100, got %d", sumWeight)
 	}
+	scoringMethod, bands, err := resolveKPIScoring(req.ScoringMethod, req.Bands)
+	if err != nil {
+		return err
+	}
+	evaluator, err := s.resolveKPIEvaluatorPolicy(ctx, req.Evaluator)
+	if err != nil {
+		return err
+	}
 
 	filter := bson.M{
 		"kpi_name":      req.KPIName,
@@ -107,10 +116,15 @@ func (s *kpiService) CreateKPITemplate(ctx context.Context, req dto.CreateKPITem
 		TotalWeight: 100,
 		Items:       items,
 		IsActive:    true, // ตั้งค่าเอง ไม่เชื่อ client
-		Version:     1,
-		CreatedBy:   claims.UserID,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+
+		ScoringMethod: scoringMethod,
+		Bands:         bands,
+		Evaluator:     evaluator,
+
+		Version:   1,
+		CreatedBy: claims.UserID,
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 
 	if err := s.kpiRepo.CreateKPI(ctx, doc); err != nil {
@@ -153,10 +167,15 @@ func (s *kpiService) GetKPITemplateByID(ctx context.Context, kpiID string) (*dto
 		Department:  m.Department,
 		TotalWeight: m.TotalWeight,
 		Items:       ItemsDTO,
-		Version:     m.Version,
-		CreatedBy:   m.CreatedBy,
-		CreatedAt:   m.CreatedAt,
-		UpdatedAt:   m.UpdatedAt,
+
+		ScoringMethod: kpiScoringMethod(m),
+		Bands:         toKPIBandDTOs(m.Bands),
+		Evaluator:     toKPIEvaluatorPolicyDTO(effectiveKPIEvaluatorPolicy(m)),
+
+		Version:   m.Version,
+		CreatedBy: m.CreatedBy,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
 	}
 	return dtoObj, nil
 }
@@ -231,6 +250,34 @@ func (s *kpiService) UpdateKPITemplate(ctx context.Context, kpiID string, req dt
 		existing.TotalWeight = 100 // คงเป็น 100 เสมอ เพื่อความชัดเจน
 	}
 
+	// วิธีคิดคะแนน: ส่ง bands อย่างเดียวได้ถ้า template เป็นแบบ bands อยู่แล้ว
+	if strings.TrimSpace(req.ScoringMethod) != "" || req.Bands != nil {
+		method := req.ScoringMethod
+		if strings.TrimSpace(method) == "" {
+			method = existing.ScoringMethod
+		}
+		var bands []dto.KPIRatingBandDTO
+		if req.Bands != nil {
+			bands = *req.Bands
+		} else {
+			bands = toKPIBandDTOs(existing.Bands)
+		}
+		scoringMethod, normalized, err := resolveKPIScoring(method, bands)
+		if err != nil {
+			return err
+		}
+		existing.ScoringMethod = scoringMethod
+		existing.Bands = normalized
+	}
+
+	if req.Evaluator != nil {
+		evaluator, err := s.resolveKPIEvaluatorPolicy(ctx, req.Evaluator)
+		if err != nil {
+			return err
+		}
+		existing.Evaluator = evaluator
+	}
+
 	existing.Version += 1    // เพิ่มเวอร์ชัน
 	existing.UpdatedAt = now // อัปเดตเวลา
 
@@ -322,10 +369,14 @@ func (s *kpiService) ListKPITemplates(ctx context.Context, claims *dto.JWTClaims
 			Department:  m.Department,
 			TotalWeight: m.TotalWeight,
 			Items:       ItemsDTO,
-			Version:     m.Version,
-			CreatedBy:   m.CreatedBy,
-			CreatedAt:   m.CreatedAt,
-			UpdatedAt:   m.UpdatedAt,
+
+			ScoringMethod: kpiScoringMethod(&m),
+			Bands:         toKPIBandDTOs(m.Bands),
+
+			Version:   m.Version,
+			CreatedBy: m.CreatedBy,
+			CreatedAt: m.CreatedAt,
+			UpdatedAt: m.UpdatedAt,
 		})
 	}
 
@@ -342,3 +393,100 @@ func (s *kpiService) ListKPITemplates(ctx context.Context, claims *dto.JWTClaims
 		List:       list,
 	}, nil
 }
+
+// resolveKPIScoring ตรวจวิธีคิดคะแนน เก็บ bands เฉพาะแบบ bands (ว่าง = A/B/C/D เริ่มต้น)
+func resolveKPIScoring(method string, bands []dto.KPIRatingBandDTO) (string, []models.KPIRatingBand, error) {
+	m, err := helpers.NormalizeKPIScoringMethod(method)
+	if err != nil {
+		return "", nil, err
+	}
+	if m != helpers.KPIScoringBands {
+		return m, nil, nil
+	}
+	normalized, err := helpers.NormalizeKPIBands(fromKPIBandDTOs(bands))
+	if err != nil {
+		return "", nil, err
+	}
+	return m, normalized, nil
+}
+
+// kpiScoringMethod วิธีคิดคะแนนของ template (template เก่าที่ยังไม่ได้ตั้ง = weighted)
+// resolveKPIEvaluatorPolicy ตรวจกฎเลือกผู้ประเมิน (nil = ค่าเริ่มต้น)
+func (s *kpiService) resolveKPIEvaluatorPolicy(ctx context.Context, req *dto.KPIEvaluatorPolicyDTO) (models.KPIEvaluatorPolicy, error) {
+	if req == nil {
+		return defaultKPIEvaluatorPolicy(), nil
+	}
+	policy := models.KPIEvaluatorPolicy{
+		Rule:          strings.ToLower(strings.TrimSpace(req.Rule)),
+		ReviewerID:    strings.TrimSpace(req.ReviewerID),
+		ReminderDays:  req.ReminderDays,
+		AutoCloseDays: req.AutoCloseDays,
+	}
+	if policy.Rule == "" {
+		policy.Rule = models.KPIEvaluatorDepartmentManager
+	}
+	if !helpers.InSet(policy.Rule, models.KPIEvaluatorDepartmentManager, models.KPIEvaluatorTaskCreator, models.KPIEvaluatorReviewer) {
+		return policy, fmt.Errorf("evaluator.rule must be department_manager, task_creator or reviewer")
+	}
+	if policy.ReminderDays < 0 || policy.ReminderDays > 365 || policy.AutoCloseDays < 0 || policy.AutoCloseDays > 365 {
+		return policy, fmt.Errorf("evaluator.reminder_days and evaluator.auto_close_days must be between 0 and 365")
+	}
+	if policy.Rule != models.KPIEvaluatorReviewer {
+		policy.ReviewerID = ""
+		return policy, nil
+	}
+	if policy.ReviewerID == "" {
+		return policy, fmt.Errorf("evaluator.reviewer_id is required for rule reviewer")
+	}
+	reviewer, err := s.userRepo.GetByID(ctx, policy.ReviewerID)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return policy, err
+	}
+	if reviewer == nil || reviewer.DeletedAt != nil {
+		return policy, fmt.Errorf("evaluator.reviewer_id not found")
+	}
+	return policy, nil
+}
+
+// defaultKPIEvaluatorPolicy ผู้จัดการแผนกเป็นผู้ประเมิน เตือนทุก 3 วัน ไม่ปิดอัตโนมัติ
+func defaultKPIEvaluatorPolicy() models.KPIEvaluatorPolicy {
+	return models.KPIEvaluatorPolicy{Rule: models.KPIEvaluatorDepartmentManager, ReminderDays: 3}
+}
+
+// effectiveKPIEvaluatorPolicy กฎของ template (template เดิมที่ยังไม่ตั้งค่า/ไม่พบ template = ค่าเริ่มต้น)
+func effectiveKPIEvaluatorPolicy(tpl *models.KPITemplate) models.KPIEvaluatorPolicy {
+	if tpl == nil || tpl.Evaluator.Rule == "" {
+		return defaultKPIEvaluatorPolicy()
+	}
+	return tpl.Evaluator
+}
+
+func toKPIEvaluatorPolicyDTO(p models.KPIEvaluatorPolicy) dto.KPIEvaluatorPolicyDTO {
+	return dto.KPIEvaluatorPolicyDTO{Rule: p.Rule, ReviewerID: p.ReviewerID, ReminderDays: p.ReminderDays, AutoCloseDays: p.AutoCloseDays}
+}
+
+func kpiScoringMethod(t *models.KPITemplate) string {
+	if t == nil || t.ScoringMethod == "" {
+		return helpers.KPIScoringWeighted
+	}
+	return t.ScoringMethod
+}
+
+func toKPIBandDTOs(bands []models.KPIRatingBand) []dto.KPIRatingBandDTO {
+	if len(bands) == 0 {
+		return nil
+	}
+	out := make([]dto.KPIRatingBandDTO, 0, len(bands))
+	for _, b := range bands {
+		out = append(out, dto.KPIRatingBandDTO{Grade: b.Grade, MinPercent: b.MinPercent, Score: b.Score})
+	}
+	return out
+}
+
+func fromKPIBandDTOs(bands []dto.KPIRatingBandDTO) []models.KPIRatingBand {
+	out := make([]models.KPIRatingBand, 0, len(bands))
+	for _, b := range bands {
+		out = append(out, models.KPIRatingBand{Grade: b.Grade, MinPercent: b.MinPercent, Score: b.Score})
+	}
+	return out
+}
@@ -0,0 +1,1173 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/config"
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/pkg/util"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	leaveCarryYears       = 5  // ย้อนคิดยอดยกมาไม่เกินกี่ปี
+	leaveCalendarMaxDays  = 92 // ช่วงปฏิทินทีมสูงสุด
+	leaveMaxEntitledDays  = 365
+	leaveMaxNoticeDays    = 365
+	leaveDefaultListLimit = 10
+)
+
+// ลำดับประเภทการลาที่แสดงผล
+var leaveTypes = []string{models.LeaveSick, models.LeavePersonal, models.LeaveAnnual, models.LeaveMaternity, models.LeaveOrdination, models.LeaveMilitary}
+
+var leaveTypeNames = map[string]string{
+	models.LeaveSick:       "ลาป่วย",
+	models.LeavePersonal:   "ลากิจ",
+	models.LeaveAnnual:     "ลาพักร้อน",
+	models.LeaveMaternity:  "ลาคลอด",
+	models.LeaveOrdination: "ลาอุปสมบท",
+	models.LeaveMilitary:   "ลารับราชการทหาร",
+}
+
+// สถานะที่นับเป็นการลา (หักสิทธิ์/ซ้อนกันไม่ได้)
+var leaveActiveStatuses = []string{models.LeavePending, models.LeaveApproved, models.LeaveRecorded}
+
+// สถานะที่อนุมัติแล้ว (หักสิทธิ์และกำลังการผลิต)
+var leaveTakenStatuses = []string{models.LeaveApproved, models.LeaveRecorded}
+
+type leaveService struct {
+	config         config.Config
+	leaveRepo      ports.LeaveRepository
+	userRepo       ports.UserRepository
+	departmentRepo ports.DepartmentRepository
+	positionRepo   ports.PositionRepository
+	capacityRepo   ports.CapacityRepository
+}
+
+func NewLeaveService(cfg config.Config, leaveRepo ports.LeaveRepository, userRepo ports.UserRepository, departmentRepo ports.DepartmentRepository, positionRepo ports.PositionRepository, capacityRepo ports.CapacityRepository) ports.LeaveService {
+	return &leaveService{config: cfg, leaveRepo: leaveRepo, userRepo: userRepo, departmentRepo: departmentRepo, positionRepo: positionRepo, capacityRepo: capacityRepo}
+}
+
+// ---------- นโยบายสิทธิ์การลา ----------
+
+func (s *leaveService) ListLeavePolicies(ctx context.Context, claims *dto.JWTClaims) ([]dto.LeavePolicyDTO, error) {
+	policies, err := s.leaveRepo.GetAllLeavePoliciesByFilter(ctx, bson.M{"deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	byType := make(map[string][]*models.LeavePolicy)
+	for _, p := range policies {
+		byType[p.LeaveType] = append(byType[p.LeaveType], p)
+	}
+
+	positionNames := make(map[string]string)
+	out := make([]dto.LeavePolicyDTO, 0, len(policies)+len(leaveTypes))
+	for _, lt := range leaveTypes {
+		hasGeneral := false
+		for _, p := range byType[lt] {
+			if p.PositionID == "" {
+				hasGeneral = true
+			}
+		}
+		if !hasGeneral {
+			def := defaultLeavePolicy(lt)
+			out = append(out, toLeavePolicyDTO(&def, "", true))
+		}
+		for _, p := range byType[lt] {
+			out = append(out, toLeavePolicyDTO(p, s.positionName(ctx, positionNames, p.PositionID), false))
+		}
+	}
+	return out, nil
+}
+
+func (s *leaveService) UpsertLeavePolicy(ctx context.Context, req dto.UpsertLeavePolicyDTO, claims *dto.JWTClaims) (*dto.LeavePolicyDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrLeaveForbidden
+	}
+	leaveType := strings.TrimSpace(req.LeaveType)
+	if _, ok := leaveTypeNames[leaveType]; !ok {
+		return nil, fmt.Errorf("invalid leave_type: %s", req.LeaveType)
+	}
+	positionID := strings.TrimSpace(req.PositionID)
+	positionName := ""
+	if positionID != "" {
+		position, err := s.positionRepo.GetOnePositionByFilter(ctx, bson.M{"position_id": positionID, "deleted_at": nil}, bson.M{"_id": 0, "position_name": 1})
+		if err != nil {
+			return nil, err
+		}
+		if position == nil {
+			return nil, errors.New("position not found")
+		}
+		positionName = position.PositionName
+	}
+
+	if len(req.Tiers) == 0 {
+		return nil, errors.New("at least one tier is required")
+	}
+	tiers := make([]models.LeaveTier, 0, len(req.Tiers))
+	seen := make(map[int]bool)
+	maxDays := 0.0
+	for _, t := range req.Tiers {
+		if t.MinServiceMonths < 0 {
+			return nil, errors.New("min_service_months must be >= 0")
+		}
+		if t.Days < 0 || t.Days > leaveMaxEntitledDays || !isHalfDayStep(t.Days) {
+			return nil, fmt.Errorf("tier days must be between 0 and %d in 0.5 steps", leaveMaxEntitledDays)
+		}
+		if seen[t.MinServiceMonths] {
+			return nil, fmt.Errorf("duplicate tier for %d service months", t.MinServiceMonths)
+		}
+		seen[t.MinServiceMonths] = true
+		tiers = append(tiers, models.LeaveTier{MinServiceMonths: t.MinServiceMonths, Days: t.Days})
+		maxDays = math.Max(maxDays, t.Days)
+	}
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].MinServiceMonths < tiers[j].MinServiceMonths })
+
+	paidDays := maxDays
+	if req.PaidDays != nil {
+		paidDays = *req.PaidDays
+	}
+	if paidDays < 0 || paidDays > leaveMaxEntitledDays {
+		return nil, fmt.Errorf("paid_days must be between 0 and %d", leaveMaxEntitledDays)
+	}
+	if req.MaxCarryOver < 0 || req.MaxCarryOver > leaveMaxEntitledDays {
+		return nil, fmt.Errorf("max_carry_over must be between 0 and %d", leaveMaxEntitledDays)
+	}
+	if req.MinNoticeDays < 0 || req.MinNoticeDays > leaveMaxNoticeDays {
+		return nil, fmt.Errorf("min_notice_days must be between 0 and %d", leaveMaxNoticeDays)
+	}
+
+	now := time.Now()
+	policy := models.LeavePolicy{
+		UpdatedAt:     now,
+		LeaveType:     leaveType,
+		PositionID:    positionID,
+		Tiers:         tiers,
+		PaidDays:      paidDays,
+		MaxCarryOver:  req.MaxCarryOver,
+		AllowExceed:   req.AllowExceed,
+		AllowHalfDay:  req.AllowHalfDay,
+		CalendarDays:  req.CalendarDays,
+		MinNoticeDays: req.MinNoticeDays,
+		UpdatedBy:     claims.UserID,
+	}
+
+	existing, err := s.leaveRepo.GetOneLeavePolicyByFilter(ctx, bson.M{"leave_type": leaveType, "position_id": positionID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		updated, err := s.leaveRepo.UpdateLeavePolicyByID(ctx, existing.PolicyID, policy)
+		if err != nil {
+			return nil, err
+		}
+		if updated == nil {
+			return nil, mongo.ErrNoDocuments
+		}
+		out := toLeavePolicyDTO(updated, positionName, false)
+		return &out, nil
+	}
+
+	policy.PolicyID = uuid.NewString()
+	policy.CreatedAt = now
+	if err := s.leaveRepo.CreateLeavePolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+	out := toLeavePolicyDTO(&policy, positionName, false)
+	return &out, nil
+}
+
+func (s *leaveService) DeleteLeavePolicy(ctx context.Context, policyID string, claims *dto.JWTClaims) error {
+	if claims.Role != "admin" {
+		return ports.ErrLeaveForbidden
+	}
+	existing, err := s.leaveRepo.GetOneLeavePolicyByFilter(ctx, bson.M{"policy_id": policyID, "deleted_at": nil}, bson.M{"_id": 0, "policy_id": 1})
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return mongo.ErrNoDocuments
+	}
+	return s.leaveRepo.SoftDeleteLeavePolicyByID(ctx, policyID)
+}
+
+// ---------- สิทธิ์คงเหลือ ----------
+
+func (s *leaveService) GetLeaveBalances(ctx context.Context, req dto.RequestLeaveBalance, claims *dto.JWTClaims) (*dto.LeaveBalanceSummaryDTO, error) {
+	userID := strings.TrimSpace(req.UserID)
+	if userID == "" {
+		userID = claims.UserID
+	}
+	user, err := s.getLeaveUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if userID != claims.UserID && claims.Role != "admin" {
+		isManager, err := s.isLeaveManager(ctx, user.DepartmentID, claims.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !isManager {
+			return nil, ports.ErrLeaveForbidden
+		}
+	}
+
+	year := req.Year
+	if year == 0 {
+		year = helpers.Today().Year()
+	}
+	balances, err := s.computeLeaveBalances(ctx, user, year)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &dto.LeaveBalanceSummaryDTO{
+		UserID:        user.UserID,
+		UserName:      strings.TrimSpace(user.FirstNameTH + " " + user.LastNameTH),
+		Year:          year,
+		ServiceMonths: helpers.ServiceMonths(user.HireDate, leaveAsOf(year)),
+		Balances:      make([]dto.LeaveBalanceDTO, 0, len(leaveTypes)),
+	}
+	for _, lt := range leaveTypes {
+		out.Balances = append(out.Balances, *balances[lt])
+	}
+	return out, nil
+}
+
+// computeLeaveBalances สิทธิ์คงเหลือทุกประเภทของปี โดยคิดยอดยกมาจากปีก่อนหน้าตามนโยบาย
+func (s *leaveService) computeLeaveBalances(ctx context.Context, user *models.User, year int) (map[string]*dto.LeaveBalanceDTO, error) {
+	policies, err := s.loadLeavePolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	startYear := year - leaveCarryYears
+	if !user.HireDate.IsZero() && user.HireDate.Year() > startYear {
+		startYear = user.HireDate.Year()
+	}
+	if startYear > year {
+		startYear = year
+	}
+
+	requests, err := s.leaveRepo.GetAllLeaveRequestsByFilter(ctx, bson.M{
+		"user_id":    user.UserID,
+		"year":       bson.M{"$gte": startYear, "$lte": year},
+		"status":     bson.M{"$in": leaveActiveStatuses},
+		"deleted_at": nil,
+	}, bson.M{"_id": 0, "leave_type": 1, "year": 1, "days": 1, "status": 1})
+	if err != nil {
+		return nil, err
+	}
+	used := make(map[string]map[int]float64)
+	pending := make(map[string]float64)
+	for _, r := range requests {
+		if r.Status == models.LeavePending {
+			if r.Year == year {
+				pending[r.LeaveType] += r.Days
+			}
+			continue
+		}
+		if used[r.LeaveType] == nil {
+			used[r.LeaveType] = make(map[int]float64)
+		}
+		used[r.LeaveType][r.Year] += r.Days
+	}
+
+	out := make(map[string]*dto.LeaveBalanceDTO, len(leaveTypes))
+	for _, lt := range leaveTypes {
+		policy := effectiveLeavePolicy(policies, lt, user.PositionID)
+		carry := leaveCarriedOver(policy, user.HireDate, used[lt], startYear, year)
+		entitled := helpers.LeaveEntitlement(policy.Tiers, helpers.ServiceMonths(user.HireDate, leaveAsOf(year)))
+		taken := used[lt][year]
+		out[lt] = &dto.LeaveBalanceDTO{
+			LeaveType:     lt,
+			LeaveTypeName: leaveTypeNames[lt],
+			Entitled:      entitled,
+			CarriedOver:   carry,
+			Used:          taken,
+			Pending:       pending[lt],
+			Remaining:     entitled + carry - taken - pending[lt],
+			PaidDays:      policy.PaidDays,
+			UnpaidDays:    math.Max(0, taken-policy.PaidDays-carry),
+			AllowHalfDay:  policy.AllowHalfDay,
+		}
+	}
+	return out, nil
+}
+
+// ---------- ใบลา ----------
+
+func (s *leaveService) CreateLeaveRequest(ctx context.Context, req dto.CreateLeaveRequestDTO, claims *dto.JWTClaims) (*dto.LeaveRequestDTO, error) {
+	userID := strings.TrimSpace(req.UserID)
+	if userID == "" {
+		userID = claims.UserID
+	}
+	if userID != claims.UserID && claims.Role != "admin" {
+		return nil, ports.ErrLeaveForbidden
+	}
+	user, err := s.getLeaveUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	leaveType := strings.TrimSpace(req.LeaveType)
+	if _, ok := leaveTypeNames[leaveType]; !ok {
+		return nil, fmt.Errorf("invalid leave_type: %s", req.LeaveType)
+	}
+	start, err := time.Parse("2006-01-02", strings.TrimSpace(req.StartDate))
+	if err != nil {
+		return nil, errors.New("invalid start_date format, expected YYYY-MM-DD")
+	}
+	end := start
+	if v := strings.TrimSpace(req.EndDate); v != "" {
+		if end, err = time.Parse("2006-01-02", v); err != nil {
+			return nil, errors.New("invalid end_date format, expected YYYY-MM-DD")
+		}
+	}
+	if end.Before(start) {
+		return nil, errors.New("end_date must be on or after start_date")
+	}
+	if start.Year() != end.Year() {
+		return nil, errors.New("leave cannot span two years, please split the request at the year end")
+	}
+
+	policies, err := s.loadLeavePolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	policy := effectiveLeavePolicy(policies, leaveType, user.PositionID)
+
+	halfDay := strings.TrimSpace(req.HalfDay)
+	if halfDay != "" {
+		if halfDay != models.LeaveHalfMorning && halfDay != models.LeaveHalfAfternoon {
+			return nil, errors.New("half_day must be morning or afternoon")
+		}
+		if !start.Equal(end) {
+			return nil, errors.New("half-day leave must be a single day")
+		}
+		if !policy.AllowHalfDay {
+			return nil, fmt.Errorf("%s cannot be taken as half-day", leaveTypeNames[leaveType])
+		}
+	}
+	if claims.Role != "admin" && policy.MinNoticeDays > 0 && start.Before(helpers.Today().AddDate(0, 0, policy.MinNoticeDays)) {
+		return nil, fmt.Errorf("%s must be requested at least %d day(s) in advance", leaveTypeNames[leaveType], policy.MinNoticeDays)
+	}
+
+	days, err := s.countLeaveDays(ctx, user.DepartmentID, policy, start, end, halfDay)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkLeaveOverlap(ctx, user.UserID, start, end, halfDay); err != nil {
+		return nil, err
+	}
+	if !policy.AllowExceed {
+		balances, err := s.computeLeaveBalances(ctx, user, start.Year())
+		if err != nil {
+			return nil, err
+		}
+		if b := balances[leaveType]; b.Remaining < days {
+			return nil, fmt.Errorf("insufficient %s balance: %.1f day(s) remaining, %.1f requested", leaveTypeNames[leaveType], math.Max(0, b.Remaining), days)
+		}
+	}
+
+	approverID := ""
+	if user.DepartmentID != "" {
+		dept, err := s.departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{"department_id": user.DepartmentID, "deleted_at": nil}, bson.M{"_id": 0, "manager_id": 1})
+		if err != nil {
+			return nil, err
+		}
+		if dept != nil && dept.ManagerID != user.UserID {
+			approverID = dept.ManagerID
+		}
+	}
+
+	now := time.Now()
+	request := models.LeaveRequest{
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		RequestID:    uuid.NewString(),
+		UserID:       user.UserID,
+		DepartmentID: user.DepartmentID,
+		LeaveType:    leaveType,
+		StartDate:    start,
+		EndDate:      end,
+		HalfDay:      halfDay,
+		Days:         days,
+		Year:         start.Year(),
+		Reason:       strings.TrimSpace(req.Reason),
+		Attachment:   strings.TrimSpace(req.Attachment),
+		Status:       models.LeavePending,
+		ApproverID:   approverID,
+		CreatedBy:    claims.UserID,
+	}
+	if err := s.leaveRepo.CreateLeaveRequest(ctx, request); err != nil {
+		return nil, err
+	}
+
+	if approverID != "" {
+		name := strings.TrimSpace(user.FirstNameTH + " " + user.LastNameTH)
+		body := fmt.Sprintf("%s ขอ%s %s (%.1f วัน)\nเหตุผล: %s\nกรุณาพิจารณาอนุมัติในระบบ", name, leaveTypeNames[leaveType], leavePeriodText(&request), days, request.Reason)
+		s.mailLeaveUser(ctx, approverID, "มีใบลารออนุมัติ", body)
+	}
+	return s.toLeaveRequestDTO(ctx, &request, newLeaveNameCache()), nil
+}
+
+func (s *leaveService) ListLeaveRequests(ctx context.Context, req dto.RequestListLeaveRequests, claims *dto.JWTClaims) (dto.Pagination, error) {
+	page, size := req.Page, req.Limit
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 100 {
+		size = leaveDefaultListLimit
+	}
+	skip := int64((page - 1) * size)
+	limit := int64(size)
+
+	filter := bson.M{"deleted_at": nil}
+	if claims.Role != "admin" {
+		managed, err := s.managedLeaveDepartments(ctx, claims.UserID)
+		if err != nil {
+			return dto.Pagination{}, err
+		}
+		filter["$or"] = []bson.M{{"user_id": claims.UserID}, {"department_id": bson.M{"$in": managed}}}
+	}
+	if v := strings.TrimSpace(req.UserID); v != "" {
+		filter["user_id"] = v
+	}
+	if v := strings.TrimSpace(req.DepartmentID); v != "" {
+		filter["department_id"] = v
+	}
+	if v := strings.TrimSpace(req.LeaveType); v != "" {
+		filter["leave_type"] = v
+	}
+	if v := strings.TrimSpace(req.Status); v != "" {
+		filter["status"] = v
+	}
+	if req.Year > 0 {
+		filter["year"] = req.Year
+	}
+
+	sortBy := bson.D{
+		{Key: "start_date", Value: -1},
+		{Key: "_id", Value: -1},
+	}
+	items, total, err := s.leaveRepo.GetListLeaveRequestsByFilter(ctx, filter, bson.M{}, sortBy, skip, limit)
+	if err != nil {
+		return dto.Pagination{}, fmt.Errorf("list leave requests: %w", err)
+	}
+
+	names := newLeaveNameCache()
+	list := make([]interface{}, 0, len(items))
+	for i := range items {
+		list = append(list, *s.toLeaveRequestDTO(ctx, &items[i], names))
+	}
+
+	totalPages := 0
+	if total > 0 && size > 0 {
+		totalPages = int((total + int64(size) - 1) / int64(size))
+	}
+
+	return dto.Pagination{
+		Page:       page,
+		Size:       size,
+		TotalCount: int(total),
+		TotalPages: totalPages,
+		List:       list,
+	}, nil
+}
+
+func (s *leaveService) ListLeaveApprovals(ctx context.Context, claims *dto.JWTClaims) ([]dto.LeaveRequestDTO, error) {
+	managed, err := s.managedLeaveDepartments(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	or := []bson.M{
+		{"status": models.LeavePending, "approver_id": claims.UserID},
+		{"status": models.LeavePending, "department_id": bson.M{"$in": managed}},
+	}
+	if claims.Role == "admin" {
+		// HR: ใบลาที่ไม่มีผู้จัดการอนุมัติ และใบลาที่อนุมัติแล้วรอบันทึก
+		or = append(or, bson.M{"status": models.LeavePending, "approver_id": ""}, bson.M{"status": models.LeaveApproved})
+	}
+	filter := bson.M{"deleted_at": nil, "user_id": bson.M{"$ne": claims.UserID}, "$or": or}
+
+	requests, err := s.leaveRepo.GetAllLeaveRequestsByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	names := newLeaveNameCache()
+	out := make([]dto.LeaveRequestDTO, 0, len(requests))
+	for _, r := range requests {
+		out = append(out, *s.toLeaveRequestDTO(ctx, r, names))
+	}
+	return out, nil
+}
+
+func (s *leaveService) GetLeaveRequest(ctx context.Context, requestID string, claims *dto.JWTClaims) (*dto.LeaveRequestDTO, error) {
+	request, err := s.getLeaveRequest(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.UserID != claims.UserID && claims.Role != "admin" {
+		canApprove, err := s.canApproveLeave(ctx, request, claims)
+		if err != nil {
+			return nil, err
+		}
+		if !canApprove {
+			return nil, ports.ErrLeaveForbidden
+		}
+	}
+	return s.toLeaveRequestDTO(ctx, request, newLeaveNameCache()), nil
+}
+
+func (s *leaveService) ApproveLeaveRequest(ctx context.Context, requestID string, req dto.LeaveDecisionDTO, claims *dto.JWTClaims) (*dto.LeaveRequestDTO, error) {
+	request, err := s.getLeaveRequest(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	canApprove, err := s.canApproveLeave(ctx, request, claims)
+	if err != nil {
+		return nil, err
+	}
+	if !canApprove {
+		return nil, ports.ErrLeaveForbidden
+	}
+	if request.Status != models.LeavePending {
+		return nil, fmt.Errorf("%w: leave request is %s", ports.ErrLeaveConflict, request.Status)
+	}
+
+	// ตรวจสิทธิ์อีกครั้ง เผื่อมีใบลาอื่นถูกอนุมัติไปก่อน
+	user, err := s.getLeaveUser(ctx, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+	policies, err := s.loadLeavePolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if policy := effectiveLeavePolicy(policies, request.LeaveType, user.PositionID); !policy.AllowExceed {
+		balances, err := s.computeLeaveBalances(ctx, user, request.Year)
+		if err != nil {
+			return nil, err
+		}
+		b := balances[request.LeaveType]
+		if available := b.Entitled + b.CarriedOver - b.Used; available < request.Days {
+			return nil, fmt.Errorf("insufficient %s balance: %.1f day(s) available, %.1f requested", leaveTypeNames[request.LeaveType], math.Max(0, available), request.Days)
+		}
+	}
+
+	now := time.Now()
+	updated, err := s.leaveRepo.TransitionLeaveRequest(ctx, requestID, []string{models.LeavePending}, bson.M{
+		"status":           models.LeaveApproved,
+		"decided_by":       claims.UserID,
+		"decided_at":       now,
+		"decision_comment": strings.TrimSpace(req.Comment),
+		"updated_at":       now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("%w: leave request was changed by someone else, please reload", ports.ErrLeaveConflict)
+	}
+
+	s.mailLeaveUser(ctx, updated.UserID, "ใบลาได้รับการอนุมัติ", fmt.Sprintf("ใบ%s %s ได้รับการอนุมัติแล้ว", leaveTypeNames[updated.LeaveType], leavePeriodText(updated)))
+	return s.toLeaveRequestDTO(ctx, updated, newLeaveNameCache()), nil
+}
+
+func (s *leaveService) RejectLeaveRequest(ctx context.Context, requestID string, req dto.LeaveDecisionDTO, claims *dto.JWTClaims) (*dto.LeaveRequestDTO, error) {
+	comment := strings.TrimSpace(req.Comment)
+	if comment == "" {
+		return nil, errors.New("comment is required when rejecting")
+	}
+	request, err := s.getLeaveRequest(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	canApprove, err := s.canApproveLeave(ctx, request, claims)
+	if err != nil {
+		return nil, err
+	}
+	if !canApprove {
+		return nil, ports.ErrLeaveForbidden
+	}
+
+	now := time.Now()
+	updated, err := s.leaveRepo.TransitionLeaveRequest(ctx, requestID, []string{models.LeavePending}, bson.M{
+		"status":           models.LeaveRejected,
+		"decided_by":       claims.UserID,
+		"decided_at":       now,
+		"decision_comment": comment,
+		"updated_at":       now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("%w: leave request is no longer pending", ports.ErrLeaveConflict)
+	}
+
+	s.mailLeaveUser(ctx, updated.UserID, "ใบลาไม่ได้รับการอนุมัติ", fmt.Sprintf("ใบ%s %s ไม่ได้รับการอนุมัติ\nเหตุผล: %s", leaveTypeNames[updated.LeaveType], leavePeriodText(updated), comment))
+	return s.toLeaveRequestDTO(ctx, updated, newLeaveNameCache()), nil
+}
+
+func (s *leaveService) RecordLeaveRequest(ctx context.Context, requestID string, req dto.LeaveDecisionDTO, claims *dto.JWTClaims) (*dto.LeaveRequestDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrLeaveForbidden
+	}
+	if _, err := s.getLeaveRequest(ctx, requestID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	updated, err := s.leaveRepo.TransitionLeaveRequest(ctx, requestID, []string{models.LeaveApproved}, bson.M{
+		"status":      models.LeaveRecorded,
+		"recorded_by": claims.UserID,
+		"recorded_at": now,
+		"hr_note":     strings.TrimSpace(req.Comment),
+		"updated_at":  now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("%w: only approved leave requests can be recorded", ports.ErrLeaveConflict)
+	}
+	return s.toLeaveRequestDTO(ctx, updated, newLeaveNameCache()), nil
+}
+
+// CancelLeaveRequest ผู้ลายกเลิกได้ก่อนเริ่มลา (รออนุมัติ/อนุมัติแล้ว) HR ยกเลิกได้ทุกใบที่ยังไม่ถูกปฏิเสธ
+func (s *leaveService) CancelLeaveRequest(ctx context.Context, requestID string, req dto.LeaveDecisionDTO, claims *dto.JWTClaims) (*dto.LeaveRequestDTO, error) {
+	request, err := s.getLeaveRequest(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := leaveActiveStatuses
+	if claims.Role != "admin" {
+		if request.UserID != claims.UserID {
+			return nil, ports.ErrLeaveForbidden
+		}
+		from = []string{models.LeavePending}
+		if request.StartDate.After(helpers.Today()) {
+			from = []string{models.LeavePending, models.LeaveApproved}
+		}
+	}
+	if !helpers.InSet(request.Status, from...) {
+		return nil, fmt.Errorf("%w: %s leave request cannot be cancelled", ports.ErrLeaveConflict, request.Status)
+	}
+
+	now := time.Now()
+	updated, err := s.leaveRepo.TransitionLeaveRequest(ctx, requestID, from, bson.M{
+		"status":        models.LeaveCancelled,
+		"cancelled_by":  claims.UserID,
+		"cancelled_at":  now,
+		"cancel_reason": strings.TrimSpace(req.Comment),
+		"updated_at":    now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("%w: leave request was changed by someone else, please reload", ports.ErrLeaveConflict)
+	}
+
+	// แจ้งผู้อนุมัติเมื่อใบที่อนุมัติแล้วถูกยกเลิก (คืนกำลังการผลิต)
+	if request.Status != models.LeavePending && request.DecidedBy != "" && request.DecidedBy != claims.UserID {
+		s.mailLeaveUser(ctx, request.DecidedBy, "ใบลาที่อนุมัติถูกยกเลิก", fmt.Sprintf("ใบ%s %s ที่คุณอนุมัติถูกยกเลิกแล้ว", leaveTypeNames[request.LeaveType], leavePeriodText(request)))
+	}
+	return s.toLeaveRequestDTO(ctx, updated, newLeaveNameCache()), nil
+}
+
+// ---------- ปฏิทินการลาของทีม ----------
+
+func (s *leaveService) GetLeaveCalendar(ctx context.Context, req dto.RequestLeaveCalendar, claims *dto.JWTClaims) (*dto.LeaveCalendarDTO, error) {
+	today := helpers.Today()
+	start := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if v := strings.TrimSpace(req.StartDate); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return nil, errors.New("invalid start_date format, expected YYYY-MM-DD")
+		}
+		start = parsed
+	}
+	end := time.Date(start.Year(), start.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+	if v := strings.TrimSpace(req.EndDate); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return nil, errors.New("invalid end_date format, expected YYYY-MM-DD")
+		}
+		end = parsed
+	}
+	if end.Before(start) {
+		return nil, errors.New("end_date must be on or after start_date")
+	}
+	if int(end.Sub(start).Hours()/24)+1 > leaveCalendarMaxDays {
+		return nil, fmt.Errorf("calendar range must not exceed %d days", leaveCalendarMaxDays)
+	}
+
+	// แผนกที่ดูได้: admin ทุกแผนก, ผู้จัดการแผนกที่ดูแล, พนักงานแผนกของตนเอง
+	departmentID := strings.TrimSpace(req.DepartmentID)
+	if claims.Role != "admin" {
+		me, err := s.getLeaveUser(ctx, claims.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if departmentID == "" {
+			departmentID = me.DepartmentID
+		}
+		if departmentID != me.DepartmentID {
+			isManager, err := s.isLeaveManager(ctx, departmentID, claims.UserID)
+			if err != nil {
+				return nil, err
+			}
+			if !isManager {
+				return nil, ports.ErrLeaveForbidden
+			}
+		}
+		if departmentID == "" {
+			return nil, ports.ErrLeaveForbidden
+		}
+	}
+
+	filter := bson.M{
+		"status":     bson.M{"$in": leaveActiveStatuses},
+		"start_date": bson.M{"$lte": end},
+		"end_date":   bson.M{"$gte": start},
+		"deleted_at": nil,
+	}
+	holidayFilter := bson.M{
+		"date":          bson.M{"$gte": start, "$lte": end},
+		"department_id": bson.M{"$in": []interface{}{nil, ""}},
+		"deleted_at":    nil,
+	}
+	if departmentID != "" {
+		filter["department_id"] = departmentID
+		holidayFilter["department_id"] = bson.M{"$in": []interface{}{nil, "", departmentID}}
+	}
+	requests, err := s.leaveRepo.GetAllLeaveRequestsByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	holidays, err := s.capacityRepo.GetAllHolidaysByFilter(ctx, holidayFilter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	holidayNames := make(map[string]string, len(holidays))
+	for _, h := range holidays {
+		holidayNames[helpers.DayKey(h.Date)] = h.Name
+	}
+
+	names := newLeaveNameCache()
+	out := &dto.LeaveCalendarDTO{StartDate: helpers.DayKey(start), EndDate: helpers.DayKey(end), Days: []dto.LeaveCalendarDayDTO{}}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		key := helpers.DayKey(day)
+		name, isHoliday := holidayNames[key]
+		row := dto.LeaveCalendarDayDTO{Date: key, IsHoliday: isHoliday, HolidayName: name, Leaves: []dto.LeaveCalendarEntryDTO{}}
+		for _, r := range requests {
+			if day.Before(r.StartDate) || day.After(r.EndDate) {
+				continue
+			}
+			row.Leaves = append(row.Leaves, dto.LeaveCalendarEntryDTO{
+				RequestID:      r.RequestID,
+				UserID:         r.UserID,
+				UserName:       s.leaveUserName(ctx, names, r.UserID),
+				DepartmentID:   r.DepartmentID,
+				DepartmentName: s.leaveDepartmentName(ctx, names, r.DepartmentID),
+				LeaveType:      r.LeaveType,
+				LeaveTypeName:  leaveTypeNames[r.LeaveType],
+				HalfDay:        r.HalfDay,
+				Status:         r.Status,
+			})
+			if r.Status != models.LeavePending {
+				row.OnLeave += leaveDayFraction(r)
+			}
+		}
+		out.Days = append(out.Days, row)
+	}
+	return out, nil
+}
+
+// ---------- helpers ----------
+
+func (s *leaveService) getLeaveUser(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.DeletedAt != nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	return user, nil
+}
+
+func (s *leaveService) getLeaveRequest(ctx context.Context, requestID string) (*models.LeaveRequest, error) {
+	request, err := s.leaveRepo.GetOneLeaveRequestByFilter(ctx, bson.M{"request_id": requestID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if request == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	return request, nil
+}
+
+func (s *leaveService) isLeaveManager(ctx context.Context, departmentID, userID string) (bool, error) {
+	if departmentID == "" {
+		return false, nil
+	}
+	dept, err := s.departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{"department_id": departmentID, "deleted_at": nil}, bson.M{"_id": 0, "manager_id": 1})
+	if err != nil {
+		return false, err
+	}
+	return dept != nil && dept.ManagerID == userID, nil
+}
+
+func (s *leaveService) managedLeaveDepartments(ctx context.Context, userID string) ([]string, error) {
+	departments, err := s.departmentRepo.GetAllDepartmentByFilter(ctx, bson.M{"manager_id": userID, "deleted_at": nil}, bson.M{"department_id": 1})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(departments))
+	for _, d := range departments {
+		out = append(out, d.DepartmentID)
+	}
+	return out, nil
+}
+
+// canApproveLeave admin, ผู้อนุมัติที่กำหนดตอนยื่น หรือผู้จัดการแผนกปัจจุบัน (ไม่อนุมัติใบลาของตนเอง)
+func (s *leaveService) canApproveLeave(ctx context.Context, request *models.LeaveRequest, claims *dto.JWTClaims) (bool, error) {
+	if claims.Role == "admin" {
+		return true, nil
+	}
+	if request.UserID == claims.UserID {
+		return false, nil
+	}
+	if request.ApproverID != "" && request.ApproverID == claims.UserID {
+		return true, nil
+	}
+	return s.isLeaveManager(ctx, request.DepartmentID, claims.UserID)
+}
+
+func (s *leaveService) loadLeavePolicies(ctx context.Context) (map[string]*models.LeavePolicy, error) {
+	policies, err := s.leaveRepo.GetAllLeavePoliciesByFilter(ctx, bson.M{"deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	return leavePolicyMap(policies), nil
+}
+
+// countLeaveDays จำนวนวันลาที่หักสิทธิ์ ไม่นับวันหยุดประจำสัปดาห์ของแผนกและวันหยุดบริษัท/แผนก (ยกเว้นนับวันปฏิทิน)
+func (s *leaveService) countLeaveDays(ctx context.Context, departmentID string, policy models.LeavePolicy, start, end time.Time, halfDay string) (float64, error) {
+	if policy.CalendarDays {
+		return float64(int(end.Sub(start).Hours()/24) + 1), nil
+	}
+
+	workDays := defaultWorkDays
+	if departmentID != "" {
+		setting, err := s.capacityRepo.GetOneDepartmentCapacityByFilter(ctx, bson.M{"department_id": departmentID, "deleted_at": nil}, bson.M{"_id": 0, "work_days": 1})
+		if err != nil {
+			return 0, err
+		}
+		if setting != nil && len(setting.WorkDays) > 0 {
+			workDays = setting.WorkDays
+		}
+	}
+	holidays, err := s.capacityRepo.GetAllHolidaysByFilter(ctx, bson.M{
+		"date":          bson.M{"$gte": start, "$lte": end},
+		"department_id": bson.M{"$in": []interface{}{nil, "", departmentID}},
+		"deleted_at":    nil,
+	}, bson.M{"_id": 0, "date": 1})
+	if err != nil {
+		return 0, err
+	}
+	off := make(map[string]bool, len(holidays))
+	for _, h := range holidays {
+		off[helpers.DayKey(h.Date)] = true
+	}
+
+	days := 0.0
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if !off[helpers.DayKey(day)] && isLeaveWorkDay(workDays, day) {
+			days++
+		}
+	}
+	if days == 0 {
+		return 0, errors.New("no working days in the selected range")
+	}
+	if halfDay != "" {
+		days = 0.5
+	}
+	return days, nil
+}
+
+// checkLeaveOverlap ห้ามลาซ้อนวันเดิม ยกเว้นลาครึ่งเช้ากับครึ่งบ่ายของวันเดียวกัน
+func (s *leaveService) checkLeaveOverlap(ctx context.Context, userID string, start, end time.Time, halfDay string) error {
+	filter := bson.M{
+		"user_id":    userID,
+		"status":     bson.M{"$in": leaveActiveStatuses},
+		"start_date": bson.M{"$lte": end},
+		"end_date":   bson.M{"$gte": start},
+		"deleted_at": nil,
+	}
+	existing, err := s.leaveRepo.GetAllLeaveRequestsByFilter(ctx, filter, bson.M{"_id": 0, "start_date": 1, "end_date": 1, "half_day": 1})
+	if err != nil {
+		return err
+	}
+	for _, r := range existing {
+		if halfDay != "" && r.HalfDay != "" && r.HalfDay != halfDay {
+			continue
+		}
+		return fmt.Errorf("%w: overlaps with another leave request on %s", ports.ErrLeaveConflict, leavePeriodText(r))
+	}
+	return nil
+}
+
+func (s *leaveService) mailLeaveUser(ctx context.Context, userID, subject, body string) {
+	if s.config.Email.Host == "" || userID == "" {
+		return
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil || strings.TrimSpace(user.Email) == "" {
+		return
+	}
+	emailCfg := util.EmailConfig{
+		Host:     s.config.Email.Host,
+		Port:     s.config.Email.Port,
+		Username: s.config.Email.Username,
+		Password: s.config.Email.Password,
+		From:     s.config.Email.From,
+	}
+	if err := util.SendMail(emailCfg, user.Email, subject, fmt.Sprintf("เรียนคุณ %s\n\n%s", user.FirstNameTH, body)); err != nil {
+		log.Println("Error sending leave email:", err)
+	}
+}
+
+type leaveNameCache struct {
+	users       map[string]string
+	departments map[string]string
+}
+
+func newLeaveNameCache() *leaveNameCache {
+	return &leaveNameCache{users: map[string]string{}, departments: map[string]string{}}
+}
+
+func (s *leaveService) leaveUserName(ctx context.Context, cache *leaveNameCache, userID string) string {
+	if userID == "" {
+		return ""
+	}
+	if name, ok := cache.users[userID]; ok {
+		return name
+	}
+	name := "ไม่พบผู้ใช้"
+	if user, _ := s.userRepo.GetByID(ctx, userID); user != nil {
+		name = strings.TrimSpace(user.FirstNameTH + " " + user.LastNameTH)
+	}
+	cache.users[userID] = name
+	return name
+}
+
+func (s *leaveService) leaveDepartmentName(ctx context.Context, cache *leaveNameCache, departmentID string) string {
+	if departmentID == "" {
+		return ""
+	}
+	if name, ok := cache.departments[departmentID]; ok {
+		return name
+	}
+	name := "ไม่พบแผนก"
+	if dept, _ := s.departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{"department_id": departmentID, "deleted_at": nil}, bson.M{"_id": 0, "department_name": 1}); dept != nil {
+		name = dept.DepartmentName
+	}
+	cache.departments[departmentID] = name
+	return name
+}
+
+func (s *leaveService) positionName(ctx context.Context, cache map[string]string, positionID string) string {
+	if positionID == "" {
+		return ""
+	}
+	if name, ok := cache[positionID]; ok {
+		return name
+	}
+	name := "ไม่พบตำแหน่ง"
+	if position, _ := s.positionRepo.GetOnePositionByFilter(ctx, bson.M{"position_id": positionID}, bson.M{"_id": 0, "position_name": 1}); position != nil {
+		name = position.PositionName
+	}
+	cache[positionID] = name
+	return name
+}
+
+func (s *leaveService) toLeaveRequestDTO(ctx context.Context, r *models.LeaveRequest, names *leaveNameCache) *dto.LeaveRequestDTO {
+	return &dto.LeaveRequestDTO{
+		CreatedAt:       r.CreatedAt,
+		UpdatedAt:       r.UpdatedAt,
+		StartDate:       r.StartDate,
+		EndDate:         r.EndDate,
+		DecidedAt:       r.DecidedAt,
+		RecordedAt:      r.RecordedAt,
+		CancelledAt:     r.CancelledAt,
+		RequestID:       r.RequestID,
+		UserID:          r.UserID,
+		UserName:        s.leaveUserName(ctx, names, r.UserID),
+		DepartmentID:    r.DepartmentID,
+		DepartmentName:  s.leaveDepartmentName(ctx, names, r.DepartmentID),
+		LeaveType:       r.LeaveType,
+		LeaveTypeName:   leaveTypeNames[r.LeaveType],
+		HalfDay:         r.HalfDay,
+		Days:            r.Days,
+		Year:            r.Year,
+		Reason:          r.Reason,
+		Attachment:      r.Attachment,
+		Status:          r.Status,
+		ApproverID:      r.ApproverID,
+		ApproverName:    s.leaveUserName(ctx, names, r.ApproverID),
+		CreatedBy:       r.CreatedBy,
+		DecidedBy:       r.DecidedBy,
+		DecisionComment: r.DecisionComment,
+		RecordedBy:      r.RecordedBy,
+		HRNote:          r.HRNote,
+		CancelledBy:     r.CancelledBy,
+		CancelReason:    r.CancelReason,
+	}
+}
+
+// effectiveLeavePolicy นโยบายเฉพาะตำแหน่ง -> นโยบายทุกตำแหน่ง -> ค่าเริ่มต้นตามกฎหมาย
+// leavePolicyMap จัดนโยบายตาม "ประเภท|ตำแหน่ง" สำหรับ effectiveLeavePolicy
+func leavePolicyMap(policies []*models.LeavePolicy) map[string]*models.LeavePolicy {
+	out := make(map[string]*models.LeavePolicy, len(policies))
+	for _, p := range policies {
+		out[p.LeaveType+"|"+p.PositionID] = p
+	}
+	return out
+}
+
+func effectiveLeavePolicy(policies map[string]*models.LeavePolicy, leaveType, positionID string) models.LeavePolicy {
+	if positionID != "" {
+		if p, ok := policies[leaveType+"|"+positionID]; ok {
+			return *p
+		}
+	}
+	if p, ok := policies[leaveType+"|"]; ok {
+		return *p
+	}
+	return defaultLeavePolicy(leaveType)
+}
+
+// defaultLeavePolicy สิทธิ์ขั้นต่ำตามพระราชบัญญัติคุ้มครองแรงงาน (ลาอุปสมบทเป็นค่าที่ใช้ทั่วไป)
+func defaultLeavePolicy(leaveType string) models.LeavePolicy {
+	p := models.LeavePolicy{LeaveType: leaveType}
+	switch leaveType {
+	case models.LeaveSick:
+		p.Tiers = []models.LeaveTier{{MinServiceMonths: 0, Days: 30}}
+		p.PaidDays, p.AllowExceed, p.AllowHalfDay = 30, true, true
+	case models.LeavePersonal:
+		p.Tiers = []models.LeaveTier{{MinServiceMonths: 0, Days: 3}}
+		p.PaidDays, p.AllowHalfDay = 3, true
+	case models.LeaveAnnual:
+		p.Tiers = []models.LeaveTier{{MinServiceMonths: 12, Days: 6}}
+		p.PaidDays, p.AllowHalfDay, p.MinNoticeDays = 6, true, 3
+	case models.LeaveMaternity:
+		p.Tiers = []models.LeaveTier{{MinServiceMonths: 0, Days: 98}}
+		p.PaidDays, p.CalendarDays = 45, true
+	case models.LeaveOrdination:
+		p.Tiers = []models.LeaveTier{{MinServiceMonths: 12, Days: 15}}
+		p.PaidDays, p.CalendarDays, p.MinNoticeDays = 15, true, 30
+	case models.LeaveMilitary:
+		p.Tiers = []models.LeaveTier{{MinServiceMonths: 0, Days: 60}}
+		p.PaidDays = 60
+	}
+	return p
+}
+
+func toLeavePolicyDTO(p *models.LeavePolicy, positionName string, isDefault bool) dto.LeavePolicyDTO {
+	tiers := make([]dto.LeaveTierDTO, 0, len(p.Tiers))
+	for _, t := range p.Tiers {
+		tiers = append(tiers, dto.LeaveTierDTO{MinServiceMonths: t.MinServiceMonths, Days: t.Days})
+	}
+	out := dto.LeavePolicyDTO{
+		PolicyID:      p.PolicyID,
+		LeaveType:     p.LeaveType,
+		LeaveTypeName: leaveTypeNames[p.LeaveType],
+		PositionID:    p.PositionID,
+		PositionName:  positionName,
+		Tiers:         tiers,
+		PaidDays:      p.PaidDays,
+		MaxCarryOver:  p.MaxCarryOver,
+		AllowExceed:   p.AllowExceed,
+		AllowHalfDay:  p.AllowHalfDay,
+		CalendarDays:  p.CalendarDays,
+		MinNoticeDays: p.MinNoticeDays,
+		IsDefault:     isDefault,
+	}
+	if !isDefault {
+		updatedAt := p.UpdatedAt
+		out.UpdatedAt = &updatedAt
+	}
+	return out
+}
+
+// leaveCarriedOver ยอดยกมาถึงต้นปี year คิดต่อเนื่องตั้งแต่ startYear จากวันที่ใช้จริงของแต่ละปี
+func leaveCarriedOver(policy models.LeavePolicy, hireDate time.Time, used map[int]float64, startYear, year int) float64 {
+	carry := 0.0
+	for y := startYear; y < year; y++ {
+		entitled := helpers.LeaveEntitlement(policy.Tiers, helpers.ServiceMonths(hireDate, leaveAsOf(y)))
+		carry = helpers.LeaveCarryOver(entitled+carry-used[y], policy.MaxCarryOver)
+	}
+	return carry
+}
+
+// leaveDayFraction สัดส่วนคนที่ลาต่อวัน (ครึ่งวัน = 0.5)
+func leaveDayFraction(r *models.LeaveRequest) float64 {
+	if r.HalfDay != "" {
+		return 0.5
+	}
+	return 1
+}
+
+func leavePeriodText(r *models.LeaveRequest) string {
+	text := r.StartDate.Format("02/01/2006")
+	if !r.EndDate.Equal(r.StartDate) {
+		text += " - " + r.EndDate.Format("02/01/2006")
+	}
+	switch r.HalfDay {
+	case models.LeaveHalfMorning:
+		text += " (ครึ่งเช้า)"
+	case models.LeaveHalfAfternoon:
+		text += " (ครึ่งบ่าย)"
+	}
+	return text
+}
+
+func isLeaveWorkDay(workDays []int, day time.Time) bool {
+	for _, wd := range workDays {
+		if int(day.Weekday()) == wd {
+			return true
+		}
+	}
+	return false
+}
+
+func isHalfDayStep(v float64) bool {
+	return math.Mod(v*2, 1) == 0
+}
+
+// leaveAsOf วันที่ใช้คิดอายุงานของปี: ปีปัจจุบันใช้วันนี้ ปีอื่นใช้วันสิ้นปี
+func leaveAsOf(year int) time.Time {
+	today := helpers.Today()
+	if year == today.Year() {
+		return today
+	}
+	return time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC)
+}
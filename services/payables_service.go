@@ -95,6 +95,7 @@ func (s *payablesService) CreatePayable(ctx context.Context, payable dto.CreateP
 		Phone:     strings.TrimSpace(payable.Phone),
 		Address:   strings.TrimSpace(payable.Address),
 		Note:      strings.TrimSpace(payable.Note),
+		JobID:     strings.TrimSpace(payable.JobID),
 		CreatedBy: claims.UserID,
 		CreatedAt: now,
 		UpdatedAt: now,
@@ -333,6 +334,7 @@ func (s *payablesService) GetPayableByID(ctx context.Context, payableID string,
 		Address:      m.Address,
 		PaymentRef:   m.PaymentRef,
 		Note:         m.Note,
+		JobID:        m.JobID,
 		Transactions: PaymentTransactions,
 	}
 	return dtoObj, nil
@@ -433,6 +435,10 @@ func (s *payablesService) UpdatePayableByID(ctx context.Context, payableID strin
 		existing.PurchaseNo = update.PurchaseNo
 	}
 
+	if strings.TrimSpace(update.JobID) != "" {
+		existing.JobID = strings.TrimSpace(update.JobID)
+	}
+
 	existing.UpdatedAt = time.Now()
 
 	if _, err := s.payablesRepo.UpdatePayableByID(ctx, payableID, *existing); err != nil {
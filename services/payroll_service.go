@@ -0,0 +1,1224 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/config"
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/pkg/util"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	payrollDefaultHoursPerDay = 8.0
+	payrollDefaultOTMultiple  = 1.5
+	payrollDaysPerMonth       = 30.0 // หารเงินเดือนเป็นค่าจ้างต่อวัน
+	payrollDefaultListLimit   = 10
+	payrollReferencePrefix    = "PAYROLL-"
+)
+
+type payrollService struct {
+	config           config.Config
+	payrollRepo      ports.PayrollRepository
+	userRepo         ports.UserRepository
+	bankAccountsRepo ports.BankAccountsRepository
+	expenseRepo      ports.ExpenseRepository
+	attendanceSvc    ports.AttendanceService
+}
+
+func NewPayrollService(cfg config.Config, payrollRepo ports.PayrollRepository, userRepo ports.UserRepository, bankAccountsRepo ports.BankAccountsRepository, expenseRepo ports.ExpenseRepository, attendanceSvc ports.AttendanceService) ports.PayrollService {
+	return &payrollService{config: cfg, payrollRepo: payrollRepo, userRepo: userRepo, bankAccountsRepo: bankAccountsRepo, expenseRepo: expenseRepo, attendanceSvc: attendanceSvc}
+}
+
+// ---------- โครงสร้างเงินเดือน ----------
+
+func (s *payrollService) ListSalaryStructures(ctx context.Context, claims *dto.JWTClaims) ([]dto.SalaryStructureDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrPayrollForbidden
+	}
+	structures, err := s.payrollRepo.GetAllSalaryStructuresByFilter(ctx, bson.M{"deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	userIDs := make([]string, 0, len(structures))
+	for _, st := range structures {
+		userIDs = append(userIDs, st.UserID)
+	}
+	users, err := s.userRepo.GetUserByFilter(ctx, bson.M{"user_id": bson.M{"$in": userIDs}}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*models.User, len(users))
+	for _, u := range users {
+		byID[u.UserID] = u
+	}
+
+	out := make([]dto.SalaryStructureDTO, 0, len(structures))
+	for _, st := range structures {
+		out = append(out, toSalaryStructureDTO(st, byID[st.UserID]))
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].EmployeeCode < out[j].EmployeeCode })
+	return out, nil
+}
+
+func (s *payrollService) GetSalaryStructure(ctx context.Context, userID string, claims *dto.JWTClaims) (*dto.SalaryStructureDTO, error) {
+	userID = strings.TrimSpace(userID)
+	if claims.Role != "admin" && userID != claims.UserID {
+		return nil, ports.ErrPayrollForbidden
+	}
+	st, err := s.payrollRepo.GetOneSalaryStructureByFilter(ctx, bson.M{"user_id": userID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if st == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := toSalaryStructureDTO(st, user)
+	return &out, nil
+}
+
+func (s *payrollService) UpsertSalaryStructure(ctx context.Context, req dto.UpsertSalaryStructureDTO, claims *dto.JWTClaims) (*dto.SalaryStructureDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrPayrollForbidden
+	}
+	userID := strings.TrimSpace(req.UserID)
+	if userID == "" {
+		return nil, errors.New("user_id is required")
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.DeletedAt != nil {
+		return nil, errors.New("user not found")
+	}
+
+	basis := strings.TrimSpace(req.PayBasis)
+	if basis == "" {
+		basis = models.PayBasisMonthly
+	}
+	if !helpers.InSet(basis, models.PayBasisMonthly, models.PayBasisDaily) {
+		return nil, fmt.Errorf("invalid pay_basis: %s (allow: monthly|daily)", req.PayBasis)
+	}
+	if req.BaseSalary < 0 || req.DailyRate < 0 || req.TaxDeductions < 0 {
+		return nil, errors.New("amounts must not be negative")
+	}
+	if basis == models.PayBasisMonthly && req.BaseSalary <= 0 {
+		return nil, errors.New("base_salary is required for monthly pay basis")
+	}
+	if basis == models.PayBasisDaily && req.DailyRate <= 0 {
+		return nil, errors.New("daily_rate is required for daily pay basis")
+	}
+
+	hours := req.HoursPerDay
+	if hours == 0 {
+		hours = payrollDefaultHoursPerDay
+	}
+	if hours < 1 || hours > 24 {
+		return nil, errors.New("hours_per_day must be between 1 and 24")
+	}
+	otMul := req.OTMultiplier
+	if otMul == 0 {
+		otMul = payrollDefaultOTMultiple
+	}
+	holidayMul := req.HolidayMultiplier
+	if holidayMul == 0 {
+		// รายเดือนได้เงินเดือนวันหยุดอยู่แล้วจึงจ่ายเพิ่ม 1 เท่า รายวันไม่ได้ค่าจ้างวันหยุดจึงจ่าย 2 เท่า
+		holidayMul = 1
+		if basis == models.PayBasisDaily {
+			holidayMul = 2
+		}
+	}
+	if otMul < 1 || holidayMul < 1 {
+		return nil, errors.New("ot_multiplier and holiday_multiplier must be at least 1")
+	}
+	allowances, err := toPayItems("allowances", req.Allowances)
+	if err != nil {
+		return nil, err
+	}
+	deductions, err := toPayItems("deductions", req.Deductions)
+	if err != nil {
+		return nil, err
+	}
+	ssf := true
+	if req.SSFEnabled != nil {
+		ssf = *req.SSFEnabled
+	}
+
+	now := time.Now()
+	st := models.SalaryStructure{
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		StructureID:       uuid.NewString(),
+		UserID:            userID,
+		PayBasis:          basis,
+		BaseSalary:        util.Round2(req.BaseSalary),
+		DailyRate:         util.Round2(req.DailyRate),
+		HoursPerDay:       hours,
+		OTMultiplier:      otMul,
+		HolidayMultiplier: holidayMul,
+		Allowances:        allowances,
+		Deductions:        deductions,
+		SSFEnabled:        ssf,
+		TaxDeductions:     util.Round2(req.TaxDeductions),
+		UpdatedBy:         claims.UserID,
+	}
+	if basis == models.PayBasisMonthly {
+		st.DailyRate = 0
+	} else {
+		st.BaseSalary = 0
+	}
+	saved, err := s.payrollRepo.UpsertSalaryStructure(ctx, st)
+	if err != nil {
+		return nil, err
+	}
+	out := toSalaryStructureDTO(saved, user)
+	return &out, nil
+}
+
+// ---------- รอบเงินเดือน ----------
+
+func (s *payrollService) CreatePayrollRun(ctx context.Context, req dto.CreatePayrollRunDTO, claims *dto.JWTClaims) (*dto.PayrollRunDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrPayrollForbidden
+	}
+	month, err := time.Parse("2006-01", strings.TrimSpace(req.Month))
+	if err != nil {
+		return nil, fmt.Errorf("invalid month: %s (use YYYY-MM)", req.Month)
+	}
+	monthKey := month.Format("2006-01")
+	if month.After(helpers.Today()) {
+		return nil, errors.New("cannot run payroll for a future month")
+	}
+
+	existing, err := s.payrollRepo.GetOnePayrollRunByFilter(ctx, bson.M{"month": monthKey, "status": bson.M{"$ne": models.PayrollCancelled}, "deleted_at": nil}, bson.M{"_id": 0, "run_id": 1, "status": 1})
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w: payroll for %s already exists (%s)", ports.ErrPayrollConflict, monthKey, existing.Status)
+	}
+
+	payDate := month.AddDate(0, 1, -1)
+	if strings.TrimSpace(req.PayDate) != "" {
+		d, err := helpers.ParseDate("pay_date", req.PayDate, time.UTC)
+		if err != nil {
+			return nil, err
+		}
+		payDate = d
+	}
+	bankID := strings.TrimSpace(req.BankID)
+	if bankID == "" {
+		bankID = config.DefaultBankAccountIDs.CompanyBank
+	}
+	bank, err := s.bankAccountsRepo.GetOneBankAccountByFilter(ctx, bson.M{"bank_id": bankID, "deleted_at": nil}, bson.M{"_id": 0, "bank_id": 1})
+	if err != nil {
+		return nil, err
+	}
+	if bank == nil {
+		return nil, errors.New("bank account not found")
+	}
+
+	now := time.Now()
+	run := models.PayrollRun{
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		PayDate:      payDate,
+		RunID:        uuid.NewString(),
+		Month:        monthKey,
+		Status:       models.PayrollDraft,
+		BankID:       bankID,
+		Note:         strings.TrimSpace(req.Note),
+		CreatedBy:    claims.UserID,
+		CalculatedAt: now,
+	}
+	slips, warnings, err := s.calculatePayslips(ctx, &run, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.payrollRepo.CreatePayrollRun(ctx, run); err != nil {
+		return nil, err
+	}
+	if err := s.payrollRepo.ReplacePayslips(ctx, run.RunID, slips); err != nil {
+		return nil, err
+	}
+	out := toPayrollRunDTO(&run, slips, warnings)
+	return &out, nil
+}
+
+func (s *payrollService) ListPayrollRuns(ctx context.Context, req dto.RequestListPayrollRuns, claims *dto.JWTClaims) (dto.Pagination, error) {
+	if claims.Role != "admin" {
+		return dto.Pagination{}, ports.ErrPayrollForbidden
+	}
+	page, size := req.Page, req.Limit
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 100 {
+		size = payrollDefaultListLimit
+	}
+	skip := int64((page - 1) * size)
+	limit := int64(size)
+
+	filter := bson.M{"deleted_at": nil}
+	if req.Year > 0 {
+		filter["month"] = bson.M{"$regex": fmt.Sprintf("^%04d-", req.Year)}
+	}
+	if v := strings.TrimSpace(req.Status); v != "" {
+		filter["status"] = v
+	}
+
+	sortBy := bson.D{
+		{Key: "month", Value: -1},
+		{Key: "created_at", Value: -1},
+	}
+	items, total, err := s.payrollRepo.GetListPayrollRunsByFilter(ctx, filter, bson.M{}, sortBy, skip, limit)
+	if err != nil {
+		return dto.Pagination{}, fmt.Errorf("list payroll runs: %w", err)
+	}
+
+	list := make([]interface{}, 0, len(items))
+	for i := range items {
+		list = append(list, toPayrollRunDTO(&items[i], nil, nil))
+	}
+
+	totalPages := 0
+	if total > 0 && size > 0 {
+		totalPages = int((total + int64(size) - 1) / int64(size))
+	}
+
+	return dto.Pagination{
+		Page:       page,
+		Size:       size,
+		TotalCount: int(total),
+		TotalPages: totalPages,
+		List:       list,
+	}, nil
+}
+
+func (s *payrollService) GetPayrollRun(ctx context.Context, runID string, claims *dto.JWTClaims) (*dto.PayrollRunDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrPayrollForbidden
+	}
+	run, err := s.getPayrollRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	slips, err := s.runPayslips(ctx, run.RunID)
+	if err != nil {
+		return nil, err
+	}
+	out := toPayrollRunDTO(run, slips, nil)
+	return &out, nil
+}
+
+func (s *payrollService) RecalculatePayrollRun(ctx context.Context, runID string, claims *dto.JWTClaims) (*dto.PayrollRunDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrPayrollForbidden
+	}
+	run, err := s.getPayrollRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.runPayslips(ctx, run.RunID)
+	if err != nil {
+		return nil, err
+	}
+	adjustments := make(map[string][]models.PayItem, len(current))
+	for _, p := range current {
+		if len(p.Adjustments) > 0 {
+			adjustments[p.UserID] = p.Adjustments
+		}
+	}
+	return s.recalculate(ctx, run, adjustments)
+}
+
+func (s *payrollService) AddPayrollAdjustment(ctx context.Context, runID string, req dto.PayrollAdjustmentDTO, claims *dto.JWTClaims) (*dto.PayrollRunDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrPayrollForbidden
+	}
+	userID := strings.TrimSpace(req.UserID)
+	name := strings.TrimSpace(req.Name)
+	if userID == "" || name == "" {
+		return nil, errors.New("user_id and name are required")
+	}
+	if req.Amount == 0 {
+		return nil, errors.New("amount must not be zero")
+	}
+	run, err := s.getPayrollRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.runPayslips(ctx, run.RunID)
+	if err != nil {
+		return nil, err
+	}
+	adjustments := make(map[string][]models.PayItem, len(current))
+	found := false
+	for _, p := range current {
+		adjustments[p.UserID] = p.Adjustments
+		if p.UserID == userID {
+			found = true
+		}
+	}
+	if !found {
+		return nil, errors.New("employee is not in this payroll run")
+	}
+	adjustments[userID] = append(adjustments[userID], models.PayItem{Name: name, Amount: util.Round2(req.Amount), Taxable: req.Taxable})
+	return s.recalculate(ctx, run, adjustments)
+}
+
+func (s *payrollService) ApprovePayrollRun(ctx context.Context, runID string, req dto.PayrollDecisionDTO, claims *dto.JWTClaims) (*dto.PayrollRunDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrPayrollForbidden
+	}
+	run, err := s.getPayrollRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if run.EmployeeCount == 0 {
+		return nil, errors.New("payroll run has no payslips")
+	}
+	now := time.Now()
+	set := bson.M{"status": models.PayrollApproved, "approved_by": claims.UserID, "approved_at": now, "updated_at": now}
+	if note := strings.TrimSpace(req.Note); note != "" {
+		set["note"] = note
+	}
+	updated, err := s.payrollRepo.TransitionPayrollRun(ctx, run.RunID, []string{models.PayrollDraft}, set)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("%w: payroll run is %s", ports.ErrPayrollConflict, run.Status)
+	}
+	return s.GetPayrollRun(ctx, updated.RunID, claims)
+}
+
+func (s *payrollService) PayPayrollRun(ctx context.Context, runID string, req dto.PayrollPaymentDTO, claims *dto.JWTClaims) (*dto.PayrollRunDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrPayrollForbidden
+	}
+	run, err := s.getPayrollRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	method := strings.TrimSpace(req.PaymentMethod)
+	if method == "" {
+		method = "transfer"
+	}
+	reference := strings.TrimSpace(req.ReferenceNo)
+	if reference == "" {
+		reference = payrollReferencePrefix + run.Month
+	}
+
+	// เปลี่ยนสถานะก่อนเพื่อกันบันทึกรายจ่ายซ้ำ ถ้าบันทึกรายจ่ายไม่สำเร็จจะคืนสถานะอนุมัติ
+	now := time.Now()
+	expenseID := uuid.NewString()
+	updated, err := s.payrollRepo.TransitionPayrollRun(ctx, run.RunID, []string{models.PayrollApproved}, bson.M{
+		"status":     models.PayrollPaid,
+		"paid_by":    claims.UserID,
+		"paid_at":    now,
+		"expense_id": expenseID,
+		"updated_at": now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("%w: payroll run must be approved before payment (current: %s)", ports.ErrPayrollConflict, run.Status)
+	}
+
+	note := fmt.Sprintf("เงินเดือน %s พนักงาน %d คน: เงินได้รวม %.2f, ประกันสังคมส่วนนายจ้าง %.2f, ประกันสังคมส่วนลูกจ้าง %.2f, ภาษีหัก ณ ที่จ่าย %.2f, โอนสุทธิ %.2f",
+		run.Month, run.EmployeeCount, run.TotalGross, run.TotalSSFEmployer, run.TotalSSFEmployee, run.TotalTax, run.TotalNet)
+	if v := strings.TrimSpace(req.Note); v != "" {
+		note += "\n" + v
+	}
+	expense := models.Expense{
+		ExpenseID:             expenseID,
+		TransactionCategoryID: config.DefaultTransactionCategoryIDs.CompanyExpense,
+		BankID:                run.BankID,
+		Description:           "เงินเดือนพนักงาน " + run.Month,
+		Amount:                util.Round2(run.TotalGross + run.TotalSSFEmployer),
+		Currency:              "THB",
+		TxnDate:               run.PayDate,
+		PaymentMethod:         method,
+		ReferenceNo:           reference,
+		Note:                  &note,
+		CreatedBy:             claims.UserID,
+		CreatedAt:             now,
+		UpdatedAt:             now,
+	}
+	if err := s.expenseRepo.CreateExpense(ctx, expense); err != nil {
+		if _, rbErr := s.payrollRepo.TransitionPayrollRun(ctx, run.RunID, []string{models.PayrollPaid}, bson.M{"status": models.PayrollApproved, "expense_id": "", "updated_at": time.Now()}); rbErr != nil {
+			log.Println("Error reverting payroll run status:", rbErr)
+		}
+		return nil, fmt.Errorf("create expense: %w", err)
+	}
+
+	slips, err := s.runPayslips(ctx, run.RunID)
+	if err != nil {
+		return nil, err
+	}
+	s.mailPayslips(slips)
+
+	out := toPayrollRunDTO(updated, slips, nil)
+	return &out, nil
+}
+
+func (s *payrollService) CancelPayrollRun(ctx context.Context, runID string, req dto.PayrollDecisionDTO, claims *dto.JWTClaims) (*dto.PayrollRunDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrPayrollForbidden
+	}
+	reason := strings.TrimSpace(req.Note)
+	if reason == "" {
+		return nil, errors.New("note is required when cancelling")
+	}
+	run, err := s.getPayrollRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	updated, err := s.payrollRepo.TransitionPayrollRun(ctx, run.RunID, []string{models.PayrollDraft, models.PayrollApproved}, bson.M{
+		"status":        models.PayrollCancelled,
+		"cancelled_by":  claims.UserID,
+		"cancelled_at":  now,
+		"cancel_reason": reason,
+		"updated_at":    now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("%w: payroll run is %s", ports.ErrPayrollConflict, run.Status)
+	}
+	out := toPayrollRunDTO(updated, nil, nil)
+	return &out, nil
+}
+
+func (s *payrollService) BuildPayrollBankFile(ctx context.Context, runID string, claims *dto.JWTClaims) ([]byte, string, error) {
+	if claims.Role != "admin" {
+		return nil, "", ports.ErrPayrollForbidden
+	}
+	run, err := s.getPayrollRun(ctx, runID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !helpers.InSet(run.Status, models.PayrollApproved, models.PayrollPaid) {
+		return nil, "", fmt.Errorf("%w: payroll run must be approved (current: %s)", ports.ErrPayrollConflict, run.Status)
+	}
+	slips, err := s.runPayslips(ctx, run.RunID)
+	if err != nil {
+		return nil, "", err
+	}
+	var missing []string
+	for _, p := range slips {
+		if p.Net > 0 && strings.TrimSpace(p.AccountNo) == "" {
+			missing = append(missing, payslipLabel(&p))
+		}
+	}
+	if len(missing) > 0 {
+		return nil, "", fmt.Errorf("%w: missing bank account for %s", ports.ErrPayrollConflict, strings.Join(missing, ", "))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff") // BOM ให้ Excel อ่านภาษาไทยได้
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"ลำดับ", "รหัสพนักงาน", "ชื่อบัญชี", "ธนาคาร", "เลขที่บัญชี", "จำนวนเงิน", "อ้างอิง"})
+	seq := 0
+	for _, p := range slips {
+		if p.Net <= 0 {
+			continue
+		}
+		seq++
+		accountName := p.AccountName
+		if accountName == "" {
+			accountName = p.NameTH
+		}
+		_ = w.Write([]string{
+			fmt.Sprint(seq),
+			p.EmployeeCode,
+			accountName,
+			p.BankName,
+			strings.NewReplacer("-", "", " ", "").Replace(p.AccountNo),
+			fmt.Sprintf("%.2f", p.Net),
+			payrollReferencePrefix + run.Month,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), fmt.Sprintf("payroll-%s.csv", run.Month), nil
+}
+
+// ---------- สลิปเงินเดือน ----------
+
+func (s *payrollService) ListMyPayslips(ctx context.Context, req dto.RequestMyPayslips, claims *dto.JWTClaims) ([]dto.PayslipDTO, error) {
+	year := req.Year
+	if year == 0 {
+		year = helpers.Today().Year()
+	}
+	runs, err := s.payrollRepo.GetAllPayrollRunsByFilter(ctx, bson.M{
+		"month":      bson.M{"$regex": fmt.Sprintf("^%04d-", year)},
+		"status":     bson.M{"$in": []string{models.PayrollApproved, models.PayrollPaid}},
+		"deleted_at": nil,
+	}, bson.M{"_id": 0, "run_id": 1, "status": 1})
+	if err != nil {
+		return nil, err
+	}
+	status := make(map[string]string, len(runs))
+	runIDs := make([]string, 0, len(runs))
+	for _, r := range runs {
+		status[r.RunID] = r.Status
+		runIDs = append(runIDs, r.RunID)
+	}
+	slips, err := s.payrollRepo.GetAllPayslipsByFilter(ctx, bson.M{"run_id": bson.M{"$in": runIDs}, "user_id": claims.UserID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]dto.PayslipDTO, 0, len(slips))
+	for _, p := range slips {
+		out = append(out, toPayslipDTO(p, status[p.RunID]))
+	}
+	return out, nil
+}
+
+func (s *payrollService) RenderPayslipPDF(ctx context.Context, payslipID string, claims *dto.JWTClaims) ([]byte, string, error) {
+	slip, err := s.payrollRepo.GetOnePayslipByFilter(ctx, bson.M{"payslip_id": strings.TrimSpace(payslipID), "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, "", err
+	}
+	if slip == nil {
+		return nil, "", mongo.ErrNoDocuments
+	}
+	if claims.Role != "admin" && slip.UserID != claims.UserID {
+		return nil, "", ports.ErrPayrollForbidden
+	}
+	run, err := s.getPayrollRun(ctx, slip.RunID)
+	if err != nil {
+		return nil, "", err
+	}
+	if claims.Role != "admin" && !helpers.InSet(run.Status, models.PayrollApproved, models.PayrollPaid) {
+		return nil, "", ports.ErrPayrollForbidden
+	}
+	name := fmt.Sprintf("payslip-%s-%s.pdf", slip.Month, slip.EmployeeCode)
+	return renderPayslip(slip, run), name, nil
+}
+
+// ---------- การคำนวณ ----------
+
+// payrollYTD ยอดสะสมของปีจากรอบที่อนุมัติ/จ่ายแล้วก่อนเดือนนี้
+type payrollYTD struct {
+	income float64
+	tax    float64
+	ssf    float64
+}
+
+func (s *payrollService) recalculate(ctx context.Context, run *models.PayrollRun, adjustments map[string][]models.PayItem) (*dto.PayrollRunDTO, error) {
+	if run.Status != models.PayrollDraft {
+		return nil, fmt.Errorf("%w: only draft payroll runs can be changed (current: %s)", ports.ErrPayrollConflict, run.Status)
+	}
+	calc := *run
+	slips, warnings, err := s.calculatePayslips(ctx, &calc, adjustments)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	updated, err := s.payrollRepo.TransitionPayrollRun(ctx, run.RunID, []string{models.PayrollDraft}, bson.M{
+		"employee_count":     calc.EmployeeCount,
+		"total_gross":        calc.TotalGross,
+		"total_ssf_employee": calc.TotalSSFEmployee,
+		"total_ssf_employer": calc.TotalSSFEmployer,
+		"total_tax":          calc.TotalTax,
+		"total_deductions":   calc.TotalDeductions,
+		"total_net":          calc.TotalNet,
+		"calculated_at":      now,
+		"updated_at":         now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("%w: payroll run is no longer draft", ports.ErrPayrollConflict)
+	}
+	if err := s.payrollRepo.ReplacePayslips(ctx, run.RunID, slips); err != nil {
+		return nil, err
+	}
+	out := toPayrollRunDTO(updated, slips, warnings)
+	return &out, nil
+}
+
+// calculatePayslips คำนวณสลิปของพนักงานที่มีโครงสร้างเงินเดือนและเริ่มงานแล้วในเดือนนั้น พร้อมยอดรวมของรอบ
+func (s *payrollService) calculatePayslips(ctx context.Context, run *models.PayrollRun, adjustments map[string][]models.PayItem) ([]models.Payslip, []string, error) {
+	month, err := time.Parse("2006-01", run.Month)
+	if err != nil {
+		return nil, nil, err
+	}
+	monthEnd := month.AddDate(0, 1, -1)
+
+	structures, err := s.payrollRepo.GetAllSalaryStructuresByFilter(ctx, bson.M{"deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, nil, err
+	}
+	byUser := make(map[string]*models.SalaryStructure, len(structures))
+	userIDs := make([]string, 0, len(structures))
+	for _, st := range structures {
+		byUser[st.UserID] = st
+		userIDs = append(userIDs, st.UserID)
+	}
+	users, err := s.userRepo.GetUserByFilter(ctx, bson.M{
+		"user_id":    bson.M{"$in": userIDs},
+		"status":     "approved",
+		"hire_date":  bson.M{"$lte": monthEnd.Add(24*time.Hour - time.Nanosecond)},
+		"deleted_at": nil,
+	}, bson.M{})
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.SliceStable(users, func(i, j int) bool { return users[i].EmployeeCode < users[j].EmployeeCode })
+
+	summaries, err := s.attendanceSvc.SummarizeAttendanceMonth(ctx, month, users)
+	if err != nil {
+		return nil, nil, err
+	}
+	attendance := make(map[string]dto.AttendanceMonthSummaryDTO, len(summaries))
+	for _, sum := range summaries {
+		attendance[sum.UserID] = sum
+	}
+	ytd, err := s.yearToDate(ctx, month, run.RunID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var warnings []string
+	if !monthEnd.Before(helpers.Today()) {
+		warnings = append(warnings, "ยังไม่สิ้นเดือน ขาดงาน/OT นับถึงเมื่อวานเท่านั้น")
+	}
+
+	now := time.Now()
+	run.EmployeeCount, run.TotalGross, run.TotalSSFEmployee, run.TotalSSFEmployer, run.TotalTax, run.TotalDeductions, run.TotalNet = 0, 0, 0, 0, 0, 0, 0
+	slips := make([]models.Payslip, 0, len(users))
+	for _, u := range users {
+		sum := attendance[u.UserID]
+		slip, err := computePayslip(byUser[u.UserID], sum, adjustments[u.UserID], ytd[u.UserID], month)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", strings.TrimSpace(u.EmployeeCode+" "+u.FirstNameTH), err)
+		}
+		slip.CreatedAt = now
+		slip.PayslipID = uuid.NewString()
+		slip.RunID = run.RunID
+		slip.Month = run.Month
+		slip.Year = month.Year()
+		slip.UserID = u.UserID
+		slip.EmployeeCode = u.EmployeeCode
+		slip.NameTH = strings.TrimSpace(u.TitleTH + u.FirstNameTH + " " + u.LastNameTH)
+		slip.NameEN = strings.TrimSpace(u.FirstNameEN + " " + u.LastNameEN)
+		slip.IDCard = u.IDCard
+		slip.DepartmentID = u.DepartmentID
+		slip.PositionID = u.PositionID
+		slip.BankName = u.BankInfo.BankName
+		slip.AccountNo = u.BankInfo.AccountNo
+		slip.AccountName = u.BankInfo.AccountName
+
+		if sum.OTPendingHours > 0 {
+			warnings = append(warnings, fmt.Sprintf("%s มี OT รออนุมัติ %.2f ชั่วโมง (ยังไม่รวมในเงินเดือน)", payslipLabel(&slip), sum.OTPendingHours))
+		}
+		if sum.IncompleteDays > 0 {
+			warnings = append(warnings, fmt.Sprintf("%s ลงเวลาไม่ครบ %d วัน", payslipLabel(&slip), sum.IncompleteDays))
+		}
+
+		run.EmployeeCount++
+		run.TotalGross += slip.Gross
+		run.TotalSSFEmployee += slip.SSFEmployee
+		run.TotalSSFEmployer += slip.SSFEmployer
+		run.TotalTax += slip.WithholdingTax
+		run.TotalDeductions += slip.TotalDeduction
+		run.TotalNet += slip.Net
+		slips = append(slips, slip)
+	}
+	run.TotalGross = util.Round2(run.TotalGross)
+	run.TotalSSFEmployee = util.Round2(run.TotalSSFEmployee)
+	run.TotalSSFEmployer = util.Round2(run.TotalSSFEmployer)
+	run.TotalTax = util.Round2(run.TotalTax)
+	run.TotalDeductions = util.Round2(run.TotalDeductions)
+	run.TotalNet = util.Round2(run.TotalNet)
+	run.CalculatedAt = now
+	return slips, warnings, nil
+}
+
+func (s *payrollService) yearToDate(ctx context.Context, month time.Time, excludeRunID string) (map[string]payrollYTD, error) {
+	out := map[string]payrollYTD{}
+	if month.Month() == time.January {
+		return out, nil
+	}
+	runs, err := s.payrollRepo.GetAllPayrollRunsByFilter(ctx, bson.M{
+		"month":      bson.M{"$gte": fmt.Sprintf("%04d-01", month.Year()), "$lt": month.Format("2006-01")},
+		"status":     bson.M{"$in": []string{models.PayrollApproved, models.PayrollPaid}},
+		"run_id":     bson.M{"$ne": excludeRunID},
+		"deleted_at": nil,
+	}, bson.M{"_id": 0, "run_id": 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return out, nil
+	}
+	runIDs := make([]string, 0, len(runs))
+	for _, r := range runs {
+		runIDs = append(runIDs, r.RunID)
+	}
+	slips, err := s.payrollRepo.GetAllPayslipsByFilter(ctx, bson.M{"run_id": bson.M{"$in": runIDs}, "deleted_at": nil}, bson.M{"_id": 0, "user_id": 1, "taxable_income": 1, "withholding_tax": 1, "ssf_employee": 1})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range slips {
+		y := out[p.UserID]
+		y.income += p.TaxableIncome
+		y.tax += p.WithholdingTax
+		y.ssf += p.SSFEmployee
+		out[p.UserID] = y
+	}
+	return out, nil
+}
+
+// computePayslip รายเดือน: เงินเดือนเต็มแล้วหักขาดงาน/ลาไม่รับค่าจ้างเป็นรายวัน (เงินเดือน / 30)
+// รายวัน: ค่าจ้าง x (วันที่มาทำงาน + วันลาที่ได้รับค่าจ้าง)
+// OT คิดจากค่าจ้างต่อชั่วโมงคูณอัตรา ส่วน OT วันหยุดใช้อัตราวันหยุด (ไม่นับเป็นฐานประกันสังคม)
+func computePayslip(st *models.SalaryStructure, sum dto.AttendanceMonthSummaryDTO, adjustments []models.PayItem, ytd payrollYTD, period time.Time) (models.Payslip, error) {
+	slip := models.Payslip{
+		PayBasis:        st.PayBasis,
+		ScheduledDays:   sum.ScheduledDays,
+		PresentDays:     sum.PresentDays,
+		AbsentDays:      sum.AbsentDays,
+		LeaveDays:       sum.LeaveDays,
+		UnpaidLeaveDays: sum.UnpaidLeaveDays,
+		OTHours:         sum.OTApprovedHours,
+		HolidayOTHours:  sum.OTHolidayHours,
+		Earnings:        []models.PayLine{},
+		Deductions:      []models.PayLine{},
+		Adjustments:     adjustments,
+	}
+	hours := st.HoursPerDay
+	if hours <= 0 {
+		hours = payrollDefaultHoursPerDay
+	}
+
+	var wage float64 // ค่าจ้างที่ใช้คิดประกันสังคม
+	earn := func(code, name string, amount float64, taxable bool) {
+		amount = util.Round2(amount)
+		if amount == 0 {
+			return
+		}
+		slip.Earnings = append(slip.Earnings, models.PayLine{Code: code, Name: name, Amount: amount, Taxable: taxable})
+		slip.Gross += amount
+		if taxable {
+			slip.TaxableIncome += amount
+		}
+	}
+	deduct := func(code, name string, amount float64) {
+		amount = util.Round2(amount)
+		if amount == 0 {
+			return
+		}
+		slip.Deductions = append(slip.Deductions, models.PayLine{Code: code, Name: name, Amount: amount})
+		slip.TotalDeduction += amount
+	}
+
+	var dailyRate float64
+	if st.PayBasis == models.PayBasisDaily {
+		dailyRate = st.DailyRate
+		paidDays := float64(sum.PresentDays) + math.Max(sum.LeaveDays-sum.UnpaidLeaveDays, 0)
+		pay := util.Round2(dailyRate * paidDays)
+		earn(models.PayCodeBase, fmt.Sprintf("ค่าจ้างรายวัน %.1f วัน", paidDays), pay, true)
+		wage += pay
+	} else {
+		dailyRate = st.BaseSalary / payrollDaysPerMonth
+		earn(models.PayCodeBase, "เงินเดือน", st.BaseSalary, true)
+		absence := util.Round2(dailyRate * sum.AbsentDays)
+		unpaid := util.Round2(dailyRate * sum.UnpaidLeaveDays)
+		earn(models.PayCodeAbsence, fmt.Sprintf("ขาดงาน %.1f วัน", sum.AbsentDays), -absence, true)
+		earn(models.PayCodeUnpaidLeave, fmt.Sprintf("ลาไม่รับค่าจ้าง %.1f วัน", sum.UnpaidLeaveDays), -unpaid, true)
+		wage += st.BaseSalary - absence - unpaid
+	}
+
+	hourly := dailyRate / hours
+	regularOT := math.Max(sum.OTApprovedHours-sum.OTHolidayHours, 0)
+	earn(models.PayCodeOT, fmt.Sprintf("ค่าล่วงเวลา %.2f ชม. x %.1f", regularOT, st.OTMultiplier), hourly*st.OTMultiplier*regularOT, true)
+	earn(models.PayCodeHolidayOT, fmt.Sprintf("ทำงานวันหยุด %.2f ชม. x %.1f", sum.OTHolidayHours, st.HolidayMultiplier), hourly*st.HolidayMultiplier*sum.OTHolidayHours, true)
+
+	for _, a := range st.Allowances {
+		earn(models.PayCodeAllowance, a.Name, a.Amount, a.Taxable)
+		if a.IsWage {
+			wage += a.Amount
+		}
+	}
+	var adjustmentDeductions []models.PayItem
+	for _, a := range adjustments {
+		if a.Amount > 0 {
+			earn(models.PayCodeAdjustment, a.Name, a.Amount, a.Taxable)
+			continue
+		}
+		adjustmentDeductions = append(adjustmentDeductions, a)
+		if a.Taxable {
+			slip.TaxableIncome += a.Amount
+		}
+	}
+	slip.Gross = util.Round2(slip.Gross)
+	slip.TaxableIncome = util.Round2(math.Max(slip.TaxableIncome, 0))
+	if slip.Gross < 0 {
+		return slip, errors.New("gross pay is negative")
+	}
+
+	slip.SSFWage = util.Round2(math.Max(wage, 0))
+	if st.SSFEnabled {
+		slip.SSFEmployee = helpers.SocialSecurity(slip.SSFWage, period)
+		slip.SSFEmployer = slip.SSFEmployee
+	}
+	slip.WithholdingTax = helpers.MonthlyWithholding(helpers.WithholdingInput{
+		Year:           period.Year(),
+		Month:          int(period.Month()),
+		YTDIncome:      ytd.income,
+		YTDTax:         ytd.tax,
+		YTDSSF:         ytd.ssf,
+		Income:         slip.TaxableIncome,
+		SSF:            slip.SSFEmployee,
+		OtherDeduction: st.TaxDeductions,
+	})
+
+	deduct(models.PayCodeSSF, "ประกันสังคม", slip.SSFEmployee)
+	deduct(models.PayCodeTax, "ภาษีหัก ณ ที่จ่าย", slip.WithholdingTax)
+	for _, d := range st.Deductions {
+		deduct(models.PayCodeDeduction, d.Name, d.Amount)
+	}
+	for _, a := range adjustmentDeductions {
+		deduct(models.PayCodeAdjustment, a.Name, -a.Amount)
+	}
+	slip.TotalDeduction = util.Round2(slip.TotalDeduction)
+	slip.Net = util.Round2(slip.Gross - slip.TotalDeduction)
+	if slip.Net < 0 {
+		return slip, fmt.Errorf("deductions %.2f exceed gross pay %.2f", slip.TotalDeduction, slip.Gross)
+	}
+
+	slip.YTDIncome = util.Round2(ytd.income + slip.TaxableIncome)
+	slip.YTDTax = util.Round2(ytd.tax + slip.WithholdingTax)
+	slip.YTDSSF = util.Round2(ytd.ssf + slip.SSFEmployee)
+	return slip, nil
+}
+
+// ---------- ตัวช่วย ----------
+
+func (s *payrollService) getPayrollRun(ctx context.Context, runID string) (*models.PayrollRun, error) {
+	run, err := s.payrollRepo.GetOnePayrollRunByFilter(ctx, bson.M{"run_id": strings.TrimSpace(runID), "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	return run, nil
+}
+
+func (s *payrollService) runPayslips(ctx context.Context, runID string) ([]models.Payslip, error) {
+	items, err := s.payrollRepo.GetAllPayslipsByFilter(ctx, bson.M{"run_id": runID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.Payslip, 0, len(items))
+	for _, p := range items {
+		out = append(out, *p)
+	}
+	return out, nil
+}
+
+// mailPayslips แจ้งพนักงานเมื่อโอนเงินเดือนแล้ว (ส่งแบบ async)
+func (s *payrollService) mailPayslips(slips []models.Payslip) {
+	if s.config.Email.Host == "" || len(slips) == 0 {
+		return
+	}
+	emailCfg := util.EmailConfig{
+		Host:     s.config.Email.Host,
+		Port:     s.config.Email.Port,
+		Username: s.config.Email.Username,
+		Password: s.config.Email.Password,
+		From:     s.config.Email.From,
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		for _, p := range slips {
+			user, err := s.userRepo.GetByID(ctx, p.UserID)
+			if err != nil || user == nil || strings.TrimSpace(user.Email) == "" {
+				continue
+			}
+			subject := fmt.Sprintf("สลิปเงินเดือน %s", p.Month)
+			body := fmt.Sprintf("บริษัทได้โอนเงินเดือนประจำเดือน %s แล้ว\nเงินได้รวม %.2f บาท\nเงินหักรวม %.2f บาท\nยอดโอนสุทธิ %.2f บาท\n\nดาวน์โหลดสลิปได้ที่เมนูเงินเดือนของฉัน", p.Month, p.Gross, p.TotalDeduction, p.Net)
+			if err := util.SendMail(emailCfg, user.Email, subject, fmt.Sprintf("เรียนคุณ %s\n\n%s", user.FirstNameTH, body)); err != nil {
+				log.Println("Error sending payslip email:", err)
+			}
+		}
+	}()
+}
+
+func toPayItems(field string, items []dto.PayItemDTO) ([]models.PayItem, error) {
+	out := make([]models.PayItem, 0, len(items))
+	for i, it := range items {
+		name := strings.TrimSpace(it.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%s[%d].name is required", field, i)
+		}
+		if it.Amount <= 0 {
+			return nil, fmt.Errorf("%s[%d].amount must be positive", field, i)
+		}
+		out = append(out, models.PayItem{Name: name, Amount: util.Round2(it.Amount), Taxable: it.Taxable, IsWage: it.IsWage})
+	}
+	return out, nil
+}
+
+func payslipLabel(p *models.Payslip) string {
+	return strings.TrimSpace(p.EmployeeCode + " " + p.NameTH)
+}
+
+func toSalaryStructureDTO(st *models.SalaryStructure, user *models.User) dto.SalaryStructureDTO {
+	updatedAt := st.UpdatedAt
+	out := dto.SalaryStructureDTO{
+		UpdatedAt:         &updatedAt,
+		StructureID:       st.StructureID,
+		UserID:            st.UserID,
+		PayBasis:          st.PayBasis,
+		BaseSalary:        st.BaseSalary,
+		DailyRate:         st.DailyRate,
+		HoursPerDay:       st.HoursPerDay,
+		OTMultiplier:      st.OTMultiplier,
+		HolidayMultiplier: st.HolidayMultiplier,
+		Allowances:        toPayItemDTOs(st.Allowances),
+		Deductions:        toPayItemDTOs(st.Deductions),
+		SSFEnabled:        st.SSFEnabled,
+		TaxDeductions:     st.TaxDeductions,
+	}
+	if user != nil {
+		out.UserName = strings.TrimSpace(user.FirstNameTH + " " + user.LastNameTH)
+		out.EmployeeCode = user.EmployeeCode
+		out.EmploymentType = user.EmploymentType
+		out.HasBankAccount = strings.TrimSpace(user.BankInfo.AccountNo) != ""
+	}
+	return out
+}
+
+func toPayItemDTOs(items []models.PayItem) []dto.PayItemDTO {
+	out := make([]dto.PayItemDTO, 0, len(items))
+	for _, it := range items {
+		out = append(out, dto.PayItemDTO{Name: it.Name, Amount: it.Amount, Taxable: it.Taxable, IsWage: it.IsWage})
+	}
+	return out
+}
+
+func toPayrollRunDTO(run *models.PayrollRun, slips []models.Payslip, warnings []string) dto.PayrollRunDTO {
+	out := dto.PayrollRunDTO{
+		CreatedAt:        run.CreatedAt,
+		CalculatedAt:     run.CalculatedAt,
+		PayDate:          run.PayDate,
+		ApprovedAt:       run.ApprovedAt,
+		PaidAt:           run.PaidAt,
+		CancelledAt:      run.CancelledAt,
+		RunID:            run.RunID,
+		Month:            run.Month,
+		Status:           run.Status,
+		BankID:           run.BankID,
+		EmployeeCount:    run.EmployeeCount,
+		TotalGross:       run.TotalGross,
+		TotalSSFEmployee: run.TotalSSFEmployee,
+		TotalSSFEmployer: run.TotalSSFEmployer,
+		TotalTax:         run.TotalTax,
+		TotalDeductions:  run.TotalDeductions,
+		TotalNet:         run.TotalNet,
+		ExpenseID:        run.ExpenseID,
+		Note:             run.Note,
+		CreatedBy:        run.CreatedBy,
+		ApprovedBy:       run.ApprovedBy,
+		PaidBy:           run.PaidBy,
+		CancelReason:     run.CancelReason,
+		Warnings:         warnings,
+	}
+	for i := range slips {
+		out.Payslips = append(out.Payslips, toPayslipDTO(&slips[i], run.Status))
+		if slips[i].Net > 0 && strings.TrimSpace(slips[i].AccountNo) == "" {
+			out.Warnings = append(out.Warnings, payslipLabel(&slips[i])+" ไม่มีบัญชีธนาคาร")
+		}
+	}
+	return out
+}
+
+func toPayslipDTO(p *models.Payslip, runStatus string) dto.PayslipDTO {
+	return dto.PayslipDTO{
+		PayslipID:       p.PayslipID,
+		RunID:           p.RunID,
+		Month:           p.Month,
+		RunStatus:       runStatus,
+		UserID:          p.UserID,
+		EmployeeCode:    p.EmployeeCode,
+		Name:            p.NameTH,
+		DepartmentID:    p.DepartmentID,
+		BankName:        p.BankName,
+		AccountNo:       p.AccountNo,
+		PayBasis:        p.PayBasis,
+		ScheduledDays:   p.ScheduledDays,
+		PresentDays:     p.PresentDays,
+		AbsentDays:      p.AbsentDays,
+		LeaveDays:       p.LeaveDays,
+		UnpaidLeaveDays: p.UnpaidLeaveDays,
+		OTHours:         p.OTHours,
+		HolidayOTHours:  p.HolidayOTHours,
+		Earnings:        toPayLineDTOs(p.Earnings),
+		Deductions:      toPayLineDTOs(p.Deductions),
+		Gross:           p.Gross,
+		TaxableIncome:   p.TaxableIncome,
+		SSFEmployee:     p.SSFEmployee,
+		SSFEmployer:     p.SSFEmployer,
+		WithholdingTax:  p.WithholdingTax,
+		TotalDeduction:  p.TotalDeduction,
+		Net:             p.Net,
+		YTDIncome:       p.YTDIncome,
+		YTDTax:          p.YTDTax,
+		YTDSSF:          p.YTDSSF,
+	}
+}
+
+func toPayLineDTOs(lines []models.PayLine) []dto.PayLineDTO {
+	out := make([]dto.PayLineDTO, 0, len(lines))
+	for _, l := range lines {
+		out = append(out, dto.PayLineDTO{Code: l.Code, Name: l.Name, Amount: l.Amount, Taxable: l.Taxable})
+	}
+	return out
+}
+
+// ---------- PDF ----------
+
+// payslipLineLabels ชื่อภาษาอังกฤษของรายการที่ระบบคำนวณ (PDF ใช้ฟอนต์มาตรฐานที่ไม่มีอักษรไทย)
+var payslipLineLabels = map[string]string{
+	models.PayCodeBase:        "Base salary",
+	models.PayCodeOT:          "Overtime",
+	models.PayCodeHolidayOT:   "Holiday work",
+	models.PayCodeAbsence:     "Absence",
+	models.PayCodeUnpaidLeave: "Unpaid leave",
+	models.PayCodeSSF:         "Social security",
+	models.PayCodeTax:         "Withholding tax",
+	models.PayCodeAllowance:   "Allowance",
+	models.PayCodeDeduction:   "Deduction",
+	models.PayCodeAdjustment:  "Adjustment",
+}
+
+func payslipLineLabel(l models.PayLine) string {
+	switch l.Code {
+	case models.PayCodeAllowance, models.PayCodeDeduction, models.PayCodeAdjustment:
+		if isASCII(l.Name) {
+			return l.Name
+		}
+	}
+	return payslipLineLabels[l.Code]
+}
+
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > 127 {
+			return false
+		}
+	}
+	return true
+}
+
+func renderPayslip(p *models.Payslip, run *models.PayrollRun) []byte {
+	doc := util.NewPDFDocument()
+	const left, right = 50.0, util.PDFPageWidth - 50
+	mid := util.PDFPageWidth / 2
+
+	name := p.NameEN
+	if name == "" {
+		name = p.EmployeeCode
+	}
+	y := 60.0
+	doc.Text(left, y, 18, true, "PAYSLIP")
+	doc.TextRight(right, y, 10, false, "Pay period: "+p.Month)
+	y += 16
+	doc.TextRight(right, y, 10, false, "Pay date: "+run.PayDate.Format("2006-01-02"))
+	y += 24
+	doc.Text(left, y, 10, false, "Employee: "+name)
+	doc.Text(mid, y, 10, false, "Employee code: "+p.EmployeeCode)
+	y += 14
+	doc.Text(left, y, 10, false, "Bank account: "+p.AccountNo)
+	doc.Text(mid, y, 10, false, "Pay basis: "+p.PayBasis)
+	y += 14
+	doc.Text(left, y, 10, false, fmt.Sprintf("Work days: %d  Present: %d  Absent: %.1f  Leave: %.1f", p.ScheduledDays, p.PresentDays, p.AbsentDays, p.LeaveDays))
+	doc.Text(mid+60, y, 10, false, fmt.Sprintf("OT hours: %.2f", p.OTHours))
+	y += 20
+	doc.Line(left, y, right, y)
+	y += 16
+
+	doc.Text(left, y, 11, true, "Earnings")
+	doc.Text(mid+10, y, 11, true, "Deductions")
+	y += 16
+	rowY := y
+	for _, l := range p.Earnings {
+		doc.Text(left, rowY, 10, false, payslipLineLabel(l))
+		doc.TextRight(mid-10, rowY, 10, false, formatBaht(l.Amount))
+		rowY += 14
+	}
+	dedY := y
+	for _, l := range p.Deductions {
+		doc.Text(mid+10, dedY, 10, false, payslipLineLabel(l))
+		doc.TextRight(right, dedY, 10, false, formatBaht(l.Amount))
+		dedY += 14
+	}
+	y = math.Max(rowY, dedY) + 6
+	doc.Line(left, y, right, y)
+	y += 16
+	doc.Text(left, y, 10, true, "Total earnings")
+	doc.TextRight(mid-10, y, 10, true, formatBaht(p.Gross))
+	doc.Text(mid+10, y, 10, true, "Total deductions")
+	doc.TextRight(right, y, 10, true, formatBaht(p.TotalDeduction))
+	y += 24
+	doc.Text(left, y, 13, true, "NET PAY (THB)")
+	doc.TextRight(right, y, 13, true, formatBaht(p.Net))
+	y += 28
+	doc.Line(left, y, right, y)
+	y += 16
+	doc.Text(left, y, 9, false, fmt.Sprintf("Year to date - taxable income: %s  withholding tax: %s  social security: %s", formatBaht(p.YTDIncome), formatBaht(p.YTDTax), formatBaht(p.YTDSSF)))
+	y += 12
+	doc.Text(left, y, 9, false, fmt.Sprintf("Employer social security contribution: %s", formatBaht(p.SSFEmployer)))
+	return doc.Bytes()
+}
+
+// formatBaht จำนวนเงินแบบมีจุลภาคคั่นหลักพัน เช่น 12,345.50
+func formatBaht(v float64) string {
+	s := fmt.Sprintf("%.2f", math.Abs(v))
+	intPart, frac := s[:len(s)-3], s[len(s)-3:]
+	var b strings.Builder
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	out := b.String() + frac
+	if v < 0 {
+		out = "-" + out
+	}
+	return out
+}
@@ -528,6 +528,7 @@ func (s *receivableService) RecordReceipt(ctx context.Context, input dto.RecordR
 			PaymentMethod:         input.PaymentMethod,
 			ReferenceNo:           rec.InvoiceNo,
 			Note:                  &signJob.JobName,
+			JobID:                 signJob.JobID,
 			CreatedBy:             claims.UserID,
 			CreatedAt:             now,
 			UpdatedAt:             now,
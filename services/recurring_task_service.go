@@ -0,0 +1,745 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/config"
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/pkg/util"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	maxRecurringAssignees   = 50
+	maxRecurringDuration    = 366
+	recurringUpcomingCount  = 5
+	recurringMaxCatchUpRuns = 1000 // กันวนไม่จบตอนไล่รอบที่ตกหล่น (เช่น cron ทุกนาทีแต่ระบบหยุดไปนาน)
+)
+
+type recurringTaskService struct {
+	config         config.Config
+	recurringRepo  ports.RecurringTaskRepository
+	taskRepo       ports.TaskRepository
+	workflowRepo   ports.WorkFlowRepository
+	userRepo       ports.UserRepository
+	departmentRepo ports.DepartmentRepository
+}
+
+func NewRecurringTaskService(cfg config.Config, recurringRepo ports.RecurringTaskRepository, taskRepo ports.TaskRepository, workflowRepo ports.WorkFlowRepository, userRepo ports.UserRepository, departmentRepo ports.DepartmentRepository) ports.RecurringTaskService {
+	return &recurringTaskService{config: cfg, recurringRepo: recurringRepo, taskRepo: taskRepo, workflowRepo: workflowRepo, userRepo: userRepo, departmentRepo: departmentRepo}
+}
+
+func (s *recurringTaskService) CreateRecurringTask(ctx context.Context, req dto.CreateRecurringTaskDTO, claims *dto.JWTClaims) (*dto.RecurringTaskDTO, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	wf, err := s.activeWorkflow(ctx, req.WorkflowID)
+	if err != nil {
+		return nil, err
+	}
+	department := strings.TrimSpace(req.DepartmentID)
+	if department == "" {
+		department = wf.Department
+	}
+	if err := s.checkManage(ctx, department, claims); err != nil {
+		return nil, err
+	}
+	importance, err := normalizeRecurringImportance(req.Importance)
+	if err != nil {
+		return nil, err
+	}
+	duration := req.DurationDays
+	if duration == 0 {
+		duration = 1
+	}
+	if duration < 1 || duration > maxRecurringDuration {
+		return nil, fmt.Errorf("duration_days must be between 1 and %d", maxRecurringDuration)
+	}
+	assignees, err := s.validateAssignees(ctx, req.Assignees)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	loc := helpers.BangkokLocation()
+	startAt, err := parseRecurringStart(req.StartAt, loc, now)
+	if err != nil {
+		return nil, err
+	}
+	schedule := strings.TrimSpace(req.Schedule)
+	sched, err := util.ParseRecurrence(schedule, startAt)
+	if err != nil {
+		return nil, err
+	}
+
+	def := models.RecurringTask{
+		RecurringID:  uuid.NewString(),
+		Name:         name,
+		Description:  strings.TrimSpace(req.Description),
+		WorkFlowID:   wf.WorkFlowID,
+		ProjectID:    strings.TrimSpace(req.ProjectID),
+		ProjectName:  strings.TrimSpace(req.ProjectName),
+		Department:   department,
+		Importance:   importance,
+		KPIID:        strings.TrimSpace(req.KPIID),
+		Schedule:     schedule,
+		StartAt:      startAt,
+		DurationDays: duration,
+		Assignees:    assignees,
+		IsActive:     true,
+		CreatedBy:    claims.UserID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	def.NextRunAt = nextRecurringRun(&def, sched, now)
+	if def.NextRunAt == nil {
+		return nil, errors.New("schedule has no upcoming occurrence")
+	}
+
+	if err := s.recurringRepo.CreateRecurringTask(ctx, def); err != nil {
+		return nil, err
+	}
+	return s.toDTO(ctx, &def, true)
+}
+
+func (s *recurringTaskService) ListRecurringTasks(ctx context.Context, req dto.RequestListRecurringTask, claims *dto.JWTClaims) (dto.Pagination, error) {
+	page, size := req.Page, req.Limit
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = 10
+	}
+	skip := int64((page - 1) * size)
+	limit := int64(size)
+
+	filter := bson.M{"deleted_at": nil}
+	if search := strings.TrimSpace(req.Search); search != "" {
+		filter["name"] = bson.M{"$regex": regexp.QuoteMeta(search), "$options": "i"}
+	}
+	if v := strings.TrimSpace(req.DepartmentID); v != "" {
+		filter["department_id"] = v
+	}
+	if v := strings.TrimSpace(req.WorkflowID); v != "" {
+		filter["workflow_id"] = v
+	}
+	switch strings.ToLower(strings.TrimSpace(req.Active)) {
+	case "true":
+		filter["is_active"] = true
+	case "false":
+		filter["is_active"] = false
+	}
+
+	// ผู้ใช้ทั่วไปเห็นเฉพาะที่ตัวเองสร้าง อยู่ในคิว หรือเป็นผู้จัดการแผนก
+	if claims.Role != "admin" {
+		managed, err := s.managedDepartments(ctx, claims.UserID)
+		if err != nil {
+			return dto.Pagination{}, err
+		}
+		filter["$or"] = bson.A{
+			bson.M{"created_by": claims.UserID},
+			bson.M{"assignees": claims.UserID},
+			bson.M{"department_id": bson.M{"$in": managed}},
+		}
+	}
+
+	sortBy := bson.D{
+		{Key: "is_active", Value: -1},
+		{Key: "next_run_at", Value: 1},
+		{Key: "_id", Value: -1},
+	}
+	items, total, err := s.recurringRepo.GetListRecurringTasksByFilter(ctx, filter, bson.M{}, sortBy, skip, limit)
+	if err != nil {
+		return dto.Pagination{}, fmt.Errorf("list recurring tasks: %w", err)
+	}
+
+	list := make([]interface{}, 0, len(items))
+	for i := range items {
+		out, err := s.toDTO(ctx, &items[i], false)
+		if err != nil {
+			return dto.Pagination{}, err
+		}
+		list = append(list, *out)
+	}
+
+	totalPages := 0
+	if total > 0 && size > 0 {
+		totalPages = int((total + int64(size) - 1) / int64(size))
+	}
+
+	return dto.Pagination{
+		Page:       page,
+		Size:       size,
+		TotalCount: int(total),
+		TotalPages: totalPages,
+		List:       list,
+	}, nil
+}
+
+func (s *recurringTaskService) GetRecurringTask(ctx context.Context, recurringID string, claims *dto.JWTClaims) (*dto.RecurringTaskDTO, error) {
+	def, err := s.getDefinition(ctx, recurringID)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Role != "admin" && def.CreatedBy != claims.UserID && !helpers.InSet(claims.UserID, def.Assignees...) {
+		if err := s.checkManage(ctx, def.Department, claims); err != nil {
+			return nil, err
+		}
+	}
+	return s.toDTO(ctx, def, true)
+}
+
+func (s *recurringTaskService) UpdateRecurringTask(ctx context.Context, recurringID string, req dto.UpdateRecurringTaskDTO, claims *dto.JWTClaims) (*dto.RecurringTaskDTO, error) {
+	def, err := s.getDefinition(ctx, recurringID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkManage(ctx, def.Department, claims); err != nil {
+		return nil, err
+	}
+
+	reschedule := false
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, errors.New("name is required")
+		}
+		def.Name = name
+	}
+	if req.Description != nil {
+		def.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.WorkflowID != nil {
+		wf, err := s.activeWorkflow(ctx, *req.WorkflowID)
+		if err != nil {
+			return nil, err
+		}
+		def.WorkFlowID = wf.WorkFlowID
+	}
+	if req.ProjectID != nil {
+		def.ProjectID = strings.TrimSpace(*req.ProjectID)
+	}
+	if req.ProjectName != nil {
+		def.ProjectName = strings.TrimSpace(*req.ProjectName)
+	}
+	if req.DepartmentID != nil {
+		department := strings.TrimSpace(*req.DepartmentID)
+		if department == "" {
+			return nil, errors.New("department_id is required")
+		}
+		if department != def.Department {
+			// ย้ายไปแผนกอื่นต้องมีสิทธิ์ทั้งสองแผนก
+			if err := s.checkManage(ctx, department, claims); err != nil {
+				return nil, err
+			}
+			def.Department = department
+		}
+	}
+	if req.Importance != nil {
+		importance, err := normalizeRecurringImportance(*req.Importance)
+		if err != nil {
+			return nil, err
+		}
+		def.Importance = importance
+	}
+	if req.KPIID != nil {
+		def.KPIID = strings.TrimSpace(*req.KPIID)
+	}
+	if req.DurationDays != nil {
+		if *req.DurationDays < 1 || *req.DurationDays > maxRecurringDuration {
+			return nil, fmt.Errorf("duration_days must be between 1 and %d", maxRecurringDuration)
+		}
+		def.DurationDays = *req.DurationDays
+	}
+	if req.Assignees != nil {
+		assignees, err := s.validateAssignees(ctx, *req.Assignees)
+		if err != nil {
+			return nil, err
+		}
+		def.Assignees = assignees
+		def.NextAssigneeIdx = 0
+	}
+
+	now := time.Now()
+	if req.StartAt != nil {
+		startAt, err := parseRecurringStart(*req.StartAt, helpers.BangkokLocation(), now)
+		if err != nil {
+			return nil, err
+		}
+		def.StartAt = startAt
+		reschedule = true
+	}
+	if req.Schedule != nil {
+		def.Schedule = strings.TrimSpace(*req.Schedule)
+		reschedule = true
+	}
+	if req.IsActive != nil && *req.IsActive != def.IsActive {
+		def.IsActive = *req.IsActive
+		reschedule = def.IsActive
+	}
+
+	sched, err := util.ParseRecurrence(def.Schedule, def.StartAt)
+	if err != nil {
+		return nil, err
+	}
+	if reschedule {
+		def.NextRunAt = nextRecurringRun(def, sched, now)
+		if def.NextRunAt == nil {
+			return nil, errors.New("schedule has no upcoming occurrence")
+		}
+	}
+	def.UpdatedAt = now
+
+	updated, err := s.recurringRepo.UpdateRecurringTaskByID(ctx, recurringID, *def)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	return s.toDTO(ctx, updated, true)
+}
+
+func (s *recurringTaskService) DeleteRecurringTask(ctx context.Context, recurringID string, claims *dto.JWTClaims) error {
+	def, err := s.getDefinition(ctx, recurringID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkManage(ctx, def.Department, claims); err != nil {
+		return err
+	}
+	// งานที่สร้างไปแล้วยังอยู่ตามเดิม
+	return s.recurringRepo.SoftDeleteRecurringTaskByID(ctx, recurringID)
+}
+
+func (s *recurringTaskService) RunRecurringTaskNow(ctx context.Context, recurringID string, claims *dto.JWTClaims) (*dto.RecurringSpawnDTO, error) {
+	def, err := s.getDefinition(ctx, recurringID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkManage(ctx, def.Department, claims); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	spawned, err := s.spawnTask(ctx, def, now)
+	if err != nil {
+		_ = s.recurringRepo.SetRecurringRunResult(ctx, recurringID, "", err.Error(), now)
+		return nil, err
+	}
+	if err := s.recurringRepo.SetRecurringRunResult(ctx, recurringID, spawned.TaskID, "", now); err != nil {
+		log.Println("Error saving recurring task run result:", err)
+	}
+	return spawned, nil
+}
+
+// RunDue สร้างงานของรายการที่ถึงรอบ รอบที่ตกหล่นระหว่างระบบหยุดจะสร้างเพียงงานเดียว (รอบล่าสุด) ไม่สร้างย้อนหลังทุกรอบ
+func (s *recurringTaskService) RunDue(ctx context.Context, now time.Time) (*dto.RecurringRunResult, error) {
+	start := time.Now()
+	result := &dto.RecurringRunResult{
+		Tasks:  []dto.RecurringSpawnDTO{},
+		Errors: []string{},
+		RunAt:  now,
+	}
+
+	defs, err := s.recurringRepo.GetAllRecurringTasksByFilter(ctx, bson.M{
+		"is_active":   true,
+		"deleted_at":  nil,
+		"next_run_at": bson.M{"$lte": now},
+	}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, def := range defs {
+		if def.NextRunAt == nil {
+			continue
+		}
+		result.Checked++
+
+		sched, err := util.ParseRecurrence(def.Schedule, def.StartAt)
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", def.Name, err))
+			_ = s.recurringRepo.SetRecurringRunResult(ctx, def.RecurringID, "", err.Error(), now)
+			continue
+		}
+
+		runAt := *def.NextRunAt
+		occurrence := runAt
+		for i := 0; i < recurringMaxCatchUpRuns; i++ {
+			n := sched.Next(occurrence)
+			if n.IsZero() || n.After(now) {
+				break
+			}
+			occurrence = n
+			result.Missed++
+		}
+
+		// นับรอบนี้ก่อนหารอบถัดไป (COUNT ของ RRULE)
+		counted := *def
+		counted.RunCount++
+		next := nextRecurringRun(&counted, sched, occurrence)
+
+		claimed, err := s.recurringRepo.ClaimRecurringRun(ctx, def.RecurringID, runAt, next, now)
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", def.Name, err))
+			continue
+		}
+		if claimed == nil {
+			continue // instance อื่นสร้างรอบนี้ไปแล้ว
+		}
+		if next == nil {
+			result.Finished++
+		}
+
+		spawned, err := s.spawnTask(ctx, claimed, occurrence)
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", def.Name, err))
+			_ = s.recurringRepo.SetRecurringRunResult(ctx, def.RecurringID, "", err.Error(), now)
+			continue
+		}
+		if err := s.recurringRepo.SetRecurringRunResult(ctx, def.RecurringID, spawned.TaskID, "", now); err != nil {
+			log.Println("Error saving recurring task run result:", err)
+		}
+		result.Spawned++
+		result.Tasks = append(result.Tasks, *spawned)
+	}
+
+	result.DurationMS = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+// spawnTask สร้าง task จาก workflow template เวอร์ชันล่าสุด ให้ผู้รับผิดชอบคนถัดไปในคิว
+func (s *recurringTaskService) spawnTask(ctx context.Context, def *models.RecurringTask, occurrence time.Time) (*dto.RecurringSpawnDTO, error) {
+	wf, err := s.activeWorkflow(ctx, def.WorkFlowID)
+	if err != nil {
+		return nil, err
+	}
+	assignee, err := s.nextAssignee(ctx, def)
+	if err != nil {
+		return nil, err
+	}
+
+	assigneeName := fmt.Sprintf("%s %s %s", assignee.TitleTH, assignee.FirstNameTH, assignee.LastNameTH)
+	departments, err := s.departmentRepo.GetAllDepartmentByFilter(ctx, bson.M{"deleted_at": nil}, bson.M{"department_id": 1, "manager_id": 1})
+	if err != nil {
+		return nil, err
+	}
+	managers := make(map[string]string, len(departments))
+	for _, d := range departments {
+		managers[d.DepartmentID] = d.ManagerID
+	}
+
+	now := time.Now()
+	steps, total := snapshotWorkflowSteps(wf, now)
+	defaultStepAssignees(ctx, s.userRepo, steps, assignee.UserID, def.Department, managers)
+
+	local := occurrence.In(helpers.BangkokLocation())
+	startDate := helpers.DateOnly(local)
+	duration := def.DurationDays
+	if duration < 1 {
+		duration = 1
+	}
+	description := def.Description
+	if description == "" {
+		description = wf.Description
+	}
+
+	task := models.Tasks{
+		TaskID:      uuid.NewString(),
+		ProjectID:   def.ProjectID,
+		ProjectName: def.ProjectName,
+		JobName:     fmt.Sprintf("%s (%s)", def.Name, local.Format("02/01/2006")),
+		Description: description,
+
+		Department:       def.Department,
+		Assignee:         assignee.UserID,
+		AssigneeName:     assigneeName,
+		AssigneeNickName: assignee.NickName,
+		Importance:       def.Importance,
+		StartDate:        startDate,
+		EndDate:          startDate.AddDate(0, 0, duration-1),
+		KPIID:            def.KPIID,
+		WorkFlowID:       wf.WorkFlowID,
+
+		AppliedWorkflow: models.TaskAppliedWorkflow{
+			WorkFlowID:   wf.WorkFlowID,
+			WorkFlowName: wf.WorkFlowName,
+			Department:   wf.Department,
+			Description:  wf.Description,
+			TotalHours:   total,
+			Steps:        steps,
+			Version:      wf.Version,
+
+			TemplateVersion: wf.Version,
+		},
+
+		Status:      "todo",
+		StepName:    helpers.CurrentStepName(steps),
+		ReadySteps:  helpers.ReadyStepNames(steps),
+		CreatedBy:   def.CreatedBy,
+		RecurringID: def.RecurringID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.taskRepo.CreateTask(ctx, task); err != nil {
+		return nil, err
+	}
+	if err := applyTaskStatsDiff(ctx, s.taskRepo, nil, taskOwnerShares(&task)); err != nil {
+		return nil, err
+	}
+
+	return &dto.RecurringSpawnDTO{
+		RecurringID: def.RecurringID,
+		TaskID:      task.TaskID,
+		JobName:     task.JobName,
+		RunAt:       occurrence,
+		Assignee:    assignee.UserID,
+	}, nil
+}
+
+// nextAssignee เลื่อนคิวแบบ round-robin ข้ามผู้ใช้ที่ถูกลบหรือยังไม่อนุมัติ
+func (s *recurringTaskService) nextAssignee(ctx context.Context, def *models.RecurringTask) (*models.User, error) {
+	if len(def.Assignees) == 0 {
+		return nil, errors.New("recurring task has no assignees")
+	}
+	idx, err := s.recurringRepo.IncrementAssigneeIndex(ctx, def.RecurringID)
+	if err != nil {
+		return nil, err
+	}
+	for k := 0; k < len(def.Assignees); k++ {
+		userID := def.Assignees[(idx+k)%len(def.Assignees)]
+		user, _ := s.userRepo.GetByID(ctx, userID)
+		if user != nil && user.DeletedAt == nil && user.Status == "approved" {
+			return user, nil
+		}
+	}
+	return nil, errors.New("no active assignee in rotation")
+}
+
+func (s *recurringTaskService) getDefinition(ctx context.Context, recurringID string) (*models.RecurringTask, error) {
+	def, err := s.recurringRepo.GetOneRecurringTaskByFilter(ctx, bson.M{"recurring_id": recurringID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if def == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	return def, nil
+}
+
+func (s *recurringTaskService) activeWorkflow(ctx context.Context, workflowID string) (*models.WorkFlowTemplate, error) {
+	workflowID = strings.TrimSpace(workflowID)
+	if workflowID == "" {
+		return nil, errors.New("workflow_id is required")
+	}
+	wf, err := s.workflowRepo.GetOneWorkFlowTemplateByFilter(ctx, bson.M{"workflow_id": workflowID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if wf == nil {
+		return nil, fmt.Errorf("workflow %s not found", workflowID)
+	}
+	if !wf.IsActive {
+		return nil, fmt.Errorf("workflow %s is inactive", wf.WorkFlowName)
+	}
+	return wf, nil
+}
+
+// checkManage admin หรือผู้จัดการของแผนกนั้น
+func (s *recurringTaskService) checkManage(ctx context.Context, departmentID string, claims *dto.JWTClaims) error {
+	if departmentID == "" {
+		return errors.New("department_id is required")
+	}
+	dept, err := s.departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{"department_id": departmentID, "deleted_at": nil}, bson.M{"department_id": 1, "manager_id": 1})
+	if err != nil && err != mongo.ErrNoDocuments {
+		return err
+	}
+	if dept == nil {
+		return errors.New("department not found")
+	}
+	if claims.Role != "admin" && dept.ManagerID != claims.UserID {
+		return ports.ErrRecurringTaskForbidden
+	}
+	return nil
+}
+
+func (s *recurringTaskService) managedDepartments(ctx context.Context, userID string) ([]string, error) {
+	depts, err := s.departmentRepo.GetAllDepartmentByFilter(ctx, bson.M{"manager_id": userID, "deleted_at": nil}, bson.M{"department_id": 1})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(depts))
+	for _, d := range depts {
+		out = append(out, d.DepartmentID)
+	}
+	return out, nil
+}
+
+func (s *recurringTaskService) validateAssignees(ctx context.Context, ids []string) ([]string, error) {
+	assignees := make([]string, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		assignees = append(assignees, id)
+	}
+	if len(assignees) == 0 {
+		return nil, errors.New("at least one assignee is required")
+	}
+	if len(assignees) > maxRecurringAssignees {
+		return nil, fmt.Errorf("assignees cannot exceed %d users", maxRecurringAssignees)
+	}
+	users, err := s.userRepo.GetUserByFilter(ctx, bson.M{"user_id": bson.M{"$in": assignees}, "status": "approved", "deleted_at": nil}, bson.M{"user_id": 1})
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]bool, len(users))
+	for _, u := range users {
+		found[u.UserID] = true
+	}
+	for _, id := range assignees {
+		if !found[id] {
+			return nil, fmt.Errorf("assignee %s not found or not approved", id)
+		}
+	}
+	return assignees, nil
+}
+
+func (s *recurringTaskService) toDTO(ctx context.Context, def *models.RecurringTask, withUpcoming bool) (*dto.RecurringTaskDTO, error) {
+	workflowName := ""
+	wf, err := s.workflowRepo.GetOneWorkFlowTemplateByFilter(ctx, bson.M{"workflow_id": def.WorkFlowID}, bson.M{"workflow_name": 1})
+	if err != nil {
+		return nil, err
+	}
+	if wf != nil {
+		workflowName = wf.WorkFlowName
+	}
+	departmentName := ""
+	dept, err := s.departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{"department_id": def.Department}, bson.M{"department_name": 1})
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+	if dept != nil {
+		departmentName = dept.DepartmentName
+	}
+
+	users, err := s.userRepo.GetUserByFilter(ctx, bson.M{"user_id": bson.M{"$in": def.Assignees}}, bson.M{"user_id": 1, "title_th": 1, "first_name_th": 1, "last_name_th": 1})
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(users))
+	for _, u := range users {
+		names[u.UserID] = fmt.Sprintf("%s %s %s", u.TitleTH, u.FirstNameTH, u.LastNameTH)
+	}
+	nextIdx := -1
+	if len(def.Assignees) > 0 {
+		nextIdx = def.NextAssigneeIdx % len(def.Assignees)
+	}
+	assignees := make([]dto.RecurringAssigneeDTO, 0, len(def.Assignees))
+	for i, id := range def.Assignees {
+		assignees = append(assignees, dto.RecurringAssigneeDTO{UserID: id, Name: names[id], IsNext: i == nextIdx})
+	}
+
+	out := &dto.RecurringTaskDTO{
+		CreatedAt:      def.CreatedAt,
+		UpdatedAt:      def.UpdatedAt,
+		RecurringID:    def.RecurringID,
+		Name:           def.Name,
+		Description:    def.Description,
+		WorkFlowID:     def.WorkFlowID,
+		WorkFlowName:   workflowName,
+		ProjectID:      def.ProjectID,
+		ProjectName:    def.ProjectName,
+		DepartmentID:   def.Department,
+		DepartmentName: departmentName,
+		Importance:     def.Importance,
+		KPIID:          def.KPIID,
+		Schedule:       def.Schedule,
+		StartAt:        def.StartAt,
+		DurationDays:   def.DurationDays,
+		Assignees:      assignees,
+		IsActive:       def.IsActive,
+		NextRunAt:      def.NextRunAt,
+		LastRunAt:      def.LastRunAt,
+		LastTaskID:     def.LastTaskID,
+		LastError:      def.LastError,
+		RunCount:       def.RunCount,
+		CreatedBy:      def.CreatedBy,
+	}
+
+	// รอบถัดไปพร้อมผู้รับผิดชอบตามคิว (ไม่นับการข้ามผู้ใช้ที่ไม่ active)
+	if withUpcoming && def.IsActive && def.NextRunAt != nil && len(def.Assignees) > 0 {
+		sched, err := util.ParseRecurrence(def.Schedule, def.StartAt)
+		if err == nil {
+			preview := *def
+			at := def.NextRunAt
+			for i := 0; i < recurringUpcomingCount && at != nil; i++ {
+				out.Upcoming = append(out.Upcoming, dto.RecurringOccurrenceDTO{
+					RunAt:    *at,
+					Assignee: def.Assignees[(def.NextAssigneeIdx+i)%len(def.Assignees)],
+				})
+				preview.RunCount++
+				at = nextRecurringRun(&preview, sched, *at)
+			}
+		}
+	}
+	return out, nil
+}
+
+// nextRecurringRun รอบถัดไปหลัง after (nil = ครบ COUNT/UNTIL แล้ว)
+func nextRecurringRun(def *models.RecurringTask, sched util.Recurrence, after time.Time) *time.Time {
+	if rr, ok := sched.(*util.RRule); ok && rr.Count > 0 && def.RunCount >= rr.Count {
+		return nil
+	}
+	next := sched.Next(after)
+	if next.IsZero() {
+		return nil
+	}
+	return &next
+}
+
+// parseRecurringStart วันเวลาเริ่ม YYYY-MM-DD หรือ YYYY-MM-DDTHH:mm ตามเวลาไทย (ว่าง = ตอนนี้)
+func parseRecurringStart(v string, loc *time.Location, now time.Time) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return now.In(loc).Truncate(time.Minute), nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04", "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, v, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid start_at: %s (use YYYY-MM-DD or YYYY-MM-DDTHH:mm)", v)
+}
+
+func normalizeRecurringImportance(v string) (string, error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	if v == "" {
+		return "medium", nil
+	}
+	switch v {
+	case "low", "medium", "high":
+		return v, nil
+	}
+	return "", fmt.Errorf("invalid importance: %s (allow: low|medium|high)", v)
+}
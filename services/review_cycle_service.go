@@ -0,0 +1,1289 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/config"
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/pkg/util"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	defaultReviewTaskWeight   = 50
+	defaultReviewReminderDays = 3
+	maxReviewReminderDays     = 30
+	maxReviewItems            = 30
+	maxReviewComment          = 2000
+	reviewReminderHour        = 9 // ส่งเตือนอัตโนมัติตั้งแต่ 09:00 น. (cron รันทุกชั่วโมง)
+)
+
+type reviewCycleService struct {
+	config            config.Config
+	reviewRepo        ports.ReviewCycleRepository
+	kpiEvaluationRepo ports.KPIEvaluationRepository
+	userRepo          ports.UserRepository
+	departmentRepo    ports.DepartmentRepository
+}
+
+func NewReviewCycleService(cfg config.Config, reviewRepo ports.ReviewCycleRepository, kpiEvaluationRepo ports.KPIEvaluationRepository, userRepo ports.UserRepository, departmentRepo ports.DepartmentRepository) ports.ReviewCycleService {
+	return &reviewCycleService{config: cfg, reviewRepo: reviewRepo, kpiEvaluationRepo: kpiEvaluationRepo, userRepo: userRepo, departmentRepo: departmentRepo}
+}
+
+func (s *reviewCycleService) CreateReviewCycle(ctx context.Context, req dto.CreateReviewCycleDTO, claims *dto.JWTClaims) (*dto.ReviewCycleDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrReviewForbidden
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	cycleType, err := normalizeReviewCycleType(req.CycleType)
+	if err != nil {
+		return nil, err
+	}
+	loc := helpers.BangkokLocation()
+	periodStart, err := helpers.ParseDate("period_start", req.PeriodStart, loc)
+	if err != nil {
+		return nil, err
+	}
+	periodEnd, err := helpers.ParseDate("period_end", req.PeriodEnd, loc)
+	if err != nil {
+		return nil, err
+	}
+	openAt := periodEnd.AddDate(0, 0, 1)
+	if strings.TrimSpace(req.OpenAt) != "" {
+		if openAt, err = helpers.ParseDate("open_at", req.OpenAt, loc); err != nil {
+			return nil, err
+		}
+	}
+	closeDate, err := helpers.ParseDate("close_at", req.CloseAt, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	taskWeight := defaultReviewTaskWeight
+	if req.TaskWeight != nil {
+		taskWeight = *req.TaskWeight
+	}
+	reminderDays := defaultReviewReminderDays
+	if req.ReminderDays != nil {
+		reminderDays = *req.ReminderDays
+	}
+	items, err := toReviewItems(req.Items, nil)
+	if err != nil {
+		return nil, err
+	}
+	bands, err := helpers.NormalizeKPIBands(fromKPIBandDTOs(req.Bands))
+	if err != nil {
+		return nil, err
+	}
+	departments, err := s.validateReviewDepartments(ctx, req.Departments)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	cycle := models.ReviewCycle{
+		CycleID:      uuid.NewString(),
+		Name:         name,
+		CycleType:    cycleType,
+		PeriodStart:  periodStart,
+		PeriodEnd:    periodEnd,
+		OpenAt:       openAt,
+		CloseAt:      reviewEndOfDay(closeDate),
+		Departments:  departments,
+		Items:        items,
+		TaskWeight:   taskWeight,
+		Bands:        bands,
+		ReminderDays: reminderDays,
+		Status:       models.ReviewCycleScheduled,
+		CreatedBy:    claims.UserID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := validateReviewCycle(&cycle); err != nil {
+		return nil, err
+	}
+
+	if err := s.reviewRepo.CreateReviewCycle(ctx, cycle); err != nil {
+		return nil, err
+	}
+	return toReviewCycleDTO(&cycle), nil
+}
+
+func (s *reviewCycleService) ListReviewCycles(ctx context.Context, req dto.RequestListReviewCycle, claims *dto.JWTClaims) (dto.Pagination, error) {
+	page, size := req.Page, req.Limit
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = 10
+	}
+	skip := int64((page - 1) * size)
+	limit := int64(size)
+
+	filter := bson.M{"deleted_at": nil}
+	if search := strings.TrimSpace(req.Search); search != "" {
+		filter["name"] = bson.M{"$regex": regexp.QuoteMeta(search), "$options": "i"}
+	}
+	if v := strings.TrimSpace(req.CycleType); v != "" {
+		filter["cycle_type"] = v
+	}
+	if v := strings.TrimSpace(req.Status); v != "" {
+		filter["status"] = v
+	}
+	// รอบที่ยังไม่เปิดเห็นเฉพาะ admin (HR)
+	if claims.Role != "admin" {
+		if req.Status == models.ReviewCycleScheduled {
+			return dto.Pagination{Page: page, Size: size, List: []interface{}{}}, nil
+		}
+		if _, ok := filter["status"]; !ok {
+			filter["status"] = bson.M{"$ne": models.ReviewCycleScheduled}
+		}
+	}
+
+	sortBy := bson.D{
+		{Key: "period_start", Value: -1},
+		{Key: "_id", Value: -1},
+	}
+	items, total, err := s.reviewRepo.GetListReviewCyclesByFilter(ctx, filter, bson.M{}, sortBy, skip, limit)
+	if err != nil {
+		return dto.Pagination{}, fmt.Errorf("list review cycles: %w", err)
+	}
+
+	list := make([]interface{}, 0, len(items))
+	for i := range items {
+		list = append(list, *toReviewCycleDTO(&items[i]))
+	}
+
+	totalPages := 0
+	if total > 0 && size > 0 {
+		totalPages = int((total + int64(size) - 1) / int64(size))
+	}
+
+	return dto.Pagination{
+		Page:       page,
+		Size:       size,
+		TotalCount: int(total),
+		TotalPages: totalPages,
+		List:       list,
+	}, nil
+}
+
+func (s *reviewCycleService) GetReviewCycle(ctx context.Context, cycleID string, claims *dto.JWTClaims) (*dto.ReviewCycleDTO, error) {
+	cycle, err := s.getCycle(ctx, cycleID)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Role != "admin" && cycle.Status == models.ReviewCycleScheduled {
+		return nil, mongo.ErrNoDocuments
+	}
+	return toReviewCycleDTO(cycle), nil
+}
+
+func (s *reviewCycleService) UpdateReviewCycle(ctx context.Context, cycleID string, req dto.UpdateReviewCycleDTO, claims *dto.JWTClaims) (*dto.ReviewCycleDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrReviewForbidden
+	}
+	cycle, err := s.getCycle(ctx, cycleID)
+	if err != nil {
+		return nil, err
+	}
+	if cycle.Status == models.ReviewCycleClosed {
+		return nil, ports.ErrReviewLocked
+	}
+	// รอบที่เปิดแล้วแก้ได้เฉพาะชื่อ วันปิด และการเตือน (แบบประเมินถูกสร้างตามหัวข้อเดิมแล้ว)
+	if cycle.Status == models.ReviewCycleOpen && (req.CycleType != nil || req.PeriodStart != nil || req.PeriodEnd != nil || req.OpenAt != nil ||
+		req.Departments != nil || req.Items != nil || req.TaskWeight != nil || req.Bands != nil) {
+		return nil, fmt.Errorf("%w: only name, close_at and reminder_days can be changed after the cycle is open", ports.ErrReviewLocked)
+	}
+
+	loc := helpers.BangkokLocation()
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, errors.New("name is required")
+		}
+		cycle.Name = name
+	}
+	if req.CycleType != nil {
+		if cycle.CycleType, err = normalizeReviewCycleType(*req.CycleType); err != nil {
+			return nil, err
+		}
+	}
+	if req.PeriodStart != nil {
+		if cycle.PeriodStart, err = helpers.ParseDate("period_start", *req.PeriodStart, loc); err != nil {
+			return nil, err
+		}
+	}
+	if req.PeriodEnd != nil {
+		if cycle.PeriodEnd, err = helpers.ParseDate("period_end", *req.PeriodEnd, loc); err != nil {
+			return nil, err
+		}
+	}
+	if req.OpenAt != nil {
+		if cycle.OpenAt, err = helpers.ParseDate("open_at", *req.OpenAt, loc); err != nil {
+			return nil, err
+		}
+	}
+	if req.CloseAt != nil {
+		closeDate, err := helpers.ParseDate("close_at", *req.CloseAt, loc)
+		if err != nil {
+			return nil, err
+		}
+		cycle.CloseAt = reviewEndOfDay(closeDate)
+	}
+	if req.Departments != nil {
+		if cycle.Departments, err = s.validateReviewDepartments(ctx, *req.Departments); err != nil {
+			return nil, err
+		}
+	}
+	if req.Items != nil {
+		if cycle.Items, err = toReviewItems(*req.Items, cycle.Items); err != nil {
+			return nil, err
+		}
+	}
+	if req.TaskWeight != nil {
+		cycle.TaskWeight = *req.TaskWeight
+	}
+	if req.Bands != nil {
+		if cycle.Bands, err = helpers.NormalizeKPIBands(fromKPIBandDTOs(*req.Bands)); err != nil {
+			return nil, err
+		}
+	}
+	if req.ReminderDays != nil {
+		cycle.ReminderDays = *req.ReminderDays
+	}
+	if err := validateReviewCycle(cycle); err != nil {
+		return nil, err
+	}
+	cycle.UpdatedAt = time.Now()
+
+	updated, err := s.reviewRepo.UpdateReviewCycleByID(ctx, cycleID, *cycle)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	return toReviewCycleDTO(updated), nil
+}
+
+func (s *reviewCycleService) DeleteReviewCycle(ctx context.Context, cycleID string, claims *dto.JWTClaims) error {
+	if claims.Role != "admin" {
+		return ports.ErrReviewForbidden
+	}
+	cycle, err := s.getCycle(ctx, cycleID)
+	if err != nil {
+		return err
+	}
+	// ลบได้เฉพาะรอบที่ยังไม่เปิด ผลประเมินที่เริ่มแล้วต้องเก็บไว้
+	if cycle.Status != models.ReviewCycleScheduled {
+		return fmt.Errorf("%w: only scheduled cycles can be deleted", ports.ErrReviewLocked)
+	}
+	return s.reviewRepo.SoftDeleteReviewCycleByID(ctx, cycleID)
+}
+
+func (s *reviewCycleService) OpenReviewCycle(ctx context.Context, cycleID string, claims *dto.JWTClaims) (*dto.ReviewCycleDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrReviewForbidden
+	}
+	if _, err := s.getCycle(ctx, cycleID); err != nil {
+		return nil, err
+	}
+	cycle, err := s.openCycle(ctx, cycleID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return toReviewCycleDTO(cycle), nil
+}
+
+func (s *reviewCycleService) CloseReviewCycle(ctx context.Context, cycleID string, claims *dto.JWTClaims) (*dto.ReviewCycleDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrReviewForbidden
+	}
+	if _, err := s.getCycle(ctx, cycleID); err != nil {
+		return nil, err
+	}
+	cycle, err := s.reviewRepo.TransitionReviewCycle(ctx, cycleID, models.ReviewCycleOpen, models.ReviewCycleClosed, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if cycle == nil {
+		return nil, fmt.Errorf("%w: cycle is not open", ports.ErrReviewLocked)
+	}
+	return toReviewCycleDTO(cycle), nil
+}
+
+func (s *reviewCycleService) SyncReviewParticipants(ctx context.Context, cycleID string, claims *dto.JWTClaims) (*dto.ReviewSyncResult, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrReviewForbidden
+	}
+	cycle, err := s.getCycle(ctx, cycleID)
+	if err != nil {
+		return nil, err
+	}
+	if cycle.Status != models.ReviewCycleOpen {
+		return nil, fmt.Errorf("%w: cycle is not open", ports.ErrReviewLocked)
+	}
+	return s.syncParticipants(ctx, cycle, time.Now())
+}
+
+func (s *reviewCycleService) GetReviewCycleProgress(ctx context.Context, cycleID string, claims *dto.JWTClaims) (*dto.ReviewCycleProgressDTO, error) {
+	cycle, err := s.getCycle(ctx, cycleID)
+	if err != nil {
+		return nil, err
+	}
+	filter := bson.M{"cycle_id": cycleID}
+	if claims.Role != "admin" {
+		// ผู้จัดการเห็นความคืบหน้าเฉพาะแผนกตัวเอง
+		managed, err := s.managedDepartments(ctx, claims.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if len(managed) == 0 {
+			return nil, ports.ErrReviewForbidden
+		}
+		filter["department_id"] = bson.M{"$in": managed}
+	}
+	reviews, err := s.reviewRepo.GetAllPerformanceReviewsByFilter(ctx, filter, bson.M{
+		"department_id": 1, "status": 1, "self_submitted_at": 1, "manager_submitted_at": 1, "finalized_at": 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := &dto.ReviewCycleProgressDTO{
+		CycleID:     cycle.CycleID,
+		Name:        cycle.Name,
+		Status:      cycle.Status,
+		CloseAt:     cycle.CloseAt,
+		ByStatus:    map[string]int{models.ReviewSelfPending: 0, models.ReviewManagerPending: 0, models.ReviewCalibration: 0, models.ReviewFinalized: 0},
+		Departments: []dto.ReviewDepartmentProgressDTO{},
+	}
+	byDept := make(map[string]*dto.ReviewProgressCountDTO)
+	var deptOrder []string
+	for _, r := range reviews {
+		out.ByStatus[r.Status]++
+		c, ok := byDept[r.DepartmentID]
+		if !ok {
+			c = &dto.ReviewProgressCountDTO{}
+			byDept[r.DepartmentID] = c
+			deptOrder = append(deptOrder, r.DepartmentID)
+		}
+		for _, count := range []*dto.ReviewProgressCountDTO{&out.Overall, c} {
+			count.Total++
+			if r.SelfSubmittedAt != nil {
+				count.SelfSubmitted++
+			}
+			if r.ManagerSubmittedAt != nil {
+				count.ManagerSubmitted++
+			}
+			if r.FinalizedAt != nil {
+				count.Finalized++
+			}
+		}
+	}
+	out.Overall.Percent = reviewProgressPercent(out.Overall)
+
+	names := s.departmentNames(ctx, deptOrder)
+	sort.Strings(deptOrder)
+	for _, id := range deptOrder {
+		c := byDept[id]
+		c.Percent = reviewProgressPercent(*c)
+		out.Departments = append(out.Departments, dto.ReviewDepartmentProgressDTO{
+			DepartmentID:           id,
+			DepartmentName:         names[id],
+			ReviewProgressCountDTO: *c,
+		})
+	}
+	return out, nil
+}
+
+func (s *reviewCycleService) SendReviewReminders(ctx context.Context, cycleID string, claims *dto.JWTClaims) (*dto.ReviewReminderResult, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrReviewForbidden
+	}
+	cycle, err := s.getCycle(ctx, cycleID)
+	if err != nil {
+		return nil, err
+	}
+	if cycle.Status != models.ReviewCycleOpen {
+		return nil, fmt.Errorf("%w: cycle is not open", ports.ErrReviewLocked)
+	}
+	if strings.TrimSpace(s.config.Email.Host) == "" {
+		return nil, errors.New("email is not configured")
+	}
+	return s.sendReminders(ctx, cycle, time.Now())
+}
+
+func (s *reviewCycleService) ListPerformanceReviews(ctx context.Context, cycleID string, req dto.RequestListPerformanceReview, claims *dto.JWTClaims) (dto.Pagination, error) {
+	cycle, err := s.getCycle(ctx, cycleID)
+	if err != nil {
+		return dto.Pagination{}, err
+	}
+	page, size := req.Page, req.Limit
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = 10
+	}
+	skip := int64((page - 1) * size)
+	limit := int64(size)
+
+	filter := bson.M{"cycle_id": cycleID}
+	if v := strings.TrimSpace(req.DepartmentID); v != "" {
+		filter["department_id"] = v
+	}
+	if v := strings.TrimSpace(req.Status); v != "" {
+		filter["status"] = v
+	}
+	// ผู้ใช้ทั่วไปเห็นของตัวเอง คนที่ตัวเองต้องประเมิน และแผนกที่เป็นผู้จัดการ
+	if claims.Role != "admin" {
+		managed, err := s.managedDepartments(ctx, claims.UserID)
+		if err != nil {
+			return dto.Pagination{}, err
+		}
+		filter["$or"] = bson.A{
+			bson.M{"user_id": claims.UserID},
+			bson.M{"manager_id": claims.UserID},
+			bson.M{"department_id": bson.M{"$in": managed}},
+		}
+	}
+
+	sortBy := bson.D{
+		{Key: "department_id", Value: 1},
+		{Key: "created_at", Value: 1},
+		{Key: "_id", Value: 1},
+	}
+	items, total, err := s.reviewRepo.GetListPerformanceReviewsByFilter(ctx, filter, bson.M{}, sortBy, skip, limit)
+	if err != nil {
+		return dto.Pagination{}, fmt.Errorf("list performance reviews: %w", err)
+	}
+
+	list := make([]interface{}, 0, len(items))
+	for i := range items {
+		list = append(list, s.toReviewDTO(ctx, &items[i], cycle, claims))
+	}
+
+	totalPages := 0
+	if total > 0 && size > 0 {
+		totalPages = int((total + int64(size) - 1) / int64(size))
+	}
+
+	return dto.Pagination{
+		Page:       page,
+		Size:       size,
+		TotalCount: int(total),
+		TotalPages: totalPages,
+		List:       list,
+	}, nil
+}
+
+func (s *reviewCycleService) ListMyPerformanceReviews(ctx context.Context, req dto.RequestMyPerformanceReview, claims *dto.JWTClaims) ([]dto.PerformanceReviewDTO, error) {
+	filter := bson.M{"user_id": claims.UserID}
+	switch strings.ToLower(strings.TrimSpace(req.Role)) {
+	case "", "self":
+	case "manager":
+		filter = bson.M{"manager_id": claims.UserID}
+	default:
+		return nil, fmt.Errorf("invalid role: %s (allow: self|manager)", req.Role)
+	}
+	if v := strings.TrimSpace(req.Status); v != "" {
+		filter["status"] = v
+	}
+	reviews, err := s.reviewRepo.GetAllPerformanceReviewsByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	return s.toReviewDTOs(ctx, reviews, claims), nil
+}
+
+func (s *reviewCycleService) GetPerformanceReview(ctx context.Context, reviewID string, claims *dto.JWTClaims) (*dto.PerformanceReviewDTO, error) {
+	review, cycle, err := s.getReview(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.UserID != claims.UserID {
+		if err := s.checkReviewManager(ctx, review, claims); err != nil {
+			return nil, err
+		}
+	}
+	out := s.toReviewDTO(ctx, review, cycle, claims)
+	return &out, nil
+}
+
+func (s *reviewCycleService) SubmitSelfReview(ctx context.Context, reviewID string, req dto.SubmitReviewScoresDTO, claims *dto.JWTClaims) (*dto.PerformanceReviewDTO, error) {
+	review, cycle, err := s.getReview(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.UserID != claims.UserID {
+		return nil, ports.ErrReviewForbidden
+	}
+	if review.FinalizedAt != nil || cycle.Status != models.ReviewCycleOpen {
+		return nil, ports.ErrReviewLocked
+	}
+	scores, err := toReviewScores(cycle.Items, req.Scores)
+	if err != nil {
+		return nil, err
+	}
+	comment, err := reviewComment(req.Comment)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	review.SelfScores = scores
+	review.SelfComment = comment
+	review.SelfPercent = helpers.WeightedKPIPercent(scores)
+	review.SelfSubmittedAt = &now
+	review.Status = reviewStatus(review)
+	review.UpdatedAt = now
+	return s.saveReview(ctx, review, cycle, claims)
+}
+
+func (s *reviewCycleService) SubmitManagerReview(ctx context.Context, reviewID string, req dto.SubmitReviewScoresDTO, claims *dto.JWTClaims) (*dto.PerformanceReviewDTO, error) {
+	review, cycle, err := s.getReview(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.UserID == claims.UserID {
+		return nil, ports.ErrReviewForbidden
+	}
+	if err := s.checkReviewManager(ctx, review, claims); err != nil {
+		return nil, err
+	}
+	// หลังปิดรอบ HR ยังกรอกแทนผู้จัดการได้ก่อนยืนยันผล
+	if review.FinalizedAt != nil || (cycle.Status != models.ReviewCycleOpen && !(claims.Role == "admin" && cycle.Status == models.ReviewCycleClosed)) {
+		return nil, ports.ErrReviewLocked
+	}
+	scores, err := toReviewScores(cycle.Items, req.Scores)
+	if err != nil {
+		return nil, err
+	}
+	comment, err := reviewComment(req.Comment)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	// คะแนน KPI ของงานอาจเปลี่ยนระหว่างรอบ (ประเมินงานเสร็จทีหลัง) คิดใหม่ตอนผู้จัดการส่งผล
+	taskKPI, err := s.taskKPI(ctx, review.UserID, cycle, now)
+	if err != nil {
+		return nil, err
+	}
+	review.TaskKPI = taskKPI
+	review.ManagerScores = scores
+	review.ManagerComment = comment
+	review.ManagerPercent = helpers.WeightedKPIPercent(scores)
+	review.ManagerSubmittedAt = &now
+	review.ManagerSubmittedBy = claims.UserID
+	review.SuggestedScore = suggestedReviewScore(review, cycle.TaskWeight)
+	review.Status = reviewStatus(review)
+	review.UpdatedAt = now
+	return s.saveReview(ctx, review, cycle, claims)
+}
+
+func (s *reviewCycleService) FinalizeReview(ctx context.Context, reviewID string, req dto.FinalizeReviewDTO, claims *dto.JWTClaims) (*dto.PerformanceReviewDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrReviewForbidden
+	}
+	review, cycle, err := s.getReview(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.FinalizedAt != nil || cycle.Status == models.ReviewCycleScheduled {
+		return nil, ports.ErrReviewLocked
+	}
+	if review.ManagerSubmittedAt == nil {
+		return nil, errors.New("manager assessment has not been submitted")
+	}
+
+	final := review.SuggestedScore
+	note := strings.TrimSpace(req.Note)
+	if req.FinalScore != nil {
+		if *req.FinalScore < 0 || *req.FinalScore > 100 {
+			return nil, errors.New("final_score must be between 0 and 100")
+		}
+		final = util.Round2(*req.FinalScore)
+		if final != review.SuggestedScore && note == "" {
+			return nil, errors.New("note is required when final_score differs from suggested_score")
+		}
+	}
+	grade := ""
+	if band, ok := helpers.KPIBandFor(cycle.Bands, final); ok {
+		grade = band.Grade
+	}
+
+	now := time.Now()
+	review.FinalScore = &final
+	review.FinalGrade = grade
+	review.CalibrationNote = note
+	review.FinalizedBy = claims.UserID
+	review.FinalizedAt = &now
+	review.Status = models.ReviewFinalized
+	review.UpdatedAt = now
+	return s.saveReview(ctx, review, cycle, claims)
+}
+
+func (s *reviewCycleService) ListReviewResults(ctx context.Context, req dto.RequestReviewResults, claims *dto.JWTClaims) ([]dto.PerformanceReviewDTO, error) {
+	userID := strings.TrimSpace(req.UserID)
+	if userID == "" {
+		userID = claims.UserID
+	}
+	if userID != claims.UserID && claims.Role != "admin" {
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, mongo.ErrNoDocuments
+		}
+		managed, err := s.managedDepartments(ctx, claims.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !helpers.InSet(user.DepartmentID, managed...) {
+			return nil, ports.ErrReviewForbidden
+		}
+	}
+	reviews, err := s.reviewRepo.GetAllPerformanceReviewsByFilter(ctx, bson.M{"user_id": userID, "finalized_at": bson.M{"$ne": nil}}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	return s.toReviewDTOs(ctx, reviews, claims), nil
+}
+
+// RunDue เปิดรอบที่ถึงวันเปิด ปิดรอบที่เลยวันปิด และส่งเตือนวันละครั้ง (หลัง 09:00 น.) ในช่วง reminder_days ก่อนปิดรอบ
+func (s *reviewCycleService) RunDue(ctx context.Context, now time.Time) (*dto.ReviewCycleRunResult, error) {
+	start := time.Now()
+	result := &dto.ReviewCycleRunResult{
+		RunAt:     now,
+		Opened:    []string{},
+		Closed:    []string{},
+		Reminders: []dto.ReviewReminderResult{},
+		Errors:    []string{},
+	}
+
+	due, err := s.reviewRepo.GetAllReviewCyclesByFilter(ctx, bson.M{
+		"status":     models.ReviewCycleScheduled,
+		"open_at":    bson.M{"$lte": now},
+		"deleted_at": nil,
+	}, bson.M{"cycle_id": 1, "name": 1})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range due {
+		opened, err := s.openCycle(ctx, c.CycleID, now)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", c.Name, err))
+			continue
+		}
+		result.Opened = append(result.Opened, opened.Name)
+	}
+
+	open, err := s.reviewRepo.GetAllReviewCyclesByFilter(ctx, bson.M{"status": models.ReviewCycleOpen, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	loc := helpers.BangkokLocation()
+	today := now.In(loc).Format("2006-01-02")
+	emailReady := strings.TrimSpace(s.config.Email.Host) != ""
+	for _, c := range open {
+		if !c.CloseAt.After(now) {
+			closed, err := s.reviewRepo.TransitionReviewCycle(ctx, c.CycleID, models.ReviewCycleOpen, models.ReviewCycleClosed, now)
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", c.Name, err))
+				continue
+			}
+			if closed != nil {
+				result.Closed = append(result.Closed, closed.Name)
+			}
+			continue
+		}
+		if !emailReady || c.ReminderDays <= 0 || now.In(loc).Hour() < reviewReminderHour || now.Before(c.CloseAt.AddDate(0, 0, -c.ReminderDays)) {
+			continue
+		}
+		if c.RemindedAt != nil && c.RemindedAt.In(loc).Format("2006-01-02") == today {
+			continue
+		}
+		reminded, err := s.sendReminders(ctx, c, now)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", c.Name, err))
+			continue
+		}
+		result.Reminders = append(result.Reminders, *reminded)
+	}
+
+	result.DurationMS = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+// openCycle เปลี่ยนเป็น open แบบมีเงื่อนไขแล้วสร้างแบบประเมินของผู้เข้าร่วม
+func (s *reviewCycleService) openCycle(ctx context.Context, cycleID string, now time.Time) (*models.ReviewCycle, error) {
+	cycle, err := s.reviewRepo.TransitionReviewCycle(ctx, cycleID, models.ReviewCycleScheduled, models.ReviewCycleOpen, now)
+	if err != nil {
+		return nil, err
+	}
+	if cycle == nil {
+		return nil, fmt.Errorf("%w: cycle is not scheduled", ports.ErrReviewLocked)
+	}
+	synced, err := s.syncParticipants(ctx, cycle, now)
+	if err != nil {
+		return nil, err
+	}
+	cycle.Participants = synced.Participants
+	return cycle, nil
+}
+
+// syncParticipants สร้างแบบประเมินให้พนักงานที่อนุมัติแล้วในแผนกที่เข้าร่วม (คนที่มีอยู่แล้วไม่ถูกแตะ)
+// ผู้จัดการแผนกถูกประเมินโดย HR (manager_id ว่าง)
+func (s *reviewCycleService) syncParticipants(ctx context.Context, cycle *models.ReviewCycle, now time.Time) (*dto.ReviewSyncResult, error) {
+	deptFilter := bson.M{"deleted_at": nil}
+	userFilter := bson.M{"status": "approved", "deleted_at": nil, "department_id": bson.M{"$nin": bson.A{"", nil}}}
+	if len(cycle.Departments) > 0 {
+		deptFilter["department_id"] = bson.M{"$in": cycle.Departments}
+		userFilter["department_id"] = bson.M{"$in": cycle.Departments}
+	}
+	depts, err := s.departmentRepo.GetAllDepartmentByFilter(ctx, deptFilter, bson.M{"department_id": 1, "manager_id": 1})
+	if err != nil {
+		return nil, err
+	}
+	managers := make(map[string]string, len(depts))
+	for _, d := range depts {
+		managers[d.DepartmentID] = d.ManagerID
+	}
+	users, err := s.userRepo.GetUserByFilter(ctx, userFilter, bson.M{"user_id": 1, "department_id": 1})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.ReviewSyncResult{}
+	for _, u := range users {
+		managerID, ok := managers[u.DepartmentID]
+		if !ok {
+			continue
+		}
+		if managerID == u.UserID {
+			managerID = ""
+		}
+		taskKPI, err := s.taskKPI(ctx, u.UserID, cycle, now)
+		if err != nil {
+			return nil, err
+		}
+		review := models.PerformanceReview{
+			ReviewID:      uuid.NewString(),
+			CycleID:       cycle.CycleID,
+			UserID:        u.UserID,
+			DepartmentID:  u.DepartmentID,
+			ManagerID:     managerID,
+			Status:        models.ReviewSelfPending,
+			TaskKPI:       taskKPI,
+			SelfScores:    []models.KPIScore{},
+			ManagerScores: []models.KPIScore{},
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		inserted, err := s.reviewRepo.UpsertPerformanceReview(ctx, review)
+		if err != nil {
+			return nil, err
+		}
+		if inserted {
+			result.Added++
+		}
+	}
+
+	total, err := s.reviewRepo.CountPerformanceReviews(ctx, bson.M{"cycle_id": cycle.CycleID})
+	if err != nil {
+		return nil, err
+	}
+	result.Participants = int(total)
+	if err := s.reviewRepo.SetReviewCycleParticipants(ctx, cycle.CycleID, result.Participants, now); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// taskKPI คะแนนเฉลี่ยของการประเมิน KPI รายงานที่ประเมินเสร็จในช่วงของรอบ
+func (s *reviewCycleService) taskKPI(ctx context.Context, userID string, cycle *models.ReviewCycle, now time.Time) (models.ReviewTaskKPI, error) {
+	evaluations, err := s.kpiEvaluationRepo.GetAllKPIEvaluationByFilter(ctx, bson.M{
+		"evaluatee_id": userID,
+		"is_evaluated": true,
+		"deleted_at":   nil,
+		"updated_at":   bson.M{"$gte": cycle.PeriodStart, "$lt": cycle.PeriodEnd.AddDate(0, 0, 1)},
+	}, bson.M{"_id": 0, "total_score": 1, "percent": 1, "grade": 1})
+	if err != nil {
+		return models.ReviewTaskKPI{}, err
+	}
+	results := make([]helpers.KPIResult, 0, len(evaluations))
+	for _, ev := range evaluations {
+		results = append(results, helpers.KPIResult{Total: ev.TotalScore, Percent: ev.Percent, Grade: ev.Grade})
+	}
+	return models.ReviewTaskKPI{
+		Evaluations:  len(evaluations),
+		Score:        helpers.KPIFromScores(results),
+		CalculatedAt: now,
+	}, nil
+}
+
+// sendReminders เตือนพนักงานที่ยังไม่ประเมินตนเอง และผู้จัดการที่ยังประเมินลูกทีมไม่ครบ (คนละหนึ่งฉบับ)
+func (s *reviewCycleService) sendReminders(ctx context.Context, cycle *models.ReviewCycle, now time.Time) (*dto.ReviewReminderResult, error) {
+	result := &dto.ReviewReminderResult{CycleID: cycle.CycleID}
+	reviews, err := s.reviewRepo.GetAllPerformanceReviewsByFilter(ctx, bson.M{
+		"cycle_id":             cycle.CycleID,
+		"finalized_at":         nil,
+		"manager_submitted_at": nil,
+	}, bson.M{"user_id": 1, "manager_id": 1, "self_submitted_at": 1})
+	if err != nil {
+		return nil, err
+	}
+
+	emailCfg := util.EmailConfig{
+		Host:     s.config.Email.Host,
+		Port:     s.config.Email.Port,
+		Username: s.config.Email.Username,
+		Password: s.config.Email.Password,
+		From:     s.config.Email.From,
+	}
+	closeAt := cycle.CloseAt.In(helpers.BangkokLocation()).Format("02/01/2006")
+
+	pendingByManager := make(map[string]int)
+	var managerOrder []string
+	for _, r := range reviews {
+		if r.ManagerID != "" {
+			if _, ok := pendingByManager[r.ManagerID]; !ok {
+				managerOrder = append(managerOrder, r.ManagerID)
+			}
+			pendingByManager[r.ManagerID]++
+		}
+		if r.SelfSubmittedAt != nil {
+			continue
+		}
+		subject := fmt.Sprintf("Self-assessment reminder: %s", cycle.Name)
+		body := fmt.Sprintf("กรุณาประเมินตนเองสำหรับรอบ \"%s\" ภายในวันที่ %s\n\nReview ID: %s\n", cycle.Name, closeAt, r.ReviewID)
+		if s.remind(ctx, emailCfg, r.UserID, subject, body) {
+			result.Employees++
+		} else {
+			result.Failed++
+		}
+	}
+	for _, managerID := range managerOrder {
+		subject := fmt.Sprintf("Performance review reminder: %s", cycle.Name)
+		body := fmt.Sprintf("มีพนักงาน %d คนที่รอการประเมินจากคุณในรอบ \"%s\" กรุณาประเมินภายในวันที่ %s\n", pendingByManager[managerID], cycle.Name, closeAt)
+		if s.remind(ctx, emailCfg, managerID, subject, body) {
+			result.Managers++
+		} else {
+			result.Failed++
+		}
+	}
+
+	if err := s.reviewRepo.SetReviewCycleReminded(ctx, cycle.CycleID, now); err != nil {
+		log.Println("Error saving review reminder time:", err)
+	}
+	return result, nil
+}
+
+func (s *reviewCycleService) remind(ctx context.Context, emailCfg util.EmailConfig, userID, subject, body string) bool {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil || strings.TrimSpace(user.Email) == "" {
+		return false
+	}
+	if err := util.SendMail(emailCfg, user.Email, subject, fmt.Sprintf("เรียนคุณ %s\n\n%s", user.FirstNameTH, body)); err != nil {
+		log.Println("Error sending review reminder email:", err)
+		return false
+	}
+	return true
+}
+
+func (s *reviewCycleService) saveReview(ctx context.Context, review *models.PerformanceReview, cycle *models.ReviewCycle, claims *dto.JWTClaims) (*dto.PerformanceReviewDTO, error) {
+	updated, err := s.reviewRepo.UpdatePerformanceReviewByID(ctx, review.ReviewID, *review)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		// ถูกยืนยันผลไประหว่างทำรายการ
+		return nil, ports.ErrReviewLocked
+	}
+	out := s.toReviewDTO(ctx, updated, cycle, claims)
+	return &out, nil
+}
+
+func (s *reviewCycleService) getCycle(ctx context.Context, cycleID string) (*models.ReviewCycle, error) {
+	cycle, err := s.reviewRepo.GetOneReviewCycleByFilter(ctx, bson.M{"cycle_id": cycleID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if cycle == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	return cycle, nil
+}
+
+func (s *reviewCycleService) getReview(ctx context.Context, reviewID string) (*models.PerformanceReview, *models.ReviewCycle, error) {
+	review, err := s.reviewRepo.GetOnePerformanceReviewByFilter(ctx, bson.M{"review_id": reviewID}, bson.M{})
+	if err != nil {
+		return nil, nil, err
+	}
+	if review == nil {
+		return nil, nil, mongo.ErrNoDocuments
+	}
+	cycle, err := s.getCycle(ctx, review.CycleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return review, cycle, nil
+}
+
+// checkReviewManager admin (HR) ผู้จัดการที่บันทึกไว้ตอนเปิดรอบ หรือผู้จัดการแผนกปัจจุบัน
+func (s *reviewCycleService) checkReviewManager(ctx context.Context, review *models.PerformanceReview, claims *dto.JWTClaims) error {
+	if claims.Role == "admin" || (review.ManagerID != "" && review.ManagerID == claims.UserID) {
+		return nil
+	}
+	dept, err := s.departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{"department_id": review.DepartmentID, "deleted_at": nil}, bson.M{"manager_id": 1})
+	if err != nil && err != mongo.ErrNoDocuments {
+		return err
+	}
+	if dept != nil && dept.ManagerID == claims.UserID && review.UserID != claims.UserID {
+		return nil
+	}
+	return ports.ErrReviewForbidden
+}
+
+func (s *reviewCycleService) managedDepartments(ctx context.Context, userID string) ([]string, error) {
+	depts, err := s.departmentRepo.GetAllDepartmentByFilter(ctx, bson.M{"manager_id": userID, "deleted_at": nil}, bson.M{"department_id": 1})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(depts))
+	for _, d := range depts {
+		out = append(out, d.DepartmentID)
+	}
+	return out, nil
+}
+
+func (s *reviewCycleService) validateReviewDepartments(ctx context.Context, ids []string) ([]string, error) {
+	departments := make([]string, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		departments = append(departments, id)
+	}
+	if len(departments) == 0 {
+		return departments, nil
+	}
+	depts, err := s.departmentRepo.GetAllDepartmentByFilter(ctx, bson.M{"department_id": bson.M{"$in": departments}, "deleted_at": nil}, bson.M{"department_id": 1})
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]bool, len(depts))
+	for _, d := range depts {
+		found[d.DepartmentID] = true
+	}
+	for _, id := range departments {
+		if !found[id] {
+			return nil, fmt.Errorf("department %s not found", id)
+		}
+	}
+	return departments, nil
+}
+
+func (s *reviewCycleService) departmentNames(ctx context.Context, ids []string) map[string]string {
+	names := make(map[string]string, len(ids))
+	if len(ids) == 0 {
+		return names
+	}
+	depts, err := s.departmentRepo.GetAllDepartmentByFilter(ctx, bson.M{"department_id": bson.M{"$in": ids}}, bson.M{"department_id": 1, "department_name": 1})
+	if err != nil {
+		return names
+	}
+	for _, d := range depts {
+		names[d.DepartmentID] = d.DepartmentName
+	}
+	return names
+}
+
+func (s *reviewCycleService) userName(ctx context.Context, userID string) string {
+	if userID == "" {
+		return ""
+	}
+	user, _ := s.userRepo.GetByID(ctx, userID)
+	if user == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s %s %s", user.TitleTH, user.FirstNameTH, user.LastNameTH)
+}
+
+func (s *reviewCycleService) toReviewDTOs(ctx context.Context, reviews []*models.PerformanceReview, claims *dto.JWTClaims) []dto.PerformanceReviewDTO {
+	cycles := make(map[string]*models.ReviewCycle)
+	out := make([]dto.PerformanceReviewDTO, 0, len(reviews))
+	for _, r := range reviews {
+		cycle, ok := cycles[r.CycleID]
+		if !ok {
+			cycle, _ = s.getCycle(ctx, r.CycleID)
+			cycles[r.CycleID] = cycle
+		}
+		if cycle == nil {
+			continue
+		}
+		out = append(out, s.toReviewDTO(ctx, r, cycle, claims))
+	}
+	return out
+}
+
+// toReviewDTO พนักงานเห็นคะแนน/ความเห็นของผู้จัดการหลังยืนยันผลแล้วเท่านั้น
+func (s *reviewCycleService) toReviewDTO(ctx context.Context, r *models.PerformanceReview, cycle *models.ReviewCycle, claims *dto.JWTClaims) dto.PerformanceReviewDTO {
+	out := dto.PerformanceReviewDTO{
+		CreatedAt:       r.CreatedAt,
+		UpdatedAt:       r.UpdatedAt,
+		ReviewID:        r.ReviewID,
+		CycleID:         r.CycleID,
+		CycleName:       cycle.Name,
+		CycleStatus:     cycle.Status,
+		UserID:          r.UserID,
+		UserName:        s.userName(ctx, r.UserID),
+		DepartmentID:    r.DepartmentID,
+		DepartmentName:  s.departmentNames(ctx, []string{r.DepartmentID})[r.DepartmentID],
+		ManagerID:       r.ManagerID,
+		ManagerName:     s.userName(ctx, r.ManagerID),
+		Status:          r.Status,
+		TaskKPI:         dto.ReviewTaskKPIDTO{Evaluations: r.TaskKPI.Evaluations, Score: r.TaskKPI.Score, CalculatedAt: r.TaskKPI.CalculatedAt},
+		SelfScores:      toKPIScoreResponses(r.SelfScores),
+		SelfComment:     r.SelfComment,
+		SelfPercent:     r.SelfPercent,
+		SelfSubmittedAt: r.SelfSubmittedAt,
+		FinalScore:      r.FinalScore,
+		FinalGrade:      r.FinalGrade,
+		CalibrationNote: r.CalibrationNote,
+		FinalizedBy:     r.FinalizedBy,
+		FinalizedAt:     r.FinalizedAt,
+	}
+	if r.UserID != claims.UserID || r.FinalizedAt != nil {
+		out.ManagerScores = toKPIScoreResponses(r.ManagerScores)
+		out.ManagerComment = r.ManagerComment
+		out.ManagerPercent = r.ManagerPercent
+		out.ManagerSubmittedAt = r.ManagerSubmittedAt
+		out.SuggestedScore = r.SuggestedScore
+	}
+	return out
+}
+
+func toReviewCycleDTO(c *models.ReviewCycle) *dto.ReviewCycleDTO {
+	items := make([]dto.ReviewItemDTO, 0, len(c.Items))
+	for _, it := range c.Items {
+		items = append(items, dto.ReviewItemDTO{
+			ItemID:      it.ItemID,
+			Name:        it.Name,
+			Description: it.Description,
+			Category:    it.Category,
+			MaxScore:    it.MaxScore,
+			Weight:      it.Weight,
+		})
+	}
+	departments := c.Departments
+	if departments == nil {
+		departments = []string{}
+	}
+	return &dto.ReviewCycleDTO{
+		CreatedAt:    c.CreatedAt,
+		UpdatedAt:    c.UpdatedAt,
+		CycleID:      c.CycleID,
+		Name:         c.Name,
+		CycleType:    c.CycleType,
+		PeriodStart:  c.PeriodStart,
+		PeriodEnd:    c.PeriodEnd,
+		OpenAt:       c.OpenAt,
+		CloseAt:      c.CloseAt,
+		Departments:  departments,
+		Items:        items,
+		TaskWeight:   c.TaskWeight,
+		Bands:        toKPIBandDTOs(c.Bands),
+		ReminderDays: c.ReminderDays,
+		Status:       c.Status,
+		Participants: c.Participants,
+		OpenedAt:     c.OpenedAt,
+		ClosedAt:     c.ClosedAt,
+		RemindedAt:   c.RemindedAt,
+		CreatedBy:    c.CreatedBy,
+	}
+}
+
+func toKPIScoreResponses(scores []models.KPIScore) []dto.KPIScoreResponse {
+	out := make([]dto.KPIScoreResponse, 0, len(scores))
+	for _, sc := range scores {
+		out = append(out, dto.KPIScoreResponse{
+			ItemID:   sc.ItemID,
+			Name:     sc.Name,
+			Category: sc.Category,
+			Weight:   sc.Weight,
+			MaxScore: sc.MaxScore,
+			Score:    sc.Score,
+			Notes:    sc.Notes,
+		})
+	}
+	return out
+}
+
+// toReviewItems ตรวจหัวข้อประเมิน (weight รวม = 100) คง item_id เดิมเมื่อส่งมา
+func toReviewItems(items []dto.ReviewItemDTO, existing []models.ReviewItem) ([]models.ReviewItem, error) {
+	if len(items) == 0 {
+		return nil, errors.New("at least one review item is required")
+	}
+	if len(items) > maxReviewItems {
+		return nil, fmt.Errorf("items cannot exceed %d", maxReviewItems)
+	}
+	known := make(map[string]bool, len(existing))
+	for _, it := range existing {
+		known[it.ItemID] = true
+	}
+	out := make([]models.ReviewItem, 0, len(items))
+	total := 0
+	for i, it := range items {
+		name := strings.TrimSpace(it.Name)
+		if name == "" {
+			return nil, fmt.Errorf("items[%d].name is required", i)
+		}
+		if it.MaxScore <= 0 {
+			return nil, fmt.Errorf("items[%d].max_score must be greater than 0", i)
+		}
+		if it.Weight <= 0 {
+			return nil, fmt.Errorf("items[%d].weight must be greater than 0", i)
+		}
+		itemID := strings.TrimSpace(it.ItemID)
+		if itemID == "" || !known[itemID] {
+			itemID = uuid.NewString()
+		}
+		total += it.Weight
+		out = append(out, models.ReviewItem{
+			ItemID:      itemID,
+			Name:        name,
+			Description: strings.TrimSpace(it.Description),
+			Category:    strings.TrimSpace(it.Category),
+			MaxScore:    it.MaxScore,
+			Weight:      it.Weight,
+		})
+	}
+	if total != 100 {
+		return nil, fmt.Errorf("total weight of items must be 100 (got %d)", total)
+	}
+	return out, nil
+}
+
+// toReviewScores คะแนนต้องครบทุกหัวข้อ ปัดเป็นจำนวนเต็มและไม่เกินคะแนนเต็ม
+func toReviewScores(items []models.ReviewItem, req []dto.KPIScoreRequest) ([]models.KPIScore, error) {
+	reqMap := make(map[string]dto.KPIScoreRequest, len(req))
+	for _, r := range req {
+		if id := strings.TrimSpace(r.ItemID); id != "" {
+			reqMap[id] = r
+		}
+	}
+	scores := make([]models.KPIScore, 0, len(items))
+	for _, it := range items {
+		r, ok := reqMap[it.ItemID]
+		if !ok {
+			return nil, fmt.Errorf("score for item %q is required", it.Name)
+		}
+		score := int(math.Round(r.Score))
+		if score < 0 {
+			score = 0
+		}
+		if score > it.MaxScore {
+			score = it.MaxScore
+		}
+		scores = append(scores, models.KPIScore{
+			ItemID:   it.ItemID,
+			Name:     it.Name,
+			Category: it.Category,
+			Notes:    strings.TrimSpace(r.Notes),
+			Weight:   it.Weight,
+			MaxScore: it.MaxScore,
+			Score:    score,
+		})
+	}
+	return scores, nil
+}
+
+func validateReviewCycle(c *models.ReviewCycle) error {
+	if c.PeriodEnd.Before(c.PeriodStart) {
+		return errors.New("period_end must not be before period_start")
+	}
+	if !c.CloseAt.After(c.OpenAt) {
+		return errors.New("close_at must be after open_at")
+	}
+	if c.TaskWeight < 0 || c.TaskWeight > 100 {
+		return errors.New("task_weight must be between 0 and 100")
+	}
+	if c.ReminderDays < 0 || c.ReminderDays > maxReviewReminderDays {
+		return fmt.Errorf("reminder_days must be between 0 and %d", maxReviewReminderDays)
+	}
+	return nil
+}
+
+// suggestedReviewScore ถ่วงคะแนน KPI ของงานกับคะแนนผู้จัดการ (ไม่มีการประเมินงานในช่วง = ใช้คะแนนผู้จัดการทั้งหมด)
+func suggestedReviewScore(r *models.PerformanceReview, taskWeight int) float64 {
+	if r.TaskKPI.Evaluations == 0 {
+		return util.Round2(r.ManagerPercent)
+	}
+	tw := float64(taskWeight)
+	return util.Round2((tw*r.TaskKPI.Score + (100-tw)*r.ManagerPercent) / 100)
+}
+
+func reviewStatus(r *models.PerformanceReview) string {
+	switch {
+	case r.FinalizedAt != nil:
+		return models.ReviewFinalized
+	case r.ManagerSubmittedAt != nil:
+		return models.ReviewCalibration
+	case r.SelfSubmittedAt != nil:
+		return models.ReviewManagerPending
+	}
+	return models.ReviewSelfPending
+}
+
+func reviewProgressPercent(c dto.ReviewProgressCountDTO) float64 {
+	if c.Total == 0 {
+		return 0
+	}
+	return util.Round2(float64(c.Finalized) / float64(c.Total) * 100)
+}
+
+func reviewComment(v string) (string, error) {
+	v = strings.TrimSpace(v)
+	if len([]rune(v)) > maxReviewComment {
+		return "", fmt.Errorf("comment cannot exceed %d characters", maxReviewComment)
+	}
+	return v, nil
+}
+
+func normalizeReviewCycleType(v string) (string, error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	switch v {
+	case "quarterly", "annual":
+		return v, nil
+	}
+	return "", fmt.Errorf("invalid cycle_type: %s (allow: quarterly|annual)", v)
+}
+
+// reviewEndOfDay สิ้นวัน (รับการประเมินได้ทั้งวันปิด)
+func reviewEndOfDay(t time.Time) time.Time {
+	return t.AddDate(0, 0, 1).Add(-time.Second)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
+	"math"
 	"regexp"
 	"strings"
 	"time"
@@ -11,6 +13,7 @@ import (
 	"github.com/Be2Bag/erp-demo/config"
 	"github.com/Be2Bag/erp-demo/dto"
 	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
 	"github.com/Be2Bag/erp-demo/pkg/util"
 	"github.com/Be2Bag/erp-demo/ports"
 	"github.com/google/uuid"
@@ -20,16 +23,21 @@ import (
 )
 
 type signJobService struct {
-	signJobRepo    ports.SignJobRepository
-	dropDownRepo   ports.DropDownRepository
-	taskRepo       ports.TaskRepository
-	incomeRepo     ports.InComeRepository
-	receivableRepo ports.ReceivableRepository
-	config         config.Config
+	signJobRepo          ports.SignJobRepository
+	dropDownRepo         ports.DropDownRepository
+	taskRepo             ports.TaskRepository
+	incomeRepo           ports.InComeRepository
+	receivableRepo       ports.ReceivableRepository
+	payableRepo          ports.PayableRepository
+	workflowRepo         ports.WorkFlowRepository
+	userRepo             ports.UserRepository
+	signTypeWorkflowRepo ports.SignTypeWorkflowRepository
+	capacityRepo         ports.CapacityRepository
+	config               config.Config
 }
 
-func NewSignJobService(cfg config.Config, signJobRepo ports.SignJobRepository, dropDownRepo ports.DropDownRepository, taskRepo ports.TaskRepository, incomeRepo ports.InComeRepository, receivableRepo ports.ReceivableRepository) ports.SignJobService {
-	return &signJobService{config: cfg, signJobRepo: signJobRepo, dropDownRepo: dropDownRepo, taskRepo: taskRepo, incomeRepo: incomeRepo, receivableRepo: receivableRepo}
+func NewSignJobService(cfg config.Config, signJobRepo ports.SignJobRepository, dropDownRepo ports.DropDownRepository, taskRepo ports.TaskRepository, incomeRepo ports.InComeRepository, receivableRepo ports.ReceivableRepository, payableRepo ports.PayableRepository, workflowRepo ports.WorkFlowRepository, userRepo ports.UserRepository, signTypeWorkflowRepo ports.SignTypeWorkflowRepository, capacityRepo ports.CapacityRepository) ports.SignJobService {
+	return &signJobService{config: cfg, signJobRepo: signJobRepo, dropDownRepo: dropDownRepo, taskRepo: taskRepo, incomeRepo: incomeRepo, receivableRepo: receivableRepo, payableRepo: payableRepo, workflowRepo: workflowRepo, userRepo: userRepo, signTypeWorkflowRepo: signTypeWorkflowRepo, capacityRepo: capacityRepo}
 }
 
 func (s *signJobService) CreateSignJob(ctx context.Context, signJob dto.CreateSignJobDTO, claims *dto.JWTClaims) error {
@@ -93,15 +101,34 @@ func (s *signJobService) CreateSignJob(ctx context.Context, signJob dto.CreateSi
 		UpdatedAt: now,
 	}
 
+	// ตรวจ mapping ประเภทป้าย -> workflow ก่อนบันทึก ไม่ให้เหลืองานที่สร้าง task ไม่ได้
+	if !signJob.WaitConfirm {
+		if _, _, err := s.resolveProductionPlans(ctx, &model); err != nil {
+			return err
+		}
+	}
+
 	if err := s.signJobRepo.CreateSignJob(ctx, model); err != nil {
 		return err
 	}
 
 	// ถ้า WaitPrice = true หรือ WaitConfirm = true ให้ข้ามการทำงานเกี่ยวกับระบบบัญชีทั้งหมด (Income, Receivable)
-	if signJob.WaitPrice || signJob.WaitConfirm {
-		return nil
+	if !signJob.WaitPrice && !signJob.WaitConfirm {
+		if err := s.createSignJobAccounting(ctx, signJob, model.JobID, now, claims); err != nil {
+			return err
+		}
 	}
 
+	// งานที่ไม่ต้องรอยืนยัน สร้าง task การผลิตจาก workflow ของประเภทป้ายหลังลงบัญชีแล้ว
+	if !signJob.WaitConfirm {
+		s.generateProductionTasksOrLog(ctx, &model, claims)
+	}
+
+	return nil
+}
+
+// createSignJobAccounting สร้าง Income/Receivable ของงานป้ายที่สร้างใหม่
+func (s *signJobService) createSignJobAccounting(ctx context.Context, signJob dto.CreateSignJobDTO, jobID string, now time.Time, claims *dto.JWTClaims) error {
 	jobName := signJob.JobName
 	nowUTC := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
 
@@ -121,6 +148,7 @@ func (s *signJobService) CreateSignJob(ctx context.Context, signJob dto.CreateSi
 				PaymentMethod:         signJob.PaymentMethod,
 				ReferenceNo:           "", // เพิ่มเลขใบเสร็จ / หมายเลขธุรกรรมธนาคาร
 				Note:                  &jobName,
+				JobID:                 jobID,
 				CreatedBy:             claims.UserID,
 				CreatedAt:             now,
 				UpdatedAt:             now,
@@ -168,7 +196,7 @@ func (s *signJobService) CreateSignJob(ctx context.Context, signJob dto.CreateSi
 				CreatedAt:    now,
 				UpdatedAt:    now,
 				Note:         jobName,
-				JobID:        model.JobID,
+				JobID:        jobID,
 			}
 
 			if err := s.receivableRepo.CreateReceivable(ctx, modelReceivable); err != nil {
@@ -188,6 +216,7 @@ func (s *signJobService) CreateSignJob(ctx context.Context, signJob dto.CreateSi
 					PaymentMethod:         signJob.PaymentMethod,
 					ReferenceNo:           invoiceNo,
 					Note:                  &jobName,
+					JobID:                 jobID,
 					CreatedBy:             claims.UserID,
 					CreatedAt:             now,
 					UpdatedAt:             now,
@@ -238,7 +267,7 @@ func (s *signJobService) CreateSignJob(ctx context.Context, signJob dto.CreateSi
 			CreatedAt:    now,
 			UpdatedAt:    now,
 			Note:         jobName,
-			JobID:        model.JobID,
+			JobID:        jobID,
 		}
 
 		if err := s.receivableRepo.CreateReceivable(ctx, modelReceivable); err != nil {
@@ -447,6 +476,10 @@ func (s *signJobService) GetSignJobByJobID(ctx context.Context, jobID string, cl
 		CreatedAt: m.CreatedAt,
 		UpdatedAt: m.UpdatedAt,
 		DeletedAt: m.DeletedAt,
+		// ---------- การยกเลิก ----------
+		CancelledAt:  m.CancelledAt,
+		CancelledBy:  m.CancelledBy,
+		CancelReason: m.CancelReason,
 	}
 	return dtoObj, nil
 }
@@ -463,6 +496,9 @@ func (s *signJobService) UpdateSignJobByJobID(ctx context.Context, jobID string,
 	if existing == nil {
 		return mongo.ErrNoDocuments
 	}
+	if existing.Status == "cancelled" {
+		return fmt.Errorf("งานนี้ถูกยกเลิกแล้ว ไม่สามารถแก้ไขได้")
+	}
 
 	// เก็บสถานะ WaitPrice เดิมไว้ เพื่อตรวจสอบว่าเปลี่ยนจากรอราคาเป็นมีราคาหรือไม่
 	wasWaitingForPrice := existing.WaitPrice
@@ -597,6 +633,7 @@ func (s *signJobService) UpdateSignJobByJobID(ctx context.Context, jobID string,
 					PaymentMethod:         existing.PaymentMethod,
 					ReferenceNo:           "",
 					Note:                  &jobName,
+					JobID:                 existing.JobID,
 					CreatedBy:             claims.UserID,
 					CreatedAt:             now,
 					UpdatedAt:             now,
@@ -663,6 +700,7 @@ func (s *signJobService) UpdateSignJobByJobID(ctx context.Context, jobID string,
 						PaymentMethod:         existing.PaymentMethod,
 						ReferenceNo:           invoiceNo,
 						Note:                  &jobName,
+						JobID:                 existing.JobID,
 						CreatedBy:             claims.UserID,
 						CreatedAt:             now,
 						UpdatedAt:             now,
@@ -829,8 +867,15 @@ func (s *signJobService) UpdateSignJobByJobID(ctx context.Context, jobID string,
 		}
 	}
 
-	// อัพเดท Income ที่เกี่ยวข้อง (โดยใช้ note เป็น job_name เดิม)
-	filterIncome := bson.M{"note": oldJobName, "deleted_at": nil}
+	// อัพเดท Income ที่เกี่ยวข้อง (ผูกด้วย job_id, รายการเก่าที่ยังไม่มี job_id ใช้ note เป็น job_name เดิม)
+	filterIncome := bson.M{
+		"deleted_at":  nil,
+		"reversal_of": bson.M{"$exists": false},
+		"$or": []bson.M{
+			{"job_id": existing.JobID},
+			{"job_id": bson.M{"$exists": false}, "note": oldJobName},
+		},
+	}
 	incomes, errOnGetIncomes := s.incomeRepo.GetAllInComeByFilter(ctx, filterIncome, nil)
 	if errOnGetIncomes != nil && !errors.Is(errOnGetIncomes, mongo.ErrNoDocuments) {
 		return errOnGetIncomes
@@ -922,11 +967,20 @@ func (s *signJobService) ConfirmSignJob(ctx context.Context, jobID string, claim
 		return mongo.ErrNoDocuments
 	}
 
+	if existing.Status == "cancelled" {
+		return fmt.Errorf("งานนี้ถูกยกเลิกแล้ว")
+	}
+
 	// ตรวจสอบว่างานนี้อยู่ในสถานะรอยืนยันหรือไม่
 	if !existing.WaitConfirm {
 		return fmt.Errorf("งานนี้ไม่ได้อยู่ในสถานะรอยืนยัน")
 	}
 
+	// ตรวจ mapping ประเภทป้าย -> workflow ก่อนยืนยัน
+	if _, _, err := s.resolveProductionPlans(ctx, existing); err != nil {
+		return err
+	}
+
 	now := time.Now()
 
 	// เปลี่ยน WaitConfirm เป็น false
@@ -942,10 +996,21 @@ func (s *signJobService) ConfirmSignJob(ctx context.Context, jobID string, claim
 	}
 
 	// ถ้ายังรอราคาอยู่ (WaitPrice = true) ไม่ต้องสร้างระบบบัญชี
-	if existing.WaitPrice {
-		return nil
+	if !existing.WaitPrice {
+		if err := s.confirmSignJobAccounting(ctx, existing, now, claims); err != nil {
+			return err
+		}
 	}
 
+	// ยืนยันแล้ว สร้าง task การผลิตจาก workflow ของประเภทป้ายหลังลงบัญชีแล้ว
+	s.generateProductionTasksOrLog(ctx, existing, claims)
+
+	return nil
+}
+
+// confirmSignJobAccounting สร้าง Income/Receivable ของงานที่ยืนยันแล้วและมีราคา
+func (s *signJobService) confirmSignJobAccounting(ctx context.Context, existing *models.SignJob, now time.Time, claims *dto.JWTClaims) error {
+
 	// ถ้าไม่รอราคาแล้ว (มีราคาแล้ว) และมีราคา >= 0.01 ให้สร้าง Income/Receivable
 	if util.IsPositiveAmount(existing.PriceTHB) {
 		jobName := existing.JobName
@@ -964,6 +1029,7 @@ func (s *signJobService) ConfirmSignJob(ctx context.Context, jobID string, claim
 				PaymentMethod:         existing.PaymentMethod,
 				ReferenceNo:           "",
 				Note:                  &jobName,
+				JobID:                 existing.JobID,
 				CreatedBy:             claims.UserID,
 				CreatedAt:             now,
 				UpdatedAt:             now,
@@ -1026,6 +1092,7 @@ func (s *signJobService) ConfirmSignJob(ctx context.Context, jobID string, claim
 				PaymentMethod:         existing.PaymentMethod,
 				ReferenceNo:           invoiceNo,
 				Note:                  &jobName,
+				JobID:                 existing.JobID,
 				CreatedBy:             claims.UserID,
 				CreatedAt:             now,
 				UpdatedAt:             now,
@@ -1082,3 +1149,525 @@ func (s *signJobService) ConfirmSignJob(ctx context.Context, jobID string, claim
 
 	return nil
 }
+
+// CancelSignJob ยกเลิกงานป้ายพร้อมเหตุผล โดยไม่ลบข้อมูลใดๆ
+//   - ลูกหนี้ของงานถูกปิด (status = cancelled, balance = 0)
+//   - รายได้ที่ยังไม่ได้รับเงินจริง (เช่น งานเครดิตที่บันทึกรายได้เต็มจำนวนไว้) ถูกกลับรายการด้วยรายการติดลบ
+//   - เงินที่รับมาแล้ว (มัดจำ / รับชำระ) ถ้าต้องคืนลูกค้า จะสร้างเป็นเจ้าหนี้คืนเงิน ส่วนที่เหลือถือว่าริบไว้
+//   - งานย่อย (task) ที่ยังไม่เสร็จถูกเปลี่ยนสถานะเป็น cancelled
+//   - ถ้าขั้นตอนใดล้มเหลว ขั้นตอนที่ทำไปแล้วจะถูกย้อนกลับและงานกลับเป็นสถานะเดิม
+func (s *signJobService) CancelSignJob(ctx context.Context, jobID string, req dto.CancelSignJobDTO, claims *dto.JWTClaims) (*dto.CancelSignJobResultDTO, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("reason is required")
+	}
+	if req.RefundAmount < 0 {
+		return nil, fmt.Errorf("refund_amount must be >= 0")
+	}
+
+	filter := bson.M{"job_id": jobID, "deleted_at": nil}
+	existing, err := s.signJobRepo.GetOneSignJobByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	if existing.Status == "cancelled" {
+		return nil, fmt.Errorf("งานนี้ถูกยกเลิกแล้ว")
+	}
+	if existing.Status == "done" {
+		return nil, fmt.Errorf("งานนี้ตรวจรับแล้ว ไม่สามารถยกเลิกได้")
+	}
+
+	now := time.Now()
+	nowUTC := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	result := &dto.CancelSignJobResultDTO{JobID: jobID}
+
+	refundDue := nowUTC.AddDate(0, 0, 7)
+	if strings.TrimSpace(req.RefundDue) != "" {
+		t, err := time.Parse("2006-01-02", req.RefundDue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid refund_due, want YYYY-MM-DD: %w", err)
+		}
+		refundDue = t
+	}
+
+	// ---------- ลูกหนี้ ----------
+	receivables, err := s.receivableRepo.GetAllReceivablesByFilter(ctx, bson.M{"job_id": jobID, "deleted_at": nil}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get receivables: %w", err)
+	}
+	invoiceNos := make([]string, 0, len(receivables))
+	for _, rec := range receivables {
+		if rec.InvoiceNo != "" {
+			invoiceNos = append(invoiceNos, rec.InvoiceNo)
+		}
+	}
+
+	// ---------- รายได้ที่ผูกกับงาน ----------
+	// ผูกด้วย job_id หรือ reference_no = เลขใบแจ้งหนี้ของลูกหนี้ (มัดจำ / รับชำระ)
+	// รายได้เก่าที่ยังไม่มี job_id ใช้ note = ชื่องาน เฉพาะเมื่อไม่มีงานอื่นชื่อซ้ำ
+	incomeMatches := []bson.M{
+		{"job_id": jobID},
+		{"reference_no": bson.M{"$in": invoiceNos}},
+	}
+	sameName, err := s.signJobRepo.GetOneSignJobByFilter(ctx, bson.M{"job_name": existing.JobName, "job_id": bson.M{"$ne": jobID}}, bson.M{"job_id": 1})
+	if err != nil {
+		return nil, err
+	}
+	if sameName == nil {
+		incomeMatches = append(incomeMatches, bson.M{"job_id": bson.M{"$exists": false}, "note": existing.JobName, "reference_no": bson.M{"$in": []interface{}{"", nil}}})
+	}
+	incomes, err := s.incomeRepo.GetAllInComeByFilter(ctx, bson.M{
+		"deleted_at":  nil,
+		"reversal_of": bson.M{"$exists": false},
+		"$or":         incomeMatches,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get incomes: %w", err)
+	}
+
+	incomeIDs := make([]string, 0, len(incomes))
+	for _, inc := range incomes {
+		incomeIDs = append(incomeIDs, inc.IncomeID)
+	}
+	reversed, err := s.incomeRepo.GetAllInComeByFilter(ctx, bson.M{"deleted_at": nil, "reversal_of": bson.M{"$in": incomeIDs}}, bson.M{"reversal_of": 1})
+	if err != nil {
+		return nil, fmt.Errorf("get reversed incomes: %w", err)
+	}
+	alreadyReversed := make(map[string]bool, len(reversed))
+	for _, r := range reversed {
+		alreadyReversed[r.ReversalOf] = true
+	}
+
+	// งานเครดิตที่ไม่มีมัดจำ ระบบบันทึกรายได้เต็มจำนวนไว้ก่อนรับเงินจริง -> รายได้ที่ไม่มี reference_no ถือว่ายังไม่ได้รับเงิน
+	creditWithoutDeposit := existing.PaymentMethod == "credit" && !existing.IsDeposit
+
+	var received float64
+	toReverse := make([]*models.Income, 0)
+	for _, inc := range incomes {
+		if alreadyReversed[inc.IncomeID] {
+			continue
+		}
+		if !creditWithoutDeposit || inc.ReferenceNo != "" {
+			received += inc.Amount
+			continue
+		}
+		toReverse = append(toReverse, inc)
+	}
+	received = util.Round2(received)
+
+	refund := util.Round2(req.RefundAmount)
+	if refund > received {
+		return nil, fmt.Errorf("refund_amount %.2f exceeds received amount %.2f", refund, received)
+	}
+
+	tasks, err := s.taskRepo.GetAllTaskByFilter(ctx, bson.M{"job_id": jobID, "deleted_at": nil, "status": bson.M{"$in": []string{"todo", "in_progress"}}}, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("get tasks: %w", err)
+	}
+
+	// ---------- งานป้าย ----------
+	// เปลี่ยนสถานะแบบมีเงื่อนไขก่อน กันการยกเลิกซ้อนกันสองครั้ง แล้วค่อยลงบัญชี
+	// ไม่มี transaction จึงเก็บขั้นตอนย้อนกลับไว้ ถ้าขั้นตอนถัดไปล้มเหลวจะย้อนทุกขั้นที่ทำไปแล้ว
+	claimed, err := s.signJobRepo.UpdateManySignJobFields(ctx, bson.M{"job_id": jobID, "deleted_at": nil, "status": existing.Status}, bson.M{
+		"status":        "cancelled",
+		"cancelled_at":  now,
+		"cancelled_by":  claims.UserID,
+		"cancel_reason": reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if claimed == 0 {
+		return nil, fmt.Errorf("งานนี้ถูกแก้ไขหรือยกเลิกไปแล้ว กรุณาลองใหม่")
+	}
+
+	var undo []func(context.Context) error
+	undo = append(undo, func(ctx context.Context) error {
+		_, err := s.signJobRepo.UpdateManySignJobFields(ctx, bson.M{"job_id": jobID}, bson.M{
+			"status":        existing.Status,
+			"cancelled_at":  nil,
+			"cancelled_by":  "",
+			"cancel_reason": "",
+		})
+		return err
+	})
+	fail := func(err error) (*dto.CancelSignJobResultDTO, error) {
+		// ใช้ context ใหม่ ให้ย้อนกลับได้แม้ request ถูกยกเลิก
+		rollbackCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		for i := len(undo) - 1; i >= 0; i-- {
+			if errUndo := undo[i](rollbackCtx); errUndo != nil {
+				log.Printf("rollback cancel sign job %s: %v", jobID, errUndo)
+			}
+		}
+		return nil, err
+	}
+
+	// ---------- กลับรายการรายได้ที่ยังไม่ได้รับเงินจริง ----------
+	for _, inc := range toReverse {
+		note := existing.JobName
+		reversal := models.Income{
+			IncomeID:              uuid.NewString(),
+			BankID:                inc.BankID,
+			TransactionCategoryID: inc.TransactionCategoryID,
+			Description:           "ยกเลิกงาน: " + inc.Description,
+			Amount:                -inc.Amount,
+			Currency:              inc.Currency,
+			TxnDate:               nowUTC,
+			PaymentMethod:         inc.PaymentMethod,
+			ReferenceNo:           inc.ReferenceNo,
+			ReversalOf:            inc.IncomeID,
+			Note:                  &note,
+			JobID:                 existing.JobID,
+			CreatedBy:             claims.UserID,
+			CreatedAt:             now,
+			UpdatedAt:             now,
+		}
+		if err := s.incomeRepo.CreateInCome(ctx, reversal); err != nil {
+			return fail(fmt.Errorf("reverse income: %w", err))
+		}
+		undo = append(undo, func(ctx context.Context) error {
+			return s.incomeRepo.SoftDeleteInComeByincomeID(ctx, reversal.IncomeID)
+		})
+		result.ReversedIncomeIDs = append(result.ReversedIncomeIDs, inc.IncomeID)
+	}
+
+	// ---------- ปิดลูกหนี้ ----------
+	for _, rec := range receivables {
+		if rec.Status == "cancelled" {
+			continue
+		}
+		original := *rec
+		result.WrittenOffBalance += rec.Balance
+		rec.Balance = 0
+		rec.Status = "cancelled"
+		rec.Note = strings.TrimSpace(rec.Note + " | ยกเลิกงาน: " + reason)
+		rec.UpdatedAt = now
+		if _, err := s.receivableRepo.UpdateReceivableByID(ctx, rec.IDReceivable, *rec); err != nil {
+			return fail(fmt.Errorf("close receivable: %w", err))
+		}
+		undo = append(undo, func(ctx context.Context) error {
+			_, err := s.receivableRepo.UpdateReceivableByID(ctx, original.IDReceivable, original)
+			return err
+		})
+		result.ClosedReceivables = append(result.ClosedReceivables, rec.IDReceivable)
+	}
+	result.WrittenOffBalance = util.Round2(result.WrittenOffBalance)
+
+	// ---------- เจ้าหนี้คืนเงิน ----------
+	if util.IsPositiveAmount(refund) {
+		refInvoice := ""
+		if len(invoiceNos) > 0 {
+			refInvoice = invoiceNos[0]
+		}
+
+		payable := models.Payable{
+			IDPayable: uuid.NewString(),
+			BankID:    config.DefaultBankAccountIDs.CompanyBank,
+			Supplier:  existing.CompanyName,
+			InvoiceNo: refInvoice,
+			IssueDate: nowUTC,
+			DueDate:   refundDue,
+			Amount:    refund,
+			Balance:   refund,
+			Status:    "pending",
+			Phone:     existing.Phone,
+			Address:   existing.Address,
+			Note:      "คืนเงินลูกค้า (ยกเลิกงาน " + existing.JobName + "): " + reason,
+			JobID:     existing.JobID,
+			CreatedBy: claims.UserID,
+			CreatedAt: now,
+			UpdatedAt: now,
+			Items: []models.ReceiptItem{{
+				Description: "คืนเงินงาน " + existing.JobName,
+				Quantity:    1,
+				UnitPrice:   refund,
+				Total:       refund,
+			}},
+		}
+		if err := s.payableRepo.CreatePayable(ctx, payable); err != nil {
+			return fail(fmt.Errorf("create refund payable: %w", err))
+		}
+		undo = append(undo, func(ctx context.Context) error {
+			return s.payableRepo.SoftDeletePayableByID(ctx, payable.IDPayable)
+		})
+		result.RefundPayableID = payable.IDPayable
+	}
+
+	result.ReceivedAmount = received
+	result.RefundAmount = refund
+	result.RetainedAmount = util.Round2(received - refund)
+
+	// ---------- งานย่อย ----------
+	for _, t := range tasks {
+		if _, err := s.taskRepo.UpdateManyTaskFields(ctx, bson.M{"task_id": t.TaskID, "deleted_at": nil}, bson.M{"status": "cancelled"}); err != nil {
+			return fail(fmt.Errorf("cancel task: %w", err))
+		}
+
+		// งานที่ถูกยกเลิกไม่นับเป็นงานค้าง
+		cancelled := *t
+		cancelled.Status = "cancelled"
+		if err := applyTaskStatsDiff(ctx, s.taskRepo, taskOwnerShares(t), taskOwnerShares(&cancelled)); err != nil {
+			undo = append(undo, func(ctx context.Context) error {
+				_, err := s.taskRepo.UpdateManyTaskFields(ctx, bson.M{"task_id": t.TaskID}, bson.M{"status": t.Status})
+				return err
+			})
+			return fail(err)
+		}
+		undo = append(undo, func(ctx context.Context) error {
+			if _, err := s.taskRepo.UpdateManyTaskFields(ctx, bson.M{"task_id": t.TaskID}, bson.M{"status": t.Status}); err != nil {
+				return err
+			}
+			return applyTaskStatsDiff(ctx, s.taskRepo, taskOwnerShares(&cancelled), taskOwnerShares(t))
+		})
+		result.CancelledTaskIDs = append(result.CancelledTaskIDs, t.TaskID)
+	}
+
+	return result, nil
+}
+
+// GenerateProductionTasks สร้าง task การผลิตของงานป้ายจาก workflow ที่ผูกกับประเภทป้าย (ใช้กรณีเพิ่ม mapping ภายหลัง)
+func (s *signJobService) GenerateProductionTasks(ctx context.Context, jobID string, claims *dto.JWTClaims) ([]string, error) {
+	job, err := s.signJobRepo.GetOneSignJobByFilter(ctx, bson.M{"job_id": jobID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	if job.Status == "cancelled" {
+		return nil, fmt.Errorf("งานนี้ถูกยกเลิกแล้ว")
+	}
+	if job.WaitConfirm {
+		return nil, fmt.Errorf("งานนี้ยังอยู่ในสถานะรอยืนยัน")
+	}
+	return s.generateProductionTasks(ctx, job, claims)
+}
+
+// generateProductionTasksOrLog สร้าง task การผลิตโดยไม่ทำให้การบันทึกงานล้มเหลว
+// ถ้าสร้างไม่ครบ สั่งสร้างซ้ำได้ที่ GenerateProductionTasks (สร้างเฉพาะ workflow ที่ยังขาด)
+func (s *signJobService) generateProductionTasksOrLog(ctx context.Context, job *models.SignJob, claims *dto.JWTClaims) {
+	if _, err := s.generateProductionTasks(ctx, job, claims); err != nil {
+		log.Printf("generate production tasks for sign job %s: %v", job.JobID, err)
+	}
+}
+
+// productionPlan task หนึ่งงานที่จะสร้างจากรายการใน mapping
+type productionPlan struct {
+	itemIdx    int
+	workflow   *models.WorkFlowTemplate
+	department string
+}
+
+// resolveProductionPlans หา mapping ของประเภทป้าย/ตัวเลือกติดตั้ง และตรวจว่า workflow ที่ผูกไว้ยังมีอยู่
+// ไม่มีประเภทป้ายหรือไม่มี mapping คืน nil (ไม่ต้องสร้าง task)
+func (s *signJobService) resolveProductionPlans(ctx context.Context, job *models.SignJob) (*models.SignTypeWorkflow, []productionPlan, error) {
+	if strings.TrimSpace(job.SignTypeID) == "" {
+		return nil, nil, nil
+	}
+
+	mappings, err := s.signTypeWorkflowRepo.GetAllSignTypeWorkflowsByFilter(ctx, bson.M{
+		"sign_type_id": job.SignTypeID,
+		"is_active":    true,
+		"deleted_at":   nil,
+	}, bson.M{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mapping := pickSignTypeWorkflow(mappings, job.InstallOption)
+	if mapping == nil || len(mapping.Items) == 0 {
+		return nil, nil, nil
+	}
+
+	plans := make([]productionPlan, 0, len(mapping.Items))
+	for i, it := range mapping.Items {
+		wf, err := s.workflowRepo.GetOneWorkFlowTemplateByFilter(ctx, bson.M{"workflow_id": it.WorkFlowID, "deleted_at": nil}, bson.M{})
+		if err != nil {
+			return nil, nil, err
+		}
+		if wf == nil {
+			return nil, nil, fmt.Errorf("workflow %s of sign type mapping not found", it.WorkFlowID)
+		}
+		department := it.DepartmentID
+		if department == "" {
+			department = wf.Department
+		}
+		plans = append(plans, productionPlan{itemIdx: i, workflow: wf, department: department})
+	}
+	return mapping, plans, nil
+}
+
+// generateProductionTasks สร้าง task ตาม mapping ของประเภทป้าย/ตัวเลือกติดตั้ง
+// วางแผนย้อนหลังจาก due_date (task สุดท้ายจบวันส่งงาน) ถ้าไม่มี due_date จะวางแผนไปข้างหน้าจากวันนี้
+// นับเฉพาะวันทำงานของแผนกตามปฏิทินกำลังการผลิต (ข้ามวันหยุด) และสร้างเฉพาะ workflow ที่ยังไม่เคยสร้างอัตโนมัติ
+func (s *signJobService) generateProductionTasks(ctx context.Context, job *models.SignJob, claims *dto.JWTClaims) ([]string, error) {
+	mapping, plans, err := s.resolveProductionPlans(ctx, job)
+	if err != nil || mapping == nil {
+		return nil, err
+	}
+
+	existingTasks, err := s.taskRepo.GetAllTaskByFilter(ctx, bson.M{"job_id": job.JobID, "auto_generated": true, "deleted_at": nil}, bson.M{"workflow_id": 1})
+	if err != nil {
+		return nil, err
+	}
+	generated := make(map[string]int, len(existingTasks))
+	for _, t := range existingTasks {
+		generated[t.WorkFlowID]++
+	}
+
+	now := time.Now()
+	today := helpers.Today()
+	horizon := today
+	if job.DueDate.After(horizon) {
+		horizon = helpers.DateOnly(job.DueDate)
+	}
+	cal, err := loadWorkingCalendar(ctx, s.capacityRepo, today.AddDate(0, 0, -31), horizon.AddDate(1, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	starts := make([]time.Time, len(plans))
+	ends := make([]time.Time, len(plans))
+	if !job.DueDate.IsZero() {
+		cursor := helpers.DateOnly(job.DueDate)
+		for i := len(plans) - 1; i >= 0; i-- {
+			dept := plans[i].department
+			ends[i] = cal.shiftWorkingDays(dept, cursor, -1)
+			starts[i] = ends[i]
+			for d := workingDaysForHours(plans[i].workflow.TotalHours, cal.setting(dept).HoursPerDay); d > 1; d-- {
+				starts[i] = cal.shiftWorkingDays(dept, starts[i].AddDate(0, 0, -1), -1)
+			}
+			cursor = starts[i].AddDate(0, 0, -1)
+		}
+		// กำหนดส่งกระชั้นชิด ไม่ให้เริ่มก่อนวันนี้
+		for i, p := range plans {
+			if starts[i].Before(today) {
+				starts[i] = cal.shiftWorkingDays(p.department, today, 1)
+			}
+			if ends[i].Before(starts[i]) {
+				ends[i] = starts[i]
+			}
+		}
+	} else {
+		cursor := today
+		for i, p := range plans {
+			starts[i] = cal.shiftWorkingDays(p.department, cursor, 1)
+			ends[i] = starts[i]
+			for d := workingDaysForHours(p.workflow.TotalHours, cal.setting(p.department).HoursPerDay); d > 1; d-- {
+				ends[i] = cal.shiftWorkingDays(p.department, ends[i].AddDate(0, 0, 1), 1)
+			}
+			cursor = ends[i].AddDate(0, 0, 1)
+		}
+	}
+
+	// ผู้จัดการแผนก ใช้เป็นผู้รับผิดชอบ step เริ่มต้นของ step ที่ทำโดยแผนกอื่น
+	departments, err := s.dropDownRepo.GetDepartments(ctx, bson.M{"deleted_at": nil}, bson.M{"department_id": 1, "manager_id": 1})
+	if err != nil {
+		return nil, err
+	}
+	managers := make(map[string]string, len(departments))
+	for _, d := range departments {
+		managers[d.DepartmentID] = d.ManagerID
+	}
+
+	createdIDs := make([]string, 0, len(plans))
+	for i, p := range plans {
+		item := mapping.Items[p.itemIdx]
+		wf := p.workflow
+		department := p.department
+		if generated[wf.WorkFlowID] > 0 {
+			generated[wf.WorkFlowID]--
+			continue
+		}
+
+		assignee := item.AssigneeID
+		if assignee == "" && len(item.AssigneePool) > 0 {
+			idx, err := s.signTypeWorkflowRepo.IncrementPoolIndex(ctx, mapping.MappingID, p.itemIdx)
+			if err != nil {
+				return createdIDs, err
+			}
+			assignee = item.AssigneePool[idx%len(item.AssigneePool)]
+		}
+
+		assigneeName := "ไม่พบชื่อผู้รับผิดชอบ"
+		assigneeNickName := "ไม่พบชื่อเล่น"
+		if assignee != "" {
+			user, err := s.userRepo.GetByID(ctx, assignee)
+			if err != nil && err != mongo.ErrNoDocuments {
+				return createdIDs, err
+			}
+			if user != nil {
+				assigneeName = fmt.Sprintf("%s %s %s", user.TitleTH, user.FirstNameTH, user.LastNameTH)
+				assigneeNickName = user.NickName
+			}
+		}
+
+		steps, total := snapshotWorkflowSteps(wf, now)
+		defaultStepAssignees(ctx, s.userRepo, steps, assignee, department, managers)
+
+		task := models.Tasks{
+			TaskID:      uuid.NewString(),
+			ProjectID:   job.ProjectID,
+			ProjectName: job.ProjectName,
+			JobID:       job.JobID,
+			JobName:     job.JobName,
+			Description: wf.Description,
+
+			Department:       department,
+			Assignee:         assignee,
+			AssigneeName:     assigneeName,
+			AssigneeNickName: assigneeNickName,
+			Importance:       item.Importance,
+			StartDate:        starts[i],
+			EndDate:          ends[i],
+			KPIID:            item.KPIID,
+			WorkFlowID:       wf.WorkFlowID,
+
+			AppliedWorkflow: models.TaskAppliedWorkflow{
+				WorkFlowID:   wf.WorkFlowID,
+				WorkFlowName: wf.WorkFlowName,
+				Department:   wf.Department,
+				Description:  wf.Description,
+				TotalHours:   total,
+				Steps:        steps,
+				Version:      wf.Version,
+
+				TemplateVersion: wf.Version,
+			},
+
+			Status:        "todo",
+			StepName:      helpers.CurrentStepName(steps),
+			ReadySteps:    helpers.ReadyStepNames(steps),
+			CreatedBy:     claims.UserID,
+			AutoGenerated: true,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+
+		if err := s.taskRepo.CreateTask(ctx, task); err != nil {
+			return createdIDs, err
+		}
+		createdIDs = append(createdIDs, task.TaskID)
+		if err := applyTaskStatsDiff(ctx, s.taskRepo, nil, taskOwnerShares(&task)); err != nil {
+			return createdIDs, err
+		}
+	}
+
+	return createdIDs, nil
+}
+
+// workingDaysForHours แปลงชั่วโมงงานเป็นจำนวนวันทำงานตามชั่วโมงต่อวันของแผนก (อย่างน้อย 1 วัน)
+func workingDaysForHours(hours, hoursPerDay float64) int {
+	if hoursPerDay <= 0 {
+		hoursPerDay = defaultHoursPerDay
+	}
+	days := int(math.Ceil(hours / hoursPerDay))
+	if days < 1 {
+		days = 1
+	}
+	return days
+}
@@ -0,0 +1,377 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/config"
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type signTypeWorkflowService struct {
+	signTypeWorkflowRepo ports.SignTypeWorkflowRepository
+	workflowRepo         ports.WorkFlowRepository
+	dropDownRepo         ports.DropDownRepository
+	config               config.Config
+}
+
+func NewSignTypeWorkflowService(cfg config.Config, signTypeWorkflowRepo ports.SignTypeWorkflowRepository, workflowRepo ports.WorkFlowRepository, dropDownRepo ports.DropDownRepository) ports.SignTypeWorkflowService {
+	return &signTypeWorkflowService{config: cfg, signTypeWorkflowRepo: signTypeWorkflowRepo, workflowRepo: workflowRepo, dropDownRepo: dropDownRepo}
+}
+
+func (s *signTypeWorkflowService) CreateSignTypeWorkflow(ctx context.Context, req dto.CreateSignTypeWorkflowDTO, claims *dto.JWTClaims) error {
+	signTypeID := strings.TrimSpace(req.SignTypeID)
+	if signTypeID == "" {
+		return errors.New("sign_type_id is required")
+	}
+	installOption, err := normalizeInstallOption(req.InstallOption)
+	if err != nil {
+		return err
+	}
+
+	signTypes, err := s.dropDownRepo.GetSignTypes(ctx, bson.M{"type_id": signTypeID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return err
+	}
+	if len(signTypes) == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	// หนึ่งประเภทป้าย + ตัวเลือกติดตั้ง มีได้เพียง mapping เดียว
+	dup, err := s.signTypeWorkflowRepo.GetOneSignTypeWorkflowByFilter(ctx, bson.M{
+		"sign_type_id":   signTypeID,
+		"install_option": installOptionFilter(installOption),
+		"deleted_at":     nil,
+	}, bson.M{})
+	if err != nil {
+		return err
+	}
+	if dup != nil {
+		return errors.New("workflow mapping for this sign type and install option already exists")
+	}
+
+	if req.BaseAreaSqm < 0 {
+		return errors.New("base_area_sqm must be >= 0")
+	}
+
+	items, err := s.buildItems(ctx, req.Items)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	mapping := models.SignTypeWorkflow{
+		MappingID:     uuid.NewString(),
+		SignTypeID:    signTypeID,
+		InstallOption: installOption,
+		Items:         items,
+		BaseAreaSqm:   req.BaseAreaSqm,
+		IsActive:      true,
+		CreatedBy:     claims.UserID,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	return s.signTypeWorkflowRepo.CreateSignTypeWorkflow(ctx, mapping)
+}
+
+func (s *signTypeWorkflowService) ListSignTypeWorkflows(ctx context.Context, claims *dto.JWTClaims, page, size int, signTypeID string) (dto.Pagination, error) {
+	skip := int64((page - 1) * size)
+	limit := int64(size)
+
+	filter := bson.M{"deleted_at": nil}
+	signTypeID = strings.TrimSpace(signTypeID)
+	if signTypeID != "" {
+		filter["sign_type_id"] = signTypeID
+	}
+
+	sortBy := bson.D{
+		{Key: "created_at", Value: -1},
+		{Key: "_id", Value: -1},
+	}
+
+	items, total, err := s.signTypeWorkflowRepo.GetListSignTypeWorkflowsByFilter(ctx, filter, bson.M{}, sortBy, skip, limit)
+	if err != nil {
+		return dto.Pagination{}, fmt.Errorf("list sign type workflows: %w", err)
+	}
+
+	lookup, err := s.loadLookups(ctx)
+	if err != nil {
+		return dto.Pagination{}, err
+	}
+
+	list := make([]interface{}, 0, len(items))
+	for i := range items {
+		list = append(list, lookup.toDTO(&items[i]))
+	}
+
+	totalPages := 0
+	if total > 0 && size > 0 {
+		totalPages = int((total + int64(size) - 1) / int64(size))
+	}
+
+	return dto.Pagination{
+		Page:       page,
+		Size:       size,
+		TotalCount: int(total),
+		TotalPages: totalPages,
+		List:       list,
+	}, nil
+}
+
+func (s *signTypeWorkflowService) GetSignTypeWorkflowByID(ctx context.Context, mappingID string, claims *dto.JWTClaims) (*dto.SignTypeWorkflowDTO, error) {
+	m, err := s.signTypeWorkflowRepo.GetOneSignTypeWorkflowByFilter(ctx, bson.M{"mapping_id": mappingID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	lookup, err := s.loadLookups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := lookup.toDTO(m)
+	return &out, nil
+}
+
+func (s *signTypeWorkflowService) UpdateSignTypeWorkflow(ctx context.Context, mappingID string, req dto.UpdateSignTypeWorkflowDTO, claims *dto.JWTClaims) error {
+	existing, err := s.signTypeWorkflowRepo.GetOneSignTypeWorkflowByFilter(ctx, bson.M{"mapping_id": mappingID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return mongo.ErrNoDocuments
+	}
+
+	if req.InstallOption != nil {
+		installOption, err := normalizeInstallOption(*req.InstallOption)
+		if err != nil {
+			return err
+		}
+		if installOption != existing.InstallOption {
+			dup, err := s.signTypeWorkflowRepo.GetOneSignTypeWorkflowByFilter(ctx, bson.M{
+				"mapping_id":     bson.M{"$ne": mappingID},
+				"sign_type_id":   existing.SignTypeID,
+				"install_option": installOptionFilter(installOption),
+				"deleted_at":     nil,
+			}, bson.M{})
+			if err != nil {
+				return err
+			}
+			if dup != nil {
+				return errors.New("workflow mapping for this sign type and install option already exists")
+			}
+		}
+		existing.InstallOption = installOption
+	}
+	if req.Items != nil {
+		items, err := s.buildItems(ctx, *req.Items)
+		if err != nil {
+			return err
+		}
+		existing.Items = items
+	}
+	if req.IsActive != nil {
+		existing.IsActive = *req.IsActive
+	}
+	if req.BaseAreaSqm != nil {
+		if *req.BaseAreaSqm < 0 {
+			return errors.New("base_area_sqm must be >= 0")
+		}
+		existing.BaseAreaSqm = *req.BaseAreaSqm
+	}
+
+	updated, err := s.signTypeWorkflowRepo.UpdateSignTypeWorkflowByID(ctx, mappingID, *existing)
+	if err != nil {
+		return err
+	}
+	if updated == nil {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (s *signTypeWorkflowService) DeleteSignTypeWorkflow(ctx context.Context, mappingID string, claims *dto.JWTClaims) error {
+	existing, err := s.signTypeWorkflowRepo.GetOneSignTypeWorkflowByFilter(ctx, bson.M{"mapping_id": mappingID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return mongo.ErrNoDocuments
+	}
+	return s.signTypeWorkflowRepo.SoftDeleteSignTypeWorkflowByID(ctx, mappingID)
+}
+
+// buildItems ตรวจสอบ workflow ที่อ้างอิงและเรียงตามลำดับการผลิต
+func (s *signTypeWorkflowService) buildItems(ctx context.Context, reqItems []dto.SignTypeWorkflowItemDTO) ([]models.SignTypeWorkflowItem, error) {
+	if len(reqItems) == 0 {
+		return nil, errors.New("items are required")
+	}
+
+	items := make([]models.SignTypeWorkflowItem, 0, len(reqItems))
+	for i, it := range reqItems {
+		workflowID := strings.TrimSpace(it.WorkFlowID)
+		if workflowID == "" {
+			return nil, fmt.Errorf("items[%d].workflow_id is required", i)
+		}
+		wf, err := s.workflowRepo.GetOneWorkFlowTemplateByFilter(ctx, bson.M{"workflow_id": workflowID, "deleted_at": nil}, bson.M{"workflow_id": 1})
+		if err != nil {
+			return nil, err
+		}
+		if wf == nil {
+			return nil, fmt.Errorf("items[%d]: workflow %s not found", i, workflowID)
+		}
+
+		importance := strings.ToLower(strings.TrimSpace(it.Importance))
+		if importance == "" {
+			importance = "mid"
+		}
+		if importance != "low" && importance != "mid" && importance != "high" {
+			return nil, fmt.Errorf("items[%d].importance must be one of: low|mid|high", i)
+		}
+
+		pool := make([]string, 0, len(it.AssigneePool))
+		for _, uid := range it.AssigneePool {
+			if uid = strings.TrimSpace(uid); uid != "" {
+				pool = append(pool, uid)
+			}
+		}
+
+		order := it.Order
+		if order <= 0 {
+			order = i + 1
+		}
+
+		items = append(items, models.SignTypeWorkflowItem{
+			WorkFlowID:   workflowID,
+			DepartmentID: strings.TrimSpace(it.DepartmentID),
+			AssigneeID:   strings.TrimSpace(it.AssigneeID),
+			AssigneePool: pool,
+			KPIID:        strings.TrimSpace(it.KPIID),
+			Importance:   importance,
+			Order:        order,
+		})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Order < items[j].Order })
+	return items, nil
+}
+
+type signTypeWorkflowLookup struct {
+	signTypes   map[string]string
+	departments map[string]string
+	workflows   map[string]*models.WorkFlowTemplate
+}
+
+func (s *signTypeWorkflowService) loadLookups(ctx context.Context) (*signTypeWorkflowLookup, error) {
+	lookup := &signTypeWorkflowLookup{
+		signTypes:   map[string]string{},
+		departments: map[string]string{},
+		workflows:   map[string]*models.WorkFlowTemplate{},
+	}
+
+	signTypes, err := s.dropDownRepo.GetSignTypes(ctx, bson.M{"deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range signTypes {
+		lookup.signTypes[st.TypeID] = st.NameTH
+	}
+
+	departments, err := s.dropDownRepo.GetDepartments(ctx, bson.M{"deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range departments {
+		lookup.departments[d.DepartmentID] = d.DepartmentName
+	}
+
+	workflows, err := s.workflowRepo.GetAllWorkFlowTemplatesByFilter(ctx, bson.M{"deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	for _, wf := range workflows {
+		lookup.workflows[wf.WorkFlowID] = wf
+	}
+
+	return lookup, nil
+}
+
+func (l *signTypeWorkflowLookup) toDTO(m *models.SignTypeWorkflow) dto.SignTypeWorkflowDTO {
+	items := make([]dto.SignTypeWorkflowItemResponse, 0, len(m.Items))
+	for _, it := range m.Items {
+		resp := dto.SignTypeWorkflowItemResponse{
+			WorkFlowID:   it.WorkFlowID,
+			DepartmentID: it.DepartmentID,
+			AssigneeID:   it.AssigneeID,
+			AssigneePool: it.AssigneePool,
+			KPIID:        it.KPIID,
+			Importance:   it.Importance,
+			Order:        it.Order,
+		}
+		if wf, ok := l.workflows[it.WorkFlowID]; ok {
+			resp.WorkFlowName = wf.WorkFlowName
+			resp.TotalHours = wf.TotalHours
+			if resp.DepartmentID == "" {
+				resp.DepartmentID = wf.Department
+			}
+		}
+		resp.DepartmentName = l.departments[resp.DepartmentID]
+		items = append(items, resp)
+	}
+
+	return dto.SignTypeWorkflowDTO{
+		MappingID:     m.MappingID,
+		SignTypeID:    m.SignTypeID,
+		SignTypeName:  l.signTypes[m.SignTypeID],
+		InstallOption: m.InstallOption,
+		CreatedBy:     m.CreatedBy,
+		Items:         items,
+		IsActive:      m.IsActive,
+		BaseAreaSqm:   m.BaseAreaSqm,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+	}
+}
+
+func normalizeInstallOption(v string) (string, error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	switch v {
+	case "", "none", "self", "shop":
+		return v, nil
+	}
+	return "", errors.New("install_option must be one of: none|self|shop")
+}
+
+// installOptionFilter ค่าว่างถูกเก็บแบบ omitempty จึงต้องค้นทั้งกรณีไม่มี field และเป็นค่าว่าง
+func installOptionFilter(v string) interface{} {
+	if v == "" {
+		return bson.M{"$in": []interface{}{nil, ""}}
+	}
+	return v
+}
+
+// pickSignTypeWorkflow เลือก mapping ที่ตัวเลือกติดตั้งตรงกันก่อน ถ้าไม่มีใช้ mapping ที่ไม่ระบุตัวเลือกติดตั้ง
+func pickSignTypeWorkflow(mappings []*models.SignTypeWorkflow, installOption string) *models.SignTypeWorkflow {
+	var fallback *models.SignTypeWorkflow
+	for _, m := range mappings {
+		if m.InstallOption != "" && m.InstallOption == installOption {
+			return m
+		}
+		if m.InstallOption == "" && fallback == nil {
+			fallback = m
+		}
+	}
+	return fallback
+}
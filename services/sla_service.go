@@ -0,0 +1,806 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/config"
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/pkg/util"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const defaultSLAWarnPercent = 80 // แจ้งเตือนเมื่อใช้เวลาไปแล้ว 80% (ถ้ากฎไม่ได้กำหนด)
+
+type slaService struct {
+	config         config.Config
+	slaRepo        ports.SLARepository
+	taskRepo       ports.TaskRepository
+	userRepo       ports.UserRepository
+	departmentRepo ports.DepartmentRepository
+	workFlowRepo   ports.WorkFlowRepository
+}
+
+func NewSLAService(cfg config.Config, slaRepo ports.SLARepository, taskRepo ports.TaskRepository, userRepo ports.UserRepository, departmentRepo ports.DepartmentRepository, workFlowRepo ports.WorkFlowRepository) ports.SLAService {
+	return &slaService{config: cfg, slaRepo: slaRepo, taskRepo: taskRepo, userRepo: userRepo, departmentRepo: departmentRepo, workFlowRepo: workFlowRepo}
+}
+
+func (s *slaService) CreateRule(ctx context.Context, req dto.CreateSLARuleDTO, claims *dto.JWTClaims) (*dto.SLARuleDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrSLAForbidden
+	}
+
+	now := time.Now()
+	rule := models.SLARule{
+		RuleID:      uuid.NewString(),
+		Name:        strings.TrimSpace(req.Name),
+		Scope:       strings.ToLower(strings.TrimSpace(req.Scope)),
+		TargetHours: req.TargetHours,
+		HoursFactor: req.HoursFactor,
+		WarnPercent: req.WarnPercent,
+		Active:      req.Active == nil || *req.Active,
+		CreatedBy:   claims.UserID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if rule.WarnPercent == 0 {
+		rule.WarnPercent = defaultSLAWarnPercent
+	}
+
+	switch rule.Scope {
+	case "step":
+		rule.WorkFlowID = strings.TrimSpace(req.WorkFlowID)
+		rule.StepName = strings.TrimSpace(req.StepName)
+		if rule.WorkFlowID == "" || rule.StepName == "" {
+			return nil, errors.New("workflow_id and step_name are required for step scope")
+		}
+		wf, err := s.workFlowRepo.GetOneWorkFlowTemplateByFilter(ctx, bson.M{"workflow_id": rule.WorkFlowID, "deleted_at": nil}, bson.M{})
+		if err != nil {
+			return nil, err
+		}
+		if wf == nil {
+			return nil, errors.New("workflow not found")
+		}
+		found := false
+		for _, st := range wf.Steps {
+			if strings.EqualFold(strings.TrimSpace(st.StepName), rule.StepName) {
+				rule.StepName = strings.TrimSpace(st.StepName)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("step %q not found in workflow", rule.StepName)
+		}
+		rule.HoursFactor = 0
+	case "importance":
+		rule.Importance = strings.ToLower(strings.TrimSpace(req.Importance))
+		if !helpers.InSet(rule.Importance, "low", "medium", "high") {
+			return nil, errors.New("importance must be low, medium or high")
+		}
+		rule.TargetHours = 0
+	default:
+		return nil, errors.New("scope must be step or importance")
+	}
+	if err := validateSLARule(&rule); err != nil {
+		return nil, err
+	}
+	if rule.Name == "" {
+		rule.Name = rule.StepName
+		if rule.Scope == "importance" {
+			rule.Name = "importance: " + rule.Importance
+		}
+	}
+
+	// กฎที่ใช้งานอยู่ต้องไม่ซ้ำ (workflow+step หรือ importance เดียวกัน)
+	if rule.Active {
+		dup, err := s.slaRepo.GetOneRuleByFilter(ctx, slaRuleKeyFilter(&rule), bson.M{"rule_id": 1})
+		if err != nil {
+			return nil, err
+		}
+		if dup != nil {
+			return nil, errors.New("an active rule for this step/importance already exists")
+		}
+	}
+
+	if err := s.slaRepo.CreateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	out := s.toRuleDTO(ctx, &rule, map[string]string{})
+	return &out, nil
+}
+
+func (s *slaService) UpdateRule(ctx context.Context, ruleID string, req dto.UpdateSLARuleDTO, claims *dto.JWTClaims) (*dto.SLARuleDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrSLAForbidden
+	}
+
+	rule, err := s.slaRepo.GetOneRuleByFilter(ctx, bson.M{"rule_id": ruleID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	wasActive := rule.Active
+	if req.Name != nil && strings.TrimSpace(*req.Name) != "" {
+		rule.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.TargetHours != nil && rule.Scope == "step" {
+		rule.TargetHours = *req.TargetHours
+	}
+	if req.HoursFactor != nil && rule.Scope == "importance" {
+		rule.HoursFactor = *req.HoursFactor
+	}
+	if req.WarnPercent != nil {
+		rule.WarnPercent = *req.WarnPercent
+	}
+	if req.Active != nil {
+		rule.Active = *req.Active
+	}
+	if err := validateSLARule(rule); err != nil {
+		return nil, err
+	}
+
+	if rule.Active && !wasActive {
+		filter := slaRuleKeyFilter(rule)
+		filter["rule_id"] = bson.M{"$ne": rule.RuleID}
+		dup, err := s.slaRepo.GetOneRuleByFilter(ctx, filter, bson.M{"rule_id": 1})
+		if err != nil {
+			return nil, err
+		}
+		if dup != nil {
+			return nil, errors.New("an active rule for this step/importance already exists")
+		}
+	}
+
+	rule.UpdatedAt = time.Now()
+	updated, err := s.slaRepo.UpdateRuleByID(ctx, ruleID, *rule)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	out := s.toRuleDTO(ctx, updated, map[string]string{})
+	return &out, nil
+}
+
+func (s *slaService) DeleteRule(ctx context.Context, ruleID string, claims *dto.JWTClaims) error {
+	if claims.Role != "admin" {
+		return ports.ErrSLAForbidden
+	}
+	rule, err := s.slaRepo.GetOneRuleByFilter(ctx, bson.M{"rule_id": ruleID, "deleted_at": nil}, bson.M{"rule_id": 1})
+	if err != nil {
+		return err
+	}
+	if rule == nil {
+		return mongo.ErrNoDocuments
+	}
+	return s.slaRepo.SoftDeleteRuleByID(ctx, ruleID)
+}
+
+func (s *slaService) ListRules(ctx context.Context, req dto.RequestListSLARules, claims *dto.JWTClaims) ([]dto.SLARuleDTO, error) {
+	filter := bson.M{"deleted_at": nil}
+	if v := strings.TrimSpace(req.Scope); v != "" {
+		filter["scope"] = strings.ToLower(v)
+	}
+	if v := strings.TrimSpace(req.WorkFlowID); v != "" {
+		filter["workflow_id"] = v
+	}
+
+	rules, err := s.slaRepo.GetAllRulesByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]string{}
+	out := make([]dto.SLARuleDTO, 0, len(rules))
+	for _, r := range rules {
+		out = append(out, s.toRuleDTO(ctx, r, names))
+	}
+	return out, nil
+}
+
+func (s *slaService) ListBreaches(ctx context.Context, req dto.RequestListSLABreaches, claims *dto.JWTClaims) (dto.Pagination, error) {
+	filter, err := s.breachScope(ctx, req.DepartmentID, claims)
+	if err != nil {
+		return dto.Pagination{}, err
+	}
+	if v := strings.TrimSpace(req.Kind); v != "" {
+		filter["kind"] = v
+	}
+	if v := strings.TrimSpace(req.Level); v != "" {
+		filter["level"] = v
+	}
+	if v := strings.TrimSpace(req.Status); v != "" {
+		filter["status"] = v
+	}
+	if v := strings.TrimSpace(req.UserID); v != "" {
+		filter["user_id"] = v
+	}
+	if v := strings.TrimSpace(req.TaskID); v != "" {
+		filter["task_id"] = v
+	}
+	if err := applyDetectedRange(filter, req.From, req.To); err != nil {
+		return dto.Pagination{}, err
+	}
+
+	page, limit := req.Page, req.Limit
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	skip := int64((page - 1) * limit)
+
+	breaches, total, err := s.slaRepo.GetListBreachesByFilter(ctx, filter, skip, int64(limit))
+	if err != nil {
+		return dto.Pagination{}, err
+	}
+
+	users := map[string]string{}
+	departments := map[string]string{}
+	list := make([]interface{}, 0, len(breaches))
+	for _, b := range breaches {
+		list = append(list, dto.SLABreachDTO{
+			DueAt:          b.DueAt,
+			DetectedAt:     b.DetectedAt,
+			EscalatedAt:    b.EscalatedAt,
+			ResolvedAt:     b.ResolvedAt,
+			BreachID:       b.BreachID,
+			RuleID:         b.RuleID,
+			Kind:           b.Kind,
+			Level:          b.Level,
+			Status:         b.Status,
+			TaskID:         b.TaskID,
+			JobName:        b.JobName,
+			StepID:         b.StepID,
+			StepName:       b.StepName,
+			WorkFlowID:     b.WorkFlowID,
+			UserID:         b.UserID,
+			UserName:       s.userName(ctx, b.UserID, users),
+			DepartmentID:   b.DepartmentID,
+			DepartmentName: s.departmentName(ctx, b.DepartmentID, departments),
+			EscalatedTo:    b.EscalatedTo,
+			OverdueHours:   b.OverdueHours,
+		})
+	}
+
+	return dto.Pagination{
+		List:       list,
+		Page:       page,
+		Size:       limit,
+		TotalCount: int(total),
+		TotalPages: int(math.Ceil(float64(total) / float64(limit))),
+	}, nil
+}
+
+// BreachSummary สรุปจำนวนครั้งที่ถูกเตือน/เกินกำหนดและชั่วโมงเกินกำหนด (ใช้เป็นข้อมูลประกอบ KPI)
+func (s *slaService) BreachSummary(ctx context.Context, req dto.RequestSLABreachSummary, claims *dto.JWTClaims) ([]dto.SLABreachSummaryDTO, error) {
+	groupBy := strings.ToLower(strings.TrimSpace(req.GroupBy))
+	if groupBy == "" {
+		groupBy = "user"
+	}
+	if !helpers.InSet(groupBy, "user", "department", "workflow") {
+		return nil, errors.New("group_by must be user, department or workflow")
+	}
+
+	filter, err := s.breachScope(ctx, req.DepartmentID, claims)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyDetectedRange(filter, req.From, req.To); err != nil {
+		return nil, err
+	}
+
+	breaches, err := s.slaRepo.GetAllBreachesByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := map[string]*dto.SLABreachSummaryDTO{}
+	for _, b := range breaches {
+		key := b.UserID
+		switch groupBy {
+		case "department":
+			key = b.DepartmentID
+		case "workflow":
+			key = b.WorkFlowID
+		}
+		row := rows[key]
+		if row == nil {
+			row = &dto.SLABreachSummaryDTO{Key: key}
+			rows[key] = row
+		}
+		if b.Level == "warning" {
+			row.Warnings++
+			continue
+		}
+		row.Overdue++
+		row.OverdueHours += b.OverdueHours
+		if b.Status == "open" {
+			row.OpenOverdue++
+		}
+	}
+
+	users := map[string]string{}
+	departments := map[string]string{}
+	workflows := map[string]string{}
+	out := make([]dto.SLABreachSummaryDTO, 0, len(rows))
+	for key, row := range rows {
+		switch groupBy {
+		case "user":
+			row.Name = s.userName(ctx, key, users)
+		case "department":
+			row.Name = s.departmentName(ctx, key, departments)
+		case "workflow":
+			row.Name = s.workflowName(ctx, key, workflows)
+		}
+		row.OverdueHours = util.Round2(row.OverdueHours)
+		out = append(out, *row)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Overdue != out[j].Overdue {
+			return out[i].Overdue > out[j].Overdue
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+// RunCheck ตรวจงานที่ยังไม่ปิดทั้งหมดเทียบกับ SLA:
+// อัปเดต sla_status ของงาน/step, บันทึก breach ใหม่ (เตือนเจ้าของงาน, เกินกำหนดแจ้งผู้จัดการแผนก)
+// และปิด breach ที่งาน/step เสร็จหรือไม่เกินกำหนดแล้ว
+func (s *slaService) RunCheck(ctx context.Context) (*dto.SLACheckResult, error) {
+	now := time.Now()
+	result := &dto.SLACheckResult{RunAt: now.Format("02/01/2006 15:04:05")}
+
+	rules, err := s.slaRepo.GetAllRulesByFilter(ctx, bson.M{"deleted_at": nil, "active": true}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	stepRules := map[string]*models.SLARule{}
+	importanceRules := map[string]*models.SLARule{}
+	for _, r := range rules {
+		if r.Scope == "step" {
+			stepRules[slaStepKey(r.WorkFlowID, r.StepName)] = r
+		} else {
+			importanceRules[r.Importance] = r
+		}
+	}
+
+	tasks, err := s.taskRepo.GetAllTaskByFilter(ctx, bson.M{
+		"deleted_at": nil,
+		"status":     bson.M{"$nin": []string{"done", "cancelled"}},
+	}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	result.CheckedTasks = len(tasks)
+
+	departments, err := s.departmentRepo.GetAllDepartmentByFilter(ctx, bson.M{"deleted_at": nil}, bson.M{"department_id": 1, "manager_id": 1})
+	if err != nil {
+		return nil, err
+	}
+	managers := make(map[string]string, len(departments))
+	for _, d := range departments {
+		managers[d.DepartmentID] = d.ManagerID
+	}
+
+	openBreaches, err := s.slaRepo.GetAllBreachesByFilter(ctx, bson.M{"status": "open"}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	open := make(map[string]*models.SLABreach, len(openBreaches))
+	for _, b := range openBreaches {
+		open[slaBreachKey(b.TaskID, b.StepID, b.Level)] = b
+	}
+
+	current := map[string]time.Time{} // breach key ที่ยังเกิดอยู่ -> due_at
+	notices := make([]slaNotice, 0)
+
+	raise := func(t *models.Tasks, st *models.TaskWorkflowStep, ruleID, level string, due time.Time) {
+		stepID, stepName, owner, department := "", "", t.Assignee, t.Department
+		kind := "task"
+		if st != nil {
+			kind = "step"
+			stepID, stepName, owner = st.StepID, st.StepName, stepOwner(t, *st)
+			if st.Department != "" {
+				department = st.Department
+			}
+		}
+		key := slaBreachKey(t.TaskID, stepID, level)
+		current[key] = due
+		if level == "overdue" {
+			// คำเตือนเดิม (ถ้ามี) ยังเปิดไว้จนกว่างาน/step จะปิด
+			current[slaBreachKey(t.TaskID, stepID, "warning")] = due
+		}
+		if _, ok := open[key]; ok {
+			return
+		}
+
+		breach := models.SLABreach{
+			BreachID:     uuid.NewString(),
+			RuleID:       ruleID,
+			Kind:         kind,
+			Level:        level,
+			Status:       "open",
+			TaskID:       t.TaskID,
+			JobName:      t.JobName,
+			StepID:       stepID,
+			StepName:     stepName,
+			WorkFlowID:   t.WorkFlowID,
+			UserID:       owner,
+			DepartmentID: department,
+			DueAt:        due,
+			DetectedAt:   now,
+		}
+		notice := slaNotice{level: level, jobName: t.JobName, taskID: t.TaskID, stepName: stepName, ownerID: owner, due: due}
+		if level == "overdue" {
+			breach.OverdueHours = util.Round2(now.Sub(due).Hours())
+			if manager := managers[department]; manager != "" {
+				breach.EscalatedTo = manager
+				breach.EscalatedAt = &now
+				notice.managerID = manager
+				result.Escalated++
+			}
+			result.Overdue++
+		} else {
+			result.Warnings++
+		}
+		if err := s.slaRepo.CreateBreach(ctx, breach); err != nil {
+			log.Printf("[SLA] บันทึก breach ของงาน %s ไม่สำเร็จ: %v", t.TaskID, err)
+			return
+		}
+		open[key] = &breach
+		notices = append(notices, notice)
+	}
+
+	for _, t := range tasks {
+		// 1) ระดับงาน: เทียบ end_date (ใช้ warn_percent ของกฎ importance ถ้ามี)
+		taskStatus := ""
+		if due, start, ok := taskSLAWindow(t); ok {
+			warn := float64(defaultSLAWarnPercent)
+			ruleID := ""
+			if r := importanceRules[strings.ToLower(t.Importance)]; r != nil {
+				warn, ruleID = r.WarnPercent, r.RuleID
+			}
+			taskStatus = slaLevel(start, due, warn, now)
+			if taskStatus != "on_track" {
+				raise(t, nil, ruleID, taskStatus, due)
+			}
+		}
+		if taskStatus != t.SLAStatus {
+			if _, err := s.taskRepo.UpdateManyTaskFields(ctx, bson.M{"task_id": t.TaskID}, bson.M{"sla_status": taskStatus}); err != nil {
+				log.Printf("[SLA] อัปเดตสถานะ SLA ของงาน %s ไม่สำเร็จ: %v", t.TaskID, err)
+			} else {
+				result.UpdatedTasks++
+			}
+		}
+
+		// 2) ระดับ step: เฉพาะ step ที่กำลังทำ นับจากเวลาเริ่ม step
+		for i := range t.AppliedWorkflow.Steps {
+			st := &t.AppliedWorkflow.Steps[i]
+			if st.Status != "in_progress" || st.StartedAt == nil {
+				continue
+			}
+			target, warn, ruleID := stepSLATarget(t, st, stepRules, importanceRules)
+			if target <= 0 {
+				continue
+			}
+			due := st.StartedAt.Add(time.Duration(target * float64(time.Hour)))
+			level := slaLevel(*st.StartedAt, due, warn, now)
+			if level != "on_track" {
+				raise(t, st, ruleID, level, due)
+			}
+			if st.SLAStatus == level && st.SLADueAt != nil && st.SLADueAt.Equal(due) {
+				continue
+			}
+			_, err := s.taskRepo.UpdateManyTaskFields(ctx,
+				bson.M{"task_id": t.TaskID, "applied_workflow.steps.step_id": st.StepID},
+				bson.M{"applied_workflow.steps.$.sla_status": level, "applied_workflow.steps.$.sla_due_at": due},
+			)
+			if err != nil {
+				log.Printf("[SLA] อัปเดต SLA ของ step %s ไม่สำเร็จ: %v", st.StepID, err)
+			}
+		}
+	}
+
+	// 3) breach ที่ยังเปิด: อัปเดตชั่วโมงเกินกำหนด หรือปิดถ้าไม่เกิดแล้ว (งานเสร็จ/step ปิด/เลื่อนกำหนด)
+	for key, b := range open {
+		due, still := current[key]
+		if still {
+			if b.Level != "overdue" {
+				continue
+			}
+			hours := util.Round2(now.Sub(due).Hours())
+			if hours == b.OverdueHours && due.Equal(b.DueAt) {
+				continue
+			}
+			b.DueAt = due
+			b.OverdueHours = hours
+			if _, err := s.slaRepo.UpdateBreachByID(ctx, b.BreachID, *b); err == nil {
+				result.UpdatedBreaches++
+			}
+			continue
+		}
+		b.Status = "resolved"
+		b.ResolvedAt = &now
+		if b.Level == "overdue" {
+			b.OverdueHours = util.Round2(math.Max(0, now.Sub(b.DueAt).Hours()))
+		}
+		if _, err := s.slaRepo.UpdateBreachByID(ctx, b.BreachID, *b); err != nil {
+			log.Printf("[SLA] ปิด breach %s ไม่สำเร็จ: %v", b.BreachID, err)
+			continue
+		}
+		result.Resolved++
+	}
+
+	s.sendNotices(ctx, notices)
+	return result, nil
+}
+
+type slaNotice struct {
+	level     string
+	jobName   string
+	taskID    string
+	stepName  string
+	ownerID   string
+	managerID string
+	due       time.Time
+}
+
+// sendNotices ส่งอีเมลเตือนผู้รับผิดชอบ และแจ้งผู้จัดการแผนกเมื่อเกินกำหนด
+func (s *slaService) sendNotices(ctx context.Context, notices []slaNotice) {
+	if len(notices) == 0 || strings.TrimSpace(s.config.Email.Host) == "" {
+		return
+	}
+	emailCfg := util.EmailConfig{
+		Host:     s.config.Email.Host,
+		Port:     s.config.Email.Port,
+		Username: s.config.Email.Username,
+		Password: s.config.Email.Password,
+		From:     s.config.Email.From,
+	}
+	loc := helpers.BangkokLocation()
+
+	for _, n := range notices {
+		target := fmt.Sprintf("งาน \"%s\"", n.jobName)
+		if n.stepName != "" {
+			target = fmt.Sprintf("ขั้นตอน \"%s\" ของงาน \"%s\"", n.stepName, n.jobName)
+		}
+		due := n.due.In(loc).Format("02/01/2006 15:04")
+
+		recipients := []string{n.ownerID}
+		subject := fmt.Sprintf("SLA warning: %s", n.jobName)
+		body := fmt.Sprintf("%s ใกล้ถึงกำหนดตาม SLA (%s)\n\nTask ID: %s\n", target, due, n.taskID)
+		if n.level == "overdue" {
+			subject = fmt.Sprintf("SLA overdue: %s", n.jobName)
+			body = fmt.Sprintf("%s เกินกำหนดตาม SLA แล้ว (กำหนด %s)\n\nTask ID: %s\n", target, due, n.taskID)
+			if n.managerID != "" && n.managerID != n.ownerID {
+				recipients = append(recipients, n.managerID)
+			}
+		}
+
+		for _, userID := range recipients {
+			if userID == "" {
+				continue
+			}
+			user, err := s.userRepo.GetByID(ctx, userID)
+			if err != nil || user == nil || strings.TrimSpace(user.Email) == "" {
+				continue
+			}
+			if err := util.SendMail(emailCfg, user.Email, subject, fmt.Sprintf("เรียนคุณ %s\n\n%s", user.FirstNameTH, body)); err != nil {
+				log.Println("Error sending SLA email:", err)
+			}
+		}
+	}
+}
+
+// breachScope admin เห็นทั้งหมด, ผู้จัดการเห็นแผนกที่ดูแล (รวมของตนเอง), พนักงานเห็นเฉพาะของตนเอง
+func (s *slaService) breachScope(ctx context.Context, departmentID string, claims *dto.JWTClaims) (bson.M, error) {
+	filter := bson.M{}
+	departmentID = strings.TrimSpace(departmentID)
+	if claims.Role == "admin" {
+		if departmentID != "" {
+			filter["department_id"] = departmentID
+		}
+		return filter, nil
+	}
+
+	departments, err := s.departmentRepo.GetAllDepartmentByFilter(ctx, bson.M{"manager_id": claims.UserID, "deleted_at": nil}, bson.M{"department_id": 1})
+	if err != nil {
+		return nil, err
+	}
+	managed := make([]string, 0, len(departments))
+	for _, d := range departments {
+		managed = append(managed, d.DepartmentID)
+	}
+
+	switch {
+	case departmentID != "":
+		if !helpers.InSet(departmentID, managed...) {
+			return nil, ports.ErrSLAForbidden
+		}
+		filter["department_id"] = departmentID
+	case len(managed) > 0:
+		filter["$or"] = bson.A{
+			bson.M{"department_id": bson.M{"$in": managed}},
+			bson.M{"user_id": claims.UserID},
+		}
+	default:
+		filter["user_id"] = claims.UserID
+	}
+	return filter, nil
+}
+
+func (s *slaService) toRuleDTO(ctx context.Context, r *models.SLARule, workflows map[string]string) dto.SLARuleDTO {
+	out := dto.SLARuleDTO{
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+		RuleID:      r.RuleID,
+		Name:        r.Name,
+		Scope:       r.Scope,
+		WorkFlowID:  r.WorkFlowID,
+		StepName:    r.StepName,
+		Importance:  r.Importance,
+		TargetHours: r.TargetHours,
+		HoursFactor: r.HoursFactor,
+		WarnPercent: r.WarnPercent,
+		Active:      r.Active,
+		CreatedBy:   r.CreatedBy,
+	}
+	if r.WorkFlowID != "" {
+		out.WorkFlowName = s.workflowName(ctx, r.WorkFlowID, workflows)
+	}
+	return out
+}
+
+func (s *slaService) userName(ctx context.Context, userID string, cache map[string]string) string {
+	if name, ok := cache[userID]; ok {
+		return name
+	}
+	name := "ไม่พบชื่อผู้ใช้"
+	if user, _ := s.userRepo.GetByID(ctx, userID); user != nil {
+		name = fmt.Sprintf("%s %s %s", user.TitleTH, user.FirstNameTH, user.LastNameTH)
+	}
+	cache[userID] = name
+	return name
+}
+
+func (s *slaService) departmentName(ctx context.Context, departmentID string, cache map[string]string) string {
+	if name, ok := cache[departmentID]; ok {
+		return name
+	}
+	name := "ไม่พบแผนก"
+	if dept, _ := s.departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{"department_id": departmentID, "deleted_at": nil}, bson.M{"department_name": 1}); dept != nil {
+		name = dept.DepartmentName
+	}
+	cache[departmentID] = name
+	return name
+}
+
+func (s *slaService) workflowName(ctx context.Context, workflowID string, cache map[string]string) string {
+	if name, ok := cache[workflowID]; ok {
+		return name
+	}
+	name := "ไม่พบ workflow"
+	if wf, _ := s.workFlowRepo.GetOneWorkFlowTemplateByFilter(ctx, bson.M{"workflow_id": workflowID}, bson.M{"workflow_name": 1}); wf != nil {
+		name = wf.WorkFlowName
+	}
+	cache[workflowID] = name
+	return name
+}
+
+func validateSLARule(r *models.SLARule) error {
+	if r.WarnPercent <= 0 || r.WarnPercent > 100 {
+		return errors.New("warn_percent must be between 1 and 100")
+	}
+	if r.TargetHours < 0 || r.HoursFactor < 0 {
+		return errors.New("target_hours and hours_factor must not be negative")
+	}
+	return nil
+}
+
+func slaRuleKeyFilter(r *models.SLARule) bson.M {
+	filter := bson.M{"deleted_at": nil, "active": true, "scope": r.Scope}
+	if r.Scope == "step" {
+		filter["workflow_id"] = r.WorkFlowID
+		filter["step_name"] = r.StepName
+	} else {
+		filter["importance"] = r.Importance
+	}
+	return filter
+}
+
+func slaStepKey(workflowID, stepName string) string {
+	return workflowID + "/" + strings.ToLower(strings.TrimSpace(stepName))
+}
+
+func slaBreachKey(taskID, stepID, level string) string {
+	return taskID + "/" + stepID + "/" + level
+}
+
+// taskSLAWindow คืนกำหนดเสร็จของงาน (สิ้นวันของ end_date ถ้าไม่ได้ระบุเวลา) และเวลาเริ่มนับ
+func taskSLAWindow(t *models.Tasks) (due, start time.Time, ok bool) {
+	if t.EndDate.IsZero() {
+		return time.Time{}, time.Time{}, false
+	}
+	due = t.EndDate
+	if due.Equal(helpers.DateOnly(due)) {
+		due = due.AddDate(0, 0, 1)
+	}
+	start = t.StartDate
+	if start.IsZero() || !start.Before(due) {
+		start = t.CreatedAt
+	}
+	return due, start, true
+}
+
+// stepSLATarget ชั่วโมงที่ยอมให้สำหรับ step: กฎของ step ก่อน แล้วค่อยกฎ importance (ตัวคูณชั่วโมงประมาณการ)
+func stepSLATarget(t *models.Tasks, st *models.TaskWorkflowStep, stepRules, importanceRules map[string]*models.SLARule) (float64, float64, string) {
+	if r := stepRules[slaStepKey(t.WorkFlowID, st.StepName)]; r != nil {
+		target := r.TargetHours
+		if target <= 0 {
+			target = st.Hours
+		}
+		return target, r.WarnPercent, r.RuleID
+	}
+	if r := importanceRules[strings.ToLower(t.Importance)]; r != nil && r.HoursFactor > 0 {
+		return st.Hours * r.HoursFactor, r.WarnPercent, r.RuleID
+	}
+	return 0, 0, ""
+}
+
+// slaLevel on_track|warning|overdue ตามสัดส่วนเวลาที่ผ่านไปเทียบช่วง start..due
+func slaLevel(start, due time.Time, warnPercent float64, now time.Time) string {
+	if !now.Before(due) {
+		return "overdue"
+	}
+	total := due.Sub(start)
+	if total <= 0 {
+		return "on_track"
+	}
+	if warnPercent <= 0 {
+		warnPercent = defaultSLAWarnPercent
+	}
+	if float64(now.Sub(start))/float64(total)*100 >= warnPercent {
+		return "warning"
+	}
+	return "on_track"
+}
+
+func applyDetectedRange(filter bson.M, from, to string) error {
+	rng := bson.M{}
+	if v := strings.TrimSpace(from); v != "" {
+		d, err := helpers.DateToISO(v)
+		if err != nil {
+			return fmt.Errorf("invalid from: %w", err)
+		}
+		rng["$gte"] = d
+	}
+	if v := strings.TrimSpace(to); v != "" {
+		d, err := helpers.DateToISO(v)
+		if err != nil {
+			return fmt.Errorf("invalid to: %w", err)
+		}
+		rng["$lt"] = d.AddDate(0, 0, 1)
+	}
+	if len(rng) > 0 {
+		filter["detected_at"] = rng
+	}
+	return nil
+}
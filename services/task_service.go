@@ -13,6 +13,7 @@ import (
 	"github.com/Be2Bag/erp-demo/dto"
 	"github.com/Be2Bag/erp-demo/models"
 	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/pkg/util"
 	"github.com/Be2Bag/erp-demo/ports"
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
@@ -28,11 +29,12 @@ type taskService struct {
 	kpiEvaluationRepo ports.KPIEvaluationRepository
 	kpiRepo           ports.KPIRepository
 	signJobRepo       ports.SignJobRepository
+	activityRepo      ports.ActivityRepository
 	config            config.Config
 }
 
-func NewTaskService(cfg config.Config, taskRepo ports.TaskRepository, userRepo ports.UserRepository, workflowRepo ports.WorkFlowRepository, departmentRepo ports.DepartmentRepository, kpiEvaluationRepo ports.KPIEvaluationRepository, kpiRepo ports.KPIRepository, signJobRepo ports.SignJobRepository) ports.TaskService {
-	return &taskService{config: cfg, taskRepo: taskRepo, userRepo: userRepo, workflowRepo: workflowRepo, departmentRepo: departmentRepo, kpiEvaluationRepo: kpiEvaluationRepo, kpiRepo: kpiRepo, signJobRepo: signJobRepo}
+func NewTaskService(cfg config.Config, taskRepo ports.TaskRepository, userRepo ports.UserRepository, workflowRepo ports.WorkFlowRepository, departmentRepo ports.DepartmentRepository, kpiEvaluationRepo ports.KPIEvaluationRepository, kpiRepo ports.KPIRepository, signJobRepo ports.SignJobRepository, activityRepo ports.ActivityRepository) ports.TaskService {
+	return &taskService{config: cfg, taskRepo: taskRepo, userRepo: userRepo, workflowRepo: workflowRepo, departmentRepo: departmentRepo, kpiEvaluationRepo: kpiEvaluationRepo, kpiRepo: kpiRepo, signJobRepo: signJobRepo, activityRepo: activityRepo}
 }
 
 func (s *taskService) GetListTasks(ctx context.Context, claims *dto.JWTClaims, page, size int, search string, department string, sortBy string, sortOrder string, status string) (dto.Pagination, error) {
@@ -124,17 +126,25 @@ func (s *taskService) GetListTasks(ctx context.Context, claims *dto.JWTClaims, p
 		steps := make([]dto.TaskWorkflowStep, 0, len(m.AppliedWorkflow.Steps))
 		for _, st := range m.AppliedWorkflow.Steps {
 			steps = append(steps, dto.TaskWorkflowStep{
-				StepID:      st.StepID,
-				StepName:    st.StepName,
-				Description: st.Description,
-				Hours:       st.Hours,
-				Order:       st.Order,
-				Status:      st.Status,
-				StartedAt:   st.StartedAt,
-				CompletedAt: st.CompletedAt,
-				Notes:       st.Notes,
-				CreatedAt:   st.CreatedAt,
-				UpdatedAt:   st.UpdatedAt,
+				StepID:       st.StepID,
+				StepName:     st.StepName,
+				Description:  st.Description,
+				Hours:        st.Hours,
+				Order:        st.Order,
+				DependsOn:    st.DependsOn,
+				Department:   st.Department,
+				Assignee:     stepOwner(&m, st),
+				AssigneeName: stepAssigneeName(&m, st),
+				SLADueAt:     st.SLADueAt,
+				SLAStatus:    st.SLAStatus,
+				Status:       st.Status,
+				StartedAt:    st.StartedAt,
+				CompletedAt:  st.CompletedAt,
+				Notes:        st.Notes,
+				CreatedAt:    st.CreatedAt,
+				UpdatedAt:    st.UpdatedAt,
+
+				Checklist: toChecklistDTOs(st.Checklist),
 			})
 		}
 		list = append(list, dto.TaskDTO{
@@ -174,6 +184,8 @@ func (s *taskService) GetListTasks(ctx context.Context, claims *dto.JWTClaims, p
 
 			Status:        m.Status,
 			StepName:      m.StepName,
+			ReadySteps:    m.ReadySteps,
+			SLAStatus:     m.SLAStatus,
 			CreatedBy:     m.CreatedBy,
 			CreatedByName: createdByName,
 			CreatedAt:     m.CreatedAt,
@@ -236,22 +248,18 @@ func (s *taskService) CreateTask(ctx context.Context, createTask dto.CreateTaskR
 	var total float64
 
 	if !createTask.IsEdit {
-		for _, st := range workflow.Steps {
-			steps = append(steps, models.TaskWorkflowStep{
-				StepID:      uuid.NewString(),
-				StepName:    st.StepName,
-				Description: st.Description,
-				Hours:       st.Hours,
-				Order:       st.Order,
-				Status:      "todo",
-				Notes:       "",
-				CreatedAt:   now,
-				UpdatedAt:   now,
-			})
-			total += st.Hours
+		steps, total = snapshotWorkflowSteps(workflow, now)
+		managers, err := s.departmentManagers(ctx)
+		if err != nil {
+			return err
 		}
+		defaultStepAssignees(ctx, s.userRepo, steps, createTask.Assignee, createTask.Department, managers)
 	} else {
 		for i, st := range createTask.ExtraSteps {
+			checklist, err := normalizeChecklist(st.Checklist)
+			if err != nil {
+				return err
+			}
 			steps = append(steps, models.TaskWorkflowStep{
 				StepID:      uuid.NewString(),
 				StepName:    st.StepName,
@@ -260,6 +268,7 @@ func (s *taskService) CreateTask(ctx context.Context, createTask dto.CreateTaskR
 				Order:       i + 1, // คง logic เดิมไว้
 				Status:      "todo",
 				Notes:       "",
+				Checklist:   newChecklist(checklist),
 				CreatedAt:   now,
 				UpdatedAt:   now,
 			})
@@ -276,27 +285,13 @@ func (s *taskService) CreateTask(ctx context.Context, createTask dto.CreateTaskR
 		Steps:        steps,
 		Version:      1,
 	}
-
-	// [ADD] เลือกชื่อสเต็ปปัจจุบัน (in_progress > todo > สุดท้าย) — วางไว้ก่อนสร้าง model
-	curStepName := ""
-	for _, s := range steps {
-		if s.Status == "in_progress" {
-			curStepName = s.StepName
-			break
-		}
-	}
-	if curStepName == "" {
-		for _, s := range steps {
-			if s.Status == "todo" {
-				curStepName = s.StepName
-				break
-			}
-		}
-	}
-	if curStepName == "" && len(steps) > 0 {
-		curStepName = steps[len(steps)-1].StepName
+	if !createTask.IsEdit {
+		AppliedWorkflow.TemplateVersion = workflow.Version
 	}
 
+	// [ADD] เลือกชื่อสเต็ปปัจจุบัน (in_progress > step ที่เริ่มได้ > todo > สุดท้าย) — วางไว้ก่อนสร้าง model
+	curStepName := helpers.CurrentStepName(steps)
+
 	assigneeName := "ไม่พบชื่อผู้รับผิดชอบ"
 	assigneeNickName := "ไม่พบชื่อเล่น"
 
@@ -330,12 +325,13 @@ func (s *taskService) CreateTask(ctx context.Context, createTask dto.CreateTaskR
 
 		AppliedWorkflow: AppliedWorkflow,
 
-		Status:    "todo",
-		StepName:  curStepName,
-		CreatedBy: claims.UserID,
-		CreatedAt: now,
-		UpdatedAt: now,
-		DeletedAt: nil,
+		Status:     "todo",
+		StepName:   curStepName,
+		ReadySteps: helpers.ReadyStepNames(steps),
+		CreatedBy:  claims.UserID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		DeletedAt:  nil,
 	}
 
 	if err := s.taskRepo.CreateTask(ctx, model); err != nil {
@@ -343,66 +339,8 @@ func (s *taskService) CreateTask(ctx context.Context, createTask dto.CreateTaskR
 	}
 
 	// <============================ UpsertUserTaskStats ============================>
-
-	filterUserTaskStats := bson.M{"user_id": createTask.Assignee} // ถ้าใช้ period ให้ใส่เพิ่ม: filter["period"] = models.StatsPeriodAll
-	projectionUserTaskStats := bson.M{}
-
-	existingStats, err := s.taskRepo.GetOneUserTaskStatsByFilter(ctx, filterUserTaskStats, projectionUserTaskStats)
-	if err != nil && err != mongo.ErrNoDocuments {
-		return err
-	}
-
-	nowUTC := time.Now().UTC()
-
-	var totals models.UserTaskTotals
-	var kpi models.UserTaskKPI
-	var createdAt time.Time
-
-	if existingStats == nil {
-		// สร้างเริ่มต้น
-		totals = models.UserTaskTotals{
-			Assigned:   1,
-			Open:       1,
-			InProgress: 0,
-			Completed:  0,
-			Skipped:    0,
-		}
-		createdAt = nowUTC
-	} else {
-		totals = existingStats.Totals
-		totals.Assigned++
-		totals.Open++
-		// กันค่าติดลบ (เผื่อข้อมูลก่อนหน้าเพี้ยน)
-		if totals.Assigned < 0 {
-			totals.Assigned = 0
-		}
-		if totals.Open < 0 {
-			totals.Open = 0
-		}
-		if totals.InProgress < 0 {
-			totals.InProgress = 0
-		}
-		if totals.Completed < 0 {
-			totals.Completed = 0
-		}
-		if totals.Skipped < 0 {
-			totals.Skipped = 0
-		}
-		kpi = existingStats.KPI
-		createdAt = existingStats.CreatedAt
-	}
-
-	statsDoc := &models.UserTaskStats{
-		UserID:       createTask.Assignee,
-		DepartmentID: createTask.Department,
-		Totals:       totals,
-		KPI:          kpi,
-		CreatedAt:    createdAt,
-		UpdatedAt:    nowUTC,
-		DeletedAt:    nil,
-	}
-
-	if err := s.taskRepo.UpsertUserTaskStats(ctx, statsDoc); err != nil {
+	// นับงานให้ผู้รับผิดชอบหลักและเจ้าของ step ทุกคน
+	if err := applyTaskStatsDiff(ctx, s.taskRepo, nil, taskOwnerShares(&model)); err != nil {
 		return err
 	}
 
@@ -449,20 +387,33 @@ func (s *taskService) GetTaskByID(ctx context.Context, taskID string) (*dto.Task
 	steps := make([]dto.TaskWorkflowStep, 0, len(m.AppliedWorkflow.Steps))
 	for _, st := range m.AppliedWorkflow.Steps {
 		steps = append(steps, dto.TaskWorkflowStep{
-			StepID:      st.StepID,
-			StepName:    st.StepName,
-			Description: st.Description,
-			Hours:       st.Hours,
-			Order:       st.Order,
-			Status:      st.Status,
-			StartedAt:   st.StartedAt,
-			CompletedAt: st.CompletedAt,
-			Notes:       st.Notes,
-			CreatedAt:   st.CreatedAt,
-			UpdatedAt:   st.UpdatedAt,
+			StepID:       st.StepID,
+			StepName:     st.StepName,
+			Description:  st.Description,
+			Hours:        st.Hours,
+			Order:        st.Order,
+			DependsOn:    st.DependsOn,
+			Department:   st.Department,
+			Assignee:     stepOwner(m, st),
+			AssigneeName: stepAssigneeName(m, st),
+			SLADueAt:     st.SLADueAt,
+			SLAStatus:    st.SLAStatus,
+			Status:       st.Status,
+			StartedAt:    st.StartedAt,
+			CompletedAt:  st.CompletedAt,
+			Notes:        st.Notes,
+			CreatedAt:    st.CreatedAt,
+			UpdatedAt:    st.UpdatedAt,
+
+			Checklist: toChecklistDTOs(st.Checklist),
 		})
 	}
 
+	history := make([]dto.TaskReassignmentDTO, 0, len(m.ReassignHistory))
+	for _, h := range m.ReassignHistory {
+		history = append(history, toTaskReassignmentDTO(h))
+	}
+
 	dtoObj := &dto.TaskDTO{
 		TaskID:      m.TaskID,
 		ProjectID:   m.ProjectID,
@@ -497,9 +448,12 @@ func (s *taskService) GetTaskByID(ctx context.Context, taskID string) (*dto.Task
 			Steps:        steps,
 			Version:      m.AppliedWorkflow.Version,
 		},
+		ReassignHistory: history,
 
 		Status:        m.Status,
 		StepName:      m.StepName,
+		ReadySteps:    m.ReadySteps,
+		SLAStatus:     m.SLAStatus,
 		CreatedBy:     m.CreatedBy,
 		CreatedByName: createdByName,
 		CreatedAt:     m.CreatedAt,
@@ -513,7 +467,7 @@ func (s *taskService) DeleteTask(ctx context.Context, taskID string, claims *dto
 
 	t, _ := s.taskRepo.GetOneTasksByFilter(ctx,
 		bson.M{"task_id": taskID, "deleted_at": nil},
-		bson.M{"assignee": 1, "status": 1, "department_id": 1},
+		bson.M{"assignee": 1, "status": 1, "department_id": 1, "applied_workflow.steps": 1},
 	)
 
 	err := s.taskRepo.SoftDeleteTaskByID(ctx, taskID)
@@ -522,55 +476,8 @@ func (s *taskService) DeleteTask(ctx context.Context, taskID string, claims *dto
 	}
 
 	// <============================ Update Stats Inline ============================>
-	if t != nil && strings.TrimSpace(t.Assignee) != "" {
-		updateStats := func(userID string, assignedDelta, openDelta, inProgDelta, completedDelta int) error {
-			existingStats, err := s.taskRepo.GetOneUserTaskStatsByFilter(ctx, bson.M{"user_id": userID}, bson.M{})
-			if err != nil && err != mongo.ErrNoDocuments {
-				return err
-			}
-			nowUTC := time.Now().UTC()
-
-			totals := models.UserTaskTotals{}
-			createdAt := nowUTC
-			if existingStats != nil {
-				totals = existingStats.Totals
-				createdAt = existingStats.CreatedAt
-			}
-			totals.Assigned += assignedDelta
-			totals.Open += openDelta
-			totals.InProgress += inProgDelta
-			totals.Completed += completedDelta
-			if totals.Assigned < 0 {
-				totals.Assigned = 0
-			}
-			if totals.Open < 0 {
-				totals.Open = 0
-			}
-			if totals.InProgress < 0 {
-				totals.InProgress = 0
-			}
-			if totals.Completed < 0 {
-				totals.Completed = 0
-			}
-
-			return s.taskRepo.UpsertUserTaskStats(ctx, &models.UserTaskStats{
-				UserID:       userID,
-				DepartmentID: t.Department,
-				Totals:       totals,
-				KPI:          models.UserTaskKPI{Score: nil, LastCalculatedAt: nil},
-				CreatedAt:    createdAt,
-				UpdatedAt:    nowUTC,
-			})
-		}
-
-		switch t.Status {
-		case "done":
-			_ = updateStats(t.Assignee, -1, 0, 0, -1)
-		case "in_progress":
-			_ = updateStats(t.Assignee, -1, -1, -1, 0)
-		default: // "todo"
-			_ = updateStats(t.Assignee, -1, -1, 0, 0)
-		}
+	if t != nil {
+		_ = applyTaskStatsDiff(ctx, s.taskRepo, taskOwnerShares(t), nil)
 	}
 
 	return nil
@@ -581,20 +488,35 @@ func (s *taskService) UpdateStepStatus(ctx context.Context, taskID, stepID strin
 
 	now := time.Now()
 
-	// ดึงสถานะ/assignee ปัจจุบันไว้ก่อน (เพื่อเทียบหลังอัปเดต)
-	prevTask, _ := s.taskRepo.GetOneTasksByFilter(ctx,
+	// ดึงงานปัจจุบันไว้ก่อน (เพื่อตรวจสิทธิ์และเทียบหลังอัปเดต)
+	prevTask, err := s.taskRepo.GetOneTasksByFilter(ctx,
 		bson.M{"task_id": taskID, "deleted_at": nil},
-		bson.M{"assignee": 1, "status": 1, "department_id": 1},
+		bson.M{},
 	)
-	var prevStatus, assignee, department string
-	if prevTask != nil {
-		if prevTask.Assignee != claims.UserID {
-			return fmt.Errorf("user is not the assignee of this task")
+	if err != nil {
+		return err
+	}
+	if prevTask == nil {
+		return mongo.ErrNoDocuments
+	}
+
+	var target *models.TaskWorkflowStep
+	for i := range prevTask.AppliedWorkflow.Steps {
+		if prevTask.AppliedWorkflow.Steps[i].StepID == stepID {
+			target = &prevTask.AppliedWorkflow.Steps[i]
+			break
 		}
+	}
+	if target == nil {
+		return mongo.ErrNoDocuments
+	}
 
-		prevStatus = prevTask.Status
-		assignee = prevTask.Assignee
-		department = prevTask.Department
+	// ผู้รับผิดชอบ step หรือผู้รับผิดชอบหลักของงานเท่านั้น
+	if claims.UserID != prevTask.Assignee && claims.UserID != stepOwner(prevTask, *target) {
+		return fmt.Errorf("user is not the assignee of this task")
+	}
+	if prevTask.Status == "cancelled" {
+		return fmt.Errorf("task has been cancelled")
 	}
 
 	var normalized *string
@@ -608,13 +530,31 @@ func (s *taskService) UpdateStepStatus(ctx context.Context, taskID, stepID strin
 		}
 	}
 
+	// เริ่ม/ปิด step ได้ต่อเมื่อ prerequisite ทั้งหมด done หรือ skip แล้ว
+	if normalized != nil && (*normalized == "in_progress" || *normalized == "done") {
+		if pending := helpers.PendingPrerequisites(prevTask.AppliedWorkflow.Steps, stepID); len(pending) > 0 {
+			names := make([]string, 0, len(pending))
+			for _, p := range pending {
+				names = append(names, p.StepName)
+			}
+			return fmt.Errorf("%w: %s", ports.ErrStepPrerequisitesPending, strings.Join(names, ", "))
+		}
+	}
+
+	// ปิด step เป็น done ได้ต่อเมื่อติ๊ก checklist ครบ (skip ไม่ต้องครบ)
+	if normalized != nil && *normalized == "done" && target.Status != "done" {
+		if pending := uncheckedItems(target.Checklist); len(pending) > 0 {
+			return fmt.Errorf("%w: %s", ports.ErrStepChecklistIncomplete, strings.Join(pending, ", "))
+		}
+	}
+
 	// อัปเดตฟิลด์ในสเต็ป (status และ/หรือ notes)
 	if err := s.taskRepo.UpdateOneStepFields(ctx, taskID, stepID, normalized, req.Notes, now); err != nil {
 		return err
 	}
 
-	// โหลด steps ทั้งหมด เพื่อสรุปสถานะงาน + คืน step ที่อัปเดตแล้ว
-	steps, err := s.taskRepo.GetAllStepSteps(ctx, taskID) // เปลี่ยนชื่อฟังก์ชันให้ตรงความจริง
+	// โหลด steps ทั้งหมด เพื่อสรุปสถานะงาน
+	steps, err := s.taskRepo.GetAllStepSteps(ctx, taskID)
 	if err != nil {
 		return err
 	}
@@ -622,124 +562,402 @@ func (s *taskService) UpdateStepStatus(ctx context.Context, taskID, stepID strin
 		return mongo.ErrNoDocuments
 	}
 
-	// หา step ที่เพิ่งแก้
-	var updatedStep *models.TaskWorkflowStep
-	for i := range steps {
-		if steps[i].StepID == stepID {
-			updatedStep = &steps[i]
+	// สรุปสถานะงานจาก steps (skip = ปิดสเต็ปเหมือน done)
+	newTaskStatus := helpers.DeriveTaskStatusFromSteps(steps)
+
+	// [ADD] คำนวณ step_name ปัจจุบัน + step ที่เริ่มได้ทันทีจาก steps ล่าสุด
+	curStepName := helpers.CurrentStepName(steps)
+
+	if err := s.taskRepo.UpdateTaskStatus(ctx, taskID, newTaskStatus, curStepName, helpers.ReadyStepNames(steps), now); err != nil {
+		return err
+	}
+
+	updatedTask := *prevTask
+	updatedTask.AppliedWorkflow.Steps = steps
+	updatedTask.Status = newTaskStatus
+
+	// บันทึก activity (สถานะ/บันทึกของ step และสถานะงาน) ไว้ใน feed
+	activities := make([]models.Activity, 0, 3)
+	if normalized != nil && *normalized != target.Status {
+		activities = append(activities, models.Activity{StepID: stepID, StepName: target.StepName, Type: "step_status", FromValue: target.Status, ToValue: *normalized})
+	}
+	if req.Notes != nil && strings.TrimSpace(*req.Notes) != strings.TrimSpace(target.Notes) {
+		activities = append(activities, models.Activity{StepID: stepID, StepName: target.StepName, Type: "step_note", FromValue: target.Notes, ToValue: *req.Notes})
+	}
+	if newTaskStatus != prevTask.Status {
+		activities = append(activities, models.Activity{Type: "task_status", FromValue: prevTask.Status, ToValue: newTaskStatus})
+	}
+	s.recordActivities(ctx, prevTask, claims.UserID, activities...)
+
+	// ส่งต่องาน: แจ้งผู้รับผิดชอบ step ที่เพิ่งเริ่มได้
+	if normalized != nil && (*normalized == "done" || *normalized == "skip") {
+		s.notifyReadySteps(&updatedTask, prevTask.AppliedWorkflow.Steps, claims.UserID)
+	}
+
+	// <============================ Update Stats Inline ============================>
+	// ปรับตามส่วนงานของแต่ละคน (ผู้รับผิดชอบหลักตามสถานะงาน, เจ้าของ step ตามสถานะ step ของตัวเอง)
+	before := taskOwnerShares(prevTask)
+	after := taskOwnerShares(&updatedTask)
+	_ = applyTaskStatsDiff(ctx, s.taskRepo, before, after)
+
+	// [EVAL] ถ้ามีคนปิดส่วนงานของตัวเองครบ ให้สร้างแบบประเมินของคนนั้น
+	if anyShareCompleted(before, after) {
+		_ = s.CreateEvaluationIfNeeded(ctx, taskID)
+	}
+
+	return nil
+}
+
+func (s *taskService) AssignStep(ctx context.Context, taskID, stepID string, req dto.AssignStepRequest, claims *dto.JWTClaims) error {
+	task, err := s.taskRepo.GetOneTasksByFilter(ctx, bson.M{"task_id": taskID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return err
+	}
+	if task == nil {
+		return mongo.ErrNoDocuments
+	}
+	if task.Status == "cancelled" {
+		return fmt.Errorf("task has been cancelled")
+	}
+
+	var target *models.TaskWorkflowStep
+	for i := range task.AppliedWorkflow.Steps {
+		if task.AppliedWorkflow.Steps[i].StepID == stepID {
+			target = &task.AppliedWorkflow.Steps[i]
 			break
 		}
 	}
-	if updatedStep == nil {
+	if target == nil {
 		return mongo.ErrNoDocuments
 	}
+	if target.Status == "done" || target.Status == "skip" {
+		return fmt.Errorf("step is already closed")
+	}
 
-	// สรุปสถานะงานจาก steps (skip = ปิดสเต็ปเหมือน done)
-	newTaskStatus := helpers.DeriveTaskStatusFromSteps(steps)
-
-	// [ADD] คำนวณ step_name ปัจจุบันจาก steps ล่าสุด (in_progress > todo > สุดท้าย)
-	curStepName := ""
-	for _, s2 := range steps {
-		if s2.Status == "in_progress" {
-			curStepName = s2.StepName
-			break
+	// สิทธิ์: admin, ผู้สร้างงาน, ผู้รับผิดชอบหลัก หรือผู้จัดการแผนกของ step
+	allowed := claims.Role == "admin" || claims.UserID == task.CreatedBy || claims.UserID == task.Assignee
+	if !allowed {
+		department := target.Department
+		if department == "" {
+			department = task.Department
+		}
+		dept, err := s.departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{"department_id": department, "deleted_at": nil}, bson.M{"manager_id": 1})
+		if err != nil && err != mongo.ErrNoDocuments {
+			return err
 		}
+		allowed = dept != nil && dept.ManagerID == claims.UserID
 	}
-	if curStepName == "" {
-		for _, s2 := range steps {
-			if s2.Status == "todo" {
-				curStepName = s2.StepName
-				break
-			}
+	if !allowed {
+		return ports.ErrStepForbidden
+	}
+
+	assignee := strings.TrimSpace(req.Assignee)
+	if assignee == task.Assignee {
+		assignee = "" // คืนให้ผู้รับผิดชอบหลัก
+	}
+	assigneeName := ""
+	if assignee != "" {
+		user, err := s.userRepo.GetByID(ctx, assignee)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return err
 		}
+		if user == nil || user.DeletedAt != nil || user.Status != "approved" {
+			return fmt.Errorf("assignee not found or not approved")
+		}
+		assigneeName = fmt.Sprintf("%s %s %s", user.TitleTH, user.FirstNameTH, user.LastNameTH)
 	}
-	if curStepName == "" && len(steps) > 0 {
-		curStepName = steps[len(steps)-1].StepName
+	if assignee == target.Assignee {
+		return nil
 	}
 
-	if err := s.taskRepo.UpdateTaskStatus(ctx, taskID, newTaskStatus, curStepName, now); err != nil {
+	if err := s.taskRepo.UpdateStepAssignee(ctx, taskID, stepID, assignee, assigneeName, time.Now()); err != nil {
 		return err
 	}
 
-	// [EVAL] ถ้าเพิ่งเปลี่ยนเป็น done ให้สร้างแบบประเมิน
-	if prevStatus != "done" && newTaskStatus == "done" {
-		_ = s.CreateEvaluationIfNeeded(ctx, taskID)
+	updatedTask := *task
+	updatedTask.AppliedWorkflow.Steps = make([]models.TaskWorkflowStep, len(task.AppliedWorkflow.Steps))
+	copy(updatedTask.AppliedWorkflow.Steps, task.AppliedWorkflow.Steps)
+	for i := range updatedTask.AppliedWorkflow.Steps {
+		if updatedTask.AppliedWorkflow.Steps[i].StepID == stepID {
+			updatedTask.AppliedWorkflow.Steps[i].Assignee = assignee
+			updatedTask.AppliedWorkflow.Steps[i].AssigneeName = assigneeName
+		}
 	}
 
-	// <============================ Update Stats Inline ============================>
-	// ปรับเฉพาะเมื่อ status งานเปลี่ยน (assignee เดิม)
-	if assignee != "" && prevStatus != "" && prevStatus != newTaskStatus {
-		updateStats := func(userID string, assignedDelta, openDelta, inProgDelta, completedDelta int) error {
-			if strings.TrimSpace(userID) == "" {
-				return nil
-			}
-			existingStats, err := s.taskRepo.GetOneUserTaskStatsByFilter(ctx, bson.M{"user_id": userID}, bson.M{})
-			if err != nil && err != mongo.ErrNoDocuments {
-				return err
-			}
-			nowUTC := time.Now()
+	newOwnerName := assigneeName
+	if assignee == "" {
+		newOwnerName = task.AssigneeName
+	}
+	s.recordActivities(ctx, task, claims.UserID, models.Activity{
+		StepID:    stepID,
+		StepName:  target.StepName,
+		Type:      "step_assigned",
+		FromValue: stepAssigneeName(task, *target),
+		ToValue:   newOwnerName,
+	})
 
-			totals := models.UserTaskTotals{}
-			createdAt := nowUTC
-			if existingStats != nil {
-				totals = existingStats.Totals
-				createdAt = existingStats.CreatedAt
-			}
-			totals.Assigned += assignedDelta // ปกติ status change ไม่แตะ assigned (0)
-			totals.Open += openDelta
-			totals.InProgress += inProgDelta
-			totals.Completed += completedDelta
-			if totals.Assigned < 0 {
-				totals.Assigned = 0
-			}
-			if totals.Open < 0 {
-				totals.Open = 0
-			}
-			if totals.InProgress < 0 {
-				totals.InProgress = 0
-			}
-			if totals.Completed < 0 {
-				totals.Completed = 0
-			}
+	// ย้ายส่วนงานของ step ไปยังผู้รับผิดชอบใหม่ใน user_task_stats
+	return applyTaskStatsDiff(ctx, s.taskRepo, taskOwnerShares(task), taskOwnerShares(&updatedTask))
+}
 
-			return s.taskRepo.UpsertUserTaskStats(ctx, &models.UserTaskStats{
-				UserID:       userID,
-				DepartmentID: department,
-				Totals:       totals,
-				KPI:          models.UserTaskKPI{Score: nil, LastCalculatedAt: nil},
-				CreatedAt:    createdAt,
-				UpdatedAt:    nowUTC,
-			})
+const maxReassignReason = 500
+
+// ReassignTask เปลี่ยนผู้รับผิดชอบหลักพร้อมเหตุผล step ที่ปิดแล้วของผู้รับผิดชอบเดิมจะระบุชื่อเดิมไว้
+// เพื่อให้ผลงานที่ทำไปแล้วยังนับให้คนเดิม ส่วน step ที่ยังไม่ปิดตามผู้รับผิดชอบใหม่
+func (s *taskService) ReassignTask(ctx context.Context, taskID string, req dto.ReassignTaskRequest, claims *dto.JWTClaims) (*dto.TaskReassignmentDTO, error) {
+	task, err := s.taskRepo.GetOneTasksByFilter(ctx, bson.M{"task_id": taskID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	// สิทธิ์: admin, ผู้สร้างงาน, ผู้รับผิดชอบหลัก หรือผู้จัดการแผนกของงาน
+	allowed := claims.Role == "admin" || claims.UserID == task.CreatedBy || claims.UserID == task.Assignee
+	if !allowed {
+		dept, err := s.departmentRepo.GetOneDepartmentByFilter(ctx, bson.M{"department_id": task.Department, "deleted_at": nil}, bson.M{"manager_id": 1})
+		if err != nil && err != mongo.ErrNoDocuments {
+			return nil, err
 		}
+		allowed = dept != nil && dept.ManagerID == claims.UserID
+	}
+	if !allowed {
+		return nil, ports.ErrTaskReassignForbidden
+	}
 
-		switch prevStatus {
-		case "todo":
-			switch newTaskStatus {
-			case "in_progress":
-				_ = updateStats(assignee, 0, 0, +1, 0)
-			case "done":
-				_ = updateStats(assignee, 0, -1, 0, +1)
-			}
-		case "in_progress":
-			switch newTaskStatus {
-			case "todo":
-				_ = updateStats(assignee, 0, 0, -1, 0)
-			case "done":
-				_ = updateStats(assignee, 0, -1, -1, +1)
-			}
-		case "done":
-			switch newTaskStatus {
-			case "in_progress":
-				_ = updateStats(assignee, 0, +1, +1, -1)
-			case "todo":
-				_ = updateStats(assignee, 0, +1, 0, -1)
-			}
+	switch task.Status {
+	case "cancelled":
+		return nil, fmt.Errorf("task has been cancelled")
+	case "done":
+		return nil, fmt.Errorf("task is already completed")
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("reason is required")
+	}
+	if len([]rune(reason)) > maxReassignReason {
+		return nil, fmt.Errorf("reason must not exceed %d characters", maxReassignReason)
+	}
+	assignee := strings.TrimSpace(req.Assignee)
+	if assignee == "" {
+		return nil, fmt.Errorf("assignee is required")
+	}
+	if assignee == task.Assignee {
+		return nil, fmt.Errorf("task is already assigned to this user")
+	}
+	user, err := s.userRepo.GetByID(ctx, assignee)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+	if user == nil || user.DeletedAt != nil || user.Status != "approved" {
+		return nil, fmt.Errorf("assignee not found or not approved")
+	}
+	assigneeName := fmt.Sprintf("%s %s %s", user.TitleTH, user.FirstNameTH, user.LastNameTH)
+
+	now := time.Now()
+	updatedTask := *task
+	updatedTask.Assignee = assignee
+	updatedTask.AssigneeName = assigneeName
+	updatedTask.AssigneeNickName = user.NickName
+	updatedTask.UpdatedAt = now
+	updatedTask.AppliedWorkflow.Steps = make([]models.TaskWorkflowStep, len(task.AppliedWorkflow.Steps))
+	copy(updatedTask.AppliedWorkflow.Steps, task.AppliedWorkflow.Steps)
+
+	transferred := make([]string, 0)
+	for i := range updatedTask.AppliedWorkflow.Steps {
+		st := &updatedTask.AppliedWorkflow.Steps[i]
+		closed := st.Status == "done" || st.Status == "skip"
+		switch {
+		case st.Assignee == assignee:
+			// step ที่ระบุผู้รับผิดชอบใหม่ไว้แล้ว กลายเป็นของผู้รับผิดชอบหลัก
+			st.Assignee, st.AssigneeName = "", ""
+		case st.Assignee == "" && closed && task.Assignee != "":
+			st.Assignee, st.AssigneeName = task.Assignee, task.AssigneeName
+		case st.Assignee == task.Assignee && !closed && req.TransferSteps:
+			st.Assignee, st.AssigneeName = "", ""
+			transferred = append(transferred, st.StepID)
+		default:
+			continue
+		}
+		st.UpdatedAt = now
+	}
+
+	entry := models.TaskReassignment{
+		ReassignedAt: now,
+		FromAssignee: task.Assignee,
+		FromName:     task.AssigneeName,
+		ToAssignee:   assignee,
+		ToName:       assigneeName,
+		Reason:       reason,
+		StepIDs:      transferred,
+		ReassignedBy: claims.UserID,
+	}
+	updated, err := s.taskRepo.ReassignTask(ctx, taskID, task.Assignee, &updatedTask, entry)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ports.ErrTaskReassignConflict
+	}
+
+	from := task.AssigneeName
+	if from == "" {
+		from = task.Assignee
+	}
+	s.recordActivities(ctx, task, claims.UserID, models.Activity{
+		Type:      "task_reassigned",
+		FromValue: from,
+		ToValue:   assigneeName,
+		Message:   reason,
+	})
+
+	// ย้ายส่วนงานระหว่างผู้รับผิดชอบเดิม/ใหม่ใน user_task_stats
+	if err := applyTaskStatsDiff(ctx, s.taskRepo, taskOwnerShares(task), taskOwnerShares(updated)); err != nil {
+		log.Println("Error updating task stats after reassign:", err)
+	}
+
+	out := toTaskReassignmentDTO(entry)
+	return &out, nil
+}
+
+func (s *taskService) AddChecklistItem(ctx context.Context, taskID, stepID string, req dto.AddChecklistItemRequest, claims *dto.JWTClaims) (*dto.TaskChecklistItem, error) {
+	task, target, err := s.checklistStep(ctx, taskID, stepID, claims)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return nil, fmt.Errorf("text is required")
+	}
+	if len(target.Checklist) >= maxChecklistItems {
+		return nil, fmt.Errorf("a step can have at most %d checklist items", maxChecklistItems)
+	}
+	for _, it := range target.Checklist {
+		if strings.EqualFold(strings.TrimSpace(it.Text), text) {
+			return nil, fmt.Errorf("checklist item already exists: %s", text)
 		}
 	}
 
+	item := models.TaskChecklistItem{ItemID: uuid.NewString(), Text: text}
+	if err := s.taskRepo.AddStepChecklistItem(ctx, taskID, stepID, item, time.Now()); err != nil {
+		return nil, err
+	}
+	s.recordActivities(ctx, task, claims.UserID, models.Activity{
+		StepID:   stepID,
+		StepName: target.StepName,
+		Type:     "step_checklist",
+		ToValue:  "added",
+		Message:  text,
+	})
+
+	out := toChecklistDTOs([]models.TaskChecklistItem{item})[0]
+	return &out, nil
+}
+
+func (s *taskService) CheckChecklistItem(ctx context.Context, taskID, stepID, itemID string, req dto.CheckChecklistItemRequest, claims *dto.JWTClaims) error {
+	task, target, err := s.checklistStep(ctx, taskID, stepID, claims)
+	if err != nil {
+		return err
+	}
+	var item *models.TaskChecklistItem
+	for i := range target.Checklist {
+		if target.Checklist[i].ItemID == itemID {
+			item = &target.Checklist[i]
+			break
+		}
+	}
+	if item == nil {
+		return mongo.ErrNoDocuments
+	}
+	if item.Checked == req.Checked {
+		return nil
+	}
+
+	now := time.Now()
+	updated := *item
+	updated.Checked = req.Checked
+	updated.CheckedBy = ""
+	updated.CheckedAt = nil
+	if req.Checked {
+		updated.CheckedBy = claims.UserID
+		updated.CheckedAt = &now
+	}
+	if err := s.taskRepo.UpdateStepChecklistItem(ctx, taskID, stepID, updated, now); err != nil {
+		return err
+	}
+
+	from, to := "unchecked", "checked"
+	if !req.Checked {
+		from, to = to, from
+	}
+	s.recordActivities(ctx, task, claims.UserID, models.Activity{
+		StepID:    stepID,
+		StepName:  target.StepName,
+		Type:      "step_checklist",
+		FromValue: from,
+		ToValue:   to,
+		Message:   item.Text,
+	})
 	return nil
 }
 
+func (s *taskService) RemoveChecklistItem(ctx context.Context, taskID, stepID, itemID string, claims *dto.JWTClaims) error {
+	task, target, err := s.checklistStep(ctx, taskID, stepID, claims)
+	if err != nil {
+		return err
+	}
+	text := ""
+	for _, it := range target.Checklist {
+		if it.ItemID == itemID {
+			text = it.Text
+			break
+		}
+	}
+	if text == "" {
+		return mongo.ErrNoDocuments
+	}
+
+	if err := s.taskRepo.RemoveStepChecklistItem(ctx, taskID, stepID, itemID, time.Now()); err != nil {
+		return err
+	}
+	s.recordActivities(ctx, task, claims.UserID, models.Activity{
+		StepID:    stepID,
+		StepName:  target.StepName,
+		Type:      "step_checklist",
+		FromValue: "added",
+		ToValue:   "removed",
+		Message:   text,
+	})
+	return nil
+}
+
+// checklistStep โหลดงาน/step ที่จะแก้ checklist (ผู้รับผิดชอบหลัก เจ้าของ step หรือ admin; step ที่ done แล้วแก้ไม่ได้)
+func (s *taskService) checklistStep(ctx context.Context, taskID, stepID string, claims *dto.JWTClaims) (*models.Tasks, *models.TaskWorkflowStep, error) {
+	task, err := s.taskRepo.GetOneTasksByFilter(ctx, bson.M{"task_id": taskID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, nil, err
+	}
+	if task == nil {
+		return nil, nil, mongo.ErrNoDocuments
+	}
+	target := findTaskStep(task.AppliedWorkflow.Steps, stepID)
+	if target == nil {
+		return nil, nil, mongo.ErrNoDocuments
+	}
+	if claims.Role != "admin" && claims.UserID != task.Assignee && claims.UserID != stepOwner(task, *target) {
+		return nil, nil, ports.ErrStepForbidden
+	}
+	if task.Status == "cancelled" {
+		return nil, nil, fmt.Errorf("task has been cancelled")
+	}
+	if target.Status == "done" {
+		return nil, nil, fmt.Errorf("step is already done, reopen it before changing the checklist")
+	}
+	return task, target, nil
+}
+
 // func (s *taskService) ReplaceTask(ctx context.Context, taskID string, req dto.UpdateTaskPutRequest, updatedBy string) error {
 // 	now := time.Now()
 
@@ -1024,44 +1242,7 @@ func (s *taskService) CreateEvaluationIfNeeded(ctx context.Context, taskID strin
 		return mongo.ErrNoDocuments
 	}
 
-	// 2) กันซ้ำด้วย unique key (เช่น unique index ที่ collection: {task_id:1} หรือ {task_id:1,kpi_id:1})
-	exists, errOnGetOneKPIEvaluationByFilter := s.kpiEvaluationRepo.GetOneKPIEvaluationByFilter(ctx,
-		bson.M{"task_id": task.TaskID, "deleted_at": nil},
-		bson.M{"_id": 1},
-	)
-	if errOnGetOneKPIEvaluationByFilter != nil && errOnGetOneKPIEvaluationByFilter != mongo.ErrNoDocuments {
-		log.Println("Error loading KPI evaluation for CreateEvaluationIfNeeded:", errOnGetOneKPIEvaluationByFilter)
-		return errOnGetOneKPIEvaluationByFilter
-	}
-	if exists != nil {
-		// มีแล้ว -> ไม่ต้องสร้างซ้ำ
-		return nil
-	}
-
-	now := time.Now()
-
-	// 3) เตรียมเอกสารแบบประเมินเริ่มต้น (ปรับ fields ให้ตรง model/eval DTO ของคุณ)
-	doc := &models.KPIEvaluation{
-		EvaluationID: uuid.NewString(),
-		ProjectID:    task.ProjectID,
-		JobID:        task.JobID,
-		TaskID:       task.TaskID,
-		KPIID:        task.KPIID,
-		Version:      1, // default; update if KPI template provides version
-		EvaluatorID:  "",
-		EvaluateeID:  task.Assignee,
-		Department:   task.Department,
-		Scores:       []models.KPIScore{}, // will append after loading template
-		TotalScore:   0,
-		Feedback:     "",
-		IsEvaluated:  false,
-		CreatedAt:    now,
-		UpdatedAt:    now,
-		DeletedAt:    nil,
-	}
-
-	// 4) (ทางเลือก) Auto-generate รายการ KPI items จาก KPI Template
-
+	// 2) (ทางเลือก) Auto-generate รายการ KPI items จาก KPI Template
 	filter := bson.M{"kpi_id": task.KPIID, "deleted_at": nil}
 	projection := bson.M{}
 
@@ -1071,22 +1252,94 @@ func (s *taskService) CreateEvaluationIfNeeded(ctx context.Context, taskID strin
 		return errOnGetOneKPIByFilter
 	}
 
-	if tpl != nil {
-		for _, it := range tpl.Items {
-			doc.Scores = append(doc.Scores, models.KPIScore{
-				ItemID:   it.ItemID,
-				Name:     it.Name,     // snapshot ชื่อ item
-				Category: it.Category, // snapshot หมวดหมู่
-				Weight:   it.Weight,   // weight ปัจจุบัน (int)
-				MaxScore: it.MaxScore, // คะแนนเต็ม
-				Score:    0,           // ยังไม่ประเมิน (zero value)
-				Notes:    "",
-			})
+	policy := effectiveKPIEvaluatorPolicy(tpl)
+
+	// 3) แบบประเมินแยกตามเจ้าของส่วนงาน (ผู้รับผิดชอบหลัก + เจ้าของ step) เฉพาะคนที่ทำส่วนของตัวเองเสร็จแล้ว
+	shares := taskOwnerShares(task)
+	owners := make([]string, 0, len(shares))
+	for userID := range shares {
+		owners = append(owners, userID)
+	}
+	sort.Strings(owners)
+
+	for _, userID := range owners {
+		share := shares[userID]
+		if share.Status != "done" {
+			continue
+		}
+
+		// กันซ้ำด้วย unique key (task_id + evaluatee_id)
+		exists, errOnGetOneKPIEvaluationByFilter := s.kpiEvaluationRepo.GetOneKPIEvaluationByFilter(ctx,
+			bson.M{"task_id": task.TaskID, "evaluatee_id": userID, "deleted_at": nil},
+			bson.M{"_id": 1},
+		)
+		if errOnGetOneKPIEvaluationByFilter != nil && errOnGetOneKPIEvaluationByFilter != mongo.ErrNoDocuments {
+			log.Println("Error loading KPI evaluation for CreateEvaluationIfNeeded:", errOnGetOneKPIEvaluationByFilter)
+			return errOnGetOneKPIEvaluationByFilter
+		}
+		if exists != nil {
+			// มีแล้ว -> ไม่ต้องสร้างซ้ำ
+			continue
+		}
+
+		now := time.Now()
+
+		// ผู้ประเมินหลักตามกฎของ template (ว่าง = รอ admin/ผู้จัดการกำหนด หรือ cron มอบหมายให้ภายหลัง)
+		evaluatorID, evaluatorRule, errOnResolveEvaluator := resolveKPIEvaluator(ctx, s.departmentRepo, policy, share.Department, task.CreatedBy, userID)
+		if errOnResolveEvaluator != nil {
+			log.Println("Error resolving KPI evaluator for CreateEvaluationIfNeeded:", errOnResolveEvaluator)
+			return errOnResolveEvaluator
+		}
+		var assignedAt *time.Time
+		if evaluatorID != "" {
+			assignedAt = &now
+		}
+
+		// 4) เตรียมเอกสารแบบประเมินเริ่มต้น
+		doc := &models.KPIEvaluation{
+			EvaluationID: uuid.NewString(),
+			ProjectID:    task.ProjectID,
+			JobID:        task.JobID,
+			TaskID:       task.TaskID,
+			StepIDs:      share.StepIDs,
+			KPIID:        task.KPIID,
+			Version:      1, // default; update if KPI template provides version
+			EvaluatorID:  evaluatorID,
+			EvaluateeID:  userID,
+			Department:   share.Department,
+			Scores:       []models.KPIScore{}, // will append after loading template
+			TotalScore:   0,
+			Feedback:     "",
+			IsEvaluated:  false,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+			DeletedAt:    nil,
+
+			EvaluatorRule: evaluatorRule,
+			AssignedAt:    assignedAt,
+		}
+
+		if tpl != nil {
+			for _, it := range tpl.Items {
+				doc.Scores = append(doc.Scores, models.KPIScore{
+					ItemID:   it.ItemID,
+					Name:     it.Name,     // snapshot ชื่อ item
+					Category: it.Category, // snapshot หมวดหมู่
+					Weight:   it.Weight,   // weight ปัจจุบัน (int)
+					MaxScore: it.MaxScore, // คะแนนเต็ม
+					Score:    0,           // ยังไม่ประเมิน (zero value)
+					Notes:    "",
+				})
+			}
+		}
+
+		// 5) Insert
+		if err := s.kpiEvaluationRepo.CreateKPIEvaluations(ctx, *doc); err != nil {
+			return err
 		}
 	}
 
-	// 5) Insert
-	return s.kpiEvaluationRepo.CreateKPIEvaluations(ctx, *doc)
+	return nil
 }
 
 func (s *taskService) ReplaceTask(ctx context.Context, taskID string, req dto.UpdateTaskPutRequest, updatedBy string) error {
@@ -1104,9 +1357,10 @@ func (s *taskService) ReplaceTask(ctx context.Context, taskID string, req dto.Up
 	if updatedBy != existing.Assignee {
 		return fmt.Errorf("user is not the assignee of this task")
 	}
+	if existing.Status == "cancelled" {
+		return fmt.Errorf("task has been cancelled")
+	}
 
-	oldAssignee := existing.Assignee
-	oldStatus := existing.Status
 	oldCreatedAt := existing.CreatedAt
 	oldCreatedBy := existing.CreatedBy
 	oldTaskID := existing.TaskID
@@ -1156,6 +1410,7 @@ func (s *taskService) ReplaceTask(ctx context.Context, taskID string, req dto.Up
 	}
 
 	steps := make([]models.TaskWorkflowStep, 0, len(req.AppliedWorkflow.Steps))
+	inheritedDeps := make(map[string]bool)
 
 	for i, st := range req.AppliedWorkflow.Steps {
 		ns, err := normalizeStatus(st.Status)
@@ -1229,21 +1484,112 @@ func (s *taskService) ReplaceTask(ctx context.Context, taskID string, req dto.Up
 			createdAt = prev.CreatedAt
 		}
 
+		// depends_on: ถ้า FE ไม่ส่งมาให้คงของเดิม (ตัด step ที่ถูกลบออกทีหลัง)
+		dependsOn := st.DependsOn
+		inherited := false
+		if dependsOn == nil && prev != nil {
+			dependsOn = prev.DependsOn
+			inherited = true
+		}
+		if inherited {
+			inheritedDeps[stepID] = true
+		}
+
+		// แผนก/ผู้รับผิดชอบ step: ว่าง = คงของเดิม
+		stepDepartment := strings.TrimSpace(st.Department)
+		stepAssignee := strings.TrimSpace(st.Assignee)
+		stepAssigneeName := ""
+		if prev != nil {
+			if stepDepartment == "" {
+				stepDepartment = prev.Department
+			}
+			if stepAssignee == "" || stepAssignee == prev.Assignee {
+				stepAssignee = prev.Assignee
+				stepAssigneeName = prev.AssigneeName
+			}
+		}
+		// SLA ของ step: คงของเดิมถ้ายังเป็นรอบเริ่มเดิม (งาน cron จะคำนวณใหม่รอบถัดไป)
+		var slaDueAt *time.Time
+		slaStatus := ""
+		templateStepID := ""
+		var checklist []models.TaskChecklistItem
+		if prev != nil {
+			templateStepID = prev.TemplateStepID
+			checklist = prev.Checklist
+		}
+		if ns == "done" && (prev == nil || prev.Status != "done") {
+			if pending := uncheckedItems(checklist); len(pending) > 0 {
+				return fmt.Errorf("steps[%d]: %w: %s", i, ports.ErrStepChecklistIncomplete, strings.Join(pending, ", "))
+			}
+		}
+		if prev != nil && started != nil && prev.StartedAt != nil && started.Equal(*prev.StartedAt) {
+			slaDueAt = prev.SLADueAt
+			slaStatus = prev.SLAStatus
+		}
+
+		if stepAssignee != "" && stepAssigneeName == "" {
+			user, err := s.userRepo.GetByID(ctx, stepAssignee)
+			if err != nil && err != mongo.ErrNoDocuments {
+				return err
+			}
+			if user == nil {
+				return fmt.Errorf("steps[%d]: assignee not found", i)
+			}
+			stepAssigneeName = fmt.Sprintf("%s %s %s", user.TitleTH, user.FirstNameTH, user.LastNameTH)
+		}
+
 		steps = append(steps, models.TaskWorkflowStep{
-			StepID:      stepID,
-			StepName:    strings.TrimSpace(st.StepName),
-			Description: desc,
-			Hours:       hours,
-			Order:       st.Order, // จะ reindex อีกที
-			Status:      ns,
-			StartedAt:   started,
-			CompletedAt: completed,
-			Notes:       notes,
-			CreatedAt:   createdAt,
-			UpdatedAt:   now,
+			StepID:       stepID,
+			StepName:     strings.TrimSpace(st.StepName),
+			Description:  desc,
+			Hours:        hours,
+			Order:        st.Order, // จะ reindex อีกที
+			Status:       ns,
+			StartedAt:    started,
+			CompletedAt:  completed,
+			Notes:        notes,
+			DependsOn:    dependsOn,
+			Department:   stepDepartment,
+			Assignee:     stepAssignee,
+			AssigneeName: stepAssigneeName,
+			SLADueAt:     slaDueAt,
+			SLAStatus:    slaStatus,
+			CreatedAt:    createdAt,
+			UpdatedAt:    now,
+
+			TemplateStepID: templateStepID,
+			Checklist:      checklist,
 		})
 	}
 
+	// ตรวจกราฟ prerequisite ของชุด steps ใหม่
+	stepIDs := make(map[string]bool, len(steps))
+	for _, s2 := range steps {
+		if stepIDs[s2.StepID] {
+			return fmt.Errorf("duplicate step_id: %s", s2.StepID)
+		}
+		stepIDs[s2.StepID] = true
+	}
+	deps := make(map[string][]string, len(steps))
+	for i := range steps {
+		if inheritedDeps[steps[i].StepID] {
+			kept := make([]string, 0, len(steps[i].DependsOn))
+			for _, d := range steps[i].DependsOn {
+				if stepIDs[d] {
+					kept = append(kept, d)
+				}
+			}
+			steps[i].DependsOn = kept
+		}
+		if len(steps[i].DependsOn) == 0 {
+			steps[i].DependsOn = nil
+		}
+		deps[steps[i].StepID] = steps[i].DependsOn
+	}
+	if err := helpers.ValidateStepGraph(deps); err != nil {
+		return err
+	}
+
 	// sort + reindex
 	sort.SliceStable(steps, func(i, j int) bool { return steps[i].Order < steps[j].Order })
 	for i := range steps {
@@ -1257,23 +1603,33 @@ func (s *taskService) ReplaceTask(ctx context.Context, taskID string, req dto.Up
 	}
 
 	// 4) คำนวณ step_name และ task.status จาก steps
-	curStepName := ""
-	for _, s2 := range steps {
-		if s2.Status == "in_progress" {
-			curStepName = s2.StepName
-			break
-		}
-	}
-	if curStepName == "" {
-		for _, s2 := range steps {
-			if s2.Status == "todo" {
-				curStepName = s2.StepName
-				break
+	curStepName := helpers.CurrentStepName(steps)
+
+	// ผู้รับผิดชอบหลัก: เปลี่ยนคนต้องดึงชื่อใหม่และบันทึกประวัติ (ไม่มีเหตุผล ควรใช้ ReassignTask)
+	newAssignee := strings.TrimSpace(req.Assignee)
+	assigneeName, assigneeNickName := existing.AssigneeName, existing.AssigneeNickName
+	history := existing.ReassignHistory
+	if newAssignee != existing.Assignee {
+		assigneeName, assigneeNickName = "", ""
+		if newAssignee != "" {
+			user, err := s.userRepo.GetByID(ctx, newAssignee)
+			if err != nil && err != mongo.ErrNoDocuments {
+				return err
 			}
+			if user == nil {
+				return fmt.Errorf("assignee not found")
+			}
+			assigneeName = fmt.Sprintf("%s %s %s", user.TitleTH, user.FirstNameTH, user.LastNameTH)
+			assigneeNickName = user.NickName
 		}
-	}
-	if curStepName == "" && len(steps) > 0 {
-		curStepName = steps[len(steps)-1].StepName
+		history = append(history, models.TaskReassignment{
+			ReassignedAt: now,
+			FromAssignee: existing.Assignee,
+			FromName:     existing.AssigneeName,
+			ToAssignee:   newAssignee,
+			ToName:       assigneeName,
+			ReassignedBy: updatedBy,
+		})
 	}
 
 	derived := helpers.DeriveTaskStatusFromSteps(steps)
@@ -1287,9 +1643,11 @@ func (s *taskService) ReplaceTask(ctx context.Context, taskID string, req dto.Up
 		JobName:     strings.TrimSpace(req.JobName),
 		Description: strings.TrimSpace(req.Description),
 
-		Department: strings.TrimSpace(req.Department),
-		Assignee:   strings.TrimSpace(req.Assignee),
-		Importance: imp,
+		Department:       strings.TrimSpace(req.Department),
+		Assignee:         newAssignee,
+		AssigneeName:     assigneeName,
+		AssigneeNickName: assigneeNickName,
+		Importance:       imp,
 
 		StartDate: start,
 		EndDate:   end,
@@ -1305,14 +1663,22 @@ func (s *taskService) ReplaceTask(ctx context.Context, taskID string, req dto.Up
 			TotalHours:   total,
 			Steps:        steps,
 			Version:      existing.AppliedWorkflow.Version + 1, // bump version
+
+			TemplateVersion: existing.AppliedWorkflow.TemplateVersion,
 		},
 
-		Status:    derived,     // ทับ req.Status
-		StepName:  curStepName, // จากขั้นตอน
-		CreatedBy: oldCreatedBy,
-		CreatedAt: oldCreatedAt,
-		UpdatedAt: now,
-		DeletedAt: nil,
+		Status:     derived,     // ทับ req.Status
+		StepName:   curStepName, // จากขั้นตอน
+		ReadySteps: helpers.ReadyStepNames(steps),
+		SLAStatus:  existing.SLAStatus,
+		CreatedBy:  oldCreatedBy,
+		CreatedAt:  oldCreatedAt,
+		UpdatedAt:  now,
+		DeletedAt:  nil,
+
+		AutoGenerated:   existing.AutoGenerated,
+		RecurringID:     existing.RecurringID,
+		ReassignHistory: history,
 	}
 
 	// 6) Replace ใน DB
@@ -1324,106 +1690,559 @@ func (s *taskService) ReplaceTask(ctx context.Context, taskID string, req dto.Up
 		return mongo.ErrNoDocuments
 	}
 
-	// (ออปชัน) ถ้าเพิ่งเปลี่ยนเป็น done ให้สร้างแบบประเมิน
-	if oldStatus != "done" && newDoc.Status == "done" {
+	// บันทึก activity ของการแก้ไขทั้งก้อน (สถานะ step ที่เปลี่ยน, step ที่เพิ่ม/ลบ, สถานะงาน)
+	s.recordActivities(ctx, &newDoc, updatedBy, replaceActivities(existing, &newDoc)...)
+
+	// 7) อัปเดตสถิติ — diff ส่วนงานของผู้รับผิดชอบหลัก/เจ้าของ step
+	before := taskOwnerShares(existing)
+	after := taskOwnerShares(&newDoc)
+	_ = applyTaskStatsDiff(ctx, s.taskRepo, before, after)
+
+	// (ออปชัน) ถ้ามีคนปิดส่วนงานของตัวเองครบ ให้สร้างแบบประเมิน
+	if anyShareCompleted(before, after) {
 		_ = s.CreateEvaluationIfNeeded(ctx, taskID)
 	}
 
-	// 7) อัปเดตสถิติ — diff ผู้รับผิดชอบ/สถานะ
-	newAssignee := newDoc.Assignee
-	newStatus := newDoc.Status
+	return nil
+}
 
-	updateStats := func(userID string, assignedDelta, openDelta, inProgDelta, completedDelta int) error {
-		if strings.TrimSpace(userID) == "" {
-			return nil
+// snapshotWorkflowSteps คัดลอก steps จาก template ลงงาน (สร้าง step_id ใหม่และแมป depends_on ตาม)
+func snapshotWorkflowSteps(wf *models.WorkFlowTemplate, now time.Time) ([]models.TaskWorkflowStep, float64) {
+	newIDs := make(map[string]string, len(wf.Steps))
+	for _, st := range wf.Steps {
+		if st.DeletedAt != nil {
+			continue
 		}
-		existingStats, err := s.taskRepo.GetOneUserTaskStatsByFilter(ctx,
-			bson.M{"user_id": userID},
-			bson.M{},
-		)
-		if err != nil && err != mongo.ErrNoDocuments {
-			return err
+		newIDs[st.StepID] = uuid.NewString()
+	}
+
+	steps := make([]models.TaskWorkflowStep, 0, len(wf.Steps))
+	var total float64
+	for _, st := range wf.Steps {
+		if st.DeletedAt != nil {
+			continue
+		}
+		department := st.Department
+		if department == "" {
+			department = wf.Department
+		}
+		var dependsOn []string
+		for _, d := range st.DependsOn {
+			if id, ok := newIDs[d]; ok {
+				dependsOn = append(dependsOn, id)
+			}
+		}
+		steps = append(steps, models.TaskWorkflowStep{
+			StepID:         newIDs[st.StepID],
+			TemplateStepID: st.StepID,
+			StepName:       st.StepName,
+			Description:    st.Description,
+			Hours:          st.Hours,
+			Order:          st.Order,
+			Status:         "todo",
+			Notes:          "",
+			DependsOn:      dependsOn,
+			Department:     department,
+			Checklist:      newChecklist(st.Checklist),
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		})
+		total += st.Hours
+	}
+	return steps, total
+}
+
+const maxChecklistItems = 50
+
+// normalizeChecklist ตัดช่องว่าง ตัดรายการว่างและรายการซ้ำ (ไม่สนตัวพิมพ์)
+func normalizeChecklist(items []string) ([]string, error) {
+	out := make([]string, 0, len(items))
+	seen := make(map[string]bool, len(items))
+	for _, it := range items {
+		text := strings.TrimSpace(it)
+		key := strings.ToLower(text)
+		if text == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, text)
+	}
+	if len(out) > maxChecklistItems {
+		return nil, fmt.Errorf("a step can have at most %d checklist items", maxChecklistItems)
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// newChecklist สร้าง checklist ของ step ในงานจากข้อความใน template (ยังไม่ติ๊ก)
+func newChecklist(items []string) []models.TaskChecklistItem {
+	if len(items) == 0 {
+		return nil
+	}
+	out := make([]models.TaskChecklistItem, 0, len(items))
+	for _, text := range items {
+		out = append(out, models.TaskChecklistItem{ItemID: uuid.NewString(), Text: text})
+	}
+	return out
+}
+
+// uncheckedItems ข้อความของรายการที่ยังไม่ติ๊ก
+func uncheckedItems(items []models.TaskChecklistItem) []string {
+	out := make([]string, 0)
+	for _, it := range items {
+		if !it.Checked {
+			out = append(out, it.Text)
+		}
+	}
+	return out
+}
+
+func toChecklistDTOs(items []models.TaskChecklistItem) []dto.TaskChecklistItem {
+	if len(items) == 0 {
+		return nil
+	}
+	out := make([]dto.TaskChecklistItem, 0, len(items))
+	for _, it := range items {
+		out = append(out, dto.TaskChecklistItem{
+			ItemID:    it.ItemID,
+			Text:      it.Text,
+			Checked:   it.Checked,
+			CheckedBy: it.CheckedBy,
+			CheckedAt: it.CheckedAt,
+		})
+	}
+	return out
+}
+
+// departmentManagers คืน map แผนก -> ผู้จัดการแผนก (ใช้เป็นผู้รับผิดชอบ step เริ่มต้นของแผนกอื่น)
+func (s *taskService) departmentManagers(ctx context.Context) (map[string]string, error) {
+	departments, err := s.departmentRepo.GetAllDepartmentByFilter(ctx, bson.M{"deleted_at": nil}, bson.M{"department_id": 1, "manager_id": 1})
+	if err != nil {
+		return nil, err
+	}
+	managers := make(map[string]string, len(departments))
+	for _, d := range departments {
+		managers[d.DepartmentID] = d.ManagerID
+	}
+	return managers, nil
+}
+
+// defaultStepAssignees กำหนดผู้รับผิดชอบ step เริ่มต้นตามแผนกของ step
+// แผนกเดียวกับงาน = ผู้รับผิดชอบหลัก (ไม่ต้องระบุ), แผนกอื่น = ผู้จัดการแผนกนั้น
+func defaultStepAssignees(ctx context.Context, userRepo ports.UserRepository, steps []models.TaskWorkflowStep, taskAssignee, taskDepartment string, managers map[string]string) {
+	names := make(map[string]string)
+	for i := range steps {
+		st := &steps[i]
+		if st.Assignee != "" || st.Department == "" || st.Department == taskDepartment {
+			continue
+		}
+		manager := managers[st.Department]
+		if manager == "" || manager == taskAssignee {
+			continue
 		}
-		nowUTC := time.Now().UTC()
-		totals := models.UserTaskTotals{}
-		createdAt := nowUTC
-		if existingStats != nil {
-			totals = existingStats.Totals
-			createdAt = existingStats.CreatedAt
+		if _, ok := names[manager]; !ok {
+			names[manager] = ""
+			if user, _ := userRepo.GetByID(ctx, manager); user != nil {
+				names[manager] = fmt.Sprintf("%s %s %s", user.TitleTH, user.FirstNameTH, user.LastNameTH)
+			}
+		}
+		st.Assignee = manager
+		st.AssigneeName = names[manager]
+	}
+}
+
+// stepOwner ผู้รับผิดชอบ step (ไม่ได้ระบุ = ผู้รับผิดชอบหลักของงาน)
+func stepOwner(t *models.Tasks, st models.TaskWorkflowStep) string {
+	if st.Assignee != "" {
+		return st.Assignee
+	}
+	return t.Assignee
+}
+
+// stepAssigneeName ชื่อผู้รับผิดชอบ step สำหรับแสดงผล
+func stepAssigneeName(t *models.Tasks, st models.TaskWorkflowStep) string {
+	if st.Assignee != "" {
+		return st.AssigneeName
+	}
+	return t.AssigneeName
+}
+
+// taskOwnerShare ส่วนงานของผู้ใช้หนึ่งคนในงาน (ใช้นับ user_task_stats และสร้างแบบประเมิน KPI)
+type taskOwnerShare struct {
+	Status     string
+	Department string
+	StepIDs    []string
+}
+
+// taskOwnerShares แยกงานตามผู้รับผิดชอบ: ผู้รับผิดชอบหลักนับตามสถานะงาน
+// ส่วนเจ้าของ step คนอื่นนับตามสถานะ step ของตัวเอง
+func taskOwnerShares(t *models.Tasks) map[string]taskOwnerShare {
+	shares := make(map[string]taskOwnerShare)
+	if t == nil {
+		return shares
+	}
+
+	owned := make(map[string][]models.TaskWorkflowStep)
+	for _, st := range t.AppliedWorkflow.Steps {
+		owner := stepOwner(t, st)
+		if owner == "" {
+			continue
 		}
-		totals.Assigned += assignedDelta
-		totals.Open += openDelta
-		totals.InProgress += inProgDelta
-		totals.Completed += completedDelta
-		if totals.Assigned < 0 {
-			totals.Assigned = 0
+		owned[owner] = append(owned[owner], st)
+	}
+
+	if t.Assignee != "" {
+		share := taskOwnerShare{Status: t.Status, Department: t.Department}
+		for _, st := range owned[t.Assignee] {
+			share.StepIDs = append(share.StepIDs, st.StepID)
 		}
-		if totals.Open < 0 {
-			totals.Open = 0
+		shares[t.Assignee] = share
+	}
+
+	for owner, steps := range owned {
+		if owner == t.Assignee {
+			continue
 		}
-		if totals.InProgress < 0 {
-			totals.InProgress = 0
+		share := taskOwnerShare{Status: helpers.DeriveTaskStatusFromSteps(steps), Department: t.Department}
+		if t.Status == "cancelled" && share.Status != "done" {
+			share.Status = "cancelled"
 		}
-		if totals.Completed < 0 {
-			totals.Completed = 0
+		for _, st := range steps {
+			share.StepIDs = append(share.StepIDs, st.StepID)
+			if st.Department != "" {
+				share.Department = st.Department
+			}
 		}
+		shares[owner] = share
+	}
+	return shares
+}
 
-		statsDoc := &models.UserTaskStats{
-			UserID:       userID,
-			DepartmentID: newDoc.Department,
-			Totals:       totals,
-			KPI:          models.UserTaskKPI{Score: nil, LastCalculatedAt: nil},
-			CreatedAt:    createdAt,
-			UpdatedAt:    nowUTC,
+// anyShareCompleted คืน true ถ้ามีผู้รับผิดชอบคนใดเพิ่งทำส่วนงานของตัวเองเสร็จ
+func anyShareCompleted(before, after map[string]taskOwnerShare) bool {
+	for userID, share := range after {
+		if share.Status == "done" && before[userID].Status != "done" {
+			return true
 		}
-		return s.taskRepo.UpsertUserTaskStats(ctx, statsDoc)
 	}
+	return false
+}
 
-	if oldAssignee != newAssignee {
-		switch oldStatus {
-		case "done":
-			_ = updateStats(oldAssignee, -1, 0, 0, -1)
-		case "in_progress":
-			_ = updateStats(oldAssignee, -1, -1, -1, 0)
-		default:
-			_ = updateStats(oldAssignee, -1, -1, 0, 0)
+// taskStatsContribution ค่าที่งานหนึ่งชิ้นนับเข้า user_task_stats ตามสถานะ (งานที่ถูกยกเลิกยังนับใน assigned)
+func taskStatsContribution(status string) models.UserTaskTotals {
+	switch status {
+	case "todo":
+		return models.UserTaskTotals{Assigned: 1, Open: 1}
+	case "in_progress":
+		return models.UserTaskTotals{Assigned: 1, Open: 1, InProgress: 1}
+	case "done":
+		return models.UserTaskTotals{Assigned: 1, Completed: 1}
+	case "cancelled":
+		return models.UserTaskTotals{Assigned: 1}
+	}
+	return models.UserTaskTotals{}
+}
+
+// applyTaskStatsDiff ปรับ user_task_stats ตามส่วนต่างของส่วนงานก่อน/หลังเปลี่ยนแปลง
+// ใช้ $inc ไม่อ่านค่าเดิมมาคำนวณ ค่าที่คลาดจากข้อมูลเก่าตรวจ/แก้ได้ด้วย CheckTaskStats/RebuildTaskStats
+func applyTaskStatsDiff(ctx context.Context, taskRepo ports.TaskRepository, before, after map[string]taskOwnerShare) error {
+	users := make([]string, 0, len(before)+len(after))
+	for userID := range before {
+		users = append(users, userID)
+	}
+	for userID := range after {
+		if _, ok := before[userID]; !ok {
+			users = append(users, userID)
 		}
-		switch newStatus {
-		case "done":
-			_ = updateStats(newAssignee, +1, 0, 0, +1)
-		case "in_progress":
-			_ = updateStats(newAssignee, +1, +1, +1, 0)
-		default:
-			_ = updateStats(newAssignee, +1, +1, 0, 0)
+	}
+	sort.Strings(users)
+
+	nowUTC := time.Now().UTC()
+	for _, userID := range users {
+		if strings.TrimSpace(userID) == "" {
+			continue
 		}
-		return nil
+		prev, next := before[userID], after[userID]
+		from := taskStatsContribution(prev.Status)
+		to := taskStatsContribution(next.Status)
+		if from == to {
+			continue
+		}
+
+		department := next.Department
+		if department == "" {
+			department = prev.Department
+		}
+		delta := models.UserTaskTotals{
+			Assigned:   to.Assigned - from.Assigned,
+			Open:       to.Open - from.Open,
+			InProgress: to.InProgress - from.InProgress,
+			Completed:  to.Completed - from.Completed,
+		}
+		if err := taskRepo.IncUserTaskStats(ctx, userID, department, delta, nowUTC); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *taskService) CheckTaskStats(ctx context.Context, userID string) (*dto.TaskStatsCheckResult, error) {
+	return s.runTaskStatsCheck(ctx, strings.TrimSpace(userID), false)
+}
+
+func (s *taskService) RebuildTaskStats(ctx context.Context, userID string) (*dto.TaskStatsCheckResult, error) {
+	return s.runTaskStatsCheck(ctx, strings.TrimSpace(userID), true)
+}
+
+// runTaskStatsCheck นับ totals จาก tasks ด้วยกติกาเดียวกับ applyTaskStatsDiff แล้วเทียบกับ user_task_stats
+// repair = true จะเขียนค่าที่นับได้ทับเฉพาะผู้ใช้ที่ไม่ตรง
+func (s *taskService) runTaskStatsCheck(ctx context.Context, userID string, repair bool) (*dto.TaskStatsCheckResult, error) {
+	start := time.Now()
+
+	taskFilter := bson.M{"deleted_at": nil}
+	statsFilter := bson.M{}
+	if userID != "" {
+		taskFilter["$or"] = bson.A{
+			bson.M{"assignee": userID},
+			bson.M{"applied_workflow.steps.assignee": userID},
+		}
+		statsFilter["user_id"] = userID
+	}
+	tasks, err := s.taskRepo.GetAllTaskByFilter(ctx, taskFilter, bson.M{
+		"task_id": 1, "assignee": 1, "status": 1, "department_id": 1,
+		"applied_workflow.steps.step_id":       1,
+		"applied_workflow.steps.status":        1,
+		"applied_workflow.steps.assignee":      1,
+		"applied_workflow.steps.department_id": 1,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if oldStatus != newStatus {
-		switch oldStatus {
-		case "todo":
-			switch newStatus {
-			case "in_progress":
-				_ = updateStats(newAssignee, 0, 0, +1, 0)
-			case "done":
-				_ = updateStats(newAssignee, 0, -1, 0, +1)
+	actual := make(map[string]models.UserTaskTotals)
+	departments := make(map[string]string)
+	for _, t := range tasks {
+		for owner, share := range taskOwnerShares(t) {
+			if strings.TrimSpace(owner) == "" || (userID != "" && owner != userID) {
+				continue
 			}
-		case "in_progress":
-			switch newStatus {
-			case "todo":
-				_ = updateStats(newAssignee, 0, 0, -1, 0)
-			case "done":
-				_ = updateStats(newAssignee, 0, -1, -1, +1)
+			c := taskStatsContribution(share.Status)
+			sum := actual[owner]
+			sum.Assigned += c.Assigned
+			sum.Open += c.Open
+			sum.InProgress += c.InProgress
+			sum.Completed += c.Completed
+			actual[owner] = sum
+			if share.Department != "" {
+				departments[owner] = share.Department
 			}
-		case "done":
-			switch newStatus {
-			case "in_progress":
-				_ = updateStats(newAssignee, 0, +1, +1, -1)
-			case "todo":
-				_ = updateStats(newAssignee, 0, +1, 0, -1)
+		}
+	}
+
+	stored, err := s.taskRepo.GetAllUserTaskStatsByFilter(ctx, statsFilter, bson.M{"user_id": 1, "department_id": 1, "totals": 1})
+	if err != nil {
+		return nil, err
+	}
+	storedBy := make(map[string]*models.UserTaskStats, len(stored))
+	for _, st := range stored {
+		storedBy[st.UserID] = st
+	}
+
+	users := make([]string, 0, len(actual)+len(storedBy))
+	for id := range actual {
+		users = append(users, id)
+	}
+	for id := range storedBy {
+		if _, ok := actual[id]; !ok {
+			users = append(users, id)
+		}
+	}
+	sort.Strings(users)
+
+	result := &dto.TaskStatsCheckResult{
+		RunAt: start,
+		Tasks: len(tasks),
+		Users: len(users),
+		Drift: []dto.TaskStatsDriftDTO{},
+	}
+	nowUTC := start.UTC()
+	for _, id := range users {
+		want := actual[id]
+		var have models.UserTaskTotals
+		department := departments[id]
+		doc, exists := storedBy[id]
+		if exists {
+			have = doc.Totals
+			if department == "" {
+				department = doc.DepartmentID
 			}
 		}
+		if sameTaskTotals(have, want) && (exists || want == (models.UserTaskTotals{})) {
+			continue
+		}
+
+		result.Drifted++
+		result.Drift = append(result.Drift, dto.TaskStatsDriftDTO{
+			UserID:       id,
+			DepartmentID: department,
+			Stored:       toTaskStatsTotalsDTO(have),
+			Actual:       toTaskStatsTotalsDTO(want),
+			Missing:      !exists,
+		})
+		if !repair {
+			continue
+		}
+		if err := s.taskRepo.SetUserTaskTotals(ctx, id, department, want, nowUTC); err != nil {
+			return nil, fmt.Errorf("rebuild stats of %s: %w", id, err)
+		}
+		result.Repaired++
 	}
 
-	return nil
+	result.DurationMS = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+// sameTaskTotals เทียบเฉพาะตัวเลขที่ระบบนับ (skipped ไม่ได้ใช้)
+func sameTaskTotals(a, b models.UserTaskTotals) bool {
+	return a.Assigned == b.Assigned && a.Open == b.Open && a.InProgress == b.InProgress && a.Completed == b.Completed
+}
+
+func toTaskStatsTotalsDTO(t models.UserTaskTotals) dto.TaskStatsTotalsDTO {
+	return dto.TaskStatsTotalsDTO{Assigned: t.Assigned, Open: t.Open, InProgress: t.InProgress, Completed: t.Completed}
+}
+
+func toTaskReassignmentDTO(r models.TaskReassignment) dto.TaskReassignmentDTO {
+	return dto.TaskReassignmentDTO{
+		ReassignedAt: r.ReassignedAt,
+		FromAssignee: r.FromAssignee,
+		FromName:     r.FromName,
+		ToAssignee:   r.ToAssignee,
+		ToName:       r.ToName,
+		Reason:       r.Reason,
+		StepIDs:      r.StepIDs,
+		ReassignedBy: r.ReassignedBy,
+	}
+}
+
+// notifyReadySteps แจ้งอีเมลผู้รับผิดชอบ step ที่เพิ่งเริ่มได้หลัง step ก่อนหน้าเสร็จ (ส่งต่องาน)
+func (s *taskService) notifyReadySteps(task *models.Tasks, prevSteps []models.TaskWorkflowStep, actorID string) {
+	wasReady := make(map[string]bool)
+	for _, st := range helpers.ReadySteps(prevSteps) {
+		wasReady[st.StepID] = true
+	}
+
+	type handoff struct {
+		userID   string
+		stepName string
+	}
+	handoffs := make([]handoff, 0)
+	for _, st := range helpers.ReadySteps(task.AppliedWorkflow.Steps) {
+		owner := stepOwner(task, st)
+		if wasReady[st.StepID] || owner == "" || owner == actorID {
+			continue
+		}
+		handoffs = append(handoffs, handoff{userID: owner, stepName: st.StepName})
+	}
+	if len(handoffs) == 0 || strings.TrimSpace(s.config.Email.Host) == "" {
+		return
+	}
+
+	emailCfg := util.EmailConfig{
+		Host:     s.config.Email.Host,
+		Port:     s.config.Email.Port,
+		Username: s.config.Email.Username,
+		Password: s.config.Email.Password,
+		From:     s.config.Email.From,
+	}
+	jobName := task.JobName
+	if jobName == "" {
+		jobName = task.ProjectName
+	}
+
+	// ส่งแบบ async ไม่ให้การอัปเดต step ต้องรอ SMTP
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		for _, h := range handoffs {
+			user, err := s.userRepo.GetByID(ctx, h.userID)
+			if err != nil || user == nil || strings.TrimSpace(user.Email) == "" {
+				continue
+			}
+			subject := fmt.Sprintf("Step ready: %s", h.stepName)
+			body := fmt.Sprintf("เรียนคุณ %s\n\nขั้นตอน \"%s\" ของงาน \"%s\" พร้อมให้เริ่มดำเนินการแล้ว เนื่องจากขั้นตอนก่อนหน้าเสร็จเรียบร้อย\n\nTask ID: %s\n",
+				user.FirstNameTH, h.stepName, jobName, task.TaskID)
+			if err := util.SendMail(emailCfg, user.Email, subject, body); err != nil {
+				log.Println("Error sending step hand-off email:", err)
+			}
+		}
+	}()
+}
+
+// recordActivities บันทึกเหตุการณ์ของงานลง feed (ไม่ให้ความล้มเหลวกระทบการอัปเดตงาน)
+func (s *taskService) recordActivities(ctx context.Context, task *models.Tasks, actorID string, activities ...models.Activity) {
+	if len(activities) == 0 || s.activityRepo == nil {
+		return
+	}
+	now := time.Now()
+	for i := range activities {
+		activities[i].ActivityID = uuid.NewString()
+		activities[i].EntityType = "task"
+		activities[i].EntityID = task.TaskID
+		activities[i].JobID = task.JobID
+		activities[i].ActorID = actorID
+		activities[i].CreatedAt = now
+	}
+	if err := s.activityRepo.CreateActivities(ctx, activities); err != nil {
+		log.Println("Error recording task activities:", err)
+	}
+}
+
+// replaceActivities เทียบงานก่อน/หลัง ReplaceTask แล้วคืนรายการเหตุการณ์ที่เปลี่ยน
+func replaceActivities(before, after *models.Tasks) []models.Activity {
+	activities := make([]models.Activity, 0)
+
+	prev := make(map[string]models.TaskWorkflowStep, len(before.AppliedWorkflow.Steps))
+	for _, st := range before.AppliedWorkflow.Steps {
+		prev[st.StepID] = st
+	}
+	kept := make(map[string]bool, len(after.AppliedWorkflow.Steps))
+	for _, st := range after.AppliedWorkflow.Steps {
+		old, ok := prev[st.StepID]
+		kept[st.StepID] = true
+		if !ok {
+			activities = append(activities, models.Activity{StepID: st.StepID, StepName: st.StepName, Type: "task_updated", Message: "เพิ่มขั้นตอน " + st.StepName})
+			continue
+		}
+		if old.Status != st.Status {
+			activities = append(activities, models.Activity{StepID: st.StepID, StepName: st.StepName, Type: "step_status", FromValue: old.Status, ToValue: st.Status})
+		}
+		if strings.TrimSpace(old.Notes) != strings.TrimSpace(st.Notes) {
+			activities = append(activities, models.Activity{StepID: st.StepID, StepName: st.StepName, Type: "step_note", FromValue: old.Notes, ToValue: st.Notes})
+		}
+	}
+	for _, st := range before.AppliedWorkflow.Steps {
+		if !kept[st.StepID] {
+			activities = append(activities, models.Activity{StepID: st.StepID, StepName: st.StepName, Type: "task_updated", Message: "ลบขั้นตอน " + st.StepName})
+		}
+	}
+
+	if before.Status != after.Status {
+		activities = append(activities, models.Activity{Type: "task_status", FromValue: before.Status, ToValue: after.Status})
+	}
+	if before.Assignee != after.Assignee {
+		from, to := before.AssigneeName, after.AssigneeName
+		if from == "" {
+			from = before.Assignee
+		}
+		if to == "" {
+			to = after.Assignee
+		}
+		activities = append(activities, models.Activity{Type: "task_updated", Message: "เปลี่ยนผู้รับผิดชอบหลัก", FromValue: from, ToValue: to})
+	}
+	if !before.EndDate.Equal(after.EndDate) {
+		activities = append(activities, models.Activity{Type: "task_updated", Message: "เปลี่ยนวันสิ้นสุดงาน", FromValue: before.EndDate.Format("2006-01-02"), ToValue: after.EndDate.Format("2006-01-02")})
+	}
+	return activities
 }
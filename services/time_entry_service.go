@@ -0,0 +1,1083 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Be2Bag/erp-demo/config"
+	"github.com/Be2Bag/erp-demo/dto"
+	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/pkg/util"
+	"github.com/Be2Bag/erp-demo/ports"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	maxTimeEntryHours   = 24 // ชั่วโมงสูงสุดต่อหนึ่งรายการที่บันทึกเอง
+	maxTimerHours       = 12 // จับเวลานานกว่านี้ต้องยืนยันเวลาหยุดจริง (ลืมกดหยุด)
+	defaultReportPeriod = 30 // ช่วงรายงานเริ่มต้น (วัน)
+)
+
+type timeEntryService struct {
+	config         config.Config
+	timeEntryRepo  ports.TimeEntryRepository
+	taskRepo       ports.TaskRepository
+	userRepo       ports.UserRepository
+	departmentRepo ports.DepartmentRepository
+}
+
+func NewTimeEntryService(cfg config.Config, timeEntryRepo ports.TimeEntryRepository, taskRepo ports.TaskRepository, userRepo ports.UserRepository, departmentRepo ports.DepartmentRepository) ports.TimeEntryService {
+	return &timeEntryService{config: cfg, timeEntryRepo: timeEntryRepo, taskRepo: taskRepo, userRepo: userRepo, departmentRepo: departmentRepo}
+}
+
+func (s *timeEntryService) StartTimer(ctx context.Context, req dto.StartTimerDTO, claims *dto.JWTClaims) (*dto.TimeEntryDTO, error) {
+	task, step, err := s.loadStep(ctx, req.TaskID, req.StepID)
+	if err != nil {
+		return nil, err
+	}
+	if step.Status == "done" || step.Status == "skip" {
+		return nil, errors.New("step is already closed")
+	}
+	if !canLogTime(task, step, claims) {
+		return nil, ports.ErrTimeEntryForbidden
+	}
+
+	running, err := s.runningEntry(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if running != nil {
+		return nil, fmt.Errorf("timer already running on step %s", running.StepName)
+	}
+
+	now := time.Now()
+	if err := s.ensureWeekOpen(ctx, claims.UserID, entryWorkDate(now)); err != nil {
+		return nil, err
+	}
+
+	entry := models.TimeEntry{
+		EntryID:      uuid.NewString(),
+		TaskID:       task.TaskID,
+		StepID:       step.StepID,
+		StepName:     step.StepName,
+		WorkFlowID:   task.WorkFlowID,
+		JobID:        task.JobID,
+		UserID:       claims.UserID,
+		DepartmentID: s.userDepartment(ctx, claims.UserID, task.Department),
+		Source:       "timer",
+		Note:         strings.TrimSpace(req.Note),
+		StartedAt:    now,
+		WorkDate:     entryWorkDate(now),
+		CreatedBy:    claims.UserID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.timeEntryRepo.CreateTimeEntry(ctx, entry); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			// มีคำขออื่นเริ่มจับเวลาไปก่อน (unique index uniq_running_timer)
+			return nil, errors.New("timer already running")
+		}
+		return nil, err
+	}
+
+	out := s.toEntryDTO(ctx, &entry, map[string]string{})
+	return &out, nil
+}
+
+func (s *timeEntryService) StopTimer(ctx context.Context, req dto.StopTimerDTO, claims *dto.JWTClaims) (*dto.TimeEntryDTO, error) {
+	running, err := s.runningEntry(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if running == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	now := time.Now()
+	if note := strings.TrimSpace(req.Note); note != "" {
+		running.Note = note
+	}
+
+	// ลืมกดหยุดนานเกินไป ต้องส่งเวลาหยุดจริงมายืนยัน (ไม่เกิน maxTimeEntryHours) ไม่ให้ชั่วโมงเกินจริงเข้า timesheet/ต้นทุนงาน
+	end := now
+	if v := strings.TrimSpace(req.EndedAt); v != "" {
+		if end, err = time.Parse(time.RFC3339, v); err != nil {
+			return nil, fmt.Errorf("invalid ended_at: %w", err)
+		}
+		if end.After(now) {
+			return nil, errors.New("ended_at cannot be in the future")
+		}
+	} else if now.Sub(running.StartedAt).Hours() > maxTimerHours {
+		return nil, fmt.Errorf("%w: timer has been running for %.1f hours, send ended_at with the actual stop time", ports.ErrTimerNeedsConfirmation, now.Sub(running.StartedAt).Hours())
+	}
+	if err := validateEntryRange(running.StartedAt, end); err != nil {
+		return nil, err
+	}
+
+	// จับเวลาข้ามวัน: ตัดเป็นรายการละวัน ให้ชั่วโมงลง timesheet ของวันที่ทำจริง
+	segments := splitAtMidnight(running.StartedAt.In(helpers.BangkokLocation()), end)
+	for _, seg := range segments {
+		if err := s.ensureWeekOpen(ctx, running.UserID, entryWorkDate(seg[0])); err != nil {
+			return nil, err
+		}
+	}
+
+	firstEnd := segments[0][1]
+	running.EndedAt = &firstEnd
+	running.Hours = entryHours(running.StartedAt, firstEnd)
+
+	updated, err := s.timeEntryRepo.UpdateTimeEntryByID(ctx, running.EntryID, *running)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	for _, seg := range segments[1:] {
+		segEnd := seg[1]
+		entry := *running
+		entry.EntryID = uuid.NewString()
+		entry.StartedAt = seg[0]
+		entry.EndedAt = &segEnd
+		entry.WorkDate = entryWorkDate(seg[0])
+		entry.Hours = entryHours(seg[0], segEnd)
+		entry.Edits = nil
+		entry.CreatedAt = now
+		entry.UpdatedAt = now
+		if err := s.timeEntryRepo.CreateTimeEntry(ctx, entry); err != nil {
+			return nil, err
+		}
+	}
+
+	out := s.toEntryDTO(ctx, updated, map[string]string{})
+	return &out, nil
+}
+
+func (s *timeEntryService) GetRunningTimer(ctx context.Context, claims *dto.JWTClaims) (*dto.TimeEntryDTO, error) {
+	running, err := s.runningEntry(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if running == nil {
+		return nil, nil
+	}
+	out := s.toEntryDTO(ctx, running, map[string]string{})
+	return &out, nil
+}
+
+func (s *timeEntryService) CreateManualEntry(ctx context.Context, req dto.CreateManualTimeEntryDTO, claims *dto.JWTClaims) (*dto.TimeEntryDTO, error) {
+	task, step, err := s.loadStep(ctx, req.TaskID, req.StepID)
+	if err != nil {
+		return nil, err
+	}
+	if !canLogTime(task, step, claims) {
+		return nil, ports.ErrTimeEntryForbidden
+	}
+
+	var start, end time.Time
+	timed := false
+	switch {
+	case strings.TrimSpace(req.StartedAt) != "" || strings.TrimSpace(req.EndedAt) != "":
+		timed = true
+		if start, err = time.Parse(time.RFC3339, strings.TrimSpace(req.StartedAt)); err != nil {
+			return nil, fmt.Errorf("invalid started_at: %w", err)
+		}
+		if end, err = time.Parse(time.RFC3339, strings.TrimSpace(req.EndedAt)); err != nil {
+			return nil, fmt.Errorf("invalid ended_at: %w", err)
+		}
+	case strings.TrimSpace(req.WorkDate) != "":
+		day, err := helpers.DateToISO(strings.TrimSpace(req.WorkDate))
+		if err != nil {
+			return nil, fmt.Errorf("invalid work_date: %w", err)
+		}
+		if req.Hours <= 0 {
+			return nil, errors.New("hours must be greater than 0")
+		}
+		start = day
+		end = day.Add(time.Duration(req.Hours * float64(time.Hour)))
+	default:
+		return nil, errors.New("started_at/ended_at or work_date/hours is required")
+	}
+	if err := validateEntryRange(start, end); err != nil {
+		return nil, err
+	}
+	if err := s.ensureWeekOpen(ctx, claims.UserID, entryWorkDate(start)); err != nil {
+		return nil, err
+	}
+	// กันลงชั่วโมงเดียวกันซ้ำ: ช่วงเวลาจริงห้ามทับรายการอื่น ส่วนแบบระบุวัน/ชั่วโมงรวมทั้งวันต้องไม่เกิน 24 ชั่วโมง
+	if timed {
+		if err := s.ensureNoOverlap(ctx, claims.UserID, start, end, ""); err != nil {
+			return nil, err
+		}
+	} else if err := s.ensureDayCapacity(ctx, claims.UserID, entryWorkDate(start), entryHours(start, end), ""); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	entry := models.TimeEntry{
+		EntryID:      uuid.NewString(),
+		TaskID:       task.TaskID,
+		StepID:       step.StepID,
+		StepName:     step.StepName,
+		WorkFlowID:   task.WorkFlowID,
+		JobID:        task.JobID,
+		UserID:       claims.UserID,
+		DepartmentID: s.userDepartment(ctx, claims.UserID, task.Department),
+		Source:       "manual",
+		Note:         strings.TrimSpace(req.Note),
+		StartedAt:    start,
+		EndedAt:      &end,
+		WorkDate:     entryWorkDate(start),
+		Hours:        entryHours(start, end),
+		CreatedBy:    claims.UserID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.timeEntryRepo.CreateTimeEntry(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	out := s.toEntryDTO(ctx, &entry, map[string]string{})
+	return &out, nil
+}
+
+func (s *timeEntryService) UpdateTimeEntry(ctx context.Context, entryID string, req dto.UpdateTimeEntryDTO, claims *dto.JWTClaims) (*dto.TimeEntryDTO, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, errors.New("reason is required")
+	}
+
+	existing, err := s.timeEntryRepo.GetOneTimeEntryByFilter(ctx, bson.M{"entry_id": entryID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	if claims.Role != "admin" && existing.UserID != claims.UserID {
+		return nil, ports.ErrTimeEntryForbidden
+	}
+	if existing.EndedAt == nil {
+		return nil, errors.New("stop the timer before editing")
+	}
+	if err := s.ensureWeekOpen(ctx, existing.UserID, existing.WorkDate); err != nil {
+		return nil, err
+	}
+
+	edit := models.TimeEntryEdit{
+		EditedAt:      time.Now(),
+		EditedBy:      claims.UserID,
+		Reason:        reason,
+		PrevStartedAt: existing.StartedAt,
+		PrevEndedAt:   existing.EndedAt,
+		PrevHours:     existing.Hours,
+		PrevNote:      existing.Note,
+	}
+
+	start, end := existing.StartedAt, *existing.EndedAt
+	if v := strings.TrimSpace(req.StartedAt); v != "" {
+		if start, err = time.Parse(time.RFC3339, v); err != nil {
+			return nil, fmt.Errorf("invalid started_at: %w", err)
+		}
+	}
+	if v := strings.TrimSpace(req.EndedAt); v != "" {
+		if end, err = time.Parse(time.RFC3339, v); err != nil {
+			return nil, fmt.Errorf("invalid ended_at: %w", err)
+		}
+	}
+	if err := validateEntryRange(start, end); err != nil {
+		return nil, err
+	}
+	if !entryWorkDate(start).Equal(existing.WorkDate) {
+		if err := s.ensureWeekOpen(ctx, existing.UserID, entryWorkDate(start)); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.ensureNoOverlap(ctx, existing.UserID, start, end, existing.EntryID); err != nil {
+		return nil, err
+	}
+
+	existing.StartedAt = start
+	existing.EndedAt = &end
+	existing.WorkDate = entryWorkDate(start)
+	existing.Hours = entryHours(start, end)
+	if req.Note != nil {
+		existing.Note = strings.TrimSpace(*req.Note)
+	}
+	existing.Edits = append(existing.Edits, edit)
+
+	updated, err := s.timeEntryRepo.UpdateTimeEntryByID(ctx, entryID, *existing)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	out := s.toEntryDTO(ctx, updated, map[string]string{})
+	return &out, nil
+}
+
+func (s *timeEntryService) ListTimeEntries(ctx context.Context, req dto.RequestListTimeEntries, claims *dto.JWTClaims) ([]dto.TimeEntryDTO, error) {
+	filter := bson.M{"deleted_at": nil}
+
+	taskID := strings.TrimSpace(req.TaskID)
+	userID := strings.TrimSpace(req.UserID)
+	if taskID != "" {
+		filter["task_id"] = taskID
+	}
+	if v := strings.TrimSpace(req.StepID); v != "" {
+		filter["step_id"] = v
+	}
+
+	// สิทธิ์: admin เห็นทั้งหมด, ผู้รับผิดชอบงานเห็นทุกคนในงานนั้น, ผู้จัดการเห็นคนในแผนก, นอกนั้นเห็นเฉพาะของตัวเอง
+	if claims.Role != "admin" {
+		switch {
+		case userID != "" && userID != claims.UserID:
+			ok, err := s.managesUser(ctx, claims.UserID, userID)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, ports.ErrTimeEntryForbidden
+			}
+		case userID == "" && taskID != "":
+			task, err := s.taskRepo.GetOneTasksByFilter(ctx, bson.M{"task_id": taskID, "deleted_at": nil}, bson.M{})
+			if err != nil {
+				return nil, err
+			}
+			if task == nil || !isTaskParticipant(task, claims.UserID) {
+				userID = claims.UserID
+			}
+		case userID == "":
+			userID = claims.UserID
+		}
+	}
+	if userID != "" {
+		filter["user_id"] = userID
+	}
+
+	dateFilter, err := workDateFilter(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	if dateFilter != nil {
+		filter["work_date"] = dateFilter
+	}
+
+	entries, err := s.timeEntryRepo.GetAllTimeEntriesByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]string{}
+	out := make([]dto.TimeEntryDTO, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, s.toEntryDTO(ctx, e, names))
+	}
+	return out, nil
+}
+
+// EstimateVsActual เทียบชั่วโมงประมาณการของ step (จาก template) กับเวลาที่ลงจริง
+// นับเฉพาะ step ที่มีการลงเวลาในช่วงที่เลือก; group_by=user แบ่งชั่วโมงประมาณการตามสัดส่วนเวลาที่แต่ละคนลงใน step นั้น
+func (s *timeEntryService) EstimateVsActual(ctx context.Context, req dto.RequestTimeReport, claims *dto.JWTClaims) ([]dto.EstimateVsActualDTO, error) {
+	groupBy := strings.ToLower(strings.TrimSpace(req.GroupBy))
+	if groupBy == "" {
+		groupBy = "workflow"
+	}
+	if groupBy != "workflow" && groupBy != "user" {
+		return nil, errors.New("group_by must be workflow or user")
+	}
+
+	from, to := req.From, req.To
+	if strings.TrimSpace(from) == "" {
+		from = helpers.Today().AddDate(0, 0, -defaultReportPeriod).Format("2006-01-02")
+	}
+	if strings.TrimSpace(to) == "" {
+		to = helpers.Today().Format("2006-01-02")
+	}
+	dateFilter, err := workDateFilter(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"deleted_at": nil, "ended_at": bson.M{"$ne": nil}, "work_date": dateFilter}
+	if v := strings.TrimSpace(req.WorkFlowID); v != "" {
+		filter["workflow_id"] = v
+	}
+	if v := strings.TrimSpace(req.UserID); v != "" {
+		filter["user_id"] = v
+	}
+	department := strings.TrimSpace(req.DepartmentID)
+
+	// ไม่ใช่ admin: ผู้จัดการดูได้เฉพาะแผนกตัวเอง พนักงานดูได้เฉพาะของตัวเอง
+	if claims.Role != "admin" {
+		managed, err := s.managedDepartments(ctx, claims.UserID)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case department != "":
+			if !helpers.InSet(department, managed...) {
+				return nil, ports.ErrTimeEntryForbidden
+			}
+		case len(managed) > 0:
+			filter["department_id"] = bson.M{"$in": managed}
+		default:
+			filter["user_id"] = claims.UserID
+		}
+	}
+	if department != "" {
+		filter["department_id"] = department
+	}
+
+	entries, err := s.timeEntryRepo.GetAllTimeEntriesByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return []dto.EstimateVsActualDTO{}, nil
+	}
+
+	taskIDs := make([]string, 0)
+	seenTask := map[string]bool{}
+	for _, e := range entries {
+		if !seenTask[e.TaskID] {
+			seenTask[e.TaskID] = true
+			taskIDs = append(taskIDs, e.TaskID)
+		}
+	}
+	tasks, err := s.taskRepo.GetAllTaskByFilter(ctx, bson.M{"task_id": bson.M{"$in": taskIDs}}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	taskByID := make(map[string]*models.Tasks, len(tasks))
+	stepByKey := map[string]models.TaskWorkflowStep{}
+	for _, t := range tasks {
+		taskByID[t.TaskID] = t
+		for _, st := range t.AppliedWorkflow.Steps {
+			stepByKey[t.TaskID+"/"+st.StepID] = st
+		}
+	}
+
+	// เวลาจริงรวมต่อ step และต่อ (คน, step)
+	stepActual := map[string]float64{}
+	userStepActual := map[string]map[string]float64{}
+	for _, e := range entries {
+		key := e.TaskID + "/" + e.StepID
+		stepActual[key] += e.Hours
+		if userStepActual[e.UserID] == nil {
+			userStepActual[e.UserID] = map[string]float64{}
+		}
+		userStepActual[e.UserID][key] += e.Hours
+	}
+
+	type stepAgg struct {
+		name      string
+		estimated float64
+		actual    float64
+	}
+	type groupAgg struct {
+		name      string
+		tasks     map[string]bool
+		steps     map[string]bool
+		estimated float64
+		actual    float64
+		byStep    map[string]*stepAgg
+	}
+	groups := map[string]*groupAgg{}
+	group := func(key, name string) *groupAgg {
+		g := groups[key]
+		if g == nil {
+			g = &groupAgg{name: name, tasks: map[string]bool{}, steps: map[string]bool{}, byStep: map[string]*stepAgg{}}
+			groups[key] = g
+		}
+		return g
+	}
+
+	if groupBy == "workflow" {
+		for key, actual := range stepActual {
+			taskID := strings.SplitN(key, "/", 2)[0]
+			t := taskByID[taskID]
+			st := stepByKey[key]
+			workflowID, workflowName := "", "ไม่พบ workflow"
+			if t != nil {
+				workflowID = t.WorkFlowID
+				workflowName = t.AppliedWorkflow.WorkFlowName
+			}
+			g := group(workflowID, workflowName)
+			g.tasks[taskID] = true
+			g.steps[key] = true
+			g.estimated += st.Hours
+			g.actual += actual
+
+			stepName := st.StepName
+			if stepName == "" {
+				stepName = "ไม่พบขั้นตอน"
+			}
+			sk := strings.ToLower(strings.TrimSpace(stepName))
+			if g.byStep[sk] == nil {
+				g.byStep[sk] = &stepAgg{name: stepName}
+			}
+			g.byStep[sk].estimated += st.Hours
+			g.byStep[sk].actual += actual
+		}
+	} else {
+		names := map[string]string{}
+		for userID, steps := range userStepActual {
+			g := group(userID, s.userName(ctx, userID, names))
+			for key, actual := range steps {
+				share := 0.0
+				if stepActual[key] > 0 {
+					share = actual / stepActual[key]
+				}
+				g.tasks[strings.SplitN(key, "/", 2)[0]] = true
+				g.steps[key] = true
+				g.estimated += stepByKey[key].Hours * share
+				g.actual += actual
+			}
+		}
+	}
+
+	out := make([]dto.EstimateVsActualDTO, 0, len(groups))
+	for key, g := range groups {
+		row := dto.EstimateVsActualDTO{
+			Key:             key,
+			Name:            g.name,
+			TaskCount:       len(g.tasks),
+			StepCount:       len(g.steps),
+			EstimatedHours:  util.Round2(g.estimated),
+			ActualHours:     util.Round2(g.actual),
+			VarianceHours:   util.Round2(g.actual - g.estimated),
+			VariancePercent: variancePercent(g.estimated, g.actual),
+		}
+		for _, st := range g.byStep {
+			row.Steps = append(row.Steps, dto.EstimateVsActualStepDTO{
+				StepName:        st.name,
+				EstimatedHours:  util.Round2(st.estimated),
+				ActualHours:     util.Round2(st.actual),
+				VarianceHours:   util.Round2(st.actual - st.estimated),
+				VariancePercent: variancePercent(st.estimated, st.actual),
+			})
+		}
+		sort.Slice(row.Steps, func(i, j int) bool { return row.Steps[i].StepName < row.Steps[j].StepName })
+		out = append(out, row)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (s *timeEntryService) GetTimesheet(ctx context.Context, req dto.RequestTimesheet, claims *dto.JWTClaims) (*dto.TimesheetDTO, error) {
+	userID := strings.TrimSpace(req.UserID)
+	if userID == "" {
+		userID = claims.UserID
+	}
+	if userID != claims.UserID && claims.Role != "admin" {
+		ok, err := s.managesUser(ctx, claims.UserID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ports.ErrTimeEntryForbidden
+		}
+	}
+
+	weekStart, err := parseWeek(req.Week)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildTimesheet(ctx, userID, weekStart, true)
+}
+
+func (s *timeEntryService) SubmitTimesheet(ctx context.Context, req dto.SubmitTimesheetDTO, claims *dto.JWTClaims) (*dto.TimesheetDTO, error) {
+	weekStart, err := parseWeek(req.Week)
+	if err != nil {
+		return nil, err
+	}
+
+	running, err := s.runningEntry(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if running != nil && weekOf(running.WorkDate).Equal(weekStart) {
+		return nil, errors.New("stop the running timer before submitting")
+	}
+
+	sheet, err := s.buildTimesheet(ctx, claims.UserID, weekStart, false)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.timeEntryRepo.GetOneTimesheetByFilter(ctx, bson.M{"user_id": claims.UserID, "week_start": weekStart}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if existing == nil {
+		doc := models.Timesheet{
+			TimesheetID:  uuid.NewString(),
+			UserID:       claims.UserID,
+			DepartmentID: s.userDepartment(ctx, claims.UserID, ""),
+			WeekStart:    weekStart,
+			Status:       "submitted",
+			TotalHours:   sheet.TotalHours,
+			SubmittedAt:  &now,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		if err := s.timeEntryRepo.CreateTimesheet(ctx, doc); err != nil {
+			return nil, err
+		}
+	} else {
+		if existing.Status == "submitted" || existing.Status == "approved" {
+			return nil, fmt.Errorf("timesheet is already %s", existing.Status)
+		}
+		existing.Status = "submitted"
+		existing.TotalHours = sheet.TotalHours
+		existing.SubmittedAt = &now
+		existing.ReviewedAt = nil
+		existing.ReviewedBy = ""
+		existing.ReviewNote = ""
+		if _, err := s.timeEntryRepo.UpdateTimesheetByID(ctx, existing.TimesheetID, *existing); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.buildTimesheet(ctx, claims.UserID, weekStart, true)
+}
+
+func (s *timeEntryService) ReviewTimesheet(ctx context.Context, timesheetID string, req dto.ReviewTimesheetDTO, claims *dto.JWTClaims) (*dto.TimesheetDTO, error) {
+	existing, err := s.timeEntryRepo.GetOneTimesheetByFilter(ctx, bson.M{"timesheet_id": timesheetID}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	if existing.Status != "submitted" {
+		return nil, fmt.Errorf("timesheet is %s", existing.Status)
+	}
+
+	// ผู้จัดการแผนกของเจ้าของใบลงเวลา (ห้ามอนุมัติของตัวเอง) หรือ admin
+	if claims.Role != "admin" {
+		managed, err := s.managedDepartments(ctx, claims.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if existing.UserID == claims.UserID || !helpers.InSet(existing.DepartmentID, managed...) {
+			return nil, ports.ErrTimeEntryForbidden
+		}
+	}
+
+	note := strings.TrimSpace(req.Note)
+	if !req.Approve && note == "" {
+		return nil, errors.New("note is required when rejecting")
+	}
+
+	now := time.Now()
+	existing.Status = "rejected"
+	if req.Approve {
+		existing.Status = "approved"
+	}
+	existing.ReviewedBy = claims.UserID
+	existing.ReviewedAt = &now
+	existing.ReviewNote = note
+	if _, err := s.timeEntryRepo.UpdateTimesheetByID(ctx, existing.TimesheetID, *existing); err != nil {
+		return nil, err
+	}
+
+	return s.buildTimesheet(ctx, existing.UserID, existing.WeekStart, true)
+}
+
+func (s *timeEntryService) ListTimesheets(ctx context.Context, req dto.RequestListTimesheets, claims *dto.JWTClaims) ([]dto.TimesheetDTO, error) {
+	filter := bson.M{}
+	department := strings.TrimSpace(req.DepartmentID)
+	if claims.Role != "admin" {
+		managed, err := s.managedDepartments(ctx, claims.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if len(managed) == 0 || (department != "" && !helpers.InSet(department, managed...)) {
+			return nil, ports.ErrTimeEntryForbidden
+		}
+		filter["department_id"] = bson.M{"$in": managed}
+	}
+	if department != "" {
+		filter["department_id"] = department
+	}
+	if v := strings.TrimSpace(req.Status); v != "" {
+		filter["status"] = v
+	}
+	if strings.TrimSpace(req.Week) != "" {
+		weekStart, err := parseWeek(req.Week)
+		if err != nil {
+			return nil, err
+		}
+		filter["week_start"] = weekStart
+	}
+
+	sheets, err := s.timeEntryRepo.GetAllTimesheetsByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]string{}
+	out := make([]dto.TimesheetDTO, 0, len(sheets))
+	for _, ts := range sheets {
+		out = append(out, dto.TimesheetDTO{
+			TimesheetID:  ts.TimesheetID,
+			UserID:       ts.UserID,
+			UserName:     s.userName(ctx, ts.UserID, names),
+			DepartmentID: ts.DepartmentID,
+			WeekStart:    helpers.DayKey(ts.WeekStart),
+			WeekEnd:      helpers.DayKey(ts.WeekStart.AddDate(0, 0, 6)),
+			Status:       ts.Status,
+			TotalHours:   ts.TotalHours,
+			SubmittedAt:  ts.SubmittedAt,
+			ReviewedBy:   ts.ReviewedBy,
+			ReviewedAt:   ts.ReviewedAt,
+			ReviewNote:   ts.ReviewNote,
+		})
+	}
+	return out, nil
+}
+
+// buildTimesheet รวมรายการเวลาของผู้ใช้ในสัปดาห์เป็นรายวัน พร้อมสถานะการอนุมัติ
+func (s *timeEntryService) buildTimesheet(ctx context.Context, userID string, weekStart time.Time, withEntries bool) (*dto.TimesheetDTO, error) {
+	weekEnd := weekStart.AddDate(0, 0, 7)
+	entries, err := s.timeEntryRepo.GetAllTimeEntriesByFilter(ctx, bson.M{
+		"user_id":    userID,
+		"deleted_at": nil,
+		"work_date":  bson.M{"$gte": weekStart, "$lt": weekEnd},
+	}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]string{}
+	out := &dto.TimesheetDTO{
+		UserID:       userID,
+		UserName:     s.userName(ctx, userID, names),
+		DepartmentID: s.userDepartment(ctx, userID, ""),
+		WeekStart:    helpers.DayKey(weekStart),
+		WeekEnd:      helpers.DayKey(weekStart.AddDate(0, 0, 6)),
+		Status:       "draft",
+	}
+
+	days := make([]dto.TimesheetDayDTO, 7)
+	for i := range days {
+		days[i] = dto.TimesheetDayDTO{Date: helpers.DayKey(weekStart.AddDate(0, 0, i)), Entries: []dto.TimeEntryDTO{}}
+	}
+	var total float64
+	for _, e := range entries {
+		idx := int(e.WorkDate.Sub(weekStart).Hours() / 24)
+		if idx < 0 || idx > 6 {
+			continue
+		}
+		item := s.toEntryDTO(ctx, e, names)
+		days[idx].Hours += item.Hours
+		if withEntries {
+			days[idx].Entries = append(days[idx].Entries, item)
+		}
+		total += item.Hours
+	}
+	for i := range days {
+		days[i].Hours = util.Round2(days[i].Hours)
+	}
+	out.Days = days
+	out.TotalHours = util.Round2(total)
+
+	sheet, err := s.timeEntryRepo.GetOneTimesheetByFilter(ctx, bson.M{"user_id": userID, "week_start": weekStart}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if sheet != nil {
+		out.TimesheetID = sheet.TimesheetID
+		out.Status = sheet.Status
+		out.SubmittedAt = sheet.SubmittedAt
+		out.ReviewedBy = sheet.ReviewedBy
+		out.ReviewedAt = sheet.ReviewedAt
+		out.ReviewNote = sheet.ReviewNote
+		if sheet.DepartmentID != "" {
+			out.DepartmentID = sheet.DepartmentID
+		}
+	}
+	return out, nil
+}
+
+func (s *timeEntryService) loadStep(ctx context.Context, taskID, stepID string) (*models.Tasks, *models.TaskWorkflowStep, error) {
+	taskID = strings.TrimSpace(taskID)
+	stepID = strings.TrimSpace(stepID)
+	if taskID == "" || stepID == "" {
+		return nil, nil, errors.New("task_id and step_id are required")
+	}
+	task, err := s.taskRepo.GetOneTasksByFilter(ctx, bson.M{"task_id": taskID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, nil, err
+	}
+	if task == nil {
+		return nil, nil, mongo.ErrNoDocuments
+	}
+	if task.Status == "cancelled" {
+		return nil, nil, errors.New("task has been cancelled")
+	}
+	for i := range task.AppliedWorkflow.Steps {
+		if task.AppliedWorkflow.Steps[i].StepID == stepID {
+			return task, &task.AppliedWorkflow.Steps[i], nil
+		}
+	}
+	return nil, nil, mongo.ErrNoDocuments
+}
+
+func (s *timeEntryService) runningEntry(ctx context.Context, userID string) (*models.TimeEntry, error) {
+	return s.timeEntryRepo.GetOneTimeEntryByFilter(ctx, bson.M{"user_id": userID, "ended_at": nil, "deleted_at": nil}, bson.M{})
+}
+
+// ensureWeekOpen ห้ามเพิ่ม/แก้เวลาในสัปดาห์ที่ส่งหรืออนุมัติ timesheet แล้ว
+func (s *timeEntryService) ensureWeekOpen(ctx context.Context, userID string, day time.Time) error {
+	weekStart := weekOf(day)
+	sheet, err := s.timeEntryRepo.GetOneTimesheetByFilter(ctx, bson.M{"user_id": userID, "week_start": weekStart}, bson.M{"status": 1})
+	if err != nil {
+		return err
+	}
+	if sheet != nil && (sheet.Status == "submitted" || sheet.Status == "approved") {
+		return fmt.Errorf("timesheet for week %s is %s", helpers.DayKey(weekStart), sheet.Status)
+	}
+	return nil
+}
+
+// ensureNoOverlap ห้ามช่วงเวลาทับรายการอื่นของผู้ใช้ (รวมตัวจับเวลาที่กำลังเดิน)
+// รายการแบบระบุวัน/ชั่วโมงไม่มีเวลาจริง (started_at = work_date) จึงไม่นำมาเทียบ
+func (s *timeEntryService) ensureNoOverlap(ctx context.Context, userID string, start, end time.Time, excludeID string) error {
+	filter := bson.M{
+		"user_id":    userID,
+		"deleted_at": nil,
+		"started_at": bson.M{"$lt": end},
+		"$or":        []bson.M{{"ended_at": bson.M{"$gt": start}}, {"ended_at": nil}},
+		"$expr":      bson.M{"$ne": []string{"$started_at", "$work_date"}},
+	}
+	if excludeID != "" {
+		filter["entry_id"] = bson.M{"$ne": excludeID}
+	}
+	other, err := s.timeEntryRepo.GetOneTimeEntryByFilter(ctx, filter, bson.M{"step_name": 1, "started_at": 1, "ended_at": 1})
+	if err != nil {
+		return err
+	}
+	if other != nil {
+		return fmt.Errorf("%w: overlaps entry on step %s started at %s", ports.ErrTimeEntryOverlap, other.StepName, other.StartedAt.In(helpers.BangkokLocation()).Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+// ensureDayCapacity ชั่วโมงรวมของวันหลังเพิ่มรายการนี้ต้องไม่เกิน 24 ชั่วโมง
+func (s *timeEntryService) ensureDayCapacity(ctx context.Context, userID string, day time.Time, hours float64, excludeID string) error {
+	filter := bson.M{"user_id": userID, "work_date": day, "deleted_at": nil}
+	if excludeID != "" {
+		filter["entry_id"] = bson.M{"$ne": excludeID}
+	}
+	entries, err := s.timeEntryRepo.GetAllTimeEntriesByFilter(ctx, filter, bson.M{"hours": 1})
+	if err != nil {
+		return err
+	}
+	total := hours
+	for _, e := range entries {
+		total += e.Hours
+	}
+	if total > maxTimeEntryHours {
+		return fmt.Errorf("%w: %.2f hours logged on %s", ports.ErrTimeEntryOverlap, total, helpers.DayKey(day))
+	}
+	return nil
+}
+
+func (s *timeEntryService) managedDepartments(ctx context.Context, userID string) ([]string, error) {
+	departments, err := s.departmentRepo.GetAllDepartmentByFilter(ctx, bson.M{"manager_id": userID, "deleted_at": nil}, bson.M{"department_id": 1})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(departments))
+	for _, d := range departments {
+		ids = append(ids, d.DepartmentID)
+	}
+	return ids, nil
+}
+
+// managesUser คืน true ถ้า managerID เป็นผู้จัดการแผนกของ userID
+func (s *timeEntryService) managesUser(ctx context.Context, managerID, userID string) (bool, error) {
+	managed, err := s.managedDepartments(ctx, managerID)
+	if err != nil || len(managed) == 0 {
+		return false, err
+	}
+	return helpers.InSet(s.userDepartment(ctx, userID, ""), managed...), nil
+}
+
+func (s *timeEntryService) userDepartment(ctx context.Context, userID, fallback string) string {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil || user.DepartmentID == "" {
+		return fallback
+	}
+	return user.DepartmentID
+}
+
+func (s *timeEntryService) userName(ctx context.Context, userID string, cache map[string]string) string {
+	if name, ok := cache[userID]; ok {
+		return name
+	}
+	name := "ไม่พบชื่อผู้ใช้"
+	if user, _ := s.userRepo.GetByID(ctx, userID); user != nil {
+		name = fmt.Sprintf("%s %s %s", user.TitleTH, user.FirstNameTH, user.LastNameTH)
+	}
+	cache[userID] = name
+	return name
+}
+
+func (s *timeEntryService) toEntryDTO(ctx context.Context, e *models.TimeEntry, names map[string]string) dto.TimeEntryDTO {
+	hours := e.Hours
+	if e.EndedAt == nil {
+		hours = entryHours(e.StartedAt, time.Now())
+	}
+	edits := make([]dto.TimeEntryEditDTO, 0, len(e.Edits))
+	for _, ed := range e.Edits {
+		edits = append(edits, dto.TimeEntryEditDTO{
+			EditedAt:      ed.EditedAt,
+			EditedBy:      ed.EditedBy,
+			Reason:        ed.Reason,
+			PrevStartedAt: ed.PrevStartedAt,
+			PrevEndedAt:   ed.PrevEndedAt,
+			PrevHours:     ed.PrevHours,
+		})
+	}
+	return dto.TimeEntryDTO{
+		EntryID:      e.EntryID,
+		TaskID:       e.TaskID,
+		StepID:       e.StepID,
+		StepName:     e.StepName,
+		WorkFlowID:   e.WorkFlowID,
+		JobID:        e.JobID,
+		UserID:       e.UserID,
+		UserName:     s.userName(ctx, e.UserID, names),
+		DepartmentID: e.DepartmentID,
+		Source:       e.Source,
+		Note:         e.Note,
+		Edits:        edits,
+		StartedAt:    e.StartedAt,
+		EndedAt:      e.EndedAt,
+		WorkDate:     helpers.DayKey(e.WorkDate),
+		Hours:        hours,
+		Running:      e.EndedAt == nil,
+		CreatedAt:    e.CreatedAt,
+		UpdatedAt:    e.UpdatedAt,
+	}
+}
+
+// canLogTime ลงเวลาได้เฉพาะผู้รับผิดชอบ step หรือผู้รับผิดชอบหลักของงาน
+func canLogTime(task *models.Tasks, step *models.TaskWorkflowStep, claims *dto.JWTClaims) bool {
+	return claims.UserID == task.Assignee || claims.UserID == stepOwner(task, *step)
+}
+
+// isTaskParticipant ผู้สร้าง ผู้รับผิดชอบหลัก หรือเจ้าของ step ใดก็ได้ในงาน
+func isTaskParticipant(task *models.Tasks, userID string) bool {
+	if task.Assignee == userID || task.CreatedBy == userID {
+		return true
+	}
+	for _, st := range task.AppliedWorkflow.Steps {
+		if st.Assignee == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func validateEntryRange(start, end time.Time) error {
+	if !end.After(start) {
+		return errors.New("ended_at must be after started_at")
+	}
+	if end.Sub(start).Hours() > maxTimeEntryHours {
+		return fmt.Errorf("a time entry cannot exceed %d hours", maxTimeEntryHours)
+	}
+	if start.After(time.Now()) {
+		return errors.New("cannot log time in the future")
+	}
+	return nil
+}
+
+// entryWorkDate วันที่ทำงานตามเวลาไทยของเวลาเริ่ม
+func entryWorkDate(t time.Time) time.Time {
+	return helpers.DateOnly(t.In(helpers.BangkokLocation()))
+}
+
+// splitAtMidnight แบ่งช่วง start-end ตามเที่ยงคืนของเวลา start (ช่วงแรกคือวันที่เริ่ม)
+func splitAtMidnight(start, end time.Time) [][2]time.Time {
+	out := [][2]time.Time{}
+	for {
+		midnight := time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, start.Location())
+		if !end.After(midnight) {
+			return append(out, [2]time.Time{start, end})
+		}
+		out = append(out, [2]time.Time{start, midnight})
+		start = midnight
+	}
+}
+
+func entryHours(start, end time.Time) float64 {
+	if !end.After(start) {
+		return 0
+	}
+	return util.Round2(end.Sub(start).Hours())
+}
+
+func variancePercent(estimated, actual float64) float64 {
+	if estimated <= 0 {
+		return 0
+	}
+	return util.Round2((actual - estimated) / estimated * 100)
+}
+
+// weekOf คืนวันจันทร์ของสัปดาห์ (00:00 UTC)
+func weekOf(day time.Time) time.Time {
+	d := helpers.DateOnly(day)
+	offset := (int(d.Weekday()) + 6) % 7
+	return d.AddDate(0, 0, -offset)
+}
+
+func parseWeek(week string) (time.Time, error) {
+	week = strings.TrimSpace(week)
+	if week == "" {
+		return weekOf(helpers.Today()), nil
+	}
+	day, err := helpers.DateToISO(week)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid week: %w", err)
+	}
+	return weekOf(day), nil
+}
+
+func workDateFilter(from, to string) (bson.M, error) {
+	filter := bson.M{}
+	if v := strings.TrimSpace(from); v != "" {
+		d, err := helpers.DateToISO(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from: %w", err)
+		}
+		filter["$gte"] = d
+	}
+	if v := strings.TrimSpace(to); v != "" {
+		d, err := helpers.DateToISO(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to: %w", err)
+		}
+		filter["$lte"] = d
+	}
+	if len(filter) == 0 {
+		return nil, nil
+	}
+	return filter, nil
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"log"
 	"maps"
 	"math"
 	"strings"
@@ -23,12 +24,14 @@ type userService struct {
 	userRepo          ports.UserRepository
 	dropDownRepo      ports.DropDownRepository
 	taskRepo          ports.TaskRepository
+	historySvc        ports.EmploymentHistoryService
+	checklistSvc      ports.EmployeeChecklistService
 	storageCloudflare *storage.CloudflareStorage
 	config            config.Config
 }
 
-func NewUserService(cfg config.Config, ur ports.UserRepository, dr ports.DropDownRepository, sc *storage.CloudflareStorage, tr ports.TaskRepository) ports.UserService {
-	return &userService{config: cfg, userRepo: ur, dropDownRepo: dr, storageCloudflare: sc, taskRepo: tr}
+func NewUserService(cfg config.Config, ur ports.UserRepository, dr ports.DropDownRepository, sc *storage.CloudflareStorage, tr ports.TaskRepository, hs ports.EmploymentHistoryService, cs ports.EmployeeChecklistService) ports.UserService {
+	return &userService{config: cfg, userRepo: ur, dropDownRepo: dr, storageCloudflare: sc, taskRepo: tr, historySvc: hs, checklistSvc: cs}
 }
 
 func (s *userService) Create(ctx context.Context, req dto.RequestCreateUser) error {
@@ -81,6 +84,7 @@ func (s *userService) Create(ctx context.Context, req dto.RequestCreateUser) err
 			{Name: "", FileURL: "", Type: "health", CreatedAt: time.Now(), UploadedAt: time.Now(), DeletedAt: nil},
 			{Name: "", FileURL: "", Type: "military", CreatedAt: time.Now(), UploadedAt: time.Now(), DeletedAt: nil},
 			{Name: "", FileURL: "", Type: "criminal", CreatedAt: time.Now(), UploadedAt: time.Now(), DeletedAt: nil},
+			{Name: "", FileURL: "", Type: "contract", CreatedAt: time.Now(), UploadedAt: time.Now(), DeletedAt: nil},
 			{Name: "", FileURL: "", Type: "other", CreatedAt: time.Now(), UploadedAt: time.Now(), DeletedAt: nil},
 		},
 		CreatedAt: time.Now(),
@@ -105,6 +109,18 @@ func (s *userService) Create(ctx context.Context, req dto.RequestCreateUser) err
 		return fmt.Errorf("failed to create user: %w", errOnCreateUser)
 	}
 
+	// เปิดประวัติการจ้างงานรายการแรกหลังบันทึกผู้ใช้สำเร็จ ถ้าไม่สำเร็จจะสร้างใหม่ตอนมีการเปลี่ยนแปลงครั้งแรก
+	if errOnHistory := s.historySvc.StartEmploymentHistory(ctx, user, ""); errOnHistory != nil {
+		log.Println("Error starting employment history:", errOnHistory)
+	} else if _, errOnUpdate := s.userRepo.UpdateUserByFilter(ctx, bson.M{"user_id": user.UserID}, bson.M{"$set": bson.M{"employment_history": user.EmploymentHistory}}); errOnUpdate != nil {
+		log.Println("Error saving employment history snapshot:", errOnUpdate)
+	}
+
+	// เปิด checklist รับพนักงานเข้า ถ้าไม่สำเร็จจะสร้างใหม่ตอนอนุมัติผู้ใช้
+	if _, errOnChecklist := s.checklistSvc.StartOnboarding(ctx, user, ""); errOnChecklist != nil {
+		log.Println("Error starting onboarding checklist:", errOnChecklist)
+	}
+
 	return nil
 }
 
@@ -191,12 +207,15 @@ func (s *userService) GetByID(ctx context.Context, id string) (*dto.ResponseGetU
 	var dtoEmploymentHistory []dto.EmploymentHistory
 	for _, eh := range user.EmploymentHistory {
 		dtoEmploymentHistory = append(dtoEmploymentHistory, dto.EmploymentHistory{
+			HistoryID:      eh.HistoryID,
 			UserID:         eh.UserID,
 			PositionID:     eh.PositionID,
 			DepartmentID:   eh.DepartmentID,
 			FromDate:       eh.FromDate,
 			ToDate:         eh.ToDate,
 			EmploymentType: eh.EmploymentType,
+			ChangeType:     eh.ChangeType,
+			Status:         eh.Status,
 			Note:           eh.Note,
 			CreatedAt:      eh.CreatedAt,
 			UpdatedAt:      eh.UpdatedAt,
@@ -408,7 +427,7 @@ func (s *userService) GetAll(ctx context.Context, req dto.RequestGetUserAll) (dt
 	return pagination, nil
 }
 
-func (s *userService) UpdateUserByID(ctx context.Context, id string, req dto.RequestUpdateUser) (*models.User, error) {
+func (s *userService) UpdateUserByID(ctx context.Context, id string, req dto.RequestUpdateUser, claims *dto.JWTClaims) (*models.User, error) {
 
 	isEditName := false
 
@@ -421,25 +440,6 @@ func (s *userService) UpdateUserByID(ctx context.Context, id string, req dto.Req
 		return nil, mongo.ErrNoDocuments
 	}
 
-	if req.EmploymentHistory != nil {
-		var employmentHistory []models.EmploymentHistory
-		for _, eh := range req.EmploymentHistory {
-			employmentHistory = append(employmentHistory, models.EmploymentHistory{
-				UserID:         eh.UserID,
-				PositionID:     eh.PositionID,
-				DepartmentID:   eh.DepartmentID,
-				FromDate:       eh.FromDate,
-				ToDate:         eh.ToDate,
-				EmploymentType: eh.EmploymentType,
-				Note:           eh.Note,
-				CreatedAt:      eh.CreatedAt,
-				UpdatedAt:      eh.UpdatedAt,
-				DeletedAt:      eh.DeletedAt,
-			})
-		}
-		user.EmploymentHistory = employmentHistory
-	}
-
 	if req.Documents != nil {
 		var documents []models.Document
 		for _, doc := range req.Documents {
@@ -502,12 +502,6 @@ func (s *userService) UpdateUserByID(ctx context.Context, id string, req dto.Req
 		}
 		user.BirthDate = parsedDate
 	}
-	if req.PositionID != "" {
-		user.PositionID = req.PositionID
-	}
-	if req.DepartmentID != "" {
-		user.DepartmentID = req.DepartmentID
-	}
 	if req.HireDate != "" {
 		parsedHireDate, err := time.Parse("2006-01-02", req.HireDate)
 		if err != nil {
@@ -515,8 +509,24 @@ func (s *userService) UpdateUserByID(ctx context.Context, id string, req dto.Req
 		}
 		user.HireDate = parsedHireDate
 	}
-	if req.EmploymentType != "" {
-		user.EmploymentType = req.EmploymentType
+	if req.PositionID != "" || req.DepartmentID != "" || req.EmploymentType != "" {
+		// ตำแหน่ง/แผนก/ประเภทการจ้างเปลี่ยนผ่านประวัติการจ้างงาน (รองรับวันที่มีผลล่วงหน้า)
+		change := models.EmploymentHistory{
+			PositionID:     req.PositionID,
+			DepartmentID:   req.DepartmentID,
+			EmploymentType: req.EmploymentType,
+			ChangedBy:      claims.UserID,
+		}
+		if req.EffectiveDate != "" {
+			effectiveDate, err := time.Parse("2006-01-02", req.EffectiveDate)
+			if err != nil {
+				return nil, fmt.Errorf("invalid date format for effective_date: %w", err)
+			}
+			change.FromDate = effectiveDate
+		}
+		if err := s.historySvc.RecordEmploymentChange(ctx, user, change); err != nil {
+			return nil, err
+		}
 	}
 	if req.Address != (dto.Address{}) {
 		user.Address = models.Address{
@@ -618,8 +628,10 @@ func (s *userService) UpdateDocuments(ctx context.Context, req dto.RequestUpdate
 	} else {
 
 		var documents []models.Document
+		found := false
 		for _, doc := range user.Documents {
 			if doc.Type == req.Type {
+				found = true
 
 				documentsURL := doc.FileURL
 				parts := strings.Split(documentsURL, "/")
@@ -640,6 +652,11 @@ func (s *userService) UpdateDocuments(ctx context.Context, req dto.RequestUpdate
 			documents = append(documents, doc)
 		}
 
+		// ผู้ใช้ที่สร้างก่อนมีประเภทเอกสารนี้ (เช่น contract) ยังไม่มีช่องเอกสาร
+		if !found {
+			documents = append(documents, models.Document{Name: req.Name, FileURL: req.FileURL, Type: req.Type, CreatedAt: time.Now(), UploadedAt: time.Now()})
+		}
+
 		user.Documents = documents
 		user.UpdatedAt = time.Now()
 	}
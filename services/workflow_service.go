@@ -4,13 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Be2Bag/erp-demo/config"
 	"github.com/Be2Bag/erp-demo/dto"
 	"github.com/Be2Bag/erp-demo/models"
+	"github.com/Be2Bag/erp-demo/pkg/helpers"
+	"github.com/Be2Bag/erp-demo/pkg/util"
 	"github.com/Be2Bag/erp-demo/ports"
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
@@ -19,12 +24,17 @@ import (
 )
 
 type workflowService struct {
-	workflowRepo ports.WorkFlowRepository
-	config       config.Config
+	workflowRepo   ports.WorkFlowRepository
+	versionRepo    ports.WorkFlowVersionRepository
+	taskRepo       ports.TaskRepository
+	userRepo       ports.UserRepository
+	departmentRepo ports.DepartmentRepository
+	activityRepo   ports.ActivityRepository
+	config         config.Config
 }
 
-func NewWorkflowService(cfg config.Config, workflowRepo ports.WorkFlowRepository) ports.WorkFlowService {
-	return &workflowService{config: cfg, workflowRepo: workflowRepo}
+func NewWorkflowService(cfg config.Config, workflowRepo ports.WorkFlowRepository, versionRepo ports.WorkFlowVersionRepository, taskRepo ports.TaskRepository, userRepo ports.UserRepository, departmentRepo ports.DepartmentRepository, activityRepo ports.ActivityRepository) ports.WorkFlowService {
+	return &workflowService{config: cfg, workflowRepo: workflowRepo, versionRepo: versionRepo, taskRepo: taskRepo, userRepo: userRepo, departmentRepo: departmentRepo, activityRepo: activityRepo}
 }
 
 func (s *workflowService) CreateWorkflowTemplate(ctx context.Context, req dto.CreateWorkflowTemplateDTO, claims *dto.JWTClaims) error {
@@ -36,22 +46,9 @@ func (s *workflowService) CreateWorkflowTemplate(ctx context.Context, req dto.Cr
 	}
 
 	now := time.Now()
-	steps := make([]models.WorkFlowStep, 0, len(req.Steps))
-	var total float64
-	for _, st := range req.Steps {
-		if st.Hours < 0 {
-			return errors.New("step hours must be >= 0")
-		}
-		steps = append(steps, models.WorkFlowStep{
-			StepID:      uuid.NewString(),
-			StepName:    st.StepName,
-			Description: st.Description,
-			Hours:       st.Hours,
-			Order:       st.Order,
-			CreatedAt:   now,
-			UpdatedAt:   now,
-		})
-		total += st.Hours
+	steps, total, err := buildWorkflowSteps(req.Steps, nil, now)
+	if err != nil {
+		return err
 	}
 
 	tmpl := models.WorkFlowTemplate{
@@ -72,7 +69,7 @@ func (s *workflowService) CreateWorkflowTemplate(ctx context.Context, req dto.Cr
 		return err
 	}
 
-	return nil
+	return s.versionRepo.CreateWorkFlowVersion(ctx, newWorkflowVersion(&tmpl, claims.UserID, "", now))
 }
 
 func (s *workflowService) GetWorkflowTemplateByID(ctx context.Context, workflowID string) (*dto.WorkflowTemplateDTO, error) {
@@ -103,6 +100,9 @@ func (s *workflowService) GetWorkflowTemplateByID(ctx context.Context, workflowI
 			Description: st.Description,
 			Hours:       st.Hours,
 			Order:       st.Order,
+			DependsOn:   st.DependsOn,
+			Department:  st.Department,
+			Checklist:   st.Checklist,
 			CreatedAt:   st.CreatedAt,
 			UpdatedAt:   st.UpdatedAt,
 		})
@@ -183,6 +183,9 @@ func (s *workflowService) ListWorkflowTemplates(ctx context.Context, claims *dto
 				Description: st.Description,
 				Hours:       st.Hours,
 				Order:       st.Order,
+				DependsOn:   st.DependsOn,
+				Department:  st.Department,
+				Checklist:   st.Checklist,
 				CreatedAt:   st.CreatedAt,
 				UpdatedAt:   st.UpdatedAt,
 			})
@@ -228,6 +231,12 @@ func (s *workflowService) UpdateWorkflowTemplate(ctx context.Context, workflowID
 		return mongo.ErrNoDocuments
 	}
 
+	// เก็บเวอร์ชันปัจจุบันไว้ก่อน (template ที่สร้างก่อนมีประวัติเวอร์ชัน)
+	if err := s.ensureVersionHistory(ctx, existing); err != nil {
+		return err
+	}
+	before := *existing
+
 	if req.WorkFlowName != "" {
 		existing.WorkFlowName = req.WorkFlowName
 	}
@@ -237,30 +246,22 @@ func (s *workflowService) UpdateWorkflowTemplate(ctx context.Context, workflowID
 	if req.Description != "" {
 		existing.Description = req.Description
 	}
+	now := time.Now()
 	if req.Steps != nil {
-		nowSteps := time.Now()
-		var total float64
-		newSteps := make([]models.WorkFlowStep, 0, len(*req.Steps))
-		for _, st := range *req.Steps {
-			if st.Hours < 0 {
-				return errors.New("step hours must be >= 0")
-			}
-			newSteps = append(newSteps, models.WorkFlowStep{
-				StepID:      uuid.NewString(),
-				StepName:    st.StepName,
-				Description: st.Description,
-				Hours:       st.Hours,
-				Order:       st.Order,
-				CreatedAt:   nowSteps,
-				UpdatedAt:   nowSteps,
-			})
-			total += st.Hours
+		newSteps, total, err := buildWorkflowSteps(*req.Steps, before.Steps, now)
+		if err != nil {
+			return err
 		}
 		existing.Steps = newSteps
 		existing.TotalHours = total
 	}
 
-	existing.UpdatedAt = time.Now()
+	// เวอร์ชันเดิมแก้ไขไม่ได้: มีการเปลี่ยนแปลง = ออกเวอร์ชันใหม่
+	changed := !workflowDiffEmpty(diffWorkflowVersions(newWorkflowVersion(&before, "", "", now), newWorkflowVersion(existing, "", "", now)))
+	if changed {
+		existing.Version = templateVersion(&before) + 1
+	}
+	existing.UpdatedAt = now
 
 	updated, err := s.workflowRepo.UpdateWorkFlowTemplateByID(ctx, workflowID, *existing)
 	if err != nil {
@@ -269,6 +270,9 @@ func (s *workflowService) UpdateWorkflowTemplate(ctx context.Context, workflowID
 	if updated == nil {
 		return mongo.ErrNoDocuments
 	}
+	if changed {
+		return s.versionRepo.CreateWorkFlowVersion(ctx, newWorkflowVersion(updated, updatedBy, strings.TrimSpace(req.ChangeNote), now))
+	}
 	return nil
 }
 
@@ -279,3 +283,825 @@ func (s *workflowService) DeleteWorkflowTemplate(ctx context.Context, workflowID
 	}
 	return err
 }
+
+// buildWorkflowSteps แปลง steps จาก request เป็น model พร้อมแปลง depends_on (อ้างด้วย order) เป็น step_id
+// และตรวจว่ากราฟ prerequisite ไม่มีวงวน
+// existing = steps เดิมของ template (ตอนแก้ไข) ใช้คง step_id เดิมตาม step_id ที่ส่งมาหรือชื่อ step เพื่อเทียบข้ามเวอร์ชันได้
+func buildWorkflowSteps(reqSteps []dto.CreateWorkflowStepDTO, existing []models.WorkFlowStep, now time.Time) ([]models.WorkFlowStep, float64, error) {
+	steps := make([]models.WorkFlowStep, 0, len(reqSteps))
+	idByOrder := make(map[int]string, len(reqSteps))
+	duplicated := make(map[int]bool)
+	used := make(map[string]bool, len(existing))
+	var total float64
+	for _, st := range reqSteps {
+		if st.Hours < 0 {
+			return nil, 0, errors.New("step hours must be >= 0")
+		}
+		id := uuid.NewString()
+		createdAt := now
+		if prev, ok := matchExistingStep(st, existing, used); ok {
+			id = prev.StepID
+			createdAt = prev.CreatedAt
+			used[id] = true
+		} else if strings.TrimSpace(st.StepID) != "" {
+			return nil, 0, fmt.Errorf("step_id %s not found in this workflow", st.StepID)
+		}
+		checklist, err := normalizeChecklist(st.Checklist)
+		if err != nil {
+			return nil, 0, fmt.Errorf("step %s: %w", st.StepName, err)
+		}
+		if _, ok := idByOrder[st.Order]; ok {
+			duplicated[st.Order] = true
+		}
+		idByOrder[st.Order] = id
+		steps = append(steps, models.WorkFlowStep{
+			StepID:      id,
+			StepName:    st.StepName,
+			Description: st.Description,
+			Hours:       st.Hours,
+			Order:       st.Order,
+			Department:  strings.TrimSpace(st.Department),
+			Checklist:   checklist,
+			CreatedAt:   createdAt,
+			UpdatedAt:   now,
+		})
+		total += st.Hours
+	}
+
+	deps := make(map[string][]string, len(reqSteps))
+	for i, st := range reqSteps {
+		key := strconv.Itoa(st.Order)
+		if duplicated[st.Order] {
+			key = fmt.Sprintf("%d#%d", st.Order, i+1)
+		}
+		refs := make([]string, 0, len(st.DependsOn))
+		seen := make(map[int]bool, len(st.DependsOn))
+		for _, order := range st.DependsOn {
+			if seen[order] {
+				continue
+			}
+			seen[order] = true
+			if duplicated[order] {
+				return nil, 0, fmt.Errorf("step order %d is not unique and cannot be used in depends_on", order)
+			}
+			if _, ok := idByOrder[order]; !ok {
+				return nil, 0, fmt.Errorf("step %s depends on unknown step %d", key, order)
+			}
+			refs = append(refs, strconv.Itoa(order))
+			steps[i].DependsOn = append(steps[i].DependsOn, idByOrder[order])
+		}
+		deps[key] = refs
+	}
+	if err := helpers.ValidateStepGraph(deps); err != nil {
+		return nil, 0, err
+	}
+
+	return steps, total, nil
+}
+
+// matchExistingStep หา step เดิมของ template ที่ตรงกับ step ใน request (step_id ก่อน แล้วค่อยชื่อ step)
+func matchExistingStep(st dto.CreateWorkflowStepDTO, existing []models.WorkFlowStep, used map[string]bool) (models.WorkFlowStep, bool) {
+	if id := strings.TrimSpace(st.StepID); id != "" {
+		for _, prev := range existing {
+			if prev.StepID == id && !used[prev.StepID] {
+				return prev, true
+			}
+		}
+		return models.WorkFlowStep{}, false
+	}
+	name := strings.TrimSpace(st.StepName)
+	for _, prev := range existing {
+		if !used[prev.StepID] && prev.DeletedAt == nil && strings.EqualFold(strings.TrimSpace(prev.StepName), name) {
+			return prev, true
+		}
+	}
+	return models.WorkFlowStep{}, false
+}
+
+func (s *workflowService) ListWorkflowVersions(ctx context.Context, workflowID string) ([]dto.WorkflowVersionDTO, error) {
+	tmpl, err := s.getTemplate(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureVersionHistory(ctx, tmpl); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.versionRepo.GetAllWorkFlowVersionsByFilter(ctx, bson.M{"workflow_id": workflowID}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]dto.WorkflowVersionDTO, 0, len(versions))
+	for _, v := range versions {
+		out = append(out, toWorkflowVersionDTO(v, templateVersion(tmpl)))
+	}
+	return out, nil
+}
+
+func (s *workflowService) GetWorkflowVersion(ctx context.Context, workflowID string, version int) (*dto.WorkflowVersionDTO, error) {
+	tmpl, err := s.getTemplate(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+	v, err := s.loadVersion(ctx, tmpl, version)
+	if err != nil {
+		return nil, err
+	}
+	out := toWorkflowVersionDTO(v, templateVersion(tmpl))
+	return &out, nil
+}
+
+func (s *workflowService) DiffWorkflowVersions(ctx context.Context, workflowID string, req dto.RequestWorkflowDiff) (*dto.WorkflowVersionDiffDTO, error) {
+	tmpl, err := s.getTemplate(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+	to := req.To
+	if to <= 0 {
+		to = templateVersion(tmpl)
+	}
+	from := req.From
+	if from <= 0 {
+		from = to - 1
+	}
+	if from <= 0 || from == to {
+		return nil, errors.New("from and to must be two different versions")
+	}
+
+	fromVersion, err := s.loadVersion(ctx, tmpl, from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := s.loadVersion(ctx, tmpl, to)
+	if err != nil {
+		return nil, err
+	}
+	diff := diffWorkflowVersions(*fromVersion, *toVersion)
+	diff.WorkFlowID = workflowID
+	return &diff, nil
+}
+
+// MigrateWorkflowTasks ย้ายงานที่ยังไม่เสร็จจากเวอร์ชันต้นทางไปเวอร์ชันปลายทาง
+// step ที่จับคู่ได้คงรหัสและสถานะเดิม (done/skip/in_progress, เวลาเริ่ม/เสร็จ, บันทึก, ผู้รับผิดชอบ)
+// step ใหม่เริ่มเป็น todo และ step ที่ถูกตัดจะแจ้งเตือนถ้าทำไปแล้ว; dry_run แสดงผลโดยไม่บันทึก
+func (s *workflowService) MigrateWorkflowTasks(ctx context.Context, workflowID string, req dto.MigrateWorkflowTasksDTO, claims *dto.JWTClaims) (*dto.WorkflowMigrationResultDTO, error) {
+	if claims.Role != "admin" {
+		return nil, ports.ErrWorkflowForbidden
+	}
+	tmpl, err := s.getTemplate(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+	to := req.ToVersion
+	if to <= 0 {
+		to = templateVersion(tmpl)
+	}
+	if req.FromVersion <= 0 {
+		return nil, errors.New("from_version is required")
+	}
+	if req.FromVersion == to {
+		return nil, errors.New("from_version and to_version must be different")
+	}
+
+	fromVersion, err := s.loadVersion(ctx, tmpl, req.FromVersion)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := s.loadVersion(ctx, tmpl, to)
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := resolveStepMapping(fromVersion.Steps, toVersion.Steps, req.StepMapping)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.WorkflowMigrationResultDTO{
+		WorkFlowID:  workflowID,
+		FromVersion: fromVersion.Version,
+		ToVersion:   toVersion.Version,
+		DryRun:      req.DryRun,
+		StepMapping: stepMappingDTOs(fromVersion.Steps, toVersion.Steps, mapping),
+		Tasks:       []dto.WorkflowTaskMigrationDTO{},
+	}
+
+	filter := bson.M{"applied_workflow.workflow_id": workflowID, "deleted_at": nil}
+	taskIDs := helpers.UniqueStrings(req.TaskIDs...)
+	if len(taskIDs) > 0 {
+		filter["task_id"] = bson.M{"$in": taskIDs}
+	} else {
+		filter["status"] = bson.M{"$nin": bson.A{"done", "cancelled"}}
+	}
+	tasks, err := s.taskRepo.GetAllTaskByFilter(ctx, filter, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]bool, len(tasks))
+	for _, t := range tasks {
+		found[t.TaskID] = true
+	}
+	for _, id := range taskIDs {
+		if !found[id] {
+			result.Tasks = append(result.Tasks, dto.WorkflowTaskMigrationDTO{TaskID: id, SkipReason: "ไม่พบงานที่ใช้ workflow นี้"})
+			result.Skipped++
+		}
+	}
+
+	var managers map[string]string
+	now := time.Now()
+	for _, task := range tasks {
+		item := dto.WorkflowTaskMigrationDTO{TaskID: task.TaskID, JobName: task.JobName, Status: task.Status}
+		switch {
+		case task.Status == "done" || task.Status == "cancelled":
+			item.SkipReason = "งานปิดแล้ว (" + task.Status + ")"
+		case taskTemplateVersion(task) != fromVersion.Version:
+			item.SkipReason = fmt.Sprintf("งานใช้เวอร์ชัน %d ไม่ใช่เวอร์ชัน %d", taskTemplateVersion(task), fromVersion.Version)
+		}
+		if item.SkipReason != "" {
+			result.Tasks = append(result.Tasks, item)
+			result.Skipped++
+			continue
+		}
+
+		steps, plan, warnings := planTaskMigration(task, fromVersion, toVersion, mapping, now)
+		if managers == nil {
+			if managers, err = s.departmentManagers(ctx); err != nil {
+				return nil, err
+			}
+		}
+		defaultStepAssignees(ctx, s.userRepo, steps, task.Assignee, task.Department, managers)
+
+		newStatus := helpers.DeriveTaskStatusFromSteps(steps)
+		if newStatus == "done" {
+			warnings = append(warnings, "หลังย้ายทุก step ปิดแล้ว งานจะเปลี่ยนเป็น done")
+		}
+		item.NewStatus = newStatus
+		item.Steps = plan
+		item.Warnings = warnings
+		item.Migrated = true
+
+		if !req.DryRun {
+			if err := s.applyTaskMigration(ctx, task, steps, toVersion, claims.UserID, now); err != nil {
+				item.Migrated = false
+				item.SkipReason = "บันทึกไม่สำเร็จ: " + err.Error()
+			}
+		}
+		if item.Migrated {
+			result.Migrated++
+		} else {
+			result.Skipped++
+		}
+		result.Tasks = append(result.Tasks, item)
+	}
+
+	return result, nil
+}
+
+// applyTaskMigration บันทึก steps ชุดใหม่ ปรับสถานะงาน/สถิติ และบันทึก activity
+func (s *workflowService) applyTaskMigration(ctx context.Context, task *models.Tasks, steps []models.TaskWorkflowStep, toVersion *models.WorkFlowTemplateVersion, actorID string, now time.Time) error {
+	var total float64
+	for _, st := range steps {
+		total += st.Hours
+	}
+
+	newDoc := *task
+	newDoc.AppliedWorkflow = models.TaskAppliedWorkflow{
+		WorkFlowID:      toVersion.WorkFlowID,
+		WorkFlowName:    toVersion.WorkFlowName,
+		Department:      toVersion.Department,
+		Description:     toVersion.Description,
+		TotalHours:      total,
+		Steps:           steps,
+		Version:         task.AppliedWorkflow.Version + 1,
+		TemplateVersion: toVersion.Version,
+	}
+	newDoc.Status = helpers.DeriveTaskStatusFromSteps(steps)
+	newDoc.StepName = helpers.CurrentStepName(steps)
+	newDoc.ReadySteps = helpers.ReadyStepNames(steps)
+	newDoc.UpdatedAt = now
+
+	updated, err := s.taskRepo.ReplaceTaskByID(ctx, task.TaskID, &newDoc)
+	if err != nil {
+		return err
+	}
+	if updated == nil {
+		return mongo.ErrNoDocuments
+	}
+
+	_ = applyTaskStatsDiff(ctx, s.taskRepo, taskOwnerShares(task), taskOwnerShares(&newDoc))
+
+	activities := []models.Activity{{
+		Type:      "task_updated",
+		FromValue: fmt.Sprintf("v%d", taskTemplateVersion(task)),
+		ToValue:   fmt.Sprintf("v%d", toVersion.Version),
+		Message:   fmt.Sprintf("ย้าย workflow \"%s\" จากเวอร์ชัน %d เป็นเวอร์ชัน %d", toVersion.WorkFlowName, taskTemplateVersion(task), toVersion.Version),
+	}}
+	if newDoc.Status != task.Status {
+		activities = append(activities, models.Activity{Type: "task_status", FromValue: task.Status, ToValue: newDoc.Status})
+	}
+	for i := range activities {
+		activities[i].ActivityID = uuid.NewString()
+		activities[i].EntityType = "task"
+		activities[i].EntityID = task.TaskID
+		activities[i].JobID = task.JobID
+		activities[i].ActorID = actorID
+		activities[i].CreatedAt = now
+	}
+	if err := s.activityRepo.CreateActivities(ctx, activities); err != nil {
+		log.Println("Error recording workflow migration activity:", err)
+	}
+	return nil
+}
+
+func (s *workflowService) getTemplate(ctx context.Context, workflowID string) (*models.WorkFlowTemplate, error) {
+	if strings.TrimSpace(workflowID) == "" {
+		return nil, errors.New("workflowID is required")
+	}
+	tmpl, err := s.workflowRepo.GetOneWorkFlowTemplateByFilter(ctx, bson.M{"workflow_id": workflowID, "deleted_at": nil}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if tmpl == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	return tmpl, nil
+}
+
+// ensureVersionHistory สร้าง snapshot ของเวอร์ชันปัจจุบัน ถ้า template ยังไม่มีในประวัติ (สร้างก่อนมีระบบเวอร์ชัน)
+func (s *workflowService) ensureVersionHistory(ctx context.Context, tmpl *models.WorkFlowTemplate) error {
+	current, err := s.versionRepo.GetOneWorkFlowVersionByFilter(ctx, bson.M{"workflow_id": tmpl.WorkFlowID, "version": templateVersion(tmpl)}, bson.M{"version_id": 1})
+	if err != nil {
+		return err
+	}
+	if current != nil {
+		return nil
+	}
+	return s.versionRepo.CreateWorkFlowVersion(ctx, newWorkflowVersion(tmpl, tmpl.CreatedBy, "", tmpl.UpdatedAt))
+}
+
+func (s *workflowService) loadVersion(ctx context.Context, tmpl *models.WorkFlowTemplate, version int) (*models.WorkFlowTemplateVersion, error) {
+	if err := s.ensureVersionHistory(ctx, tmpl); err != nil {
+		return nil, err
+	}
+	v, err := s.versionRepo.GetOneWorkFlowVersionByFilter(ctx, bson.M{"workflow_id": tmpl.WorkFlowID, "version": version}, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	return v, nil
+}
+
+// departmentManagers คืน map แผนก -> ผู้จัดการแผนก (ใช้กำหนดผู้รับผิดชอบ step ใหม่)
+func (s *workflowService) departmentManagers(ctx context.Context) (map[string]string, error) {
+	departments, err := s.departmentRepo.GetAllDepartmentByFilter(ctx, bson.M{"deleted_at": nil}, bson.M{"department_id": 1, "manager_id": 1})
+	if err != nil {
+		return nil, err
+	}
+	managers := make(map[string]string, len(departments))
+	for _, d := range departments {
+		managers[d.DepartmentID] = d.ManagerID
+	}
+	return managers, nil
+}
+
+// templateVersion เวอร์ชันของ template (ข้อมูลเก่าที่ยังไม่มีเลขเวอร์ชัน = 1)
+func templateVersion(tmpl *models.WorkFlowTemplate) int {
+	if tmpl.Version <= 0 {
+		return 1
+	}
+	return tmpl.Version
+}
+
+// taskTemplateVersion เวอร์ชันของ template ที่งาน snapshot มา (งานก่อนมีระบบเวอร์ชัน = 1)
+func taskTemplateVersion(task *models.Tasks) int {
+	if task.AppliedWorkflow.TemplateVersion <= 0 {
+		return 1
+	}
+	return task.AppliedWorkflow.TemplateVersion
+}
+
+func newWorkflowVersion(tmpl *models.WorkFlowTemplate, createdBy, note string, now time.Time) models.WorkFlowTemplateVersion {
+	steps := make([]models.WorkFlowStep, 0, len(tmpl.Steps))
+	for _, st := range tmpl.Steps {
+		if st.DeletedAt == nil {
+			steps = append(steps, st)
+		}
+	}
+	return models.WorkFlowTemplateVersion{
+		VersionID:    uuid.NewString(),
+		WorkFlowID:   tmpl.WorkFlowID,
+		Version:      templateVersion(tmpl),
+		WorkFlowName: tmpl.WorkFlowName,
+		Department:   tmpl.Department,
+		Description:  tmpl.Description,
+		Steps:        steps,
+		TotalHours:   tmpl.TotalHours,
+		ChangeNote:   note,
+		CreatedBy:    createdBy,
+		CreatedAt:    now,
+	}
+}
+
+func toWorkflowStepDTOs(steps []models.WorkFlowStep) []dto.WorkflowStepDTO {
+	out := make([]dto.WorkflowStepDTO, 0, len(steps))
+	for _, st := range steps {
+		out = append(out, dto.WorkflowStepDTO{
+			StepID:      st.StepID,
+			StepName:    st.StepName,
+			Description: st.Description,
+			Hours:       st.Hours,
+			Order:       st.Order,
+			DependsOn:   st.DependsOn,
+			Department:  st.Department,
+			Checklist:   st.Checklist,
+			CreatedAt:   st.CreatedAt,
+			UpdatedAt:   st.UpdatedAt,
+		})
+	}
+	return out
+}
+
+func toWorkflowVersionDTO(v *models.WorkFlowTemplateVersion, current int) dto.WorkflowVersionDTO {
+	return dto.WorkflowVersionDTO{
+		CreatedAt:    v.CreatedAt,
+		VersionID:    v.VersionID,
+		WorkFlowID:   v.WorkFlowID,
+		Version:      v.Version,
+		WorkFlowName: v.WorkFlowName,
+		Department:   v.Department,
+		Description:  v.Description,
+		Steps:        toWorkflowStepDTOs(v.Steps),
+		TotalHours:   v.TotalHours,
+		ChangeNote:   v.ChangeNote,
+		CreatedBy:    v.CreatedBy,
+		IsCurrent:    v.Version == current,
+	}
+}
+
+// diffWorkflowVersions เทียบสองเวอร์ชัน: ฟิลด์ของ template และ step ที่เพิ่ม/ตัด/แก้ไข (จับคู่ด้วย step_id)
+func diffWorkflowVersions(from, to models.WorkFlowTemplateVersion) dto.WorkflowVersionDiffDTO {
+	diff := dto.WorkflowVersionDiffDTO{
+		WorkFlowID:   to.WorkFlowID,
+		FromVersion:  from.Version,
+		ToVersion:    to.Version,
+		Fields:       []dto.WorkflowFieldChangeDTO{},
+		AddedSteps:   []dto.WorkflowStepDTO{},
+		RemovedSteps: []dto.WorkflowStepDTO{},
+		ChangedSteps: []dto.WorkflowStepChangeDTO{},
+		HoursDelta:   util.Round2(to.TotalHours - from.TotalHours),
+	}
+	diff.Fields = appendFieldChange(diff.Fields, "workflow_name", from.WorkFlowName, to.WorkFlowName)
+	diff.Fields = appendFieldChange(diff.Fields, "department_id", from.Department, to.Department)
+	diff.Fields = appendFieldChange(diff.Fields, "description", from.Description, to.Description)
+
+	fromNames := stepNamesByID(from.Steps)
+	toNames := stepNamesByID(to.Steps)
+	fromByID := make(map[string]models.WorkFlowStep, len(from.Steps))
+	for _, st := range from.Steps {
+		fromByID[st.StepID] = st
+	}
+	seen := make(map[string]bool, len(to.Steps))
+	for _, st := range to.Steps {
+		seen[st.StepID] = true
+		prev, ok := fromByID[st.StepID]
+		if !ok {
+			diff.AddedSteps = append(diff.AddedSteps, toWorkflowStepDTOs([]models.WorkFlowStep{st})...)
+			continue
+		}
+		changes := []dto.WorkflowFieldChangeDTO{}
+		changes = appendFieldChange(changes, "step_name", prev.StepName, st.StepName)
+		changes = appendFieldChange(changes, "description", prev.Description, st.Description)
+		changes = appendFieldChange(changes, "hours", strconv.FormatFloat(prev.Hours, 'f', -1, 64), strconv.FormatFloat(st.Hours, 'f', -1, 64))
+		changes = appendFieldChange(changes, "order", strconv.Itoa(prev.Order), strconv.Itoa(st.Order))
+		changes = appendFieldChange(changes, "department_id", prev.Department, st.Department)
+		changes = appendFieldChange(changes, "depends_on", dependsOnNames(prev.DependsOn, fromNames), dependsOnNames(st.DependsOn, toNames))
+		changes = appendFieldChange(changes, "checklist", strings.Join(prev.Checklist, ", "), strings.Join(st.Checklist, ", "))
+		if len(changes) > 0 {
+			diff.ChangedSteps = append(diff.ChangedSteps, dto.WorkflowStepChangeDTO{StepID: st.StepID, StepName: st.StepName, Changes: changes})
+		}
+	}
+	for _, st := range from.Steps {
+		if !seen[st.StepID] {
+			diff.RemovedSteps = append(diff.RemovedSteps, toWorkflowStepDTOs([]models.WorkFlowStep{st})...)
+		}
+	}
+	return diff
+}
+
+func workflowDiffEmpty(diff dto.WorkflowVersionDiffDTO) bool {
+	return len(diff.Fields) == 0 && len(diff.AddedSteps) == 0 && len(diff.RemovedSteps) == 0 && len(diff.ChangedSteps) == 0
+}
+
+func appendFieldChange(changes []dto.WorkflowFieldChangeDTO, field, from, to string) []dto.WorkflowFieldChangeDTO {
+	if from == to {
+		return changes
+	}
+	return append(changes, dto.WorkflowFieldChangeDTO{Field: field, From: from, To: to})
+}
+
+func stepNamesByID(steps []models.WorkFlowStep) map[string]string {
+	names := make(map[string]string, len(steps))
+	for _, st := range steps {
+		names[st.StepID] = st.StepName
+	}
+	return names
+}
+
+func dependsOnNames(ids []string, names map[string]string) string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if name, ok := names[id]; ok {
+			out = append(out, name)
+		} else {
+			out = append(out, id)
+		}
+	}
+	sort.Strings(out)
+	return strings.Join(out, ", ")
+}
+
+// resolveStepMapping จับคู่ step เวอร์ชันเก่า -> เวอร์ชันใหม่: step_id เดียวกันก่อน แล้วชื่อเดียวกัน
+// แล้วค่อยทับด้วย mapping ที่ผู้ใช้ระบุ ("" = ตัดทิ้ง)
+func resolveStepMapping(from, to []models.WorkFlowStep, override map[string]string) (map[string]string, error) {
+	toIDs := make(map[string]bool, len(to))
+	for _, st := range to {
+		toIDs[st.StepID] = true
+	}
+	mapping := make(map[string]string, len(from))
+	for _, st := range from {
+		if toIDs[st.StepID] {
+			mapping[st.StepID] = st.StepID
+			continue
+		}
+		for _, cand := range to {
+			if strings.EqualFold(strings.TrimSpace(cand.StepName), strings.TrimSpace(st.StepName)) {
+				mapping[st.StepID] = cand.StepID
+				break
+			}
+		}
+	}
+
+	fromIDs := stepNamesByID(from)
+	for oldID, newID := range override {
+		if _, ok := fromIDs[oldID]; !ok {
+			return nil, fmt.Errorf("step_mapping: step %s not found in version being migrated from", oldID)
+		}
+		newID = strings.TrimSpace(newID)
+		if newID != "" && !toIDs[newID] {
+			return nil, fmt.Errorf("step_mapping: step %s not found in target version", newID)
+		}
+		mapping[oldID] = newID
+	}
+	return mapping, nil
+}
+
+func stepMappingDTOs(from, to []models.WorkFlowStep, mapping map[string]string) []dto.WorkflowStepMappingDTO {
+	toNames := stepNamesByID(to)
+	out := make([]dto.WorkflowStepMappingDTO, 0, len(from))
+	for _, st := range from {
+		newID := mapping[st.StepID]
+		out = append(out, dto.WorkflowStepMappingDTO{FromStepID: st.StepID, FromStepName: st.StepName, ToStepID: newID, ToStepName: toNames[newID]})
+	}
+	return out
+}
+
+// planTaskMigration สร้าง steps ชุดใหม่ของงานตามเวอร์ชันปลายทาง
+//   - step ของงานผูกกับ step ของ template ผ่าน template_step_id (งานเก่าจับจากชื่อ)
+//   - step ที่มี step เดิมหลายตัวมารวมกัน: ปิดครบ = done, มีเริ่มแล้ว = in_progress
+//   - step ที่เพิ่มเองในงาน (ไม่อยู่ใน template) คงไว้ท้ายรายการ
+func planTaskMigration(task *models.Tasks, fromVersion, toVersion *models.WorkFlowTemplateVersion, mapping map[string]string, now time.Time) ([]models.TaskWorkflowStep, []dto.WorkflowTaskStepPlanDTO, []string) {
+	fromIDs := stepNamesByID(fromVersion.Steps)
+	sources := make(map[string][]models.TaskWorkflowStep) // step_id ใหม่ของ template -> step เดิมของงาน
+	custom := make([]models.TaskWorkflowStep, 0)
+	plan := make([]dto.WorkflowTaskStepPlanDTO, 0, len(toVersion.Steps))
+	warnings := make([]string, 0)
+
+	current := make([]models.TaskWorkflowStep, len(task.AppliedWorkflow.Steps))
+	copy(current, task.AppliedWorkflow.Steps)
+	sort.SliceStable(current, func(i, j int) bool { return current[i].Order < current[j].Order })
+
+	for _, st := range current {
+		templateStepID := st.TemplateStepID
+		if _, ok := fromIDs[templateStepID]; !ok {
+			templateStepID = ""
+			for _, ts := range fromVersion.Steps {
+				if strings.EqualFold(strings.TrimSpace(ts.StepName), strings.TrimSpace(st.StepName)) {
+					templateStepID = ts.StepID
+					break
+				}
+			}
+		}
+		if templateStepID == "" {
+			custom = append(custom, st)
+			continue
+		}
+		newID := mapping[templateStepID]
+		if newID == "" {
+			plan = append(plan, dto.WorkflowTaskStepPlanDTO{StepID: st.StepID, StepName: st.StepName, Action: "removed", Status: st.Status})
+			switch st.Status {
+			case "done", "skip":
+				warnings = append(warnings, fmt.Sprintf("step \"%s\" ที่ปิดแล้วจะถูกตัดออก", st.StepName))
+			case "in_progress":
+				warnings = append(warnings, fmt.Sprintf("step \"%s\" ที่กำลังทำอยู่จะถูกตัดออก", st.StepName))
+			}
+			continue
+		}
+		sources[newID] = append(sources[newID], st)
+	}
+
+	taskStepIDs := make(map[string]string, len(toVersion.Steps)) // step_id ของ template -> step_id ในงาน
+	steps := make([]models.TaskWorkflowStep, 0, len(toVersion.Steps)+len(custom))
+	for _, ts := range toVersion.Steps {
+		department := ts.Department
+		if department == "" {
+			department = toVersion.Department
+		}
+		step := models.TaskWorkflowStep{
+			StepID:         uuid.NewString(),
+			TemplateStepID: ts.StepID,
+			StepName:       ts.StepName,
+			Description:    ts.Description,
+			Hours:          ts.Hours,
+			Order:          ts.Order,
+			Status:         "todo",
+			Department:     department,
+			Checklist:      newChecklist(ts.Checklist),
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		src := sources[ts.StepID]
+		action := "added"
+		names := make([]string, 0, len(src))
+		if len(src) > 0 {
+			action = "kept"
+			if len(src) > 1 {
+				action = "merged"
+			}
+			mergeStepState(&step, src)
+			for _, p := range src {
+				names = append(names, p.StepName)
+			}
+		}
+		taskStepIDs[ts.StepID] = step.StepID
+		steps = append(steps, step)
+		plan = append(plan, dto.WorkflowTaskStepPlanDTO{StepID: step.StepID, StepName: step.StepName, Action: action, FromStepNames: names, Status: step.Status})
+	}
+
+	// depends_on อ้าง step_id ในงาน
+	for i, ts := range toVersion.Steps {
+		for _, d := range ts.DependsOn {
+			if id, ok := taskStepIDs[d]; ok {
+				steps[i].DependsOn = append(steps[i].DependsOn, id)
+			}
+		}
+	}
+
+	// step ที่เพิ่มเองในงาน: คงไว้ท้ายรายการ และตัด prerequisite ที่ไม่มีแล้ว
+	kept := make(map[string]bool, len(steps)+len(custom))
+	for _, st := range steps {
+		kept[st.StepID] = true
+	}
+	for _, st := range custom {
+		kept[st.StepID] = true
+	}
+	maxOrder := 0
+	for _, st := range steps {
+		if st.Order > maxOrder {
+			maxOrder = st.Order
+		}
+	}
+	for _, st := range custom {
+		maxOrder++
+		st.Order = maxOrder
+		deps := make([]string, 0, len(st.DependsOn))
+		for _, d := range st.DependsOn {
+			if kept[d] {
+				deps = append(deps, d)
+			}
+		}
+		st.DependsOn = nil
+		if len(deps) > 0 {
+			st.DependsOn = deps
+		}
+		steps = append(steps, st)
+		plan = append(plan, dto.WorkflowTaskStepPlanDTO{StepID: st.StepID, StepName: st.StepName, Action: "custom", Status: st.Status})
+	}
+
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].Order < steps[j].Order })
+	for i := range steps {
+		steps[i].Order = i + 1
+	}
+
+	// step ที่ปิดแล้วแต่มี prerequisite ใหม่ที่ยังไม่ทำ
+	for _, st := range steps {
+		if st.Status != "done" && st.Status != "skip" {
+			continue
+		}
+		for _, p := range helpers.PendingPrerequisites(steps, st.StepID) {
+			warnings = append(warnings, fmt.Sprintf("step \"%s\" ปิดแล้วแต่ prerequisite \"%s\" ยังไม่เสร็จ", st.StepName, p.StepName))
+		}
+	}
+
+	return steps, plan, warnings
+}
+
+// mergeStepState คัดลอกสถานะจาก step เดิมของงาน (หลายตัว = รวมกัน) ลง step ใหม่
+// step เดี่ยวคง step_id เดิมเพื่อให้เวลา/ความคิดเห็น/ไฟล์แนบที่อ้าง step ยังใช้ได้
+func mergeStepState(step *models.TaskWorkflowStep, src []models.TaskWorkflowStep) {
+	first := src[0]
+	step.StepID = first.StepID
+	step.CreatedAt = first.CreatedAt
+	step.Assignee = first.Assignee
+	step.AssigneeName = first.AssigneeName
+	if first.Department != "" && len(src) == 1 {
+		step.Department = first.Department
+	}
+
+	allClosed, allSkipped, started := true, true, false
+	notes := make([]string, 0, len(src))
+	for _, p := range src {
+		switch p.Status {
+		case "done":
+			allSkipped = false
+			started = true
+		case "skip":
+		case "in_progress":
+			allClosed, allSkipped = false, false
+			started = true
+		default:
+			allClosed, allSkipped = false, false
+		}
+		if p.StartedAt != nil && (step.StartedAt == nil || p.StartedAt.Before(*step.StartedAt)) {
+			step.StartedAt = p.StartedAt
+		}
+		if p.CompletedAt != nil && (step.CompletedAt == nil || p.CompletedAt.After(*step.CompletedAt)) {
+			step.CompletedAt = p.CompletedAt
+		}
+		if strings.TrimSpace(p.Notes) != "" {
+			notes = append(notes, strings.TrimSpace(p.Notes))
+		}
+	}
+	step.Notes = strings.Join(notes, "\n")
+	step.Checklist = mergeChecklist(step.Checklist, src)
+
+	switch {
+	case allClosed && allSkipped:
+		step.Status = "skip"
+	case allClosed:
+		step.Status = "done"
+	case started:
+		step.Status = "in_progress"
+	default:
+		step.Status = "todo"
+	}
+	if step.Status != "done" && step.Status != "skip" {
+		step.CompletedAt = nil
+	}
+
+	if len(src) == 1 {
+		// step ที่ปิดแล้วคงชั่วโมงประมาณการเดิม (ใช้เทียบกับเวลาจริง)
+		if first.Status == "done" || first.Status == "skip" {
+			step.Hours = first.Hours
+		}
+		step.SLADueAt = first.SLADueAt
+		step.SLAStatus = first.SLAStatus
+	}
+}
+
+// mergeChecklist คงสถานะติ๊กของรายการที่ข้อความตรงกับของเดิม และเก็บรายการที่เพิ่มเองในงานไว้ท้ายรายการ
+func mergeChecklist(items []models.TaskChecklistItem, src []models.TaskWorkflowStep) []models.TaskChecklistItem {
+	byText := make(map[string]models.TaskChecklistItem)
+	order := make([]string, 0)
+	for _, p := range src {
+		for _, it := range p.Checklist {
+			key := strings.ToLower(strings.TrimSpace(it.Text))
+			prev, ok := byText[key]
+			if !ok {
+				order = append(order, key)
+			}
+			if !ok || (it.Checked && !prev.Checked) {
+				byText[key] = it
+			}
+		}
+	}
+
+	used := make(map[string]bool, len(items))
+	for i := range items {
+		key := strings.ToLower(strings.TrimSpace(items[i].Text))
+		used[key] = true
+		if prev, ok := byText[key]; ok {
+			items[i].ItemID = prev.ItemID
+			items[i].Checked = prev.Checked
+			items[i].CheckedBy = prev.CheckedBy
+			items[i].CheckedAt = prev.CheckedAt
+		}
+	}
+	for _, key := range order {
+		if !used[key] {
+			items = append(items, byText[key])
+		}
+	}
+	return items
+}